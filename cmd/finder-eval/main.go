@@ -0,0 +1,166 @@
+// Command finder-eval replays a captured corpus of recipe finder ranker calls
+// against a candidate light model (or a baseline) and reports how closely it
+// agrees with production: top-pick agreement, shortlist overlap, avoid-flag
+// recall on allergen cases, latency and cost.
+//
+// The corpus is either read from finder_runs (rows captured via
+// FINDER_RANK_CAPTURE_RATE) or from a JSONL file written with -export:
+//
+//	go run ./cmd/finder-eval -provider gemini -model gemini-2.5-flash -since 168h
+//	go run ./cmd/finder-eval -since 168h -export corpus.jsonl
+//	go run ./cmd/finder-eval -corpus corpus.jsonl -provider deepseek
+//
+// Besides the light-tier providers (anthropic|openai|gemini|deepseek),
+// -provider accepts two baselines that make no model calls: "captured"
+// replays the reference results (a sanity check; every agreement is 1) and
+// "search-order" scores the unranked fallback.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/config"
+	"github.com/windoze95/saltybytes-api/internal/db"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+	"github.com/windoze95/saltybytes-api/internal/service"
+	"go.uber.org/zap"
+)
+
+func main() {
+	provider := flag.String("provider", "captured", "ranker to evaluate: anthropic|openai|gemini|deepseek|captured|search-order")
+	model := flag.String("model", "", "model id (provider default when empty)")
+	baseURL := flag.String("base-url", "", "OpenAI-compatible base URL override")
+	since := flag.Duration("since", 7*24*time.Hour, "load captures newer than this (database corpus only)")
+	limit := flag.Int("limit", 500, "maximum captures to load (0 = no limit)")
+	corpusPath := flag.String("corpus", "", "read the corpus from this JSONL file instead of the database")
+	exportPath := flag.String("export", "", "write the loaded corpus to this JSONL file and exit")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	logger.Init(true)
+	defer logger.Sync()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.Get().Fatal("failed to load config", zap.Error(err))
+	}
+
+	cases, err := loadCorpus(cfg, *corpusPath, *since, *limit)
+	if err != nil {
+		logger.Get().Fatal("failed to load corpus", zap.Error(err))
+	}
+	if len(cases) == 0 {
+		logger.Get().Fatal("corpus is empty; set FINDER_RANK_CAPTURE_RATE and let captures accumulate")
+	}
+
+	if *exportPath != "" {
+		f, err := os.Create(*exportPath)
+		if err != nil {
+			logger.Get().Fatal("failed to create export file", zap.Error(err))
+		}
+		if err := service.WriteFinderEvalCases(f, cases); err != nil {
+			f.Close()
+			logger.Get().Fatal("failed to write corpus", zap.Error(err))
+		}
+		if err := f.Close(); err != nil {
+			logger.Get().Fatal("failed to write corpus", zap.Error(err))
+		}
+		fmt.Printf("exported %d cases to %s\n", len(cases), *exportPath)
+		return
+	}
+
+	ranker, err := buildRanker(cfg, *provider, *model, *baseURL, cases)
+	if err != nil {
+		logger.Get().Fatal("failed to build ranker", zap.Error(err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report := service.RunFinderEval(ctx, ranker, cases, ai.DefaultPricing)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			logger.Get().Fatal("failed to encode report", zap.Error(err))
+		}
+		return
+	}
+	printReport(*provider, *model, report)
+}
+
+// loadCorpus reads the eval cases from a JSONL file when path is set,
+// otherwise from the captured finder runs in the database.
+func loadCorpus(cfg *config.Config, path string, since time.Duration, limit int) ([]service.FinderEvalCase, error) {
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return service.ReadFinderEvalCases(f)
+	}
+
+	database, err := db.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("connect to database: %w", err)
+	}
+	if sqlDB, err := database.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	return service.LoadFinderEvalCases(repository.NewFinderRunRepository(database), time.Now().Add(-since), limit)
+}
+
+// buildRanker resolves -provider to a baseline or a light-tier provider. The
+// provider is built without middleware: cost is priced from the harness' own
+// call capture and nothing is written to ai_usage_logs.
+func buildRanker(cfg *config.Config, provider, model, baseURL string, cases []service.FinderEvalCase) (service.FinderRanker, error) {
+	switch provider {
+	case "captured":
+		return service.NewCapturedFinderRanker(cases), nil
+	case "search-order":
+		return service.SearchOrderFinderRanker{}, nil
+	}
+
+	prompts, err := config.LoadPrompts(cfg.EnvVars.PromptsPath)
+	if err != nil {
+		return nil, fmt.Errorf("load prompts: %w", err)
+	}
+	keys := ai.LightKeys{
+		AnthropicAPIKey:     cfg.EnvVars.AnthropicAPIKey,
+		AnthropicLightModel: cfg.EnvVars.AnthropicLightModel,
+		OpenAIAPIKey:        cfg.EnvVars.OpenAIAPIKey,
+		GeminiAPIKey:        cfg.EnvVars.GeminiAPIKey,
+		DeepSeekAPIKey:      cfg.EnvVars.DeepSeekAPIKey,
+	}
+	spec := ai.LightProviderSpec{Provider: provider, Model: model, BaseURL: baseURL}
+	return ai.BuildLightProvider(spec, keys, prompts, nil)
+}
+
+func printReport(provider, model string, r service.FinderEvalReport) {
+	name := provider
+	if model != "" {
+		name += "/" + model
+	}
+	fmt.Printf("finder ranker eval: %s\n", name)
+	fmt.Printf("  cases             %d (%d failed)\n", r.Cases, r.Failed)
+	fmt.Printf("  top-1 agreement   %.1f%%\n", r.Top1Agreement*100)
+	fmt.Printf("  top-3 overlap     %.1f%%\n", r.Top3Overlap*100)
+	fmt.Printf("  keep/drop agree   %.1f%%\n", r.KeepAgreement*100)
+	if r.AvoidFlags > 0 {
+		fmt.Printf("  avoid recall      %.1f%% (%d/%d flags over %d allergen cases)\n",
+			r.AvoidRecall*100, r.AvoidCaught, r.AvoidFlags, r.AllergenCases)
+	} else {
+		fmt.Printf("  avoid recall      n/a (%d allergen cases, no reference avoid flags)\n", r.AllergenCases)
+	}
+	fmt.Printf("  latency p50/p95   %s / %s\n", r.LatencyP50.Round(time.Millisecond), r.LatencyP95.Round(time.Millisecond))
+	fmt.Printf("  cost              $%.4f (reference $%.4f)\n", r.CostUSD, r.ReferenceCostUSD)
+}
@@ -7,7 +7,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 	"strings"
 	"time"
 
@@ -73,6 +72,10 @@ func NewAnthropicLightProvider(apiKey string, model string, prompts *config.Prom
 	}
 }
 
+// ingredientUnits is the recipe schema's enum for an ingredient's unit: the
+// canonical unit spellings, which ValidateRecipe accepts as they are.
+var ingredientUnits = []string{"pieces", "tsp", "tbsp", "fl oz", "cup", "pt", "qt", "gal", "oz", "lb", "mL", "L", "mg", "g", "kg", "pinch", "dash", "drop", "bushel"}
+
 // createRecipeTool builds the Claude tool definition that mirrors the existing
 // OpenAI create_recipe function-call schema.
 // recipeProperties is the JSON-schema property set describing a single recipe.
@@ -90,7 +93,7 @@ func recipeProperties(summaryPrompt string) map[string]interface{} {
 				"type": "object",
 				"properties": map[string]interface{}{
 					"name":          map[string]interface{}{"type": "string", "description": "Name of the ingredient, do not include unit or amount in this field"},
-					"unit":          map[string]interface{}{"type": "string", "description": "Unit for the ingredient, comply with UnitSystem specified.", "enum": []string{"pieces", "tsp", "tbsp", "fl oz", "cup", "pt", "qt", "gal", "oz", "lb", "mL", "L", "mg", "g", "kg", "pinch", "dash", "drop", "bushel"}},
+					"unit":          map[string]interface{}{"type": "string", "description": "Unit for the ingredient, comply with UnitSystem specified.", "enum": ingredientUnits},
 					"amount":        map[string]interface{}{"type": "number", "description": "Amount of the ingredient"},
 					"amount_high":   map[string]interface{}{"type": "number", "description": "Upper bound when the source gives a range (e.g. '2-3 cups' -> amount 2, amount_high 3). Omit or 0 for a single amount."},
 					"metric_unit":   map[string]interface{}{"type": "string", "description": "Metric equivalent unit. Always metric (g, kg, mL, L, mg). Duplicate primary if already metric.", "enum": []string{"mg", "g", "kg", "mL", "L"}},
@@ -325,7 +328,7 @@ func classifyVoiceIntentTool() anthropic.ToolUnionParam {
 					"type": map[string]interface{}{
 						"type":        "string",
 						"description": "The classified intent type",
-						"enum":        []string{"scroll_up", "scroll_down", "navigate", "question", "ignore"},
+						"enum":        []string{"scroll_up", "scroll_down", "navigate", "question", "read_step", "ignore"},
 					},
 					"amount": map[string]interface{}{
 						"type":        "string",
@@ -340,6 +343,10 @@ func classifyVoiceIntentTool() anthropic.ToolUnionParam {
 						"type":        "string",
 						"description": "The question text for question intents",
 					},
+					"step": map[string]interface{}{
+						"type":        "integer",
+						"description": "For read_step: the 1-based step number to read aloud, or 0 for the current step",
+					},
 				},
 			},
 		},
@@ -380,6 +387,106 @@ func estimatePortionsTool() anthropic.ToolUnionParam {
 	}
 }
 
+func timeCookStepsTool() anthropic.ToolUnionParam {
+	return anthropic.ToolUnionParam{
+		OfTool: &anthropic.ToolParam{
+			Name:        "time_cook_steps",
+			Description: anthropic.String("Report how long each recipe step takes, the equipment it occupies, and which earlier steps it waits on."),
+			InputSchema: anthropic.ToolInputSchemaParam{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"steps": map[string]interface{}{
+						"type":        "array",
+						"description": "One entry per recipe step, in the order given",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"minutes": map[string]interface{}{
+									"type":        "integer",
+									"description": "Realistic minutes the step takes, including unattended time such as baking or resting",
+								},
+								"equipment": map[string]interface{}{
+									"type":        "string",
+									"enum":        []string{EquipmentOven, EquipmentBurner, "none"},
+									"description": "Oven or stovetop burner the step occupies for its whole duration, or none",
+								},
+								"depends_on": map[string]interface{}{
+									"type":        "array",
+									"items":       map[string]interface{}{"type": "integer"},
+									"description": "1-based numbers of earlier steps that must finish before this step can start",
+								},
+							},
+							"required": []string{"minutes", "equipment", "depends_on"},
+						},
+					},
+				},
+				ExtraFields: map[string]interface{}{
+					"required": []string{"steps"},
+				},
+			},
+		},
+	}
+}
+
+func suggestSubstitutionsTool() anthropic.ToolUnionParam {
+	return anthropic.ToolUnionParam{
+		OfTool: &anthropic.ToolParam{
+			Name:        "suggest_substitutions",
+			Description: anthropic.String("Propose ingredient replacements that make the recipe fit the constraints, with quantities and their effect on the dish."),
+			InputSchema: anthropic.ToolInputSchemaParam{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"substitutions": map[string]interface{}{
+						"type":        "array",
+						"description": "One entry per ingredient that must change; leave out ingredients that already fit",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"ingredient": map[string]interface{}{
+									"type":        "integer",
+									"description": "1-based number of the ingredient being replaced",
+								},
+								"replacement": map[string]interface{}{
+									"type":        "string",
+									"description": "The replacement ingredient, e.g. \"coconut oil\" or \"ground flaxseed mixed with water\"",
+								},
+								"ratio": map[string]interface{}{
+									"type":        "number",
+									"description": "How much replacement to use per 1 unit of the original, measured in unit (or in the original's unit when unit is empty). E.g. 0.75 for 3/4 cup oil per cup of butter, or 3 with unit tbsp for 3 tbsp aquafaba per egg",
+								},
+								"unit": map[string]interface{}{
+									"type":        "string",
+									"description": "Unit the replacement is measured in when it differs from the original's, e.g. tbsp, g, cup; empty to keep the original's unit",
+								},
+								"addresses": map[string]interface{}{
+									"type":        "string",
+									"description": "The constraint this swap satisfies, e.g. \"milk allergy\"",
+								},
+								"effect": map[string]interface{}{
+									"type":        "string",
+									"description": "One sentence on how the swap changes taste, texture or method",
+								},
+								"from_pantry": map[string]interface{}{
+									"type":        "boolean",
+									"description": "Whether the replacement is something the cook listed as on hand",
+								},
+							},
+							"required": []string{"ingredient", "replacement", "ratio", "unit", "addresses", "effect", "from_pantry"},
+						},
+					},
+					"effect": map[string]interface{}{
+						"type":        "string",
+						"description": "One or two sentences on how the dish as a whole will differ with every swap made",
+					},
+				},
+				ExtraFields: map[string]interface{}{
+					"required": []string{"substitutions", "effect"},
+				},
+			},
+		},
+	}
+}
+
 // finderRankSystemPrompt steers the recipe finder's single ranking call. It is
 // an inline prompt (like EstimatePortions') rather than a config template, so
 // the finder is self-contained. The hard rule — reference candidates only by
@@ -400,6 +507,8 @@ For each candidate you return also:
 - Give one short sentence (reason) explaining why it fits — for a collection, say what it collects.
 - Give a best-effort dietary safety assessment for each family member mentioned in the dietary needs, judging ONLY from the candidate's title and description: "safe" if nothing conflicts, "caution" if it might conflict or is unclear, "avoid" if it clearly conflicts with an allergy or restriction. Keep the note short. Omit members you cannot assess.
 
+When a conversation summary is given, the user is refining an earlier find: honour their latest message over the original request, prefer candidates that differ from what they were already shown, and respect everything they ruled out.
+
 Also suggest 2-4 broadened search queries (broaden_queries) the user could try to get more options.`
 
 // finderRankSystemPrompt is the tool-calling variant used by the Anthropic
@@ -544,6 +653,7 @@ type voiceIntentToolResult struct {
 	Amount string `json:"amount"`
 	Target string `json:"target"`
 	Text   string `json:"text"`
+	Step   int    `json:"step"`
 }
 
 // portionToolResult is the JSON structure returned by the estimate_portions tool call.
@@ -553,6 +663,31 @@ type portionToolResult struct {
 	Confidence  float64 `json:"confidence"`
 }
 
+// cookStepsToolResult is the JSON structure returned by the time_cook_steps
+// tool call.
+type cookStepsToolResult struct {
+	Steps []struct {
+		Minutes   int    `json:"minutes"`
+		Equipment string `json:"equipment"`
+		DependsOn []int  `json:"depends_on"`
+	} `json:"steps"`
+}
+
+// substitutionsToolResult is the JSON structure returned by the
+// suggest_substitutions tool call.
+type substitutionsToolResult struct {
+	Substitutions []struct {
+		Ingredient  int     `json:"ingredient"`
+		Replacement string  `json:"replacement"`
+		Ratio       float64 `json:"ratio"`
+		Unit        string  `json:"unit"`
+		Addresses   string  `json:"addresses"`
+		Effect      string  `json:"effect"`
+		FromPantry  bool    `json:"from_pantry"`
+	} `json:"substitutions"`
+	Effect string `json:"effect"`
+}
+
 // dietaryProfileToolResult is the JSON structure returned by the
 // save_dietary_profile tool call.
 type dietaryProfileToolResult struct {
@@ -595,6 +730,7 @@ func toolResultToVoiceIntent(tr *voiceIntentToolResult) *VoiceIntent {
 		Amount: tr.Amount,
 		Target: tr.Target,
 		Text:   tr.Text,
+		Step:   tr.Step,
 	}
 }
 
@@ -624,6 +760,7 @@ type finderRankPayload struct {
 	CookingContext string                       `json:"cooking_context,omitempty"`
 	Requirements   string                       `json:"requirements,omitempty"`
 	DietSummary    string                       `json:"diet_summary,omitempty"`
+	Conversation   string                       `json:"conversation,omitempty"`
 	Candidates     []finderRankPayloadCandidate `json:"candidates"`
 }
 
@@ -647,6 +784,7 @@ func buildFinderRankPayload(req FinderRankRequest) finderRankPayload {
 		CookingContext: req.CookingContext,
 		Requirements:   req.Requirements,
 		DietSummary:    req.DietSummary,
+		Conversation:   req.Conversation,
 		Candidates:     candidates,
 	}
 }
@@ -878,14 +1016,11 @@ func (p *AnthropicProvider) createMessageWithRetry(ctx context.Context, params a
 func classifyAnthropicError(err error) *AIError {
 	var apiErr *anthropic.Error
 	if errors.As(err, &apiErr) {
-		switch apiErr.StatusCode {
-		case http.StatusTooManyRequests:
-			return NewAIError(FailureTransient, err, "rate limited")
-		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
-			return NewAIError(FailureTransient, err, "server error")
-		case http.StatusUnauthorized:
-			return NewAIError(FailureAuth, err, "unauthorized")
+		if aiErr := classifyHTTPStatus(apiErr.StatusCode, err); aiErr != nil {
+			return aiErr
 		}
+	} else if aiErr := classifyTransportError(err); aiErr != nil {
+		return aiErr
 	}
 	return NewAIError(FailureUnknown, err, "unknown API error")
 }
@@ -985,6 +1120,71 @@ func extractPortionFromToolUse(msg *anthropic.Message) (*PortionEstimate, error)
 	return nil, errors.New("no tool_use block found in Claude response")
 }
 
+// extractCookStepsFromToolUse parses the tool-use content block for step
+// timing, requiring one entry per step.
+func extractCookStepsFromToolUse(msg *anthropic.Message, steps int) ([]CookStepTiming, error) {
+	for _, block := range msg.Content {
+		if block.Type == "tool_use" {
+			raw, err := json.Marshal(block.Input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal tool input: %w", err)
+			}
+			var tr cookStepsToolResult
+			if err := json.Unmarshal(raw, &tr); err != nil {
+				return nil, fmt.Errorf("failed to parse cook steps tool result: %w", err)
+			}
+			if len(tr.Steps) != steps {
+				return nil, fmt.Errorf("cook steps tool timed %d steps, want %d", len(tr.Steps), steps)
+			}
+			timings := make([]CookStepTiming, len(tr.Steps))
+			for i, st := range tr.Steps {
+				timings[i] = CookStepTiming{Minutes: st.Minutes, DependsOn: st.DependsOn}
+				if st.Equipment == EquipmentOven || st.Equipment == EquipmentBurner {
+					timings[i].Equipment = st.Equipment
+				}
+			}
+			return timings, nil
+		}
+	}
+	return nil, errors.New("no tool_use block found in Claude response")
+}
+
+// extractSubstitutionsFromToolUse parses the tool-use content block for
+// substitutions, dropping entries that name no ingredient of the recipe or
+// have no usable quantity.
+func extractSubstitutionsFromToolUse(msg *anthropic.Message, ingredients int) (*SubstitutionResult, error) {
+	for _, block := range msg.Content {
+		if block.Type == "tool_use" {
+			raw, err := json.Marshal(block.Input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal tool input: %w", err)
+			}
+			var tr substitutionsToolResult
+			if err := json.Unmarshal(raw, &tr); err != nil {
+				return nil, fmt.Errorf("failed to parse substitutions tool result: %w", err)
+			}
+			result := &SubstitutionResult{Effect: strings.TrimSpace(tr.Effect)}
+			for _, sub := range tr.Substitutions {
+				replacement := strings.TrimSpace(sub.Replacement)
+				if sub.Ingredient < 1 || sub.Ingredient > ingredients || replacement == "" || sub.Ratio <= 0 {
+					continue
+				}
+				result.Suggestions = append(result.Suggestions, SubstitutionSuggestion{
+					Ingredient:  sub.Ingredient,
+					Replacement: replacement,
+					Ratio:       sub.Ratio,
+					Unit:        strings.TrimSpace(sub.Unit),
+					Addresses:   strings.TrimSpace(sub.Addresses),
+					Effect:      strings.TrimSpace(sub.Effect),
+					FromPantry:  sub.FromPantry,
+				})
+			}
+			return result, nil
+		}
+	}
+	return nil, errors.New("no tool_use block found in Claude response")
+}
+
 // extractFinderRankFromToolUse parses the tool-use content block for the recipe finder ranking.
 func extractFinderRankFromToolUse(msg *anthropic.Message) (*FinderRankResult, error) {
 	for _, block := range msg.Content {
@@ -1063,37 +1263,33 @@ func extractTextContent(msg *anthropic.Message) (string, error) {
 	return text, nil
 }
 
-// validateRecipeResult checks that a recipe has minimum required fields.
-func validateRecipeResult(r *RecipeResult) error {
-	if r.Title == "" {
-		return NewAIError(FailureContentQuality, fmt.Errorf("recipe missing title"), "recipe quality check failed")
-	}
-	if len(r.Ingredients) == 0 {
-		return NewAIError(FailureContentQuality, fmt.Errorf("recipe has no ingredients"), "recipe quality check failed")
-	}
-	if len(r.Instructions) == 0 {
-		return NewAIError(FailureContentQuality, fmt.Errorf("recipe has no instructions"), "recipe quality check failed")
-	}
-	return nil
-}
-
-// extractAndValidateRecipe is a helper that extracts a recipe from a tool-use
-// response and validates that it has the minimum required fields.
-func extractAndValidateRecipe(msg *anthropic.Message) (*RecipeResult, error) {
+// recipeFromToolUse parses a create_recipe response and runs it through
+// finishRecipe. The repair turn continues the same conversation: the model's
+// tool call, then a tool_result naming every invalid field.
+func (p *AnthropicProvider) recipeFromToolUse(ctx context.Context, params anthropic.MessageNewParams, msg *anthropic.Message) (*RecipeResult, error) {
 	result, err := extractRecipeFromToolUse(msg)
 	if err != nil {
 		return nil, err
 	}
-	if err := validateRecipeResult(result); err != nil {
-		aiErr := err.(*AIError)
-		logger.Get().Warn("AI operation failed",
-			zap.String("kind", aiErr.kindString()),
-			zap.String("detail", aiErr.Detail),
-			zap.Error(aiErr.Err),
+	return finishRecipe(ctx, result, func(ctx context.Context, feedback string) (*RecipeResult, error) {
+		toolUseID := ""
+		for _, block := range msg.Content {
+			if block.Type == "tool_use" {
+				toolUseID = block.ID
+				break
+			}
+		}
+		repair := params
+		repair.Messages = append(append([]anthropic.MessageParam{}, params.Messages...),
+			msg.ToParam(),
+			newUserMessage(anthropic.NewToolResultBlock(toolUseID, feedback, true)),
 		)
-		return nil, err
-	}
-	return result, nil
+		resp, err := p.createMessageWithRetry(ctx, repair)
+		if err != nil {
+			return nil, err
+		}
+		return extractRecipeFromToolUse(resp)
+	})
 }
 
 // buildCachedSystemPrompt creates system prompt TextBlockParams where the
@@ -1120,6 +1316,7 @@ func buildCachedSystemPrompt(staticPrefix string, dynamicSuffix string) []anthro
 
 // GenerateRecipe creates a new recipe via Claude tool use.
 func (p *AnthropicProvider) GenerateRecipe(ctx context.Context, req RecipeRequest) (*RecipeResult, error) {
+	prompts := p.prompts.For("GenerateRecipe")
 	op := AIOperation{
 		Name:      "GenerateRecipe",
 		Provider:  "anthropic",
@@ -1128,7 +1325,7 @@ func (p *AnthropicProvider) GenerateRecipe(ctx context.Context, req RecipeReques
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (*RecipeResult, error) {
-		sysSuffix, err := config.RenderPrompt(p.prompts.Recipe.Generate.System, map[string]interface{}{
+		sysSuffix, err := config.RenderPrompt(prompts.Recipe.Generate.System, map[string]interface{}{
 			"UnitSystem":     req.UnitSystem,
 			"Requirements":   req.Requirements,
 			"CookingContext": req.CookingContext,
@@ -1137,20 +1334,20 @@ func (p *AnthropicProvider) GenerateRecipe(ctx context.Context, req RecipeReques
 			return nil, fmt.Errorf("render system prompt: %w", err)
 		}
 
-		userPrompt, err := config.RenderPrompt(p.prompts.Recipe.Generate.User, map[string]interface{}{
+		userPrompt, err := config.RenderPrompt(prompts.Recipe.Generate.User, map[string]interface{}{
 			"Prompt": req.UserPrompt,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("render user prompt: %w", err)
 		}
 
-		summaryDesc := p.prompts.Recipe.Summarize.Recipe
+		summaryDesc := prompts.Recipe.Summarize.Recipe
 		tool := createRecipeTool(summaryDesc)
 
 		params := anthropic.MessageNewParams{
 			Model:     p.model,
 			MaxTokens: 4096,
-			System:    buildCachedSystemPrompt(p.prompts.Recipe.Generate.SystemPrefix, sysSuffix),
+			System:    buildCachedSystemPrompt(prompts.Recipe.Generate.SystemPrefix, sysSuffix),
 			Messages: []anthropic.MessageParam{
 				newUserMessage(anthropic.NewTextBlock(userPrompt)),
 			},
@@ -1167,11 +1364,11 @@ func (p *AnthropicProvider) GenerateRecipe(ctx context.Context, req RecipeReques
 			return nil, err
 		}
 
-		result, err := extractAndValidateRecipe(resp)
+		result, err := p.recipeFromToolUse(ctx, params, resp)
 		if err != nil {
 			return nil, err
 		}
-		result.PromptVersion = config.PromptVersion(p.prompts)
+		result.PromptVersion = config.PromptVersion(prompts)
 		return result, nil
 	})
 }
@@ -1180,6 +1377,7 @@ func (p *AnthropicProvider) GenerateRecipe(ctx context.Context, req RecipeReques
 // events to the provided channel. The channel is NOT closed by this method;
 // the caller is responsible for closing it after this returns.
 func (p *AnthropicProvider) StreamGenerateRecipe(ctx context.Context, req RecipeRequest, events chan<- StreamEvent) (*RecipeResult, error) {
+	prompts := p.prompts.For("GenerateRecipe")
 	op := AIOperation{
 		Name:      "StreamGenerateRecipe",
 		Provider:  "anthropic",
@@ -1188,6 +1386,9 @@ func (p *AnthropicProvider) StreamGenerateRecipe(ctx context.Context, req Recipe
 	}
 
 	// Middleware: manual Before/After since we can't use runWithMiddleware for streaming
+	op.Attribution = AttributionFromContext(ctx)
+	op.Fallback = fallbackInfoFromContext(ctx)
+	op.Experiment = experimentInfoFromContext(ctx)
 	if p.middleware != nil {
 		ctx = p.middleware.Before(ctx, op)
 	}
@@ -1202,7 +1403,7 @@ func (p *AnthropicProvider) StreamGenerateRecipe(ctx context.Context, req Recipe
 		}
 	}()
 
-	sysSuffix, err := config.RenderPrompt(p.prompts.Recipe.Generate.System, map[string]interface{}{
+	sysSuffix, err := config.RenderPrompt(prompts.Recipe.Generate.System, map[string]interface{}{
 		"UnitSystem":     req.UnitSystem,
 		"Requirements":   req.Requirements,
 		"CookingContext": req.CookingContext,
@@ -1212,7 +1413,7 @@ func (p *AnthropicProvider) StreamGenerateRecipe(ctx context.Context, req Recipe
 		return nil, finalErr
 	}
 
-	userPrompt, err := config.RenderPrompt(p.prompts.Recipe.Generate.User, map[string]interface{}{
+	userPrompt, err := config.RenderPrompt(prompts.Recipe.Generate.User, map[string]interface{}{
 		"Prompt": req.UserPrompt,
 	})
 	if err != nil {
@@ -1220,13 +1421,13 @@ func (p *AnthropicProvider) StreamGenerateRecipe(ctx context.Context, req Recipe
 		return nil, finalErr
 	}
 
-	summaryDesc := p.prompts.Recipe.Summarize.Recipe
+	summaryDesc := prompts.Recipe.Summarize.Recipe
 	tool := createRecipeTool(summaryDesc)
 
 	params := anthropic.MessageNewParams{
 		Model:     p.model,
 		MaxTokens: 4096,
-		System:    buildCachedSystemPrompt(p.prompts.Recipe.Generate.SystemPrefix, sysSuffix),
+		System:    buildCachedSystemPrompt(prompts.Recipe.Generate.SystemPrefix, sysSuffix),
 		Messages: []anthropic.MessageParam{
 			newUserMessage(anthropic.NewTextBlock(userPrompt)),
 		},
@@ -1285,7 +1486,7 @@ func (p *AnthropicProvider) StreamGenerateRecipe(ctx context.Context, req Recipe
 		return nil, finalErr
 	}
 
-	result, err := extractAndValidateRecipe(&message)
+	result, err := p.recipeFromToolUse(ctx, params, &message)
 	if err != nil {
 		finalErr = err
 		var aiErr *AIError
@@ -1299,12 +1500,13 @@ func (p *AnthropicProvider) StreamGenerateRecipe(ctx context.Context, req Recipe
 
 	// Do NOT emit StreamEventComplete here — the service layer emits it
 	// after persistence succeeds, preventing premature completion signals.
-	result.PromptVersion = config.PromptVersion(p.prompts)
+	result.PromptVersion = config.PromptVersion(prompts)
 	return result, nil
 }
 
 // RegenerateRecipe revises an existing recipe based on conversation history.
 func (p *AnthropicProvider) RegenerateRecipe(ctx context.Context, req RegenerateRequest) (*RecipeResult, error) {
+	prompts := p.prompts.For("RegenerateRecipe")
 	op := AIOperation{
 		Name:      "RegenerateRecipe",
 		Provider:  "anthropic",
@@ -1313,7 +1515,7 @@ func (p *AnthropicProvider) RegenerateRecipe(ctx context.Context, req Regenerate
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (*RecipeResult, error) {
-		sysSuffix, err := config.RenderPrompt(p.prompts.Recipe.Regenerate.System, map[string]interface{}{
+		sysSuffix, err := config.RenderPrompt(prompts.Recipe.Regenerate.System, map[string]interface{}{
 			"UnitSystem":     req.UnitSystem,
 			"Requirements":   req.Requirements,
 			"CookingContext": req.CookingContext,
@@ -1322,12 +1524,12 @@ func (p *AnthropicProvider) RegenerateRecipe(ctx context.Context, req Regenerate
 			return nil, fmt.Errorf("render system prompt: %w", err)
 		}
 
-		summaryDesc := p.prompts.Recipe.Summarize.Changes
+		summaryDesc := prompts.Recipe.Summarize.Changes
 		tool := createRecipeTool(summaryDesc)
 
 		// Build message list: existing history + new user prompt
 		_, historyParams := messagesToAnthropicParams(req.ExistingHistory)
-		userPrompt, err := config.RenderPrompt(p.prompts.Recipe.Regenerate.User, map[string]interface{}{
+		userPrompt, err := config.RenderPrompt(prompts.Recipe.Regenerate.User, map[string]interface{}{
 			"Prompt": req.UserPrompt,
 		})
 		if err != nil {
@@ -1338,7 +1540,7 @@ func (p *AnthropicProvider) RegenerateRecipe(ctx context.Context, req Regenerate
 		params := anthropic.MessageNewParams{
 			Model:     p.model,
 			MaxTokens: 4096,
-			System:    buildCachedSystemPrompt(p.prompts.Recipe.Regenerate.SystemPrefix, sysSuffix),
+			System:    buildCachedSystemPrompt(prompts.Recipe.Regenerate.SystemPrefix, sysSuffix),
 			Messages:  historyParams,
 			Tools:     []anthropic.ToolUnionParam{tool},
 			ToolChoice: anthropic.ToolChoiceUnionParam{
@@ -1353,17 +1555,18 @@ func (p *AnthropicProvider) RegenerateRecipe(ctx context.Context, req Regenerate
 			return nil, err
 		}
 
-		result, err := extractAndValidateRecipe(resp)
+		result, err := p.recipeFromToolUse(ctx, params, resp)
 		if err != nil {
 			return nil, err
 		}
-		result.PromptVersion = config.PromptVersion(p.prompts)
+		result.PromptVersion = config.PromptVersion(prompts)
 		return result, nil
 	})
 }
 
 // ForkRecipe creates a new recipe branched from an existing one.
 func (p *AnthropicProvider) ForkRecipe(ctx context.Context, req ForkRequest) (*RecipeResult, error) {
+	prompts := p.prompts.For("ForkRecipe")
 	op := AIOperation{
 		Name:      "ForkRecipe",
 		Provider:  "anthropic",
@@ -1372,7 +1575,7 @@ func (p *AnthropicProvider) ForkRecipe(ctx context.Context, req ForkRequest) (*R
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (*RecipeResult, error) {
-		sysSuffix, err := config.RenderPrompt(p.prompts.Recipe.Fork.System, map[string]interface{}{
+		sysSuffix, err := config.RenderPrompt(prompts.Recipe.Fork.System, map[string]interface{}{
 			"UnitSystem":     req.UnitSystem,
 			"Requirements":   req.Requirements,
 			"CookingContext": req.CookingContext,
@@ -1381,11 +1584,11 @@ func (p *AnthropicProvider) ForkRecipe(ctx context.Context, req ForkRequest) (*R
 			return nil, fmt.Errorf("render system prompt: %w", err)
 		}
 
-		summaryDesc := p.prompts.Recipe.Summarize.Recipe
+		summaryDesc := prompts.Recipe.Summarize.Recipe
 		tool := createRecipeTool(summaryDesc)
 
 		_, historyParams := messagesToAnthropicParams(req.ExistingHistory)
-		userPrompt, err := config.RenderPrompt(p.prompts.Recipe.Fork.User, map[string]interface{}{
+		userPrompt, err := config.RenderPrompt(prompts.Recipe.Fork.User, map[string]interface{}{
 			"Prompt": req.UserPrompt,
 		})
 		if err != nil {
@@ -1396,7 +1599,7 @@ func (p *AnthropicProvider) ForkRecipe(ctx context.Context, req ForkRequest) (*R
 		params := anthropic.MessageNewParams{
 			Model:     p.model,
 			MaxTokens: 4096,
-			System:    buildCachedSystemPrompt(p.prompts.Recipe.Fork.SystemPrefix, sysSuffix),
+			System:    buildCachedSystemPrompt(prompts.Recipe.Fork.SystemPrefix, sysSuffix),
 			Messages:  historyParams,
 			Tools:     []anthropic.ToolUnionParam{tool},
 			ToolChoice: anthropic.ToolChoiceUnionParam{
@@ -1411,17 +1614,18 @@ func (p *AnthropicProvider) ForkRecipe(ctx context.Context, req ForkRequest) (*R
 			return nil, err
 		}
 
-		result, err := extractAndValidateRecipe(resp)
+		result, err := p.recipeFromToolUse(ctx, params, resp)
 		if err != nil {
 			return nil, err
 		}
-		result.PromptVersion = config.PromptVersion(p.prompts)
+		result.PromptVersion = config.PromptVersion(prompts)
 		return result, nil
 	})
 }
 
 // AnalyzeAllergens analyses ingredients for allergen risks.
 func (p *AnthropicProvider) AnalyzeAllergens(ctx context.Context, req AllergenRequest) (*AllergenResult, error) {
+	prompts := p.prompts.For("AnalyzeAllergens")
 	op := AIOperation{
 		Name:      "AnalyzeAllergens",
 		Provider:  "anthropic",
@@ -1430,13 +1634,13 @@ func (p *AnthropicProvider) AnalyzeAllergens(ctx context.Context, req AllergenRe
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (*AllergenResult, error) {
-		sysPrompt, err := config.RenderPrompt(p.prompts.Allergen.Analyze.System, nil)
+		sysPrompt, err := config.RenderPrompt(prompts.Allergen.Analyze.System, nil)
 		if err != nil {
 			return nil, fmt.Errorf("render system prompt: %w", err)
 		}
 
 		ingredientList, _ := json.Marshal(req.Ingredients)
-		userPrompt, err := config.RenderPrompt(p.prompts.Allergen.Analyze.User, map[string]interface{}{
+		userPrompt, err := config.RenderPrompt(prompts.Allergen.Analyze.User, map[string]interface{}{
 			"Ingredients": string(ingredientList),
 			"IsPremium":   req.IsPremium,
 		})
@@ -1472,6 +1676,7 @@ func (p *AnthropicProvider) AnalyzeAllergens(ctx context.Context, req AllergenRe
 
 // ClassifyVoiceIntent classifies a voice transcript into an app intent.
 func (p *AnthropicProvider) ClassifyVoiceIntent(ctx context.Context, transcript string) (*VoiceIntent, error) {
+	prompts := p.prompts.For("ClassifyVoiceIntent")
 	op := AIOperation{
 		Name:      "ClassifyVoiceIntent",
 		Provider:  "anthropic",
@@ -1480,7 +1685,7 @@ func (p *AnthropicProvider) ClassifyVoiceIntent(ctx context.Context, transcript
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (*VoiceIntent, error) {
-		sysPrompt, err := config.RenderPrompt(p.prompts.Voice.Intent.System, nil)
+		sysPrompt, err := config.RenderPrompt(prompts.Voice.Intent.System, nil)
 		if err != nil {
 			return nil, fmt.Errorf("render system prompt: %w", err)
 		}
@@ -1552,6 +1757,107 @@ func (p *AnthropicProvider) EstimatePortions(ctx context.Context, recipeDef inte
 	})
 }
 
+// AnalyzeCookSteps times a recipe's steps for multi-dish cook plans via a
+// forced time_cook_steps tool call.
+func (p *AnthropicProvider) AnalyzeCookSteps(ctx context.Context, req CookStepsRequest) ([]CookStepTiming, error) {
+	op := AIOperation{
+		Name:      "AnalyzeCookSteps",
+		Provider:  "anthropic",
+		Model:     string(p.model),
+		StartTime: time.Now(),
+	}
+
+	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) ([]CookStepTiming, error) {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Recipe: %s\n", req.Title)
+		for i, step := range req.Steps {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+		}
+
+		params := anthropic.MessageNewParams{
+			Model:     p.model,
+			MaxTokens: 1024,
+			System:    buildCachedSystemPrompt("You are a culinary expert planning when to cook each step of a recipe. For every step, estimate its duration in minutes, the oven or stovetop burner it occupies, and which earlier steps must be finished first. Steps that only need ingredients prepared by other steps depend on those steps; independent prep can run in parallel.", ""),
+			Messages: []anthropic.MessageParam{
+				newUserMessage(anthropic.NewTextBlock(b.String())),
+			},
+			Tools: []anthropic.ToolUnionParam{timeCookStepsTool()},
+			ToolChoice: anthropic.ToolChoiceUnionParam{
+				OfToolChoiceTool: &anthropic.ToolChoiceToolParam{
+					Name: "time_cook_steps",
+				},
+			},
+		}
+
+		resp, err := p.createMessageWithRetry(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		return extractCookStepsFromToolUse(resp, len(req.Steps))
+	})
+}
+
+// SuggestSubstitutions proposes ingredient swaps for a recipe via a forced
+// suggest_substitutions tool call.
+func (p *AnthropicProvider) SuggestSubstitutions(ctx context.Context, req SubstitutionRequest) (*SubstitutionResult, error) {
+	op := AIOperation{
+		Name:      "SuggestSubstitutions",
+		Provider:  "anthropic",
+		Model:     string(p.model),
+		StartTime: time.Now(),
+	}
+
+	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (*SubstitutionResult, error) {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Recipe: %s\n\nIngredients:\n", req.Title)
+		for i, ing := range req.Ingredients {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, ing)
+		}
+		if len(req.Instructions) > 0 {
+			b.WriteString("\nSteps:\n")
+			for i, step := range req.Instructions {
+				fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+			}
+		}
+		b.WriteString("\nMust avoid:\n")
+		for _, avoid := range req.Avoid {
+			b.WriteString("- " + avoid + "\n")
+		}
+		if len(req.AllergenNotes) > 0 {
+			b.WriteString("\nKnown allergens by ingredient:\n")
+			for _, note := range req.AllergenNotes {
+				b.WriteString("- " + note + "\n")
+			}
+		}
+		if len(req.Pantry) > 0 {
+			b.WriteString("\nOn hand: " + strings.Join(req.Pantry, ", ") + "\n")
+		}
+
+		params := anthropic.MessageNewParams{
+			Model:     p.model,
+			MaxTokens: 2048,
+			System:    buildCachedSystemPrompt("You are a culinary expert adapting a recipe for dietary needs. Replace every ingredient that conflicts with what must be avoided, including hidden sources (e.g. butter and whey for a milk allergy, and any allergen the known-allergen notes list), and nothing else. Prefer replacements the cook has on hand. Give each replacement's quantity as a ratio to the original so the dish keeps its proportions, and never suggest a replacement that conflicts with another constraint.", ""),
+			Messages: []anthropic.MessageParam{
+				newUserMessage(anthropic.NewTextBlock(b.String())),
+			},
+			Tools: []anthropic.ToolUnionParam{suggestSubstitutionsTool()},
+			ToolChoice: anthropic.ToolChoiceUnionParam{
+				OfToolChoiceTool: &anthropic.ToolChoiceToolParam{
+					Name: "suggest_substitutions",
+				},
+			},
+		}
+
+		resp, err := p.createMessageWithRetry(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		return extractSubstitutionsFromToolUse(resp, len(req.Ingredients))
+	})
+}
+
 // ExpandAndRankRecipes performs the recipe finder's single ranking call via a
 // forced rank_recipes tool call. Mirrors the light-tier structured-call pattern
 // of EstimatePortions / ClassifyVoiceIntent: an inline system prompt, a modest
@@ -1605,6 +1911,7 @@ func (p *AnthropicProvider) ExpandAndRankRecipes(ctx context.Context, req Finder
 
 // ExtractRecipeFromText extracts a structured recipe from free-form text.
 func (p *AnthropicProvider) ExtractRecipeFromText(ctx context.Context, text string, unitSystem string) (*RecipeResult, error) {
+	prompts := p.prompts.For("ExtractRecipeFromText")
 	op := AIOperation{
 		Name:      "ExtractRecipeFromText",
 		Provider:  "anthropic",
@@ -1618,16 +1925,17 @@ func (p *AnthropicProvider) ExtractRecipeFromText(ctx context.Context, text stri
 		var templateData map[string]interface{}
 
 		if unitSystem == UnitSystemPreserveSource {
-			sysPrefix = p.prompts.Import.URL.SystemPrefix
-			promptTemplate = p.prompts.Import.URL.System
+			sysPrefix = prompts.Import.URL.SystemPrefix
+			promptTemplate = prompts.Import.URL.System
 			templateData = map[string]interface{}{
 				"UnitSystem": "the original units from the source text. Do not convert measurements. Report which unit system is used via the unit_system field",
 			}
 		} else {
-			sysPrefix = p.prompts.Import.Text.SystemPrefix
-			promptTemplate = p.prompts.Import.Text.System
+			sysPrefix = prompts.Import.Text.SystemPrefix
+			promptTemplate = prompts.Import.Text.System
 			templateData = map[string]interface{}{
 				"UnitSystem": unitSystem,
+				"Locale":     LocaleFromContext(ctx).Market(),
 			}
 		}
 
@@ -1636,7 +1944,7 @@ func (p *AnthropicProvider) ExtractRecipeFromText(ctx context.Context, text stri
 			return nil, fmt.Errorf("render system prompt: %w", err)
 		}
 
-		summaryDesc := p.prompts.Recipe.Summarize.Recipe
+		summaryDesc := prompts.Recipe.Summarize.Recipe
 		tool := createRecipeTool(summaryDesc)
 
 		params := anthropic.MessageNewParams{
@@ -1659,17 +1967,18 @@ func (p *AnthropicProvider) ExtractRecipeFromText(ctx context.Context, text stri
 			return nil, err
 		}
 
-		result, err := extractAndValidateRecipe(resp)
+		result, err := p.recipeFromToolUse(ctx, params, resp)
 		if err != nil {
 			return nil, err
 		}
-		result.PromptVersion = config.PromptVersion(p.prompts)
+		result.PromptVersion = config.PromptVersion(prompts)
 		return result, nil
 	})
 }
 
 // CookingQA answers a cooking question with optional recipe context.
 func (p *AnthropicProvider) CookingQA(ctx context.Context, question string, recipeContext string) (string, error) {
+	prompts := p.prompts.For("CookingQA")
 	op := AIOperation{
 		Name:      "CookingQA",
 		Provider:  "anthropic",
@@ -1678,7 +1987,7 @@ func (p *AnthropicProvider) CookingQA(ctx context.Context, question string, reci
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (string, error) {
-		sysSuffix, err := config.RenderPrompt(p.prompts.CookingQA.System, map[string]interface{}{
+		sysSuffix, err := config.RenderPrompt(prompts.CookingQA.System, map[string]interface{}{
 			"RecipeContext": recipeContext,
 		})
 		if err != nil {
@@ -1688,7 +1997,7 @@ func (p *AnthropicProvider) CookingQA(ctx context.Context, question string, reci
 		params := anthropic.MessageNewParams{
 			Model:     p.model,
 			MaxTokens: 1024,
-			System:    buildCachedSystemPrompt(p.prompts.CookingQA.SystemPrefix, sysSuffix),
+			System:    buildCachedSystemPrompt(prompts.CookingQA.SystemPrefix, sysSuffix),
 			Messages: []anthropic.MessageParam{
 				newUserMessage(anthropic.NewTextBlock(question)),
 			},
@@ -1703,11 +2012,66 @@ func (p *AnthropicProvider) CookingQA(ctx context.Context, question string, reci
 	})
 }
 
+// StreamCookingChat answers a cooking question in the context of the
+// conversation so far, streaming the answer's text deltas to onDelta. It uses
+// the CookingQA prompt and is metered as a CookingQA call. A stream that
+// fails is not retried: the client has already seen part of the answer.
+func (p *AnthropicProvider) StreamCookingChat(ctx context.Context, req CookingChatRequest, onDelta func(string)) (string, error) {
+	prompts := p.prompts.For("CookingQA")
+	op := AIOperation{
+		Name:      "CookingQA",
+		Provider:  "anthropic",
+		Model:     string(p.model),
+		StartTime: time.Now(),
+	}
+
+	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (string, error) {
+		sysSuffix, err := config.RenderPrompt(prompts.CookingQA.System, map[string]interface{}{
+			"RecipeContext": req.RecipeContext,
+		})
+		if err != nil {
+			return "", fmt.Errorf("render system prompt: %w", err)
+		}
+
+		_, messages := messagesToAnthropicParams(req.History)
+		params := anthropic.MessageNewParams{
+			Model:     p.model,
+			MaxTokens: 1024,
+			System:    buildCachedSystemPrompt(prompts.CookingQA.SystemPrefix, sysSuffix),
+			Messages:  append(messages, newUserMessage(anthropic.NewTextBlock(req.Question))),
+		}
+
+		stream := p.client.Messages.NewStreaming(ctx, params)
+		defer stream.Close()
+
+		var message anthropic.Message
+		for stream.Next() {
+			event := stream.Current()
+			if err := message.Accumulate(event); err != nil {
+				return "", NewAIError(FailureContentParse, err, "failed to accumulate stream event")
+			}
+			if event.Type == "content_block_delta" && event.Delta.Text != "" {
+				onDelta(event.Delta.Text)
+			}
+		}
+		if err := stream.Err(); err != nil {
+			return "", classifyAnthropicError(err)
+		}
+		recordUsage(ctx, TokenUsage{
+			InputTokens:  int(message.Usage.InputTokens),
+			OutputTokens: int(message.Usage.OutputTokens),
+		})
+
+		return extractTextContent(&message)
+	})
+}
+
 // DietaryInterview conducts a multi-turn dietary interview. The model is
 // given the save_dietary_profile tool with tool choice left on auto: it keeps
 // asking questions (plain text turns) until it has gathered enough
 // information, then calls the tool to emit the structured profile.
 func (p *AnthropicProvider) DietaryInterview(ctx context.Context, messages []Message, memberName string) (*DietaryInterviewResult, error) {
+	prompts := p.prompts.For("DietaryInterview")
 	op := AIOperation{
 		Name:      "DietaryInterview",
 		Provider:  "anthropic",
@@ -1716,7 +2080,7 @@ func (p *AnthropicProvider) DietaryInterview(ctx context.Context, messages []Mes
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (*DietaryInterviewResult, error) {
-		sysSuffix, err := config.RenderPrompt(p.prompts.DietaryInterview.System, map[string]interface{}{
+		sysSuffix, err := config.RenderPrompt(prompts.DietaryInterview.System, map[string]interface{}{
 			"MemberName": memberName,
 		})
 		if err != nil {
@@ -1730,7 +2094,7 @@ func (p *AnthropicProvider) DietaryInterview(ctx context.Context, messages []Mes
 		params := anthropic.MessageNewParams{
 			Model:     p.model,
 			MaxTokens: 1024,
-			System:    buildCachedSystemPrompt(p.prompts.DietaryInterview.SystemPrefix, sysSuffix),
+			System:    buildCachedSystemPrompt(prompts.DietaryInterview.SystemPrefix, sysSuffix),
 			Messages:  msgParams,
 			Tools:     []anthropic.ToolUnionParam{saveDietaryProfileTool()},
 		}
@@ -1748,6 +2112,7 @@ func (p *AnthropicProvider) DietaryInterview(ctx context.Context, messages []Mes
 
 // ExtractRecipeFromImage extracts a structured recipe from a photo.
 func (p *AnthropicProvider) ExtractRecipeFromImage(ctx context.Context, imageData []byte, unitSystem string, requirements string) (*RecipeResult, error) {
+	prompts := p.prompts.For("ExtractRecipeFromImage")
 	op := AIOperation{
 		Name:      "ExtractRecipeFromImage",
 		Provider:  "anthropic",
@@ -1756,9 +2121,10 @@ func (p *AnthropicProvider) ExtractRecipeFromImage(ctx context.Context, imageDat
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (*RecipeResult, error) {
-		sysSuffix, err := config.RenderPrompt(p.prompts.Recipe.Import.Vision.System, map[string]interface{}{
+		sysSuffix, err := config.RenderPrompt(prompts.Recipe.Import.Vision.System, map[string]interface{}{
 			"UnitSystem":   unitSystem,
 			"Requirements": requirements,
+			"Locale":       LocaleFromContext(ctx).Market(),
 		})
 		if err != nil {
 			return nil, fmt.Errorf("render system prompt: %w", err)
@@ -1767,13 +2133,13 @@ func (p *AnthropicProvider) ExtractRecipeFromImage(ctx context.Context, imageDat
 		b64 := base64.StdEncoding.EncodeToString(imageData)
 		mediaType := detectImageMediaType(imageData)
 
-		summaryDesc := p.prompts.Recipe.Summarize.Recipe
+		summaryDesc := prompts.Recipe.Summarize.Recipe
 		tool := createRecipeTool(summaryDesc)
 
 		params := anthropic.MessageNewParams{
 			Model:     p.model,
 			MaxTokens: 4096,
-			System:    buildCachedSystemPrompt(p.prompts.Recipe.Import.Vision.SystemPrefix, sysSuffix),
+			System:    buildCachedSystemPrompt(prompts.Recipe.Import.Vision.SystemPrefix, sysSuffix),
 			Messages: []anthropic.MessageParam{
 				newUserMessage(
 					anthropic.ContentBlockParamUnion{
@@ -1802,11 +2168,11 @@ func (p *AnthropicProvider) ExtractRecipeFromImage(ctx context.Context, imageDat
 			return nil, err
 		}
 
-		result, err := extractAndValidateRecipe(resp)
+		result, err := p.recipeFromToolUse(ctx, params, resp)
 		if err != nil {
 			return nil, err
 		}
-		result.PromptVersion = config.PromptVersion(p.prompts)
+		result.PromptVersion = config.PromptVersion(prompts)
 		return result, nil
 	})
 }
@@ -1814,6 +2180,7 @@ func (p *AnthropicProvider) ExtractRecipeFromImage(ctx context.Context, imageDat
 // ExtractRecipesFromMedia extracts every distinct recipe found across the
 // provided images and/or PDF documents in a single Claude request.
 func (p *AnthropicProvider) ExtractRecipesFromMedia(ctx context.Context, media []MediaInput, contextText string, unitSystem string, requirements string) ([]*RecipeResult, error) {
+	prompts := p.prompts.For("ExtractRecipesFromMedia")
 	op := AIOperation{
 		Name:      "ExtractRecipesFromMedia",
 		Provider:  "anthropic",
@@ -1822,9 +2189,10 @@ func (p *AnthropicProvider) ExtractRecipesFromMedia(ctx context.Context, media [
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) ([]*RecipeResult, error) {
-		sysSuffix, err := config.RenderPrompt(p.prompts.Recipe.Import.Vision.System, map[string]interface{}{
+		sysSuffix, err := config.RenderPrompt(prompts.Recipe.Import.Vision.System, map[string]interface{}{
 			"UnitSystem":   unitSystem,
 			"Requirements": requirements,
+			"Locale":       LocaleFromContext(ctx).Market(),
 		})
 		if err != nil {
 			return nil, fmt.Errorf("render system prompt: %w", err)
@@ -1860,13 +2228,13 @@ func (p *AnthropicProvider) ExtractRecipesFromMedia(ctx context.Context, media [
 		}
 		blocks = append(blocks, anthropic.NewTextBlock("These images and/or documents contain one or more recipes. Extract EVERY distinct recipe you find across all of them, returning one entry per recipe. If an item shows a prepared dish with no written recipe, infer a reasonable recipe for it."))
 
-		summaryDesc := p.prompts.Recipe.Summarize.Recipe
+		summaryDesc := prompts.Recipe.Summarize.Recipe
 		tool := createMultiRecipeTool(summaryDesc)
 
 		params := anthropic.MessageNewParams{
 			Model:     p.model,
 			MaxTokens: 16000,
-			System:    buildCachedSystemPrompt(p.prompts.Recipe.Import.Vision.SystemPrefix, sysSuffix),
+			System:    buildCachedSystemPrompt(prompts.Recipe.Import.Vision.SystemPrefix, sysSuffix),
 			Messages: []anthropic.MessageParam{
 				newUserMessage(blocks...),
 			},
@@ -1888,11 +2256,12 @@ func (p *AnthropicProvider) ExtractRecipesFromMedia(ctx context.Context, media [
 			return nil, err
 		}
 
-		pv := config.PromptVersion(p.prompts)
+		pv := config.PromptVersion(prompts)
 		valid := make([]*RecipeResult, 0, len(results))
 		for _, r := range results {
-			if err := validateRecipeResult(r); err != nil {
-				logger.Get().Warn("skipping invalid extracted recipe", zap.String("title", r.Title), zap.Error(err))
+			r, err := finishRecipe(ctx, r, nil)
+			if err != nil {
+				logger.Get().Warn("skipping invalid extracted recipe", zap.Error(err))
 				continue
 			}
 			r.PromptVersion = pv
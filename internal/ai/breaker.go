@@ -0,0 +1,178 @@
+package ai
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a CircuitBreaker.
+type BreakerState string
+
+const (
+	// BreakerClosed lets every call through.
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen rejects calls until the cooldown elapses.
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen lets a single probe call through; its outcome closes or
+	// re-opens the breaker.
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// Breaker defaults used when a caller passes a non-positive threshold/cooldown.
+const (
+	defaultBreakerThreshold = 3
+	defaultBreakerCooldown  = 2 * time.Minute
+)
+
+// CircuitBreaker trips after Threshold consecutive failures and stays open for
+// Cooldown, after which exactly one half-open probe is allowed through. A
+// successful probe closes it; a failed probe re-opens it for another cooldown.
+// Safe for concurrent use.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	// now is overridable in tests.
+	now func() time.Time
+
+	mu           sync.Mutex
+	state        BreakerState
+	failures     int
+	openedAt     time.Time
+	probing      bool
+	lastError    string
+	lastFailedAt time.Time
+}
+
+// BreakerSnapshot is a point-in-time view of a breaker, for admin/health APIs.
+type BreakerSnapshot struct {
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	LastError           string       `json:"last_error,omitempty"`
+	LastFailedAt        *time.Time   `json:"last_failed_at,omitempty"`
+}
+
+// NewCircuitBreaker creates a closed breaker. Non-positive arguments fall back
+// to the defaults (3 failures, 2 minute cooldown).
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = defaultBreakerThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     BreakerClosed,
+	}
+}
+
+// Allow reports whether a call may proceed. An open breaker whose cooldown has
+// elapsed moves to half-open and admits one probe; concurrent callers are
+// rejected until that probe reports back.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerClosed:
+		return true
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// RecordSuccess closes the breaker and clears the failure streak.
+func (b *CircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Release ends a call whose outcome says nothing about the provider's health,
+// such as a rejected input or a caller that gave up. The failure streak is
+// left alone, and a half-open breaker admits its next probe.
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// RecordFailure counts a failure. A failed half-open probe, or reaching the
+// threshold while closed, opens the breaker.
+func (b *CircuitBreaker) RecordFailure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	b.failures++
+	b.lastFailedAt = now
+	if err != nil {
+		b.lastError = err.Error()
+	}
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = now
+		b.probing = false
+	}
+}
+
+// Trip opens the breaker immediately regardless of the failure streak — used
+// for failures that are known to persist (e.g. an exhausted quota).
+func (b *CircuitBreaker) Trip(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	b.state = BreakerOpen
+	b.openedAt = now
+	b.lastFailedAt = now
+	b.probing = false
+	if b.failures < b.threshold {
+		b.failures = b.threshold
+	}
+	if err != nil {
+		b.lastError = err.Error()
+	}
+}
+
+// State returns the current state without admitting a probe.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Snapshot returns a copy of the breaker's state for reporting.
+func (b *CircuitBreaker) Snapshot() BreakerSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	snap := BreakerSnapshot{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+	}
+	if b.state != BreakerClosed {
+		t := b.openedAt
+		snap.OpenedAt = &t
+	}
+	if !b.lastFailedAt.IsZero() {
+		t := b.lastFailedAt
+		snap.LastFailedAt = &t
+	}
+	return snap
+}
@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_TripsAfterThresholdAndProbes(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	b := NewCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.RecordFailure(errors.New("boom"))
+	if !b.Allow() {
+		t.Fatal("breaker should stay closed below the threshold")
+	}
+	b.RecordFailure(errors.New("boom"))
+	if b.State() != BreakerOpen {
+		t.Fatalf("state = %s, want open", b.State())
+	}
+	if b.Allow() {
+		t.Fatal("open breaker must reject calls during cooldown")
+	}
+
+	now = now.Add(time.Minute)
+	if !b.Allow() {
+		t.Fatal("breaker should admit a probe after cooldown")
+	}
+	if b.Allow() {
+		t.Fatal("only one half-open probe may be in flight")
+	}
+
+	b.RecordFailure(errors.New("still down"))
+	if b.State() != BreakerOpen {
+		t.Fatalf("failed probe should re-open, got %s", b.State())
+	}
+
+	now = now.Add(time.Minute)
+	if !b.Allow() {
+		t.Fatal("breaker should admit a second probe")
+	}
+	b.RecordSuccess()
+	if b.State() != BreakerClosed || !b.Allow() {
+		t.Fatal("successful probe should close the breaker")
+	}
+	if snap := b.Snapshot(); snap.ConsecutiveFailures != 0 || snap.LastError != "still down" {
+		t.Errorf("snapshot = %+v", snap)
+	}
+}
+
+func TestCircuitBreaker_TripOpensImmediately(t *testing.T) {
+	b := NewCircuitBreaker(5, time.Hour)
+	b.Trip(ErrSearchQuotaExhausted)
+	if b.Allow() {
+		t.Fatal("tripped breaker must reject calls")
+	}
+	snap := b.Snapshot()
+	if snap.State != BreakerOpen || snap.OpenedAt == nil {
+		t.Errorf("snapshot = %+v, want open with opened_at", snap)
+	}
+}
+
+func TestCircuitBreaker_ReleaseEndsProbeWithoutVerdict(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	b := NewCircuitBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.RecordFailure(errors.New("boom"))
+	now = now.Add(time.Minute)
+	if !b.Allow() {
+		t.Fatal("breaker should admit a probe after cooldown")
+	}
+	b.Release()
+	if b.State() != BreakerHalfOpen {
+		t.Fatalf("state = %s, want still half-open", b.State())
+	}
+	if !b.Allow() {
+		t.Fatal("a released probe should let the next probe through")
+	}
+}
+
+func TestNewCircuitBreaker_Defaults(t *testing.T) {
+	b := NewCircuitBreaker(0, 0)
+	if b.threshold != defaultBreakerThreshold || b.cooldown != defaultBreakerCooldown {
+		t.Errorf("defaults = %d/%s", b.threshold, b.cooldown)
+	}
+}
@@ -0,0 +1,235 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrCassetteMiss is returned by a ReplayProvider asked for a call its
+// cassette holds no recording of.
+var ErrCassetteMiss = errors.New("no recorded AI interaction")
+
+// cassetteFormat is bumped if the file layout changes incompatibly.
+const cassetteFormat = 1
+
+// redacted replaces every secret removed from a recording.
+const redacted = "[REDACTED]"
+
+// secretPatterns match credentials that could surface in a prompt or model
+// output even though API keys never reach the recorded layer directly.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`sk-[A-Za-z0-9_-]{16,}`),
+	regexp.MustCompile(`AIza[0-9A-Za-z_-]{30,}`),
+	regexp.MustCompile(`(?i)bearer [A-Za-z0-9._~+/=-]{16,}`),
+}
+
+// Interaction is one recorded provider call. Key identifies the normalized
+// request; Request is kept alongside it so cassettes stay reviewable. Binary
+// inputs (images, audio, video, documents) are recorded by digest only.
+type Interaction struct {
+	Operation string          `json:"operation"`
+	Key       string          `json:"key"`
+	Request   json.RawMessage `json:"request"`
+	Response  json.RawMessage `json:"response,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	ErrorKind *AIFailureKind  `json:"error_kind,omitempty"`
+}
+
+// Cassette is a file of recorded provider calls, written by a Recorder and
+// served by a ReplayProvider. Safe for concurrent use.
+type Cassette struct {
+	path    string
+	secrets []string
+	saveMu  sync.Mutex
+
+	mu           sync.RWMutex
+	interactions []Interaction
+	byKey        map[string]int
+	lastByOp     map[string]int
+}
+
+type cassetteFile struct {
+	Format       int           `json:"format"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadCassette reads the cassette at path. A missing file yields an empty
+// cassette that Save will create, so recording can start from nothing.
+// secrets (the configured API keys) are scrubbed from everything recorded.
+func LoadCassette(path string, secrets ...string) (*Cassette, error) {
+	c := &Cassette{path: path, byKey: map[string]int{}, lastByOp: map[string]int{}}
+	for _, s := range secrets {
+		if len(s) >= 8 {
+			c.secrets = append(c.secrets, s)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+	var f cassetteFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse cassette %s: %w", path, err)
+	}
+	if f.Format != cassetteFormat {
+		return nil, fmt.Errorf("cassette %s: unsupported format %d", path, f.Format)
+	}
+	for _, in := range f.Interactions {
+		c.putLocked(in)
+	}
+	return c, nil
+}
+
+// Len returns the number of recorded interactions.
+func (c *Cassette) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.interactions)
+}
+
+// Save writes the cassette to its file, replacing it atomically.
+func (c *Cassette) Save() error {
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+	c.mu.RLock()
+	data, err := json.MarshalIndent(cassetteFile{Format: cassetteFormat, Interactions: c.interactions}, "", "  ")
+	c.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(c.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create cassette dir: %w", err)
+		}
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// record adds (or replaces) the interaction for a call and saves the file.
+func (c *Cassette) record(op string, req interface{}, resp interface{}, callErr error) error {
+	reqJSON, key, err := c.normalize(op, req)
+	if err != nil {
+		return err
+	}
+	in := Interaction{Operation: op, Key: key, Request: reqJSON}
+	if callErr != nil {
+		in.Error = c.scrub(callErr.Error())
+		if errors.Is(callErr, ErrVideoTooLarge) {
+			in.Error = ErrVideoTooLarge.Error()
+		}
+		var aiErr *AIError
+		if errors.As(callErr, &aiErr) {
+			kind := aiErr.Kind
+			in.ErrorKind = &kind
+		}
+	} else {
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return fmt.Errorf("encode %s response: %w", op, err)
+		}
+		in.Response = json.RawMessage(c.scrub(string(data)))
+	}
+	c.mu.Lock()
+	c.putLocked(in)
+	c.mu.Unlock()
+	return c.Save()
+}
+
+// lookup finds the interaction recorded for a call. When strict is false and
+// the exact request was never recorded, the latest recording of the same
+// operation stands in, so a local stack keeps serving plausible responses to
+// requests that differ from the recorded ones.
+func (c *Cassette) lookup(op string, req interface{}, strict bool) (Interaction, error) {
+	_, key, err := c.normalize(op, req)
+	if err != nil {
+		return Interaction{}, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if i, ok := c.byKey[key]; ok {
+		return c.interactions[i], nil
+	}
+	if !strict {
+		if i, ok := c.lastByOp[op]; ok {
+			return c.interactions[i], nil
+		}
+	}
+	return Interaction{}, fmt.Errorf("%w: %s %s", ErrCassetteMiss, op, key)
+}
+
+func (c *Cassette) putLocked(in Interaction) {
+	if i, ok := c.byKey[in.Key]; ok {
+		c.interactions[i] = in
+	} else {
+		c.interactions = append(c.interactions, in)
+		c.byKey[in.Key] = len(c.interactions) - 1
+	}
+	c.lastByOp[in.Operation] = c.byKey[in.Key]
+}
+
+// normalize renders a request as scrubbed JSON and derives its key. The key
+// is taken after scrubbing, so a cassette recorded with one set of keys
+// replays under another.
+func (c *Cassette) normalize(op string, req interface{}) (json.RawMessage, string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("encode %s request: %w", op, err)
+	}
+	clean := c.scrub(string(data))
+	sum := sha256.Sum256([]byte(op + "\n" + clean))
+	return json.RawMessage(clean), hex.EncodeToString(sum[:12]), nil
+}
+
+// scrub removes configured secrets and anything shaped like a credential.
+func (c *Cassette) scrub(s string) string {
+	for _, secret := range c.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	for _, re := range secretPatterns {
+		s = re.ReplaceAllString(s, redacted)
+	}
+	return s
+}
+
+// digest stands in for binary input in a recorded request.
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("sha256:%s (%d bytes)", hex.EncodeToString(sum[:]), len(data))
+}
+
+// mediaDigests records media inputs by kind and digest.
+func mediaDigests(media []MediaInput) []string {
+	out := make([]string, len(media))
+	for i, m := range media {
+		out[i] = string(m.Kind) + " " + digest(m.Data)
+	}
+	return out
+}
+
+// replayError rebuilds a recorded failure, keeping its AIError
+// classification and the package sentinels callers test for.
+func replayError(in Interaction) error {
+	if in.Error == ErrVideoTooLarge.Error() {
+		return ErrVideoTooLarge
+	}
+	err := errors.New(in.Error)
+	if in.ErrorKind != nil {
+		return NewAIError(*in.ErrorKind, err, "replayed "+in.Operation)
+	}
+	return err
+}
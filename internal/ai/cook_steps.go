@@ -0,0 +1,59 @@
+package ai
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrCookStepsUnsupported is returned by AnalyzeCookSteps when the provider
+// cannot time recipe steps; callers fall back to reading the step text.
+var ErrCookStepsUnsupported = errors.New("provider does not analyze cook steps")
+
+// Equipment a cook step occupies, as reported in CookStepTiming.
+const (
+	EquipmentOven   = "oven"
+	EquipmentBurner = "burner"
+)
+
+// CookStepsRequest asks for the timing of a recipe's steps.
+type CookStepsRequest struct {
+	Title string
+	Steps []string
+}
+
+// CookStepTiming is how long one recipe step takes and what it needs.
+// DependsOn lists the 1-based steps of the same recipe that must finish
+// before this one starts; steps that depend on nothing can run in parallel.
+type CookStepTiming struct {
+	Minutes   int
+	Equipment string // EquipmentOven, EquipmentBurner, or empty
+	DependsOn []int
+}
+
+// CookStepAnalyzer is implemented by text providers that can time a recipe's
+// steps, returning one CookStepTiming per step, in order.
+type CookStepAnalyzer interface {
+	AnalyzeCookSteps(ctx context.Context, req CookStepsRequest) ([]CookStepTiming, error)
+}
+
+// Compile-time checks for the providers and wrappers that analyze cook
+// steps.
+var (
+	_ CookStepAnalyzer = (*AnthropicProvider)(nil)
+	_ CookStepAnalyzer = (*SwitchableTextProvider)(nil)
+	_ CookStepAnalyzer = (*RoutedTextProvider)(nil)
+	_ CookStepAnalyzer = (*ExperimentTextProvider)(nil)
+	_ CookStepAnalyzer = (*FallbackTextProvider)(nil)
+	_ CookStepAnalyzer = (*recordingText)(nil)
+	_ CookStepAnalyzer = (*ReplayProvider)(nil)
+)
+
+// AnalyzeCookSteps times req's steps with p, or returns
+// ErrCookStepsUnsupported when p does not implement CookStepAnalyzer.
+func AnalyzeCookSteps(ctx context.Context, p TextProvider, req CookStepsRequest) ([]CookStepTiming, error) {
+	a, ok := p.(CookStepAnalyzer)
+	if !ok {
+		return nil, ErrCookStepsUnsupported
+	}
+	return a.AnalyzeCookSteps(ctx, req)
+}
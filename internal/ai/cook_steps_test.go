@@ -0,0 +1,45 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+func TestExtractCookStepsFromToolUse(t *testing.T) {
+	input := `{"steps": [
+		{"minutes": 10, "equipment": "oven", "depends_on": []},
+		{"minutes": 5, "equipment": "none", "depends_on": []},
+		{"minutes": 25, "equipment": "oven", "depends_on": [1, 2]}
+	]}`
+	msg := &anthropic.Message{
+		Content: []anthropic.ContentBlockUnion{
+			{Type: "tool_use", Name: "time_cook_steps", Input: json.RawMessage(input)},
+		},
+	}
+
+	timings, err := extractCookStepsFromToolUse(msg, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timings[0].Equipment != EquipmentOven || timings[1].Equipment != "" {
+		t.Errorf("equipment = %q, %q; want oven and none mapped to empty", timings[0].Equipment, timings[1].Equipment)
+	}
+	if timings[2].Minutes != 25 || len(timings[2].DependsOn) != 2 {
+		t.Errorf("step 3 = %+v", timings[2])
+	}
+
+	if _, err := extractCookStepsFromToolUse(msg, 4); err == nil {
+		t.Error("a timing per step should be required")
+	}
+}
+
+func TestAnalyzeCookSteps_Unsupported(t *testing.T) {
+	_, err := AnalyzeCookSteps(context.Background(), &stubCookingQAProvider{}, CookStepsRequest{Steps: []string{"Boil water"}})
+	if !errors.Is(err, ErrCookStepsUnsupported) {
+		t.Errorf("error = %v, want ErrCookStepsUnsupported", err)
+	}
+}
@@ -0,0 +1,72 @@
+package ai
+
+import (
+	"context"
+	"strings"
+)
+
+// CookingChatRequest is a cooking-mode question with the context the server
+// grounds it in and the conversation so far, so follow-ups like "and how long
+// for that?" resolve against earlier turns.
+type CookingChatRequest struct {
+	Question      string
+	RecipeContext string
+	History       []Message // earlier user/assistant turns, oldest first
+}
+
+// CookingChatStreamer is implemented by text providers that answer a
+// multi-turn cooking question, streaming the answer as it is generated.
+// onDelta is called with each chunk in order; the full answer is returned.
+// It is metered as a CookingQA call.
+type CookingChatStreamer interface {
+	StreamCookingChat(ctx context.Context, req CookingChatRequest, onDelta func(string)) (string, error)
+}
+
+// Compile-time checks for the providers and wrappers that stream cooking
+// chat (wrappers delegate through StreamCookingChat, so an inner provider
+// without streaming still answers).
+var (
+	_ CookingChatStreamer = (*AnthropicProvider)(nil)
+	_ CookingChatStreamer = (*OpenAICompatProvider)(nil)
+	_ CookingChatStreamer = (*SwitchableTextProvider)(nil)
+	_ CookingChatStreamer = (*RoutedTextProvider)(nil)
+	_ CookingChatStreamer = (*ExperimentTextProvider)(nil)
+)
+
+// StreamCookingChat answers req with p, streaming when p implements
+// CookingChatStreamer. Otherwise the history is folded into the recipe
+// context for a plain CookingQA call and the answer arrives as one delta.
+func StreamCookingChat(ctx context.Context, p TextProvider, req CookingChatRequest, onDelta func(string)) (string, error) {
+	if s, ok := p.(CookingChatStreamer); ok {
+		return s.StreamCookingChat(ctx, req, onDelta)
+	}
+	answer, err := p.CookingQA(ctx, req.Question, flattenCookingHistory(req.RecipeContext, req.History))
+	if err != nil {
+		return "", err
+	}
+	onDelta(answer)
+	return answer, nil
+}
+
+// flattenCookingHistory appends the conversation so far to recipeContext for
+// providers that take a single question.
+func flattenCookingHistory(recipeContext string, history []Message) string {
+	if len(history) == 0 {
+		return recipeContext
+	}
+	var b strings.Builder
+	b.WriteString(recipeContext)
+	if recipeContext != "" {
+		b.WriteString("\n\n")
+	}
+	b.WriteString("Conversation so far:")
+	for _, m := range history {
+		switch m.Role {
+		case "user":
+			b.WriteString("\nUser: " + m.Content)
+		case "assistant":
+			b.WriteString("\nAssistant: " + m.Content)
+		}
+	}
+	return b.String()
+}
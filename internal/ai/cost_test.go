@@ -80,3 +80,18 @@ func TestRunWithMiddleware_FlowsUsageFromCall(t *testing.T) {
 		t.Errorf("usage = %+v, want {42 7}", mw.captured.Usage)
 	}
 }
+
+func TestCostMiddleware_AttributesCallFromContext(t *testing.T) {
+	var got UsageRecord
+	mw := &CostMiddleware{Sink: func(r UsageRecord) { got = r }}
+	ctx := WithAttribution(context.Background(), CallAttribution{UserID: 7, RequestID: "req-1", Endpoint: "/v1/recipes/:recipe_id/chat"})
+	_, _ = runWithMiddleware(ctx, mw,
+		AIOperation{Name: "RegenerateRecipe", Model: "claude-haiku-4-5", StartTime: time.Now()},
+		func(ctx context.Context) (string, error) {
+			recordUsage(ctx, TokenUsage{InputTokens: 10, OutputTokens: 5})
+			return "ok", nil
+		})
+	if got.UserID != 7 || got.RequestID != "req-1" || got.Endpoint != "/v1/recipes/:recipe_id/chat" {
+		t.Errorf("record attribution = %d/%q/%q, want 7/req-1/the route", got.UserID, got.RequestID, got.Endpoint)
+	}
+}
@@ -13,8 +13,10 @@ import (
 
 // EmbeddingProviderImpl implements EmbeddingProvider using OpenAI embeddings.
 type EmbeddingProviderImpl struct {
-	apiKey string
-	model  openai.EmbeddingModel
+	apiKey     string
+	model      openai.EmbeddingModel
+	baseURL    string       // empty means the OpenAI API; overridden in tests
+	middleware AIMiddleware // nil means no middleware
 }
 
 // NewEmbeddingProvider creates a new embedding provider using
@@ -26,6 +28,13 @@ func NewEmbeddingProvider(apiKey string) *EmbeddingProviderImpl {
 	}
 }
 
+// WithMiddleware sets the middleware chain for this provider. Through it
+// embeddings are metered and share the OpenAI rate limit with every other
+// call, queueing at the caller's priority (see WithPriority).
+func (p *EmbeddingProviderImpl) WithMiddleware(mw AIMiddleware) {
+	p.middleware = mw
+}
+
 // GenerateEmbedding produces a vector embedding for the given text,
 // suitable for pgvector storage.
 func (p *EmbeddingProviderImpl) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
@@ -33,7 +42,25 @@ func (p *EmbeddingProviderImpl) GenerateEmbedding(ctx context.Context, text stri
 		return nil, errors.New("embedding text is empty")
 	}
 
-	client := openai.NewClient(p.apiKey)
+	op := AIOperation{
+		Name:      "GenerateEmbedding",
+		Provider:  "openai",
+		Model:     string(p.model),
+		StartTime: time.Now(),
+	}
+	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) ([]float32, error) {
+		return p.createEmbedding(ctx, text)
+	})
+}
+
+// createEmbedding calls the embeddings API with the same retry policy as the
+// sibling OpenAI providers.
+func (p *EmbeddingProviderImpl) createEmbedding(ctx context.Context, text string) ([]float32, error) {
+	cfg := openai.DefaultConfig(p.apiKey)
+	if p.baseURL != "" {
+		cfg.BaseURL = p.baseURL
+	}
+	client := openai.NewClientWithConfig(cfg)
 	const maxRetries = 3
 	var lastErr error
 
@@ -43,6 +70,7 @@ func (p *EmbeddingProviderImpl) GenerateEmbedding(ctx context.Context, text stri
 			Input: []string{text},
 		})
 		if err == nil {
+			recordUsage(ctx, TokenUsage{InputTokens: resp.Usage.PromptTokens})
 			if len(resp.Data) == 0 || len(resp.Data[0].Embedding) == 0 {
 				return nil, errors.New("embedding API returned empty result")
 			}
@@ -2,8 +2,13 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	openai "github.com/sashabaranov/go-openai"
 )
@@ -34,3 +39,50 @@ func TestGenerateEmbedding_EmptyTextRejected(t *testing.T) {
 		t.Errorf("error = %q, want mention of empty embedding text", err.Error())
 	}
 }
+
+func TestGenerateEmbedding_BackfillQueuesBehindInteractive(t *testing.T) {
+	var mu sync.Mutex
+	var served []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		served = append(served, req.Input[0])
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1,0.2]}],"usage":{"prompt_tokens":3,"total_tokens":3}}`))
+	}))
+	defer srv.Close()
+
+	// 600 RPM refills one request every 100ms; drain the bucket so both
+	// calls below have to queue for it.
+	limiter := NewRateLimiter(map[string]RateLimit{"openai": {RPM: 600}})
+	embedOp := AIOperation{Name: "GenerateEmbedding", Provider: "openai", Model: string(openai.SmallEmbedding3), StartTime: time.Now()}
+	for i := 0; i < 600; i++ {
+		if _, err := limiter.Admit(context.Background(), embedOp); err != nil {
+			t.Fatalf("admit %d: %v", i, err)
+		}
+	}
+	p := NewEmbeddingProvider("test-key")
+	p.baseURL = srv.URL + "/v1"
+	p.WithMiddleware(limiter)
+
+	var wg sync.WaitGroup
+	embed := func(ctx context.Context, text string) {
+		defer wg.Done()
+		if _, err := p.GenerateEmbedding(ctx, text); err != nil {
+			t.Errorf("GenerateEmbedding(%q): %v", text, err)
+		}
+	}
+	wg.Add(2)
+	go embed(WithPriority(context.Background(), PriorityBackfill), "backfill")
+	time.Sleep(20 * time.Millisecond)
+	go embed(context.Background(), "search")
+	wg.Wait()
+
+	if len(served) != 2 || served[0] != "search" {
+		t.Errorf("served %v, want the interactive search embedded before the queued backfill", served)
+	}
+}
@@ -1,6 +1,12 @@
 package ai
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
 
 // AIFailureKind classifies the type of AI failure for targeted recovery.
 type AIFailureKind int
@@ -22,6 +28,10 @@ const (
 	FailureQuotaExhausted
 	// FailureUnknown is an unrecognized error. Do not retry.
 	FailureUnknown
+	// FailureThrottled means the local RateLimiter shed the call before it
+	// reached the provider. Not retryable on the same provider, but another
+	// provider's limit may still have room.
+	FailureThrottled
 )
 
 // AIError wraps an error with classification metadata.
@@ -58,6 +68,8 @@ func (e *AIError) kindString() string {
 		return "quota_exhausted"
 	case FailureUnknown:
 		return "unknown"
+	case FailureThrottled:
+		return "throttled"
 	default:
 		return "unknown"
 	}
@@ -68,3 +80,32 @@ func NewAIError(kind AIFailureKind, err error, detail string) *AIError {
 	retryable := kind == FailureTransient || kind == FailureContentEmpty
 	return &AIError{Kind: kind, Err: err, Retryable: retryable, Detail: detail}
 }
+
+// classifyHTTPStatus maps a provider API's HTTP status to the failure
+// taxonomy, or returns nil when the status says nothing beyond "this request
+// was rejected". 529 is Anthropic's "overloaded"; 402 is a billing failure,
+// which no retry on the same provider will fix.
+func classifyHTTPStatus(status int, err error) *AIError {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return NewAIError(FailureTransient, err, "rate limited")
+	case status == http.StatusPaymentRequired:
+		return NewAIError(FailureQuotaExhausted, err, "quota exhausted")
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return NewAIError(FailureAuth, err, "unauthorized")
+	case status >= http.StatusInternalServerError:
+		return NewAIError(FailureTransient, err, "server error")
+	}
+	return nil
+}
+
+// classifyTransportError classifies a failure to reach the provider at all
+// (refused or reset connections, DNS failures, timeouts, truncated
+// responses) as transient, or returns nil when err is not one.
+func classifyTransportError(err error) *AIError {
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return NewAIError(FailureTransient, err, "network error")
+	}
+	return nil
+}
@@ -3,8 +3,12 @@ package ai
 import (
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -137,6 +141,32 @@ func TestClassifyAnthropicError(t *testing.T) {
 			wantKind:  FailureTransient,
 			retryable: true,
 		},
+		{
+			name:      "529 overloaded is transient",
+			err:       func(t *testing.T) error { return newAnthropicAPIError(t, 529) },
+			wantKind:  FailureTransient,
+			retryable: true,
+		},
+		{
+			name:      "402 billing error is quota exhausted, not retryable",
+			err:       func(t *testing.T) error { return newAnthropicAPIError(t, http.StatusPaymentRequired) },
+			wantKind:  FailureQuotaExhausted,
+			retryable: false,
+		},
+		{
+			name: "connection refused is transient",
+			err: func(t *testing.T) error {
+				return &url.Error{Op: "Post", URL: "https://api.anthropic.com/v1/messages", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+			},
+			wantKind:  FailureTransient,
+			retryable: true,
+		},
+		{
+			name:      "truncated response is transient",
+			err:       func(t *testing.T) error { return fmt.Errorf("read body: %w", io.ErrUnexpectedEOF) },
+			wantKind:  FailureTransient,
+			retryable: true,
+		},
 		{
 			name:      "401 unauthorized is auth, not retryable",
 			err:       func(t *testing.T) error { return newAnthropicAPIError(t, http.StatusUnauthorized) },
@@ -216,3 +246,29 @@ func TestClassifyOpenAIError(t *testing.T) {
 		})
 	}
 }
+
+func TestClassifyOpenAICompatError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantKind AIFailureKind
+	}{
+		{"429 rate limit is transient", &openai.APIError{HTTPStatusCode: 429, Code: "rate_limit_exceeded"}, FailureTransient},
+		{"429 insufficient_quota is quota exhausted", &openai.APIError{HTTPStatusCode: 429, Code: "insufficient_quota"}, FailureQuotaExhausted},
+		{"503 is transient", &openai.APIError{HTTPStatusCode: 503}, FailureTransient},
+		{"504 is transient", &openai.APIError{HTTPStatusCode: 504}, FailureTransient},
+		{"401 is auth", &openai.APIError{HTTPStatusCode: 401}, FailureAuth},
+		{"400 is unknown", &openai.APIError{HTTPStatusCode: 400}, FailureUnknown},
+		{"RequestError 500 is transient", &openai.RequestError{HTTPStatusCode: 500, Err: errors.New("boom")}, FailureTransient},
+		{"connection reset is transient", &url.Error{Op: "Post", URL: "https://api.openai.com", Err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}}, FailureTransient},
+		{"plain error is unknown", errors.New("render prompt"), FailureUnknown},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := classifyOpenAICompatError(tc.err); got.Kind != tc.wantKind {
+				t.Errorf("Kind = %v, want %v", got.Kind, tc.wantKind)
+			}
+		})
+	}
+}
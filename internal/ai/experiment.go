@@ -0,0 +1,187 @@
+package ai
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+)
+
+// ExperimentArm is one side of a traffic split: Percent of the experiment's
+// users are served by Provider.
+type ExperimentArm struct {
+	Name     string
+	Percent  int
+	Provider TextProvider
+}
+
+// Experiment splits one operation's traffic between arms. Key names it in
+// usage records; Arms' percentages sum to 100.
+type Experiment struct {
+	Key       string
+	Operation string
+	Arms      []ExperimentArm
+}
+
+// Assign picks the arm for a user. Assignment is a stable hash of the
+// experiment key and user ID, so a user stays on one arm for the life of the
+// experiment and different experiments split users independently.
+func (e *Experiment) Assign(userID uint) (ExperimentArm, bool) {
+	if len(e.Arms) == 0 {
+		return ExperimentArm{}, false
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(e.Key + ":" + strconv.FormatUint(uint64(userID), 10)))
+	bucket := int(h.Sum32() % 100)
+	for _, arm := range e.Arms {
+		if bucket < arm.Percent {
+			return arm, true
+		}
+		bucket -= arm.Percent
+	}
+	return e.Arms[len(e.Arms)-1], true
+}
+
+type experimentKeyType struct{}
+
+// ExperimentInfo is attached to the context of a call served by an experiment
+// arm, so the serving provider's middleware can tag its usage record.
+type ExperimentInfo struct {
+	Experiment string
+	Arm        string
+}
+
+// experimentInfoFromContext returns the ExperimentInfo for a call, if any.
+func experimentInfoFromContext(ctx context.Context) ExperimentInfo {
+	info, _ := ctx.Value(experimentKeyType{}).(ExperimentInfo)
+	return info
+}
+
+// ExperimentRouter is the live set of running experiments, at most one per
+// operation. Tiers wrap their provider with Wrap so a call to an operation
+// under experiment goes to the caller's arm. Only attributed calls take part:
+// without a user there is nothing to keep the assignment sticky to, so those
+// calls stay on the tier. Background jobs started by a request (regenerate,
+// fork, video import) run under the request's attribution, so they take part
+// too. The set is swapped atomically with Set.
+type ExperimentRouter struct {
+	mu          sync.RWMutex
+	experiments map[string]*Experiment
+}
+
+// NewExperimentRouter creates a router with no experiments.
+func NewExperimentRouter() *ExperimentRouter {
+	return &ExperimentRouter{experiments: map[string]*Experiment{}}
+}
+
+// Set atomically replaces the running experiments.
+func (r *ExperimentRouter) Set(experiments []*Experiment) {
+	table := make(map[string]*Experiment, len(experiments))
+	for _, e := range experiments {
+		if e != nil && len(e.Arms) > 0 {
+			table[e.Operation] = e
+		}
+	}
+	r.mu.Lock()
+	r.experiments = table
+	r.mu.Unlock()
+}
+
+// Wrap returns a TextProvider that serves operations under experiment from
+// the caller's arm and everything else from def.
+func (r *ExperimentRouter) Wrap(def TextProvider) *ExperimentTextProvider {
+	return &ExperimentTextProvider{router: r, def: def}
+}
+
+// ExperimentTextProvider is a tier's provider behind an ExperimentRouter.
+type ExperimentTextProvider struct {
+	router *ExperimentRouter
+	def    TextProvider
+}
+
+var _ TextProvider = (*ExperimentTextProvider)(nil)
+
+// pick returns the provider for op and the context to call it with, tagged
+// with the arm when the call is part of an experiment.
+func (p *ExperimentTextProvider) pick(ctx context.Context, op string) (context.Context, TextProvider) {
+	userID := AttributionFromContext(ctx).UserID
+	if userID == 0 {
+		return ctx, p.def
+	}
+	p.router.mu.RLock()
+	exp := p.router.experiments[op]
+	p.router.mu.RUnlock()
+	if exp == nil {
+		return ctx, p.def
+	}
+	arm, ok := exp.Assign(userID)
+	if !ok || arm.Provider == nil {
+		return ctx, p.def
+	}
+	return context.WithValue(ctx, experimentKeyType{}, ExperimentInfo{Experiment: exp.Key, Arm: arm.Name}), arm.Provider
+}
+
+func (p *ExperimentTextProvider) GenerateRecipe(ctx context.Context, req RecipeRequest) (*RecipeResult, error) {
+	ctx, provider := p.pick(ctx, "GenerateRecipe")
+	return provider.GenerateRecipe(ctx, req)
+}
+
+func (p *ExperimentTextProvider) RegenerateRecipe(ctx context.Context, req RegenerateRequest) (*RecipeResult, error) {
+	ctx, provider := p.pick(ctx, "RegenerateRecipe")
+	return provider.RegenerateRecipe(ctx, req)
+}
+
+func (p *ExperimentTextProvider) ForkRecipe(ctx context.Context, req ForkRequest) (*RecipeResult, error) {
+	ctx, provider := p.pick(ctx, "ForkRecipe")
+	return provider.ForkRecipe(ctx, req)
+}
+
+func (p *ExperimentTextProvider) AnalyzeAllergens(ctx context.Context, req AllergenRequest) (*AllergenResult, error) {
+	ctx, provider := p.pick(ctx, "AnalyzeAllergens")
+	return provider.AnalyzeAllergens(ctx, req)
+}
+
+func (p *ExperimentTextProvider) ClassifyVoiceIntent(ctx context.Context, transcript string) (*VoiceIntent, error) {
+	ctx, provider := p.pick(ctx, "ClassifyVoiceIntent")
+	return provider.ClassifyVoiceIntent(ctx, transcript)
+}
+
+func (p *ExperimentTextProvider) EstimatePortions(ctx context.Context, recipeDef interface{}) (*PortionEstimate, error) {
+	ctx, provider := p.pick(ctx, "EstimatePortions")
+	return provider.EstimatePortions(ctx, recipeDef)
+}
+
+func (p *ExperimentTextProvider) ExtractRecipeFromText(ctx context.Context, text string, unitSystem string) (*RecipeResult, error) {
+	ctx, provider := p.pick(ctx, "ExtractRecipeFromText")
+	return provider.ExtractRecipeFromText(ctx, text, unitSystem)
+}
+
+func (p *ExperimentTextProvider) CookingQA(ctx context.Context, question string, recipeContext string) (string, error) {
+	ctx, provider := p.pick(ctx, "CookingQA")
+	return provider.CookingQA(ctx, question, recipeContext)
+}
+
+func (p *ExperimentTextProvider) StreamCookingChat(ctx context.Context, req CookingChatRequest, onDelta func(string)) (string, error) {
+	ctx, provider := p.pick(ctx, "CookingQA")
+	return StreamCookingChat(ctx, provider, req, onDelta)
+}
+
+func (p *ExperimentTextProvider) AnalyzeCookSteps(ctx context.Context, req CookStepsRequest) ([]CookStepTiming, error) {
+	ctx, provider := p.pick(ctx, "AnalyzeCookSteps")
+	return AnalyzeCookSteps(ctx, provider, req)
+}
+
+func (p *ExperimentTextProvider) SuggestSubstitutions(ctx context.Context, req SubstitutionRequest) (*SubstitutionResult, error) {
+	ctx, provider := p.pick(ctx, "SuggestSubstitutions")
+	return SuggestSubstitutions(ctx, provider, req)
+}
+
+func (p *ExperimentTextProvider) DietaryInterview(ctx context.Context, messages []Message, memberName string) (*DietaryInterviewResult, error) {
+	ctx, provider := p.pick(ctx, "DietaryInterview")
+	return provider.DietaryInterview(ctx, messages, memberName)
+}
+
+func (p *ExperimentTextProvider) ExpandAndRankRecipes(ctx context.Context, req FinderRankRequest) (*FinderRankResult, error) {
+	ctx, provider := p.pick(ctx, "ExpandAndRankRecipes")
+	return provider.ExpandAndRankRecipes(ctx, req)
+}
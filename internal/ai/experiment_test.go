@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExperiment_AssignIsStickyAndSplits(t *testing.T) {
+	exp := &Experiment{Key: "qa-flash", Arms: []ExperimentArm{{Name: "control", Percent: 70}, {Name: "flash", Percent: 30}}}
+
+	counts := map[string]int{}
+	for user := uint(1); user <= 2000; user++ {
+		arm, ok := exp.Assign(user)
+		if !ok {
+			t.Fatal("expected an arm")
+		}
+		again, _ := exp.Assign(user)
+		if again.Name != arm.Name {
+			t.Fatalf("user %d moved from %s to %s", user, arm.Name, again.Name)
+		}
+		counts[arm.Name]++
+	}
+	// 30% of 2000 = 600; allow generous slack for hash variance.
+	if counts["flash"] < 450 || counts["flash"] > 750 {
+		t.Errorf("flash arm got %d of 2000 users, want about 600", counts["flash"])
+	}
+}
+
+func TestExperimentTextProvider_TagsArmAndSkipsUnattributed(t *testing.T) {
+	var records []UsageRecord
+	mw := &CostMiddleware{Sink: func(r UsageRecord) { records = append(records, r) }}
+	router := NewExperimentRouter()
+	tier := router.Wrap(&fallbackStub{switchStub: switchStub{name: "tier"}, mw: mw})
+	router.Set([]*Experiment{{
+		Key:       "extract-test",
+		Operation: "ExtractRecipeFromText",
+		Arms:      []ExperimentArm{{Name: "only", Percent: 100, Provider: &fallbackStub{switchStub: switchStub{name: "arm"}, mw: mw}}},
+	}})
+
+	got, _ := tier.ExtractRecipeFromText(context.Background(), "x", "metric")
+	if got.Title != "tier" {
+		t.Errorf("unattributed call served by %q, want the tier", got.Title)
+	}
+
+	ctx := WithAttribution(context.Background(), CallAttribution{UserID: 7, RequestID: "req-1"})
+	got, _ = tier.ExtractRecipeFromText(ctx, "x", "metric")
+	if got.Title != "arm" {
+		t.Errorf("attributed call served by %q, want the arm", got.Title)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d usage records, want 2", len(records))
+	}
+	if records[0].Experiment != "" {
+		t.Errorf("unattributed record tagged %q, want untagged", records[0].Experiment)
+	}
+	if records[1].Experiment != "extract-test" || records[1].ExperimentArm != "only" {
+		t.Errorf("record = %+v, want tagged extract-test/only", records[1])
+	}
+
+	// Other operations are untouched.
+	if qa, _ := tier.CookingQA(ctx, "q", ""); qa != "tier" {
+		t.Errorf("CookingQA served by %q, want the tier", qa)
+	}
+}
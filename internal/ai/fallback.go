@@ -0,0 +1,263 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"go.uber.org/zap"
+)
+
+// ErrNoTextProviders is returned when a FallbackTextProvider has an empty chain.
+var ErrNoTextProviders = errors.New("no AI providers configured")
+
+// FallbackMember is one link of a FallbackTextProvider chain. Name identifies
+// it in logs, usage records and the admin API (e.g. "anthropic/claude-sonnet-4-5").
+// A nil Breaker gets one with the default tuning.
+type FallbackMember struct {
+	Name     string
+	Provider TextProvider
+	Breaker  *CircuitBreaker
+}
+
+// FallbackMemberStatus is a chain member's live health, for the admin API.
+type FallbackMemberStatus struct {
+	Name   string          `json:"name"`
+	Health BreakerSnapshot `json:"health"`
+}
+
+type fallbackKeyType struct{}
+
+// FallbackInfo is attached to the context of each attempt a
+// FallbackTextProvider makes, so the serving provider's middleware can record
+// that a call was failed over (and from what) in its usage record.
+type FallbackInfo struct {
+	Primary string // first choice in the chain when the call started
+	Attempt int    // 0 for the first choice, n for the nth fallback
+}
+
+// fallbackInfoFromContext returns the FallbackInfo for an attempt, if any.
+func fallbackInfoFromContext(ctx context.Context) FallbackInfo {
+	info, _ := ctx.Value(fallbackKeyType{}).(FallbackInfo)
+	return info
+}
+
+// FallbackTextProvider is a TextProvider over an ordered chain of providers.
+// Each call goes to the first member whose circuit breaker admits it; a
+// failure that another provider could plausibly serve (a retryable AIError —
+// rate limits, 5xx and 529s, network errors, empty responses — or an
+// exhausted quota) moves the call to the next member; providers classify
+// their API and transport errors into AIErrors for this. Other failures (bad
+// requests, unparseable output) are returned as is, since a different
+// provider would not fare better on the same input, and do not count against
+// the provider's breaker. Breakers half-open after their cooldown so a
+// recovered provider takes traffic back on its own. When every breaker is open
+// the first member is tried anyway rather than failing outright. The chain can
+// be replaced at runtime with SetChain.
+type FallbackTextProvider struct {
+	mu      sync.RWMutex
+	members []FallbackMember
+}
+
+var _ TextProvider = (*FallbackTextProvider)(nil)
+
+// NewFallbackTextProvider creates a provider over members, in order.
+func NewFallbackTextProvider(members ...FallbackMember) *FallbackTextProvider {
+	f := &FallbackTextProvider{}
+	f.SetChain(members)
+	return f
+}
+
+// SetChain atomically replaces the chain. Members without a provider are
+// dropped; members without a breaker get a default one.
+func (f *FallbackTextProvider) SetChain(members []FallbackMember) {
+	chain := make([]FallbackMember, 0, len(members))
+	for _, m := range members {
+		if m.Provider == nil {
+			continue
+		}
+		if m.Breaker == nil {
+			m.Breaker = NewCircuitBreaker(0, 0)
+		}
+		chain = append(chain, m)
+	}
+	f.mu.Lock()
+	f.members = chain
+	f.mu.Unlock()
+}
+
+// Chain returns the current members with their live health, in order.
+func (f *FallbackTextProvider) Chain() []FallbackMemberStatus {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	out := make([]FallbackMemberStatus, len(f.members))
+	for i, m := range f.members {
+		out[i] = FallbackMemberStatus{Name: m.Name, Health: m.Breaker.Snapshot()}
+	}
+	return out
+}
+
+func (f *FallbackTextProvider) chain() []FallbackMember {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.members
+}
+
+// shouldFailOver reports whether err is worth retrying on another provider.
+func shouldFailOver(err error) bool {
+	var aiErr *AIError
+	if !errors.As(err, &aiErr) {
+		return false
+	}
+	return aiErr.Retryable || aiErr.Kind == FailureQuotaExhausted || aiErr.Kind == FailureThrottled
+}
+
+// isUnsupported reports whether err is a provider declining an optional
+// operation it does not implement, which the next member may.
+func isUnsupported(err error) bool {
+	return errors.Is(err, ErrSubstitutionsUnsupported) || errors.Is(err, ErrCookStepsUnsupported)
+}
+
+// runFallback runs call against each admitted member in turn until one serves
+// it or fails with an error another member could not do better on.
+func runFallback[T any](ctx context.Context, f *FallbackTextProvider, op string, call func(context.Context, TextProvider) (T, error)) (T, error) {
+	var zero T
+	members := f.chain()
+	if len(members) == 0 {
+		return zero, ErrNoTextProviders
+	}
+
+	primary := members[0].Name
+	attempt := 0
+	var lastErr error
+	for _, m := range members {
+		if ctx.Err() != nil {
+			break
+		}
+		if !m.Breaker.Allow() {
+			continue
+		}
+		attemptCtx := context.WithValue(ctx, fallbackKeyType{}, FallbackInfo{Primary: primary, Attempt: attempt})
+		attempt++
+
+		result, err := call(attemptCtx, m.Provider)
+		if err == nil {
+			m.Breaker.RecordSuccess()
+			return result, nil
+		}
+		if isUnsupported(err) {
+			m.Breaker.Release()
+			lastErr = err
+			continue
+		}
+		if ctx.Err() != nil {
+			// The caller gave up; that is no verdict on the provider.
+			m.Breaker.Release()
+			lastErr = err
+			break
+		}
+		if !shouldFailOver(err) {
+			// The failure is about this input, not the provider's health.
+			m.Breaker.Release()
+			return zero, err
+		}
+		var aiErr *AIError
+		switch {
+		case errors.As(err, &aiErr) && aiErr.Kind == FailureThrottled:
+			// Shed locally: the provider is healthy, just saturated.
+			m.Breaker.Release()
+		case errors.As(err, &aiErr) && aiErr.Kind == FailureQuotaExhausted:
+			m.Breaker.Trip(err)
+		default:
+			m.Breaker.RecordFailure(err)
+		}
+		logger.Get().Warn("ai provider failed, trying next in chain",
+			zap.String("operation", op), zap.String("provider", m.Name), zap.Error(err))
+		lastErr = err
+	}
+
+	if attempt == 0 && ctx.Err() == nil {
+		// Every breaker is open: better to try the first choice than to fail
+		// a call no provider was even asked to serve.
+		attemptCtx := context.WithValue(ctx, fallbackKeyType{}, FallbackInfo{Primary: primary})
+		return call(attemptCtx, members[0].Provider)
+	}
+	if lastErr == nil {
+		lastErr = ctx.Err()
+	}
+	return zero, fmt.Errorf("all AI providers failed: %w", lastErr)
+}
+
+func (f *FallbackTextProvider) GenerateRecipe(ctx context.Context, req RecipeRequest) (*RecipeResult, error) {
+	return runFallback(ctx, f, "GenerateRecipe", func(ctx context.Context, p TextProvider) (*RecipeResult, error) {
+		return p.GenerateRecipe(ctx, req)
+	})
+}
+
+func (f *FallbackTextProvider) RegenerateRecipe(ctx context.Context, req RegenerateRequest) (*RecipeResult, error) {
+	return runFallback(ctx, f, "RegenerateRecipe", func(ctx context.Context, p TextProvider) (*RecipeResult, error) {
+		return p.RegenerateRecipe(ctx, req)
+	})
+}
+
+func (f *FallbackTextProvider) ForkRecipe(ctx context.Context, req ForkRequest) (*RecipeResult, error) {
+	return runFallback(ctx, f, "ForkRecipe", func(ctx context.Context, p TextProvider) (*RecipeResult, error) {
+		return p.ForkRecipe(ctx, req)
+	})
+}
+
+func (f *FallbackTextProvider) AnalyzeAllergens(ctx context.Context, req AllergenRequest) (*AllergenResult, error) {
+	return runFallback(ctx, f, "AnalyzeAllergens", func(ctx context.Context, p TextProvider) (*AllergenResult, error) {
+		return p.AnalyzeAllergens(ctx, req)
+	})
+}
+
+func (f *FallbackTextProvider) ClassifyVoiceIntent(ctx context.Context, transcript string) (*VoiceIntent, error) {
+	return runFallback(ctx, f, "ClassifyVoiceIntent", func(ctx context.Context, p TextProvider) (*VoiceIntent, error) {
+		return p.ClassifyVoiceIntent(ctx, transcript)
+	})
+}
+
+func (f *FallbackTextProvider) EstimatePortions(ctx context.Context, recipeDef interface{}) (*PortionEstimate, error) {
+	return runFallback(ctx, f, "EstimatePortions", func(ctx context.Context, p TextProvider) (*PortionEstimate, error) {
+		return p.EstimatePortions(ctx, recipeDef)
+	})
+}
+
+func (f *FallbackTextProvider) ExtractRecipeFromText(ctx context.Context, text string, unitSystem string) (*RecipeResult, error) {
+	return runFallback(ctx, f, "ExtractRecipeFromText", func(ctx context.Context, p TextProvider) (*RecipeResult, error) {
+		return p.ExtractRecipeFromText(ctx, text, unitSystem)
+	})
+}
+
+func (f *FallbackTextProvider) CookingQA(ctx context.Context, question string, recipeContext string) (string, error) {
+	return runFallback(ctx, f, "CookingQA", func(ctx context.Context, p TextProvider) (string, error) {
+		return p.CookingQA(ctx, question, recipeContext)
+	})
+}
+
+func (f *FallbackTextProvider) DietaryInterview(ctx context.Context, messages []Message, memberName string) (*DietaryInterviewResult, error) {
+	return runFallback(ctx, f, "DietaryInterview", func(ctx context.Context, p TextProvider) (*DietaryInterviewResult, error) {
+		return p.DietaryInterview(ctx, messages, memberName)
+	})
+}
+
+func (f *FallbackTextProvider) ExpandAndRankRecipes(ctx context.Context, req FinderRankRequest) (*FinderRankResult, error) {
+	return runFallback(ctx, f, "ExpandAndRankRecipes", func(ctx context.Context, p TextProvider) (*FinderRankResult, error) {
+		return p.ExpandAndRankRecipes(ctx, req)
+	})
+}
+
+func (f *FallbackTextProvider) AnalyzeCookSteps(ctx context.Context, req CookStepsRequest) ([]CookStepTiming, error) {
+	return runFallback(ctx, f, "AnalyzeCookSteps", func(ctx context.Context, p TextProvider) ([]CookStepTiming, error) {
+		return AnalyzeCookSteps(ctx, p, req)
+	})
+}
+
+func (f *FallbackTextProvider) SuggestSubstitutions(ctx context.Context, req SubstitutionRequest) (*SubstitutionResult, error) {
+	return runFallback(ctx, f, "SuggestSubstitutions", func(ctx context.Context, p TextProvider) (*SubstitutionResult, error) {
+		return SuggestSubstitutions(ctx, p, req)
+	})
+}
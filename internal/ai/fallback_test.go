@@ -0,0 +1,214 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// fallbackStub is a switchStub whose ExtractRecipeFromText fails with err (when
+// set) and counts its calls. With classify set, err is the raw API or
+// transport error and is classified the way the real provider would before
+// it reaches the chain. onCall, when set, runs as the call starts. With mw
+// set the call runs through the middleware so the usage record can be
+// inspected.
+type fallbackStub struct {
+	switchStub
+	err      error
+	classify func(error) *AIError
+	onCall   func()
+	calls    int
+	mw       AIMiddleware
+}
+
+func (s *fallbackStub) ExtractRecipeFromText(ctx context.Context, text, unitSystem string) (*RecipeResult, error) {
+	s.calls++
+	if s.onCall != nil {
+		s.onCall()
+	}
+	op := AIOperation{Name: "ExtractRecipeFromText", Provider: s.name, Model: s.name, StartTime: time.Now()}
+	return runWithMiddleware(ctx, s.mw, op, func(ctx context.Context) (*RecipeResult, error) {
+		if s.err != nil && s.classify != nil {
+			return nil, s.classify(s.err)
+		}
+		if s.err != nil {
+			return nil, s.err
+		}
+		recordUsage(ctx, TokenUsage{InputTokens: 10, OutputTokens: 5})
+		return &RecipeResult{Title: s.name}, nil
+	})
+}
+
+func TestFallbackTextProvider_FailsOverOnOutage(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	tests := []struct {
+		name     string
+		err      error
+		classify func(error) *AIError
+	}{
+		{"openai 503", &openai.APIError{HTTPStatusCode: http.StatusServiceUnavailable}, classifyOpenAICompatError},
+		{"openai 429", &openai.APIError{HTTPStatusCode: http.StatusTooManyRequests}, classifyOpenAICompatError},
+		{"gemini 500 with array body", &openai.RequestError{HTTPStatusCode: http.StatusInternalServerError, Err: errors.New("json: cannot unmarshal array")}, classifyOpenAICompatError},
+		{"openai connection refused", refused, classifyOpenAICompatError},
+		{"openai timeout", &net.DNSError{Err: "i/o timeout", Name: "api.openai.com", IsTimeout: true}, classifyOpenAICompatError},
+		{"anthropic 529", newAnthropicAPIError(t, 529), classifyAnthropicError},
+		{"anthropic connection refused", refused, classifyAnthropicError},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var records []UsageRecord
+			mw := &CostMiddleware{Sink: func(r UsageRecord) { records = append(records, r) }}
+			primary := &fallbackStub{switchStub: switchStub{name: "primary"}, err: tc.err, classify: tc.classify, mw: mw}
+			backup := &fallbackStub{switchStub: switchStub{name: "backup"}, mw: mw}
+			f := NewFallbackTextProvider(
+				FallbackMember{Name: "primary", Provider: primary},
+				FallbackMember{Name: "backup", Provider: backup},
+			)
+
+			got, err := f.ExtractRecipeFromText(context.Background(), "x", "metric")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Title != "backup" {
+				t.Errorf("served by %q, want backup", got.Title)
+			}
+			if len(records) != 1 {
+				t.Fatalf("got %d usage records, want 1 (the failed call used no tokens)", len(records))
+			}
+			if records[0].Provider != "backup" || records[0].FailoverAttempt != 1 || records[0].FailoverFrom != "primary" {
+				t.Errorf("record = %+v, want backup serving attempt 1 failed over from primary", records[0])
+			}
+			if h := f.Chain()[0].Health; h.ConsecutiveFailures != 1 {
+				t.Errorf("primary failures = %d, want the outage counted", h.ConsecutiveFailures)
+			}
+		})
+	}
+}
+
+func TestFallbackTextProvider_ReturnsNonRetryableError(t *testing.T) {
+	badRequest := &openai.APIError{HTTPStatusCode: http.StatusBadRequest, Message: "max_tokens too large"}
+	primary := &fallbackStub{switchStub: switchStub{name: "primary"}, err: badRequest, classify: classifyOpenAICompatError}
+	backup := &fallbackStub{switchStub: switchStub{name: "backup"}}
+	f := NewFallbackTextProvider(
+		FallbackMember{Name: "primary", Provider: primary},
+		FallbackMember{Name: "backup", Provider: backup},
+	)
+
+	if _, err := f.ExtractRecipeFromText(context.Background(), "x", "metric"); !errors.Is(err, badRequest) {
+		t.Fatalf("err = %v, want the bad request error", err)
+	}
+	if backup.calls != 0 {
+		t.Error("a bad request must not fail over")
+	}
+	if h := f.Chain()[0].Health; h.State != BreakerClosed || h.ConsecutiveFailures != 0 {
+		t.Errorf("health = %+v; a bad request must not count against the provider's breaker", h)
+	}
+}
+
+func TestFallbackTextProvider_ErrorDoesNotCloseBreaker(t *testing.T) {
+	breaker := NewCircuitBreaker(3, time.Minute)
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	primary := &fallbackStub{switchStub: switchStub{name: "primary"}, err: refused, classify: classifyOpenAICompatError}
+	f := NewFallbackTextProvider(
+		FallbackMember{Name: "primary", Provider: primary, Breaker: breaker},
+		FallbackMember{Name: "backup", Provider: &fallbackStub{switchStub: switchStub{name: "backup"}}},
+	)
+	ctx := context.Background()
+
+	f.ExtractRecipeFromText(ctx, "x", "metric")
+	f.ExtractRecipeFromText(ctx, "x", "metric")
+	// A rejected input between outages must not reset the failure streak.
+	primary.err = &openai.APIError{HTTPStatusCode: http.StatusBadRequest}
+	f.ExtractRecipeFromText(ctx, "x", "metric")
+	primary.err = refused
+	f.ExtractRecipeFromText(ctx, "x", "metric")
+
+	if breaker.State() != BreakerOpen {
+		t.Errorf("breaker = %s after three outages, want open", breaker.State())
+	}
+}
+
+func TestFallbackTextProvider_CallerCancelledDoesNotFailOver(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	// The request is abandoned mid-call; the SDK surfaces it as a
+	// cancellation wrapped in a transport error.
+	abandoned := &net.OpError{Op: "read", Net: "tcp", Err: context.Canceled}
+	primary := &fallbackStub{switchStub: switchStub{name: "primary"}, err: abandoned, classify: classifyOpenAICompatError, onCall: cancel}
+	backup := &fallbackStub{switchStub: switchStub{name: "backup"}}
+	f := NewFallbackTextProvider(
+		FallbackMember{Name: "primary", Provider: primary},
+		FallbackMember{Name: "backup", Provider: backup},
+	)
+
+	if _, err := f.ExtractRecipeFromText(ctx, "x", "metric"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if backup.calls != 0 {
+		t.Error("a cancelled call must not fail over")
+	}
+	if h := f.Chain()[0].Health; h.ConsecutiveFailures != 0 {
+		t.Errorf("primary failures = %d; the caller giving up is not the provider's fault", h.ConsecutiveFailures)
+	}
+}
+
+func TestFallbackTextProvider_QuotaTripsAndRecovers(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	breaker := NewCircuitBreaker(5, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	quota := &openai.APIError{HTTPStatusCode: http.StatusTooManyRequests, Code: "insufficient_quota", Message: "You exceeded your current quota"}
+	primary := &fallbackStub{switchStub: switchStub{name: "primary"}, err: quota, classify: classifyOpenAICompatError}
+	backup := &fallbackStub{switchStub: switchStub{name: "backup"}}
+	f := NewFallbackTextProvider(
+		FallbackMember{Name: "primary", Provider: primary, Breaker: breaker},
+		FallbackMember{Name: "backup", Provider: backup},
+	)
+	ctx := context.Background()
+
+	if _, err := f.ExtractRecipeFromText(ctx, "x", "metric"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if breaker.State() != BreakerOpen {
+		t.Fatalf("an exhausted quota should trip the breaker at once, got %s", breaker.State())
+	}
+
+	// While open, the primary is skipped without being called.
+	if _, err := f.ExtractRecipeFromText(ctx, "x", "metric"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if primary.calls != 1 || backup.calls != 2 {
+		t.Errorf("calls primary/backup = %d/%d, want 1/2", primary.calls, backup.calls)
+	}
+
+	// After the cooldown a half-open probe reaches the recovered primary.
+	primary.err = nil
+	now = now.Add(time.Minute)
+	got, err := f.ExtractRecipeFromText(ctx, "x", "metric")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Title != "primary" || breaker.State() != BreakerClosed {
+		t.Errorf("served by %q with breaker %s, want primary and closed", got.Title, breaker.State())
+	}
+}
+
+func TestFallbackTextProvider_AllFailed(t *testing.T) {
+	overloaded := newAnthropicAPIError(t, 529)
+	f := NewFallbackTextProvider(
+		FallbackMember{Name: "a", Provider: &fallbackStub{switchStub: switchStub{name: "a"}, err: overloaded, classify: classifyAnthropicError}},
+		FallbackMember{Name: "b", Provider: &fallbackStub{switchStub: switchStub{name: "b"}, err: overloaded, classify: classifyAnthropicError}},
+	)
+	if _, err := f.ExtractRecipeFromText(context.Background(), "x", "metric"); !errors.Is(err, overloaded) {
+		t.Fatalf("err = %v, want the last provider's error wrapped", err)
+	}
+
+	if _, err := NewFallbackTextProvider().CookingQA(context.Background(), "q", ""); !errors.Is(err, ErrNoTextProviders) {
+		t.Fatalf("empty chain err = %v, want ErrNoTextProviders", err)
+	}
+}
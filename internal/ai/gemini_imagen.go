@@ -0,0 +1,122 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// GeminiImageProvider implements ImageProvider with Imagen through the Gemini
+// API's predict endpoint, authenticated with the same GEMINI_API_KEY as the
+// native video and vision providers.
+type GeminiImageProvider struct {
+	apiKey  string
+	model   string
+	baseURL string
+	http    *http.Client
+}
+
+// NewGeminiImageProvider creates an Imagen provider for model.
+func NewGeminiImageProvider(apiKey, model string) *GeminiImageProvider {
+	return &GeminiImageProvider{
+		apiKey:  apiKey,
+		model:   model,
+		baseURL: "https://generativelanguage.googleapis.com/v1beta",
+		http:    &http.Client{Timeout: 90 * time.Second},
+	}
+}
+
+type imagenPredictRequest struct {
+	Instances  []imagenInstance `json:"instances"`
+	Parameters imagenParameters `json:"parameters"`
+}
+
+type imagenInstance struct {
+	Prompt string `json:"prompt"`
+}
+
+type imagenParameters struct {
+	SampleCount int    `json:"sampleCount"`
+	AspectRatio string `json:"aspectRatio"`
+}
+
+type imagenPredictResponse struct {
+	Predictions []struct {
+		BytesBase64Encoded string `json:"bytesBase64Encoded"`
+		MimeType           string `json:"mimeType"`
+	} `json:"predictions"`
+}
+
+// GenerateImage generates one square image and returns its bytes (PNG). It
+// retries a bounded number of times on HTTP 429 / 5xx and transport errors.
+func (p *GeminiImageProvider) GenerateImage(ctx context.Context, prompt string) ([]byte, error) {
+	if prompt == "" {
+		return nil, errors.New("image prompt is empty")
+	}
+	payload, err := json.Marshal(imagenPredictRequest{
+		Instances:  []imagenInstance{{Prompt: prompt}},
+		Parameters: imagenParameters{SampleCount: 1, AspectRatio: "1:1"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal imagen request: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/models/%s:predict", p.baseURL, p.model)
+
+	const maxAttempts = 3
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			if err := backoffWait(ctx, attempt); err != nil {
+				return nil, err
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("x-goog-api-key", p.apiKey)
+
+		resp, err := p.http.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("imagen request failed: %w", err)
+			continue
+		}
+		body, readErr := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+		resp.Body.Close()
+		if readErr != nil {
+			lastErr = fmt.Errorf("read imagen response: %w", readErr)
+			continue
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			var out imagenPredictResponse
+			if err := json.Unmarshal(body, &out); err != nil {
+				return nil, fmt.Errorf("unmarshal imagen response: %w", err)
+			}
+			// Imagen returns no predictions when its safety filter drops the image.
+			if len(out.Predictions) == 0 || out.Predictions[0].BytesBase64Encoded == "" {
+				return nil, errors.New("imagen returned no image")
+			}
+			data, err := base64.StdEncoding.DecodeString(out.Predictions[0].BytesBase64Encoded)
+			if err != nil {
+				return nil, fmt.Errorf("base64 decode error: %w", err)
+			}
+			return data, nil
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			lastErr = fmt.Errorf("imagen predict returned status %d: %s", resp.StatusCode, truncateBody(body))
+			continue
+		default:
+			return nil, fmt.Errorf("imagen predict returned status %d: %s", resp.StatusCode, truncateBody(body))
+		}
+	}
+	return nil, fmt.Errorf("imagen predict: exhausted %d attempts: %w", maxAttempts, lastErr)
+}
@@ -27,7 +27,7 @@ var ErrVideoTooLarge = errors.New("video exceeds inline size limit")
 // video inline (base64) to Google's native Gemini generateContent API and
 // forcing a create_recipe function call. It deliberately reuses the shared
 // recipe schema (recipeProperties), parsing (recipeToolResult /
-// toolResultToRecipeResult) and validation (validateRecipeResult) helpers so
+// toolResultToRecipeResult) and validation (finishRecipe) helpers so
 // its output is byte-for-byte faithful to the other providers.
 type GeminiVideoProvider struct {
 	apiKey     string
@@ -152,6 +152,7 @@ type geminiGenerateContentResponse struct {
 // returns ErrVideoTooLarge (without hitting the network) when the video exceeds
 // the inline size limit so the caller can fall back to frame sampling.
 func (p *GeminiVideoProvider) ExtractRecipesFromVideo(ctx context.Context, videoData []byte, mimeType, contextText, unitSystem, requirements string) ([]*RecipeResult, error) {
+	prompts := p.prompts.For("ExtractRecipesFromVideo")
 	if len(videoData) == 0 {
 		return nil, fmt.Errorf("gemini: empty video data")
 	}
@@ -172,14 +173,15 @@ func (p *GeminiVideoProvider) ExtractRecipesFromVideo(ctx context.Context, video
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) ([]*RecipeResult, error) {
 		// Reuse the exact vision prompt fields the frame-sampling path uses so
 		// native ingestion stays consistent with the other providers.
-		sysSuffix, err := config.RenderPrompt(p.prompts.Recipe.Import.Vision.System, map[string]interface{}{
+		sysSuffix, err := config.RenderPrompt(prompts.Recipe.Import.Vision.System, map[string]interface{}{
 			"UnitSystem":   unitSystem,
 			"Requirements": requirements,
+			"Locale":       LocaleFromContext(ctx).Market(),
 		})
 		if err != nil {
 			return nil, fmt.Errorf("render system prompt: %w", err)
 		}
-		systemPrompt := combineSystemPrompt(p.prompts.Recipe.Import.Vision.SystemPrefix, sysSuffix)
+		systemPrompt := combineSystemPrompt(prompts.Recipe.Import.Vision.SystemPrefix, sysSuffix)
 
 		// User parts: the inline video followed by the framing text. Mirrors the
 		// context framing used by ExtractRecipesFromMedia.
@@ -201,7 +203,7 @@ func (p *GeminiVideoProvider) ExtractRecipesFromVideo(ctx context.Context, video
 				FunctionDeclarations: []geminiFunctionDeclaration{{
 					Name:        "create_recipe",
 					Description: "Create a structured recipe definition with all required fields.",
-					Parameters:  schemaObject(recipeProperties(p.prompts.Recipe.Summarize.Recipe)),
+					Parameters:  schemaObject(recipeProperties(prompts.Recipe.Summarize.Recipe)),
 				}},
 			}},
 			ToolConfig: &geminiToolConfig{
@@ -236,11 +238,11 @@ func (p *GeminiVideoProvider) ExtractRecipesFromVideo(ctx context.Context, video
 			return nil, NewAIError(FailureContentParse, fmt.Errorf("failed to unmarshal recipe: %w", err), "failed to parse recipe tool result")
 		}
 
-		result := toolResultToRecipeResult(&tr)
-		if err := validateRecipeResult(result); err != nil {
+		result, err := finishRecipe(ctx, toolResultToRecipeResult(&tr), nil)
+		if err != nil {
 			return nil, err
 		}
-		result.PromptVersion = config.PromptVersion(p.prompts)
+		result.PromptVersion = config.PromptVersion(prompts)
 		return []*RecipeResult{result}, nil
 	})
 }
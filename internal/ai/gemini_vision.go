@@ -18,7 +18,7 @@ import (
 // using Google's native Gemini generateContent API with forced function calling.
 // It deliberately reuses the shared recipe schema (recipeProperties), parsing
 // (recipeToolResult / multiRecipeToolResult / toolResultToRecipeResult) and
-// validation (validateRecipeResult) helpers so its output is byte-for-byte
+// validation (finishRecipe) helpers so its output is byte-for-byte
 // faithful to the Anthropic vision provider.
 type GeminiVisionProvider struct {
 	apiKey     string
@@ -62,20 +62,22 @@ func (p *GeminiVisionProvider) httpClient() *http.Client {
 
 // visionSystemPrompt renders the shared vision system prompt (prefix + dynamic
 // suffix) exactly as the Anthropic provider does.
-func (p *GeminiVisionProvider) visionSystemPrompt(unitSystem, requirements string) (string, error) {
-	sysSuffix, err := config.RenderPrompt(p.prompts.Recipe.Import.Vision.System, map[string]interface{}{
+func (p *GeminiVisionProvider) visionSystemPrompt(ctx context.Context, prompts *config.Prompts, unitSystem, requirements string) (string, error) {
+	sysSuffix, err := config.RenderPrompt(prompts.Recipe.Import.Vision.System, map[string]interface{}{
 		"UnitSystem":   unitSystem,
 		"Requirements": requirements,
+		"Locale":       LocaleFromContext(ctx).Market(),
 	})
 	if err != nil {
 		return "", fmt.Errorf("render system prompt: %w", err)
 	}
-	return combineSystemPrompt(p.prompts.Recipe.Import.Vision.SystemPrefix, sysSuffix), nil
+	return combineSystemPrompt(prompts.Recipe.Import.Vision.SystemPrefix, sysSuffix), nil
 }
 
 // ExtractRecipeFromImage extracts a single structured recipe from a photo via a
 // forced create_recipe function call. Mirrors AnthropicProvider.ExtractRecipeFromImage.
 func (p *GeminiVisionProvider) ExtractRecipeFromImage(ctx context.Context, imageData []byte, unitSystem string, requirements string) (*RecipeResult, error) {
+	prompts := p.prompts.For("ExtractRecipeFromImage")
 	op := AIOperation{
 		Name:      "ExtractRecipeFromImage",
 		Provider:  "gemini",
@@ -84,7 +86,7 @@ func (p *GeminiVisionProvider) ExtractRecipeFromImage(ctx context.Context, image
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (*RecipeResult, error) {
-		systemPrompt, err := p.visionSystemPrompt(unitSystem, requirements)
+		systemPrompt, err := p.visionSystemPrompt(ctx, prompts, unitSystem, requirements)
 		if err != nil {
 			return nil, err
 		}
@@ -101,7 +103,7 @@ func (p *GeminiVisionProvider) ExtractRecipeFromImage(ctx context.Context, image
 				FunctionDeclarations: []geminiFunctionDeclaration{{
 					Name:        "create_recipe",
 					Description: "Create a structured recipe definition with all required fields.",
-					Parameters:  schemaObject(recipeProperties(p.prompts.Recipe.Summarize.Recipe)),
+					Parameters:  schemaObject(recipeProperties(prompts.Recipe.Summarize.Recipe)),
 				}},
 			}},
 			ToolConfig: &geminiToolConfig{
@@ -135,11 +137,11 @@ func (p *GeminiVisionProvider) ExtractRecipeFromImage(ctx context.Context, image
 			return nil, NewAIError(FailureContentParse, fmt.Errorf("failed to unmarshal recipe: %w", err), "failed to parse recipe tool result")
 		}
 
-		result := toolResultToRecipeResult(&tr)
-		if err := validateRecipeResult(result); err != nil {
+		result, err := finishRecipe(ctx, toolResultToRecipeResult(&tr), nil)
+		if err != nil {
 			return nil, err
 		}
-		result.PromptVersion = config.PromptVersion(p.prompts)
+		result.PromptVersion = config.PromptVersion(prompts)
 		return result, nil
 	})
 }
@@ -148,6 +150,7 @@ func (p *GeminiVisionProvider) ExtractRecipeFromImage(ctx context.Context, image
 // provided images and/or PDF documents in a single native Gemini request.
 // Mirrors AnthropicProvider.ExtractRecipesFromMedia.
 func (p *GeminiVisionProvider) ExtractRecipesFromMedia(ctx context.Context, media []MediaInput, contextText string, unitSystem string, requirements string) ([]*RecipeResult, error) {
+	prompts := p.prompts.For("ExtractRecipesFromMedia")
 	op := AIOperation{
 		Name:      "ExtractRecipesFromMedia",
 		Provider:  "gemini",
@@ -156,7 +159,7 @@ func (p *GeminiVisionProvider) ExtractRecipesFromMedia(ctx context.Context, medi
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) ([]*RecipeResult, error) {
-		systemPrompt, err := p.visionSystemPrompt(unitSystem, requirements)
+		systemPrompt, err := p.visionSystemPrompt(ctx, prompts, unitSystem, requirements)
 		if err != nil {
 			return nil, err
 		}
@@ -183,7 +186,7 @@ func (p *GeminiVisionProvider) ExtractRecipesFromMedia(ctx context.Context, medi
 				FunctionDeclarations: []geminiFunctionDeclaration{{
 					Name:        "extract_recipes",
 					Description: "Extract every distinct recipe found across the provided images and documents. Return one entry per recipe.",
-					Parameters:  multiRecipeSchema(p.prompts.Recipe.Summarize.Recipe),
+					Parameters:  multiRecipeSchema(prompts.Recipe.Summarize.Recipe),
 				}},
 			}},
 			ToolConfig: &geminiToolConfig{
@@ -218,12 +221,12 @@ func (p *GeminiVisionProvider) ExtractRecipesFromMedia(ctx context.Context, medi
 			return nil, NewAIError(FailureContentParse, fmt.Errorf("failed to unmarshal recipes: %w", err), "failed to parse recipes tool result")
 		}
 
-		pv := config.PromptVersion(p.prompts)
+		pv := config.PromptVersion(prompts)
 		valid := make([]*RecipeResult, 0, len(tr.Recipes))
 		for i := range tr.Recipes {
-			r := toolResultToRecipeResult(&tr.Recipes[i])
-			if err := validateRecipeResult(r); err != nil {
-				logger.Get().Warn("skipping invalid extracted recipe", zap.String("title", r.Title), zap.Error(err))
+			r, err := finishRecipe(ctx, toolResultToRecipeResult(&tr.Recipes[i]), nil)
+			if err != nil {
+				logger.Get().Warn("skipping invalid extracted recipe", zap.Error(err))
 				continue
 			}
 			r.PromptVersion = pv
@@ -0,0 +1,114 @@
+package ai
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Default image models per provider, used when a spec leaves the model blank.
+const (
+	openAIDefaultImageModel = "dall-e-3"
+	geminiDefaultImageModel = "imagen-3.0-generate-002"
+)
+
+// BuildImageProvider constructs the ImageProvider for a spec, mirroring
+// BuildLightProvider: openai serves the OpenAI images API (or any
+// OpenAI-compatible endpoint at BaseURL); gemini serves Imagen through the
+// Gemini API (BaseURL overrides its API root); replay serves the loaded
+// cassette. Returns an error when the provider's API key is missing or the
+// provider can't generate images.
+func BuildImageProvider(spec LightProviderSpec, keys LightKeys) (ImageProvider, error) {
+	switch spec.Provider {
+	case "openai", "":
+		if keys.OpenAIAPIKey == "" {
+			return nil, fmt.Errorf("image provider openai selected but OPENAI_API_KEY is not set")
+		}
+		model := spec.Model
+		if model == "" {
+			model = openAIDefaultImageModel
+		}
+		return NewOpenAIImageProvider(keys.OpenAIAPIKey, spec.BaseURL, model), nil
+	case "gemini":
+		if keys.GeminiAPIKey == "" {
+			return nil, fmt.Errorf("image provider gemini selected but GEMINI_API_KEY is not set")
+		}
+		model := spec.Model
+		if model == "" {
+			model = geminiDefaultImageModel
+		}
+		p := NewGeminiImageProvider(keys.GeminiAPIKey, model)
+		if spec.BaseURL != "" {
+			p.baseURL = spec.BaseURL
+		}
+		return p, nil
+	case "replay":
+		if keys.Replay == nil {
+			return nil, fmt.Errorf("replay provider selected but no AI cassette is loaded")
+		}
+		return keys.Replay, nil
+	default:
+		return nil, fmt.Errorf("unknown image provider %q", spec.Provider)
+	}
+}
+
+// imageProbePrompt is the validation probe's prompt: small, unambiguous and
+// well inside every provider's content policy.
+const imageProbePrompt = "A single red apple on a plain white background."
+
+// ValidateImageModel is ValidateModel for image models: it builds the provider
+// and generates one real image, so the probe costs one image at the model's
+// list price. A non-nil error means the model must not serve GenerateImage.
+func ValidateImageModel(ctx context.Context, spec LightProviderSpec, keys LightKeys) error {
+	p, err := BuildImageProvider(spec, keys)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 90*time.Second)
+	defer cancel()
+	img, err := p.GenerateImage(ctx, imageProbePrompt)
+	if err != nil {
+		return fmt.Errorf("validation probe failed: %w", err)
+	}
+	if len(img) == 0 {
+		return fmt.Errorf("validation probe failed: empty image")
+	}
+	return nil
+}
+
+// BuildSpeechSynthesisProvider constructs the read-aloud provider for a spec:
+// openai serves OpenAI TTS (spec.Model defaults to tts-1); local serves the
+// keyless LocalSpeechProvider stand-in; replay serves the loaded cassette.
+func BuildSpeechSynthesisProvider(spec LightProviderSpec, keys LightKeys) (SpeechSynthesisProvider, error) {
+	switch spec.Provider {
+	case "openai", "":
+		if keys.OpenAIAPIKey == "" {
+			return nil, fmt.Errorf("tts provider openai selected but OPENAI_API_KEY is not set")
+		}
+		return NewOpenAITTSProvider(keys.OpenAIAPIKey, spec.Model), nil
+	case "local":
+		return NewLocalSpeechProvider(), nil
+	case "replay":
+		if keys.Replay == nil {
+			return nil, fmt.Errorf("replay provider selected but no AI cassette is loaded")
+		}
+		return keys.Replay, nil
+	default:
+		return nil, fmt.Errorf("unknown tts provider %q", spec.Provider)
+	}
+}
+
+// SpeechSynthesisModel names the provider and model BuildSpeechSynthesisProvider
+// builds for spec, such as "openai/tts-1" or "local", so audio cached from one
+// synthesizer is never served as another's.
+func SpeechSynthesisModel(spec LightProviderSpec) string {
+	switch spec.Provider {
+	case "openai", "":
+		return "openai/" + cmp.Or(spec.Model, string(openai.TTSModel1))
+	default:
+		return spec.Provider
+	}
+}
@@ -0,0 +1,153 @@
+package ai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBuildImageProvider(t *testing.T) {
+	keys := LightKeys{OpenAIAPIKey: "k", GeminiAPIKey: "k"}
+	for _, provider := range []string{"openai", "gemini", ""} {
+		if _, err := BuildImageProvider(LightProviderSpec{Provider: provider}, keys); err != nil {
+			t.Errorf("BuildImageProvider(%q): unexpected error %v", provider, err)
+		}
+		if provider != "" {
+			if _, err := BuildImageProvider(LightProviderSpec{Provider: provider}, LightKeys{}); err == nil {
+				t.Errorf("BuildImageProvider(%s) with no key: expected error", provider)
+			}
+		}
+	}
+	// Text-only providers can't generate images.
+	for _, provider := range []string{"anthropic", "deepseek"} {
+		if _, err := BuildImageProvider(LightProviderSpec{Provider: provider}, keys); err == nil {
+			t.Errorf("BuildImageProvider(%s): expected error", provider)
+		}
+	}
+}
+
+func TestBuildSpeechSynthesisProvider(t *testing.T) {
+	if _, err := BuildSpeechSynthesisProvider(LightProviderSpec{Provider: "openai"}, LightKeys{OpenAIAPIKey: "k"}); err != nil {
+		t.Errorf("openai: unexpected error %v", err)
+	}
+	if _, err := BuildSpeechSynthesisProvider(LightProviderSpec{Provider: "openai"}, LightKeys{}); err == nil {
+		t.Error("openai with no key: expected error")
+	}
+	// The local stand-in needs no key.
+	if _, err := BuildSpeechSynthesisProvider(LightProviderSpec{Provider: "local"}, LightKeys{}); err != nil {
+		t.Errorf("local: unexpected error %v", err)
+	}
+	if _, err := BuildSpeechSynthesisProvider(LightProviderSpec{Provider: "anthropic"}, LightKeys{OpenAIAPIKey: "k"}); err == nil {
+		t.Error("anthropic: expected error")
+	}
+}
+
+func TestSpeechSynthesisModel(t *testing.T) {
+	cases := map[LightProviderSpec]string{
+		{}:                                      "openai/tts-1",
+		{Provider: "openai", Model: "tts-1-hd"}: "openai/tts-1-hd",
+		{Provider: "local"}:                     "local",
+	}
+	for spec, want := range cases {
+		if got := SpeechSynthesisModel(spec); got != want {
+			t.Errorf("SpeechSynthesisModel(%+v) = %q, want %q", spec, got, want)
+		}
+	}
+}
+
+func TestOpenAIImageProvider_CompatibleEndpoint(t *testing.T) {
+	var gotModel string
+	var gotFormat interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/images/generations" {
+			http.NotFound(w, r)
+			return
+		}
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		gotModel, _ = body["model"].(string)
+		gotFormat = body["response_format"]
+		fmt.Fprintf(w, `{"data":[{"b64_json":%q}]}`, base64.StdEncoding.EncodeToString([]byte("png")))
+	}))
+	defer srv.Close()
+
+	p := NewOpenAIImageProvider("k", srv.URL, "gpt-image-1")
+	img, err := p.GenerateImage(context.Background(), "toast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(img) != "png" || gotModel != "gpt-image-1" {
+		t.Errorf("got %q from model %q, want png from gpt-image-1", img, gotModel)
+	}
+	if gotFormat != nil {
+		t.Errorf("response_format = %v, want it omitted for gpt-image models", gotFormat)
+	}
+}
+
+func TestOpenAIImageProvider_DownloadsURLResponse(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/images/generations":
+			fmt.Fprintf(w, `{"data":[{"url":%q}]}`, srv.URL+"/out.png")
+		case "/out.png":
+			_, _ = w.Write([]byte("downloaded"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	img, err := NewOpenAIImageProvider("k", srv.URL, "dall-e-3").GenerateImage(context.Background(), "toast")
+	if err != nil || string(img) != "downloaded" {
+		t.Errorf("got %q, %v; want the image behind the returned URL", img, err)
+	}
+}
+
+func TestGeminiImageProvider_Predict(t *testing.T) {
+	var gotPath, gotKey, gotPrompt string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotKey = r.Header.Get("x-goog-api-key")
+		var body imagenPredictRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Instances) == 1 {
+			gotPrompt = body.Instances[0].Prompt
+		}
+		fmt.Fprintf(w, `{"predictions":[{"bytesBase64Encoded":%q,"mimeType":"image/png"}]}`,
+			base64.StdEncoding.EncodeToString([]byte("imagen")))
+	}))
+	defer srv.Close()
+
+	p, err := BuildImageProvider(LightProviderSpec{Provider: "gemini", BaseURL: srv.URL}, LightKeys{GeminiAPIKey: "gk"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := p.GenerateImage(context.Background(), "toast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(img) != "imagen" {
+		t.Errorf("image = %q, want imagen", img)
+	}
+	if gotPath != "/models/"+geminiDefaultImageModel+":predict" || gotKey != "gk" || gotPrompt != "toast" {
+		t.Errorf("request = %s key %q prompt %q", gotPath, gotKey, gotPrompt)
+	}
+}
+
+func TestGeminiImageProvider_FilteredIsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	p := NewGeminiImageProvider("k", "imagen-3.0-generate-002")
+	p.baseURL = srv.URL
+	if _, err := p.GenerateImage(context.Background(), "toast"); err == nil {
+		t.Error("expected an error when Imagen returns no predictions")
+	}
+}
@@ -24,7 +24,7 @@ const (
 // id, and an optional endpoint override. The zero Provider ("") is treated as
 // "anthropic" (the Haiku default).
 type LightProviderSpec struct {
-	Provider string `json:"provider"` // anthropic|openai|gemini|deepseek
+	Provider string `json:"provider"` // anthropic|openai|gemini|deepseek|replay
 	Model    string `json:"model"`
 	BaseURL  string `json:"base_url"`
 }
@@ -37,11 +37,15 @@ type LightKeys struct {
 	OpenAIAPIKey        string
 	GeminiAPIKey        string
 	DeepSeekAPIKey      string
+	// Replay serves the "replay" provider from a recorded cassette; nil when
+	// no cassette is configured.
+	Replay *ReplayProvider
 }
 
 // BuildLightProvider constructs the cheap "light" tier TextProvider for a spec.
 // Anthropic uses the Claude Haiku client; openai/gemini/deepseek use the shared
-// OpenAI-compatible provider with the appropriate base URL. mw (cost metering +
+// OpenAI-compatible provider with the appropriate base URL; replay serves the
+// loaded cassette (keys.Replay) with no network. mw (cost metering +
 // logging) is attached when non-nil. Returns an error when the required API key
 // for the selected provider is missing or the provider name is unknown — so a
 // misconfigured switch fails loudly instead of silently doing nothing.
@@ -94,6 +98,11 @@ func BuildLightProvider(spec LightProviderSpec, keys LightKeys, prompts *config.
 			p.WithMiddleware(mw)
 		}
 		return p, nil
+	case "replay":
+		if keys.Replay == nil {
+			return nil, fmt.Errorf("replay provider selected but no AI cassette is loaded")
+		}
+		return keys.Replay, nil
 	case "anthropic", "":
 		model := spec.Model
 		if model == "" {
@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+)
+
+// LocalSpeechProvider is a SpeechSynthesisProvider stand-in for development
+// and tests: it needs no API key and no network, and renders text as a WAV of
+// one soft beep per word at a natural speaking pace, so clients can exercise
+// read-aloud playback end to end. It is deterministic, so cached step audio
+// stays stable across runs.
+type LocalSpeechProvider struct{}
+
+// NewLocalSpeechProvider creates the local text-to-speech stand-in.
+func NewLocalSpeechProvider() *LocalSpeechProvider {
+	return &LocalSpeechProvider{}
+}
+
+const (
+	localSampleRate = 8000
+	// localWordSeconds paces words at ~150 per minute; each word is a beep
+	// followed by silence.
+	localWordSeconds = 0.4
+	localBeepSeconds = 0.12
+	localBeepHz      = 440
+	// localMaxWords caps the clip at a minute or so for runaway input.
+	localMaxWords = 150
+)
+
+// SynthesizeSpeech renders text as a 16-bit mono WAV. voice is ignored.
+func (p *LocalSpeechProvider) SynthesizeSpeech(ctx context.Context, text, voice string) (*SpeechAudio, error) {
+	words := len(strings.Fields(text))
+	if words == 0 {
+		return nil, errors.New("speech text is empty")
+	}
+	words = min(words, localMaxWords)
+
+	wordSamples := int(localWordSeconds * localSampleRate)
+	beepSamples := int(localBeepSeconds * localSampleRate)
+	samples := make([]int16, words*wordSamples)
+	for w := 0; w < words; w++ {
+		for i := 0; i < beepSamples; i++ {
+			t := float64(i) / localSampleRate
+			samples[w*wordSamples+i] = int16(4000 * math.Sin(2*math.Pi*localBeepHz*t))
+		}
+	}
+	return &SpeechAudio{Data: encodeWAV(samples, localSampleRate), Format: "wav"}, nil
+}
+
+// encodeWAV wraps 16-bit mono PCM samples in a RIFF/WAVE container.
+func encodeWAV(samples []int16, sampleRate int) []byte {
+	dataSize := len(samples) * 2
+	var buf bytes.Buffer
+	buf.Grow(44 + dataSize)
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(36+dataSize))
+	buf.WriteString("WAVEfmt ")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(16))           // fmt chunk size
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(1))            // PCM
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(1))            // mono
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))   // sample rate
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*2)) // byte rate
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(2))            // block align
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(16))           // bits per sample
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(dataSize))
+	_ = binary.Write(&buf, binary.LittleEndian, samples)
+	return buf.Bytes()
+}
@@ -0,0 +1,40 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+)
+
+func TestLocalSpeechProvider_RendersPacedWAV(t *testing.T) {
+	p := NewLocalSpeechProvider()
+	short, err := p.SynthesizeSpeech(context.Background(), "Whisk the eggs.", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if short.Format != "wav" || short.ContentType() != "audio/wav" {
+		t.Errorf("format = %q (%s), want wav", short.Format, short.ContentType())
+	}
+	if !bytes.HasPrefix(short.Data, []byte("RIFF")) || string(short.Data[8:16]) != "WAVEfmt " {
+		t.Fatal("not a RIFF/WAVE file")
+	}
+	if size := binary.LittleEndian.Uint32(short.Data[40:44]); int(size) != len(short.Data)-44 {
+		t.Errorf("data chunk size = %d, want %d", size, len(short.Data)-44)
+	}
+
+	long, _ := p.SynthesizeSpeech(context.Background(), "Whisk the eggs until pale and thick.", "")
+	if len(long.Data) <= len(short.Data) {
+		t.Error("longer text should render longer audio")
+	}
+	again, _ := p.SynthesizeSpeech(context.Background(), "Whisk the eggs.", "nova")
+	if !bytes.Equal(again.Data, short.Data) {
+		t.Error("output should be deterministic")
+	}
+}
+
+func TestLocalSpeechProvider_RejectsEmptyText(t *testing.T) {
+	if _, err := NewLocalSpeechProvider().SynthesizeSpeech(context.Background(), "  ", ""); err == nil {
+		t.Error("want an error for empty text")
+	}
+}
@@ -0,0 +1,83 @@
+package ai
+
+import (
+	"context"
+	"strings"
+)
+
+// Locale is the user's language and region, carried on the request context so
+// search backends can localize results and extraction prompts can name
+// ingredients the way the user does ("double cream" vs "heavy cream").
+type Locale struct {
+	Language string // BCP 47 tag, e.g. "en-GB"; may be just a language ("fr")
+	Region   string // ISO 3166-1 alpha-2, e.g. "GB"
+}
+
+type localeKey struct{}
+
+// WithLocale returns a context carrying loc. A zero Locale leaves ctx as is.
+func WithLocale(ctx context.Context, loc Locale) context.Context {
+	if loc.IsZero() {
+		return ctx
+	}
+	return context.WithValue(ctx, localeKey{}, loc)
+}
+
+// LocaleFromContext returns the Locale attached by WithLocale, or the zero
+// Locale when there is none.
+func LocaleFromContext(ctx context.Context) Locale {
+	loc, _ := ctx.Value(localeKey{}).(Locale)
+	return loc
+}
+
+// IsZero reports whether no locale information is set.
+func (l Locale) IsZero() bool {
+	return l.Language == "" && l.Region == ""
+}
+
+// LanguageCode returns the lowercase primary language subtag ("en").
+func (l Locale) LanguageCode() string {
+	lang, _, _ := strings.Cut(l.Language, "-")
+	return strings.ToLower(lang)
+}
+
+// CountryCode returns the uppercase country: Region when set, otherwise the
+// region subtag of Language ("en-GB" → "GB"), otherwise "".
+func (l Locale) CountryCode() string {
+	if l.Region != "" {
+		return strings.ToUpper(l.Region)
+	}
+	for _, sub := range strings.Split(l.Language, "-")[1:] {
+		if len(sub) == 2 {
+			return strings.ToUpper(sub)
+		}
+	}
+	return ""
+}
+
+// Market returns the language-country pair search APIs expect ("en-GB"),
+// degrading to whichever half is known.
+func (l Locale) Market() string {
+	lang, country := l.LanguageCode(), l.CountryCode()
+	switch {
+	case lang != "" && country != "":
+		return lang + "-" + country
+	case lang != "":
+		return lang
+	default:
+		return country
+	}
+}
+
+// CacheKey is the locale's contribution to a search cache key: the lowercased
+// market, or "" for no locale (which shares the legacy, locale-less entries).
+func (l Locale) CacheKey() string {
+	return strings.ToLower(l.Market())
+}
+
+// ParseLocaleKey is the inverse of CacheKey, for replaying a cached query
+// (e.g. refreshing a hot search) in the locale it was cached under.
+func ParseLocaleKey(key string) Locale {
+	lang, country, _ := strings.Cut(key, "-")
+	return Locale{Language: lang, Region: strings.ToUpper(country)}
+}
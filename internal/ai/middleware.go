@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/windoze95/saltybytes-api/internal/logger"
@@ -14,6 +15,36 @@ type AIOperation struct {
 	Provider  string // e.g. "anthropic"
 	Model     string // e.g. "claude-3-5-sonnet-20241022"
 	StartTime time.Time
+	// Attribution is who and what the call was made for, taken from the
+	// context (WithAttribution) when the call starts.
+	Attribution CallAttribution
+	// Fallback is set when the call is an attempt by a FallbackTextProvider.
+	Fallback FallbackInfo
+	// Experiment is set when the call was served by an experiment arm.
+	Experiment ExperimentInfo
+}
+
+// CallAttribution ties an AI call to the request that caused it, so its cost
+// can be charged to a user and broken down by endpoint. Zero fields mean the
+// call ran outside a user request (background warming, refreshes).
+type CallAttribution struct {
+	UserID    uint
+	RequestID string
+	Endpoint  string // route pattern, e.g. "/v1/recipes/:id/regenerate"
+}
+
+type attributionKey struct{}
+
+// WithAttribution returns a context whose AI calls are attributed to a.
+func WithAttribution(ctx context.Context, a CallAttribution) context.Context {
+	return context.WithValue(ctx, attributionKey{}, a)
+}
+
+// AttributionFromContext returns the CallAttribution attached by
+// WithAttribution, or the zero value when there is none.
+func AttributionFromContext(ctx context.Context) CallAttribution {
+	a, _ := ctx.Value(attributionKey{}).(CallAttribution)
+	return a
 }
 
 // TokenUsage is the token consumption reported by a provider call.
@@ -29,6 +60,8 @@ type AIOperationResult struct {
 	Duration  time.Duration
 	Err       error
 	Usage     TokenUsage
+	// Repair is set when the call returned a recipe that needed fixing.
+	Repair RecipeRepair
 }
 
 type usageKeyType struct{}
@@ -36,12 +69,62 @@ type usageKeyType struct{}
 // usageKey marks the per-call usage sink stored in the context.
 var usageKey usageKeyType
 
-// recordUsage reports a provider call's token usage to the in-flight sink so the
-// middleware can meter cost. A no-op if no sink is in the context.
+// recordUsage adds a provider call's token usage to the in-flight sink so the
+// middleware can meter cost. An operation that makes several calls (a repair
+// turn, a re-ask) is metered for all of them. A no-op if no sink is in the
+// context.
 func recordUsage(ctx context.Context, u TokenUsage) {
 	if sink, ok := ctx.Value(usageKey).(*TokenUsage); ok && sink != nil {
-		*sink = u
+		sink.InputTokens += u.InputTokens
+		sink.OutputTokens += u.OutputTokens
+		sink.CacheInputTokens += u.CacheInputTokens
+	}
+}
+
+type callCaptureKeyType struct{}
+
+// callCaptureKey marks the CallCapture stored in the context.
+var callCaptureKey callCaptureKeyType
+
+// CallCapture collects the outcome (operation, duration, token usage, error) of
+// every AI call made under a context from WithCallCapture — so a caller can
+// learn which model served a call and what it cost without owning the
+// provider's middleware. Safe for concurrent use.
+type CallCapture struct {
+	mu      sync.Mutex
+	results []AIOperationResult
+}
+
+// WithCallCapture returns a context whose AI calls are recorded on the
+// returned CallCapture.
+func WithCallCapture(ctx context.Context) (context.Context, *CallCapture) {
+	c := &CallCapture{}
+	return context.WithValue(ctx, callCaptureKey, c), c
+}
+
+// Last returns the most recently finished call, false if none finished yet.
+func (c *CallCapture) Last() (AIOperationResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.results) == 0 {
+		return AIOperationResult{}, false
 	}
+	return c.results[len(c.results)-1], true
+}
+
+// Results returns every captured call in completion order.
+func (c *CallCapture) Results() []AIOperationResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]AIOperationResult, len(c.results))
+	copy(out, c.results)
+	return out
+}
+
+func (c *CallCapture) add(r AIOperationResult) {
+	c.mu.Lock()
+	c.results = append(c.results, r)
+	c.mu.Unlock()
 }
 
 // AIMiddleware intercepts AI calls for observability and control.
@@ -50,6 +133,14 @@ type AIMiddleware interface {
 	After(ctx context.Context, result AIOperationResult)
 }
 
+// AdmissionMiddleware is an AIMiddleware that can hold a call before it
+// starts, or refuse it (see RateLimiter). Admit runs before Before; a refused
+// call never reaches the provider, but After still sees its error.
+type AdmissionMiddleware interface {
+	AIMiddleware
+	Admit(ctx context.Context, op AIOperation) (context.Context, error)
+}
+
 // LoggingMiddleware logs every AI operation with timing and error info.
 type LoggingMiddleware struct{}
 
@@ -94,6 +185,20 @@ func (c *MiddlewareChain) Before(ctx context.Context, op AIOperation) context.Co
 	return ctx
 }
 
+// Admit runs every admission middleware in order, stopping at the first
+// refusal.
+func (c *MiddlewareChain) Admit(ctx context.Context, op AIOperation) (context.Context, error) {
+	for _, mw := range c.middlewares {
+		if a, ok := mw.(AdmissionMiddleware); ok {
+			var err error
+			if ctx, err = a.Admit(ctx, op); err != nil {
+				return ctx, err
+			}
+		}
+	}
+	return ctx, nil
+}
+
 func (c *MiddlewareChain) After(ctx context.Context, result AIOperationResult) {
 	// Run in reverse order (like defer)
 	for i := len(c.middlewares) - 1; i >= 0; i-- {
@@ -107,20 +212,36 @@ func runWithMiddleware[T any](ctx context.Context, mw AIMiddleware, op AIOperati
 	// Install a usage sink the provider call fills via recordUsage.
 	var usage TokenUsage
 	ctx = context.WithValue(ctx, usageKey, &usage)
+	var repair RecipeRepair
+	ctx = context.WithValue(ctx, repairKey, &repair)
+	op.Attribution = AttributionFromContext(ctx)
+	op.Fallback = fallbackInfoFromContext(ctx)
+	op.Experiment = experimentInfoFromContext(ctx)
 
-	if mw != nil {
-		ctx = mw.Before(ctx, op)
+	var result T
+	var err error
+	if a, ok := mw.(AdmissionMiddleware); ok {
+		ctx, err = a.Admit(ctx, op)
+	}
+	if err == nil {
+		if mw != nil {
+			ctx = mw.Before(ctx, op)
+		}
+		result, err = fn(ctx)
 	}
 
-	result, err := fn(ctx)
-
+	opResult := AIOperationResult{
+		Operation: op,
+		Duration:  time.Since(op.StartTime),
+		Err:       err,
+		Usage:     usage,
+		Repair:    repair,
+	}
 	if mw != nil {
-		mw.After(ctx, AIOperationResult{
-			Operation: op,
-			Duration:  time.Since(op.StartTime),
-			Err:       err,
-			Usage:     usage,
-		})
+		mw.After(ctx, opResult)
+	}
+	if c, ok := ctx.Value(callCaptureKey).(*CallCapture); ok && c != nil {
+		c.add(opResult)
 	}
 
 	return result, err
@@ -137,6 +258,22 @@ type UsageRecord struct {
 	CostUSD          float64
 	DurationMS       int64
 	Success          bool
+	UserID           uint
+	RequestID        string
+	Endpoint         string
+	// FailoverAttempt is 0 for a first-choice call and n for the nth fallback
+	// in a FallbackTextProvider chain; FailoverFrom names the first choice
+	// when the call was failed over.
+	FailoverAttempt int
+	FailoverFrom    string
+	// Experiment and ExperimentArm name the A/B arm that served the call.
+	Experiment    string
+	ExperimentArm string
+	// RepairIssues, RepairTurns and RepairCleaned record what it took to fix
+	// the call's recipe output (see RecipeRepair).
+	RepairIssues  int
+	RepairTurns   int
+	RepairCleaned bool
 }
 
 // CostMiddleware meters each AI call's token usage + cost and hands it to a sink
@@ -173,5 +310,23 @@ func (m *CostMiddleware) After(ctx context.Context, result AIOperationResult) {
 		CostUSD:          pricing.Cost(result.Operation.Model, u),
 		DurationMS:       result.Duration.Milliseconds(),
 		Success:          result.Err == nil,
+		UserID:           result.Operation.Attribution.UserID,
+		RequestID:        result.Operation.Attribution.RequestID,
+		Endpoint:         result.Operation.Attribution.Endpoint,
+		FailoverAttempt:  result.Operation.Fallback.Attempt,
+		FailoverFrom:     failoverFrom(result.Operation.Fallback),
+		Experiment:       result.Operation.Experiment.Experiment,
+		ExperimentArm:    result.Operation.Experiment.Arm,
+		RepairIssues:     result.Repair.Issues,
+		RepairTurns:      result.Repair.Turns,
+		RepairCleaned:    result.Repair.Cleaned,
 	})
 }
+
+// failoverFrom is the chain's first choice for a failed-over call, else "".
+func failoverFrom(f FallbackInfo) string {
+	if f.Attempt == 0 {
+		return ""
+	}
+	return f.Primary
+}
@@ -5,6 +5,9 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
 	"time"
 
 	openai "github.com/sashabaranov/go-openai"
@@ -12,37 +15,69 @@ import (
 	"go.uber.org/zap"
 )
 
-// DALLEProvider implements ImageProvider using OpenAI DALL-E 3.
+// DALLEProvider implements ImageProvider over the OpenAI images API: DALL-E 3
+// by default, or any model on an OpenAI-compatible endpoint (gpt-image-1, a
+// self-hosted server).
 type DALLEProvider struct {
-	apiKey string
+	apiKey  string
+	baseURL string
+	model   string
+	http    *http.Client
 }
 
 // NewDALLEProvider creates a new DALL-E image generation provider.
 func NewDALLEProvider(apiKey string) *DALLEProvider {
-	return &DALLEProvider{apiKey: apiKey}
+	return NewOpenAIImageProvider(apiKey, "", openai.CreateImageModelDallE3)
 }
 
-// GenerateImage generates an image using DALL-E 3 and returns the raw bytes.
+// NewOpenAIImageProvider creates an image provider for model on the OpenAI
+// images API, or on the OpenAI-compatible endpoint at baseURL when non-empty.
+func NewOpenAIImageProvider(apiKey, baseURL, model string) *DALLEProvider {
+	return &DALLEProvider{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		model:   model,
+		http:    &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// GenerateImage generates an image and returns the raw bytes.
 func (p *DALLEProvider) GenerateImage(ctx context.Context, prompt string) ([]byte, error) {
 	if prompt == "" {
 		return nil, errors.New("image prompt is empty")
 	}
 
-	client := openai.NewClient(p.apiKey)
+	clientCfg := openai.DefaultConfig(p.apiKey)
+	if p.baseURL != "" {
+		clientCfg.BaseURL = p.baseURL
+	}
+	client := openai.NewClientWithConfig(clientCfg)
+	req := openai.ImageRequest{
+		Model:  p.model,
+		Prompt: prompt,
+		Size:   openai.CreateImageSize1024x1024,
+		N:      1,
+	}
+	// Only the DALL-E models take response_format; gpt-image models always
+	// return base64 and reject the parameter.
+	if strings.HasPrefix(p.model, "dall-e") {
+		req.ResponseFormat = openai.CreateImageResponseFormatB64JSON
+	}
 	const maxRetries = 3
 	var lastErr error
 
 	for i := 0; i < maxRetries; i++ {
-		resp, err := client.CreateImage(ctx, openai.ImageRequest{
-			Model:          openai.CreateImageModelDallE3,
-			Prompt:         prompt,
-			Size:           openai.CreateImageSize1024x1024,
-			ResponseFormat: openai.CreateImageResponseFormatB64JSON,
-			N:              1,
-		})
+		resp, err := client.CreateImage(ctx, req)
 		if err == nil {
-			if len(resp.Data) == 0 || resp.Data[0].B64JSON == "" {
-				return nil, errors.New("DALL-E API returned an empty image")
+			if len(resp.Data) == 0 {
+				return nil, errors.New("image API returned an empty image")
+			}
+			if resp.Data[0].B64JSON == "" {
+				// Some compatible servers ignore response_format and return a URL.
+				if resp.Data[0].URL == "" {
+					return nil, errors.New("image API returned an empty image")
+				}
+				return p.download(ctx, resp.Data[0].URL)
 			}
 			imgBytes, decErr := base64.StdEncoding.DecodeString(resp.Data[0].B64JSON)
 			if decErr != nil {
@@ -74,6 +109,33 @@ func (p *DALLEProvider) GenerateImage(ctx context.Context, prompt string) ([]byt
 	return nil, fmt.Errorf("DALL-E API: exhausted %d retries: %w", maxRetries, lastErr)
 }
 
+// maxImageBytes caps an image fetched from a returned URL.
+const maxImageBytes = 20 << 20
+
+// download fetches an image the API returned by URL.
+func (p *DALLEProvider) download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download generated image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download generated image: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("download generated image: %w", err)
+	}
+	if len(data) > maxImageBytes {
+		return nil, errors.New("generated image exceeds the size limit")
+	}
+	return data, nil
+}
+
 // classifyOpenAIError determines whether an OpenAI API error is retryable.
 func classifyOpenAIError(err error) (shouldRetry bool, waitTime time.Duration) {
 	var apiErr *openai.APIError
@@ -84,7 +84,7 @@ func voiceIntentProperties() map[string]interface{} {
 		"type": map[string]interface{}{
 			"type":        "string",
 			"description": "The classified intent type",
-			"enum":        []string{"scroll_up", "scroll_down", "navigate", "question", "ignore"},
+			"enum":        []string{"scroll_up", "scroll_down", "navigate", "question", "read_step", "ignore"},
 		},
 		"amount": map[string]interface{}{
 			"type":        "string",
@@ -99,6 +99,10 @@ func voiceIntentProperties() map[string]interface{} {
 			"type":        "string",
 			"description": "The question text for question intents",
 		},
+		"step": map[string]interface{}{
+			"type":        "integer",
+			"description": "For read_step: the 1-based step number to read aloud, or 0 for the current step",
+		},
 	}
 }
 
@@ -146,6 +150,7 @@ func dietaryProfileProperties() map[string]interface{} {
 // Mirrors AnthropicProvider.GenerateRecipe: it sends only the rendered system
 // and user prompts (it does not use req.Messages, matching the Anthropic side).
 func (p *OpenAICompatProvider) GenerateRecipe(ctx context.Context, req RecipeRequest) (*RecipeResult, error) {
+	prompts := p.prompts.For("GenerateRecipe")
 	op := AIOperation{
 		Name:      "GenerateRecipe",
 		Provider:  p.providerName,
@@ -154,7 +159,7 @@ func (p *OpenAICompatProvider) GenerateRecipe(ctx context.Context, req RecipeReq
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (*RecipeResult, error) {
-		sysSuffix, err := config.RenderPrompt(p.prompts.Recipe.Generate.System, map[string]interface{}{
+		sysSuffix, err := config.RenderPrompt(prompts.Recipe.Generate.System, map[string]interface{}{
 			"UnitSystem":     req.UnitSystem,
 			"Requirements":   req.Requirements,
 			"CookingContext": req.CookingContext,
@@ -163,20 +168,20 @@ func (p *OpenAICompatProvider) GenerateRecipe(ctx context.Context, req RecipeReq
 			return nil, fmt.Errorf("render system prompt: %w", err)
 		}
 
-		userPrompt, err := config.RenderPrompt(p.prompts.Recipe.Generate.User, map[string]interface{}{
+		userPrompt, err := config.RenderPrompt(prompts.Recipe.Generate.User, map[string]interface{}{
 			"Prompt": req.UserPrompt,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("render user prompt: %w", err)
 		}
 
-		summaryDesc := p.prompts.Recipe.Summarize.Recipe
+		summaryDesc := prompts.Recipe.Summarize.Recipe
 
 		chatReq := openai.ChatCompletionRequest{
 			Model:     p.model,
 			MaxTokens: 4096,
 			Messages: []openai.ChatCompletionMessage{
-				{Role: openai.ChatMessageRoleSystem, Content: combineSystemPrompt(p.prompts.Recipe.Generate.SystemPrefix, sysSuffix)},
+				{Role: openai.ChatMessageRoleSystem, Content: combineSystemPrompt(prompts.Recipe.Generate.SystemPrefix, sysSuffix)},
 				{Role: openai.ChatMessageRoleUser, Content: userPrompt},
 			},
 			Tools: []openai.Tool{{
@@ -193,13 +198,14 @@ func (p *OpenAICompatProvider) GenerateRecipe(ctx context.Context, req RecipeReq
 			},
 		}
 
-		return p.completeRecipe(ctx, chatReq)
+		return p.completeRecipe(ctx, prompts, chatReq)
 	})
 }
 
 // RegenerateRecipe revises an existing recipe based on conversation history.
 // Mirrors AnthropicProvider.RegenerateRecipe.
 func (p *OpenAICompatProvider) RegenerateRecipe(ctx context.Context, req RegenerateRequest) (*RecipeResult, error) {
+	prompts := p.prompts.For("RegenerateRecipe")
 	op := AIOperation{
 		Name:      "RegenerateRecipe",
 		Provider:  p.providerName,
@@ -208,7 +214,7 @@ func (p *OpenAICompatProvider) RegenerateRecipe(ctx context.Context, req Regener
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (*RecipeResult, error) {
-		sysSuffix, err := config.RenderPrompt(p.prompts.Recipe.Regenerate.System, map[string]interface{}{
+		sysSuffix, err := config.RenderPrompt(prompts.Recipe.Regenerate.System, map[string]interface{}{
 			"UnitSystem":     req.UnitSystem,
 			"Requirements":   req.Requirements,
 			"CookingContext": req.CookingContext,
@@ -217,9 +223,9 @@ func (p *OpenAICompatProvider) RegenerateRecipe(ctx context.Context, req Regener
 			return nil, fmt.Errorf("render system prompt: %w", err)
 		}
 
-		summaryDesc := p.prompts.Recipe.Summarize.Changes
+		summaryDesc := prompts.Recipe.Summarize.Changes
 
-		userPrompt, err := config.RenderPrompt(p.prompts.Recipe.Regenerate.User, map[string]interface{}{
+		userPrompt, err := config.RenderPrompt(prompts.Recipe.Regenerate.User, map[string]interface{}{
 			"Prompt": req.UserPrompt,
 		})
 		if err != nil {
@@ -228,7 +234,7 @@ func (p *OpenAICompatProvider) RegenerateRecipe(ctx context.Context, req Regener
 
 		// Build message list: system + existing history + new user prompt.
 		messages := []openai.ChatCompletionMessage{
-			{Role: openai.ChatMessageRoleSystem, Content: combineSystemPrompt(p.prompts.Recipe.Regenerate.SystemPrefix, sysSuffix)},
+			{Role: openai.ChatMessageRoleSystem, Content: combineSystemPrompt(prompts.Recipe.Regenerate.SystemPrefix, sysSuffix)},
 		}
 		messages = append(messages, messagesToOpenAIParams(req.ExistingHistory)...)
 		messages = append(messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: userPrompt})
@@ -251,13 +257,14 @@ func (p *OpenAICompatProvider) RegenerateRecipe(ctx context.Context, req Regener
 			},
 		}
 
-		return p.completeRecipe(ctx, chatReq)
+		return p.completeRecipe(ctx, prompts, chatReq)
 	})
 }
 
 // ForkRecipe creates a new recipe branched from an existing one.
 // Mirrors AnthropicProvider.ForkRecipe.
 func (p *OpenAICompatProvider) ForkRecipe(ctx context.Context, req ForkRequest) (*RecipeResult, error) {
+	prompts := p.prompts.For("ForkRecipe")
 	op := AIOperation{
 		Name:      "ForkRecipe",
 		Provider:  p.providerName,
@@ -266,7 +273,7 @@ func (p *OpenAICompatProvider) ForkRecipe(ctx context.Context, req ForkRequest)
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (*RecipeResult, error) {
-		sysSuffix, err := config.RenderPrompt(p.prompts.Recipe.Fork.System, map[string]interface{}{
+		sysSuffix, err := config.RenderPrompt(prompts.Recipe.Fork.System, map[string]interface{}{
 			"UnitSystem":     req.UnitSystem,
 			"Requirements":   req.Requirements,
 			"CookingContext": req.CookingContext,
@@ -275,9 +282,9 @@ func (p *OpenAICompatProvider) ForkRecipe(ctx context.Context, req ForkRequest)
 			return nil, fmt.Errorf("render system prompt: %w", err)
 		}
 
-		summaryDesc := p.prompts.Recipe.Summarize.Recipe
+		summaryDesc := prompts.Recipe.Summarize.Recipe
 
-		userPrompt, err := config.RenderPrompt(p.prompts.Recipe.Fork.User, map[string]interface{}{
+		userPrompt, err := config.RenderPrompt(prompts.Recipe.Fork.User, map[string]interface{}{
 			"Prompt": req.UserPrompt,
 		})
 		if err != nil {
@@ -285,7 +292,7 @@ func (p *OpenAICompatProvider) ForkRecipe(ctx context.Context, req ForkRequest)
 		}
 
 		messages := []openai.ChatCompletionMessage{
-			{Role: openai.ChatMessageRoleSystem, Content: combineSystemPrompt(p.prompts.Recipe.Fork.SystemPrefix, sysSuffix)},
+			{Role: openai.ChatMessageRoleSystem, Content: combineSystemPrompt(prompts.Recipe.Fork.SystemPrefix, sysSuffix)},
 		}
 		messages = append(messages, messagesToOpenAIParams(req.ExistingHistory)...)
 		messages = append(messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: userPrompt})
@@ -308,19 +315,29 @@ func (p *OpenAICompatProvider) ForkRecipe(ctx context.Context, req ForkRequest)
 			},
 		}
 
-		return p.completeRecipe(ctx, chatReq)
+		return p.completeRecipe(ctx, prompts, chatReq)
 	})
 }
 
 // completeRecipe issues a forced create_recipe chat completion and parses,
 // validates and stamps the resulting recipe. Shared by GenerateRecipe,
 // RegenerateRecipe and ForkRecipe (their only difference is prompt/history).
-func (p *OpenAICompatProvider) completeRecipe(ctx context.Context, chatReq openai.ChatCompletionRequest) (*RecipeResult, error) {
+func (p *OpenAICompatProvider) completeRecipe(ctx context.Context, prompts *config.Prompts, chatReq openai.ChatCompletionRequest) (*RecipeResult, error) {
 	resp, err := p.createChatCompletion(ctx, chatReq)
 	if err != nil {
 		return nil, err
 	}
 
+	result, err := p.recipeFromToolCall(ctx, chatReq, resp)
+	if err != nil {
+		return nil, err
+	}
+	result.PromptVersion = config.PromptVersion(prompts)
+	return result, nil
+}
+
+// parseRecipeToolCall decodes the create_recipe call in a chat completion.
+func parseRecipeToolCall(resp *openai.ChatCompletionResponse) (*RecipeResult, error) {
 	args, err := firstToolCallArguments(resp, "create_recipe")
 	if err != nil {
 		return nil, err
@@ -330,18 +347,40 @@ func (p *OpenAICompatProvider) completeRecipe(ctx context.Context, chatReq opena
 	if err := json.Unmarshal([]byte(args), &tr); err != nil {
 		return nil, NewAIError(FailureContentParse, fmt.Errorf("failed to unmarshal recipe: %w", err), "failed to parse recipe tool result")
 	}
+	return toolResultToRecipeResult(&tr), nil
+}
 
-	result := toolResultToRecipeResult(&tr)
-	if err := validateRecipeResult(result); err != nil {
+// recipeFromToolCall parses a create_recipe completion and runs it through
+// finishRecipe. The repair turn continues the same conversation: the model's
+// tool call, then a tool message naming every invalid field.
+func (p *OpenAICompatProvider) recipeFromToolCall(ctx context.Context, chatReq openai.ChatCompletionRequest, resp *openai.ChatCompletionResponse) (*RecipeResult, error) {
+	result, err := parseRecipeToolCall(resp)
+	if err != nil {
 		return nil, err
 	}
-	result.PromptVersion = config.PromptVersion(p.prompts)
-	return result, nil
+	return finishRecipe(ctx, result, func(ctx context.Context, feedback string) (*RecipeResult, error) {
+		call := resp.Choices[0].Message
+		repair := chatReq
+		repair.Messages = append(append([]openai.ChatCompletionMessage{}, chatReq.Messages...),
+			call,
+			openai.ChatCompletionMessage{
+				Role:       openai.ChatMessageRoleTool,
+				ToolCallID: call.ToolCalls[0].ID,
+				Content:    feedback,
+			},
+		)
+		fixed, err := p.createChatCompletion(ctx, repair)
+		if err != nil {
+			return nil, err
+		}
+		return parseRecipeToolCall(fixed)
+	})
 }
 
 // AnalyzeAllergens analyses ingredients for allergen risks via a forced
 // analyze_allergens function call. Mirrors AnthropicProvider.AnalyzeAllergens.
 func (p *OpenAICompatProvider) AnalyzeAllergens(ctx context.Context, req AllergenRequest) (*AllergenResult, error) {
+	prompts := p.prompts.For("AnalyzeAllergens")
 	op := AIOperation{
 		Name:      "AnalyzeAllergens",
 		Provider:  p.providerName,
@@ -350,13 +389,13 @@ func (p *OpenAICompatProvider) AnalyzeAllergens(ctx context.Context, req Allerge
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (*AllergenResult, error) {
-		sysPrompt, err := config.RenderPrompt(p.prompts.Allergen.Analyze.System, nil)
+		sysPrompt, err := config.RenderPrompt(prompts.Allergen.Analyze.System, nil)
 		if err != nil {
 			return nil, fmt.Errorf("render system prompt: %w", err)
 		}
 
 		ingredientList, _ := json.Marshal(req.Ingredients)
-		userPrompt, err := config.RenderPrompt(p.prompts.Allergen.Analyze.User, map[string]interface{}{
+		userPrompt, err := config.RenderPrompt(prompts.Allergen.Analyze.User, map[string]interface{}{
 			"Ingredients": string(ingredientList),
 			"IsPremium":   req.IsPremium,
 		})
@@ -407,6 +446,7 @@ func (p *OpenAICompatProvider) AnalyzeAllergens(ctx context.Context, req Allerge
 // forced classify_voice_intent function call. Mirrors
 // AnthropicProvider.ClassifyVoiceIntent.
 func (p *OpenAICompatProvider) ClassifyVoiceIntent(ctx context.Context, transcript string) (*VoiceIntent, error) {
+	prompts := p.prompts.For("ClassifyVoiceIntent")
 	op := AIOperation{
 		Name:      "ClassifyVoiceIntent",
 		Provider:  p.providerName,
@@ -415,7 +455,7 @@ func (p *OpenAICompatProvider) ClassifyVoiceIntent(ctx context.Context, transcri
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (*VoiceIntent, error) {
-		sysPrompt, err := config.RenderPrompt(p.prompts.Voice.Intent.System, nil)
+		sysPrompt, err := config.RenderPrompt(prompts.Voice.Intent.System, nil)
 		if err != nil {
 			return nil, fmt.Errorf("render system prompt: %w", err)
 		}
@@ -558,6 +598,7 @@ func (p *OpenAICompatProvider) ExpandAndRankRecipes(ctx context.Context, req Fin
 // information, then calls the tool (Complete=true, Profile set, Response=wrap-up).
 // Mirrors AnthropicProvider.DietaryInterview and extractDietaryInterviewFromMessage.
 func (p *OpenAICompatProvider) DietaryInterview(ctx context.Context, messages []Message, memberName string) (*DietaryInterviewResult, error) {
+	prompts := p.prompts.For("DietaryInterview")
 	op := AIOperation{
 		Name:      "DietaryInterview",
 		Provider:  p.providerName,
@@ -566,7 +607,7 @@ func (p *OpenAICompatProvider) DietaryInterview(ctx context.Context, messages []
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (*DietaryInterviewResult, error) {
-		sysSuffix, err := config.RenderPrompt(p.prompts.DietaryInterview.System, map[string]interface{}{
+		sysSuffix, err := config.RenderPrompt(prompts.DietaryInterview.System, map[string]interface{}{
 			"MemberName": memberName,
 		})
 		if err != nil {
@@ -574,7 +615,7 @@ func (p *OpenAICompatProvider) DietaryInterview(ctx context.Context, messages []
 		}
 
 		chatMsgs := []openai.ChatCompletionMessage{
-			{Role: openai.ChatMessageRoleSystem, Content: combineSystemPrompt(p.prompts.DietaryInterview.SystemPrefix, sysSuffix)},
+			{Role: openai.ChatMessageRoleSystem, Content: combineSystemPrompt(prompts.DietaryInterview.SystemPrefix, sysSuffix)},
 		}
 		chatMsgs = append(chatMsgs, messagesToOpenAIParams(messages)...)
 
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	openai "github.com/sashabaranov/go-openai"
@@ -23,7 +25,7 @@ import (
 //
 // It deliberately reuses the schema (recipeProperties, portionProperties),
 // parsing (recipeToolResult, portionToolResult and their converters) and
-// validation (validateRecipeResult) helpers defined alongside the Anthropic
+// validation (finishRecipe) helpers defined alongside the Anthropic
 // provider so the two tiers stay byte-for-byte faithful.
 type OpenAICompatProvider struct {
 	client       *openai.Client
@@ -96,7 +98,7 @@ func (p *OpenAICompatProvider) createChatCompletion(ctx context.Context, req ope
 		lastErr = err
 		shouldRetry, waitTime := classifyOpenAIError(err)
 		if !shouldRetry {
-			return nil, fmt.Errorf("%s chat completion error: %w", p.providerName, err)
+			return nil, fmt.Errorf("%s chat completion error: %w", p.providerName, classifyOpenAICompatError(err))
 		}
 
 		logger.Get().Warn("OpenAI-compat chat completion error, retrying",
@@ -114,7 +116,33 @@ func (p *OpenAICompatProvider) createChatCompletion(ctx context.Context, req ope
 		}
 	}
 
-	return nil, fmt.Errorf("%s chat completion: exhausted %d retries: %w", p.providerName, maxRetries, lastErr)
+	return nil, fmt.Errorf("%s chat completion: exhausted %d retries: %w", p.providerName, maxRetries, classifyOpenAICompatError(lastErr))
+}
+
+// classifyOpenAICompatError classifies a chat-completion error into the AI
+// failure taxonomy so a FallbackTextProvider can tell an outage from a bad
+// request. OpenAI reports an exhausted quota as a 429 with code
+// insufficient_quota, which no amount of waiting clears.
+func classifyOpenAICompatError(err error) *AIError {
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		if apiErr.Code == "insufficient_quota" || apiErr.Type == "insufficient_quota" {
+			return NewAIError(FailureQuotaExhausted, err, "quota exhausted")
+		}
+		if aiErr := classifyHTTPStatus(apiErr.HTTPStatusCode, err); aiErr != nil {
+			return aiErr
+		}
+	case errors.As(err, &reqErr):
+		if aiErr := classifyHTTPStatus(reqErr.HTTPStatusCode, err); aiErr != nil {
+			return aiErr
+		}
+	}
+	if aiErr := classifyTransportError(err); aiErr != nil {
+		return aiErr
+	}
+	return NewAIError(FailureUnknown, err, "unknown API error")
 }
 
 // firstToolCallArguments returns the JSON argument string of the first tool
@@ -150,6 +178,7 @@ func schemaObject(properties map[string]interface{}) map[string]interface{} {
 // ExtractRecipeFromText extracts a structured recipe from free-form text via a
 // forced create_recipe function call. Mirrors AnthropicProvider.ExtractRecipeFromText.
 func (p *OpenAICompatProvider) ExtractRecipeFromText(ctx context.Context, text string, unitSystem string) (*RecipeResult, error) {
+	prompts := p.prompts.For("ExtractRecipeFromText")
 	op := AIOperation{
 		Name:      "ExtractRecipeFromText",
 		Provider:  p.providerName,
@@ -163,16 +192,17 @@ func (p *OpenAICompatProvider) ExtractRecipeFromText(ctx context.Context, text s
 		var templateData map[string]interface{}
 
 		if unitSystem == UnitSystemPreserveSource {
-			sysPrefix = p.prompts.Import.URL.SystemPrefix
-			promptTemplate = p.prompts.Import.URL.System
+			sysPrefix = prompts.Import.URL.SystemPrefix
+			promptTemplate = prompts.Import.URL.System
 			templateData = map[string]interface{}{
 				"UnitSystem": "the original units from the source text. Do not convert measurements. Report which unit system is used via the unit_system field",
 			}
 		} else {
-			sysPrefix = p.prompts.Import.Text.SystemPrefix
-			promptTemplate = p.prompts.Import.Text.System
+			sysPrefix = prompts.Import.Text.SystemPrefix
+			promptTemplate = prompts.Import.Text.System
 			templateData = map[string]interface{}{
 				"UnitSystem": unitSystem,
+				"Locale":     LocaleFromContext(ctx).Market(),
 			}
 		}
 
@@ -181,7 +211,7 @@ func (p *OpenAICompatProvider) ExtractRecipeFromText(ctx context.Context, text s
 			return nil, fmt.Errorf("render system prompt: %w", err)
 		}
 
-		summaryDesc := p.prompts.Recipe.Summarize.Recipe
+		summaryDesc := prompts.Recipe.Summarize.Recipe
 
 		req := openai.ChatCompletionRequest{
 			Model:     p.model,
@@ -209,21 +239,11 @@ func (p *OpenAICompatProvider) ExtractRecipeFromText(ctx context.Context, text s
 			return nil, err
 		}
 
-		args, err := firstToolCallArguments(resp, "create_recipe")
+		result, err := p.recipeFromToolCall(ctx, req, resp)
 		if err != nil {
 			return nil, err
 		}
-
-		var tr recipeToolResult
-		if err := json.Unmarshal([]byte(args), &tr); err != nil {
-			return nil, NewAIError(FailureContentParse, fmt.Errorf("failed to unmarshal recipe: %w", err), "failed to parse recipe tool result")
-		}
-
-		result := toolResultToRecipeResult(&tr)
-		if err := validateRecipeResult(result); err != nil {
-			return nil, err
-		}
-		result.PromptVersion = config.PromptVersion(p.prompts)
+		result.PromptVersion = config.PromptVersion(prompts)
 		return result, nil
 	})
 }
@@ -286,6 +306,7 @@ func (p *OpenAICompatProvider) EstimatePortions(ctx context.Context, recipeDef i
 // CookingQA answers a cooking question with optional recipe context via a plain
 // completion. Mirrors AnthropicProvider.CookingQA.
 func (p *OpenAICompatProvider) CookingQA(ctx context.Context, question string, recipeContext string) (string, error) {
+	prompts := p.prompts.For("CookingQA")
 	op := AIOperation{
 		Name:      "CookingQA",
 		Provider:  p.providerName,
@@ -294,7 +315,7 @@ func (p *OpenAICompatProvider) CookingQA(ctx context.Context, question string, r
 	}
 
 	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (string, error) {
-		sysSuffix, err := config.RenderPrompt(p.prompts.CookingQA.System, map[string]interface{}{
+		sysSuffix, err := config.RenderPrompt(prompts.CookingQA.System, map[string]interface{}{
 			"RecipeContext": recipeContext,
 		})
 		if err != nil {
@@ -305,7 +326,7 @@ func (p *OpenAICompatProvider) CookingQA(ctx context.Context, question string, r
 			Model:     p.model,
 			MaxTokens: 1024,
 			Messages: []openai.ChatCompletionMessage{
-				{Role: openai.ChatMessageRoleSystem, Content: combineSystemPrompt(p.prompts.CookingQA.SystemPrefix, sysSuffix)},
+				{Role: openai.ChatMessageRoleSystem, Content: combineSystemPrompt(prompts.CookingQA.SystemPrefix, sysSuffix)},
 				{Role: openai.ChatMessageRoleUser, Content: question},
 			},
 		}
@@ -326,6 +347,72 @@ func (p *OpenAICompatProvider) CookingQA(ctx context.Context, question string, r
 	})
 }
 
+// StreamCookingChat answers a cooking question in the context of the
+// conversation so far, streaming the answer. Mirrors
+// AnthropicProvider.StreamCookingChat.
+func (p *OpenAICompatProvider) StreamCookingChat(ctx context.Context, req CookingChatRequest, onDelta func(string)) (string, error) {
+	prompts := p.prompts.For("CookingQA")
+	op := AIOperation{
+		Name:      "CookingQA",
+		Provider:  p.providerName,
+		Model:     p.model,
+		StartTime: time.Now(),
+	}
+
+	return runWithMiddleware(ctx, p.middleware, op, func(ctx context.Context) (string, error) {
+		sysSuffix, err := config.RenderPrompt(prompts.CookingQA.System, map[string]interface{}{
+			"RecipeContext": req.RecipeContext,
+		})
+		if err != nil {
+			return "", fmt.Errorf("render system prompt: %w", err)
+		}
+
+		messages := []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: combineSystemPrompt(prompts.CookingQA.SystemPrefix, sysSuffix)},
+		}
+		messages = append(messages, messagesToOpenAIParams(req.History)...)
+		messages = append(messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: req.Question})
+
+		stream, err := p.client.CreateChatCompletionStream(ctx, openai.ChatCompletionRequest{
+			Model:         p.model,
+			MaxTokens:     1024,
+			Messages:      messages,
+			Stream:        true,
+			StreamOptions: &openai.StreamOptions{IncludeUsage: true},
+		})
+		if err != nil {
+			return "", fmt.Errorf("%s chat completion stream error: %w", p.providerName, classifyOpenAICompatError(err))
+		}
+		defer stream.Close()
+
+		var answer strings.Builder
+		for {
+			chunk, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return "", fmt.Errorf("%s chat completion stream error: %w", p.providerName, classifyOpenAICompatError(err))
+			}
+			if chunk.Usage != nil {
+				recordUsage(ctx, TokenUsage{
+					InputTokens:  chunk.Usage.PromptTokens,
+					OutputTokens: chunk.Usage.CompletionTokens,
+				})
+			}
+			if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+				answer.WriteString(chunk.Choices[0].Delta.Content)
+				onDelta(chunk.Choices[0].Delta.Content)
+			}
+		}
+
+		if answer.Len() == 0 {
+			return "", NewAIError(FailureContentEmpty, errors.New("no text content in chat completion stream"), "no text content in response")
+		}
+		return answer.String(), nil
+	})
+}
+
 // The remaining TextProvider methods (GenerateRecipe, RegenerateRecipe,
 // ForkRecipe, AnalyzeAllergens, ClassifyVoiceIntent, DietaryInterview) are
 // implemented in openai_maintier.go, so this provider can serve the full main
@@ -3,6 +3,7 @@ package ai
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -135,6 +136,78 @@ func TestOpenAICompatProvider_CookingQA(t *testing.T) {
 	}
 }
 
+func TestOpenAICompatProvider_StreamCookingChat(t *testing.T) {
+	var sent openai.ChatCompletionRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"choices":[{"index":0,"delta":{"content":"About "}}]}`,
+			`{"choices":[{"index":0,"delta":{"content":"two minutes."}}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":40,"completion_tokens":5}}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	p := NewOpenAICompatProvider("test-key", srv.URL, "gpt-4o-mini", "openai", testPrompts())
+
+	var deltas []string
+	got, err := p.StreamCookingChat(context.Background(), CookingChatRequest{
+		Question:      "and the other side?",
+		RecipeContext: "Seared steak",
+		History: []Message{
+			{Role: "user", Content: "how long to sear?"},
+			{Role: "assistant", Content: "Three minutes."},
+		},
+	}, func(d string) { deltas = append(deltas, d) })
+	if err != nil {
+		t.Fatalf("StreamCookingChat returned error: %v", err)
+	}
+	if got != "About two minutes." || len(deltas) != 2 {
+		t.Errorf("StreamCookingChat = %q with deltas %q", got, deltas)
+	}
+
+	if len(sent.Messages) != 4 || !strings.Contains(sent.Messages[0].Content, "Seared steak") ||
+		sent.Messages[2].Content != "Three minutes." || sent.Messages[3].Content != "and the other side?" {
+		t.Errorf("messages = %+v, want system, history, then the question", sent.Messages)
+	}
+}
+
+func TestStreamCookingChat_FallsBackToCookingQA(t *testing.T) {
+	var gotContext string
+	p := &stubCookingQAProvider{answer: "Yes.", recipeContext: &gotContext}
+
+	var deltas []string
+	got, err := StreamCookingChat(context.Background(), p, CookingChatRequest{
+		Question:      "still fine?",
+		RecipeContext: "Pancakes",
+		History:       []Message{{Role: "user", Content: "oat milk ok?"}, {Role: "assistant", Content: "Sure."}},
+	}, func(d string) { deltas = append(deltas, d) })
+	if err != nil || got != "Yes." || len(deltas) != 1 || deltas[0] != "Yes." {
+		t.Fatalf("StreamCookingChat = %q, %v (deltas %q)", got, err, deltas)
+	}
+	if want := "Pancakes\n\nConversation so far:\nUser: oat milk ok?\nAssistant: Sure."; gotContext != want {
+		t.Errorf("recipe context = %q, want %q", gotContext, want)
+	}
+}
+
+// stubCookingQAProvider answers CookingQA without streaming.
+type stubCookingQAProvider struct {
+	TextProvider
+	answer        string
+	recipeContext *string
+}
+
+func (s *stubCookingQAProvider) CookingQA(ctx context.Context, question, recipeContext string) (string, error) {
+	*s.recipeContext = recipeContext
+	return s.answer, nil
+}
+
 func TestOpenAICompatProvider_EstimatePortions(t *testing.T) {
 	canned := openai.ChatCompletionResponse{
 		Choices: []openai.ChatCompletionChoice{{
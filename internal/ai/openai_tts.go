@@ -0,0 +1,94 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"go.uber.org/zap"
+)
+
+// DefaultSpeechVoice is the voice used when neither the caller nor TTS_VOICE
+// names one.
+const DefaultSpeechVoice = "alloy"
+
+// maxSpeechInput is the OpenAI speech endpoint's input limit, in characters.
+const maxSpeechInput = 4096
+
+// OpenAITTSProvider implements SpeechSynthesisProvider with OpenAI's speech
+// endpoint, returning MP3.
+type OpenAITTSProvider struct {
+	apiKey string
+	model  string
+}
+
+// NewOpenAITTSProvider creates a text-to-speech provider. model defaults to
+// tts-1, the low-latency model; read-aloud favours speed over fidelity.
+func NewOpenAITTSProvider(apiKey, model string) *OpenAITTSProvider {
+	if model == "" {
+		model = string(openai.TTSModel1)
+	}
+	return &OpenAITTSProvider{apiKey: apiKey, model: model}
+}
+
+// SynthesizeSpeech renders text as MP3 speech in voice.
+func (p *OpenAITTSProvider) SynthesizeSpeech(ctx context.Context, text, voice string) (*SpeechAudio, error) {
+	if text == "" {
+		return nil, errors.New("speech text is empty")
+	}
+	if len(text) > maxSpeechInput {
+		return nil, fmt.Errorf("speech text is %d characters, over the %d limit", len(text), maxSpeechInput)
+	}
+	if voice == "" {
+		voice = DefaultSpeechVoice
+	}
+
+	client := openai.NewClient(p.apiKey)
+	const maxRetries = 3
+	var lastErr error
+
+	for i := 0; i < maxRetries; i++ {
+		resp, err := client.CreateSpeech(ctx, openai.CreateSpeechRequest{
+			Model:          openai.SpeechModel(p.model),
+			Input:          text,
+			Voice:          openai.SpeechVoice(voice),
+			ResponseFormat: openai.SpeechResponseFormatMp3,
+		})
+		if err == nil {
+			data, readErr := io.ReadAll(io.LimitReader(resp, 16<<20))
+			resp.Close()
+			if readErr != nil {
+				return nil, fmt.Errorf("read speech response: %w", readErr)
+			}
+			if len(data) == 0 {
+				return nil, errors.New("OpenAI TTS returned empty audio")
+			}
+			return &SpeechAudio{Data: data, Format: "mp3"}, nil
+		}
+
+		lastErr = err
+		shouldRetry, waitTime := classifyOpenAIError(err)
+		if !shouldRetry {
+			return nil, fmt.Errorf("OpenAI TTS API error: %w", err)
+		}
+
+		logger.Get().Warn("OpenAI TTS API error, retrying",
+			zap.Error(err),
+			zap.Int("attempt", i+1),
+		)
+
+		if i < maxRetries-1 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(waitTime * time.Duration(i+1)):
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("OpenAI TTS API: exhausted %d retries: %w", maxRetries, lastErr)
+}
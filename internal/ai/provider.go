@@ -62,7 +62,8 @@ type VideoProvider interface {
 	ExtractRecipesFromVideo(ctx context.Context, videoData []byte, mimeType, contextText, unitSystem, requirements string) ([]*RecipeResult, error)
 }
 
-// ImageProvider handles image generation (DALL-E 3).
+// ImageProvider handles image generation (DALL-E, Imagen, or an
+// OpenAI-compatible endpoint; see BuildImageProvider).
 type ImageProvider interface {
 	GenerateImage(ctx context.Context, prompt string) ([]byte, error)
 }
@@ -73,12 +74,39 @@ type SpeechProvider interface {
 	TranscribeAudio(ctx context.Context, audioData []byte, format string) (string, error)
 }
 
+// SpeechSynthesisProvider handles text-to-speech for cooking mode's
+// read-aloud. voice names a provider voice (e.g. "alloy"); empty uses the
+// provider's default.
+type SpeechSynthesisProvider interface {
+	SynthesizeSpeech(ctx context.Context, text, voice string) (*SpeechAudio, error)
+}
+
+// SpeechAudio is synthesized speech: the encoded audio and its container
+// format ("mp3", "wav").
+type SpeechAudio struct {
+	Data   []byte
+	Format string
+}
+
+// ContentType is the MIME type of the audio's format.
+func (a *SpeechAudio) ContentType() string {
+	switch a.Format {
+	case "mp3":
+		return "audio/mpeg"
+	case "wav":
+		return "audio/wav"
+	default:
+		return "application/octet-stream"
+	}
+}
+
 // EmbeddingProvider handles vector embeddings.
 type EmbeddingProvider interface {
 	GenerateEmbedding(ctx context.Context, text string) ([]float32, error)
 }
 
-// SearchProvider handles web recipe search (Google + Brave fallback).
+// SearchProvider handles web recipe search. Satisfied by each search backend
+// and by the service layer's weighted backend registry.
 type SearchProvider interface {
 	SearchRecipes(ctx context.Context, query string, count int, offset int) ([]SearchResult, error)
 }
@@ -164,10 +192,11 @@ type IngredientAnalysisResult struct {
 
 // VoiceIntent is the classified intent from a voice command.
 type VoiceIntent struct {
-	Type   string // "scroll_up", "scroll_down", "navigate", "question", "ignore"
+	Type   string // "scroll_up", "scroll_down", "navigate", "question", "read_step", "ignore"
 	Amount string // "small", "large"
 	Target string // "ingredients", "instructions"
 	Text   string // for questions
+	Step   int    // for read_step: 1-based step number, 0 for the current step
 }
 
 // PortionEstimate is the estimated portion information for a recipe.
@@ -206,6 +235,12 @@ type DietaryAllergyResult struct {
 	Notes    string
 }
 
+// Search result origins.
+const (
+	SearchOriginWeb   = "web"
+	SearchOriginLocal = "local"
+)
+
 // SearchResult is a single web search result.
 type SearchResult struct {
 	Title       string  `json:"title"`
@@ -214,6 +249,9 @@ type SearchResult struct {
 	Rating      float64 `json:"rating"`
 	ImageURL    string  `json:"image_url"`
 	Description string  `json:"description"`
+	// Origin marks where the result came from: SearchOriginWeb or
+	// SearchOriginLocal (our own canonical recipe index).
+	Origin string `json:"origin,omitempty"`
 }
 
 // Message represents a single message in a conversation.
@@ -227,24 +265,27 @@ type Message struct {
 // refer back to candidates by Index — it is never given a way to emit a recipe
 // that is not in this list.
 type FinderCandidate struct {
-	Index       int
-	Title       string
-	URL         string
-	Source      string
-	Description string
+	Index       int    `json:"index"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Source      string `json:"source"`
+	Description string `json:"description"`
 }
 
 // FinderRankRequest is the input to ExpandAndRankRecipes. All of the steering
 // fields are composed server-side (never client-trusted); Candidates are the
 // real search results to rank.
 type FinderRankRequest struct {
-	Facets         string // deterministic summary of the tapped facet chips
-	FreeText       string // optional typed/spoken free text
-	UnitSystem     string
-	CookingContext string
-	Requirements   string
-	DietSummary    string // compacted family dietary needs (allergies, restrictions, preferences)
-	Candidates     []FinderCandidate
+	Facets         string `json:"facets"`    // deterministic summary of the tapped facet chips
+	FreeText       string `json:"free_text"` // optional typed/spoken free text
+	UnitSystem     string `json:"unit_system"`
+	CookingContext string `json:"cooking_context"`
+	Requirements   string `json:"requirements"`
+	DietSummary    string `json:"diet_summary"` // compacted family dietary needs (allergies, restrictions, preferences)
+	// Conversation is a compacted summary of the earlier turns of a multi-turn
+	// find (what was asked, shown and dismissed); empty for a one-shot find.
+	Conversation string            `json:"conversation,omitempty"`
+	Candidates   []FinderCandidate `json:"candidates"`
 }
 
 // MemberSafety is the model's best-effort dietary assessment of a candidate for
@@ -261,21 +302,21 @@ type MemberSafety struct {
 // FinderRanking is one ranked candidate: Index into the FinderRankRequest's
 // candidate list, a one-line rationale and per-member safety badges.
 type FinderRanking struct {
-	Index  int
-	Reason string
-	Safety []MemberSafety
+	Index  int            `json:"index"`
+	Reason string         `json:"reason"`
+	Safety []MemberSafety `json:"safety,omitempty"`
 	// Expand marks a candidate that is a collection/listicle/roundup page (many
 	// recipes) rather than a single recipe, worth digging into for its
 	// individual recipes. ExpandPriority (higher = more promising) orders which
 	// collections to dig first when several are flagged.
-	Expand         bool
-	ExpandPriority int
+	Expand         bool `json:"expand"`
+	ExpandPriority int  `json:"expand_priority"`
 }
 
 // FinderRankResult is the output of ExpandAndRankRecipes: candidates in
 // match-ranked order (by Index into the request's candidate list) plus a few
 // broadened query suggestions the user can fall back to.
 type FinderRankResult struct {
-	Ranked         []FinderRanking
-	BroadenQueries []string
+	Ranked         []FinderRanking `json:"ranked"`
+	BroadenQueries []string        `json:"broaden_queries"`
 }
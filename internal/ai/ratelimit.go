@@ -0,0 +1,455 @@
+package ai
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"go.uber.org/zap"
+)
+
+// Priority ranks AI calls competing for a saturated rate limit: a queued call
+// is admitted before any lower-priority one, and lower priorities are shed
+// first.
+type Priority int
+
+const (
+	// PriorityInteractive is a call a user is waiting on; the default.
+	PriorityInteractive Priority = iota
+	// PriorityWarming is speculative cache warming.
+	PriorityWarming
+	// PriorityBackfill is bulk background work.
+	PriorityBackfill
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityWarming:
+		return "warming"
+	case PriorityBackfill:
+		return "backfill"
+	default:
+		return "unknown"
+	}
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose AI calls queue at priority p.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the priority attached by WithPriority, or
+// PriorityInteractive when there is none.
+func PriorityFromContext(ctx context.Context) Priority {
+	p, _ := ctx.Value(priorityKey{}).(Priority)
+	return p
+}
+
+// RateLimit sizes the buckets of one provider or provider/model. A zero field
+// leaves that dimension unlimited.
+type RateLimit struct {
+	RPM int // requests per minute
+	TPM int // input + output tokens per minute
+}
+
+func (l RateLimit) unlimited() bool { return l.RPM <= 0 && l.TPM <= 0 }
+
+// ParseRateLimits parses the AI_RATE_LIMITS format: comma-separated
+// "provider=RPM:TPM" or "provider/model=RPM:TPM" entries, either number
+// possibly 0 (unlimited), e.g. "anthropic=50:40000,gemini/gemini-2.0-flash=2000:0".
+func ParseRateLimits(s string) (map[string]RateLimit, error) {
+	out := map[string]RateLimit{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("rate limit %q: want provider[/model]=RPM:TPM", entry)
+		}
+		rpmStr, tpmStr, ok := strings.Cut(val, ":")
+		if !ok {
+			return nil, fmt.Errorf("rate limit %q: want provider[/model]=RPM:TPM", entry)
+		}
+		rpm, err := strconv.Atoi(strings.TrimSpace(rpmStr))
+		if err != nil || rpm < 0 {
+			return nil, fmt.Errorf("rate limit %q: bad RPM", entry)
+		}
+		tpm, err := strconv.Atoi(strings.TrimSpace(tpmStr))
+		if err != nil || tpm < 0 {
+			return nil, fmt.Errorf("rate limit %q: bad TPM", entry)
+		}
+		out[strings.TrimSpace(key)] = RateLimit{RPM: rpm, TPM: tpm}
+	}
+	return out, nil
+}
+
+// ErrThrottled is wrapped by the FailureThrottled error a RateLimiter returns
+// for a call it sheds.
+var ErrThrottled = errors.New("ai rate limit saturated")
+
+// defaultTokenEstimate is a call's token estimate before its operation has
+// been metered on a model and when operationTokenEstimates has no seed.
+const defaultTokenEstimate = 2000
+
+// operationTokenEstimates seeds the per-call token estimate of the operations
+// whose size is far from the default. Metered usage takes over after the
+// first call.
+var operationTokenEstimates = map[string]float64{
+	"ClassifyVoiceIntent":     400,
+	"EstimatePortions":        800,
+	"CookingQA":               1500,
+	"GenerateRecipe":          4000,
+	"RegenerateRecipe":        5000,
+	"ForkRecipe":              5000,
+	"ExtractRecipeFromText":   6000,
+	"ExpandAndRankRecipes":    6000,
+	"ExtractRecipesFromMedia": 8000,
+}
+
+// estimateWeight is how far one metered call moves the running estimate.
+const estimateWeight = 0.2
+
+// DefaultRateLimitMaxWait is how long each priority queues before it is shed.
+var DefaultRateLimitMaxWait = map[Priority]time.Duration{
+	PriorityInteractive: 30 * time.Second,
+	PriorityWarming:     15 * time.Second,
+	PriorityBackfill:    5 * time.Second,
+}
+
+// RateLimiter is an AIMiddleware that keeps AI calls inside each provider's
+// requests- and tokens-per-minute limits with a token bucket per
+// provider/model. Calls over the limit queue by priority (see WithPriority);
+// the token cost of a call is estimated up front from the metered usage of
+// earlier calls of the same operation, and corrected in After once the real
+// usage is known. Background priorities are shed first: a full queue drops
+// its lowest-priority waiter, and each priority only waits MaxWait.
+//
+// Limits come from config (per provider, or per provider/model) and from the
+// model registry (SetRegistryLimits), which take precedence. A provider/model
+// with no limit is not throttled.
+type RateLimiter struct {
+	// MaxQueue bounds the waiters per bucket.
+	MaxQueue int
+	// MaxWait bounds how long each priority may queue; zero waits as long as
+	// the call's context allows.
+	MaxWait map[Priority]time.Duration
+
+	mu        sync.Mutex
+	limits    map[string]RateLimit // config: "provider" or "provider/model"
+	overrides map[string]RateLimit // registry: "provider/model"
+	buckets   map[string]*bucket
+	estimates map[string]float64 // "operation/model" -> tokens per call
+	seq       uint64
+	now       func() time.Time
+}
+
+// NewRateLimiter creates a limiter with the configured limits (see
+// ParseRateLimits) and the default queue bounds.
+func NewRateLimiter(limits map[string]RateLimit) *RateLimiter {
+	if limits == nil {
+		limits = map[string]RateLimit{}
+	}
+	return &RateLimiter{
+		MaxQueue:  64,
+		MaxWait:   DefaultRateLimitMaxWait,
+		limits:    limits,
+		overrides: map[string]RateLimit{},
+		buckets:   map[string]*bucket{},
+		estimates: map[string]float64{},
+		now:       time.Now,
+	}
+}
+
+// SetRegistryLimits replaces the model registry's limits, keyed
+// "provider/model"; they override the configured ones.
+func (l *RateLimiter) SetRegistryLimits(limits map[string]RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.overrides = map[string]RateLimit{}
+	for key, lim := range limits {
+		if !lim.unlimited() {
+			l.overrides[key] = lim
+		}
+	}
+	for key, b := range l.buckets {
+		provider, model, _ := strings.Cut(key, "/")
+		if lim := l.resolveLocked(provider, model); lim != b.limit {
+			b.setLimit(lim, l.now())
+			l.dispatchLocked(b)
+		}
+	}
+}
+
+// resolveLocked returns the limit for provider/model: registry, then
+// configured provider/model, then configured provider.
+func (l *RateLimiter) resolveLocked(provider, model string) RateLimit {
+	key := provider + "/" + model
+	if lim, ok := l.overrides[key]; ok {
+		return lim
+	}
+	if lim, ok := l.limits[key]; ok {
+		return lim
+	}
+	return l.limits[provider]
+}
+
+// bucket is one provider/model's token buckets and queue.
+type bucket struct {
+	limit    RateLimit
+	requests float64 // available requests
+	tokens   float64 // available tokens; negative after an underestimate
+	last     time.Time
+	queue    []*waiter // by priority, then arrival
+	timer    *time.Timer
+}
+
+type waiter struct {
+	priority Priority
+	seq      uint64
+	tokens   float64
+	done     chan error // receives nil on admission, an error when shed
+}
+
+func newBucket(lim RateLimit, now time.Time) *bucket {
+	return &bucket{limit: lim, requests: float64(lim.RPM), tokens: float64(lim.TPM), last: now}
+}
+
+func (b *bucket) setLimit(lim RateLimit, now time.Time) {
+	b.refill(now)
+	b.limit = lim
+	b.requests = min(b.requests, float64(lim.RPM))
+	b.tokens = min(b.tokens, float64(lim.TPM))
+}
+
+func (b *bucket) refill(now time.Time) {
+	minutes := now.Sub(b.last).Minutes()
+	b.last = now
+	if minutes <= 0 {
+		return
+	}
+	if b.limit.RPM > 0 {
+		b.requests = min(float64(b.limit.RPM), b.requests+minutes*float64(b.limit.RPM))
+	}
+	if b.limit.TPM > 0 {
+		b.tokens = min(float64(b.limit.TPM), b.tokens+minutes*float64(b.limit.TPM))
+	}
+}
+
+func (b *bucket) fits(tokens float64) bool {
+	return (b.limit.RPM <= 0 || b.requests >= 1) && (b.limit.TPM <= 0 || b.tokens >= tokens)
+}
+
+func (b *bucket) take(tokens float64) {
+	if b.limit.RPM > 0 {
+		b.requests--
+	}
+	if b.limit.TPM > 0 {
+		b.tokens -= tokens
+	}
+}
+
+// waitFor is how long until a call of the given tokens fits.
+func (b *bucket) waitFor(tokens float64) time.Duration {
+	var minutes float64
+	if b.limit.RPM > 0 && b.requests < 1 {
+		minutes = max(minutes, (1-b.requests)/float64(b.limit.RPM))
+	}
+	if b.limit.TPM > 0 && b.tokens < tokens {
+		minutes = max(minutes, (tokens-b.tokens)/float64(b.limit.TPM))
+	}
+	return max(time.Duration(minutes*float64(time.Minute)), 10*time.Millisecond)
+}
+
+// clamp caps a call's tokens at the bucket size so an oversized call is
+// delayed rather than blocked forever.
+func (b *bucket) clamp(tokens float64) float64 {
+	if b.limit.TPM > 0 {
+		return min(tokens, float64(b.limit.TPM))
+	}
+	return tokens
+}
+
+func (b *bucket) remove(w *waiter) bool {
+	i := slices.Index(b.queue, w)
+	if i < 0 {
+		return false
+	}
+	b.queue = slices.Delete(b.queue, i, i+1)
+	return true
+}
+
+// reservation is an admitted call's claim on a bucket, reconciled in After.
+type reservation struct {
+	bucket   *bucket
+	estimate string // key into estimates
+	tokens   float64
+}
+
+type reservationKey struct{}
+
+// Admit holds the call until its provider/model's buckets have room, or
+// refuses it with a FailureThrottled error when it is shed.
+func (l *RateLimiter) Admit(ctx context.Context, op AIOperation) (context.Context, error) {
+	priority := PriorityFromContext(ctx)
+	key := op.Provider + "/" + op.Model
+
+	l.mu.Lock()
+	lim := l.resolveLocked(op.Provider, op.Model)
+	b, ok := l.buckets[key]
+	if !ok {
+		if lim.unlimited() {
+			l.mu.Unlock()
+			return ctx, nil
+		}
+		b = newBucket(lim, l.now())
+		l.buckets[key] = b
+	} else if b.limit != lim {
+		b.setLimit(lim, l.now())
+	}
+
+	estimateKey := op.Name + "/" + op.Model
+	tokens := b.clamp(l.estimateLocked(op.Name, estimateKey))
+	l.seq++
+	w := &waiter{priority: priority, seq: l.seq, tokens: tokens, done: make(chan error, 1)}
+	if !l.enqueueLocked(b, w, op) {
+		l.mu.Unlock()
+		return ctx, throttled(op, priority, "queue full")
+	}
+	l.dispatchLocked(b)
+	l.mu.Unlock()
+
+	admitted := context.WithValue(ctx, reservationKey{}, &reservation{bucket: b, estimate: estimateKey, tokens: tokens})
+
+	var timeout <-chan time.Time
+	if d := l.MaxWait[priority]; d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		timeout = t.C
+	}
+	var giveUp error
+	select {
+	case err := <-w.done:
+		if err != nil {
+			return ctx, err
+		}
+		return admitted, nil
+	case <-ctx.Done():
+		giveUp = ctx.Err()
+	case <-timeout:
+		giveUp = throttled(op, priority, "waited too long")
+	}
+
+	l.mu.Lock()
+	if b.remove(w) {
+		l.dispatchLocked(b)
+		l.mu.Unlock()
+		return ctx, giveUp
+	}
+	l.mu.Unlock()
+	// Admitted or shed while giving up; honour whichever it was.
+	if err := <-w.done; err != nil {
+		return ctx, err
+	}
+	return admitted, nil
+}
+
+// enqueueLocked queues w in priority order. A full queue sheds its
+// lowest-priority, latest waiter to make room, unless w is no more important.
+func (l *RateLimiter) enqueueLocked(b *bucket, w *waiter, op AIOperation) bool {
+	if l.MaxQueue > 0 && len(b.queue) >= l.MaxQueue {
+		last := b.queue[len(b.queue)-1]
+		if last.priority <= w.priority {
+			return false
+		}
+		b.queue = b.queue[:len(b.queue)-1]
+		last.done <- throttled(op, last.priority, "displaced by a higher-priority call")
+	}
+	i, _ := slices.BinarySearchFunc(b.queue, w, func(q, t *waiter) int {
+		return cmp.Or(cmp.Compare(q.priority, t.priority), cmp.Compare(q.seq, t.seq))
+	})
+	b.queue = slices.Insert(b.queue, i, w)
+	return true
+}
+
+// dispatchLocked admits queued calls in order while they fit, and schedules
+// another pass for when the head of the queue will.
+func (l *RateLimiter) dispatchLocked(b *bucket) {
+	b.refill(l.now())
+	for len(b.queue) > 0 && b.fits(b.queue[0].tokens) {
+		w := b.queue[0]
+		b.queue = b.queue[1:]
+		b.take(w.tokens)
+		w.done <- nil
+	}
+	if len(b.queue) > 0 && b.timer == nil {
+		b.timer = time.AfterFunc(b.waitFor(b.queue[0].tokens), func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			b.timer = nil
+			l.dispatchLocked(b)
+		})
+	}
+}
+
+func (l *RateLimiter) estimateLocked(operation, key string) float64 {
+	if e, ok := l.estimates[key]; ok {
+		return e
+	}
+	if e, ok := operationTokenEstimates[operation]; ok {
+		return e
+	}
+	return defaultTokenEstimate
+}
+
+func throttled(op AIOperation, p Priority, why string) error {
+	logger.Get().Warn("ai call shed by rate limiter",
+		zap.String("operation", op.Name),
+		zap.String("provider", op.Provider),
+		zap.String("model", op.Model),
+		zap.String("priority", p.String()),
+		zap.String("reason", why),
+	)
+	return NewAIError(FailureThrottled, ErrThrottled, fmt.Sprintf("%s %s call shed: %s", op.Provider, p, why))
+}
+
+// Before is a no-op; admission happens in Admit.
+func (l *RateLimiter) Before(ctx context.Context, op AIOperation) context.Context {
+	return ctx
+}
+
+// After charges the bucket for the call's real token usage in place of the
+// estimate and folds the usage into the operation's running estimate. A call
+// that failed without consuming tokens is refunded.
+func (l *RateLimiter) After(ctx context.Context, result AIOperationResult) {
+	res, ok := ctx.Value(reservationKey{}).(*reservation)
+	if !ok {
+		return
+	}
+	used := float64(result.Usage.InputTokens + result.Usage.OutputTokens)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := res.bucket
+	b.refill(l.now())
+	if b.limit.TPM > 0 {
+		b.tokens = min(float64(b.limit.TPM), b.tokens+res.tokens-used)
+	}
+	if used > 0 {
+		prev := l.estimateLocked(result.Operation.Name, res.estimate)
+		l.estimates[res.estimate] = prev + estimateWeight*(used-prev)
+	}
+	l.dispatchLocked(b)
+}
@@ -0,0 +1,216 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func limitedOp() AIOperation {
+	return AIOperation{Name: "GenerateRecipe", Provider: "anthropic", Model: "claude-haiku-4-5", StartTime: time.Now()}
+}
+
+// drain admits calls until the bucket is empty.
+func drain(t *testing.T, l *RateLimiter, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if _, err := l.Admit(context.Background(), limitedOp()); err != nil {
+			t.Fatalf("admit %d: %v", i, err)
+		}
+	}
+}
+
+func isThrottled(err error) bool {
+	var aiErr *AIError
+	return errors.As(err, &aiErr) && aiErr.Kind == FailureThrottled && errors.Is(err, ErrThrottled)
+}
+
+func TestParseRateLimits(t *testing.T) {
+	got, err := ParseRateLimits(" anthropic=50:40000, gemini/gemini-2.0-flash=2000:0 ,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["anthropic"] != (RateLimit{RPM: 50, TPM: 40000}) || got["gemini/gemini-2.0-flash"] != (RateLimit{RPM: 2000}) {
+		t.Errorf("parsed %+v", got)
+	}
+	for _, bad := range []string{"anthropic", "anthropic=50", "=1:2", "anthropic=x:1", "anthropic=1:-2"} {
+		if _, err := ParseRateLimits(bad); err == nil {
+			t.Errorf("ParseRateLimits(%q): expected error", bad)
+		}
+	}
+}
+
+func TestRateLimiter_UnlimitedPassesThrough(t *testing.T) {
+	l := NewRateLimiter(map[string]RateLimit{"openai": {RPM: 1}})
+	for i := 0; i < 100; i++ {
+		if _, err := l.Admit(context.Background(), limitedOp()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRateLimiter_WaitsForRefill(t *testing.T) {
+	// 1200 RPM refills one request every 50ms.
+	l := NewRateLimiter(map[string]RateLimit{"anthropic": {RPM: 1200}})
+	drain(t, l, 1200)
+
+	start := time.Now()
+	if _, err := l.Admit(context.Background(), limitedOp()); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited < 30*time.Millisecond {
+		t.Errorf("admitted after %v; want a wait for the bucket to refill", waited)
+	}
+}
+
+func TestRateLimiter_AdmitsByPriority(t *testing.T) {
+	l := NewRateLimiter(map[string]RateLimit{"anthropic": {RPM: 600}})
+	drain(t, l, 600)
+
+	var mu sync.Mutex
+	var order []Priority
+	var wg sync.WaitGroup
+	admit := func(p Priority) {
+		defer wg.Done()
+		if _, err := l.Admit(WithPriority(context.Background(), p), limitedOp()); err != nil {
+			t.Error(err)
+			return
+		}
+		mu.Lock()
+		order = append(order, p)
+		mu.Unlock()
+	}
+	wg.Add(3)
+	go admit(PriorityBackfill)
+	time.Sleep(10 * time.Millisecond)
+	go admit(PriorityWarming)
+	time.Sleep(10 * time.Millisecond)
+	go admit(PriorityInteractive)
+	wg.Wait()
+
+	want := []Priority{PriorityInteractive, PriorityWarming, PriorityBackfill}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("admission order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestRateLimiter_FullQueueShedsBackgroundFirst(t *testing.T) {
+	l := NewRateLimiter(map[string]RateLimit{"anthropic": {RPM: 1}})
+	l.MaxQueue = 1
+	drain(t, l, 1)
+
+	shed := make(chan error, 1)
+	go func() {
+		_, err := l.Admit(WithPriority(context.Background(), PriorityBackfill), limitedOp())
+		shed <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// An interactive call displaces the queued backfill...
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _, _ = l.Admit(ctx, limitedOp()) }()
+	select {
+	case err := <-shed:
+		if !isThrottled(err) {
+			t.Errorf("backfill err = %v, want FailureThrottled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("queued backfill was not shed")
+	}
+
+	// ...and a newcomer no more important than the queue is refused outright.
+	time.Sleep(10 * time.Millisecond)
+	if _, err := l.Admit(WithPriority(context.Background(), PriorityWarming), limitedOp()); !isThrottled(err) {
+		t.Errorf("warming err = %v, want FailureThrottled", err)
+	}
+}
+
+func TestRateLimiter_MaxWaitShedsAndContextCancels(t *testing.T) {
+	l := NewRateLimiter(map[string]RateLimit{"anthropic": {RPM: 1}})
+	l.MaxWait = map[Priority]time.Duration{PriorityWarming: 20 * time.Millisecond}
+	drain(t, l, 1)
+
+	if _, err := l.Admit(WithPriority(context.Background(), PriorityWarming), limitedOp()); !isThrottled(err) {
+		t.Errorf("warming err = %v, want FailureThrottled after MaxWait", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Admit(ctx, limitedOp()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("interactive err = %v, want the context's deadline", err)
+	}
+	if n := len(l.buckets["anthropic/claude-haiku-4-5"].queue); n != 0 {
+		t.Errorf("%d waiters left queued after giving up", n)
+	}
+}
+
+func TestRateLimiter_ChargesActualTokensAndLearnsEstimate(t *testing.T) {
+	l := NewRateLimiter(map[string]RateLimit{"anthropic": {TPM: 100000}})
+	op := limitedOp()
+	ctx, err := l.Admit(context.Background(), op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := l.buckets["anthropic/claude-haiku-4-5"]
+	if b.tokens > 100000-4000+1 {
+		t.Errorf("tokens = %.0f, want the GenerateRecipe seed estimate (4000) reserved", b.tokens)
+	}
+
+	l.After(ctx, AIOperationResult{Operation: op, Usage: TokenUsage{InputTokens: 9000, OutputTokens: 1000}})
+	if b.tokens > 100000-10000+1 {
+		t.Errorf("tokens = %.0f, want the real 10000 charged", b.tokens)
+	}
+	if e := l.estimates["GenerateRecipe/claude-haiku-4-5"]; e != 5200 {
+		t.Errorf("estimate = %.0f, want 4000 moved a fifth of the way to 10000", e)
+	}
+
+	// A failed call that consumed nothing is refunded.
+	before := b.tokens
+	ctx, _ = l.Admit(context.Background(), op)
+	l.After(ctx, AIOperationResult{Operation: op, Err: errors.New("boom")})
+	if b.tokens < before-1 {
+		t.Errorf("tokens = %.0f, want the failed call refunded (%.0f)", b.tokens, before)
+	}
+}
+
+func TestRateLimiter_RegistryLimitOverridesConfig(t *testing.T) {
+	l := NewRateLimiter(map[string]RateLimit{"anthropic": {RPM: 1}})
+	l.SetRegistryLimits(map[string]RateLimit{"anthropic/claude-haiku-4-5": {RPM: 1000}})
+	drain(t, l, 10)
+
+	// Clearing it caps the bucket at the config's 1 RPM: one more call, then a wait.
+	l.SetRegistryLimits(nil)
+	drain(t, l, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Admit(ctx, limitedOp()); err == nil {
+		t.Error("clearing the registry limit should fall back to the config's 1 RPM")
+	}
+}
+
+func TestRunWithMiddleware_ShedCallNeverReachesProvider(t *testing.T) {
+	l := NewRateLimiter(map[string]RateLimit{"anthropic": {RPM: 1}})
+	l.MaxWait = map[Priority]time.Duration{PriorityBackfill: time.Millisecond}
+	drain(t, l, 1)
+	var calls []string
+	capture := &recordingMiddleware{name: "log", calls: &calls}
+	chain := NewMiddlewareChain(capture, l)
+
+	called := false
+	_, err := runWithMiddleware(WithPriority(context.Background(), PriorityBackfill), chain, limitedOp(),
+		func(ctx context.Context) (string, error) {
+			called = true
+			return "", nil
+		})
+	if !isThrottled(err) || called {
+		t.Errorf("err = %v, provider called = %v; want shed before the provider", err, called)
+	}
+	if len(calls) != 1 || calls[0] != "log:after" || !isThrottled(capture.gotResult.Err) {
+		t.Errorf("calls = %v, want only After to see the refusal", calls)
+	}
+}
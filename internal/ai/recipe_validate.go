@@ -0,0 +1,257 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/units"
+	"go.uber.org/zap"
+)
+
+// RecipeIssue is one problem found in a model's recipe output. Field is the
+// path in create_recipe terms (e.g. "ingredients[2].unit") so the repair turn
+// can point the model at exactly what to fix.
+type RecipeIssue struct {
+	Field   string
+	Problem string
+}
+
+func (i RecipeIssue) String() string {
+	return i.Field + ": " + i.Problem
+}
+
+// metricUnits are the units metric_unit may hold.
+var metricUnits = map[string]bool{"mg": true, "g": true, "kg": true, "mL": true, "L": true}
+
+// ValidateRecipe lists everything wrong with a recipe result: a missing title,
+// ingredients or instructions; empty ingredient names or steps; units that
+// units.Canonical doesn't know or that aren't in canonical form; negative or
+// zero amounts on measured ingredients; inverted ranges; non-metric metric
+// equivalents; and ingredient lines repeated verbatim.
+func ValidateRecipe(r *RecipeResult) []RecipeIssue {
+	var issues []RecipeIssue
+	add := func(field, format string, args ...interface{}) {
+		issues = append(issues, RecipeIssue{Field: field, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(r.Title) == "" {
+		add("title", "missing")
+	}
+	if len(r.Ingredients) == 0 {
+		add("ingredients", "no ingredients")
+	}
+	if len(r.Instructions) == 0 {
+		add("instructions", "no instructions")
+	}
+
+	seen := map[string]int{}
+	for i, ing := range r.Ingredients {
+		field := fmt.Sprintf("ingredients[%d]", i)
+		if strings.TrimSpace(ing.Name) == "" {
+			add(field+".name", "empty")
+			continue
+		}
+		if ing.Unit != "" {
+			canonical, ok := units.Canonical(ing.Unit)
+			switch {
+			case !ok:
+				add(field+".unit", "unknown unit %q", ing.Unit)
+			case canonical != ing.Unit:
+				add(field+".unit", "%q is not a canonical unit; use %q", ing.Unit, canonical)
+			}
+		}
+		if ing.Amount < 0 {
+			add(field+".amount", "negative (%g)", ing.Amount)
+		} else if ing.Amount == 0 && ing.Unit != "" {
+			add(field+".amount", "zero with unit %q; give the quantity, or leave unit empty for an unmeasured ingredient", ing.Unit)
+		}
+		if ing.AmountHigh != 0 && ing.AmountHigh < ing.Amount {
+			add(field+".amount_high", "%g is below amount %g", ing.AmountHigh, ing.Amount)
+		}
+		if ing.MetricUnit != "" && !metricUnits[ing.MetricUnit] {
+			add(field+".metric_unit", "%q is not one of mg, g, kg, mL, L", ing.MetricUnit)
+		}
+		if ing.MetricAmount < 0 {
+			add(field+".metric_amount", "negative (%g)", ing.MetricAmount)
+		}
+		key := ingredientKey(ing)
+		if j, dup := seen[key]; dup {
+			add(field, "duplicates ingredients[%d]", j)
+		} else {
+			seen[key] = i
+		}
+	}
+
+	for i, step := range r.Instructions {
+		if strings.TrimSpace(step) == "" {
+			add(fmt.Sprintf("instructions[%d]", i), "empty step")
+		}
+	}
+	return issues
+}
+
+// ingredientKey identifies an ingredient line for duplicate detection. Only a
+// verbatim repeat counts: the same ingredient listed twice with different
+// amounts is usually split across components (dough and filling).
+func ingredientKey(ing IngredientResult) string {
+	return fmt.Sprintf("%s|%s|%g", strings.ToLower(strings.TrimSpace(ing.Name)), ing.Unit, ing.Amount)
+}
+
+// CleanupRecipe deterministically fixes what ValidateRecipe reports where a
+// safe fix exists, returning a new result: unit spellings are canonicalized
+// and unknown units dropped, negative amounts made positive, zero-amount
+// ingredients left unmeasured, inverted ranges and non-metric equivalents
+// cleared, nameless ingredients, verbatim duplicates and empty steps removed.
+// A missing title or an empty ingredient or instruction list can't be fixed
+// here.
+func CleanupRecipe(r *RecipeResult) *RecipeResult {
+	out := *r
+	out.Title = strings.TrimSpace(r.Title)
+	out.Ingredients = make([]IngredientResult, 0, len(r.Ingredients))
+	seen := map[string]bool{}
+	for _, ing := range r.Ingredients {
+		ing.Name = strings.TrimSpace(ing.Name)
+		if ing.Name == "" {
+			continue
+		}
+		if ing.Unit != "" {
+			canonical, ok := units.Canonical(ing.Unit)
+			if !ok {
+				canonical = ""
+			}
+			ing.Unit = canonical
+		}
+		ing.Amount = math.Abs(ing.Amount)
+		ing.AmountHigh = math.Abs(ing.AmountHigh)
+		if ing.Amount == 0 {
+			ing.Unit = ""
+			ing.AmountHigh = 0
+		}
+		if ing.AmountHigh != 0 && ing.AmountHigh < ing.Amount {
+			ing.AmountHigh = 0
+		}
+		if ing.MetricUnit != "" && !metricUnits[ing.MetricUnit] {
+			if canonical, ok := units.Canonical(ing.MetricUnit); ok && metricUnits[canonical] {
+				ing.MetricUnit = canonical
+			} else {
+				ing.MetricUnit = ""
+				ing.MetricAmount = 0
+			}
+		}
+		ing.MetricAmount = math.Abs(ing.MetricAmount)
+		key := ingredientKey(ing)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out.Ingredients = append(out.Ingredients, ing)
+	}
+	out.Instructions = make([]string, 0, len(r.Instructions))
+	for _, step := range r.Instructions {
+		if step = strings.TrimSpace(step); step != "" {
+			out.Instructions = append(out.Instructions, step)
+		}
+	}
+	return &out
+}
+
+// RecipeRepair counts what it took to get a usable recipe out of one AI
+// call: the issues found in the model's first answer, repair turns sent, and
+// whether deterministic cleanup had the last word. Recorded per call in
+// ai_usage_logs so the dashboard shows which models need repairing.
+type RecipeRepair struct {
+	Issues  int
+	Turns   int
+	Cleaned bool
+}
+
+type repairKeyType struct{}
+
+// repairKey marks the per-call repair sink stored in the context.
+var repairKey repairKeyType
+
+// recordRepair adds a recipe's repair stats to the in-flight sink. A no-op if
+// no sink is in the context.
+func recordRepair(ctx context.Context, r RecipeRepair) {
+	if sink, ok := ctx.Value(repairKey).(*RecipeRepair); ok && sink != nil {
+		sink.Issues += r.Issues
+		sink.Turns += r.Turns
+		sink.Cleaned = sink.Cleaned || r.Cleaned
+	}
+}
+
+// repairFeedback is the tool-result text of a repair turn.
+func repairFeedback(issues []RecipeIssue) string {
+	var b strings.Builder
+	b.WriteString("The recipe you returned has these problems:\n")
+	for _, issue := range issues {
+		b.WriteString("- ")
+		b.WriteString(issue.String())
+		b.WriteString("\n")
+	}
+	b.WriteString("Call create_recipe again with the complete corrected recipe. Change only what is listed; keep everything else as it was.")
+	return b.String()
+}
+
+func issuesSummary(issues []RecipeIssue) string {
+	parts := make([]string, len(issues))
+	for i, issue := range issues {
+		parts[i] = issue.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// recipeRepairFunc asks the model to fix its recipe, given feedback naming
+// every invalid field.
+type recipeRepairFunc func(ctx context.Context, feedback string) (*RecipeResult, error)
+
+// finishRecipe validates a parsed recipe. One with issues gets a single
+// repair turn (when repair is non-nil) telling the model exactly which fields
+// were invalid; whatever is still wrong after that is cleaned up
+// deterministically. It fails with FailureContentQuality only when the
+// result still lacks a title, ingredients or instructions.
+func finishRecipe(ctx context.Context, r *RecipeResult, repair recipeRepairFunc) (*RecipeResult, error) {
+	issues := ValidateRecipe(r)
+	if len(issues) == 0 {
+		return r, nil
+	}
+	stats := RecipeRepair{Issues: len(issues)}
+	logger.Get().Info("recipe result has issues",
+		zap.String("title", r.Title), zap.Int("issues", len(issues)), zap.String("detail", issuesSummary(issues)))
+
+	if repair != nil {
+		stats.Turns = 1
+		fixed, err := repair(ctx, repairFeedback(issues))
+		switch {
+		case err != nil:
+			logger.Get().Warn("recipe repair turn failed, cleaning up the original", zap.Error(err))
+		case fixed != nil:
+			remaining := ValidateRecipe(fixed)
+			if len(remaining) == 0 {
+				recordRepair(ctx, stats)
+				return fixed, nil
+			}
+			if len(remaining) < len(issues) {
+				r = fixed
+			}
+		}
+	}
+
+	cleaned := CleanupRecipe(r)
+	stats.Cleaned = true
+	recordRepair(ctx, stats)
+	if remaining := ValidateRecipe(cleaned); len(remaining) > 0 {
+		err := NewAIError(FailureContentQuality, errors.New(issuesSummary(remaining)), "recipe quality check failed")
+		logger.Get().Warn("AI operation failed",
+			zap.String("kind", err.kindString()),
+			zap.String("detail", err.Detail),
+			zap.Error(err.Err),
+		)
+		return nil, err
+	}
+	return cleaned, nil
+}
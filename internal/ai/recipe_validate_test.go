@@ -0,0 +1,175 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validRecipe() *RecipeResult {
+	return &RecipeResult{
+		Title: "Pancakes",
+		Ingredients: []IngredientResult{
+			{Name: "flour", Unit: "cup", Amount: 2, MetricUnit: "g", MetricAmount: 250},
+			{Name: "salt", Unit: "tsp", Amount: 0.5},
+			{Name: "butter, for the pan"},
+		},
+		Instructions: []string{"Mix.", "Cook."},
+	}
+}
+
+func issueFields(issues []RecipeIssue) []string {
+	out := make([]string, len(issues))
+	for i, issue := range issues {
+		out[i] = issue.Field
+	}
+	return out
+}
+
+func TestValidateRecipe_ValidHasNoIssues(t *testing.T) {
+	if issues := ValidateRecipe(validRecipe()); len(issues) != 0 {
+		t.Errorf("issues = %v, want none", issues)
+	}
+}
+
+func TestValidateRecipe_AcceptsEverySchemaUnit(t *testing.T) {
+	for _, unit := range ingredientUnits {
+		r := validRecipe()
+		r.Ingredients = append(r.Ingredients, IngredientResult{Name: "milk", Unit: unit, Amount: 1})
+		if issues := ValidateRecipe(r); len(issues) != 0 {
+			t.Errorf("unit %q: issues = %v, want none", unit, issues)
+		}
+		if got := CleanupRecipe(r).Ingredients[3].Unit; got != unit {
+			t.Errorf("unit %q: cleaned up to %q", unit, got)
+		}
+	}
+}
+
+func TestValidateRecipe_ReportsEachField(t *testing.T) {
+	r := validRecipe()
+	r.Ingredients = append(r.Ingredients,
+		IngredientResult{Name: "sugar", Unit: "handful", Amount: 1},
+		IngredientResult{Name: "milk", Unit: "cups", Amount: 1},
+		IngredientResult{Name: "eggs", Amount: -2},
+		IngredientResult{Name: "oil", Unit: "tbsp", Amount: 0},
+		IngredientResult{Name: "water", Unit: "cup", Amount: 2, AmountHigh: 1},
+		IngredientResult{Name: "flour", Unit: "cup", Amount: 2},
+	)
+	r.Instructions = append(r.Instructions, "  ")
+
+	got := strings.Join(issueFields(ValidateRecipe(r)), ",")
+	want := "ingredients[3].unit,ingredients[4].unit,ingredients[5].amount,ingredients[6].amount," +
+		"ingredients[7].amount_high,ingredients[8],instructions[2]"
+	if got != want {
+		t.Errorf("issue fields = %s, want %s", got, want)
+	}
+}
+
+func TestCleanupRecipe_FixesWhatItCan(t *testing.T) {
+	r := validRecipe()
+	r.Ingredients = append(r.Ingredients,
+		IngredientResult{Name: "milk", Unit: "cups", Amount: 1},
+		IngredientResult{Name: "sugar", Unit: "handful", Amount: 1},
+		IngredientResult{Name: "oil", Unit: "tbsp", Amount: 0},
+		IngredientResult{Name: "  "},
+		IngredientResult{Name: "flour", Unit: "cup", Amount: 2},
+	)
+	r.Instructions = append(r.Instructions, "")
+
+	cleaned := CleanupRecipe(r)
+	if issues := ValidateRecipe(cleaned); len(issues) != 0 {
+		t.Fatalf("cleaned recipe still has issues: %v", issues)
+	}
+	if len(cleaned.Ingredients) != 6 || len(cleaned.Instructions) != 2 {
+		t.Fatalf("cleaned to %d ingredients / %d steps, want 6 / 2", len(cleaned.Ingredients), len(cleaned.Instructions))
+	}
+	if u := cleaned.Ingredients[3].Unit; u != "cup" {
+		t.Errorf("milk unit = %q, want cup", u)
+	}
+	if u := cleaned.Ingredients[4].Unit; u != "" {
+		t.Errorf("unknown unit kept as %q", u)
+	}
+	if len(r.Ingredients) != 8 {
+		t.Error("CleanupRecipe must not modify its input")
+	}
+}
+
+func TestFinishRecipe_ValidSkipsRepair(t *testing.T) {
+	called := false
+	got, err := finishRecipe(context.Background(), validRecipe(), func(ctx context.Context, feedback string) (*RecipeResult, error) {
+		called = true
+		return nil, nil
+	})
+	if err != nil || got == nil || called {
+		t.Errorf("got %v, err %v, repair called %v; want the recipe as returned", got, err, called)
+	}
+}
+
+func TestFinishRecipe_RepairTurnFixes(t *testing.T) {
+	bad := validRecipe()
+	bad.Ingredients[0].Unit = "cups"
+	var feedback string
+	got, err := finishRecipe(context.Background(), bad, func(ctx context.Context, fb string) (*RecipeResult, error) {
+		feedback = fb
+		return validRecipe(), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(feedback, "ingredients[0].unit") {
+		t.Errorf("feedback does not name the invalid field:\n%s", feedback)
+	}
+	if got.Ingredients[0].MetricAmount != 250 {
+		t.Error("want the repaired recipe")
+	}
+}
+
+func TestFinishRecipe_FailedRepairFallsBackToCleanup(t *testing.T) {
+	bad := validRecipe()
+	bad.Ingredients[0].Unit = "cups"
+	got, err := finishRecipe(context.Background(), bad, func(ctx context.Context, fb string) (*RecipeResult, error) {
+		return nil, errors.New("upstream down")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Ingredients[0].Unit != "cup" {
+		t.Errorf("unit = %q, want cleanup to canonicalize it", got.Ingredients[0].Unit)
+	}
+}
+
+func TestFinishRecipe_UnfixableIsContentQuality(t *testing.T) {
+	bad := validRecipe()
+	bad.Instructions = nil
+	_, err := finishRecipe(context.Background(), bad, func(ctx context.Context, fb string) (*RecipeResult, error) {
+		return bad, nil
+	})
+	var aiErr *AIError
+	if !errors.As(err, &aiErr) || aiErr.Kind != FailureContentQuality {
+		t.Errorf("err = %v, want FailureContentQuality", err)
+	}
+}
+
+func TestRunWithMiddleware_FlowsRepairToUsageRecord(t *testing.T) {
+	var got UsageRecord
+	mw := &CostMiddleware{Sink: func(r UsageRecord) { got = r }}
+	bad := validRecipe()
+	bad.Ingredients[0].Unit = "cups"
+	_, _ = runWithMiddleware(context.Background(), mw,
+		AIOperation{Name: "GenerateRecipe", Model: "claude-haiku-4-5", StartTime: time.Now()},
+		func(ctx context.Context) (*RecipeResult, error) {
+			recordUsage(ctx, TokenUsage{InputTokens: 10, OutputTokens: 5})
+			return finishRecipe(ctx, bad, func(ctx context.Context, fb string) (*RecipeResult, error) {
+				recordUsage(ctx, TokenUsage{InputTokens: 20, OutputTokens: 5})
+				return validRecipe(), nil
+			})
+		})
+	if got.RepairIssues != 1 || got.RepairTurns != 1 || got.RepairCleaned {
+		t.Errorf("repair = %d/%d/%v, want 1 issue, 1 turn, not cleaned", got.RepairIssues, got.RepairTurns, got.RepairCleaned)
+	}
+	if got.InputTokens != 30 || got.OutputTokens != 10 {
+		t.Errorf("usage = %d/%d, want both calls metered (30/10)", got.InputTokens, got.OutputTokens)
+	}
+}
@@ -0,0 +1,363 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"go.uber.org/zap"
+)
+
+// The request shapes below are what a cassette keys on: every argument of a
+// call except the context, with binary input reduced to a digest.
+
+type imageRequest struct {
+	Image        string `json:"image"`
+	UnitSystem   string `json:"unit_system"`
+	Requirements string `json:"requirements"`
+}
+
+type mediaRequest struct {
+	Media        []string `json:"media"`
+	ContextText  string   `json:"context_text"`
+	UnitSystem   string   `json:"unit_system"`
+	Requirements string   `json:"requirements"`
+}
+
+type videoRequest struct {
+	Video        string `json:"video"`
+	MimeType     string `json:"mime_type"`
+	ContextText  string `json:"context_text"`
+	UnitSystem   string `json:"unit_system"`
+	Requirements string `json:"requirements"`
+}
+
+type textRequest struct {
+	Text       string `json:"text"`
+	UnitSystem string `json:"unit_system"`
+}
+
+type qaRequest struct {
+	Question      string `json:"question"`
+	RecipeContext string `json:"recipe_context"`
+}
+
+type interviewRequest struct {
+	Messages   []Message `json:"messages"`
+	MemberName string    `json:"member_name"`
+}
+
+type audioRequest struct {
+	Audio  string `json:"audio"`
+	Format string `json:"format"`
+}
+
+type speechRequest struct {
+	Text  string `json:"text"`
+	Voice string `json:"voice"`
+}
+
+// Recorder captures real provider calls into a cassette. Each Wrap method
+// returns a provider that forwards to the real one and records the request
+// and response (or error); the result is passed through untouched. A failure
+// to write the cassette is logged and never fails the call.
+type Recorder struct {
+	cassette *Cassette
+}
+
+// NewRecorder creates a recorder writing to c.
+func NewRecorder(c *Cassette) *Recorder {
+	return &Recorder{cassette: c}
+}
+
+// recordCall runs call and records its outcome under op.
+func recordCall[T any](c *Cassette, op string, req interface{}, call func() (T, error)) (T, error) {
+	out, err := call()
+	if rerr := c.record(op, req, out, err); rerr != nil {
+		logger.Get().Warn("ai cassette: record failed", zap.String("operation", op), zap.Error(rerr))
+	}
+	return out, err
+}
+
+// WrapText records every TextProvider call.
+func (r *Recorder) WrapText(p TextProvider) TextProvider {
+	return &recordingText{inner: p, c: r.cassette}
+}
+
+// WrapVision records every VisionProvider call.
+func (r *Recorder) WrapVision(p VisionProvider) VisionProvider {
+	return &recordingVision{inner: p, c: r.cassette}
+}
+
+// WrapVideo records every VideoProvider call.
+func (r *Recorder) WrapVideo(p VideoProvider) VideoProvider {
+	return &recordingVideo{inner: p, c: r.cassette}
+}
+
+// WrapImage records every ImageProvider call.
+func (r *Recorder) WrapImage(p ImageProvider) ImageProvider {
+	return &recordingImage{inner: p, c: r.cassette}
+}
+
+// WrapSpeech records every SpeechProvider call.
+func (r *Recorder) WrapSpeech(p SpeechProvider) SpeechProvider {
+	return &recordingSpeech{inner: p, c: r.cassette}
+}
+
+// WrapSpeechSynthesis records every SpeechSynthesisProvider call.
+func (r *Recorder) WrapSpeechSynthesis(p SpeechSynthesisProvider) SpeechSynthesisProvider {
+	return &recordingSpeechSynthesis{inner: p, c: r.cassette}
+}
+
+// WrapEmbedding records every EmbeddingProvider call.
+func (r *Recorder) WrapEmbedding(p EmbeddingProvider) EmbeddingProvider {
+	return &recordingEmbedding{inner: p, c: r.cassette}
+}
+
+type recordingText struct {
+	inner TextProvider
+	c     *Cassette
+}
+
+func (p *recordingText) GenerateRecipe(ctx context.Context, req RecipeRequest) (*RecipeResult, error) {
+	return recordCall(p.c, "GenerateRecipe", req, func() (*RecipeResult, error) { return p.inner.GenerateRecipe(ctx, req) })
+}
+
+func (p *recordingText) RegenerateRecipe(ctx context.Context, req RegenerateRequest) (*RecipeResult, error) {
+	return recordCall(p.c, "RegenerateRecipe", req, func() (*RecipeResult, error) { return p.inner.RegenerateRecipe(ctx, req) })
+}
+
+func (p *recordingText) ForkRecipe(ctx context.Context, req ForkRequest) (*RecipeResult, error) {
+	return recordCall(p.c, "ForkRecipe", req, func() (*RecipeResult, error) { return p.inner.ForkRecipe(ctx, req) })
+}
+
+func (p *recordingText) AnalyzeAllergens(ctx context.Context, req AllergenRequest) (*AllergenResult, error) {
+	return recordCall(p.c, "AnalyzeAllergens", req, func() (*AllergenResult, error) { return p.inner.AnalyzeAllergens(ctx, req) })
+}
+
+func (p *recordingText) ClassifyVoiceIntent(ctx context.Context, transcript string) (*VoiceIntent, error) {
+	return recordCall(p.c, "ClassifyVoiceIntent", transcript, func() (*VoiceIntent, error) { return p.inner.ClassifyVoiceIntent(ctx, transcript) })
+}
+
+func (p *recordingText) EstimatePortions(ctx context.Context, recipeDef interface{}) (*PortionEstimate, error) {
+	return recordCall(p.c, "EstimatePortions", recipeDef, func() (*PortionEstimate, error) { return p.inner.EstimatePortions(ctx, recipeDef) })
+}
+
+func (p *recordingText) ExtractRecipeFromText(ctx context.Context, text string, unitSystem string) (*RecipeResult, error) {
+	req := textRequest{Text: text, UnitSystem: unitSystem}
+	return recordCall(p.c, "ExtractRecipeFromText", req, func() (*RecipeResult, error) { return p.inner.ExtractRecipeFromText(ctx, text, unitSystem) })
+}
+
+func (p *recordingText) CookingQA(ctx context.Context, question string, recipeContext string) (string, error) {
+	req := qaRequest{Question: question, RecipeContext: recipeContext}
+	return recordCall(p.c, "CookingQA", req, func() (string, error) { return p.inner.CookingQA(ctx, question, recipeContext) })
+}
+
+func (p *recordingText) DietaryInterview(ctx context.Context, messages []Message, memberName string) (*DietaryInterviewResult, error) {
+	req := interviewRequest{Messages: messages, MemberName: memberName}
+	return recordCall(p.c, "DietaryInterview", req, func() (*DietaryInterviewResult, error) {
+		return p.inner.DietaryInterview(ctx, messages, memberName)
+	})
+}
+
+func (p *recordingText) ExpandAndRankRecipes(ctx context.Context, req FinderRankRequest) (*FinderRankResult, error) {
+	return recordCall(p.c, "ExpandAndRankRecipes", req, func() (*FinderRankResult, error) { return p.inner.ExpandAndRankRecipes(ctx, req) })
+}
+
+func (p *recordingText) AnalyzeCookSteps(ctx context.Context, req CookStepsRequest) ([]CookStepTiming, error) {
+	return recordCall(p.c, "AnalyzeCookSteps", req, func() ([]CookStepTiming, error) { return AnalyzeCookSteps(ctx, p.inner, req) })
+}
+
+func (p *recordingText) SuggestSubstitutions(ctx context.Context, req SubstitutionRequest) (*SubstitutionResult, error) {
+	return recordCall(p.c, "SuggestSubstitutions", req, func() (*SubstitutionResult, error) { return SuggestSubstitutions(ctx, p.inner, req) })
+}
+
+type recordingVision struct {
+	inner VisionProvider
+	c     *Cassette
+}
+
+func (p *recordingVision) ExtractRecipeFromImage(ctx context.Context, imageData []byte, unitSystem string, requirements string) (*RecipeResult, error) {
+	req := imageRequest{Image: digest(imageData), UnitSystem: unitSystem, Requirements: requirements}
+	return recordCall(p.c, "ExtractRecipeFromImage", req, func() (*RecipeResult, error) {
+		return p.inner.ExtractRecipeFromImage(ctx, imageData, unitSystem, requirements)
+	})
+}
+
+func (p *recordingVision) ExtractRecipesFromMedia(ctx context.Context, media []MediaInput, contextText string, unitSystem string, requirements string) ([]*RecipeResult, error) {
+	req := mediaRequest{Media: mediaDigests(media), ContextText: contextText, UnitSystem: unitSystem, Requirements: requirements}
+	return recordCall(p.c, "ExtractRecipesFromMedia", req, func() ([]*RecipeResult, error) {
+		return p.inner.ExtractRecipesFromMedia(ctx, media, contextText, unitSystem, requirements)
+	})
+}
+
+type recordingVideo struct {
+	inner VideoProvider
+	c     *Cassette
+}
+
+func (p *recordingVideo) ExtractRecipesFromVideo(ctx context.Context, videoData []byte, mimeType, contextText, unitSystem, requirements string) ([]*RecipeResult, error) {
+	req := videoRequest{Video: digest(videoData), MimeType: mimeType, ContextText: contextText, UnitSystem: unitSystem, Requirements: requirements}
+	return recordCall(p.c, "ExtractRecipesFromVideo", req, func() ([]*RecipeResult, error) {
+		return p.inner.ExtractRecipesFromVideo(ctx, videoData, mimeType, contextText, unitSystem, requirements)
+	})
+}
+
+type recordingImage struct {
+	inner ImageProvider
+	c     *Cassette
+}
+
+func (p *recordingImage) GenerateImage(ctx context.Context, prompt string) ([]byte, error) {
+	return recordCall(p.c, "GenerateImage", prompt, func() ([]byte, error) { return p.inner.GenerateImage(ctx, prompt) })
+}
+
+type recordingSpeech struct {
+	inner SpeechProvider
+	c     *Cassette
+}
+
+func (p *recordingSpeech) TranscribeAudio(ctx context.Context, audioData []byte, format string) (string, error) {
+	req := audioRequest{Audio: digest(audioData), Format: format}
+	return recordCall(p.c, "TranscribeAudio", req, func() (string, error) { return p.inner.TranscribeAudio(ctx, audioData, format) })
+}
+
+type recordingSpeechSynthesis struct {
+	inner SpeechSynthesisProvider
+	c     *Cassette
+}
+
+func (p *recordingSpeechSynthesis) SynthesizeSpeech(ctx context.Context, text, voice string) (*SpeechAudio, error) {
+	req := speechRequest{Text: text, Voice: voice}
+	return recordCall(p.c, "SynthesizeSpeech", req, func() (*SpeechAudio, error) { return p.inner.SynthesizeSpeech(ctx, text, voice) })
+}
+
+type recordingEmbedding struct {
+	inner EmbeddingProvider
+	c     *Cassette
+}
+
+func (p *recordingEmbedding) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	return recordCall(p.c, "GenerateEmbedding", text, func() ([]float32, error) { return p.inner.GenerateEmbedding(ctx, text) })
+}
+
+// ReplayProvider serves every provider interface from a cassette, with no
+// network and no API keys. Strict replays only exact recordings (for tests);
+// otherwise an unrecorded request gets the latest recording of the same
+// operation, which is enough to run the stack locally.
+type ReplayProvider struct {
+	cassette *Cassette
+	strict   bool
+}
+
+var (
+	_ TextProvider      = (*ReplayProvider)(nil)
+	_ VisionProvider    = (*ReplayProvider)(nil)
+	_ VideoProvider     = (*ReplayProvider)(nil)
+	_ ImageProvider     = (*ReplayProvider)(nil)
+	_ SpeechProvider    = (*ReplayProvider)(nil)
+	_ EmbeddingProvider = (*ReplayProvider)(nil)
+
+	_ SpeechSynthesisProvider = (*ReplayProvider)(nil)
+)
+
+// NewReplayProvider creates a provider replaying c.
+func NewReplayProvider(c *Cassette, strict bool) *ReplayProvider {
+	return &ReplayProvider{cassette: c, strict: strict}
+}
+
+// replayCall returns the recorded outcome of a call.
+func replayCall[T any](p *ReplayProvider, op string, req interface{}) (T, error) {
+	var out T
+	in, err := p.cassette.lookup(op, req, p.strict)
+	if err != nil {
+		return out, NewAIError(FailureUnknown, err, "replay "+op)
+	}
+	if in.Error != "" {
+		return out, replayError(in)
+	}
+	if err := json.Unmarshal(in.Response, &out); err != nil {
+		return out, NewAIError(FailureContentParse, fmt.Errorf("decode recorded %s: %w", op, err), "replay "+op)
+	}
+	return out, nil
+}
+
+func (p *ReplayProvider) GenerateRecipe(ctx context.Context, req RecipeRequest) (*RecipeResult, error) {
+	return replayCall[*RecipeResult](p, "GenerateRecipe", req)
+}
+
+func (p *ReplayProvider) RegenerateRecipe(ctx context.Context, req RegenerateRequest) (*RecipeResult, error) {
+	return replayCall[*RecipeResult](p, "RegenerateRecipe", req)
+}
+
+func (p *ReplayProvider) ForkRecipe(ctx context.Context, req ForkRequest) (*RecipeResult, error) {
+	return replayCall[*RecipeResult](p, "ForkRecipe", req)
+}
+
+func (p *ReplayProvider) AnalyzeAllergens(ctx context.Context, req AllergenRequest) (*AllergenResult, error) {
+	return replayCall[*AllergenResult](p, "AnalyzeAllergens", req)
+}
+
+func (p *ReplayProvider) ClassifyVoiceIntent(ctx context.Context, transcript string) (*VoiceIntent, error) {
+	return replayCall[*VoiceIntent](p, "ClassifyVoiceIntent", transcript)
+}
+
+func (p *ReplayProvider) EstimatePortions(ctx context.Context, recipeDef interface{}) (*PortionEstimate, error) {
+	return replayCall[*PortionEstimate](p, "EstimatePortions", recipeDef)
+}
+
+func (p *ReplayProvider) ExtractRecipeFromText(ctx context.Context, text string, unitSystem string) (*RecipeResult, error) {
+	return replayCall[*RecipeResult](p, "ExtractRecipeFromText", textRequest{Text: text, UnitSystem: unitSystem})
+}
+
+func (p *ReplayProvider) CookingQA(ctx context.Context, question string, recipeContext string) (string, error) {
+	return replayCall[string](p, "CookingQA", qaRequest{Question: question, RecipeContext: recipeContext})
+}
+
+func (p *ReplayProvider) DietaryInterview(ctx context.Context, messages []Message, memberName string) (*DietaryInterviewResult, error) {
+	return replayCall[*DietaryInterviewResult](p, "DietaryInterview", interviewRequest{Messages: messages, MemberName: memberName})
+}
+
+func (p *ReplayProvider) ExpandAndRankRecipes(ctx context.Context, req FinderRankRequest) (*FinderRankResult, error) {
+	return replayCall[*FinderRankResult](p, "ExpandAndRankRecipes", req)
+}
+
+func (p *ReplayProvider) AnalyzeCookSteps(ctx context.Context, req CookStepsRequest) ([]CookStepTiming, error) {
+	return replayCall[[]CookStepTiming](p, "AnalyzeCookSteps", req)
+}
+
+func (p *ReplayProvider) SuggestSubstitutions(ctx context.Context, req SubstitutionRequest) (*SubstitutionResult, error) {
+	return replayCall[*SubstitutionResult](p, "SuggestSubstitutions", req)
+}
+
+func (p *ReplayProvider) ExtractRecipeFromImage(ctx context.Context, imageData []byte, unitSystem string, requirements string) (*RecipeResult, error) {
+	req := imageRequest{Image: digest(imageData), UnitSystem: unitSystem, Requirements: requirements}
+	return replayCall[*RecipeResult](p, "ExtractRecipeFromImage", req)
+}
+
+func (p *ReplayProvider) ExtractRecipesFromMedia(ctx context.Context, media []MediaInput, contextText string, unitSystem string, requirements string) ([]*RecipeResult, error) {
+	req := mediaRequest{Media: mediaDigests(media), ContextText: contextText, UnitSystem: unitSystem, Requirements: requirements}
+	return replayCall[[]*RecipeResult](p, "ExtractRecipesFromMedia", req)
+}
+
+func (p *ReplayProvider) ExtractRecipesFromVideo(ctx context.Context, videoData []byte, mimeType, contextText, unitSystem, requirements string) ([]*RecipeResult, error) {
+	req := videoRequest{Video: digest(videoData), MimeType: mimeType, ContextText: contextText, UnitSystem: unitSystem, Requirements: requirements}
+	return replayCall[[]*RecipeResult](p, "ExtractRecipesFromVideo", req)
+}
+
+func (p *ReplayProvider) GenerateImage(ctx context.Context, prompt string) ([]byte, error) {
+	return replayCall[[]byte](p, "GenerateImage", prompt)
+}
+
+func (p *ReplayProvider) TranscribeAudio(ctx context.Context, audioData []byte, format string) (string, error) {
+	return replayCall[string](p, "TranscribeAudio", audioRequest{Audio: digest(audioData), Format: format})
+}
+
+func (p *ReplayProvider) SynthesizeSpeech(ctx context.Context, text, voice string) (*SpeechAudio, error) {
+	return replayCall[*SpeechAudio](p, "SynthesizeSpeech", speechRequest{Text: text, Voice: voice})
+}
+
+func (p *ReplayProvider) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	return replayCall[[]float32](p, "GenerateEmbedding", text)
+}
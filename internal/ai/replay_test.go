@@ -0,0 +1,137 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// echoQA answers CookingQA by echoing the question, leaking the key it was
+// given so redaction can be checked.
+type echoQA struct {
+	switchStub
+	key string
+}
+
+func (e echoQA) CookingQA(_ context.Context, question string, _ string) (string, error) {
+	return "answer to " + question + " via " + e.key, nil
+}
+
+type stubSpeech struct{ text string }
+
+func (s stubSpeech) TranscribeAudio(context.Context, []byte, string) (string, error) {
+	return s.text, nil
+}
+
+type stubVideo struct{}
+
+func (stubVideo) ExtractRecipesFromVideo(context.Context, []byte, string, string, string, string) ([]*RecipeResult, error) {
+	return nil, ErrVideoTooLarge
+}
+
+func TestRecordReplay_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	const key = "sk-live-0123456789abcdefghij"
+
+	c, err := LoadCassette(path, key)
+	if err != nil {
+		t.Fatalf("LoadCassette() error = %v", err)
+	}
+	rec := NewRecorder(c)
+	text := rec.WrapText(echoQA{switchStub: switchStub{name: "Toast"}, key: key})
+	speech := rec.WrapSpeech(stubSpeech{text: "next step"})
+	video := rec.WrapVideo(stubVideo{})
+
+	ctx := context.Background()
+	if _, err := text.GenerateRecipe(ctx, RecipeRequest{UserPrompt: "toast"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := text.CookingQA(ctx, "how long?", "toast"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := speech.TranscribeAudio(ctx, []byte("audio bytes"), "webm"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := video.ExtractRecipesFromVideo(ctx, []byte("video"), "video/mp4", "", "metric", ""); !errors.Is(err, ErrVideoTooLarge) {
+		t.Fatalf("recorded video err = %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cassette not written: %v", err)
+	}
+	if strings.Contains(string(raw), key) {
+		t.Error("cassette contains the API key")
+	}
+	if strings.Contains(string(raw), "audio bytes") {
+		t.Error("cassette contains raw audio instead of a digest")
+	}
+
+	loaded, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("reload error = %v", err)
+	}
+	if loaded.Len() != 4 {
+		t.Fatalf("reloaded %d interactions, want 4", loaded.Len())
+	}
+	replay := NewReplayProvider(loaded, true)
+
+	got, err := replay.GenerateRecipe(ctx, RecipeRequest{UserPrompt: "toast"})
+	if err != nil || got.Title != "Toast" {
+		t.Errorf("GenerateRecipe replay = %+v, %v", got, err)
+	}
+	answer, err := replay.CookingQA(ctx, "how long?", "toast")
+	if err != nil || answer != "answer to how long? via [REDACTED]" {
+		t.Errorf("CookingQA replay = %q, %v", answer, err)
+	}
+	transcript, err := replay.TranscribeAudio(ctx, []byte("audio bytes"), "webm")
+	if err != nil || transcript != "next step" {
+		t.Errorf("TranscribeAudio replay = %q, %v", transcript, err)
+	}
+	if _, err := replay.ExtractRecipesFromVideo(ctx, []byte("video"), "video/mp4", "", "metric", ""); !errors.Is(err, ErrVideoTooLarge) {
+		t.Errorf("video replay err = %v, want ErrVideoTooLarge", err)
+	}
+}
+
+func TestReplay_StrictAndLenientMisses(t *testing.T) {
+	c, err := LoadCassette(filepath.Join(t.TempDir(), "cassette.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := NewRecorder(c).WrapText(switchStub{name: "Recorded"}).GenerateRecipe(ctx, RecipeRequest{UserPrompt: "toast"}); err != nil {
+		t.Fatal(err)
+	}
+
+	strict := NewReplayProvider(c, true)
+	if _, err := strict.GenerateRecipe(ctx, RecipeRequest{UserPrompt: "soup"}); !errors.Is(err, ErrCassetteMiss) {
+		t.Errorf("strict miss err = %v, want ErrCassetteMiss", err)
+	}
+
+	lenient := NewReplayProvider(c, false)
+	got, err := lenient.GenerateRecipe(ctx, RecipeRequest{UserPrompt: "soup"})
+	if err != nil || got.Title != "Recorded" {
+		t.Errorf("lenient miss = %+v, %v; want the latest GenerateRecipe recording", got, err)
+	}
+	if _, err := lenient.CookingQA(ctx, "?", ""); !errors.Is(err, ErrCassetteMiss) {
+		t.Errorf("unrecorded operation err = %v, want ErrCassetteMiss", err)
+	}
+}
+
+func TestBuildLightProvider_Replay(t *testing.T) {
+	if _, err := BuildLightProvider(LightProviderSpec{Provider: "replay"}, LightKeys{}, nil, nil); err == nil {
+		t.Error("replay without a cassette should fail to build")
+	}
+	c, err := LoadCassette(filepath.Join(t.TempDir(), "cassette.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay := NewReplayProvider(c, false)
+	p, err := BuildLightProvider(LightProviderSpec{Provider: "replay"}, LightKeys{Replay: replay}, nil, nil)
+	if err != nil || p != TextProvider(replay) {
+		t.Errorf("BuildLightProvider(replay) = %v, %v", p, err)
+	}
+}
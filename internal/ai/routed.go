@@ -0,0 +1,203 @@
+package ai
+
+import (
+	"context"
+	"sync"
+)
+
+// TextOperations names every TextProvider operation, as used for
+// AIOperation.Name and as keys of an OperationRouter's routing table.
+var TextOperations = []string{
+	"GenerateRecipe",
+	"RegenerateRecipe",
+	"ForkRecipe",
+	"AnalyzeAllergens",
+	"ClassifyVoiceIntent",
+	"EstimatePortions",
+	"ExtractRecipeFromText",
+	"CookingQA",
+	"AnalyzeCookSteps",
+	"SuggestSubstitutions",
+	"DietaryInterview",
+	"ExpandAndRankRecipes",
+}
+
+// IsTextOperation reports whether op names a TextProvider operation.
+func IsTextOperation(op string) bool {
+	for _, o := range TextOperations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// ImageOperation is the ImageProvider's one operation. It is routed like a
+// text operation, but only to image models.
+const ImageOperation = "GenerateImage"
+
+// PromptOperations names every operation that renders prompt templates — the
+// keys config.Prompts.For resolves, and so the operations a prompt version
+// can be pinned to.
+var PromptOperations = []string{
+	"GenerateRecipe",
+	"RegenerateRecipe",
+	"ForkRecipe",
+	"AnalyzeAllergens",
+	"ClassifyVoiceIntent",
+	"ExtractRecipeFromText",
+	"CookingQA",
+	"DietaryInterview",
+	"ExtractRecipeFromImage",
+	"ExtractRecipesFromMedia",
+	"ExtractRecipesFromVideo",
+}
+
+// IsPromptOperation reports whether op renders prompt templates.
+func IsPromptOperation(op string) bool {
+	for _, o := range PromptOperations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// OperationRouter is a live per-operation routing table: operation name →
+// the provider that serves it. It backs the admin's per-operation model
+// routing; tiers wrap their default provider with Wrap so an operation with a
+// route goes to its own model and everything else stays on the tier. The table
+// is swapped atomically with Set.
+type OperationRouter struct {
+	mu     sync.RWMutex
+	routes map[string]TextProvider
+	image  ImageProvider
+}
+
+// NewOperationRouter creates a router with no routes.
+func NewOperationRouter() *OperationRouter {
+	return &OperationRouter{routes: map[string]TextProvider{}}
+}
+
+// Set atomically replaces the routing table. Nil providers are dropped.
+func (r *OperationRouter) Set(routes map[string]TextProvider) {
+	table := make(map[string]TextProvider, len(routes))
+	for op, p := range routes {
+		if p != nil {
+			table[op] = p
+		}
+	}
+	r.mu.Lock()
+	r.routes = table
+	r.mu.Unlock()
+}
+
+func (r *OperationRouter) route(op string) TextProvider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.routes[op]
+}
+
+// SetImage atomically replaces the ImageOperation route; nil clears it.
+func (r *OperationRouter) SetImage(p ImageProvider) {
+	r.mu.Lock()
+	r.image = p
+	r.mu.Unlock()
+}
+
+func (r *OperationRouter) imageRoute() ImageProvider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.image
+}
+
+// WrapImage returns an ImageProvider that generates with the ImageOperation
+// route when one is set and with def otherwise.
+func (r *OperationRouter) WrapImage(def ImageProvider) ImageProvider {
+	return &routedImageProvider{router: r, def: def}
+}
+
+type routedImageProvider struct {
+	router *OperationRouter
+	def    ImageProvider
+}
+
+func (p *routedImageProvider) GenerateImage(ctx context.Context, prompt string) ([]byte, error) {
+	if routed := p.router.imageRoute(); routed != nil {
+		return routed.GenerateImage(ctx, prompt)
+	}
+	return p.def.GenerateImage(ctx, prompt)
+}
+
+// Wrap returns a TextProvider that sends routed operations to their route and
+// everything else to def.
+func (r *OperationRouter) Wrap(def TextProvider) *RoutedTextProvider {
+	return &RoutedTextProvider{router: r, def: def}
+}
+
+// RoutedTextProvider is a tier's default provider behind an OperationRouter.
+type RoutedTextProvider struct {
+	router *OperationRouter
+	def    TextProvider
+}
+
+var _ TextProvider = (*RoutedTextProvider)(nil)
+
+func (p *RoutedTextProvider) pick(op string) TextProvider {
+	if routed := p.router.route(op); routed != nil {
+		return routed
+	}
+	return p.def
+}
+
+func (p *RoutedTextProvider) GenerateRecipe(ctx context.Context, req RecipeRequest) (*RecipeResult, error) {
+	return p.pick("GenerateRecipe").GenerateRecipe(ctx, req)
+}
+
+func (p *RoutedTextProvider) RegenerateRecipe(ctx context.Context, req RegenerateRequest) (*RecipeResult, error) {
+	return p.pick("RegenerateRecipe").RegenerateRecipe(ctx, req)
+}
+
+func (p *RoutedTextProvider) ForkRecipe(ctx context.Context, req ForkRequest) (*RecipeResult, error) {
+	return p.pick("ForkRecipe").ForkRecipe(ctx, req)
+}
+
+func (p *RoutedTextProvider) AnalyzeAllergens(ctx context.Context, req AllergenRequest) (*AllergenResult, error) {
+	return p.pick("AnalyzeAllergens").AnalyzeAllergens(ctx, req)
+}
+
+func (p *RoutedTextProvider) ClassifyVoiceIntent(ctx context.Context, transcript string) (*VoiceIntent, error) {
+	return p.pick("ClassifyVoiceIntent").ClassifyVoiceIntent(ctx, transcript)
+}
+
+func (p *RoutedTextProvider) EstimatePortions(ctx context.Context, recipeDef interface{}) (*PortionEstimate, error) {
+	return p.pick("EstimatePortions").EstimatePortions(ctx, recipeDef)
+}
+
+func (p *RoutedTextProvider) ExtractRecipeFromText(ctx context.Context, text string, unitSystem string) (*RecipeResult, error) {
+	return p.pick("ExtractRecipeFromText").ExtractRecipeFromText(ctx, text, unitSystem)
+}
+
+func (p *RoutedTextProvider) CookingQA(ctx context.Context, question string, recipeContext string) (string, error) {
+	return p.pick("CookingQA").CookingQA(ctx, question, recipeContext)
+}
+
+func (p *RoutedTextProvider) StreamCookingChat(ctx context.Context, req CookingChatRequest, onDelta func(string)) (string, error) {
+	return StreamCookingChat(ctx, p.pick("CookingQA"), req, onDelta)
+}
+
+func (p *RoutedTextProvider) AnalyzeCookSteps(ctx context.Context, req CookStepsRequest) ([]CookStepTiming, error) {
+	return AnalyzeCookSteps(ctx, p.pick("AnalyzeCookSteps"), req)
+}
+
+func (p *RoutedTextProvider) SuggestSubstitutions(ctx context.Context, req SubstitutionRequest) (*SubstitutionResult, error) {
+	return SuggestSubstitutions(ctx, p.pick("SuggestSubstitutions"), req)
+}
+
+func (p *RoutedTextProvider) DietaryInterview(ctx context.Context, messages []Message, memberName string) (*DietaryInterviewResult, error) {
+	return p.pick("DietaryInterview").DietaryInterview(ctx, messages, memberName)
+}
+
+func (p *RoutedTextProvider) ExpandAndRankRecipes(ctx context.Context, req FinderRankRequest) (*FinderRankResult, error) {
+	return p.pick("ExpandAndRankRecipes").ExpandAndRankRecipes(ctx, req)
+}
@@ -0,0 +1,94 @@
+package ai
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+)
+
+func TestRoutedTextProvider_RoutesPerOperation(t *testing.T) {
+	router := NewOperationRouter()
+	light := router.Wrap(switchStub{name: "light"})
+	main := router.Wrap(switchStub{name: "main"})
+	ctx := context.Background()
+
+	router.Set(map[string]TextProvider{"CookingQA": switchStub{name: "routed"}})
+
+	for name, p := range map[string]*RoutedTextProvider{"light": light, "main": main} {
+		if got, _ := p.CookingQA(ctx, "q", ""); got != "routed" {
+			t.Errorf("%s tier CookingQA served by %q, want routed", name, got)
+		}
+		got, _ := p.ExtractRecipeFromText(ctx, "x", "metric")
+		if got.Title != name {
+			t.Errorf("%s tier ExtractRecipeFromText served by %q, want the tier", name, got.Title)
+		}
+	}
+
+	router.Set(nil)
+	if got, _ := light.CookingQA(ctx, "q", ""); got != "light" {
+		t.Errorf("after clearing, CookingQA served by %q, want light", got)
+	}
+}
+
+type imageStub string
+
+func (s imageStub) GenerateImage(ctx context.Context, prompt string) ([]byte, error) {
+	return []byte(s), nil
+}
+
+func TestOperationRouter_RoutesImageGeneration(t *testing.T) {
+	router := NewOperationRouter()
+	p := router.WrapImage(imageStub("default"))
+	ctx := context.Background()
+
+	router.SetImage(imageStub("routed"))
+	if got, _ := p.GenerateImage(ctx, "toast"); string(got) != "routed" {
+		t.Errorf("GenerateImage served by %q, want routed", got)
+	}
+	router.SetImage(nil)
+	if got, _ := p.GenerateImage(ctx, "toast"); string(got) != "default" {
+		t.Errorf("after clearing, GenerateImage served by %q, want default", got)
+	}
+}
+
+// TestTextOperations_CoverRoutedOperations checks that every operation the
+// routed and experiment wrappers pick a provider for is listed in
+// TextOperations, so the admin can route it and run experiments on it.
+func TestTextOperations_CoverRoutedOperations(t *testing.T) {
+	fset := token.NewFileSet()
+	for _, file := range []string{"routed.go", "experiment.go"} {
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatalf("parse %s: %v", file, err)
+		}
+		picks := 0
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "pick" {
+				return true
+			}
+			for _, arg := range call.Args {
+				lit, ok := arg.(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				op, _ := strconv.Unquote(lit.Value)
+				picks++
+				if !IsTextOperation(op) {
+					t.Errorf("%s: pick(%q) is not in TextOperations", fset.Position(lit.Pos()), op)
+				}
+			}
+			return true
+		})
+		if picks == 0 {
+			t.Errorf("%s: found no pick calls", file)
+		}
+	}
+}
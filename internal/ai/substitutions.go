@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSubstitutionsUnsupported is returned by SuggestSubstitutions when the
+// provider cannot propose ingredient substitutions.
+var ErrSubstitutionsUnsupported = errors.New("provider does not suggest substitutions")
+
+// SubstitutionRequest asks for ingredient swaps that make a recipe fit the
+// given constraints.
+type SubstitutionRequest struct {
+	Title        string
+	Ingredients  []string // ingredient lines, in recipe order
+	Instructions []string
+	// Avoid lists what the dish must not contain, e.g. "peanuts (severe
+	// allergy)" or "no eggs".
+	Avoid []string
+	// AllergenNotes are known allergens per ingredient from a prior allergen
+	// analysis, e.g. "Butter: milk".
+	AllergenNotes []string
+	// Pantry is what the cook has on hand; replacements from it are preferred.
+	Pantry []string
+}
+
+// SubstitutionSuggestion replaces one ingredient. Ratio is how much of the
+// replacement to use per one unit of the original: in Unit when set, else in
+// the original ingredient's own unit.
+type SubstitutionSuggestion struct {
+	Ingredient  int // 1-based index into the request's Ingredients
+	Replacement string
+	Ratio       float64
+	Unit        string
+	Addresses   string // the constraint the swap satisfies
+	Effect      string // expected effect on taste, texture or method
+	FromPantry  bool
+}
+
+// SubstitutionResult is the proposed swaps and their overall effect on the
+// dish.
+type SubstitutionResult struct {
+	Suggestions []SubstitutionSuggestion
+	Effect      string
+}
+
+// Substituter is implemented by text providers that can propose ingredient
+// substitutions.
+type Substituter interface {
+	SuggestSubstitutions(ctx context.Context, req SubstitutionRequest) (*SubstitutionResult, error)
+}
+
+// Compile-time checks for the providers and wrappers that suggest
+// substitutions.
+var (
+	_ Substituter = (*AnthropicProvider)(nil)
+	_ Substituter = (*SwitchableTextProvider)(nil)
+	_ Substituter = (*RoutedTextProvider)(nil)
+	_ Substituter = (*ExperimentTextProvider)(nil)
+	_ Substituter = (*FallbackTextProvider)(nil)
+	_ Substituter = (*recordingText)(nil)
+	_ Substituter = (*ReplayProvider)(nil)
+)
+
+// SuggestSubstitutions proposes swaps for req with p, or returns
+// ErrSubstitutionsUnsupported when p does not implement Substituter.
+func SuggestSubstitutions(ctx context.Context, p TextProvider, req SubstitutionRequest) (*SubstitutionResult, error) {
+	s, ok := p.(Substituter)
+	if !ok {
+		return nil, ErrSubstitutionsUnsupported
+	}
+	return s.SuggestSubstitutions(ctx, req)
+}
@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+func TestExtractSubstitutionsFromToolUse(t *testing.T) {
+	input := `{"substitutions": [
+		{"ingredient": 2, "replacement": " coconut oil ", "ratio": 0.75, "unit": "", "addresses": "milk allergy", "effect": "Slightly less rich.", "from_pantry": true},
+		{"ingredient": 3, "replacement": "aquafaba", "ratio": 3, "unit": "tbsp", "addresses": "no eggs", "effect": "A little flatter.", "from_pantry": false},
+		{"ingredient": 9, "replacement": "ghost", "ratio": 1, "unit": "", "addresses": "", "effect": "", "from_pantry": false},
+		{"ingredient": 1, "replacement": "oat flour", "ratio": 0, "unit": "", "addresses": "", "effect": "", "from_pantry": false}
+	], "effect": "Dairy- and egg-free pancakes."}`
+	msg := &anthropic.Message{
+		Content: []anthropic.ContentBlockUnion{
+			{Type: "tool_use", Name: "suggest_substitutions", Input: json.RawMessage(input)},
+		},
+	}
+
+	result, err := extractSubstitutionsFromToolUse(msg, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Suggestions) != 2 {
+		t.Fatalf("suggestions = %+v, want the out-of-range and zero-ratio entries dropped", result.Suggestions)
+	}
+	if got := result.Suggestions[0]; got.Ingredient != 2 || got.Replacement != "coconut oil" || !got.FromPantry {
+		t.Errorf("first suggestion = %+v", got)
+	}
+	if got := result.Suggestions[1]; got.Ratio != 3 || got.Unit != "tbsp" {
+		t.Errorf("second suggestion = %+v", got)
+	}
+	if result.Effect != "Dairy- and egg-free pancakes." {
+		t.Errorf("effect = %q", result.Effect)
+	}
+}
+
+func TestSuggestSubstitutions_Unsupported(t *testing.T) {
+	_, err := SuggestSubstitutions(context.Background(), &stubCookingQAProvider{}, SubstitutionRequest{Ingredients: []string{"1 egg"}})
+	if !errors.Is(err, ErrSubstitutionsUnsupported) {
+		t.Errorf("error = %v, want ErrSubstitutionsUnsupported", err)
+	}
+}
+
+// substituterStub is a switchStub that also suggests substitutions.
+type substituterStub struct{ switchStub }
+
+func (s substituterStub) SuggestSubstitutions(context.Context, SubstitutionRequest) (*SubstitutionResult, error) {
+	return &SubstitutionResult{Effect: s.name}, nil
+}
+
+func TestSuggestSubstitutions_ThroughProductionStack(t *testing.T) {
+	// The main tier as the router builds it: a fallback chain whose first
+	// member cannot suggest substitutions, behind per-operation routing, an
+	// experiment router and the cassette recorder.
+	cassette, err := LoadCassette(filepath.Join(t.TempDir(), "cassette.json"))
+	if err != nil {
+		t.Fatalf("LoadCassette() error = %v", err)
+	}
+	chain := NewFallbackTextProvider(
+		FallbackMember{Name: "openai/gpt-4.1-mini", Provider: switchStub{name: "openai"}},
+		FallbackMember{Name: "anthropic/claude-sonnet-4-5", Provider: substituterStub{switchStub{name: "anthropic"}}},
+	)
+	stack := NewRecorder(cassette).WrapText(NewExperimentRouter().Wrap(NewOperationRouter().Wrap(chain)))
+
+	got, err := SuggestSubstitutions(context.Background(), stack, SubstitutionRequest{Ingredients: []string{"1 egg"}})
+	if err != nil {
+		t.Fatalf("SuggestSubstitutions() error = %v", err)
+	}
+	if got.Effect != "anthropic" {
+		t.Errorf("served by %q, want the chain member that suggests substitutions", got.Effect)
+	}
+	if h := chain.Chain()[0].Health; h.ConsecutiveFailures != 0 {
+		t.Errorf("unsupported member failures = %d; declining an operation is not a failure", h.ConsecutiveFailures)
+	}
+
+	// With no member able to, the caller still sees ErrSubstitutionsUnsupported.
+	chain.SetChain([]FallbackMember{{Name: "openai", Provider: switchStub{name: "openai"}}})
+	if _, err := SuggestSubstitutions(context.Background(), stack, SubstitutionRequest{}); !errors.Is(err, ErrSubstitutionsUnsupported) {
+		t.Errorf("error = %v, want ErrSubstitutionsUnsupported", err)
+	}
+}
@@ -73,6 +73,18 @@ func (s *SwitchableTextProvider) CookingQA(ctx context.Context, question string,
 	return s.get().CookingQA(ctx, question, recipeContext)
 }
 
+func (s *SwitchableTextProvider) StreamCookingChat(ctx context.Context, req CookingChatRequest, onDelta func(string)) (string, error) {
+	return StreamCookingChat(ctx, s.get(), req, onDelta)
+}
+
+func (s *SwitchableTextProvider) AnalyzeCookSteps(ctx context.Context, req CookStepsRequest) ([]CookStepTiming, error) {
+	return AnalyzeCookSteps(ctx, s.get(), req)
+}
+
+func (s *SwitchableTextProvider) SuggestSubstitutions(ctx context.Context, req SubstitutionRequest) (*SubstitutionResult, error) {
+	return SuggestSubstitutions(ctx, s.get(), req)
+}
+
 func (s *SwitchableTextProvider) DietaryInterview(ctx context.Context, messages []Message, memberName string) (*DietaryInterviewResult, error) {
 	return s.get().DietaryInterview(ctx, messages, memberName)
 }
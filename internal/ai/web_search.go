@@ -3,15 +3,14 @@ package ai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync/atomic"
 	"time"
-
-	"github.com/windoze95/saltybytes-api/internal/logger"
-	"go.uber.org/zap"
 )
 
 // exhaustionCooldown is how long to wait before retrying a provider after a
@@ -21,32 +20,80 @@ import (
 // API while still recovering within the same process lifetime.
 const exhaustionCooldown = 1 * time.Hour
 
-// WebSearchProvider implements SearchProvider using Brave Search as the
-// primary engine. Google Custom Search support is retained but disabled
-// (see SearchRecipes) because Google CSE no longer supports searching
-// the entire web — it requires a curated list of sites. DO NOT DELETE
-// the Google code; it can be re-enabled if a suitable CSE config is created.
-type WebSearchProvider struct {
-	googleAPIKey      string
-	googleCX          string
-	braveAPIKey       string
-	httpClient        *http.Client
-	googleExhaustedAt atomic.Int64 // Unix timestamp; 0 = not exhausted
-	braveExhaustedAt  atomic.Int64 // Unix timestamp; 0 = not exhausted
+// ErrSearchQuotaExhausted is returned (wrapped) by a web search backend whose
+// API reported an exhausted quota, or that is still inside its exhaustion
+// cooldown. Callers use it to trip the backend's breaker immediately instead of
+// counting it as an ordinary failure.
+var ErrSearchQuotaExhausted = errors.New("search quota exhausted")
+
+// Search backend kinds. Web kinds are built by BuildSearchBackend; the
+// canonical kind is our own CanonicalRecipe index and is supplied by the
+// service layer (it needs the DB).
+const (
+	SearchBackendBrave     = "brave"
+	SearchBackendGoogle    = "google"
+	SearchBackendBing      = "bing"
+	SearchBackendSearXNG   = "searxng"
+	SearchBackendCanonical = "canonical"
+)
+
+// SearchBackend is one web search engine in the search registry.
+type SearchBackend interface {
+	SearchProvider
+	// Kind returns the backend kind (brave|google|bing|searxng|canonical).
+	Kind() string
+}
+
+// SearchBackendSpec identifies a search backend: its kind plus an optional
+// endpoint override (required for SearXNG, which is self-hosted).
+type SearchBackendSpec struct {
+	Kind    string `json:"kind"`
+	BaseURL string `json:"base_url"`
 }
 
-// NewWebSearchProvider creates a search provider with Google primary + Brave fallback.
-func NewWebSearchProvider(googleAPIKey, googleCX, braveAPIKey string) *WebSearchProvider {
-	return &WebSearchProvider{
-		googleAPIKey: googleAPIKey,
-		googleCX:     googleCX,
-		braveAPIKey:  braveAPIKey,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+// SearchKeys carries the search API keys (sourced from env/SSM, never the DB).
+type SearchKeys struct {
+	GoogleAPIKey string
+	GoogleCX     string
+	BraveAPIKey  string
+	BingAPIKey   string
+}
+
+// BuildSearchBackend constructs the web search backend for a spec. Returns an
+// error when the backend's API key (or, for SearXNG, its base URL) is missing
+// or the kind is unknown, so a misconfigured registry entry fails loudly.
+func BuildSearchBackend(spec SearchBackendSpec, keys SearchKeys) (SearchBackend, error) {
+	switch spec.Kind {
+	case SearchBackendBrave:
+		if keys.BraveAPIKey == "" {
+			return nil, fmt.Errorf("search backend brave selected but BRAVE_SEARCH_KEY is not set")
+		}
+		return NewBraveSearchBackend(keys.BraveAPIKey, spec.BaseURL), nil
+	case SearchBackendGoogle:
+		if keys.GoogleAPIKey == "" || keys.GoogleCX == "" {
+			return nil, fmt.Errorf("search backend google selected but GOOGLE_SEARCH_KEY/GOOGLE_SEARCH_CX are not set")
+		}
+		return NewGoogleSearchBackend(keys.GoogleAPIKey, keys.GoogleCX, spec.BaseURL), nil
+	case SearchBackendBing:
+		if keys.BingAPIKey == "" {
+			return nil, fmt.Errorf("search backend bing selected but BING_SEARCH_KEY is not set")
+		}
+		return NewBingSearchBackend(keys.BingAPIKey, spec.BaseURL), nil
+	case SearchBackendSearXNG:
+		if spec.BaseURL == "" {
+			return nil, fmt.Errorf("search backend searxng requires a base_url")
+		}
+		return NewSearXNGSearchBackend(spec.BaseURL), nil
+	default:
+		return nil, fmt.Errorf("unknown search backend %q", spec.Kind)
 	}
 }
 
+// newSearchHTTPClient is the shared HTTP client shape for web search backends.
+func newSearchHTTPClient() *http.Client {
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
 // isExhausted returns true if the provider's cooldown period hasn't elapsed yet.
 func isExhausted(exhaustedAt *atomic.Int64) bool {
 	ts := exhaustedAt.Load()
@@ -61,38 +108,68 @@ func markExhausted(exhaustedAt *atomic.Int64) {
 	exhaustedAt.Store(time.Now().Unix())
 }
 
-// SearchRecipes tries Brave first. Google CSE is currently disabled because
-// Google no longer allows Custom Search Engines to search the entire web
-// (a curated site list is required). DO NOT DELETE the Google code — it can
-// be re-enabled once a suitable CSE configuration is set up.
-func (p *WebSearchProvider) SearchRecipes(ctx context.Context, query string, count int, offset int) ([]SearchResult, error) {
-	if count <= 0 {
-		count = 10
+// getSearchJSON performs a GET against a search API and returns the body.
+// 429/403 mark the backend exhausted and return ErrSearchQuotaExhausted.
+func getSearchJSON(ctx context.Context, client *http.Client, name, reqURL string, headers map[string]string, exhaustedAt *atomic.Int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", name, err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
 	}
 
-	// Try Brave (unless we recently hit a quota limit)
-	if !isExhausted(&p.braveExhaustedAt) && p.braveAPIKey != "" {
-		results, err := p.searchBrave(ctx, query, count, offset)
-		if err == nil {
-			return results, nil
-		}
-		logger.Get().Warn("brave search failed", zap.Error(err))
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s search request failed: %w", name, err)
 	}
+	defer resp.Body.Close()
 
-	// NOTE: Google CSE fallback is disabled. See struct comment for details.
-	// To re-enable, uncomment the block below:
-	//
-	// if !isExhausted(&p.googleExhaustedAt) && p.googleAPIKey != "" {
-	// 	return p.searchGoogle(ctx, query, count)
-	// }
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", name, err)
+	}
 
-	return nil, fmt.Errorf("no search providers available")
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusForbidden {
+		markExhausted(exhaustedAt)
+		return nil, fmt.Errorf("%s: %w (status %d)", name, ErrSearchQuotaExhausted, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s API returned status %d: %s", name, resp.StatusCode, string(body))
+	}
+	return body, nil
 }
 
 // --- Google Custom Search ---
 
+// Google CSE no longer supports searching the entire web — it requires a
+// curated list of sites — so it is not seeded as an enabled backend. DO NOT
+// DELETE this backend; it can be enabled from the admin API once a suitable
+// CSE config exists.
+
 const googleSearchEndpoint = "https://www.googleapis.com/customsearch/v1"
 
+// GoogleSearchBackend searches via Google Custom Search.
+type GoogleSearchBackend struct {
+	apiKey      string
+	cx          string
+	endpoint    string
+	httpClient  *http.Client
+	exhaustedAt atomic.Int64 // Unix timestamp; 0 = not exhausted
+}
+
+// NewGoogleSearchBackend creates a Google CSE backend. An empty endpoint uses
+// the public API.
+func NewGoogleSearchBackend(apiKey, cx, endpoint string) *GoogleSearchBackend {
+	if endpoint == "" {
+		endpoint = googleSearchEndpoint
+	}
+	return &GoogleSearchBackend{apiKey: apiKey, cx: cx, endpoint: endpoint, httpClient: newSearchHTTPClient()}
+}
+
+// Kind implements SearchBackend.
+func (p *GoogleSearchBackend) Kind() string { return SearchBackendGoogle }
+
 type googleSearchResponse struct {
 	Items []googleSearchItem `json:"items"`
 	Error *googleErrorBlock  `json:"error"`
@@ -123,45 +200,38 @@ type googleErrorBlock struct {
 	Message string `json:"message"`
 }
 
-func (p *WebSearchProvider) searchGoogle(ctx context.Context, query string, count int, offset int) ([]SearchResult, error) {
+// SearchRecipes implements SearchProvider.
+func (p *GoogleSearchBackend) SearchRecipes(ctx context.Context, query string, count int, offset int) ([]SearchResult, error) {
+	if isExhausted(&p.exhaustedAt) {
+		return nil, fmt.Errorf("google: %w (cooling down)", ErrSearchQuotaExhausted)
+	}
 	// Google CSE max is 10 per request
-	if count > 10 {
+	if count <= 0 || count > 10 {
 		count = 10
 	}
 
 	// Site filtering is handled by the Custom Search Engine config,
 	// so we just pass the raw query here.
 	params := url.Values{}
-	params.Set("key", p.googleAPIKey)
-	params.Set("cx", p.googleCX)
+	params.Set("key", p.apiKey)
+	params.Set("cx", p.cx)
 	params.Set("q", query)
 	params.Set("num", fmt.Sprintf("%d", count))
-
-	reqURL := fmt.Sprintf("%s?%s", googleSearchEndpoint, params.Encode())
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create google request: %w", err)
-	}
-
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("google search request failed: %w", err)
+	if offset > 0 {
+		// CSE's start is 1-based.
+		params.Set("start", fmt.Sprintf("%d", offset+1))
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read google response: %w", err)
+	loc := LocaleFromContext(ctx)
+	if cc := loc.CountryCode(); cc != "" {
+		params.Set("gl", strings.ToLower(cc))
 	}
-
-	// 429 = quota exhausted for today
-	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == 403 {
-		markExhausted(&p.googleExhaustedAt)
-		return nil, fmt.Errorf("google quota exhausted (status %d)", resp.StatusCode)
+	if lang := loc.LanguageCode(); lang != "" {
+		params.Set("hl", lang)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("google API returned status %d: %s", resp.StatusCode, string(body))
+	body, err := getSearchJSON(ctx, p.httpClient, "google", fmt.Sprintf("%s?%s", p.endpoint, params.Encode()), nil, &p.exhaustedAt)
+	if err != nil {
+		return nil, err
 	}
 
 	var gResp googleSearchResponse
@@ -171,7 +241,8 @@ func (p *WebSearchProvider) searchGoogle(ctx context.Context, query string, coun
 
 	if gResp.Error != nil {
 		if gResp.Error.Code == 429 || gResp.Error.Code == 403 {
-			markExhausted(&p.googleExhaustedAt)
+			markExhausted(&p.exhaustedAt)
+			return nil, fmt.Errorf("google API error %d: %w: %s", gResp.Error.Code, ErrSearchQuotaExhausted, gResp.Error.Message)
 		}
 		return nil, fmt.Errorf("google API error %d: %s", gResp.Error.Code, gResp.Error.Message)
 	}
@@ -196,6 +267,26 @@ func (p *WebSearchProvider) searchGoogle(ctx context.Context, query string, coun
 
 const braveSearchEndpoint = "https://api.search.brave.com/res/v1/web/search"
 
+// BraveSearchBackend searches via the Brave Search API.
+type BraveSearchBackend struct {
+	apiKey      string
+	endpoint    string
+	httpClient  *http.Client
+	exhaustedAt atomic.Int64 // Unix timestamp; 0 = not exhausted
+}
+
+// NewBraveSearchBackend creates a Brave backend. An empty endpoint uses the
+// public API.
+func NewBraveSearchBackend(apiKey, endpoint string) *BraveSearchBackend {
+	if endpoint == "" {
+		endpoint = braveSearchEndpoint
+	}
+	return &BraveSearchBackend{apiKey: apiKey, endpoint: endpoint, httpClient: newSearchHTTPClient()}
+}
+
+// Kind implements SearchBackend.
+func (p *BraveSearchBackend) Kind() string { return SearchBackendBrave }
+
 type braveSearchResponse struct {
 	Web *braveWebResults `json:"web"`
 }
@@ -215,7 +306,14 @@ type braveThumbnail struct {
 	Src string `json:"src"`
 }
 
-func (p *WebSearchProvider) searchBrave(ctx context.Context, query string, count int, offset int) ([]SearchResult, error) {
+// SearchRecipes implements SearchProvider.
+func (p *BraveSearchBackend) SearchRecipes(ctx context.Context, query string, count int, offset int) ([]SearchResult, error) {
+	if isExhausted(&p.exhaustedAt) {
+		return nil, fmt.Errorf("brave: %w (cooling down)", ErrSearchQuotaExhausted)
+	}
+	if count <= 0 {
+		count = 10
+	}
 	if count > 20 {
 		count = 20
 	}
@@ -226,33 +324,21 @@ func (p *WebSearchProvider) searchBrave(ctx context.Context, query string, count
 	if offset > 0 {
 		params.Set("offset", fmt.Sprintf("%d", offset))
 	}
-
-	reqURL := fmt.Sprintf("%s?%s", braveSearchEndpoint, params.Encode())
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create brave request: %w", err)
+	loc := LocaleFromContext(ctx)
+	if cc := loc.CountryCode(); cc != "" {
+		params.Set("country", cc)
 	}
-	req.Header.Set("X-Subscription-Token", p.braveAPIKey)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("brave search request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read brave response: %w", err)
+	if lang := loc.LanguageCode(); lang != "" {
+		params.Set("search_lang", lang)
 	}
 
-	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == 403 {
-		markExhausted(&p.braveExhaustedAt)
-		return nil, fmt.Errorf("brave quota exhausted (status %d)", resp.StatusCode)
+	headers := map[string]string{
+		"X-Subscription-Token": p.apiKey,
+		"Accept":               "application/json",
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("brave API returned status %d: %s", resp.StatusCode, string(body))
+	body, err := getSearchJSON(ctx, p.httpClient, "brave", fmt.Sprintf("%s?%s", p.endpoint, params.Encode()), headers, &p.exhaustedAt)
+	if err != nil {
+		return nil, err
 	}
 
 	var bResp braveSearchResponse
@@ -280,6 +366,180 @@ func (p *WebSearchProvider) searchBrave(ctx context.Context, query string, count
 	return results, nil
 }
 
+// --- Bing Web Search ---
+
+const bingSearchEndpoint = "https://api.bing.microsoft.com/v7.0/search"
+
+// BingSearchBackend searches via the Bing Web Search API.
+type BingSearchBackend struct {
+	apiKey      string
+	endpoint    string
+	httpClient  *http.Client
+	exhaustedAt atomic.Int64 // Unix timestamp; 0 = not exhausted
+}
+
+// NewBingSearchBackend creates a Bing backend. An empty endpoint uses the
+// public API.
+func NewBingSearchBackend(apiKey, endpoint string) *BingSearchBackend {
+	if endpoint == "" {
+		endpoint = bingSearchEndpoint
+	}
+	return &BingSearchBackend{apiKey: apiKey, endpoint: endpoint, httpClient: newSearchHTTPClient()}
+}
+
+// Kind implements SearchBackend.
+func (p *BingSearchBackend) Kind() string { return SearchBackendBing }
+
+type bingSearchResponse struct {
+	WebPages *bingWebPages `json:"webPages"`
+}
+
+type bingWebPages struct {
+	Value []bingWebPage `json:"value"`
+}
+
+type bingWebPage struct {
+	Name         string `json:"name"`
+	URL          string `json:"url"`
+	Snippet      string `json:"snippet"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+}
+
+// SearchRecipes implements SearchProvider.
+func (p *BingSearchBackend) SearchRecipes(ctx context.Context, query string, count int, offset int) ([]SearchResult, error) {
+	if isExhausted(&p.exhaustedAt) {
+		return nil, fmt.Errorf("bing: %w (cooling down)", ErrSearchQuotaExhausted)
+	}
+	if count <= 0 {
+		count = 10
+	}
+	if count > 50 {
+		count = 50
+	}
+
+	params := url.Values{}
+	params.Set("q", query+" recipe")
+	params.Set("count", fmt.Sprintf("%d", count))
+	params.Set("responseFilter", "Webpages")
+	if offset > 0 {
+		params.Set("offset", fmt.Sprintf("%d", offset))
+	}
+	// Bing wants a full market ("en-GB") in mkt; with only a country, cc.
+	loc := LocaleFromContext(ctx)
+	if loc.LanguageCode() != "" && loc.CountryCode() != "" {
+		params.Set("mkt", loc.Market())
+	} else if cc := loc.CountryCode(); cc != "" {
+		params.Set("cc", cc)
+	}
+
+	headers := map[string]string{"Ocp-Apim-Subscription-Key": p.apiKey}
+	body, err := getSearchJSON(ctx, p.httpClient, "bing", fmt.Sprintf("%s?%s", p.endpoint, params.Encode()), headers, &p.exhaustedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	var bResp bingSearchResponse
+	if err := json.Unmarshal(body, &bResp); err != nil {
+		return nil, fmt.Errorf("failed to parse bing response: %w", err)
+	}
+	if bResp.WebPages == nil {
+		return nil, nil
+	}
+
+	results := make([]SearchResult, 0, len(bResp.WebPages.Value))
+	for _, r := range bResp.WebPages.Value {
+		results = append(results, SearchResult{
+			Title:       r.Name,
+			URL:         r.URL,
+			Source:      extractDomain(r.URL),
+			ImageURL:    r.ThumbnailURL,
+			Description: r.Snippet,
+		})
+	}
+	return results, nil
+}
+
+// --- SearXNG (self-hosted metasearch) ---
+
+// SearXNGSearchBackend searches a self-hosted SearXNG instance via its JSON
+// API (the instance must have the json output format enabled).
+type SearXNGSearchBackend struct {
+	baseURL     string
+	httpClient  *http.Client
+	exhaustedAt atomic.Int64 // Unix timestamp; 0 = not exhausted
+}
+
+// NewSearXNGSearchBackend creates a SearXNG backend for the instance at baseURL.
+func NewSearXNGSearchBackend(baseURL string) *SearXNGSearchBackend {
+	return &SearXNGSearchBackend{baseURL: strings.TrimRight(baseURL, "/"), httpClient: newSearchHTTPClient()}
+}
+
+// Kind implements SearchBackend.
+func (p *SearXNGSearchBackend) Kind() string { return SearchBackendSearXNG }
+
+type searxngResponse struct {
+	Results []searxngResult `json:"results"`
+}
+
+type searxngResult struct {
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	Content   string `json:"content"`
+	Thumbnail string `json:"thumbnail"`
+	ImgSrc    string `json:"img_src"`
+}
+
+// SearchRecipes implements SearchProvider. SearXNG pages by page number, so
+// the offset is mapped onto the page that contains it.
+func (p *SearXNGSearchBackend) SearchRecipes(ctx context.Context, query string, count int, offset int) ([]SearchResult, error) {
+	if isExhausted(&p.exhaustedAt) {
+		return nil, fmt.Errorf("searxng: %w (cooling down)", ErrSearchQuotaExhausted)
+	}
+	if count <= 0 {
+		count = 10
+	}
+
+	params := url.Values{}
+	params.Set("q", query+" recipe")
+	params.Set("format", "json")
+	params.Set("categories", "general")
+	if offset > 0 {
+		params.Set("pageno", fmt.Sprintf("%d", offset/count+1))
+	}
+	if loc := LocaleFromContext(ctx); loc.LanguageCode() != "" {
+		params.Set("language", loc.Market())
+	}
+
+	body, err := getSearchJSON(ctx, p.httpClient, "searxng", fmt.Sprintf("%s/search?%s", p.baseURL, params.Encode()), map[string]string{"Accept": "application/json"}, &p.exhaustedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	var sResp searxngResponse
+	if err := json.Unmarshal(body, &sResp); err != nil {
+		return nil, fmt.Errorf("failed to parse searxng response: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(sResp.Results))
+	for _, r := range sResp.Results {
+		if len(results) >= count {
+			break
+		}
+		img := r.Thumbnail
+		if img == "" {
+			img = r.ImgSrc
+		}
+		results = append(results, SearchResult{
+			Title:       r.Title,
+			URL:         r.URL,
+			Source:      extractDomain(r.URL),
+			ImageURL:    img,
+			Description: r.Content,
+		})
+	}
+	return results, nil
+}
+
 // extractDomain pulls the hostname from a URL string.
 func extractDomain(rawURL string) string {
 	u, err := url.Parse(rawURL)
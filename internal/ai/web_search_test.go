@@ -0,0 +1,109 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBingSearchBackend_ParsesWebPages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Ocp-Apim-Subscription-Key") != "k" {
+			t.Errorf("missing subscription key header")
+		}
+		if got := r.URL.Query().Get("q"); got != "pad thai recipe" {
+			t.Errorf("q = %q", got)
+		}
+		if got := r.URL.Query().Get("mkt"); got != "en-GB" {
+			t.Errorf("mkt = %q, want en-GB", got)
+		}
+		w.Write([]byte(`{"webPages":{"value":[{"name":"Pad Thai","url":"https://example.com/pad-thai","snippet":"Classic","thumbnailUrl":"https://img/x.jpg"}]}}`))
+	}))
+	defer srv.Close()
+
+	ctx := WithLocale(context.Background(), Locale{Language: "en", Region: "gb"})
+	results, err := NewBingSearchBackend("k", srv.URL).SearchRecipes(ctx, "pad thai", 5, 0)
+	if err != nil {
+		t.Fatalf("SearchRecipes: %v", err)
+	}
+	if len(results) != 1 || results[0].Source != "example.com" || results[0].ImageURL != "https://img/x.jpg" {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestSearXNGSearchBackend_PagesAndCaps(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" || r.URL.Query().Get("format") != "json" {
+			t.Errorf("unexpected request %s", r.URL.String())
+		}
+		if got := r.URL.Query().Get("pageno"); got != "3" {
+			t.Errorf("pageno = %q, want 3", got)
+		}
+		w.Write([]byte(`{"results":[{"title":"A","url":"https://a.com/1"},{"title":"B","url":"https://b.com/2","img_src":"https://b.com/i.jpg"},{"title":"C","url":"https://c.com/3"}]}`))
+	}))
+	defer srv.Close()
+
+	results, err := NewSearXNGSearchBackend(srv.URL+"/").SearchRecipes(context.Background(), "soup", 2, 4)
+	if err != nil {
+		t.Fatalf("SearchRecipes: %v", err)
+	}
+	if len(results) != 2 || results[1].ImageURL != "https://b.com/i.jpg" {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestBraveSearchBackend_QuotaExhausted(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	b := NewBraveSearchBackend("k", srv.URL)
+	if _, err := b.SearchRecipes(context.Background(), "x", 5, 0); !errors.Is(err, ErrSearchQuotaExhausted) {
+		t.Fatalf("err = %v, want ErrSearchQuotaExhausted", err)
+	}
+	// Cooling down: the second call must not hit the API.
+	if _, err := b.SearchRecipes(context.Background(), "x", 5, 0); !errors.Is(err, ErrSearchQuotaExhausted) {
+		t.Fatalf("err = %v, want ErrSearchQuotaExhausted", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestBuildSearchBackend(t *testing.T) {
+	keys := SearchKeys{BraveAPIKey: "b"}
+	if b, err := BuildSearchBackend(SearchBackendSpec{Kind: SearchBackendBrave}, keys); err != nil || b.Kind() != SearchBackendBrave {
+		t.Errorf("brave: %v, %v", b, err)
+	}
+	if _, err := BuildSearchBackend(SearchBackendSpec{Kind: SearchBackendBing}, keys); err == nil {
+		t.Error("bing without a key should fail to build")
+	}
+	if _, err := BuildSearchBackend(SearchBackendSpec{Kind: SearchBackendSearXNG}, keys); err == nil {
+		t.Error("searxng without a base URL should fail to build")
+	}
+	if _, err := BuildSearchBackend(SearchBackendSpec{Kind: "altavista"}, keys); err == nil {
+		t.Error("unknown kind should fail to build")
+	}
+}
+
+func TestLocale_Derivations(t *testing.T) {
+	l := Locale{Language: "en-GB"}
+	if l.LanguageCode() != "en" || l.CountryCode() != "GB" || l.Market() != "en-GB" || l.CacheKey() != "en-gb" {
+		t.Errorf("en-GB derivations: %q %q %q %q", l.LanguageCode(), l.CountryCode(), l.Market(), l.CacheKey())
+	}
+	if got := ParseLocaleKey(l.CacheKey()).Market(); got != "en-GB" {
+		t.Errorf("round trip = %q", got)
+	}
+	// An explicit Region wins over the tag's region subtag.
+	if got := (Locale{Language: "en-US", Region: "ca"}).Market(); got != "en-CA" {
+		t.Errorf("region override = %q", got)
+	}
+	if got := LocaleFromContext(WithLocale(context.Background(), Locale{})); !got.IsZero() {
+		t.Errorf("zero locale should not be attached: %+v", got)
+	}
+}
@@ -0,0 +1,208 @@
+package allergens
+
+import "strings"
+
+// Classification is the lexicon's verdict on one ingredient: the allergens
+// it contains, those it may contain (cross-contact or common formulations),
+// and whether it is a seed oil.
+type Classification struct {
+	Entry      string   // the lexicon name matched, e.g. "all-purpose flour"
+	Contains   []string // taxonomy IDs
+	MayContain []string // taxonomy IDs
+	SeedOil    bool
+}
+
+// lexiconEntry is a group of well-known ingredients that carry the same
+// allergens.
+type lexiconEntry struct {
+	names      []string
+	contains   []string
+	mayContain []string
+	seedOil    bool
+}
+
+// lexicon lists ingredients whose allergens are settled enough to classify
+// without the model. Anything branded, blended or regionally variable
+// (chocolate chips, curry paste, stock cubes) stays out so the model sees it.
+var lexicon = []lexiconEntry{
+	// Free of every taxonomy allergen.
+	{names: []string{"sugar", "white sugar", "caster sugar", "superfine sugar", "cane sugar"}},
+	{names: []string{"brown sugar"}},
+	{names: []string{"powdered sugar", "icing sugar", "confectioners sugar"}},
+	{names: []string{"salt", "table salt", "sea salt", "flaky salt"}},
+	{names: []string{"black pepper", "pepper", "peppercorn", "white pepper"}},
+	{names: []string{"water", "ice", "ice water"}},
+	{names: []string{"honey"}},
+	{names: []string{"maple syrup"}},
+	{names: []string{"baking soda", "bicarbonate of soda"}},
+	{names: []string{"yeast", "active dry yeast", "instant yeast"}},
+	{names: []string{"vanilla extract", "vanilla", "vanilla bean"}},
+	{names: []string{"cornstarch", "cornflour", "corn starch"}},
+	{names: []string{"cocoa powder", "unsweetened cocoa powder"}},
+	{names: []string{"rice", "white rice", "brown rice", "basmati rice", "jasmine rice", "arborio rice"}},
+	{names: []string{"olive oil", "extra-virgin olive oil", "virgin olive oil"}},
+	{names: []string{"coconut oil"}},
+	{names: []string{"avocado oil"}},
+	{names: []string{"coconut milk"}},
+	{names: []string{"vinegar", "white vinegar", "apple cider vinegar", "rice vinegar", "balsamic vinegar"}, mayContain: []string{Sulphites}},
+	{names: []string{"garlic", "garlic clove", "clove garlic", "clove of garlic"}},
+	{names: []string{"onion", "yellow onion", "red onion", "white onion", "green onion", "scallion", "shallot", "leek"}},
+	{names: []string{"tomato", "cherry tomato", "tomato paste", "canned tomato", "crushed tomato"}},
+	{names: []string{"potato", "sweet potato"}},
+	{names: []string{"carrot"}},
+	{names: []string{"bell pepper", "red bell pepper", "green bell pepper", "jalapeno", "chili pepper"}},
+	{names: []string{"zucchini", "courgette", "cucumber", "eggplant", "aubergine"}},
+	{names: []string{"spinach", "kale", "lettuce", "cabbage", "arugula"}},
+	{names: []string{"broccoli", "cauliflower"}},
+	{names: []string{"mushroom"}},
+	{names: []string{"avocado"}},
+	{names: []string{"lemon", "lemon juice", "lemon zest", "lime", "lime juice", "lime zest", "orange", "orange juice"}},
+	{names: []string{"apple", "banana", "blueberry", "strawberry", "raspberry"}},
+	{names: []string{"chickpea", "black bean", "kidney bean", "lentil"}},
+	{names: []string{"chicken", "chicken breast", "chicken thigh", "whole chicken"}},
+	{names: []string{"beef", "steak", "beef chuck"}},
+	{names: []string{"pork", "pork shoulder", "pork chop"}},
+	{names: []string{"lamb"}},
+	{names: []string{
+		"cinnamon", "cumin", "paprika", "smoked paprika", "chili powder", "cayenne", "cayenne pepper",
+		"turmeric", "nutmeg", "oregano", "basil", "parsley", "cilantro", "coriander", "thyme",
+		"rosemary", "sage", "dill", "mint", "bay leaf", "ginger", "clove", "cardamom",
+		"garlic powder", "onion powder", "red pepper flake",
+	}},
+
+	// Seed oils.
+	{names: []string{"vegetable oil"}, mayContain: []string{Soy}, seedOil: true},
+	{names: []string{"canola oil", "rapeseed oil"}, seedOil: true},
+	{names: []string{"sunflower oil"}, seedOil: true},
+	{names: []string{"corn oil"}, seedOil: true},
+	{names: []string{"grapeseed oil"}, seedOil: true},
+	{names: []string{"safflower oil"}, seedOil: true},
+	{names: []string{"soybean oil"}, contains: []string{Soy}, seedOil: true},
+
+	// Milk.
+	{names: []string{"milk", "cow milk", "skim milk", "low-fat milk", "2% milk"}, contains: []string{Milk}},
+	{names: []string{"butter", "ghee", "clarified butter"}, contains: []string{Milk}},
+	{names: []string{"heavy cream", "whipping cream", "heavy whipping cream", "double cream", "single cream", "half-and-half", "cream"}, contains: []string{Milk}},
+	{names: []string{"sour cream", "creme fraiche", "buttermilk", "yogurt", "greek yogurt", "yoghurt"}, contains: []string{Milk}},
+	{names: []string{"cream cheese", "ricotta", "mascarpone", "cottage cheese"}, contains: []string{Milk}},
+	{names: []string{"parmesan", "parmesan cheese", "cheddar", "cheddar cheese", "mozzarella", "mozzarella cheese", "feta", "feta cheese", "gruyere"}, contains: []string{Milk}},
+
+	// Egg.
+	{names: []string{"egg", "egg yolk", "egg white", "whole egg"}, contains: []string{Egg}},
+	{names: []string{"mayonnaise", "mayo"}, contains: []string{Egg}, mayContain: []string{Mustard}},
+
+	// Gluten cereals.
+	{names: []string{"flour", "all-purpose flour", "plain flour", "bread flour", "cake flour", "wheat flour", "self-rising flour", "self-raising flour"}, contains: []string{Wheat}},
+	{names: []string{"breadcrumb", "panko", "panko breadcrumb"}, contains: []string{Wheat}},
+	{names: []string{"pasta", "spaghetti", "penne", "fettuccine", "linguine", "macaroni", "lasagna noodle"}, contains: []string{Wheat}},
+	{names: []string{"couscous", "bulgur", "semolina"}, contains: []string{Wheat}},
+	{names: []string{"rolled oat", "oat", "old-fashioned oat", "quick oat"}, contains: []string{Oats}, mayContain: []string{Wheat}},
+	{names: []string{"barley", "pearl barley"}, contains: []string{Barley}},
+
+	// Soy.
+	{names: []string{"soy sauce", "shoyu"}, contains: []string{Soy, Wheat}},
+	{names: []string{"tamari"}, contains: []string{Soy}, mayContain: []string{Wheat}},
+	{names: []string{"tofu", "firm tofu", "silken tofu", "tempeh", "edamame"}, contains: []string{Soy}},
+	{names: []string{"miso", "white miso", "miso paste"}, contains: []string{Soy}, mayContain: []string{Barley}},
+
+	// Nuts and peanuts.
+	{names: []string{"peanut", "peanut butter"}, contains: []string{Peanut}},
+	{names: []string{"almond", "almond flour"}, contains: []string{Almond}},
+	{names: []string{"walnut"}, contains: []string{Walnut}},
+	{names: []string{"pecan"}, contains: []string{Pecan}},
+	{names: []string{"cashew"}, contains: []string{Cashew}},
+	{names: []string{"pistachio"}, contains: []string{Pistachio}},
+	{names: []string{"hazelnut"}, contains: []string{Hazelnut}},
+	{names: []string{"pine nut"}, contains: []string{PineNut}},
+
+	// Fish and shellfish.
+	{names: []string{"salmon", "salmon fillet", "tuna", "cod", "tilapia", "halibut", "anchovy", "sardine"}, contains: []string{Fish}},
+	{names: []string{"fish sauce"}, contains: []string{Fish}},
+	{names: []string{"worcestershire sauce", "worcestershire"}, contains: []string{Fish}},
+	{names: []string{"shrimp", "prawn", "crab", "lobster"}, contains: []string{Crustacean}},
+	{names: []string{"mussel", "clam", "scallop", "squid", "oyster"}, contains: []string{Mollusc}},
+	{names: []string{"oyster sauce"}, contains: []string{Mollusc}, mayContain: []string{Wheat, Soy}},
+
+	// Sesame, mustard, celery.
+	{names: []string{"sesame seed", "tahini", "sesame oil", "toasted sesame oil"}, contains: []string{Sesame}},
+	{names: []string{"mustard", "dijon mustard", "whole grain mustard", "mustard seed", "mustard powder", "dry mustard"}, contains: []string{Mustard}},
+	{names: []string{"celery", "celery stalk", "celery rib", "celery seed", "celeriac"}, contains: []string{Celery}},
+
+	// Sulphites.
+	{names: []string{"white wine", "red wine", "dry white wine", "dry red wine"}, contains: []string{Sulphites}},
+}
+
+// descriptors are words that describe an ingredient's size, cut, state or
+// amount without changing what it is made of. Classify ignores them.
+// Hyphenated descriptors are listed by their parts ("low-sodium" is "low"
+// and "sodium"), since normalize splits words on hyphens.
+var descriptors = map[string]bool{
+	"large": true, "medium": true, "small": true, "extra": true, "jumbo": true,
+	"fresh": true, "freshly": true, "frozen": true, "organic": true, "raw": true, "dried": true,
+	"unsalted": true, "salted": true, "granulated": true, "kosher": true, "fine": true, "finely": true,
+	"coarse": true, "coarsely": true, "whole": true, "ground": true, "light": true, "dark": true,
+	"chopped": true, "minced": true, "diced": true, "sliced": true, "grated": true, "shredded": true,
+	"crushed": true, "beaten": true, "softened": true, "melted": true, "cold": true, "warm": true,
+	"room": true, "temperature": true, "at": true, "peeled": true, "cubed": true, "packed": true,
+	"lightly": true, "sifted": true, "divided": true, "toasted": true, "roughly": true, "thinly": true,
+	"boneless": true, "skinless": true, "low": true, "reduced": true, "sodium": true, "fat": true, "optional": true,
+	"plus": true, "more": true, "for": true, "to": true, "taste": true, "serving": true, "garnish": true,
+	"about": true, "of": true,
+}
+
+// lexiconName is one lexicon name and the entry it belongs to.
+type lexiconName struct {
+	name  string
+	entry *lexiconEntry
+}
+
+// lexiconIndex maps each normalized lexicon name to its entry.
+var lexiconIndex = func() map[string]lexiconName {
+	m := map[string]lexiconName{}
+	for i := range lexicon {
+		for _, name := range lexicon[i].names {
+			m[strings.TrimSpace(normalize(name))] = lexiconName{name: name, entry: &lexicon[i]}
+		}
+	}
+	return m
+}()
+
+// Classify looks an ingredient up in the lexicon of well-known ingredients,
+// ignoring descriptors and amounts ("2 large eggs, beaten" is egg). It
+// reports false when the ingredient is not in the lexicon, in which case
+// only the model can classify it.
+func Classify(ingredient string) (Classification, bool) {
+	match, ok := lookupIngredient(ingredient)
+	if !ok {
+		return Classification{}, false
+	}
+	entry := match.entry
+	return Classification{
+		Entry:      match.name,
+		Contains:   append([]string{}, entry.contains...),
+		MayContain: append([]string{}, entry.mayContain...),
+		SeedOil:    entry.seedOil,
+	}, true
+}
+
+// lookupIngredient finds an ingredient's lexicon name: by its full
+// normalized name first, so names such as "toasted sesame oil" win, then
+// with descriptors and numbers removed.
+func lookupIngredient(ingredient string) (lexiconName, bool) {
+	text := strings.TrimSpace(normalize(ingredient))
+	if text == "" {
+		return lexiconName{}, false
+	}
+	if match, ok := lexiconIndex[text]; ok {
+		return match, true
+	}
+	var words []string
+	for _, w := range strings.Fields(text) {
+		if descriptors[w] || strings.Trim(w, "0123456789") == "" {
+			continue
+		}
+		words = append(words, w)
+	}
+	match, ok := lexiconIndex[strings.Join(words, " ")]
+	return match, ok
+}
@@ -0,0 +1,81 @@
+package allergens
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		ingredient string
+		entry      string
+		contains   []string
+		mayContain []string
+		seedOil    bool
+	}{
+		{"Butter", "butter", []string{Milk}, nil, false},
+		{"unsalted butter, softened", "butter", []string{Milk}, nil, false},
+		{"2 Large Eggs", "egg", []string{Egg}, nil, false},
+		{"All-purpose flour", "all-purpose flour", []string{Wheat}, nil, false},
+		{"Granulated sugar", "sugar", nil, nil, false},
+		{"Kosher salt, to taste", "salt", nil, nil, false},
+		{"3 cloves of garlic, minced", "clove garlic", nil, nil, false},
+		{"toasted sesame oil", "toasted sesame oil", []string{Sesame}, nil, false},
+		{"low-sodium soy sauce", "soy sauce", []string{Soy, Wheat}, nil, false},
+		{"reduced-sodium soy sauce", "soy sauce", []string{Soy, Wheat}, nil, false},
+		{"Low-Fat Milk", "low-fat milk", []string{Milk}, nil, false},
+		{"low fat milk", "low-fat milk", []string{Milk}, nil, false},
+		{"extra-large eggs", "egg", []string{Egg}, nil, false},
+		{"Extra-virgin olive oil", "extra-virgin olive oil", nil, nil, false},
+		{"extra virgin olive oil", "extra-virgin olive oil", nil, nil, false},
+		{"half-and-half", "half-and-half", []string{Milk}, nil, false},
+		{"Vegetable oil", "vegetable oil", nil, []string{Soy}, true},
+		{"rolled oats", "rolled oat", []string{Oats}, []string{Wheat}, false},
+		{"ground cumin", "cumin", nil, nil, false},
+	}
+	for _, tt := range tests {
+		c, ok := Classify(tt.ingredient)
+		if !ok {
+			t.Errorf("Classify(%q) not classified", tt.ingredient)
+			continue
+		}
+		if c.Entry != tt.entry || !sameIDs(c.Contains, tt.contains) || !sameIDs(c.MayContain, tt.mayContain) || c.SeedOil != tt.seedOil {
+			t.Errorf("Classify(%q) = %+v", tt.ingredient, c)
+		}
+	}
+
+	// Blends, brands and anything not in the lexicon are left to the model.
+	for _, ingredient := range []string{"chocolate chips", "red curry paste", "almond milk", "peanut oil", "salt and pepper", "dairy-free milk", "gluten-free flour", ""} {
+		if c, ok := Classify(ingredient); ok {
+			t.Errorf("Classify(%q) = %+v, want unclassified", ingredient, c)
+		}
+	}
+}
+
+// sameIDs compares allergen ID lists, treating nil and empty as equal.
+func sameIDs(a, b []string) bool {
+	return len(a) == 0 && len(b) == 0 || slices.Equal(a, b)
+}
+
+func TestLexiconIsConsistent(t *testing.T) {
+	seen := map[string]bool{}
+	for _, entry := range lexicon {
+		for _, id := range append(slices.Clone(entry.contains), entry.mayContain...) {
+			if _, ok := Lookup(id); !ok {
+				t.Errorf("lexicon entry %q names unknown allergen %q", entry.names[0], id)
+			}
+		}
+		for _, name := range entry.names {
+			if seen[name] {
+				t.Errorf("lexicon name %q listed twice", name)
+			}
+			seen[name] = true
+			// A lexicon name must not name an allergen the entry omits.
+			for _, id := range Match(name) {
+				if !slices.ContainsFunc(entry.contains, func(c string) bool { return IsA(c, id) || IsA(id, c) }) {
+					t.Errorf("lexicon name %q matches %q, which its entry does not contain", name, id)
+				}
+			}
+		}
+	}
+}
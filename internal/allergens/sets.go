@@ -0,0 +1,112 @@
+package allergens
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownSet is returned by NormalizeSet for an ID that names no set.
+var ErrUnknownSet = errors.New("unknown allergen set")
+
+// Regulatory allergen set IDs.
+const (
+	SetUSTop9  = "us_top9" // US FALCPA major food allergens, with sesame (FASTER Act)
+	SetEU14    = "eu_14"   // EU Regulation 1169/2011, Annex II
+	SetCanada  = "canada"  // Health Canada priority food allergens
+	SetANZ     = "anz"     // Food Standards Australia New Zealand, Schedule 9
+	DefaultSet = SetUSTop9
+)
+
+// Set is a regulatory allergen set: the allergens a jurisdiction requires
+// food labels to declare.
+type Set struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Allergens []string `json:"allergens"`
+}
+
+// sets holds every regulatory set, DefaultSet first.
+var sets = []Set{
+	{ID: SetUSTop9, Name: "US Top 9", Allergens: []string{
+		Milk, Egg, Fish, Crustacean, TreeNut, Peanut, Wheat, Soy, Sesame,
+	}},
+	{ID: SetEU14, Name: "EU 14", Allergens: []string{
+		Gluten, Crustacean, Egg, Fish, Peanut, Soy, Milk, TreeNut, Celery, Mustard, Sesame, Sulphites, Lupin, Mollusc,
+	}},
+	{ID: SetCanada, Name: "Canada", Allergens: []string{
+		Egg, Milk, Mustard, Peanut, Crustacean, Mollusc, Fish, Sesame, Soy, Sulphites, TreeNut, Wheat, Gluten,
+	}},
+	{ID: SetANZ, Name: "Australia/NZ", Allergens: []string{
+		Wheat, Gluten, Fish, Crustacean, Mollusc, Egg, Milk, Lupin, Peanut, Soy, Sesame, TreeNut, Sulphites,
+	}},
+}
+
+// euRegions are the countries that label to the EU 14: the EU, the EEA,
+// Switzerland and the UK.
+var euRegions = map[string]bool{
+	"AT": true, "BE": true, "BG": true, "HR": true, "CY": true, "CZ": true, "DK": true, "EE": true,
+	"FI": true, "FR": true, "DE": true, "GR": true, "HU": true, "IE": true, "IT": true, "LV": true,
+	"LT": true, "LU": true, "MT": true, "NL": true, "PL": true, "PT": true, "RO": true, "SK": true,
+	"SI": true, "ES": true, "SE": true, "IS": true, "LI": true, "NO": true, "CH": true, "GB": true,
+}
+
+// Sets returns every regulatory allergen set.
+func Sets() []Set {
+	return append([]Set(nil), sets...)
+}
+
+// LookupSet returns the set with the given ID.
+func LookupSet(id string) (Set, bool) {
+	for _, s := range sets {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return Set{}, false
+}
+
+// NormalizeSet validates a set ID and returns it lowercased. An empty ID is
+// valid and means "derive from the user's region".
+func NormalizeSet(id string) (string, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if id == "" {
+		return "", nil
+	}
+	if _, ok := LookupSet(id); !ok {
+		return "", fmt.Errorf("%w %q; use 'us_top9', 'eu_14', 'canada' or 'anz'", ErrUnknownSet, id)
+	}
+	return id, nil
+}
+
+// SetForRegion returns the set a country labels to, or DefaultSet when the
+// region is unset or has no mapping.
+func SetForRegion(region string) string {
+	region = strings.ToUpper(strings.TrimSpace(region))
+	switch {
+	case euRegions[region]:
+		return SetEU14
+	case region == "CA":
+		return SetCanada
+	case region == "AU", region == "NZ":
+		return SetANZ
+	}
+	return DefaultSet
+}
+
+// InSet reports whether the set declares allergen id, directly or through
+// an ancestor: almond is in every set because tree nuts are. A broader
+// allergen counts when the set declares one of its children, so unspecified
+// shellfish is in the US Top 9 through crustaceans.
+func InSet(set, id string) bool {
+	s, ok := LookupSet(set)
+	if !ok {
+		return false
+	}
+	for _, declared := range s.Allergens {
+		if IsA(id, declared) || IsA(declared, id) {
+			return true
+		}
+	}
+	return false
+}
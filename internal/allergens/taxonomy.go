@@ -0,0 +1,330 @@
+// Package allergens is SaltyBytes' allergen taxonomy: the canonical
+// allergens, the words that name them (synonyms and derivatives such as
+// whey for milk or tahini for sesame), how they nest (almond is a tree nut,
+// wheat is a gluten cereal), and the regulatory allergen sets users can pick
+// to be warned about (US Top 9, EU 14, Canada, Australia/NZ).
+//
+// Matching is WORD-BASED: a term names an allergen when one of its words
+// or phrases appears as whole words, so "nutmeg" and "butternut squash" are
+// not nuts. Each allergen also lists look-alike phrases that do not contain
+// it ("peanut butter" is not milk, "coconut milk" is not milk).
+//
+// Bump Version whenever the taxonomy changes, so stored analyses derived
+// from an older one are re-derived.
+package allergens
+
+import (
+	"sort"
+	"strings"
+)
+
+// Version identifies the taxonomy an analysis was derived with.
+const Version = "2026.2"
+
+// Canonical allergen IDs.
+const (
+	Milk       = "milk"
+	Egg        = "egg"
+	Fish       = "fish"
+	Shellfish  = "shellfish"
+	Crustacean = "crustacean"
+	Mollusc    = "mollusc"
+	TreeNut    = "tree_nut"
+	Almond     = "almond"
+	BrazilNut  = "brazil_nut"
+	Cashew     = "cashew"
+	Hazelnut   = "hazelnut"
+	Macadamia  = "macadamia"
+	Pecan      = "pecan"
+	PineNut    = "pine_nut"
+	Pistachio  = "pistachio"
+	Walnut     = "walnut"
+	Peanut     = "peanut"
+	Gluten     = "gluten"
+	Wheat      = "wheat"
+	Barley     = "barley"
+	Rye        = "rye"
+	Oats       = "oats"
+	Soy        = "soy"
+	Sesame     = "sesame"
+	Mustard    = "mustard"
+	Celery     = "celery"
+	Lupin      = "lupin"
+	Sulphites  = "sulphites"
+)
+
+// Allergen is one node of the taxonomy. Terms are the words and phrases
+// that name it or an ingredient derived from it; NotTerms are look-alike
+// phrases that do not contain it. FreeTerms declare a whole term free of it:
+// "dairy-free milk" names no milk at all.
+type Allergen struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Parent    string   `json:"parent,omitempty"`
+	Terms     []string `json:"-"`
+	NotTerms  []string `json:"-"`
+	FreeTerms []string `json:"-"`
+}
+
+// taxonomy holds every allergen, parents before children.
+var taxonomy = []Allergen{
+	{ID: Milk, Name: "Milk", Terms: []string{
+		"milk", "dairy", "butter", "buttermilk", "cheese", "cream", "whey", "casein", "caseinate",
+		"lactose", "lactalbumin", "lactoglobulin", "ghee", "yogurt", "yoghurt", "kefir", "curd",
+		"custard", "paneer", "ricotta", "mozzarella", "parmesan", "cheddar", "mascarpone",
+	}, NotTerms: []string{
+		"peanut butter", "nut butter", "almond butter", "cashew butter", "seed butter", "sunflower butter",
+		"cocoa butter", "cacao butter", "shea butter", "apple butter", "coconut milk", "coconut cream",
+		"almond milk", "oat milk", "soy milk", "soya milk", "rice milk", "cashew milk", "hemp milk",
+		"cream of tartar", "milk thistle", "non dairy",
+	}, FreeTerms: []string{"dairy free", "milk free"}},
+	{ID: Egg, Name: "Egg", Terms: []string{
+		"egg", "albumen", "albumin", "ovalbumin", "lysozyme", "mayonnaise", "mayo", "meringue",
+	}, FreeTerms: []string{"egg free"}},
+	{ID: Fish, Name: "Fish", Terms: []string{
+		"fish", "anchovy", "anchovies", "cod", "salmon", "tuna", "tilapia", "halibut", "trout",
+		"sardine", "mackerel", "haddock", "bass", "snapper", "catfish", "pollock", "swordfish",
+		"fish sauce", "worcestershire", "bonito", "dashi", "caviar", "roe",
+	}},
+	{ID: Shellfish, Name: "Shellfish", Terms: []string{"shellfish", "seafood"}},
+	{ID: Crustacean, Name: "Crustacean shellfish", Parent: Shellfish, Terms: []string{
+		"crustacean", "crustacea", "shrimp", "prawn", "crab", "lobster", "crayfish", "crawfish",
+		"langoustine", "scampi", "krill",
+	}},
+	{ID: Mollusc, Name: "Molluscs", Parent: Shellfish, Terms: []string{
+		"mollusc", "mollusk", "clam", "mussel", "oyster", "scallop", "squid", "calamari", "octopus",
+		"snail", "escargot", "cockle", "abalone", "whelk", "oyster sauce",
+	}},
+	{ID: TreeNut, Name: "Tree nuts", Terms: []string{"tree nut", "nut", "praline", "nougat"}, NotTerms: []string{
+		"monkey nut",
+	}, FreeTerms: []string{"nut free"}},
+	{ID: Almond, Name: "Almond", Parent: TreeNut, Terms: []string{"almond", "marzipan", "frangipane", "amaretto"}},
+	{ID: BrazilNut, Name: "Brazil nut", Parent: TreeNut, Terms: []string{"brazil nut"}},
+	{ID: Cashew, Name: "Cashew", Parent: TreeNut, Terms: []string{"cashew"}},
+	{ID: Hazelnut, Name: "Hazelnut", Parent: TreeNut, Terms: []string{"hazelnut", "filbert", "gianduja", "nutella"}},
+	{ID: Macadamia, Name: "Macadamia", Parent: TreeNut, Terms: []string{"macadamia"}},
+	{ID: Pecan, Name: "Pecan", Parent: TreeNut, Terms: []string{"pecan"}},
+	{ID: PineNut, Name: "Pine nut", Parent: TreeNut, Terms: []string{"pine nut", "pignoli", "pesto"}},
+	{ID: Pistachio, Name: "Pistachio", Parent: TreeNut, Terms: []string{"pistachio"}},
+	{ID: Walnut, Name: "Walnut", Parent: TreeNut, Terms: []string{"walnut"}},
+	{ID: Peanut, Name: "Peanut", Terms: []string{"peanut", "groundnut", "arachis", "monkey nut", "satay"}, FreeTerms: []string{"peanut free", "nut free"}},
+	{ID: Gluten, Name: "Cereals containing gluten", Terms: []string{"gluten", "seitan"}, FreeTerms: []string{"gluten free"}},
+	{ID: Wheat, Name: "Wheat", Parent: Gluten, Terms: []string{
+		"wheat", "flour", "semolina", "durum", "spelt", "farro", "kamut", "einkorn", "emmer",
+		"triticale", "couscous", "bulgur", "bulgar", "freekeh", "breadcrumb", "panko", "pasta",
+		"noodle", "bread", "soy sauce",
+	}, NotTerms: []string{
+		"rice flour", "almond flour", "coconut flour", "corn flour", "chickpea flour", "gram flour",
+		"oat flour", "potato flour", "tapioca flour", "cassava flour", "lupin flour", "soy flour",
+		"soya flour", "hazelnut flour", "chestnut flour", "sorghum flour", "millet flour", "teff flour",
+		"quinoa flour", "pea flour", "rice noodle", "rice pasta", "glass noodle", "cellophane noodle",
+	}, FreeTerms: []string{"gluten free", "wheat free"}},
+	{ID: Barley, Name: "Barley", Parent: Gluten, Terms: []string{"barley", "malt"}},
+	{ID: Rye, Name: "Rye", Parent: Gluten, Terms: []string{"rye", "pumpernickel"}},
+	{ID: Oats, Name: "Oats", Parent: Gluten, Terms: []string{"oat", "oatmeal"}},
+	{ID: Soy, Name: "Soy", Terms: []string{
+		"soy", "soya", "soybean", "tofu", "tempeh", "edamame", "miso", "tamari", "natto", "shoyu",
+	}, FreeTerms: []string{"soy free"}},
+	{ID: Sesame, Name: "Sesame", Terms: []string{"sesame", "tahini", "tahina", "halva", "halvah", "gomasio", "benne"}},
+	{ID: Mustard, Name: "Mustard", Terms: []string{"mustard", "dijon"}},
+	{ID: Celery, Name: "Celery", Terms: []string{"celery", "celeriac"}},
+	{ID: Lupin, Name: "Lupin", Terms: []string{"lupin", "lupine", "lupini"}},
+	{ID: Sulphites, Name: "Sulphites", Terms: []string{
+		"sulphite", "sulfite", "sulphur dioxide", "sulfur dioxide", "metabisulphite", "metabisulfite",
+		"bisulphite", "bisulfite", "wine",
+	}},
+}
+
+// byID indexes taxonomy.
+var byID = func() map[string]*Allergen {
+	m := make(map[string]*Allergen, len(taxonomy))
+	for i := range taxonomy {
+		m[taxonomy[i].ID] = &taxonomy[i]
+	}
+	return m
+}()
+
+// Lookup returns the allergen with the given ID.
+func Lookup(id string) (Allergen, bool) {
+	a, ok := byID[id]
+	if !ok {
+		return Allergen{}, false
+	}
+	return *a, true
+}
+
+// All returns every allergen, parents before children.
+func All() []Allergen {
+	return append([]Allergen(nil), taxonomy...)
+}
+
+// Ancestors returns id's parent, grandparent and so on, nearest first.
+func Ancestors(id string) []string {
+	var out []string
+	for a, ok := byID[id]; ok && a.Parent != ""; a, ok = byID[a.Parent] {
+		out = append(out, a.Parent)
+	}
+	return out
+}
+
+// IsA reports whether id is ancestor or one of its descendants.
+func IsA(id, ancestor string) bool {
+	if id == ancestor {
+		return true
+	}
+	for _, p := range Ancestors(id) {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// matcher is an allergen's Terms, NotTerms and FreeTerms, normalized once.
+type matcher struct {
+	id        string
+	terms     []string
+	notTerms  []string
+	freeTerms []string
+}
+
+// matchers holds a matcher per allergen, in taxonomy order.
+var matchers = func() []matcher {
+	out := make([]matcher, len(taxonomy))
+	for i, a := range taxonomy {
+		out[i].id = a.ID
+		for _, t := range a.Terms {
+			out[i].terms = append(out[i].terms, normalize(t))
+		}
+		for _, t := range a.NotTerms {
+			out[i].notTerms = append(out[i].notTerms, normalize(t))
+		}
+		for _, t := range a.FreeTerms {
+			out[i].freeTerms = append(out[i].freeTerms, normalize(t))
+		}
+	}
+	return out
+}()
+
+// Match returns the allergens a term names directly, in taxonomy order:
+// "whey protein" is milk, "almond flour" is almond. Ancestors are not
+// included; see Resolve.
+func Match(term string) []string {
+	text := normalize(term)
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	var out []string
+	for _, m := range matchers {
+		if containsAny(text, m.freeTerms) {
+			continue
+		}
+		t := text
+		for _, not := range m.notTerms {
+			for strings.Contains(t, not) {
+				t = strings.Replace(t, not, " ", 1)
+			}
+		}
+		for _, word := range m.terms {
+			if strings.Contains(t, word) {
+				out = append(out, m.id)
+				break
+			}
+		}
+	}
+	// A more specific match makes its ancestor's redundant: "almond nut"
+	// is almond, not every tree nut.
+	var specific []string
+	for _, id := range out {
+		redundant := false
+		for _, other := range out {
+			if other != id && IsA(other, id) {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			specific = append(specific, id)
+		}
+	}
+	return specific
+}
+
+// Resolve returns the allergens a term names and all their ancestors, sorted:
+// "almond" is almond and tree_nut.
+func Resolve(term string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, id := range Match(term) {
+		for _, a := range append([]string{id}, Ancestors(id)...) {
+			if !seen[a] {
+				seen[a] = true
+				out = append(out, a)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Related reports whether two terms name overlapping allergens: the same
+// allergen, or one an ancestor of the other. A tree nut allergy is related
+// to almond; a wheat allergy is not related to barley, though both are
+// gluten cereals.
+func Related(a, b string) bool {
+	for _, x := range Match(a) {
+		for _, y := range Match(b) {
+			if IsA(x, y) || IsA(y, x) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SameTerm reports whether two terms are the same words once normalized
+// ("Kiwis" and "kiwi"), which matches allergens the taxonomy doesn't know.
+// An empty term is never the same as anything.
+func SameTerm(a, b string) bool {
+	na := normalize(a)
+	return strings.TrimSpace(na) != "" && na == normalize(b)
+}
+
+// containsAny reports whether text contains any of the normalized phrases.
+func containsAny(text string, phrases []string) bool {
+	for _, p := range phrases {
+		if strings.Contains(text, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize lowercases a term and reduces it to space-separated words with
+// simple plurals folded ("Eggs," becomes " egg "), padded so whole-word
+// matching is a substring check. Hyphens separate words, so "dairy-free" and
+// "dairy free" match the same phrases.
+func normalize(s string) string {
+	s = strings.ToLower(s)
+	s = strings.NewReplacer("'", "", "’", "").Replace(s)
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	for i, w := range words {
+		words[i] = singular(w)
+	}
+	return " " + strings.Join(words, " ") + " "
+}
+
+// singular folds the plurals the taxonomy's words take.
+func singular(w string) string {
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "oes"), strings.HasSuffix(w, "shes"), strings.HasSuffix(w, "ches"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us") && len(w) > 3:
+		return w[:len(w)-1]
+	}
+	return w
+}
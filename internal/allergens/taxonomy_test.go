@@ -0,0 +1,143 @@
+package allergens
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{"Whey protein", []string{Milk}},
+		{"tahini", []string{Sesame}},
+		{"Almonds, slivered", []string{Almond}},
+		{"pine nuts", []string{PineNut}},
+		{"mixed nuts", []string{TreeNut}},
+		{"peanut butter", []string{Peanut}},
+		{"coconut milk", nil},
+		{"nutmeg", nil},
+		{"butternut squash", nil},
+		{"eggplant", nil},
+		{"rice flour", nil},
+		{"lupin flour", []string{Lupin}},
+		{"all-purpose flour", []string{Wheat}},
+		{"soy sauce", []string{Wheat, Soy}},
+		{"egg noodles", []string{Egg, Wheat}},
+		{"Shrimp", []string{Crustacean}},
+		{"shellfish", []string{Shellfish}},
+		{"salmon fillets", []string{Fish}},
+		{"Dijon mustard", []string{Mustard}},
+		{"gluten-free oats", []string{Oats}},
+		{"gluten free oats", []string{Oats}},
+		{"dairy-free milk", nil},
+		{"Dairy-Free cheese", nil},
+		{"egg-free mayo", nil},
+		{"nut-free granola", nil},
+		{"gluten-free flour", nil},
+		{"gluten-free soy sauce", []string{Soy}},
+		{"non-dairy creamer", nil},
+		{"half-and-half", nil},
+		{"sulfites", []string{Sulphites}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.term); !slices.Equal(got, tt.want) {
+			t.Errorf("Match(%q) = %v, want %v", tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestResolveAndRelated(t *testing.T) {
+	if got := Resolve("almond flour"); !slices.Equal(got, []string{Almond, TreeNut}) {
+		t.Errorf("Resolve(almond flour) = %v", got)
+	}
+	if got := Resolve("semolina"); !slices.Equal(got, []string{Gluten, Wheat}) {
+		t.Errorf("Resolve(semolina) = %v", got)
+	}
+
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"tree nuts", "cashew", true},
+		{"casein", "milk", true},
+		{"shellfish", "lobster", true},
+		{"wheat", "barley", false},
+		{"peanuts", "tree nuts", false},
+		{"fish", "shellfish", false},
+	}
+	for _, tt := range tests {
+		if got := Related(tt.a, tt.b); got != tt.want {
+			t.Errorf("Related(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSameTerm(t *testing.T) {
+	if !SameTerm("Kiwis", "kiwi") || !SameTerm("dairy-free", "dairy free") {
+		t.Error("normalized spellings should be the same term")
+	}
+	if SameTerm("peanut", "pea") || SameTerm("", "") || SameTerm(" ", "") {
+		t.Error("SameTerm should not match partial or empty terms")
+	}
+}
+
+func TestTaxonomyIsConsistent(t *testing.T) {
+	seen := map[string]bool{}
+	for _, a := range All() {
+		if seen[a.ID] {
+			t.Errorf("duplicate allergen %q", a.ID)
+		}
+		if a.Parent != "" && !seen[a.Parent] {
+			t.Errorf("%q is listed before its parent %q", a.ID, a.Parent)
+		}
+		seen[a.ID] = true
+	}
+	for _, s := range Sets() {
+		for _, id := range s.Allergens {
+			if !seen[id] {
+				t.Errorf("set %q declares unknown allergen %q", s.ID, id)
+			}
+		}
+	}
+}
+
+func TestSets(t *testing.T) {
+	tests := []struct {
+		set, id string
+		want    bool
+	}{
+		{SetUSTop9, Sesame, true},
+		{SetUSTop9, Almond, true},
+		{SetUSTop9, Shellfish, true},
+		{SetUSTop9, Mollusc, false},
+		{SetUSTop9, Barley, false},
+		{SetUSTop9, Gluten, true},
+		{SetEU14, Barley, true},
+		{SetEU14, Lupin, true},
+		{SetCanada, Lupin, false},
+		{SetANZ, Mustard, false},
+		{"unknown", Milk, false},
+	}
+	for _, tt := range tests {
+		if got := InSet(tt.set, tt.id); got != tt.want {
+			t.Errorf("InSet(%q, %q) = %v, want %v", tt.set, tt.id, got, tt.want)
+		}
+	}
+
+	for region, want := range map[string]string{"gb": SetEU14, "DE": SetEU14, "CA": SetCanada, "NZ": SetANZ, "US": SetUSTop9, "": DefaultSet, "JP": DefaultSet} {
+		if got := SetForRegion(region); got != want {
+			t.Errorf("SetForRegion(%q) = %q, want %q", region, got, want)
+		}
+	}
+
+	if got, err := NormalizeSet(" EU_14 "); err != nil || got != SetEU14 {
+		t.Errorf("NormalizeSet(EU_14) = %q, %v", got, err)
+	}
+	if _, err := NormalizeSet("eu_15"); !errors.Is(err, ErrUnknownSet) {
+		t.Errorf("NormalizeSet(eu_15) err = %v, want ErrUnknownSet", err)
+	}
+}
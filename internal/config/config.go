@@ -49,10 +49,37 @@ type EnvVars struct {
 	MainBaseURL    string `env:"MAIN_BASE_URL" optional:"true"`
 	GeminiAPIKey   string `env:"GEMINI_API_KEY" optional:"true"`
 	DeepSeekAPIKey string `env:"DEEPSEEK_API_KEY" optional:"true"`
+	// Image-generation provider selection: "openai" (DALL-E 3, or any model on
+	// an OpenAI-compatible endpoint via ImageBaseURL) or "gemini" (Imagen).
+	// The startup default — a GenerateImage route set in the dashboard
+	// overrides it via DB.
+	ImageProvider string `env:"IMAGE_PROVIDER" envDefault:"openai" optional:"true"`
+	ImageModel    string `env:"IMAGE_MODEL" optional:"true"`
+	ImageBaseURL  string `env:"IMAGE_BASE_URL" optional:"true"`
+	// Cooking-mode read-aloud: TTSProvider is "openai" (OpenAI TTS, TTSModel
+	// defaulting to tts-1) or "local" (a keyless stand-in for development);
+	// TTSVoice is the voice answers and steps are read in.
+	TTSProvider string `env:"TTS_PROVIDER" envDefault:"openai" optional:"true"`
+	TTSModel    string `env:"TTS_MODEL" optional:"true"`
+	TTSVoice    string `env:"TTS_VOICE" envDefault:"alloy" optional:"true"`
+	// AIRateLimits caps AI calls per provider or provider/model, as
+	// "provider[/model]=RPM:TPM,..." (0 leaves that dimension unlimited).
+	// Per-model limits in the model registry take precedence.
+	AIRateLimits string `env:"AI_RATE_LIMITS" optional:"true"`
+	// AICassettePath is the recorded AI interactions file. AIRecord captures
+	// every live AI call into it; LIGHT_PROVIDER/MAIN_PROVIDER=replay serve
+	// from it with no network (AIReplayStrict: exact recordings only).
+	AICassettePath string `env:"AI_CASSETTE_PATH" envDefault:"testdata/ai_cassette.json" optional:"true"`
+	AIRecord       bool   `env:"AI_RECORD" optional:"true"`
+	AIReplayStrict bool   `env:"AI_REPLAY_STRICT" optional:"true"`
 	// AdminToken guards the admin API (the dashboard's live model-switch +
 	// registry endpoints). When empty the admin API is disabled entirely, so a
 	// deploy without the secret can never expose those endpoints.
 	AdminToken string `env:"ADMIN_TOKEN" optional:"true"`
+	// WSBackplane shares cooking-mode rooms between API instances:
+	// "postgres" (LISTEN/NOTIFY on the app database) or "local" for a single
+	// instance.
+	WSBackplane string `env:"WS_BACKPLANE" envDefault:"postgres" optional:"true"`
 	// VideoNativeGemini routes video import through native Gemini video+audio
 	// extraction (far cheaper than sampling frames onto Sonnet, and it reads the
 	// narration natively). Requires GEMINI_API_KEY. Falls back to frame sampling
@@ -70,6 +97,8 @@ type EnvVars struct {
 	GoogleSearchKey string `env:"GOOGLE_SEARCH_KEY" optional:"true"`
 	GoogleSearchCX  string `env:"GOOGLE_SEARCH_CX" optional:"true"`
 	BraveSearchKey  string `env:"BRAVE_SEARCH_KEY" optional:"true"`
+	BingSearchKey   string `env:"BING_SEARCH_KEY" optional:"true"`
+	SearXNGBaseURL  string `env:"SEARXNG_BASE_URL" optional:"true"` // self-hosted SearXNG instance (no key)
 	FirecrawlAPIKey string `env:"FIRECRAWL_API_KEY" optional:"true"`
 	// ScrapeCreatorsAPIKey enables video-link import (TikTok/Instagram/YouTube/
 	// Facebook/Pinterest). When empty, video import is disabled.
@@ -85,6 +114,16 @@ type EnvVars struct {
 	// warm extractions (a runaway guard, intentionally high — not a normal cap;
 	// JSON-LD/AI gap-fill runs freely under it). 0 disables the ceiling.
 	RecipeWarmingDailyLimit int `env:"RECIPE_WARMING_DAILY_LIMIT" envDefault:"5000" optional:"true"`
+	// AIMonthlyCapFreeUSD and AIMonthlyCapPremiumUSD are the per-user monthly
+	// ceilings on metered AI cost (from ai_usage_logs) for each tier. A user
+	// over their ceiling is refused further gated actions until the monthly
+	// reset, whatever their count-based limits say. 0 disables the ceiling.
+	AIMonthlyCapFreeUSD    float64 `env:"AI_MONTHLY_CAP_FREE_USD" envDefault:"1" optional:"true"`
+	AIMonthlyCapPremiumUSD float64 `env:"AI_MONTHLY_CAP_PREMIUM_USD" envDefault:"20" optional:"true"`
+	// FinderRankCaptureRate is the fraction of finder runs (0–1) whose exact
+	// ranker request/result is stored on the run row, building the corpus that
+	// cmd/finder-eval replays. 0 disables capture.
+	FinderRankCaptureRate float64 `env:"FINDER_RANK_CAPTURE_RATE" envDefault:"0" optional:"true"`
 	// PublicBaseURL is the externally reachable base URL of this API. It is the
 	// OAuth issuer identifier and the base of the MCP resource URL, so it must
 	// match what MCP hosts (Claude/ChatGPT connectors) are configured with.
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PromptStore holds the live prompt set behind a Prompts loaded at startup.
+// Providers keep the *Prompts they were built with and resolve it per call
+// with For, so swapping the store's contents (a new active version from the
+// prompt registry, or an operation pinned to an older one) takes effect on
+// the next call without rebuilding any provider.
+type PromptStore struct {
+	mu     sync.RWMutex
+	active *Prompts
+	pins   map[string]*Prompts
+}
+
+// NewPromptStore creates a store serving base and attaches it to base, so
+// every provider already holding base follows the store from then on.
+func NewPromptStore(base *Prompts) *PromptStore {
+	s := &PromptStore{active: base, pins: map[string]*Prompts{}}
+	base.live = s
+	return s
+}
+
+// Set atomically replaces the active prompt set and the per-operation pins.
+// A nil active keeps the current one.
+func (s *PromptStore) Set(active *Prompts, pins map[string]*Prompts) {
+	table := make(map[string]*Prompts, len(pins))
+	for op, p := range pins {
+		if p != nil {
+			table[op] = p
+		}
+	}
+	s.mu.Lock()
+	if active != nil {
+		s.active = active
+	}
+	s.pins = table
+	s.mu.Unlock()
+}
+
+// Active returns the prompt set served to unpinned operations.
+func (s *PromptStore) Active() *Prompts {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.active
+}
+
+// For returns the prompt set to use for one call of op: the version op is
+// pinned to, else the store's active version. Prompts without a store (tests,
+// tools) return themselves.
+func (p *Prompts) For(op string) *Prompts {
+	if p == nil || p.live == nil {
+		return p
+	}
+	s := p.live
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if pinned := s.pins[op]; pinned != nil {
+		return pinned
+	}
+	if s.active != nil {
+		return s.active
+	}
+	return p
+}
+
+// ParsePrompts parses a YAML prompt configuration, in the same format as the
+// prompts file.
+func ParsePrompts(data []byte) (*Prompts, error) {
+	var prompts Prompts
+	if err := yaml.Unmarshal(data, &prompts); err != nil {
+		return nil, fmt.Errorf("failed to parse prompts YAML: %w", err)
+	}
+	return &prompts, nil
+}
+
+// MarshalPrompts renders a prompt set back to YAML.
+func MarshalPrompts(prompts *Prompts) ([]byte, error) {
+	return yaml.Marshal(prompts)
+}
+
+// FlattenPrompts returns every template in a prompt set keyed by its dotted
+// YAML path, e.g. "recipe.generate.system".
+func FlattenPrompts(prompts *Prompts) (map[string]string, error) {
+	tree, err := promptTree(prompts)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]string{}
+	flattenPromptTree("", tree, out)
+	return out, nil
+}
+
+// PromptPaths returns the dotted path of every template field, sorted.
+func PromptPaths() []string {
+	flat, _ := FlattenPrompts(&Prompts{})
+	paths := make([]string, 0, len(flat))
+	for path := range flat {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// ApplyPromptChanges returns a copy of base with the templates at the given
+// dotted paths replaced. Unknown paths are an error.
+func ApplyPromptChanges(base *Prompts, changes map[string]string) (*Prompts, error) {
+	tree, err := promptTree(base)
+	if err != nil {
+		return nil, err
+	}
+	for path, value := range changes {
+		if err := setPromptTree(tree, strings.Split(path, "."), value); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	data, err := yaml.Marshal(tree)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePrompts(data)
+}
+
+// promptSampleData fills every placeholder the templates use, so validation
+// exercises both sides of each conditional.
+var promptSampleData = map[string]interface{}{
+	"Prompt":         "sample prompt",
+	"UnitSystem":     "metric",
+	"Requirements":   "sample requirements",
+	"CookingContext": "sample cooking context",
+	"Locale":         "US",
+	"Ingredients":    "sample ingredients",
+	"IsPremium":      true,
+	"MemberName":     "Sam",
+	"RecipeContext":  "sample recipe",
+	"Transcript":     "sample transcript",
+}
+
+// ValidatePrompts renders every template in next with sample data and reports
+// the first that fails to parse or execute, or that references a placeholder
+// no caller supplies. When prev is given, a template that was set there may
+// not be blanked in next.
+func ValidatePrompts(next, prev *Prompts) error {
+	flat, err := FlattenPrompts(next)
+	if err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(flat))
+	for path := range flat {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		out, err := RenderPrompt(flat[path], promptSampleData)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if strings.Contains(out, "<no value>") {
+			return fmt.Errorf("%s: template references an unknown placeholder", path)
+		}
+	}
+	if prev == nil {
+		return nil
+	}
+	before, err := FlattenPrompts(prev)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if strings.TrimSpace(before[path]) != "" && strings.TrimSpace(flat[path]) == "" {
+			return fmt.Errorf("%s: template may not be emptied", path)
+		}
+	}
+	return nil
+}
+
+// promptTree converts a prompt set to its generic YAML tree.
+func promptTree(prompts *Prompts) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(prompts)
+	if err != nil {
+		return nil, err
+	}
+	tree := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func flattenPromptTree(prefix string, node map[string]interface{}, out map[string]string) {
+	for key, v := range node {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		switch v := v.(type) {
+		case map[string]interface{}:
+			flattenPromptTree(path, v, out)
+		case string:
+			out[path] = v
+		case nil:
+			out[path] = ""
+		}
+	}
+}
+
+func setPromptTree(node map[string]interface{}, path []string, value string) error {
+	v, ok := node[path[0]]
+	if !ok {
+		return fmt.Errorf("unknown prompt path")
+	}
+	if len(path) == 1 {
+		if _, isLeaf := v.(string); !isLeaf && v != nil {
+			return fmt.Errorf("not a template")
+		}
+		node[path[0]] = value
+		return nil
+	}
+	child, isMap := v.(map[string]interface{})
+	if !isMap {
+		return fmt.Errorf("unknown prompt path")
+	}
+	return setPromptTree(child, path[1:], value)
+}
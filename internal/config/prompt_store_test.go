@@ -0,0 +1,93 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPromptsFor_WithoutStoreReturnsSelf(t *testing.T) {
+	p := &Prompts{}
+	if got := p.For("GenerateRecipe"); got != p {
+		t.Fatal("For without a store should return the receiver")
+	}
+}
+
+func TestPromptStore_ActiveAndPins(t *testing.T) {
+	base := &Prompts{CookingQA: SinglePrompt{System: "base"}}
+	store := NewPromptStore(base)
+	if got := base.For("CookingQA"); got != base {
+		t.Fatal("a fresh store should serve the base set")
+	}
+
+	active := &Prompts{CookingQA: SinglePrompt{System: "v2"}}
+	pinned := &Prompts{CookingQA: SinglePrompt{System: "v1"}}
+	store.Set(active, map[string]*Prompts{"CookingQA": pinned})
+
+	if got := base.For("CookingQA").CookingQA.System; got != "v1" {
+		t.Errorf("pinned op served %q, want v1", got)
+	}
+	if got := base.For("GenerateRecipe").CookingQA.System; got != "v2" {
+		t.Errorf("unpinned op served %q, want v2", got)
+	}
+
+	store.Set(nil, nil)
+	if got := base.For("CookingQA").CookingQA.System; got != "v2" {
+		t.Errorf("after clearing pins served %q, want v2", got)
+	}
+}
+
+func TestApplyPromptChanges(t *testing.T) {
+	base := &Prompts{Recipe: RecipePrompts{Generate: PromptPair{System: "old", User: "{{.Prompt}}"}}}
+
+	next, err := ApplyPromptChanges(base, map[string]string{"recipe.generate.system": "new"})
+	if err != nil {
+		t.Fatalf("ApplyPromptChanges() error = %v", err)
+	}
+	if next.Recipe.Generate.System != "new" || next.Recipe.Generate.User != "{{.Prompt}}" {
+		t.Errorf("got %+v", next.Recipe.Generate)
+	}
+	if base.Recipe.Generate.System != "old" {
+		t.Error("base must not be modified")
+	}
+
+	for _, path := range []string{"recipe.generate.nope", "recipe.generate", "nope"} {
+		if _, err := ApplyPromptChanges(base, map[string]string{path: "x"}); err == nil {
+			t.Errorf("path %q should be rejected", path)
+		}
+	}
+}
+
+func TestValidatePrompts(t *testing.T) {
+	good := &Prompts{Recipe: RecipePrompts{Generate: PromptPair{
+		System: "{{if .Requirements}}Req: {{.Requirements}}{{end}} in {{.UnitSystem}}",
+		User:   "{{.Prompt}}",
+	}}}
+	if err := ValidatePrompts(good, nil); err != nil {
+		t.Fatalf("valid prompts rejected: %v", err)
+	}
+
+	broken := &Prompts{Recipe: RecipePrompts{Generate: PromptPair{User: "{{.Prompt"}}}
+	if err := ValidatePrompts(broken, nil); err == nil || !strings.Contains(err.Error(), "recipe.generate.user") {
+		t.Errorf("unparseable template: err = %v", err)
+	}
+
+	typo := &Prompts{Recipe: RecipePrompts{Generate: PromptPair{User: "{{.Promt}}"}}}
+	if err := ValidatePrompts(typo, nil); err == nil {
+		t.Error("unknown placeholder should be rejected")
+	}
+
+	blanked := &Prompts{Recipe: RecipePrompts{Generate: PromptPair{System: good.Recipe.Generate.System}}}
+	if err := ValidatePrompts(blanked, good); err == nil || !strings.Contains(err.Error(), "recipe.generate.user") {
+		t.Errorf("emptied template: err = %v", err)
+	}
+}
+
+func TestValidatePrompts_ShippedPromptsFile(t *testing.T) {
+	p, err := LoadPrompts("../../configs/prompts.yaml")
+	if err != nil {
+		t.Fatalf("LoadPrompts() error = %v", err)
+	}
+	if err := ValidatePrompts(p, nil); err != nil {
+		t.Errorf("shipped prompts fail validation: %v", err)
+	}
+}
@@ -9,8 +9,6 @@ import (
 	"os"
 	"strings"
 	"text/template"
-
-	"gopkg.in/yaml.v3"
 )
 
 // PromptPair holds a system and user prompt template.
@@ -70,6 +68,9 @@ type Prompts struct {
 	CookingQA        SinglePrompt    `yaml:"cooking_qa"`
 	DietaryInterview SinglePrompt    `yaml:"dietary_interview"`
 	Import           ImportPrompts   `yaml:"import"`
+
+	// live is the store this set was registered with, if any (see For).
+	live *PromptStore
 }
 
 // PromptVersion returns a short hash of the current prompt templates.
@@ -90,12 +91,7 @@ func LoadPrompts(path string) (*Prompts, error) {
 		return nil, fmt.Errorf("failed to read prompts file: %w", err)
 	}
 
-	var prompts Prompts
-	if err := yaml.Unmarshal(data, &prompts); err != nil {
-		return nil, fmt.Errorf("failed to parse prompts YAML: %w", err)
-	}
-
-	return &prompts, nil
+	return ParsePrompts(data)
 }
 
 // RenderPrompt executes Go template interpolation on a prompt string.
@@ -64,8 +64,17 @@ func connectToDatabaseWithRetry(databaseURL string) (*gorm.DB, error) {
 		&models.VideoImport{},
 		&models.AIUsageLog{},
 		&models.AIModelOption{},
+		&models.SearchBackendOption{},
 		&models.AIConfig{},
+		&models.AIOperationRoute{},
+		&models.AIExperiment{},
+		&models.AIFallbackEntry{},
+		&models.PromptSetVersion{},
+		&models.PromptConfig{},
+		&models.PromptPin{},
 		&models.FinderSession{},
+		&models.CookingSession{},
+		&models.CookLog{},
 		&models.FinderRun{},
 		&models.ExtractionEvent{},
 		&models.OAuthClient{},
@@ -75,6 +84,12 @@ func connectToDatabaseWithRetry(databaseURL string) (*gorm.DB, error) {
 		return nil, fmt.Errorf("database auto-migration failed: %w", migrateErr)
 	}
 
+	// The search cache key grew a market column; drop the old single-column
+	// unique index so the same query can be cached once per market.
+	if execErr := database.Exec(`DROP INDEX IF EXISTS idx_search_caches_normalized_query`).Error; execErr != nil {
+		logger.Get().Warn("failed to drop idx_search_caches_normalized_query index", zap.Error(execErr))
+	}
+
 	// HNSW index for vector similarity on search cache embeddings
 	if execErr := database.Exec(`CREATE INDEX IF NOT EXISTS idx_search_caches_embedding ON search_caches USING hnsw (embedding vector_cosine_ops)`).Error; execErr != nil {
 		logger.Get().Warn("failed to create idx_search_caches_embedding index", zap.Error(execErr))
@@ -85,6 +100,21 @@ func connectToDatabaseWithRetry(databaseURL string) (*gorm.DB, error) {
 		logger.Get().Warn("failed to create idx_canonical_recipes_embedding index", zap.Error(execErr))
 	}
 
+	// Full-text document over each canonical recipe's title, ingredient names
+	// and instructions, for local search ahead of the web. A stored generated
+	// column so it never drifts from recipe_data.
+	if execErr := database.Exec(`ALTER TABLE canonical_recipes ADD COLUMN IF NOT EXISTS search_document tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('english', coalesce(recipe_data->>'title', '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(jsonb_path_query_array(recipe_data, '$.ingredients[*].name')::text, '')), 'B') ||
+			setweight(to_tsvector('english', coalesce(recipe_data->>'instructions', '')), 'D')
+		) STORED`).Error; execErr != nil {
+		logger.Get().Warn("failed to add canonical_recipes.search_document column", zap.Error(execErr))
+	}
+	if execErr := database.Exec(`CREATE INDEX IF NOT EXISTS idx_canonical_recipes_search_document ON canonical_recipes USING gin (search_document)`).Error; execErr != nil {
+		logger.Get().Warn("failed to create idx_canonical_recipes_search_document index", zap.Error(execErr))
+	}
+
 	// HNSW index for vector similarity on user recipe embeddings
 	if execErr := database.Exec(`CREATE INDEX IF NOT EXISTS idx_recipes_embedding ON recipes USING hnsw (embedding vector_cosine_ops)`).Error; execErr != nil {
 		logger.Get().Warn("failed to create idx_recipes_embedding index", zap.Error(execErr))
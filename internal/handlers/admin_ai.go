@@ -1,17 +1,22 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/windoze95/saltybytes-api/internal/ai"
 	"github.com/windoze95/saltybytes-api/internal/models"
 	"github.com/windoze95/saltybytes-api/internal/service"
 )
 
-// AdminAIHandler exposes the light-tier model registry + live switch to the
-// admin dashboard. All routes sit behind RequireAdminToken.
+// AdminAIHandler exposes the light-tier model registry + live switch, the
+// per-operation routes, and the main tier's failover chain, to the admin
+// dashboard. All routes sit behind
+// RequireAdminToken.
 type AdminAIHandler struct {
-	Manager *service.AIModelManager
+	Manager  *service.AIModelManager
+	MainTier *service.MainTierManager // optional; fallback routes 503 when nil
 }
 
 // NewAdminAIHandler creates a new AdminAIHandler.
@@ -26,19 +31,30 @@ type modelOptionRequest struct {
 	ModelID            string  `json:"model_id"`
 	Label              string  `json:"label"`
 	BaseURL            string  `json:"base_url"`
+	Kind               string  `json:"kind"` // "text" or "image"; omitted means text on create, unchanged on update
 	InputPricePerMTok  float64 `json:"input_price_per_mtok"`
 	OutputPricePerMTok float64 `json:"output_price_per_mtok"`
+	RPMLimit           int     `json:"rpm_limit"`
+	TPMLimit           int     `json:"tpm_limit"`
 	Enabled            bool    `json:"enabled"`
 }
 
+// validKind reports whether the request names a known model kind.
+func (r modelOptionRequest) validKind() bool {
+	return r.Kind == "" || r.Kind == models.AIModelKindText || r.Kind == models.AIModelKindImage
+}
+
 func (r modelOptionRequest) toModel() *models.AIModelOption {
 	return &models.AIModelOption{
 		Provider:           r.Provider,
 		ModelID:            r.ModelID,
 		Label:              r.Label,
 		BaseURL:            r.BaseURL,
+		Kind:               r.Kind,
 		InputPricePerMTok:  r.InputPricePerMTok,
 		OutputPricePerMTok: r.OutputPricePerMTok,
+		RPMLimit:           r.RPMLimit,
+		TPMLimit:           r.TPMLimit,
 		Enabled:            r.Enabled,
 	}
 }
@@ -70,8 +86,15 @@ func (h *AdminAIHandler) CreateModel(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "provider and model_id are required"})
 		return
 	}
+	if !req.validKind() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be text or image"})
+		return
+	}
 
 	opt := req.toModel()
+	if opt.Kind == "" {
+		opt.Kind = models.AIModelKindText
+	}
 	if err := h.Manager.AddModel(c.Request.Context(), opt); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -87,7 +110,7 @@ func (h *AdminAIHandler) UpdateModel(c *gin.Context) {
 		return
 	}
 	var req modelOptionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBindJSON(&req); err != nil || !req.validKind() {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
 		return
 	}
@@ -143,3 +166,126 @@ func (h *AdminAIHandler) SetActive(c *gin.Context) {
 		"option": opt,
 	})
 }
+
+// ListRoutes returns the per-operation model routes and the operations that
+// can be routed.
+func (h *AdminAIHandler) ListRoutes(c *gin.Context) {
+	routes, err := h.Manager.ListRoutes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"routes":     routes,
+		"operations": append(append([]string{}, ai.TextOperations...), ai.ImageOperation),
+	})
+}
+
+// routeRequest selects which registered option serves an operation.
+type routeRequest struct {
+	OptionID uint `json:"option_id"`
+}
+
+// SetRoute pins one operation to a registered option after a green validation
+// probe. On a failed probe it returns 400 and leaves routing unchanged.
+func (h *AdminAIHandler) SetRoute(c *gin.Context) {
+	var req routeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.OptionID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "option_id is required"})
+		return
+	}
+	route, err := h.Manager.SetRoute(c.Request.Context(), c.Param("operation"), req.OptionID)
+	if err != nil {
+		c.JSON(modelRouteErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, route)
+}
+
+// ClearRoute sends an operation back to its tier's model.
+func (h *AdminAIHandler) ClearRoute(c *gin.Context) {
+	if err := h.Manager.ClearRoute(c.Param("operation")); err != nil {
+		c.JSON(modelRouteErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// modelRouteErrorStatus maps a route edit error to a status: validation
+// failures are the caller's fault (400), anything else is ours (500).
+func modelRouteErrorStatus(err error) int {
+	if errors.Is(err, service.ErrInvalidModelRoute) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// fallbackEntryRequest is the editable shape of a main-tier chain entry. API
+// keys are never accepted here — they live in env/SSM.
+type fallbackEntryRequest struct {
+	Provider           string `json:"provider"`
+	Model              string `json:"model"`
+	BaseURL            string `json:"base_url"`
+	Label              string `json:"label"`
+	Enabled            bool   `json:"enabled"`
+	BreakerThreshold   int    `json:"breaker_threshold"`
+	BreakerCooldownSec int    `json:"breaker_cooldown_sec"`
+}
+
+// fallbackChainRequest is the whole chain, in failover order.
+type fallbackChainRequest struct {
+	Entries []fallbackEntryRequest `json:"entries"`
+}
+
+// GetFallbackChain returns the main tier's failover chain in order, with each
+// entry's breaker health and any build error (e.g. a missing key).
+func (h *AdminAIHandler) GetFallbackChain(c *gin.Context) {
+	if h.MainTier == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "main tier failover is not configured"})
+		return
+	}
+	chain, err := h.MainTier.Chain()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": chain})
+}
+
+// SetFallbackChain replaces the main tier's failover chain with the ordered
+// list given. Every entry must be buildable and at least one enabled; on a
+// validation failure it returns 400 and the running chain is untouched.
+func (h *AdminAIHandler) SetFallbackChain(c *gin.Context) {
+	if h.MainTier == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "main tier failover is not configured"})
+		return
+	}
+	var req fallbackChainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	entries := make([]models.AIFallbackEntry, 0, len(req.Entries))
+	for _, e := range req.Entries {
+		entries = append(entries, models.AIFallbackEntry{
+			Provider:           e.Provider,
+			Model:              e.Model,
+			BaseURL:            e.BaseURL,
+			Label:              e.Label,
+			Enabled:            e.Enabled,
+			BreakerThreshold:   e.BreakerThreshold,
+			BreakerCooldownSec: e.BreakerCooldownSec,
+		})
+	}
+
+	chain, err := h.MainTier.ReplaceChain(entries)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidFallbackChain) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": chain})
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+	"github.com/windoze95/saltybytes-api/internal/service"
+)
+
+// AdminAICostHandler exposes per-user / per-operation / per-model AI cost
+// attribution to the admin dashboard. All routes sit behind RequireAdminToken.
+type AdminAICostHandler struct {
+	Costs *service.AICostService
+	Subs  *service.SubscriptionService
+}
+
+// NewAdminAICostHandler creates a new AdminAICostHandler.
+func NewAdminAICostHandler(costs *service.AICostService, subs *service.SubscriptionService) *AdminAICostHandler {
+	return &AdminAICostHandler{Costs: costs, Subs: subs}
+}
+
+// GetCosts breaks metered AI cost down by one dimension over a time range:
+// ?group_by=user|operation|model|endpoint (default operation), optional
+// from/to (RFC 3339; default the last 30 days), user_id to narrow to one
+// account, and limit on the number of groups.
+func (h *AdminAICostHandler) GetCosts(c *gin.Context) {
+	q := repository.AIUsageQuery{GroupBy: c.DefaultQuery("group_by", "operation")}
+
+	var err error
+	if q.From, err = parseTimeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 timestamp"})
+		return
+	}
+	if q.To, err = parseTimeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 timestamp"})
+		return
+	}
+	if v := c.Query("user_id"); v != "" {
+		if q.UserID, err = parseUintParam(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil || q.Limit < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+
+	report, err := h.Costs.Breakdown(q)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCostQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetUserSpend returns a user's AI spend this subscription month against
+// their tier's ceiling.
+func (h *AdminAICostHandler) GetUserSpend(c *gin.Context) {
+	userID, err := parseUintParam(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	sub, err := h.Subs.GetSubscription(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	spend, err := h.Subs.MonthlySpend(sub)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":  userID,
+		"tier":     sub.Tier,
+		"spend":    spend,
+		"over_cap": spend.OverCap(),
+	})
+}
+
+// parseTimeQuery parses an optional RFC 3339 query parameter (zero when unset).
+func parseTimeQuery(c *gin.Context, name string) (time.Time, error) {
+	v := c.Query(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/service"
+)
+
+// AdminAIExperimentHandler exposes A/B experiments between AI models to the
+// admin dashboard. All routes sit behind RequireAdminToken.
+type AdminAIExperimentHandler struct {
+	Experiments *service.AIExperimentManager
+}
+
+// NewAdminAIExperimentHandler creates a new AdminAIExperimentHandler.
+func NewAdminAIExperimentHandler(experiments *service.AIExperimentManager) *AdminAIExperimentHandler {
+	return &AdminAIExperimentHandler{Experiments: experiments}
+}
+
+// experimentRequest is the editable shape of an experiment. API keys are never
+// accepted here — they live in env/SSM.
+type experimentRequest struct {
+	Key       string                   `json:"key"`
+	Operation string                   `json:"operation"`
+	Notes     string                   `json:"notes"`
+	Arms      []models.AIExperimentArm `json:"arms"`
+}
+
+// ListExperiments returns every experiment, running or stopped.
+func (h *AdminAIExperimentHandler) ListExperiments(c *gin.Context) {
+	exps, err := h.Experiments.ListExperiments()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"experiments": exps})
+}
+
+// StartExperiment validates, probes and starts an experiment. Any arm that
+// can't be built or fails its probe rejects the whole experiment (400).
+func (h *AdminAIExperimentHandler) StartExperiment(c *gin.Context) {
+	var req experimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	exp := &models.AIExperiment{
+		Key:       req.Key,
+		Operation: req.Operation,
+		Notes:     req.Notes,
+		Arms:      req.Arms,
+	}
+	if err := h.Experiments.Start(c.Request.Context(), exp); err != nil {
+		c.JSON(experimentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, exp)
+}
+
+// StopExperiment ends an experiment; its report stays available.
+func (h *AdminAIExperimentHandler) StopExperiment(c *gin.Context) {
+	id, err := parseUintParam(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	exp, err := h.Experiments.Stop(id)
+	if err != nil {
+		c.JSON(experimentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, exp)
+}
+
+// GetReport returns an experiment's per-arm cost, latency and quality.
+func (h *AdminAIExperimentHandler) GetReport(c *gin.Context) {
+	id, err := parseUintParam(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	report, err := h.Experiments.Report(id)
+	if err != nil {
+		c.JSON(experimentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// experimentErrorStatus maps a manager error to a status.
+func experimentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidExperiment):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrExperimentNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
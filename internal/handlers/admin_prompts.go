@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/windoze95/saltybytes-api/internal/service"
+)
+
+// AdminPromptHandler exposes the prompt registry — versioned prompt
+// templates, activation/rollback and per-operation pins — to the admin
+// dashboard. All routes sit behind RequireAdminToken.
+type AdminPromptHandler struct {
+	Prompts *service.PromptRegistry
+}
+
+// NewAdminPromptHandler creates a new AdminPromptHandler.
+func NewAdminPromptHandler(prompts *service.PromptRegistry) *AdminPromptHandler {
+	return &AdminPromptHandler{Prompts: prompts}
+}
+
+// GetPrompts returns the version history, the active version, the pins and
+// the editable template paths.
+func (h *AdminPromptHandler) GetPrompts(c *gin.Context) {
+	state, err := h.Prompts.State()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, state)
+}
+
+// GetVersion returns one version with its full YAML content.
+func (h *AdminPromptHandler) GetVersion(c *gin.Context) {
+	id, err := parseUintParam(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	v, err := h.Prompts.GetVersion(id)
+	if err != nil {
+		c.JSON(promptErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, v)
+}
+
+// CreateVersion records a new version from a full YAML document or a set of
+// template changes. Templates that fail to render are rejected (400). The new
+// version is not activated.
+func (h *AdminPromptHandler) CreateVersion(c *gin.Context) {
+	var req service.PromptVersionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	v, err := h.Prompts.CreateVersion(req)
+	if err != nil {
+		c.JSON(promptErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, v)
+}
+
+// DiffVersion lists the templates that differ between a version and
+// ?against= (default: the version it was edited from).
+func (h *AdminPromptHandler) DiffVersion(c *gin.Context) {
+	id, err := parseUintParam(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var against uint
+	if q := c.Query("against"); q != "" {
+		if against, err = parseUintParam(q); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid against"})
+			return
+		}
+	}
+	diff, err := h.Prompts.Diff(id, against)
+	if err != nil {
+		c.JSON(promptErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"version_id": id, "against": against, "changes": diff})
+}
+
+// SetActive activates a version for every unpinned operation. Activating an
+// older version rolls back to it.
+func (h *AdminPromptHandler) SetActive(c *gin.Context) {
+	var req struct {
+		VersionID uint `json:"version_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.VersionID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version_id is required"})
+		return
+	}
+	v, err := h.Prompts.Activate(req.VersionID)
+	if err != nil {
+		c.JSON(promptErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"active": v})
+}
+
+// SetPin serves one operation from a specific version.
+func (h *AdminPromptHandler) SetPin(c *gin.Context) {
+	var req struct {
+		VersionID uint `json:"version_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.VersionID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version_id is required"})
+		return
+	}
+	pin, err := h.Prompts.SetPin(c.Param("operation"), req.VersionID)
+	if err != nil {
+		c.JSON(promptErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, pin)
+}
+
+// ClearPin returns an operation to the active version.
+func (h *AdminPromptHandler) ClearPin(c *gin.Context) {
+	op := c.Param("operation")
+	if err := h.Prompts.ClearPin(op); err != nil {
+		c.JSON(promptErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"operation": op, "cleared": true})
+}
+
+// promptErrorStatus maps a registry error to a status.
+func promptErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidPrompt):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrPromptVersionNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/service"
+)
+
+// AdminSearchHandler exposes the web search backend registry (weights, quotas,
+// breaker health) to the admin dashboard. All routes sit behind
+// RequireAdminToken.
+type AdminSearchHandler struct {
+	Manager *service.SearchBackendManager
+}
+
+// NewAdminSearchHandler creates a new AdminSearchHandler.
+func NewAdminSearchHandler(manager *service.SearchBackendManager) *AdminSearchHandler {
+	return &AdminSearchHandler{Manager: manager}
+}
+
+// searchBackendRequest is the editable shape of a registry entry. API keys are
+// never accepted here — they live in env/SSM.
+type searchBackendRequest struct {
+	Kind               string `json:"kind"`
+	BaseURL            string `json:"base_url"`
+	Label              string `json:"label"`
+	Enabled            bool   `json:"enabled"`
+	Weight             int    `json:"weight"`
+	DailyQuota         int    `json:"daily_quota"`
+	BreakerThreshold   int    `json:"breaker_threshold"`
+	BreakerCooldownSec int    `json:"breaker_cooldown_sec"`
+}
+
+// searchBackendUpdateRequest is a partial edit: omitted fields keep their
+// stored values, so a PUT without "enabled" can't switch a backend off.
+type searchBackendUpdateRequest struct {
+	Kind               *string `json:"kind"`
+	BaseURL            *string `json:"base_url"`
+	Label              *string `json:"label"`
+	Enabled            *bool   `json:"enabled"`
+	Weight             *int    `json:"weight"`
+	DailyQuota         *int    `json:"daily_quota"`
+	BreakerThreshold   *int    `json:"breaker_threshold"`
+	BreakerCooldownSec *int    `json:"breaker_cooldown_sec"`
+}
+
+func (r searchBackendUpdateRequest) toUpdate() service.SearchBackendUpdate {
+	return service.SearchBackendUpdate{
+		Kind:               r.Kind,
+		BaseURL:            r.BaseURL,
+		Label:              r.Label,
+		Enabled:            r.Enabled,
+		Weight:             r.Weight,
+		DailyQuota:         r.DailyQuota,
+		BreakerThreshold:   r.BreakerThreshold,
+		BreakerCooldownSec: r.BreakerCooldownSec,
+	}
+}
+
+func (r searchBackendRequest) toModel() *models.SearchBackendOption {
+	return &models.SearchBackendOption{
+		Kind:               r.Kind,
+		BaseURL:            r.BaseURL,
+		Label:              r.Label,
+		Enabled:            r.Enabled,
+		Weight:             r.Weight,
+		DailyQuota:         r.DailyQuota,
+		BreakerThreshold:   r.BreakerThreshold,
+		BreakerCooldownSec: r.BreakerCooldownSec,
+	}
+}
+
+// ListBackends returns every registered search backend with its live health
+// (breaker state, last error, today's quota usage).
+func (h *AdminSearchHandler) ListBackends(c *gin.Context) {
+	backends, err := h.Manager.ListBackends()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"backends": backends})
+}
+
+// CreateBackend registers a new search backend.
+func (h *AdminSearchHandler) CreateBackend(c *gin.Context) {
+	var req searchBackendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	opt := req.toModel()
+	if err := h.Manager.AddBackend(opt); err != nil {
+		c.JSON(searchBackendErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, opt)
+}
+
+// UpdateBackend edits a search backend; fields omitted from the body keep
+// their values. Changing its kind, URL or breaker tuning resets its breaker.
+func (h *AdminSearchHandler) UpdateBackend(c *gin.Context) {
+	id, err := parseUintParam(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req searchBackendUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	opt, err := h.Manager.UpdateBackend(id, req.toUpdate())
+	if err != nil {
+		c.JSON(searchBackendErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, opt)
+}
+
+// DeleteBackend removes a search backend.
+func (h *AdminSearchHandler) DeleteBackend(c *gin.Context) {
+	id, err := parseUintParam(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.Manager.DeleteBackend(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// searchBackendErrorStatus maps a manager error to a status: validation
+// failures are the caller's fault (400), anything else is ours (500).
+func searchBackendErrorStatus(err error) int {
+	if errors.Is(err, service.ErrInvalidSearchBackend) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/service"
+)
+
+// memSearchBackendRepo is an in-memory service.SearchBackendRepo.
+type memSearchBackendRepo struct {
+	opts []models.SearchBackendOption
+}
+
+func (r *memSearchBackendRepo) ListBackends() ([]models.SearchBackendOption, error) {
+	return append([]models.SearchBackendOption(nil), r.opts...), nil
+}
+
+func (r *memSearchBackendRepo) GetBackend(id uint) (*models.SearchBackendOption, error) {
+	for i := range r.opts {
+		if r.opts[i].ID == id {
+			cp := r.opts[i]
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memSearchBackendRepo) CreateBackend(opt *models.SearchBackendOption) error {
+	opt.ID = uint(len(r.opts) + 1)
+	r.opts = append(r.opts, *opt)
+	return nil
+}
+
+func (r *memSearchBackendRepo) UpdateBackend(opt *models.SearchBackendOption) error {
+	for i := range r.opts {
+		if r.opts[i].ID == opt.ID {
+			r.opts[i] = *opt
+		}
+	}
+	return nil
+}
+
+func (r *memSearchBackendRepo) DeleteBackend(id uint) error { return nil }
+
+func (r *memSearchBackendRepo) IncrementUsage(id uint, day string) (int, error) { return 0, nil }
+
+func TestUpdateBackend_OmittedFieldsKeepValues(t *testing.T) {
+	repo := &memSearchBackendRepo{opts: []models.SearchBackendOption{
+		{ID: 1, Kind: ai.SearchBackendBrave, Label: "Brave", Enabled: true, Weight: 3, DailyQuota: 100},
+	}}
+	handler := NewAdminSearchHandler(service.NewSearchBackendManager(repo, ai.SearchKeys{BraveAPIKey: "b"}, nil, ""))
+
+	r := gin.New()
+	r.PUT("/admin/search/backends/:id", handler.UpdateBackend)
+
+	req := httptest.NewRequest("PUT", "/admin/search/backends/1", strings.NewReader(`{"weight": 5}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d. body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	got := repo.opts[0]
+	if !got.Enabled || got.Weight != 5 || got.Kind != ai.SearchBackendBrave || got.Label != "Brave" || got.DailyQuota != 100 {
+		t.Errorf("backend = %+v, want only weight changed", got)
+	}
+
+	req = httptest.NewRequest("PUT", "/admin/search/backends/1", strings.NewReader(`{"enabled": false}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || repo.opts[0].Enabled {
+		t.Errorf("explicit disable: status = %d, backend = %+v", w.Code, repo.opts[0])
+	}
+}
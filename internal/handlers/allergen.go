@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/windoze95/saltybytes-api/internal/allergens"
 	"github.com/windoze95/saltybytes-api/internal/logger"
 	"github.com/windoze95/saltybytes-api/internal/models"
 	"github.com/windoze95/saltybytes-api/internal/repository"
@@ -38,6 +39,10 @@ func (h *AllergenHandler) AnalyzeRecipe(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID"})
 		return
 	}
+	set, ok := regulatorySet(c, user)
+	if !ok {
+		return
+	}
 
 	// Check tier and usage limits through the subscription service so stale
 	// monthly counters get reset and nil-subscription users are gated with
@@ -81,20 +86,23 @@ func (h *AllergenHandler) AnalyzeRecipe(c *gin.Context) {
 		var notFound repository.NotFoundError
 		if errors.As(err, &notFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if errors.Is(err, service.ErrAllergenAIUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "allergen analysis is not available"})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "allergen analysis failed"})
 		}
 		return
 	}
 
-	// Increment usage counter after successful analysis
-	if h.Service.SubService != nil {
+	// Count only analyses that called the model; cached and rule-only ones
+	// are free.
+	if h.Service.SubService != nil && result.UsedAI {
 		if err := h.Service.SubService.IncrementUsage(user.ID, "allergen"); err != nil {
 			logger.Get().Error("failed to increment allergen usage", zap.Uint("user_id", user.ID), zap.Error(err))
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"analysis": result})
+	c.JSON(http.StatusOK, gin.H{"analysis": result.WithRegulatorySet(set)})
 }
 
 // GetAnalysis returns cached allergen analysis for a recipe.
@@ -112,6 +120,10 @@ func (h *AllergenHandler) GetAnalysis(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID"})
 		return
 	}
+	set, ok := regulatorySet(c, user)
+	if !ok {
+		return
+	}
 
 	// Verify recipe ownership
 	recipe, err := h.Service.RecipeRepo.GetRecipeByID(recipeID)
@@ -136,7 +148,7 @@ func (h *AllergenHandler) GetAnalysis(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"analysis": result})
+	c.JSON(http.StatusOK, gin.H{"analysis": result.WithRegulatorySet(set)})
 }
 
 // CheckFamily cross-references allergen analysis with family dietary profiles.
@@ -180,3 +192,29 @@ func (h *AllergenHandler) CheckFamily(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"family_check": result})
 }
+
+// ListAllergenSets returns the regulatory allergen sets users can pick and
+// the allergen taxonomy they draw on.
+// GET /v1/allergens/sets
+func (h *AllergenHandler) ListAllergenSets(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"taxonomy_version": allergens.Version,
+		"sets":             allergens.Sets(),
+		"allergens":        allergens.All(),
+	})
+}
+
+// regulatorySet returns the allergen set to report against: the "set" query
+// parameter when present, else the user's own. It writes a 400 and returns
+// false when the parameter names no known set.
+func regulatorySet(c *gin.Context, user *models.User) (string, bool) {
+	set, err := allergens.NormalizeSet(c.Query("set"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	if set == "" {
+		set = service.UserAllergenSet(user)
+	}
+	return set, true
+}
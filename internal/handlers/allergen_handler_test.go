@@ -30,11 +30,13 @@ func freeSubscription(userID uint, allergenUsed int) *models.Subscription {
 }
 
 // newAllergenHandlerFixture wires an AllergenHandler whose recipe repo holds
-// the standard test recipe (owned by user 1) and whose subscription service is
-// backed by the given user repo.
+// the standard test recipe (owned by user 1), plus an ingredient the allergen
+// lexicon can't classify so analysis reaches the provider, and whose
+// subscription service is backed by the given user repo.
 func newAllergenHandlerFixture(allergenRepo *testutil.MockAllergenRepo, provider ai.TextProvider, userRepo *testutil.MockUserRepo) (*AllergenHandler, *testutil.MockRecipeRepo) {
 	recipeRepo := testutil.NewMockRecipeRepo()
 	recipe := testutil.TestRecipe()
+	recipe.Ingredients = append(recipe.Ingredients, models.Ingredient{Name: "Vegetable shortening", Unit: "tbsp", Amount: 1})
 	recipeRepo.Recipes[recipe.ID] = recipe
 
 	subService := service.NewSubscriptionService(&config.Config{}, userRepo)
@@ -165,6 +167,64 @@ func TestAnalyzeRecipe_Handler_FreeUserAtLimit_403(t *testing.T) {
 	}
 }
 
+func TestAnalyzeRecipe_Handler_MetersOnlyModelCalls(t *testing.T) {
+	user := testutil.TestUser()
+	user.Subscription = freeSubscription(user.ID, 0)
+	userRepo := testutil.NewMockUserRepo()
+	userRepo.Users[user.ID] = user
+	handler, recipeRepo := newAllergenHandlerFixture(&testutil.MockAllergenRepo{}, allergenAIProvider(), userRepo)
+
+	r := gin.New()
+	r.POST("/recipes/:recipe_id/allergens/analyze", setUser(user), handler.AnalyzeRecipe)
+	analyze := func() int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/recipes/1/allergens/analyze", nil))
+		return w.Code
+	}
+
+	// Every ingredient is in the lexicon: no model call, nothing charged.
+	withShortening := recipeRepo.Recipes[1].Ingredients
+	recipeRepo.Recipes[1].Ingredients = testutil.TestRecipe().Ingredients
+	if code := analyze(); code != http.StatusOK {
+		t.Fatalf("rule-only status = %d, want 200", code)
+	}
+	if got := userRepo.Users[user.ID].Subscription.AllergenAnalysesUsed; got != 0 {
+		t.Errorf("rule-only analysis charged: AllergenAnalysesUsed = %d, want 0", got)
+	}
+
+	recipeRepo.Recipes[1].Ingredients = withShortening
+	if code := analyze(); code != http.StatusOK {
+		t.Fatalf("model status = %d, want 200", code)
+	}
+	if got := userRepo.Users[user.ID].Subscription.AllergenAnalysesUsed; got != 1 {
+		t.Errorf("model analysis: AllergenAnalysesUsed = %d, want 1", got)
+	}
+}
+
+func TestAnalyzeRecipe_Handler_NoProvider(t *testing.T) {
+	user := testutil.TestUser()
+	user.Subscription = freeSubscription(user.ID, 0)
+	userRepo := testutil.NewMockUserRepo()
+	userRepo.Users[user.ID] = user
+	handler, recipeRepo := newAllergenHandlerFixture(&testutil.MockAllergenRepo{}, nil, userRepo)
+
+	r := gin.New()
+	r.POST("/recipes/:recipe_id/allergens/analyze", setUser(user), handler.AnalyzeRecipe)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/recipes/1/allergens/analyze", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("unclassified ingredient without a provider: status = %d, want 503", w.Code)
+	}
+
+	recipeRepo.Recipes[1].Ingredients = testutil.TestRecipe().Ingredients
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/recipes/1/allergens/analyze", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("rule-only without a provider: status = %d, want 200. body: %s", w.Code, w.Body.String())
+	}
+}
+
 func TestAnalyzeRecipe_Handler_PremiumBypassesLimit(t *testing.T) {
 	user := testutil.TestUser()
 	user.Subscription = &models.Subscription{
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+	"github.com/windoze95/saltybytes-api/internal/service"
+	"github.com/windoze95/saltybytes-api/internal/util"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CookLogHandler serves the "I made this" cook log: entries recording when a
+// recipe was cooked, with ratings, notes and photos, and per-recipe stats.
+type CookLogHandler struct {
+	Service *service.CookLogService
+}
+
+// NewCookLogHandler creates a new CookLogHandler.
+func NewCookLogHandler(svc *service.CookLogService) *CookLogHandler {
+	return &CookLogHandler{Service: svc}
+}
+
+// cookLogValidationErrors are the service errors reported as 400s.
+var cookLogValidationErrors = []error{
+	service.ErrInvalidCookRating,
+	service.ErrInvalidCookServings,
+	service.ErrCookLogNotesTooLong,
+	service.ErrCookedInFuture,
+	service.ErrCookLogNodeMismatch,
+	service.ErrTooManyCookPhotos,
+	service.ErrUnknownCookPhoto,
+}
+
+// respondCookLogError maps a cook log service error to a response. A
+// not-owned log, or a recipe the user can't see, is reported as not-found so
+// a user can't probe for other users' logs and recipes.
+func respondCookLogError(c *gin.Context, err error, action string, fields ...zap.Field) {
+	for _, target := range cookLogValidationErrors {
+		if errors.Is(err, target) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	var notFound repository.NotFoundError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, service.ErrCookLogNotOwned):
+		c.JSON(http.StatusNotFound, gin.H{"error": "cook log not found"})
+	case errors.As(err, &notFound), errors.Is(err, service.ErrCookLogRecipeHidden):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+	default:
+		logger.Get().Error("failed to "+action, append(fields, zap.Error(err))...)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to " + action})
+	}
+}
+
+// CreateCookLog handles POST /v1/cook-logs.
+func (h *CookLogHandler) CreateCookLog(c *gin.Context) {
+	user, err := util.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var request struct {
+		RecipeID uint      `json:"recipe_id" binding:"required"`
+		NodeID   *uint     `json:"node_id"`
+		CookedAt time.Time `json:"cooked_at"`
+		Servings int       `json:"servings"`
+		Rating   int       `json:"rating"`
+		Notes    string    `json:"notes"`
+		JoinCode string    `json:"join_code"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	log, err := h.Service.Log(c.Request.Context(), user.ID, service.CookLogInput{
+		RecipeID: request.RecipeID,
+		NodeID:   request.NodeID,
+		CookedAt: request.CookedAt,
+		Servings: request.Servings,
+		Rating:   request.Rating,
+		Notes:    request.Notes,
+		JoinCode: request.JoinCode,
+	})
+	if err != nil {
+		respondCookLogError(c, err, "create cook log", zap.Uint("recipe_id", request.RecipeID))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"cook_log": log})
+}
+
+// ListCookLogs handles GET /v1/cook-logs?recipe_id=&page=&page_size=.
+func (h *CookLogHandler) ListCookLogs(c *gin.Context) {
+	user, err := util.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var recipeID uint
+	if r := c.Query("recipe_id"); r != "" {
+		if recipeID, err = parseUintParam(r); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID"})
+			return
+		}
+	}
+	page := 1
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	pageSize := 20
+	if ps, err := strconv.Atoi(c.Query("page_size")); err == nil && ps > 0 && ps <= 100 {
+		pageSize = ps
+	}
+
+	logs, total, err := h.Service.List(c.Request.Context(), user.ID, recipeID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		logger.Get().Error("failed to list cook logs", zap.Uint("user_id", user.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list cook logs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cook_logs": logs, "total": total, "page": page, "page_size": pageSize})
+}
+
+// GetCookLog handles GET /v1/cook-logs/:cook_log_id.
+func (h *CookLogHandler) GetCookLog(c *gin.Context) {
+	user, err := util.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := parseUintParam(c.Param("cook_log_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cook log ID"})
+		return
+	}
+
+	log, err := h.Service.Get(c.Request.Context(), user.ID, id)
+	if err != nil {
+		respondCookLogError(c, err, "get cook log", zap.Uint("cook_log_id", id))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cook_log": log})
+}
+
+// UpdateCookLog handles PATCH /v1/cook-logs/:cook_log_id. Omitted fields
+// are left as they are; photo_urls can only drop photos.
+func (h *CookLogHandler) UpdateCookLog(c *gin.Context) {
+	user, err := util.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := parseUintParam(c.Param("cook_log_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cook log ID"})
+		return
+	}
+
+	var request struct {
+		CookedAt  *time.Time `json:"cooked_at"`
+		Servings  *int       `json:"servings"`
+		Rating    *int       `json:"rating"`
+		Notes     *string    `json:"notes"`
+		PhotoURLs []string   `json:"photo_urls"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	log, err := h.Service.Update(c.Request.Context(), user.ID, id, service.CookLogUpdate{
+		CookedAt:  request.CookedAt,
+		Servings:  request.Servings,
+		Rating:    request.Rating,
+		Notes:     request.Notes,
+		PhotoURLs: request.PhotoURLs,
+	})
+	if err != nil {
+		respondCookLogError(c, err, "update cook log", zap.Uint("cook_log_id", id))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cook_log": log})
+}
+
+// DeleteCookLog handles DELETE /v1/cook-logs/:cook_log_id.
+func (h *CookLogHandler) DeleteCookLog(c *gin.Context) {
+	user, err := util.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := parseUintParam(c.Param("cook_log_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cook log ID"})
+		return
+	}
+
+	if err := h.Service.Delete(c.Request.Context(), user.ID, id); err != nil {
+		respondCookLogError(c, err, "delete cook log", zap.Uint("cook_log_id", id))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "cook log deleted"})
+}
+
+// AddCookLogPhoto handles POST /v1/cook-logs/:cook_log_id/photos, a
+// multipart upload of one "image" (jpg, png or webp, up to 10MB).
+func (h *CookLogHandler) AddCookLogPhoto(c *gin.Context) {
+	user, err := util.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := parseUintParam(c.Param("cook_log_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cook log ID"})
+		return
+	}
+
+	file, header, err := c.Request.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Image file is required"})
+		return
+	}
+	defer file.Close()
+
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	contentType, ok := allowedImageTypes[ext]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported image type. Allowed: jpg, png, webp"})
+		return
+	}
+	const maxSize = 10 << 20
+	if header.Size > maxSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Image exceeds maximum size of 10MB"})
+		return
+	}
+	imgBytes, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image"})
+		return
+	}
+
+	log, err := h.Service.AddPhoto(c.Request.Context(), user.ID, id, imgBytes, ext, contentType)
+	if err != nil {
+		respondCookLogError(c, err, "add cook log photo", zap.Uint("cook_log_id", id))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"cook_log": log})
+}
+
+// GetCookStats handles GET /v1/recipes/:recipe_id/cook-stats: how many
+// times the recipe has been cooked, its average rating and when it was last
+// cooked, across everyone who logged it.
+func (h *CookLogHandler) GetCookStats(c *gin.Context) {
+	recipeID, err := parseUintParam(c.Param("recipe_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	stats, err := h.Service.Stats(c.Request.Context(), recipeID)
+	if err != nil {
+		logger.Get().Error("failed to get cook stats", zap.Uint("recipe_id", recipeID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get cook stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cook_stats": stats})
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+	"github.com/windoze95/saltybytes-api/internal/service"
+	"github.com/windoze95/saltybytes-api/internal/util"
+	"go.uber.org/zap"
+)
+
+// cookingSaveTimeout bounds saving a session's edits, which includes a
+// main-tier merge.
+const cookingSaveTimeout = 2 * time.Minute
+
+// CookingSessionHandler serves the "resume cooking" list of a user's
+// unexpired cooking sessions, saves a session's edits to the recipe tree
+// when they finish cooking, and hands out join codes for others to cook
+// along in the owner's room.
+type CookingSessionHandler struct {
+	Service *service.CookingSessionService
+	// Tree saves a session's edits as a recipe node; nil disables SaveSession.
+	Tree *service.RecipeTreeService
+	// Recipes looks up the recipe being shared; nil disables Invite.
+	Recipes repository.RecipeRepo
+	// CookLogs drafts a cook log when a session ends; nil leaves it out.
+	CookLogs *service.CookLogService
+	// SubService gates SaveSession's merge by AI-generation usage; nil skips
+	// gating.
+	SubService *service.SubscriptionService
+}
+
+// NewCookingSessionHandler creates a new CookingSessionHandler.
+func NewCookingSessionHandler(svc *service.CookingSessionService) *CookingSessionHandler {
+	return &CookingSessionHandler{Service: svc}
+}
+
+// ListSessions handles GET /v1/cooking/sessions.
+func (h *CookingSessionHandler) ListSessions(c *gin.Context) {
+	user, err := util.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sessions, err := h.Service.ListActive(c.Request.Context(), user.ID)
+	if err != nil {
+		logger.Get().Error("failed to list cooking sessions", zap.Uint("user_id", user.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// EndSession handles DELETE /v1/cooking/sessions/:recipe_id. The response
+// offers a cook log draft for the session, to confirm via POST /v1/cook-logs.
+func (h *CookingSessionHandler) EndSession(c *gin.Context) {
+	user, err := util.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	recipeID, err := strconv.ParseUint(c.Param("recipe_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID"})
+		return
+	}
+
+	var draft *service.CookLogDraft
+	if h.CookLogs != nil {
+		if session, err := h.Service.Get(c.Request.Context(), user.ID, uint(recipeID)); err == nil && session != nil {
+			d := h.CookLogs.Draft(session)
+			draft = &d
+		}
+	}
+
+	if err := h.Service.End(c.Request.Context(), user.ID, uint(recipeID)); err != nil {
+		logger.Get().Error("failed to end cooking session", zap.Uint("recipe_id", uint(recipeID)), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to end session"})
+		return
+	}
+
+	response := gin.H{"message": "session ended"}
+	if draft != nil {
+		response["cook_log_draft"] = draft
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// SaveSession handles POST /v1/cooking/sessions/:recipe_id/save. The
+// session's ephemeral edits are merged into the recipe and saved as a new
+// node of its tree: the new active version, or with "ephemeral" set, a node
+// to promote or discard later. The session ends once saved, and like
+// EndSession the response offers a cook log draft, for the saved node.
+func (h *CookingSessionHandler) SaveSession(c *gin.Context) {
+	user, err := util.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	recipeID, err := strconv.ParseUint(c.Param("recipe_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID"})
+		return
+	}
+
+	var request struct {
+		BranchName string `json:"branch_name"`
+		Ephemeral  bool   `json:"ephemeral"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if h.Tree == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "saving cooking edits is not available"})
+		return
+	}
+
+	recipe, err := h.Tree.Repo.GetRecipeByID(uint(recipeID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+		return
+	}
+	if recipe.CreatedByID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only modify your own recipes"})
+		return
+	}
+
+	session, err := h.Service.Get(c.Request.Context(), user.ID, recipe.ID)
+	if err != nil {
+		logger.Get().Error("failed to get cooking session", zap.Uint("recipe_id", recipe.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get session"})
+		return
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no active cooking session"})
+		return
+	}
+
+	// Saving runs a main-tier merge, so it counts as an AI generation like
+	// any other regeneration of the recipe.
+	if h.SubService != nil {
+		allowed, err := h.SubService.CheckLimit(user.ID, "ai_generation")
+		if err != nil {
+			logger.Get().Error("failed to check AI generation limit", zap.Uint("user_id", user.ID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check subscription limits"})
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "AI generation limit reached; upgrade to premium for unlimited generations"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), cookingSaveTimeout)
+	defer cancel()
+	node, err := h.Tree.SaveCookingEdits(ctx, user, recipe, session.Edits, request.BranchName, request.Ephemeral)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNoCookingEdits):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrCookingMergeDisabled):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			logger.Get().Error("failed to save cooking edits", zap.Uint("recipe_id", recipe.ID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save cooking edits"})
+		}
+		return
+	}
+	if h.SubService != nil {
+		if err := h.SubService.IncrementUsage(user.ID, "ai_generation"); err != nil {
+			logger.Get().Error("failed to increment AI generation usage", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+	}
+
+	if err := h.Service.End(c.Request.Context(), user.ID, recipe.ID); err != nil {
+		logger.Get().Warn("failed to end saved cooking session", zap.Uint("recipe_id", recipe.ID), zap.Error(err))
+	}
+
+	response := gin.H{"node": node}
+	if h.CookLogs != nil {
+		draft := h.CookLogs.Draft(session)
+		draft.NodeID = &node.ID
+		if node.Response != nil && node.Response.Title != "" {
+			draft.RecipeTitle = node.Response.Title
+		}
+		response["cook_log_draft"] = draft
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// Invite handles POST /v1/cooking/sessions/:recipe_id/invite. The owner gets
+// a short code that lets anyone they share it with join their cooking room
+// (the cooking WebSocket's "code" query parameter) until it expires. Family
+// members with their own account can join without one.
+func (h *CookingSessionHandler) Invite(c *gin.Context) {
+	user, err := util.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	recipeID, err := strconv.ParseUint(c.Param("recipe_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID"})
+		return
+	}
+
+	if h.Recipes == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "cooking invites are not available"})
+		return
+	}
+
+	recipe, err := h.Recipes.GetRecipeByID(uint(recipeID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+		return
+	}
+	if recipe.CreatedByID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only invite others to cook your own recipes"})
+		return
+	}
+
+	session, err := h.Service.CreateJoinCode(c.Request.Context(), user.ID, recipe)
+	if err != nil {
+		logger.Get().Error("failed to create cooking join code", zap.Uint("recipe_id", recipe.ID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create invite"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"join_code":  session.JoinCode,
+		"expires_at": session.JoinCodeExpiresAt,
+		"recipe_id":  recipe.ID,
+	})
+}
+
+// ResolveJoinCode handles GET /v1/cooking/join/:code, telling a guest which
+// recipe's room a join code opens so they can connect to it.
+func (h *CookingSessionHandler) ResolveJoinCode(c *gin.Context) {
+	session, err := h.Service.ResolveJoinCode(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidJoinCode) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Get().Error("failed to resolve cooking join code", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve join code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recipe_id":    session.RecipeID,
+		"recipe_title": session.RecipeTitle,
+		"host_id":      session.UserID,
+		"expires_at":   session.JoinCodeExpiresAt,
+	})
+}
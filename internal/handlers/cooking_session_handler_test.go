@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/windoze95/saltybytes-api/internal/config"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/service"
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestSaveSession_FreeUserAtLimit_403(t *testing.T) {
+	user := testutil.TestUser()
+	user.Subscription = &models.Subscription{
+		Model:             gorm.Model{ID: 1},
+		UserID:            user.ID,
+		Tier:              models.TierFree,
+		AIGenerationsUsed: 50,
+		MonthlyResetAt:    time.Now().Add(time.Hour),
+	}
+	userRepo := testutil.NewMockUserRepo()
+	userRepo.Users[user.ID] = user
+
+	recipe := testutil.TestRecipe()
+	recipe.CreatedByID = user.ID
+	recipeRepo := testutil.NewMockRecipeRepo()
+	recipeRepo.Recipes[recipe.ID] = recipe
+
+	sessionRepo := testutil.NewMockCookingSessionRepo()
+	if _, err := sessionRepo.Modify(context.Background(), user.ID, recipe.ID, func(s *models.CookingSession) error {
+		s.Edits = models.CookingEditList{{Modification: "less salt"}}
+		return nil
+	}); err != nil {
+		t.Fatalf("seed session: %v", err)
+	}
+
+	handler := NewCookingSessionHandler(service.NewCookingSessionService(sessionRepo))
+	handler.Tree = service.NewRecipeTreeService(&config.Config{}, recipeRepo)
+	handler.SubService = service.NewSubscriptionService(&config.Config{}, userRepo)
+
+	r := gin.New()
+	r.POST("/cooking/sessions/:recipe_id/save", setUser(user), handler.SaveSession)
+
+	req := httptest.NewRequest("POST", "/cooking/sessions/1/save", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d. body: %s", w.Code, http.StatusForbidden, w.Body.String())
+	}
+}
@@ -3,8 +3,10 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,6 +14,7 @@ import (
 	"github.com/windoze95/saltybytes-api/internal/service"
 	"github.com/windoze95/saltybytes-api/internal/util"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // FinderHandler streams a guided recipe-finder run over SSE.
@@ -38,15 +41,67 @@ func (h *FinderHandler) FindRecipes(c *gin.Context) {
 		return
 	}
 
+	h.streamFinder(c, user.ID, func(ctx context.Context, events chan<- service.FinderEvent) {
+		h.Service.FindRecipes(ctx, user, req, events)
+	})
+}
+
+// ContinueSession handles POST /v1/recipes/finder/sessions/:session_id/turns.
+// It runs one follow-up turn of a saved find — continuing from the session's
+// facets, shown results and dismissed URLs — and streams it exactly like
+// FindRecipes. The done event carries the session ID.
+func (h *FinderHandler) ContinueSession(c *gin.Context) {
+	user, err := util.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("session_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session ID"})
+		return
+	}
+
+	var turn service.FinderTurnRequest
+	if err := c.BindJSON(&turn); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if h.Service.Sessions == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "finder sessions are not available"})
+		return
+	}
+	session, err := h.Service.Sessions.Get(c.Request.Context(), user.ID, uint(id))
+	if err != nil {
+		// Not-owned is reported as not-found, like GetSession.
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrFinderSessionNotOwned) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+			return
+		}
+		logger.Get().Error("failed to get finder session", zap.Uint("session_id", uint(id)), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get session"})
+		return
+	}
+
+	h.streamFinder(c, user.ID, func(ctx context.Context, events chan<- service.FinderEvent) {
+		h.Service.ContinueSession(ctx, user, session, turn, events)
+	})
+}
+
+// streamFinder gates a finder run by the search limit and streams its events
+// as SSE until a terminal event.
+func (h *FinderHandler) streamFinder(c *gin.Context, userID uint, run func(ctx context.Context, events chan<- service.FinderEvent)) {
 	// Subscription gate. The finder drives search, so it is gated by the same
 	// "search" limit as the search endpoint. SearchService.SearchRecipes does
 	// NOT check or increment usage itself — all gating lives in the search
 	// handler — so we gate here exactly once and increment once for a
 	// non-cached search below, avoiding any double counting.
 	if h.SubService != nil {
-		allowed, err := h.SubService.CheckLimit(user.ID, "search")
+		allowed, err := h.SubService.CheckLimit(userID, "search")
 		if err != nil {
-			logger.Get().Error("failed to check search limit", zap.Uint("user_id", user.ID), zap.Error(err))
+			logger.Get().Error("failed to check search limit", zap.Uint("user_id", userID), zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check subscription limits"})
 			return
 		}
@@ -70,7 +125,7 @@ func (h *FinderHandler) FindRecipes(c *gin.Context) {
 	go func() {
 		defer close(events)
 		defer util.RecoverPanic("recipe finder")
-		h.Service.FindRecipes(ctx, user, req, events)
+		run(ctx, events)
 	}()
 
 	c.Stream(func(w io.Writer) bool {
@@ -85,7 +140,7 @@ func (h *FinderHandler) FindRecipes(c *gin.Context) {
 			// "found" event carries whether results came from cache, and it is
 			// emitted exactly once per run, so this counts at most once.
 			if event.Type == service.FinderEventFound && !event.FromCache {
-				h.incrementSearchUsage(user.ID)
+				h.incrementSearchUsage(userID)
 			}
 			data, _ := json.Marshal(event)
 			c.SSEvent(string(event.Type), string(data))
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -19,6 +20,9 @@ type RecipeHandler struct {
 	// SubService gates the AI-generation endpoints by subscription usage
 	// when set (nil skips gating, e.g. in isolated tests).
 	SubService *service.SubscriptionService
+	// Substitutions proposes and applies ingredient swaps; nil disables
+	// the substitution endpoints.
+	Substitutions *service.SubstitutionService
 }
 
 // NewRecipeHandler is the constructor function for initializing a new RecipeHandler.
@@ -57,7 +61,9 @@ func (h *RecipeHandler) incrementAIGenerationUsage(userID uint) {
 	}
 }
 
-// ListRecipes returns a paginated list of the authenticated user's recipes.
+// ListRecipes returns a paginated list of the authenticated user's recipes,
+// newest first or ordered by cook history ("sort": last_cooked, most_cooked,
+// top_rated).
 func (h *RecipeHandler) ListRecipes(c *gin.Context) {
 	user, err := util.GetUserFromContext(c)
 	if err != nil {
@@ -88,7 +94,11 @@ func (h *RecipeHandler) ListRecipes(c *gin.Context) {
 	if len([]rune(q)) >= 2 {
 		recipes, total, err = h.Service.SearchUserRecipes(c.Request.Context(), user.ID, q, page, pageSize)
 	} else {
-		recipes, total, err = h.Service.GetUserRecipes(user.ID, page, pageSize)
+		recipes, total, err = h.Service.ListUserRecipes(c.Request.Context(), user.ID, c.Query("sort"), page, pageSize)
+	}
+	if errors.Is(err, service.ErrInvalidRecipeSort) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 	if err != nil {
 		logger.Get().Error("failed to list recipes", zap.Uint("user_id", user.ID), zap.Error(err))
@@ -171,7 +181,7 @@ func (h *RecipeHandler) RegenerateRecipe(c *gin.Context) {
 	}
 
 	prompt := strings.TrimSpace(request.UserPrompt)
-	err = h.Service.InitRegenerateRecipe(user, recipeID, prompt, genImage)
+	err = h.Service.InitRegenerateRecipe(c.Request.Context(), user, recipeID, prompt, genImage)
 	if err != nil {
 		logger.Get().Error("failed to initialize recipe regeneration", zap.Uint("recipe_id", recipeID), zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "An unexpected error occurred while initializing generation"})
@@ -225,7 +235,7 @@ func (h *RecipeHandler) GenerateRecipeWithFork(c *gin.Context) {
 	}
 
 	prompt := strings.TrimSpace(request.UserPrompt)
-	recipeResponse, err := h.Service.InitGenerateRecipeWithFork(user, recipeID, prompt, genImage)
+	recipeResponse, err := h.Service.InitGenerateRecipeWithFork(c.Request.Context(), user, recipeID, prompt, genImage)
 	if err != nil {
 		logger.Get().Error("failed to initialize recipe fork", zap.Uint("recipe_id", recipeID), zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "An unexpected error occurred while initializing generation"})
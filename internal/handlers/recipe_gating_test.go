@@ -78,3 +78,31 @@ func TestForkRecipe_FreeUserAtLimit_403(t *testing.T) {
 		t.Errorf("status = %d, want %d. body: %s", w.Code, http.StatusForbidden, w.Body.String())
 	}
 }
+
+func TestSuggestSubstitutions_FreeUserAtLimit_403(t *testing.T) {
+	user := testutil.TestUser()
+	user.Subscription = &models.Subscription{
+		Model:             gorm.Model{ID: 1},
+		UserID:            user.ID,
+		Tier:              models.TierFree,
+		AIGenerationsUsed: 50,
+		MonthlyResetAt:    time.Now().Add(time.Hour),
+	}
+	userRepo := testutil.NewMockUserRepo()
+	userRepo.Users[user.ID] = user
+
+	handler, recipeRepo := newGatedRecipeHandler(userRepo)
+	handler.Substitutions = service.NewSubstitutionService(&testutil.MockTextProvider{}, recipeRepo, &testutil.MockFamilyRepo{}, handler.Service)
+
+	r := gin.New()
+	r.POST("/recipes/:recipe_id/substitutions", setUser(user), handler.SuggestSubstitutions)
+
+	req := httptest.NewRequest("POST", "/recipes/1/substitutions", strings.NewReader(`{"avoid": ["eggs"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d. body: %s", w.Code, http.StatusForbidden, w.Body.String())
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -168,3 +169,104 @@ func (h *RecipeTreeHandler) SetActiveNode(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Active node updated"})
 }
+
+// PromoteNode makes an ephemeral node a permanent part of the tree and the
+// recipe's active version.
+// PUT /v1/recipes/:recipe_id/tree/nodes/:node_id/promote
+func (h *RecipeTreeHandler) PromoteNode(c *gin.Context) {
+	user, err := util.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	recipeIDStr := c.Param("recipe_id")
+	recipeID, err := parseUintParam(recipeIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	recipe, err := h.Service.Repo.GetRecipeByID(recipeID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+		return
+	}
+	if recipe.CreatedByID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only modify your own recipes"})
+		return
+	}
+
+	nodeIDStr := c.Param("node_id")
+	nodeID, err := parseUintParam(nodeIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid node ID"})
+		return
+	}
+
+	node, err := h.Service.PromoteNode(recipe, nodeID)
+	if err != nil {
+		respondNodeError(c, "failed to promote node", recipeIDStr, nodeIDStr, err, "Failed to promote node")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"node": node})
+}
+
+// DiscardNode deletes an ephemeral node that nothing builds on.
+// DELETE /v1/recipes/:recipe_id/tree/nodes/:node_id
+func (h *RecipeTreeHandler) DiscardNode(c *gin.Context) {
+	user, err := util.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	recipeIDStr := c.Param("recipe_id")
+	recipeID, err := parseUintParam(recipeIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	recipe, err := h.Service.Repo.GetRecipeByID(recipeID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+		return
+	}
+	if recipe.CreatedByID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only modify your own recipes"})
+		return
+	}
+
+	nodeIDStr := c.Param("node_id")
+	nodeID, err := parseUintParam(nodeIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid node ID"})
+		return
+	}
+
+	if err := h.Service.DiscardNode(recipeID, nodeID); err != nil {
+		respondNodeError(c, "failed to discard node", recipeIDStr, nodeIDStr, err, "Failed to discard node")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Node discarded"})
+}
+
+// respondNodeError maps an ephemeral-node operation error to a response.
+func respondNodeError(c *gin.Context, logMsg, recipeIDStr, nodeIDStr string, err error, fallback string) {
+	var notFound repository.NotFoundError
+	switch {
+	case errors.As(err, &notFound), errors.Is(err, service.ErrNodeNotInTree):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Node not found"})
+	case errors.Is(err, service.ErrNodeNotEphemeral), errors.Is(err, service.ErrEphemeralNodeInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.Get().Error(logMsg,
+			zap.String("recipe_id", recipeIDStr),
+			zap.String("node_id", nodeIDStr),
+			zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
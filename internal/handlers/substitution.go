@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+	"github.com/windoze95/saltybytes-api/internal/service"
+	"github.com/windoze95/saltybytes-api/internal/util"
+	"go.uber.org/zap"
+)
+
+// substitutionValidationErrors are the service errors reported as 400s.
+var substitutionValidationErrors = []error{
+	service.ErrNoSubstitutionConstraints,
+	service.ErrTooManySubstitutionInputs,
+	service.ErrRecipeHasNoIngredients,
+	service.ErrNoSubstitutions,
+	service.ErrTooManySubstitutions,
+	service.ErrUnknownSubstitutionIngredient,
+}
+
+// respondSubstitutionError maps a substitution service error to a response.
+func respondSubstitutionError(c *gin.Context, err error, action string, recipeID uint) {
+	for _, target := range substitutionValidationErrors {
+		if errors.Is(err, target) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	var notFound repository.NotFoundError
+	switch {
+	case errors.As(err, &notFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+	case errors.Is(err, service.ErrSubstitutionMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSubstitutionsUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		logger.Get().Error("failed to "+action, zap.Uint("recipe_id", recipeID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to " + action})
+	}
+}
+
+// SuggestSubstitutions handles POST /v1/recipes/:recipe_id/substitutions:
+// ingredient swaps for family members' dietary profiles ("member_ids") and
+// ad-hoc constraints ("avoid", e.g. "no eggs"), preferring "pantry" items.
+// Each proposal counts as an AI generation.
+func (h *RecipeHandler) SuggestSubstitutions(c *gin.Context) {
+	user, err := util.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	recipeID, err := parseUintParam(c.Param("recipe_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	var request service.SubstitutionConstraints
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if h.Substitutions == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "substitutions are not available"})
+		return
+	}
+
+	if !h.checkAIGenerationLimit(c, user.ID) {
+		return
+	}
+
+	proposal, err := h.Substitutions.Propose(c.Request.Context(), user.ID, recipeID, request)
+	if err != nil {
+		respondSubstitutionError(c, err, "suggest substitutions", recipeID)
+		return
+	}
+
+	h.incrementAIGenerationUsage(user.ID)
+
+	c.JSON(http.StatusOK, gin.H{"proposal": proposal})
+}
+
+// ApplySubstitutions handles POST /v1/recipes/:recipe_id/substitutions/apply:
+// forks the recipe with the chosen swaps, like POST /v1/recipes/:recipe_id/fork.
+func (h *RecipeHandler) ApplySubstitutions(c *gin.Context) {
+	user, err := util.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	recipeID, err := parseUintParam(c.Param("recipe_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	var request struct {
+		Substitutions []service.Substitution `json:"substitutions"`
+		GenImage      *bool                  `json:"gen_image"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	genImage := request.GenImage == nil || *request.GenImage
+
+	if h.Substitutions == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "substitutions are not available"})
+		return
+	}
+
+	if !h.checkAIGenerationLimit(c, user.ID) {
+		return
+	}
+
+	recipeResponse, err := h.Substitutions.Apply(c.Request.Context(), user, recipeID, request.Substitutions, genImage)
+	if err != nil {
+		respondSubstitutionError(c, err, "apply substitutions", recipeID)
+		return
+	}
+
+	h.incrementAIGenerationUsage(user.ID)
+
+	c.JSON(http.StatusOK, gin.H{"recipe": recipeResponse, "message": "Applying substitutions"})
+}
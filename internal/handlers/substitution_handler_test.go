@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+)
+
+func TestSubstitutions_DisabledIs503(t *testing.T) {
+	user := testutil.TestUser()
+	handler := NewRecipeHandler(newRecipeService(testutil.NewMockRecipeRepo()))
+
+	r := gin.New()
+	r.POST("/recipes/:recipe_id/substitutions", setUser(user), handler.SuggestSubstitutions)
+	r.POST("/recipes/:recipe_id/substitutions/apply", setUser(user), handler.ApplySubstitutions)
+
+	for path, body := range map[string]string{
+		"/recipes/1/substitutions":       `{"avoid": ["eggs"]}`,
+		"/recipes/1/substitutions/apply": `{"substitutions": [{"ingredient": "2 eggs", "replacement": "2 tbsp flax"}]}`,
+	} {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("POST %s: status = %d, want %d. body: %s", path, w.Code, http.StatusServiceUnavailable, w.Body.String())
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/windoze95/saltybytes-api/internal/allergens"
 	"github.com/windoze95/saltybytes-api/internal/logger"
 	"github.com/windoze95/saltybytes-api/internal/models"
 	"github.com/windoze95/saltybytes-api/internal/repository"
@@ -362,6 +363,9 @@ func (h *UserHandler) UpdatePersonalization(c *gin.Context) {
 		UnitSystem     *string `json:"unit_system"`
 		Requirements   *string `json:"requirements"`
 		CookingContext *string `json:"cooking_context"`
+		Locale         *string `json:"locale"`
+		Region         *string `json:"region"`
+		AllergenSet    *string `json:"allergen_set"`
 		UID            *string `json:"uid"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -378,6 +382,9 @@ func (h *UserHandler) UpdatePersonalization(c *gin.Context) {
 		UnitSystem:     req.UnitSystem,
 		Requirements:   req.Requirements,
 		CookingContext: req.CookingContext,
+		Locale:         req.Locale,
+		Region:         req.Region,
+		AllergenSet:    req.AllergenSet,
 	}
 	if req.UID != nil {
 		uid, parseErr := uuid.Parse(*req.UID)
@@ -389,6 +396,10 @@ func (h *UserHandler) UpdatePersonalization(c *gin.Context) {
 	}
 
 	if err := h.Service.UpdatePersonalization(user, update); err != nil {
+		if errors.Is(err, allergens.ErrUnknownSet) || errors.Is(err, models.ErrInvalidLocale) || errors.Is(err, models.ErrInvalidRegion) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		logger.Get().Error("failed to update personalization", zap.Uint("user_id", user.ID), zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update personalization"})
 		return
@@ -482,3 +482,66 @@ func makeLegacyRefreshToken(t *testing.T, userID uint) string {
 	}
 	return s
 }
+
+func TestUpdatePersonalization_LocaleAndRegion(t *testing.T) {
+	repo := testutil.NewMockUserRepo()
+	user := testutil.TestUser()
+	repo.Users[user.ID] = user
+
+	r, _ := newPersonalizationTestRouter(repo, user)
+
+	body := `{"locale": "en_gb", "region": "gb"}`
+	req := httptest.NewRequest("PUT", "/users/me/personalization", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d. body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	p := repo.Users[user.ID].Personalization
+	if p.Locale != "en-GB" || p.Region != "GB" {
+		t.Errorf("Locale/Region = %q/%q, want normalized en-GB/GB", p.Locale, p.Region)
+	}
+
+	for _, body := range []string{`{"region": "Britain"}`, `{"locale": "english"}`} {
+		req = httptest.NewRequest("PUT", "/users/me/personalization", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+	if p := repo.Users[user.ID].Personalization; p.Locale != "en-GB" || p.Region != "GB" {
+		t.Errorf("rejected update changed Locale/Region to %q/%q", p.Locale, p.Region)
+	}
+}
+
+func TestUpdatePersonalization_AllergenSet(t *testing.T) {
+	repo := testutil.NewMockUserRepo()
+	user := testutil.TestUser()
+	repo.Users[user.ID] = user
+
+	r, _ := newPersonalizationTestRouter(repo, user)
+
+	req := httptest.NewRequest("PUT", "/users/me/personalization", strings.NewReader(`{"allergen_set": "EU_14"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d. body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if got := repo.Users[user.ID].Personalization.AllergenSet; got != "eu_14" {
+		t.Errorf("AllergenSet = %q, want normalized eu_14", got)
+	}
+
+	req = httptest.NewRequest("PUT", "/users/me/personalization", strings.NewReader(`{"allergen_set": "top8"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unknown set: status = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,146 @@
+// Package imaging turns one source image (a generated PNG, an uploaded photo)
+// into the resized variants the app actually downloads: a list-view thumbnail,
+// a card image and a full-size image, each encoded as both WebP and JPEG, plus
+// a blurhash placeholder to paint while they load.
+//
+// Variants only ever scale down: a source smaller than a variant's bound is
+// encoded at its own size rather than upscaled.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png" // decode generated and uploaded PNGs
+
+	"github.com/buckket/go-blurhash"
+	"github.com/chai2010/webp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // decode uploaded WebP
+)
+
+// Variant names, in ascending size.
+const (
+	Thumb = "thumb"
+	Card  = "card"
+	Full  = "full"
+)
+
+// Size bounds the longest edge of each variant, in pixels.
+var Size = map[string]int{
+	Thumb: 320,
+	Card:  768,
+	Full:  1536,
+}
+
+// Names lists the variants in the order Process returns them.
+var Names = []string{Thumb, Card, Full}
+
+const (
+	// jpegQuality and webpQuality trade size for artifacts; food photos hold
+	// up well at these settings.
+	jpegQuality = 82
+	webpQuality = 80
+	// Blurhash component counts: 4x3 is the blurhash authors' default, enough
+	// to suggest a plate's layout in a ~28-character string.
+	blurhashX = 4
+	blurhashY = 3
+	// maxSourcePixels refuses decompression bombs before decoding.
+	maxSourcePixels = 40_000_000
+)
+
+// ErrUnsupportedImage is returned for bytes that don't decode as PNG, JPEG or
+// WebP.
+var ErrUnsupportedImage = errors.New("unsupported image format")
+
+// Variant is one resized, encoded rendition of a source image.
+type Variant struct {
+	Name   string
+	Width  int
+	Height int
+	WebP   []byte
+	JPEG   []byte
+}
+
+// Result is every variant of one source image plus its blurhash.
+type Result struct {
+	Variants []Variant
+	Blurhash string
+}
+
+// Process decodes src and renders every variant. It is CPU-bound and meant to
+// run off the request path, where recipe images are generated.
+func Process(src []byte) (*Result, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if cfg.Width*cfg.Height > maxSourcePixels {
+		return nil, fmt.Errorf("image is %dx%d, over the %d pixel limit", cfg.Width, cfg.Height, maxSourcePixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+
+	res := &Result{Variants: make([]Variant, 0, len(Names))}
+	for _, name := range Names {
+		scaled := fit(img, Size[name])
+		v := Variant{Name: name, Width: scaled.Bounds().Dx(), Height: scaled.Bounds().Dy()}
+		if v.WebP, err = encodeWebP(scaled); err != nil {
+			return nil, fmt.Errorf("encode %s webp: %w", name, err)
+		}
+		if v.JPEG, err = encodeJPEG(scaled); err != nil {
+			return nil, fmt.Errorf("encode %s jpeg: %w", name, err)
+		}
+		res.Variants = append(res.Variants, v)
+		if name == Thumb {
+			// Hashing the thumbnail is as good as hashing the source and far cheaper.
+			if res.Blurhash, err = blurhash.Encode(blurhashX, blurhashY, scaled); err != nil {
+				return nil, fmt.Errorf("blurhash: %w", err)
+			}
+		}
+	}
+	return res, nil
+}
+
+// fit scales img so its longest edge is at most bound, preserving the aspect
+// ratio. A source already within bound is copied as-is.
+func fit(img image.Image, bound int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > bound || h > bound {
+		if w >= h {
+			h = max(1, h*bound/w)
+			w = bound
+		} else {
+			w = max(1, w*bound/h)
+			h = bound
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if w == b.Dx() && h == b.Dy() {
+		draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	} else {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	}
+	return dst
+}
+
+func encodeWebP(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := webp.Encode(&buf, img, &webp.Options{Quality: webpQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,70 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/chai2010/webp"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 255 / w), G: uint8(y * 255 / h), B: 90, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcess_RendersEveryVariantInBothFormats(t *testing.T) {
+	res, err := Process(testPNG(t, 1024, 1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{Thumb: 320, Card: 768, Full: 1024} // full never upscales
+	if len(res.Variants) != len(Names) {
+		t.Fatalf("got %d variants, want %d", len(res.Variants), len(Names))
+	}
+	for _, v := range res.Variants {
+		if v.Width != want[v.Name] || v.Height != want[v.Name] {
+			t.Errorf("%s = %dx%d, want %d square", v.Name, v.Width, v.Height, want[v.Name])
+		}
+		if cfg, err := jpeg.DecodeConfig(bytes.NewReader(v.JPEG)); err != nil || cfg.Width != v.Width {
+			t.Errorf("%s jpeg does not decode to its width: %v", v.Name, err)
+		}
+		if cfg, err := webp.DecodeConfig(bytes.NewReader(v.WebP)); err != nil || cfg.Width != v.Width {
+			t.Errorf("%s webp does not decode to its width: %v", v.Name, err)
+		}
+	}
+	if len(res.Blurhash) < 6 {
+		t.Errorf("blurhash = %q, want a placeholder", res.Blurhash)
+	}
+}
+
+func TestProcess_PreservesAspectRatio(t *testing.T) {
+	res, err := Process(testPNG(t, 1000, 500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	thumb := res.Variants[0]
+	if thumb.Width != 320 || thumb.Height != 160 {
+		t.Errorf("thumb = %dx%d, want 320x160", thumb.Width, thumb.Height)
+	}
+}
+
+func TestProcess_RejectsNonImages(t *testing.T) {
+	if _, err := Process([]byte("definitely not an image")); !errors.Is(err, ErrUnsupportedImage) {
+		t.Errorf("err = %v, want ErrUnsupportedImage", err)
+	}
+}
@@ -22,6 +22,7 @@ var widgetHTML string
 // serverInstructions is surfaced to connected MCP hosts to guide tool use.
 const serverInstructions = `SaltyBytes finds REAL recipes from around the web and manages the user's saved recipe collection.
 Typical flow: search_recipes to find candidates -> preview_recipe on the chosen result -> save_recipe when the user wants to keep it.
+For household-aware suggestions the user wants to iterate on, use find_recipes and pass its session_id back on each follow-up instead of searching again.
 Every tool renders an interactive widget in the conversation; prefer letting the widget present recipe details instead of restating them in text.`
 
 // widgetResourceMeta declares the widget's MCP Apps metadata (CSP etc.).
@@ -195,7 +195,7 @@ func TestServerLifecycle_ToolsAndWidget(t *testing.T) {
 	}
 	wantTools := map[string]bool{
 		"search_recipes": false, "preview_recipe": false, "save_recipe": false,
-		"list_my_recipes": false, "get_recipe": false,
+		"list_my_recipes": false, "get_recipe": false, "find_recipes": false,
 	}
 	for _, tool := range tools.Tools {
 		if _, known := wantTools[tool.Name]; known {
@@ -238,3 +238,51 @@ func TestServerLifecycle_ToolsAndWidget(t *testing.T) {
 		t.Fatal("expected IsError for unauthenticated tool call")
 	}
 }
+
+func TestFindRecipes_StartsThenRefinesSession(t *testing.T) {
+	deps, _, _ := newTestDeps(t)
+	var lastRank ai.FinderRankRequest
+	ranker := &testutil.MockTextProvider{
+		ExpandAndRankRecipesFunc: func(ctx context.Context, req ai.FinderRankRequest) (*ai.FinderRankResult, error) {
+			lastRank = req
+			ranked := make([]ai.FinderRanking, len(req.Candidates))
+			for i, c := range req.Candidates {
+				ranked[i] = ai.FinderRanking{Index: c.Index, Reason: "fits"}
+			}
+			return &ai.FinderRankResult{Ranked: ranked}, nil
+		},
+	}
+	finder := service.NewRecipeFinderService(&config.Config{}, deps.Search, &testutil.MockFamilyRepo{}, service.NewWarmService(nil, nil, 0, 0), ranker)
+	finder.Sessions = service.NewFinderSessionService(testutil.NewMockFinderSessionRepo())
+	deps.Finder = finder
+
+	_, out, err := deps.findRecipes(context.Background(), reqWithScopes("search"), findRecipesIn{Request: "salmon dinner"})
+	if err != nil {
+		t.Fatalf("findRecipes: %v", err)
+	}
+	if out.SessionID == 0 || len(out.Results) != 2 {
+		t.Fatalf("first find = %+v, want a session and 2 results", out)
+	}
+
+	_, out, err = deps.findRecipes(context.Background(), reqWithScopes("search"), findRecipesIn{
+		Request:   "no fish",
+		SessionID: out.SessionID,
+		Dismiss:   []string{"https://example.com/salmon"},
+	})
+	if err != nil {
+		t.Fatalf("refine: %v", err)
+	}
+	if len(out.Results) != 1 || out.Results[0].Title != "Weeknight Ramen" {
+		t.Fatalf("refined results = %+v, want only the ramen", out.Results)
+	}
+	if !strings.Contains(out.Query, "-fish") {
+		t.Errorf("refined query %q should exclude fish", out.Query)
+	}
+	if !strings.Contains(lastRank.Conversation, "no fish") {
+		t.Errorf("ranker conversation missing the follow-up: %q", lastRank.Conversation)
+	}
+
+	if _, _, err := deps.findRecipes(context.Background(), reqWithScopes("search"), findRecipesIn{Request: "x", SessionID: 999}); err == nil {
+		t.Fatal("expected error for an unknown session")
+	}
+}
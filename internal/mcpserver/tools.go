@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/windoze95/saltybytes-api/internal/ai"
@@ -39,6 +40,13 @@ type Deps struct {
 	Import        *service.ImportService
 	MultiResolver *service.MultiRecipeResolver
 	Subs          *service.SubscriptionService
+	Finder        *service.RecipeFinderService
+}
+
+// attributeTo tags ctx so the AI calls a tool makes are charged to user in
+// ai_usage_logs, with the tool name as the endpoint.
+func attributeTo(ctx context.Context, user *models.User, tool string) context.Context {
+	return ai.WithAttribution(ctx, ai.CallAttribution{UserID: user.ID, Endpoint: "mcp/" + tool})
 }
 
 // userForRequest resolves the authenticated SaltyBytes user from the verified
@@ -121,7 +129,7 @@ func (d *Deps) searchRecipes(ctx context.Context, req *mcp.CallToolRequest, in s
 	if offset < 0 || offset > 200 {
 		offset = 0
 	}
-	result, err := d.Search.SearchRecipes(ctx, in.Query, count, offset)
+	result, err := d.Search.SearchRecipes(service.WithUserLocale(ctx, user), in.Query, count, offset)
 	if err != nil {
 		logger.Get().Error("mcp search failed", zap.Error(err))
 		return nil, out, fmt.Errorf("recipe search failed; try again in a moment")
@@ -146,6 +154,128 @@ func (d *Deps) searchRecipes(ctx context.Context, req *mcp.CallToolRequest, in s
 	return textResult(summary), out, nil
 }
 
+// --- find_recipes ---
+
+type findRecipesIn struct {
+	Request   string   `json:"request" jsonschema:"what the user wants, or how to change the last results, e.g. 'cosy vegetarian dinner' or 'something lighter, no fish'"`
+	SessionID uint     `json:"session_id,omitempty" jsonschema:"session_id from a previous find_recipes call to refine those results instead of starting over"`
+	Dismiss   []string `json:"dismiss,omitempty" jsonschema:"source_urls of results the user rejected; they will not be shown again in this session"`
+}
+
+type findRecipesOut struct {
+	View      string            `json:"view"`
+	Query     string            `json:"query"`
+	Results   []ai.SearchResult `json:"results"`
+	HasMore   bool              `json:"has_more"`
+	SessionID uint              `json:"session_id,omitempty"`
+}
+
+// findRecipes runs the guided recipe finder (ranked for the user's household,
+// collections dug into their individual recipes). Without a session_id it
+// starts a new find; with one it continues that session, so an assistant can
+// iterate on the results without re-running from scratch.
+func (d *Deps) findRecipes(ctx context.Context, req *mcp.CallToolRequest, in findRecipesIn) (*mcp.CallToolResult, findRecipesOut, error) {
+	out := findRecipesOut{View: viewSearchResults, Query: in.Request}
+	user, err := d.userForRequest(req, "search")
+	if err != nil {
+		return nil, out, err
+	}
+	if d.Finder == nil {
+		return nil, out, fmt.Errorf("the recipe finder is not available right now")
+	}
+	if in.SessionID == 0 && strings.TrimSpace(in.Request) == "" {
+		return nil, out, fmt.Errorf("request is required")
+	}
+	if d.Subs != nil {
+		allowed, err := d.Subs.CheckLimit(user.ID, "search")
+		if err != nil {
+			return nil, out, fmt.Errorf("could not check subscription limits")
+		}
+		if !allowed {
+			return nil, out, fmt.Errorf("this account has used all of its recipe searches for the month; more become available when the monthly limit resets")
+		}
+	}
+
+	var session *models.FinderSession
+	if in.SessionID != 0 {
+		if d.Finder.Sessions == nil {
+			return nil, out, fmt.Errorf("the recipe finder is not available right now")
+		}
+		session, err = d.Finder.Sessions.Get(ctx, user.ID, in.SessionID)
+		if err != nil {
+			return nil, out, fmt.Errorf("finder session %d not found; call find_recipes without session_id to start over", in.SessionID)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(attributeTo(service.WithUserLocale(ctx, user), user, "find_recipes"), 2*time.Minute)
+	defer cancel()
+	events := make(chan service.FinderEvent, 32)
+	go func() {
+		defer close(events)
+		if session != nil {
+			d.Finder.ContinueSession(ctx, user, session, service.FinderTurnRequest{Message: in.Request, Dismiss: in.Dismiss}, events)
+		} else {
+			d.Finder.FindRecipes(ctx, user, service.FinderRequest{FreeText: in.Request}, events)
+		}
+	}()
+
+	var broaden []string
+	var failed bool
+	for ev := range events {
+		switch ev.Type {
+		case service.FinderEventSearching:
+			out.Query = ev.Query
+		case service.FinderEventFound:
+			if !ev.FromCache && d.Subs != nil {
+				if err := d.Subs.IncrementUsage(user.ID, "search"); err != nil {
+					logger.Get().Error("mcp: failed to increment search usage", zap.Uint("user_id", user.ID), zap.Error(err))
+				}
+			}
+		case service.FinderEventShortlist, service.FinderEventExpanded:
+			for _, it := range ev.Items {
+				out.Results = append(out.Results, it.Result)
+			}
+			if ev.Type == service.FinderEventShortlist {
+				out.HasMore = ev.HasMore
+			}
+		case service.FinderEventEmpty:
+			broaden = ev.Broaden
+		case service.FinderEventError:
+			failed = true
+		case service.FinderEventDone:
+			out.SessionID = ev.SessionID
+		}
+	}
+	if out.SessionID == 0 && session != nil {
+		out.SessionID = session.ID
+	}
+
+	if failed {
+		return nil, out, fmt.Errorf("recipe search failed; try again in a moment")
+	}
+	if len(out.Results) == 0 {
+		summary := "No matching recipes found."
+		if len(broaden) > 0 {
+			summary += " Try one of: " + strings.Join(broaden, "; ") + "."
+		}
+		return textResult(summary), out, nil
+	}
+
+	titles := make([]string, 0, len(out.Results))
+	for i, r := range out.Results {
+		if i >= 5 {
+			break
+		}
+		titles = append(titles, fmt.Sprintf("%q (%s)", r.Title, r.Source))
+	}
+	summary := fmt.Sprintf("Found %d real recipes — shown to the user as interactive cards. Top picks: %s.",
+		len(out.Results), strings.Join(titles, ", "))
+	if out.SessionID != 0 {
+		summary += fmt.Sprintf(" To refine these (e.g. 'something lighter', 'no fish'), call find_recipes again with session_id %d, listing any rejected source_urls in dismiss.", out.SessionID)
+	}
+	return textResult(summary), out, nil
+}
+
 // --- preview_recipe ---
 
 type previewRecipeIn struct {
@@ -164,13 +294,14 @@ type previewRecipeOut struct {
 
 func (d *Deps) previewRecipe(ctx context.Context, req *mcp.CallToolRequest, in previewRecipeIn) (*mcp.CallToolResult, previewRecipeOut, error) {
 	out := previewRecipeOut{View: viewPreview, SourceURL: in.URL}
-	if _, err := d.userForRequest(req, "recipes:read"); err != nil {
+	user, err := d.userForRequest(req, "recipes:read")
+	if err != nil {
 		return nil, out, err
 	}
 	if strings.TrimSpace(in.URL) == "" {
 		return nil, out, fmt.Errorf("url is required")
 	}
-	preview, err := d.Import.PreviewFromURLWithMultiCheck(ctx, in.URL, d.MultiResolver)
+	preview, err := d.Import.PreviewFromURLWithMultiCheck(attributeTo(ctx, user, "preview_recipe"), in.URL, d.MultiResolver)
 	if err != nil {
 		logger.Get().Warn("mcp preview failed", zap.String("url", in.URL), zap.Error(err))
 		return nil, out, fmt.Errorf("could not extract a recipe from that page — the site may be blocking access; try another result")
@@ -212,7 +343,7 @@ func (d *Deps) saveRecipe(ctx context.Context, req *mcp.CallToolRequest, in save
 	if strings.TrimSpace(in.URL) == "" {
 		return nil, out, fmt.Errorf("url is required")
 	}
-	recipe, err := d.Import.ImportFromURL(ctx, in.URL, user)
+	recipe, err := d.Import.ImportFromURL(attributeTo(ctx, user, "save_recipe"), in.URL, user)
 	if err != nil {
 		logger.Get().Warn("mcp import failed", zap.String("url", in.URL), zap.Error(err))
 		return nil, out, fmt.Errorf("could not import that recipe — the site may be blocking access; try previewing it first")
@@ -322,6 +453,13 @@ func registerTools(server *mcp.Server, deps *Deps) {
 		Meta:        widgetMeta(),
 	}, deps.searchRecipes)
 
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "find_recipes",
+		Title:       "Find recipes for the household",
+		Description: "Find real recipes tailored to the user's household (dietary needs, allergies) and keep refining them conversationally. Call without session_id to start; call again with the returned session_id and the user's follow-up ('something lighter', 'no fish') to refine without starting over — rejected results passed in dismiss never come back.",
+		Meta:        widgetMeta(),
+	}, deps.findRecipes)
+
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "preview_recipe",
 		Title:       "Preview a recipe",
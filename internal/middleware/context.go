@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/windoze95/saltybytes-api/internal/ai"
 	"github.com/windoze95/saltybytes-api/internal/logger"
 	"github.com/windoze95/saltybytes-api/internal/service"
 	"github.com/windoze95/saltybytes-api/internal/util"
@@ -31,6 +32,26 @@ func AttachUserToContext(userService *service.UserService) gin.HandlerFunc {
 		}
 
 		c.Set("user", user)
+		// Carry the user's locale on the request context so search and
+		// extraction downstream localize without threading it through.
+		c.Request = c.Request.WithContext(service.WithUserLocale(c.Request.Context(), user))
+		c.Next()
+	}
+}
+
+// AttributeAICalls tags the request context so every AI call made while
+// serving it is recorded in ai_usage_logs against the authenticated user, the
+// request ID (from logger.RequestIDMiddleware) and the route pattern. It runs
+// after VerifyTokenMiddleware; without a user_id the call is still tagged with
+// the request and endpoint.
+func AttributeAICalls() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := util.GetUserIDFromContext(c)
+		c.Request = c.Request.WithContext(ai.WithAttribution(c.Request.Context(), ai.CallAttribution{
+			UserID:    userID,
+			RequestID: c.GetString("request_id"),
+			Endpoint:  c.FullPath(),
+		}))
 		c.Next()
 	}
 }
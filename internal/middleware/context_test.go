@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/windoze95/saltybytes-api/internal/ai"
 	"github.com/windoze95/saltybytes-api/internal/config"
 	"github.com/windoze95/saltybytes-api/internal/models"
 	"github.com/windoze95/saltybytes-api/internal/service"
@@ -120,3 +121,19 @@ func TestAttachUserToContext_ComposedWithVerifyToken(t *testing.T) {
 		t.Errorf("attached user = %+v, want user %d", attached, user.ID)
 	}
 }
+
+func TestAttributeAICalls_TagsRequestContext(t *testing.T) {
+	var got ai.CallAttribution
+	r := gin.New()
+	r.Use(setUserID(42), func(c *gin.Context) { c.Set("request_id", "req-9"); c.Next() })
+	r.Use(AttributeAICalls())
+	r.GET("/recipes/:recipe_id", func(c *gin.Context) {
+		got = ai.AttributionFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/recipes/5", nil))
+	if got.UserID != 42 || got.RequestID != "req-9" || got.Endpoint != "/recipes/:recipe_id" {
+		t.Errorf("attribution = %+v, want user 42, req-9, the route pattern", got)
+	}
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Experiment statuses.
+const (
+	AIExperimentRunning = "running"
+	AIExperimentStopped = "stopped"
+)
+
+// AIExperiment is an A/B test between models on one TextProvider operation,
+// created through the admin API. While running, the operation's attributed
+// traffic is split between Arms by percentage, sticky per user; every call an
+// arm serves is tagged with Key and the arm name in ai_usage_logs, which is
+// what the per-arm report joins outcomes against. At most one experiment runs
+// per operation. Like AIModelOption it stores only model identity — API keys
+// stay in env/SSM.
+type AIExperiment struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Key       string           `gorm:"size:64;uniqueIndex" json:"key"`
+	Operation string           `gorm:"size:64;index" json:"operation"`
+	Status    string           `gorm:"size:16;index" json:"status"` // running | stopped
+	Arms      AIExperimentArms `gorm:"type:jsonb" json:"arms"`
+	Notes     string           `gorm:"type:text" json:"notes,omitempty"`
+
+	StartedAt time.Time  `json:"started_at"`
+	StoppedAt *time.Time `json:"stopped_at,omitempty"`
+}
+
+// AIExperimentArm is one side of an experiment's split.
+type AIExperimentArm struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"` // anthropic|openai|gemini|deepseek
+	Model    string `json:"model"`
+	BaseURL  string `json:"base_url,omitempty"`
+	Percent  int    `json:"percent"`
+}
+
+// AIExperimentArms is the JSONB list of an experiment's arms.
+type AIExperimentArms []AIExperimentArm
+
+// Scan is a GORM hook that scans jsonb into AIExperimentArms.
+func (a *AIExperimentArms) Scan(value interface{}) error {
+	if value == nil {
+		*a = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
+	}
+	return json.Unmarshal(bytes, a)
+}
+
+// Value is a GORM hook that returns the json value of AIExperimentArms.
+func (a AIExperimentArms) Value() (driver.Value, error) {
+	if a == nil {
+		return json.Marshal([]AIExperimentArm{})
+	}
+	return json.Marshal([]AIExperimentArm(a))
+}
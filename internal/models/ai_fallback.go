@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// AIFallbackEntry is one link of the main (flagship) tier's provider failover
+// chain, edited through the admin API. Calls go to the first enabled entry
+// whose breaker is closed and fail over down the chain by Position on
+// retryable provider errors. Like AIModelOption it stores only identity +
+// tuning — API keys stay in env/SSM. Provider "anthropic" with an empty Model
+// is the configured Sonnet. BreakerThreshold and BreakerCooldownSec tune the
+// entry's circuit breaker (0 = defaults).
+type AIFallbackEntry struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Position int    `gorm:"index" json:"position"`
+	Provider string `gorm:"size:32" json:"provider"` // anthropic|openai|gemini|deepseek
+	Model    string `gorm:"size:96" json:"model"`
+	BaseURL  string `gorm:"size:255" json:"base_url"`
+	Label    string `gorm:"size:96" json:"label"`
+	Enabled  bool   `gorm:"default:true" json:"enabled"`
+
+	BreakerThreshold   int `json:"breaker_threshold"`
+	BreakerCooldownSec int `json:"breaker_cooldown_sec"`
+}
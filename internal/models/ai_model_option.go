@@ -26,10 +26,20 @@ type AIModelOption struct {
 	Label    string `gorm:"size:96" json:"label"`                                                               // human-friendly name
 	BaseURL  string `gorm:"size:255" json:"base_url"`                                                           // optional endpoint override
 
+	// Kind is AIModelKindText or AIModelKindImage. Image models are probed by
+	// generating an image and can only serve the GenerateImage route; rows
+	// from before the column existed read as text.
+	Kind string `gorm:"size:16;default:text" json:"kind"`
+
 	// List prices (USD per 1M tokens) for dashboard counterfactual comparison.
 	InputPricePerMTok  float64 `json:"input_price_per_mtok"`
 	OutputPricePerMTok float64 `json:"output_price_per_mtok"`
 
+	// Request and token rate limits for this model; 0 falls back to the
+	// AI_RATE_LIMITS entry for the model or its provider.
+	RPMLimit int `json:"rpm_limit"`
+	TPMLimit int `json:"tpm_limit"`
+
 	Enabled bool `gorm:"default:true" json:"enabled"`
 
 	// Validation-probe outcome.
@@ -38,6 +48,15 @@ type AIModelOption struct {
 	LastValidatedAt *time.Time `json:"last_validated_at"`
 }
 
+// AIModelOption kinds.
+const (
+	AIModelKindText  = "text"
+	AIModelKindImage = "image"
+)
+
+// IsImage reports whether the option is an image-generation model.
+func (o *AIModelOption) IsImage() bool { return o.Kind == AIModelKindImage }
+
 // AIConfig is the single-row table holding the currently-active light-tier
 // selection. It stores the resolved spec (provider/model/base URL) rather than a
 // foreign key to AIModelOption, so the running provider keeps working even if
@@ -51,3 +70,20 @@ type AIConfig struct {
 	ActiveModel    string `gorm:"size:96" json:"active_model"`
 	ActiveBaseURL  string `gorm:"size:255" json:"active_base_url"`
 }
+
+// AIOperationRoute pins one TextProvider operation (e.g. "CookingQA",
+// "AnalyzeAllergens") to a registered model, overriding whichever tier would
+// otherwise serve it. Like AIConfig it stores the resolved spec alongside the
+// option it came from, so the route keeps working if the option row is later
+// edited or deleted. Written by the dashboard after a green probe.
+type AIOperationRoute struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Operation string `gorm:"size:64;uniqueIndex" json:"operation"`
+	OptionID  uint   `json:"option_id"`
+	Provider  string `gorm:"size:32" json:"provider"`
+	Model     string `gorm:"size:96" json:"model"`
+	BaseURL   string `gorm:"size:255" json:"base_url"`
+}
@@ -6,9 +6,34 @@ import "time"
 // usage it consumed, the metered cost, latency and outcome. It's the data
 // behind the dashboard's model cost comparison and counterfactual ("what would
 // model X have cost") pricing — those are computed from the stored token counts.
+// UserID, RequestID and Endpoint attribute the call to the request that caused
+// it (zero for background work) — the basis of per-user spend caps.
 type AIUsageLog struct {
 	ID        uint      `gorm:"primarykey"`
-	CreatedAt time.Time `gorm:"index"`
+	CreatedAt time.Time `gorm:"index;index:idx_ai_usage_user_created,priority:2"`
+
+	UserID    uint   `gorm:"index:idx_ai_usage_user_created,priority:1"`
+	RequestID string `gorm:"size:64"`
+	Endpoint  string `gorm:"size:128;index"` // route pattern, e.g. "/v1/recipes/:id/regenerate"
+
+	// FailoverAttempt is 0 when the main tier's first-choice provider served
+	// the call, n when the nth fallback in the chain did; FailoverFrom names
+	// the first choice it failed over from.
+	FailoverAttempt int    `gorm:"default:0"`
+	FailoverFrom    string `gorm:"size:128"`
+
+	// Experiment and ExperimentArm tag a call served by an A/B experiment arm
+	// (see AIExperiment); empty otherwise.
+	Experiment    string `gorm:"size:64;index"`
+	ExperimentArm string `gorm:"size:64"`
+
+	// RepairIssues counts the problems found in a recipe the call returned,
+	// RepairTurns the repair turns sent back to the model, and RepairCleaned
+	// whether deterministic cleanup had to finish the job; all zero for a
+	// recipe that was valid as returned (or a call that returns none).
+	RepairIssues  int  `gorm:"default:0"`
+	RepairTurns   int  `gorm:"default:0"`
+	RepairCleaned bool `gorm:"default:false"`
 
 	Operation string `gorm:"size:64;index"` // e.g. "ExtractRecipeFromText"
 	Provider  string `gorm:"size:32;index"` // e.g. "anthropic"
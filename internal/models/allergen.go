@@ -21,22 +21,31 @@ type AllergenAnalysis struct {
 	Recipe             *Recipe                `gorm:"foreignKey:RecipeID" json:"-"`
 	NodeID             *uint                  `gorm:"index" json:"node_id"`
 	IngredientAnalyses IngredientAnalysisList `gorm:"type:jsonb" json:"ingredient_analyses"`
-	ContainsNuts       bool                   `json:"contains_nuts"`
-	ContainsDairy      bool                   `json:"contains_dairy"`
-	ContainsGluten     bool                   `json:"contains_gluten"`
-	ContainsSoy        bool                   `json:"contains_soy"`
-	ContainsSeedOils   bool                   `json:"contains_seed_oils"`
-	ContainsShellfish  bool                   `json:"contains_shellfish"`
-	ContainsEggs       bool                   `json:"contains_eggs"`
-	SafeForProfiles    UintList               `gorm:"type:jsonb" json:"safe_for_profiles"`
-	UnsafeForProfiles  UintList               `gorm:"type:jsonb" json:"unsafe_for_profiles"`
-	Confidence         float64                `gorm:"default:0" json:"confidence"`
-	RequiresReview     bool                   `gorm:"default:true" json:"requires_review"`
-	IsPremium          bool                   `gorm:"default:false" json:"is_premium"`
-	PromptVersion      string                 `json:"prompt_version"`
-	Disclaimer         string                 `gorm:"-" json:"disclaimer"`
+	Allergens          AllergenResultList     `gorm:"type:jsonb" json:"allergens"`
+	TaxonomyVersion    string                 `json:"taxonomy_version"`
+	// The Contains* flags are derived from Allergens for older clients.
+	ContainsNuts      bool     `json:"contains_nuts"`
+	ContainsDairy     bool     `json:"contains_dairy"`
+	ContainsGluten    bool     `json:"contains_gluten"`
+	ContainsSoy       bool     `json:"contains_soy"`
+	ContainsSeedOils  bool     `json:"contains_seed_oils"`
+	ContainsShellfish bool     `json:"contains_shellfish"`
+	ContainsEggs      bool     `json:"contains_eggs"`
+	SafeForProfiles   UintList `gorm:"type:jsonb" json:"safe_for_profiles"`
+	UnsafeForProfiles UintList `gorm:"type:jsonb" json:"unsafe_for_profiles"`
+	Confidence        float64  `gorm:"default:0" json:"confidence"`
+	RequiresReview    bool     `gorm:"default:true" json:"requires_review"`
+	IsPremium         bool     `gorm:"default:false" json:"is_premium"`
+	PromptVersion     string   `json:"prompt_version"`
+	Disclaimer        string   `gorm:"-" json:"disclaimer"`
 }
 
+// Ingredient analysis sources.
+const (
+	AnalysisSourceRule  = "rule"  // classified by the allergen lexicon
+	AnalysisSourceModel = "model" // classified by the AI provider
+)
+
 // IngredientAnalysis represents the allergen analysis for a single ingredient.
 type IngredientAnalysis struct {
 	IngredientName    string   `json:"ingredient_name"`
@@ -45,6 +54,45 @@ type IngredientAnalysis struct {
 	SubIngredients    []string `json:"sub_ingredients"`
 	SeedOilRisk       bool     `json:"seed_oil_risk"`
 	Confidence        float64  `json:"confidence"`
+	Source            string   `json:"source"`         // "rule" or "model"; "" on analyses stored before rules existed
+	Rule              string   `json:"rule,omitempty"` // the lexicon entry, when Source is "rule"
+}
+
+// Allergen result statuses.
+const (
+	AllergenContains   = "contains"
+	AllergenMayContain = "may_contain"
+)
+
+// AllergenResult is one taxonomy allergen found in a recipe: whether it is
+// definitely present and which ingredients carry it.
+type AllergenResult struct {
+	Allergen    string   `json:"allergen"` // allergens taxonomy ID, e.g. "sesame"
+	Name        string   `json:"name"`
+	Status      string   `json:"status"` // "contains" or "may_contain"
+	Ingredients []string `json:"ingredients"`
+}
+
+// AllergenResultList is a slice of AllergenResult for JSONB storage.
+type AllergenResultList []AllergenResult
+
+// Scan is a GORM hook that scans jsonb into AllergenResultList.
+func (j *AllergenResultList) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
+	}
+
+	result := AllergenResultList{}
+	err := json.Unmarshal(bytes, &result)
+	*j = AllergenResultList(result)
+
+	return err
+}
+
+// Value is a GORM hook that returns json value of AllergenResultList.
+func (j AllergenResultList) Value() (driver.Value, error) {
+	return json.Marshal(j)
 }
 
 // IngredientAnalysisList is a slice of IngredientAnalysis for JSONB storage.
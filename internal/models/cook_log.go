@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CookLog records that someone actually cooked a recipe: when, from which
+// version of its tree (NodeID, when known), for how many, and how it went.
+// RecipeTitle is the title at logging time, so the history still reads
+// sensibly after the recipe is renamed or deleted.
+type CookLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	RecipeID    uint   `gorm:"not null;index" json:"recipe_id"`
+	NodeID      *uint  `gorm:"index" json:"node_id,omitempty"`
+	RecipeTitle string `gorm:"size:255" json:"recipe_title"`
+	UserID      uint   `gorm:"not null;index" json:"user_id"`
+
+	CookedAt time.Time `gorm:"not null;index" json:"cooked_at"`
+	Servings int       `json:"servings,omitempty"`
+	// Rating is 1 to 5; 0 means unrated.
+	Rating    int            `json:"rating,omitempty"`
+	Notes     string         `gorm:"type:text" json:"notes,omitempty"`
+	PhotoURLs pq.StringArray `gorm:"type:text[]" json:"photo_urls"`
+}
+
+// CookStats summarizes a recipe's cook logs. AverageRating is over the rated
+// entries only (RatingCount of them).
+type CookStats struct {
+	RecipeID      uint       `json:"recipe_id"`
+	TimesCooked   int64      `json:"times_cooked"`
+	RatingCount   int64      `json:"rating_count"`
+	AverageRating float64    `json:"average_rating,omitempty"`
+	LastCookedAt  *time.Time `json:"last_cooked_at,omitempty"`
+}
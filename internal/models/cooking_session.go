@@ -0,0 +1,142 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CookingSession is a user's cooking-mode state for one recipe, kept so a
+// dropped socket can resume where it left off: the step they were on, the
+// ephemeral edits applied, the timers running and the Q&A so far. There is at
+// most one per (user, recipe); it expires after a period of inactivity
+// (LastActiveAt). RecipeTitle is refreshed on every connect for the "resume
+// cooking" list. When the recipe's owner shares their kitchen, everyone in the
+// room works on the owner's session.
+type CookingSession struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID      uint   `gorm:"not null;uniqueIndex:idx_cooking_sessions_user_recipe,priority:1" json:"user_id"`
+	RecipeID    uint   `gorm:"not null;uniqueIndex:idx_cooking_sessions_user_recipe,priority:2" json:"recipe_id"`
+	RecipeTitle string `gorm:"size:255" json:"recipe_title"`
+
+	// CurrentStep is only meaningful when HasStep is set, distinguishing
+	// "step 0" from "never reported".
+	CurrentStep int  `json:"current_step"`
+	HasStep     bool `json:"has_step"`
+
+	Edits   CookingEditList  `gorm:"type:jsonb;default:'[]'" json:"edits"`
+	Timers  CookingTimerList `gorm:"type:jsonb;default:'[]'" json:"timers"`
+	History CookingQAList    `gorm:"type:jsonb;default:'[]'" json:"history"`
+
+	LastActiveAt time.Time `gorm:"index" json:"last_active_at"`
+
+	// JoinCode lets people outside the household join the session's room
+	// until JoinCodeExpiresAt.
+	JoinCode          string     `gorm:"size:16;index" json:"join_code,omitempty"`
+	JoinCodeExpiresAt *time.Time `json:"join_code_expires_at,omitempty"`
+}
+
+// CookingEdit is one ephemeral recipe modification applied during cooking.
+type CookingEdit struct {
+	StepIndex    int       `json:"step_index,omitempty"`
+	Modification string    `json:"modification"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CookingTimer is a kitchen timer started in cooking mode, optionally tied
+// to a recipe step. It is running until EndsAt.
+type CookingTimer struct {
+	ID              string    `json:"id"`
+	Label           string    `json:"label,omitempty"`
+	Step            int       `json:"step,omitempty"`
+	DurationSeconds int       `json:"duration_seconds"`
+	StartedAt       time.Time `json:"started_at"`
+	EndsAt          time.Time `json:"ends_at"`
+}
+
+// CookingQA is one cooking question and the answer given.
+type CookingQA struct {
+	Question  string    `json:"question"`
+	Answer    string    `json:"answer"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CookingEditList is a slice of CookingEdit for JSONB storage.
+type CookingEditList []CookingEdit
+
+// Scan is a GORM hook that scans jsonb into CookingEditList.
+func (j *CookingEditList) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
+	}
+
+	result := CookingEditList{}
+	err := json.Unmarshal(bytes, &result)
+	*j = result
+
+	return err
+}
+
+// Value is a GORM hook that returns the json value of CookingEditList.
+func (j CookingEditList) Value() (driver.Value, error) {
+	if j == nil {
+		return json.Marshal(CookingEditList{})
+	}
+	return json.Marshal(j)
+}
+
+// CookingTimerList is a slice of CookingTimer for JSONB storage.
+type CookingTimerList []CookingTimer
+
+// Scan is a GORM hook that scans jsonb into CookingTimerList.
+func (j *CookingTimerList) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
+	}
+
+	result := CookingTimerList{}
+	err := json.Unmarshal(bytes, &result)
+	*j = result
+
+	return err
+}
+
+// Value is a GORM hook that returns the json value of CookingTimerList.
+func (j CookingTimerList) Value() (driver.Value, error) {
+	if j == nil {
+		return json.Marshal(CookingTimerList{})
+	}
+	return json.Marshal(j)
+}
+
+// CookingQAList is a slice of CookingQA for JSONB storage.
+type CookingQAList []CookingQA
+
+// Scan is a GORM hook that scans jsonb into CookingQAList.
+func (j *CookingQAList) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
+	}
+
+	result := CookingQAList{}
+	err := json.Unmarshal(bytes, &result)
+	*j = result
+
+	return err
+}
+
+// Value is a GORM hook that returns the json value of CookingQAList.
+func (j CookingQAList) Value() (driver.Value, error) {
+	if j == nil {
+		return json.Marshal(CookingQAList{})
+	}
+	return json.Marshal(j)
+}
@@ -15,6 +15,11 @@ import (
 // It is auto-saved server-side after each completed first-page find, so a user
 // can browse and resume past finds. gorm.Model fields are declared explicitly so
 // JSON serializes snake_case (mirrors Family).
+//
+// A session is also the state of a multi-turn find: each follow-up turn
+// updates Intent and Results in place, appends to Turns, and adds any
+// candidates the user dismissed to DismissedURLs, which are never shown again
+// in that session.
 type FinderSession struct {
 	ID        uint             `gorm:"primarykey" json:"id"`
 	CreatedAt time.Time        `json:"created_at"`
@@ -25,6 +30,47 @@ type FinderSession struct {
 	Intent    FinderIntent     `gorm:"type:jsonb" json:"intent"`
 	Results   SearchResultList `gorm:"type:jsonb" json:"results"`
 	Narration StringList       `gorm:"type:jsonb" json:"narration"`
+
+	Turns         FinderTurnList `gorm:"type:jsonb;default:'[]'" json:"turns"`
+	DismissedURLs StringList     `gorm:"type:jsonb;default:'[]'" json:"dismissed_urls"`
+}
+
+// FinderTurn is one follow-up turn of a multi-turn find: the user's message
+// ("something lighter… no fish"), the terms it excluded, what it dismissed,
+// and the titles it ended up showing (kept for the ranker's conversation
+// summary on later turns).
+type FinderTurn struct {
+	Message   string    `json:"message,omitempty"`
+	Excludes  []string  `json:"excludes,omitempty"`
+	Dismissed []string  `json:"dismissed,omitempty"`
+	Query     string    `json:"query"`
+	Shown     []string  `json:"shown,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FinderTurnList is a slice of FinderTurn for JSONB storage.
+type FinderTurnList []FinderTurn
+
+// Scan is a GORM hook that scans jsonb into FinderTurnList.
+func (j *FinderTurnList) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
+	}
+
+	result := FinderTurnList{}
+	err := json.Unmarshal(bytes, &result)
+	*j = FinderTurnList(result)
+
+	return err
+}
+
+// Value is a GORM hook that returns the json value of FinderTurnList.
+func (j FinderTurnList) Value() (driver.Value, error) {
+	if j == nil {
+		return json.Marshal(FinderTurnList{})
+	}
+	return json.Marshal(j)
 }
 
 // FinderIntent captures what a user asked for in a finder run, stored as JSONB.
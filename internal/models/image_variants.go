@@ -0,0 +1,58 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ImageVariant is one resized rendition of a recipe image, stored as WebP and
+// JPEG objects of the same size.
+type ImageVariant struct {
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	WebPURL string `json:"webp_url"`
+	JPEGURL string `json:"jpeg_url"`
+}
+
+// ImageVariants are the renditions generated from a recipe's image on upload
+// (see the imaging package) plus its blurhash placeholder. ImageURL keeps
+// pointing at the original upload.
+type ImageVariants struct {
+	Blurhash string       `json:"blurhash"`
+	Thumb    ImageVariant `json:"thumb"`
+	Card     ImageVariant `json:"card"`
+	Full     ImageVariant `json:"full"`
+}
+
+// URLs lists every object URL the variants reference.
+func (v *ImageVariants) URLs() []string {
+	var out []string
+	for _, variant := range []ImageVariant{v.Thumb, v.Card, v.Full} {
+		for _, u := range []string{variant.WebPURL, variant.JPEGURL} {
+			if u != "" {
+				out = append(out, u)
+			}
+		}
+	}
+	return out
+}
+
+// Scan is a GORM hook that scans jsonb into ImageVariants.
+func (v *ImageVariants) Scan(value interface{}) error {
+	if value == nil {
+		*v = ImageVariants{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+// Value is a GORM hook that returns the json value of ImageVariants.
+func (v ImageVariants) Value() (driver.Value, error) {
+	return json.Marshal(v)
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// PromptSetVersion is one immutable revision of the full prompt configuration
+// (the same YAML as configs/prompts.yaml), kept so edits made through the
+// admin API can be diffed and rolled back. Hash is config.PromptVersion of the
+// content — the value stamped on recipes it generated. ParentID is the version
+// it was edited from (nil for the seed taken from the prompts file).
+type PromptSetVersion struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Hash     string `gorm:"size:16;index" json:"hash"`
+	Content  string `gorm:"type:text" json:"content"`
+	Note     string `gorm:"size:255" json:"note"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// PromptConfig is the single-row table naming the prompt version served to
+// every operation that is not pinned. Seeded with the prompts file on first
+// boot; updated when a version is activated or rolled back to.
+type PromptConfig struct {
+	ID        uint      `gorm:"primarykey" json:"id"` // always 1 (single row)
+	UpdatedAt time.Time `json:"updated_at"`
+
+	ActiveVersionID uint `json:"active_version_id"`
+}
+
+// PromptPin serves one TextProvider operation from a specific prompt version
+// instead of the active one, e.g. to compare a candidate prompt under an
+// experiment before activating it everywhere.
+type PromptPin struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Operation string `gorm:"size:64;uniqueIndex" json:"operation"`
+	VersionID uint   `json:"version_id"`
+}
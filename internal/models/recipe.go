@@ -31,11 +31,13 @@ type Recipe struct {
 	TreeID             *uint            `gorm:"index"`
 	Tree               *RecipeTree      `gorm:"foreignKey:TreeID"`
 	OriginalImageURL   string           `json:"original_image_url,omitempty"`
+	ImageVariants      *ImageVariants   `gorm:"type:jsonb" json:"image_variants,omitempty"` // resized WebP/JPEG renditions of ImageURL; nil until generated
 	Embedding          *string          `gorm:"type:vector(1536)" json:"-"`
 	CanonicalID        *uint            `gorm:"index"`
 	Canonical          *CanonicalRecipe `gorm:"foreignKey:CanonicalID"`
 	HasDiverged        bool             `gorm:"default:false"`
 	PromptVersion      string           `json:"prompt_version,omitempty" gorm:"size:16"` // hash of prompt templates used
+	RequestID          string           `json:"-" gorm:"size:64;index"`                  // request that created it; joins to ai_usage_logs
 }
 
 // Tag is the model for a recipe hashtag.
@@ -59,6 +61,7 @@ const (
 	RecipeTypeImportCopypasta RecipeType = "import_text"
 	RecipeTypeManualEntry     RecipeType = "user_input"
 	RecipeTypeRemix           RecipeType = "remix"
+	RecipeTypeCookingEdits    RecipeType = "cooking_edits"
 )
 
 // RecipeTree is the model for a recipe's branching tree structure.
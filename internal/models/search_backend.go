@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// SearchBackendOption is an operator-managed entry in the web search backend
+// registry (Brave, Google, Bing, SearXNG, or our own canonical-recipe index).
+// Like AIModelOption it stores only identity + tuning — API keys stay in
+// env/SSM, never the DB.
+//
+// Weight steers which healthy backend is tried first (chosen at random in
+// proportion to weight); a weight of 0 makes the backend supplement-only, so
+// it is consulted only to top up a primary that returned too few recipe-like
+// hits. DailyQuota caps calls per UTC day across all instances (0 = no cap);
+// UsedToday/UsageDay hold the shared counter. BreakerThreshold and
+// BreakerCooldownSec tune the backend's circuit breaker (0 = defaults).
+type SearchBackendOption struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// (kind, base_url) is unique so concurrent first-boot seeding across
+	// instances can't create duplicate rows.
+	Kind    string `gorm:"size:32;uniqueIndex:idx_search_backend_options_kind_url,priority:1" json:"kind"` // brave|google|bing|searxng|canonical
+	BaseURL string `gorm:"size:255;uniqueIndex:idx_search_backend_options_kind_url,priority:2" json:"base_url"`
+	Label   string `gorm:"size:96" json:"label"`
+
+	Enabled bool `gorm:"default:true" json:"enabled"`
+	Weight  int  `gorm:"default:1" json:"weight"`
+
+	DailyQuota int    `json:"daily_quota"`
+	UsedToday  int    `json:"used_today"`
+	UsageDay   string `gorm:"size:10" json:"usage_day"` // YYYY-MM-DD (UTC) that UsedToday counts
+
+	BreakerThreshold   int `json:"breaker_threshold"`
+	BreakerCooldownSec int `json:"breaker_cooldown_sec"`
+}
@@ -11,9 +11,13 @@ import (
 )
 
 // SearchCache stores cached web search results to avoid repeated API calls.
+// Entries are keyed by (NormalizedQuery, Market) so a UK searcher and a US
+// searcher don't share results; Market is the searcher's ai.Locale CacheKey,
+// "" when unknown.
 type SearchCache struct {
 	gorm.Model
-	NormalizedQuery string           `gorm:"uniqueIndex;size:512;not null"`
+	NormalizedQuery string           `gorm:"uniqueIndex:idx_search_caches_query_market,priority:1;size:512;not null"`
+	Market          string           `gorm:"uniqueIndex:idx_search_caches_query_market,priority:2;size:16;not null;default:''"`
 	Results         SearchResultList `gorm:"type:jsonb;not null"`
 	ResultCount     int              `gorm:"not null"`
 	HitCount        int              `gorm:"default:0"`
@@ -30,6 +34,9 @@ type SearchResultItem struct {
 	Rating      float64 `json:"rating"`
 	ImageURL    string  `json:"image_url"`
 	Description string  `json:"description"`
+	// Origin marks where the result came from: SearchOriginWeb or
+	// SearchOriginLocal (our own canonical recipe index).
+	Origin string `json:"origin,omitempty"`
 }
 
 // SearchResultList is a slice of SearchResultItem for JSONB storage.
@@ -21,6 +21,10 @@ type FinderRun struct {
 	Query  string `gorm:"size:512" json:"query"`
 	Offset int    `json:"offset"`
 
+	// RequestID ties the run to its AI calls in ai_usage_logs (e.g. to join
+	// RankOK to an experiment arm); empty outside a user request.
+	RequestID string `gorm:"size:64;index" json:"request_id,omitempty"`
+
 	// Search step.
 	ResultsFound int  `json:"results_found"`
 	FromCache    bool `json:"from_cache"`
@@ -50,6 +54,41 @@ type FinderRun struct {
 	RankMS   int64 `json:"rank_ms"`
 	DigMS    int64 `json:"dig_ms"`
 	TotalMS  int64 `json:"total_ms"`
+
+	// RankCapture is the exact ranker input/output for offline evaluation,
+	// recorded on a sampled fraction of runs (FINDER_RANK_CAPTURE_RATE); nil
+	// when the run wasn't sampled or never reached the rank step.
+	RankCapture *FinderRankCapture `gorm:"type:jsonb" json:"rank_capture,omitempty"`
+}
+
+// FinderRankCapture is one captured ExpandAndRankRecipes call: the request
+// exactly as sent (ai.FinderRankRequest), the parsed result
+// (ai.FinderRankResult; absent when the call failed) and the model, tokens and
+// latency that produced it. The finder-eval command replays these against a
+// candidate model.
+type FinderRankCapture struct {
+	Provider         string          `json:"provider"`
+	Model            string          `json:"model"`
+	Request          json.RawMessage `json:"request"`
+	Result           json.RawMessage `json:"result,omitempty"`
+	InputTokens      int             `json:"input_tokens"`
+	OutputTokens     int             `json:"output_tokens"`
+	CacheInputTokens int             `json:"cache_input_tokens"`
+	DurationMS       int64           `json:"duration_ms"`
+}
+
+// Scan is a GORM hook that scans jsonb into FinderRankCapture.
+func (c *FinderRankCapture) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+// Value is a GORM hook that returns the json value of FinderRankCapture.
+func (c FinderRankCapture) Value() (driver.Value, error) {
+	return json.Marshal(c)
 }
 
 // ExtractionEvent records one terminal recipe-extraction attempt — success or
@@ -62,6 +101,10 @@ type ExtractionEvent struct {
 	URL    string `gorm:"size:2048" json:"url"`
 	Domain string `gorm:"size:255;index" json:"domain"`
 
+	// RequestID ties the attempt to its AI calls in ai_usage_logs; empty
+	// outside a user request (e.g. background warming).
+	RequestID string `gorm:"size:64;index" json:"request_id,omitempty"`
+
 	// Origin is which product flow asked for the extraction:
 	// import | preview | warm | finder_dig | multi_expand | unknown.
 	Origin string `gorm:"size:32;index" json:"origin"`
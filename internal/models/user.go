@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -171,6 +172,9 @@ type Personalization struct {
 	UnitSystem     string `gorm:"type:text;default:'us_customary'"`
 	Requirements   string // Additional instructions or guidelines
 	CookingContext string `json:"cooking_context" gorm:"type:text"` // free-form cooking preferences injected into AI prompts
+	Locale         string `json:"locale" gorm:"type:text"`          // BCP 47 language tag, e.g. "en-GB"; "" = unset
+	Region         string `json:"region" gorm:"type:text"`          // ISO 3166-1 alpha-2 country, e.g. "GB"; "" = unset
+	AllergenSet    string `json:"allergen_set" gorm:"type:text"`    // regulatory allergen set, e.g. "eu_14"; "" = derive from Region
 	UID            uuid.UUID
 }
 
@@ -180,6 +184,9 @@ type PersonalizationUpdate struct {
 	UnitSystem     *string
 	Requirements   *string
 	CookingContext *string
+	Locale         *string
+	Region         *string
+	AllergenSet    *string
 	UID            *uuid.UUID
 }
 
@@ -202,6 +209,75 @@ func (p *Personalization) CookingContextPrompt() string {
 	return fmt.Sprintf("Additional context about the user's kitchen and preferences: %s", p.CookingContext)
 }
 
+// ErrInvalidLocale and ErrInvalidRegion are returned by NormalizeLocaleTag and
+// NormalizeRegion for values they can't accept.
+var (
+	ErrInvalidLocale = errors.New("locale must be a language tag such as 'en-GB'")
+	ErrInvalidRegion = errors.New("region must be a two-letter country code such as 'GB'")
+)
+
+// NormalizeLocaleTag validates a BCP 47 language tag and returns it in
+// canonical case ("EN-gb" → "en-GB"). An empty tag is valid and means unset.
+func NormalizeLocaleTag(tag string) (string, error) {
+	tag = strings.TrimSpace(strings.ReplaceAll(tag, "_", "-"))
+	if tag == "" {
+		return "", nil
+	}
+	parts := strings.Split(tag, "-")
+	if len(parts[0]) < 2 || len(parts[0]) > 3 || !isASCIIAlpha(parts[0]) {
+		return "", ErrInvalidLocale
+	}
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		sub := parts[i]
+		if len(sub) < 2 || len(sub) > 8 || !isASCIIAlnum(sub) {
+			return "", ErrInvalidLocale
+		}
+		switch {
+		case len(sub) == 2 && isASCIIAlpha(sub):
+			parts[i] = strings.ToUpper(sub) // region
+		case len(sub) == 4 && isASCIIAlpha(sub):
+			parts[i] = strings.ToUpper(sub[:1]) + strings.ToLower(sub[1:]) // script
+		default:
+			parts[i] = strings.ToLower(sub)
+		}
+	}
+	return strings.Join(parts, "-"), nil
+}
+
+// NormalizeRegion validates an ISO 3166-1 alpha-2 country code and returns it
+// uppercased. An empty region is valid and means unset.
+func NormalizeRegion(region string) (string, error) {
+	region = strings.TrimSpace(region)
+	if region == "" {
+		return "", nil
+	}
+	if len(region) != 2 || !isASCIIAlpha(region) {
+		return "", ErrInvalidRegion
+	}
+	return strings.ToUpper(region), nil
+}
+
+// isASCIIAlpha reports whether s is made only of ASCII letters.
+func isASCIIAlpha(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// isASCIIAlnum reports whether s is made only of ASCII letters and digits.
+func isASCIIAlnum(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
 // BeforeCreate is a GORM hook that runs before creating a new user Personalization.
 func (p *Personalization) BeforeCreate(tx *gorm.DB) (err error) {
 	if p.UnitSystem != "us_customary" && p.UnitSystem != "metric" {
@@ -138,3 +138,38 @@ func TestUnitSystemText_Invalid(t *testing.T) {
 		t.Errorf("UnitSystemText(invalid) = %q, want 'US Customary' (default)", got)
 	}
 }
+
+// --- NormalizeLocaleTag / NormalizeRegion ---
+
+func TestNormalizeLocaleTag(t *testing.T) {
+	cases := map[string]string{
+		"":           "",
+		"en-GB":      "en-GB",
+		"EN_gb":      "en-GB",
+		"fr":         "fr",
+		"zh-hant-tw": "zh-Hant-TW",
+	}
+	for in, want := range cases {
+		got, err := NormalizeLocaleTag(in)
+		if err != nil || got != want {
+			t.Errorf("NormalizeLocaleTag(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"e", "english-GB", "en-", "en-G!"} {
+		if _, err := NormalizeLocaleTag(bad); err == nil {
+			t.Errorf("NormalizeLocaleTag(%q) should fail", bad)
+		}
+	}
+}
+
+func TestNormalizeRegion(t *testing.T) {
+	if got, err := NormalizeRegion("gb"); err != nil || got != "GB" {
+		t.Errorf("NormalizeRegion(gb) = %q, %v", got, err)
+	}
+	if got, err := NormalizeRegion(""); err != nil || got != "" {
+		t.Errorf("NormalizeRegion('') = %q, %v", got, err)
+	}
+	if _, err := NormalizeRegion("GBR"); err == nil {
+		t.Error("NormalizeRegion(GBR) should fail")
+	}
+}
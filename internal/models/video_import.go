@@ -11,15 +11,16 @@ import (
 // once and served from cache to everyone else (the primary cost control).
 type VideoExtractionCache struct {
 	gorm.Model
-	VideoKey       string    `gorm:"uniqueIndex;size:512;not null"` // "<platform>:<video_id>"
-	Platform       string    `gorm:"size:32;not null"`
-	OriginalURL    string    `gorm:"size:2048;not null"`
-	RecipeData     RecipeDef `gorm:"type:jsonb;not null"`
-	ThumbnailURL   string    `gorm:"size:1024"` // S3 URL of a representative frame, shared across importers
-	HitCount       int       `gorm:"default:0"`
-	LastAccessedAt time.Time `gorm:"index;not null"`
-	FetchedAt      time.Time `gorm:"index;not null"`
-	PromptVersion  string    `gorm:"size:16"`
+	VideoKey          string         `gorm:"uniqueIndex;size:512;not null"` // "<platform>:<video_id>"
+	Platform          string         `gorm:"size:32;not null"`
+	OriginalURL       string         `gorm:"size:2048;not null"`
+	RecipeData        RecipeDef      `gorm:"type:jsonb;not null"`
+	ThumbnailURL      string         `gorm:"size:1024"`  // S3 URL of a representative frame, shared across importers
+	ThumbnailVariants *ImageVariants `gorm:"type:jsonb"` // resized WebP/JPEG renditions of ThumbnailURL, shared like it
+	HitCount          int            `gorm:"default:0"`
+	LastAccessedAt    time.Time      `gorm:"index;not null"`
+	FetchedAt         time.Time      `gorm:"index;not null"`
+	PromptVersion     string         `gorm:"size:16"`
 }
 
 // VideoImportStatus is the lifecycle state of an async video-import job.
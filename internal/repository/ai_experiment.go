@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// AIExperimentRepository persists A/B experiments between AI models
+// (ai_experiments) and aggregates their per-arm results.
+type AIExperimentRepository struct {
+	DB *gorm.DB
+}
+
+// NewAIExperimentRepository creates a new AIExperimentRepository.
+func NewAIExperimentRepository(db *gorm.DB) *AIExperimentRepository {
+	return &AIExperimentRepository{DB: db}
+}
+
+// ListExperiments returns every experiment, newest first.
+func (r *AIExperimentRepository) ListExperiments() ([]models.AIExperiment, error) {
+	var exps []models.AIExperiment
+	if err := r.DB.Order("created_at DESC").Find(&exps).Error; err != nil {
+		return nil, err
+	}
+	return exps, nil
+}
+
+// ListRunning returns the running experiments.
+func (r *AIExperimentRepository) ListRunning() ([]models.AIExperiment, error) {
+	var exps []models.AIExperiment
+	if err := r.DB.Where("status = ?", models.AIExperimentRunning).Order("id").Find(&exps).Error; err != nil {
+		return nil, err
+	}
+	return exps, nil
+}
+
+// GetExperiment returns a single experiment by ID, or (nil, nil) if it does
+// not exist.
+func (r *AIExperimentRepository) GetExperiment(id uint) (*models.AIExperiment, error) {
+	var exp models.AIExperiment
+	err := r.DB.First(&exp, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &exp, nil
+}
+
+// CreateExperiment inserts a new experiment.
+func (r *AIExperimentRepository) CreateExperiment(exp *models.AIExperiment) error {
+	return r.DB.Create(exp).Error
+}
+
+// UpdateExperiment persists all fields of an existing experiment.
+func (r *AIExperimentRepository) UpdateExperiment(exp *models.AIExperiment) error {
+	return r.DB.Save(exp).Error
+}
+
+// AIExperimentArmUsage is one arm's metered calls.
+type AIExperimentArmUsage struct {
+	Arm           string  `json:"arm"`
+	Calls         int64   `json:"calls"`
+	Users         int64   `json:"users"`
+	CostUSD       float64 `json:"cost_usd"`
+	AvgDurationMS float64 `json:"avg_duration_ms"`
+	SuccessRate   float64 `json:"success_rate"`
+}
+
+// AIExperimentArmOutcome counts one outcome signal for an arm: how many
+// outcome rows its requests produced and how many were good.
+type AIExperimentArmOutcome struct {
+	Arm   string `json:"arm"`
+	Total int64  `json:"total"`
+	Good  int64  `json:"good"`
+}
+
+// ArmUsage sums an experiment's tagged calls per arm.
+func (r *AIExperimentRepository) ArmUsage(key string) ([]AIExperimentArmUsage, error) {
+	var rows []AIExperimentArmUsage
+	err := r.DB.Model(&models.AIUsageLog{}).
+		Select("experiment_arm AS arm, COUNT(*) AS calls, COUNT(DISTINCT user_id) AS users, "+
+			"COALESCE(SUM(cost_usd), 0) AS cost_usd, "+
+			"COALESCE(AVG(duration_ms), 0) AS avg_duration_ms, "+
+			"COALESCE(AVG(CASE WHEN success THEN 1.0 ELSE 0.0 END), 0) AS success_rate").
+		Where("experiment = ?", key).
+		Group("experiment_arm").
+		Order("experiment_arm").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// armRequestsCTE is the (arm, request_id) pairs of an experiment's calls — the
+// join key between arms and the outcome tables.
+const armRequestsCTE = `WITH arm_requests AS (
+	SELECT DISTINCT experiment_arm AS arm, request_id
+	FROM ai_usage_logs
+	WHERE experiment = ? AND request_id <> ''
+)
+`
+
+// armOutcomes counts outcome rows joined to an experiment's requests.
+func (r *AIExperimentRepository) armOutcomes(key, join, good string) ([]AIExperimentArmOutcome, error) {
+	var rows []AIExperimentArmOutcome
+	err := r.DB.Raw(armRequestsCTE+
+		"SELECT ar.arm, COUNT(*) AS total, COALESCE(SUM(CASE WHEN "+good+" THEN 1 ELSE 0 END), 0) AS good "+
+		"FROM arm_requests ar "+join+" GROUP BY ar.arm ORDER BY ar.arm", key).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// ArmExtractions counts extraction attempts (and successes) made by each arm's
+// requests.
+func (r *AIExperimentRepository) ArmExtractions(key string) ([]AIExperimentArmOutcome, error) {
+	return r.armOutcomes(key, "JOIN extraction_events o ON o.request_id = ar.request_id", "o.success")
+}
+
+// ArmFinderRuns counts finder runs (and those whose rank step succeeded) made
+// by each arm's requests.
+func (r *AIExperimentRepository) ArmFinderRuns(key string) ([]AIExperimentArmOutcome, error) {
+	return r.armOutcomes(key, "JOIN finder_runs o ON o.request_id = ar.request_id", "o.rank_ok")
+}
+
+// ArmRecipeRevisions counts recipes created by each arm's requests, and how
+// many the user later edited or regenerated (any node past the tree's root).
+func (r *AIExperimentRepository) ArmRecipeRevisions(key string) ([]AIExperimentArmOutcome, error) {
+	return r.armOutcomes(key,
+		"JOIN recipes o ON o.request_id = ar.request_id AND o.deleted_at IS NULL",
+		`o.user_edited OR EXISTS (
+			SELECT 1 FROM recipe_trees t JOIN recipe_nodes n ON n.tree_id = t.id
+			WHERE t.recipe_id = o.id AND n.parent_id IS NOT NULL AND n.deleted_at IS NULL)`)
+}
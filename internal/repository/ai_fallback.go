@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// AIFallbackRepository persists the main tier's provider failover chain
+// (ai_fallback_entries).
+type AIFallbackRepository struct {
+	DB *gorm.DB
+}
+
+// NewAIFallbackRepository creates a new AIFallbackRepository.
+func NewAIFallbackRepository(db *gorm.DB) *AIFallbackRepository {
+	return &AIFallbackRepository{DB: db}
+}
+
+// ListChain returns the chain in failover order.
+func (r *AIFallbackRepository) ListChain() ([]models.AIFallbackEntry, error) {
+	var entries []models.AIFallbackEntry
+	if err := r.DB.Order("position").Order("id").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ReplaceChain swaps the whole chain in one transaction, numbering the entries
+// in the order given.
+func (r *AIFallbackRepository) ReplaceChain(entries []models.AIFallbackEntry) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.AIFallbackEntry{}).Error; err != nil {
+			return err
+		}
+		for i := range entries {
+			entries[i].ID = 0
+			entries[i].Position = i
+			if err := tx.Create(&entries[i]).Error; err != nil {
+				return err
+			}
+			// Create skips a false Enabled in favour of the column default.
+			if !entries[i].Enabled {
+				if err := tx.Model(&entries[i]).Update("enabled", false).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
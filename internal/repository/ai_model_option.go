@@ -9,7 +9,8 @@ import (
 )
 
 // AIModelOptionRepository persists the swappable light-tier model registry
-// (ai_model_options) and the single active-selection row (ai_config).
+// (ai_model_options), the single active-selection row (ai_config) and the
+// per-operation routes (ai_operation_routes).
 type AIModelOptionRepository struct {
 	DB *gorm.DB
 }
@@ -77,3 +78,25 @@ func (r *AIModelOptionRepository) UpsertConfig(cfg *models.AIConfig) error {
 		DoUpdates: clause.AssignmentColumns([]string{"active_provider", "active_model", "active_base_url", "updated_at"}),
 	}).Create(cfg).Error
 }
+
+// ListRoutes returns every per-operation route, by operation.
+func (r *AIModelOptionRepository) ListRoutes() ([]models.AIOperationRoute, error) {
+	var routes []models.AIOperationRoute
+	if err := r.DB.Order("operation").Find(&routes).Error; err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+// UpsertRoute writes the route for route.Operation, inserting or replacing it.
+func (r *AIModelOptionRepository) UpsertRoute(route *models.AIOperationRoute) error {
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "operation"}},
+		DoUpdates: clause.AssignmentColumns([]string{"option_id", "provider", "model", "base_url", "updated_at"}),
+	}).Create(route).Error
+}
+
+// DeleteRoute removes the route for an operation, if any.
+func (r *AIModelOptionRepository) DeleteRoute(operation string) error {
+	return r.DB.Where("operation = ?", operation).Delete(&models.AIOperationRoute{}).Error
+}
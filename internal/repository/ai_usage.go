@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/windoze95/saltybytes-api/internal/models"
 	"gorm.io/gorm"
 )
@@ -19,3 +22,78 @@ func NewAIUsageRepository(db *gorm.DB) *AIUsageRepository {
 func (r *AIUsageRepository) Insert(log *models.AIUsageLog) error {
 	return r.DB.Create(log).Error
 }
+
+// SumUserCostSince returns the metered AI cost attributed to a user since t.
+func (r *AIUsageRepository) SumUserCostSince(userID uint, t time.Time) (float64, error) {
+	var total float64
+	err := r.DB.Model(&models.AIUsageLog{}).
+		Where("user_id = ? AND created_at >= ?", userID, t).
+		Select("COALESCE(SUM(cost_usd), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// AIUsageGroupings maps a cost breakdown dimension to its ai_usage_logs column.
+var AIUsageGroupings = map[string]string{
+	"user":      "user_id",
+	"operation": "operation",
+	"model":     "model",
+	"endpoint":  "endpoint",
+}
+
+// AIUsageQuery selects the calls a cost breakdown covers. UserID 0 means every
+// user; Limit 0 means no limit.
+type AIUsageQuery struct {
+	GroupBy string // a key of AIUsageGroupings
+	From    time.Time
+	To      time.Time
+	UserID  uint
+	Limit   int
+}
+
+// AIUsageCostRow is one group of a cost breakdown.
+type AIUsageCostRow struct {
+	Key          string  `json:"key"`
+	Calls        int64   `json:"calls"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+	// RepairedCalls counts calls whose recipe output had issues; RepairTurns
+	// the repair turns they needed; CleanedCalls those deterministic cleanup
+	// had to finish. Grouped by model, this shows which models need repairing.
+	RepairedCalls int64 `json:"repaired_calls"`
+	RepairTurns   int64 `json:"repair_turns"`
+	CleanedCalls  int64 `json:"cleaned_calls"`
+}
+
+// CostBreakdown sums metered cost over [From, To) grouped by one dimension,
+// most expensive first.
+func (r *AIUsageRepository) CostBreakdown(q AIUsageQuery) ([]AIUsageCostRow, error) {
+	column, ok := AIUsageGroupings[q.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown cost grouping: %s", q.GroupBy)
+	}
+
+	tx := r.DB.Model(&models.AIUsageLog{}).
+		Select(fmt.Sprintf("CAST(%s AS TEXT) AS key, COUNT(*) AS calls, "+
+			"COALESCE(SUM(input_tokens), 0) AS input_tokens, "+
+			"COALESCE(SUM(output_tokens), 0) AS output_tokens, "+
+			"COALESCE(SUM(cost_usd), 0) AS cost_usd, "+
+			"COALESCE(SUM(CASE WHEN repair_issues > 0 THEN 1 ELSE 0 END), 0) AS repaired_calls, "+
+			"COALESCE(SUM(repair_turns), 0) AS repair_turns, "+
+			"COALESCE(SUM(CASE WHEN repair_cleaned THEN 1 ELSE 0 END), 0) AS cleaned_calls", column)).
+		Where("created_at >= ? AND created_at < ?", q.From, q.To)
+	if q.UserID != 0 {
+		tx = tx.Where("user_id = ?", q.UserID)
+	}
+	tx = tx.Group(column).Order("cost_usd DESC")
+	if q.Limit > 0 {
+		tx = tx.Limit(q.Limit)
+	}
+
+	var rows []AIUsageCostRow
+	if err := tx.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
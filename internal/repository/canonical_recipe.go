@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/windoze95/saltybytes-api/internal/models"
@@ -55,3 +57,113 @@ func (r *CanonicalRecipeRepository) IncrementHitCount(id uint) error {
 			"last_accessed_at": time.Now(),
 		}).Error
 }
+
+// SearchByTitle returns single-recipe canonical entries whose title contains
+// every term, most-served first. Collection markers (IsMultiPage) are never
+// returned — they hold no recipe.
+func (r *CanonicalRecipeRepository) SearchByTitle(terms []string, limit, offset int) ([]models.CanonicalRecipe, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	q := r.DB.Where("is_multi_page = ?", false).Where("recipe_data->>'title' <> ''")
+	for _, t := range terms {
+		q = q.Where(`recipe_data->>'title' ILIKE ? ESCAPE '\'`, "%"+escapeLike(t)+"%")
+	}
+	var entries []models.CanonicalRecipe
+	if err := q.Order("hit_count DESC").Order("id DESC").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// likeEscaper escapes LIKE's wildcards and its escape character so a search
+// term matches literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes a term for a LIKE pattern with ESCAPE '\'.
+func escapeLike(term string) string {
+	return likeEscaper.Replace(term)
+}
+
+// CanonicalRecipeHit is a canonical entry matched by a local search, with its
+// relevance score (cosine similarity for embedding search, ts_rank_cd for
+// full-text search; higher is better).
+type CanonicalRecipeHit struct {
+	Recipe models.CanonicalRecipe
+	Score  float64
+}
+
+// canonicalScore is one (id, score) row of a ranked canonical search.
+type canonicalScore struct {
+	ID    uint
+	Score float64
+}
+
+// SearchByEmbedding returns single-recipe canonical entries whose embedding is
+// within maxDistance (cosine) of the query embedding, nearest first.
+func (r *CanonicalRecipeRepository) SearchByEmbedding(embedding []float32, maxDistance float64, limit int) ([]CanonicalRecipeHit, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	literal := PgvectorLiteral(embedding)
+
+	var rows []canonicalScore
+	err := r.DB.Model(&models.CanonicalRecipe{}).
+		Select("id, 1 - (embedding <=> ?) AS score", literal).
+		Where("is_multi_page = ? AND embedding IS NOT NULL AND (embedding <=> ?) < ?", false, literal, maxDistance).
+		Order(fmt.Sprintf("embedding <=> '%s'", literal)).
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search canonical recipes by embedding: %w", err)
+	}
+	return r.loadHits(rows)
+}
+
+// SearchFullText runs a web-style full-text query (quoted phrases, "-term"
+// negations) over the search_document column — the title, ingredient names
+// and instructions of each entry's RecipeData — best match first.
+func (r *CanonicalRecipeRepository) SearchFullText(query string, limit int) ([]CanonicalRecipeHit, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	var rows []canonicalScore
+	err := r.DB.Model(&models.CanonicalRecipe{}).
+		Select("id, ts_rank_cd(search_document, websearch_to_tsquery('english', ?)) AS score", query).
+		Where("is_multi_page = ? AND search_document @@ websearch_to_tsquery('english', ?)", false, query).
+		Order("score DESC").
+		Order("hit_count DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to full-text search canonical recipes: %w", err)
+	}
+	return r.loadHits(rows)
+}
+
+// loadHits fetches the entries for ranked rows, preserving their order.
+func (r *CanonicalRecipeRepository) loadHits(rows []canonicalScore) ([]CanonicalRecipeHit, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	var entries []models.CanonicalRecipe
+	if err := r.DB.Where("id IN ?", ids).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.CanonicalRecipe, len(entries))
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+	hits := make([]CanonicalRecipeHit, 0, len(rows))
+	for _, row := range rows {
+		if e, ok := byID[row.ID]; ok {
+			hits = append(hits, CanonicalRecipeHit{Recipe: e, Score: row.Score})
+		}
+	}
+	return hits, nil
+}
@@ -0,0 +1,20 @@
+package repository
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"pancakes", "pancakes"},
+		{"_", `\_`},
+		{"100%", `100\%`},
+		{`a\b`, `a\\b`},
+		{`50%_off\`, `50\%\_off\\`},
+	}
+	for _, tc := range tests {
+		if got := escapeLike(tc.in); got != tc.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
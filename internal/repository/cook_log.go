@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CookSort orders a user's recipes by their cook logs.
+type CookSort string
+
+// CookSort values. Recipes never cooked (or never rated, for
+// CookSortTopRated) sort last, newest first.
+const (
+	CookSortLastCooked CookSort = "last_cooked"
+	CookSortMostCooked CookSort = "most_cooked"
+	CookSortTopRated   CookSort = "top_rated"
+)
+
+// cookSortOrder is the ORDER BY for each CookSort over recipes joined to
+// cook_stats.
+var cookSortOrder = map[CookSort]string{
+	CookSortLastCooked: "cook_stats.last_cooked_at DESC NULLS LAST, recipes.created_at DESC",
+	CookSortMostCooked: "COALESCE(cook_stats.times_cooked, 0) DESC, cook_stats.last_cooked_at DESC NULLS LAST, recipes.created_at DESC",
+	CookSortTopRated:   "cook_stats.average_rating DESC NULLS LAST, COALESCE(cook_stats.times_cooked, 0) DESC, recipes.created_at DESC",
+}
+
+// CookLogRepository persists cook logs.
+type CookLogRepository struct {
+	DB *gorm.DB
+}
+
+// NewCookLogRepository creates a new CookLogRepository.
+func NewCookLogRepository(db *gorm.DB) *CookLogRepository {
+	return &CookLogRepository{DB: db}
+}
+
+// Create inserts a new cook log.
+func (r *CookLogRepository) Create(ctx context.Context, log *models.CookLog) error {
+	if err := r.DB.WithContext(ctx).Create(log).Error; err != nil {
+		logger.Get().Error("failed to create cook log", zap.Uint("user_id", log.UserID), zap.Uint("recipe_id", log.RecipeID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Update persists a cook log's editable fields.
+func (r *CookLogRepository) Update(ctx context.Context, log *models.CookLog) error {
+	if err := r.DB.WithContext(ctx).Model(log).
+		Select("cooked_at", "servings", "rating", "notes", "photo_urls", "updated_at").
+		Updates(log).Error; err != nil {
+		logger.Get().Error("failed to update cook log", zap.Uint("cook_log_id", log.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetByID returns a cook log by ID (ownership is enforced by the caller).
+func (r *CookLogRepository) GetByID(ctx context.Context, id uint) (*models.CookLog, error) {
+	var log models.CookLog
+	if err := r.DB.WithContext(ctx).Where("id = ?", id).First(&log).Error; err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+// Delete removes a cook log by ID.
+func (r *CookLogRepository) Delete(ctx context.Context, id uint) error {
+	if err := r.DB.WithContext(ctx).Delete(&models.CookLog{}, id).Error; err != nil {
+		logger.Get().Error("failed to delete cook log", zap.Uint("cook_log_id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ListByUser returns a page of a user's cook logs, most recently cooked
+// first, plus the total count. A non-zero recipeID limits it to that recipe.
+func (r *CookLogRepository) ListByUser(ctx context.Context, userID, recipeID uint, limit, offset int) ([]models.CookLog, int64, error) {
+	var (
+		logs  []models.CookLog
+		total int64
+	)
+	scope := func() *gorm.DB {
+		q := r.DB.WithContext(ctx).Model(&models.CookLog{}).Where("user_id = ?", userID)
+		if recipeID != 0 {
+			q = q.Where("recipe_id = ?", recipeID)
+		}
+		return q
+	}
+	if err := scope().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := scope().Order("cooked_at DESC").Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// cookStatsQuery aggregates cook logs per recipe.
+func (r *CookLogRepository) cookStatsQuery(ctx context.Context) *gorm.DB {
+	return r.DB.WithContext(ctx).Model(&models.CookLog{}).
+		Select("recipe_id, COUNT(*) AS times_cooked, COUNT(NULLIF(rating, 0)) AS rating_count, " +
+			"AVG(NULLIF(rating, 0)) AS average_rating, MAX(cooked_at) AS last_cooked_at").
+		Group("recipe_id")
+}
+
+// Stats returns the cook stats of each recipe in recipeIDs that has been
+// cooked, keyed by recipe ID.
+func (r *CookLogRepository) Stats(ctx context.Context, recipeIDs []uint) (map[uint]models.CookStats, error) {
+	out := make(map[uint]models.CookStats, len(recipeIDs))
+	if len(recipeIDs) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		RecipeID      uint
+		TimesCooked   int64
+		RatingCount   int64
+		AverageRating *float64
+		LastCookedAt  *time.Time
+	}
+	if err := r.cookStatsQuery(ctx).Where("recipe_id IN ?", recipeIDs).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		stats := models.CookStats{
+			RecipeID:     row.RecipeID,
+			TimesCooked:  row.TimesCooked,
+			RatingCount:  row.RatingCount,
+			LastCookedAt: row.LastCookedAt,
+		}
+		if row.AverageRating != nil {
+			stats.AverageRating = *row.AverageRating
+		}
+		out[row.RecipeID] = stats
+	}
+	return out, nil
+}
+
+// ListUserRecipesByCooking is GetUserRecipes ordered by the recipes' cook
+// logs.
+func (r *CookLogRepository) ListUserRecipesByCooking(ctx context.Context, userID uint, sort CookSort, page, pageSize int) ([]models.Recipe, int64, error) {
+	order, ok := cookSortOrder[sort]
+	if !ok {
+		order = cookSortOrder[CookSortLastCooked]
+	}
+
+	var (
+		recipes []models.Recipe
+		total   int64
+	)
+	if err := r.DB.WithContext(ctx).Model(&models.Recipe{}).Where("created_by_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := r.DB.WithContext(ctx).
+		Preload("Hashtags").
+		Preload("Canonical").
+		Preload("CreatedBy", func(db *gorm.DB) *gorm.DB {
+			return db.Select("ID", "Username")
+		}).
+		Joins("LEFT JOIN (?) AS cook_stats ON cook_stats.recipe_id = recipes.id", r.cookStatsQuery(ctx)).
+		Where("recipes.created_by_id = ?", userID).
+		Order(order).
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&recipes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return recipes, total, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CookingSessionRepository persists cooking-mode sessions.
+type CookingSessionRepository struct {
+	DB *gorm.DB
+}
+
+// NewCookingSessionRepository creates a new CookingSessionRepository.
+func NewCookingSessionRepository(db *gorm.DB) *CookingSessionRepository {
+	return &CookingSessionRepository{DB: db}
+}
+
+// Get returns the user's session for a recipe, or nil if there is none.
+func (r *CookingSessionRepository) Get(ctx context.Context, userID, recipeID uint) (*models.CookingSession, error) {
+	var session models.CookingSession
+	err := r.DB.WithContext(ctx).Where("user_id = ? AND recipe_id = ?", userID, recipeID).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetByJoinCode returns the session whose join code is code and still valid
+// at now, or nil if there is none.
+func (r *CookingSessionRepository) GetByJoinCode(ctx context.Context, code string, now time.Time) (*models.CookingSession, error) {
+	var session models.CookingSession
+	err := r.DB.WithContext(ctx).Where("join_code = ? AND join_code_expires_at > ?", code, now).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Modify applies fn to the user's session for a recipe, creating it first if
+// needed, and saves the result. The row is locked for the duration so devices
+// on different instances can't overwrite each other's changes.
+func (r *CookingSessionRepository) Modify(ctx context.Context, userID, recipeID uint, fn func(*models.CookingSession) error) (*models.CookingSession, error) {
+	var session models.CookingSession
+	var fnErr error
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.CookingSession{
+			UserID:       userID,
+			RecipeID:     recipeID,
+			LastActiveAt: time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND recipe_id = ?", userID, recipeID).
+			First(&session).Error; err != nil {
+			return err
+		}
+		if fnErr = fn(&session); fnErr != nil {
+			return fnErr
+		}
+		return tx.Save(&session).Error
+	})
+	if fnErr != nil {
+		return nil, fnErr
+	}
+	if err != nil {
+		logger.Get().Error("failed to update cooking session",
+			zap.Uint("user_id", userID), zap.Uint("recipe_id", recipeID), zap.Error(err))
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListActiveByUser returns the user's sessions active since the given time,
+// most recent first.
+func (r *CookingSessionRepository) ListActiveByUser(ctx context.Context, userID uint, since time.Time) ([]models.CookingSession, error) {
+	var sessions []models.CookingSession
+	if err := r.DB.WithContext(ctx).
+		Where("user_id = ? AND last_active_at >= ?", userID, since).
+		Order("last_active_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Delete removes the user's session for a recipe.
+func (r *CookingSessionRepository) Delete(ctx context.Context, userID, recipeID uint) error {
+	return r.DB.WithContext(ctx).
+		Where("user_id = ? AND recipe_id = ?", userID, recipeID).
+		Delete(&models.CookingSession{}).Error
+}
+
+// DeleteInactive removes sessions not active since the given time and
+// returns how many were deleted.
+func (r *CookingSessionRepository) DeleteInactive(ctx context.Context, before time.Time) (int64, error) {
+	result := r.DB.WithContext(ctx).Where("last_active_at < ?", before).Delete(&models.CookingSession{})
+	return result.RowsAffected, result.Error
+}
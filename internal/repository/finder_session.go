@@ -28,6 +28,18 @@ func (r *FinderSessionRepository) Create(ctx context.Context, session *models.Fi
 	return nil
 }
 
+// Update persists a session's multi-turn state (intent, results, narration,
+// turns and dismissed URLs) after a follow-up turn.
+func (r *FinderSessionRepository) Update(ctx context.Context, session *models.FinderSession) error {
+	if err := r.DB.WithContext(ctx).Model(session).
+		Select("intent", "results", "narration", "turns", "dismissed_urls", "updated_at").
+		Updates(session).Error; err != nil {
+		logger.Get().Error("failed to update finder session", zap.Uint("session_id", session.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
 // ListByUser returns a page of a user's sessions, newest first, plus the total count.
 func (r *FinderSessionRepository) ListByUser(ctx context.Context, userID uint, limit, offset int) ([]models.FinderSession, int64, error) {
 	var (
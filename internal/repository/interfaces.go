@@ -15,6 +15,7 @@ type RecipeRepo interface {
 	DeleteRecipe(recipeID uint) error
 	UpdateRecipeTitle(recipe *models.Recipe, title string) error
 	UpdateRecipeImageURL(recipeID uint, imageURL string) error
+	UpdateRecipeImageVariants(recipeID uint, variants *models.ImageVariants) error
 	UpdateRecipeStatus(recipeID uint, status string) error
 	UpdateRecipeDef(recipe *models.Recipe) error
 	FindTagByName(tagName string) (*models.Tag, error)
@@ -29,6 +30,8 @@ type RecipeRepo interface {
 	GetNodeAncestors(nodeID uint) ([]models.RecipeNode, error)
 	AddNodeToTree(node *models.RecipeNode, setActive bool) error
 	SetActiveNode(treeID uint, nodeID uint) error
+	SetNodeEphemeral(nodeID uint, ephemeral bool) error
+	DeleteNode(nodeID uint) error
 	UpdateRecipeFromNode(recipeID uint, node *models.RecipeNode) error
 	MaterializeRecipeFromCanonical(recipeID uint, data models.RecipeDef) error
 }
@@ -64,10 +67,10 @@ type VideoImportRepo interface {
 
 // SearchCacheRepo is the interface for search cache repository operations.
 type SearchCacheRepo interface {
-	GetByNormalizedQuery(query string) (*models.SearchCache, error)
+	GetByNormalizedQuery(query, market string) (*models.SearchCache, error)
 	Upsert(entry *models.SearchCache) error
 	IncrementHitCount(id uint) error
-	FindSimilar(embedding []float32, threshold float64, limit int) ([]models.SearchCache, error)
+	FindSimilar(embedding []float32, threshold float64, limit int, market string) ([]models.SearchCache, error)
 	GetHotQueries(minHits int, maxAge, refreshWindow time.Duration) ([]models.SearchCache, error)
 	DeleteStale(maxAge time.Duration) (int64, error)
 }
@@ -87,16 +90,43 @@ type FamilyRepo interface {
 // FinderSessionRepo is the interface for saved recipe-finder session operations.
 type FinderSessionRepo interface {
 	Create(ctx context.Context, session *models.FinderSession) error
+	Update(ctx context.Context, session *models.FinderSession) error
 	ListByUser(ctx context.Context, userID uint, limit, offset int) ([]models.FinderSession, int64, error)
 	GetByID(ctx context.Context, id uint) (*models.FinderSession, error)
 	Delete(ctx context.Context, id uint) error
 }
 
+// CookingSessionRepo is the interface for cooking-mode session operations.
+type CookingSessionRepo interface {
+	Get(ctx context.Context, userID, recipeID uint) (*models.CookingSession, error)
+	GetByJoinCode(ctx context.Context, code string, now time.Time) (*models.CookingSession, error)
+	Modify(ctx context.Context, userID, recipeID uint, fn func(*models.CookingSession) error) (*models.CookingSession, error)
+	ListActiveByUser(ctx context.Context, userID uint, since time.Time) ([]models.CookingSession, error)
+	Delete(ctx context.Context, userID, recipeID uint) error
+	DeleteInactive(ctx context.Context, before time.Time) (int64, error)
+}
+
+// CookLogRepo is the interface for cook log ("I made this") operations.
+type CookLogRepo interface {
+	Create(ctx context.Context, log *models.CookLog) error
+	Update(ctx context.Context, log *models.CookLog) error
+	GetByID(ctx context.Context, id uint) (*models.CookLog, error)
+	Delete(ctx context.Context, id uint) error
+	ListByUser(ctx context.Context, userID, recipeID uint, limit, offset int) ([]models.CookLog, int64, error)
+	Stats(ctx context.Context, recipeIDs []uint) (map[uint]models.CookStats, error)
+	ListUserRecipesByCooking(ctx context.Context, userID uint, sort CookSort, page, pageSize int) ([]models.Recipe, int64, error)
+}
+
 // FinderRunRepo persists agent-run workflow telemetry (dashboard analytics).
 type FinderRunRepo interface {
 	Create(run *models.FinderRun) error
 }
 
+// FinderRankCorpusRepo loads captured ranker calls for offline evaluation.
+type FinderRankCorpusRepo interface {
+	ListRankCaptures(since time.Time, limit int) ([]models.FinderRun, error)
+}
+
 // ExtractionEventRepo persists terminal recipe-extraction outcomes
 // (dashboard analytics + failure drill-downs).
 type ExtractionEventRepo interface {
@@ -136,4 +166,6 @@ var _ FamilyRepo = (*FamilyRepository)(nil)
 var _ AllergenRepo = (*AllergenRepository)(nil)
 var _ FinderSessionRepo = (*FinderSessionRepository)(nil)
 var _ FinderRunRepo = (*FinderRunRepository)(nil)
+var _ CookingSessionRepo = (*CookingSessionRepository)(nil)
+var _ FinderRankCorpusRepo = (*FinderRunRepository)(nil)
 var _ ExtractionEventRepo = (*ExtractionEventRepository)(nil)
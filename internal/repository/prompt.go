@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PromptRepository persists the prompt registry: the version history
+// (prompt_set_versions), the single active-version row (prompt_configs) and
+// the per-operation pins (prompt_pins).
+type PromptRepository struct {
+	DB *gorm.DB
+}
+
+// NewPromptRepository creates a new PromptRepository.
+func NewPromptRepository(db *gorm.DB) *PromptRepository {
+	return &PromptRepository{DB: db}
+}
+
+// ListVersions returns every prompt version, newest first, without content.
+func (r *PromptRepository) ListVersions() ([]models.PromptSetVersion, error) {
+	var versions []models.PromptSetVersion
+	if err := r.DB.Omit("content").Order("id DESC").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetVersion returns a single version by ID, or (nil, nil) if it does not exist.
+func (r *PromptRepository) GetVersion(id uint) (*models.PromptSetVersion, error) {
+	var v models.PromptSetVersion
+	err := r.DB.First(&v, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// CreateVersion inserts a new version.
+func (r *PromptRepository) CreateVersion(v *models.PromptSetVersion) error {
+	return r.DB.Create(v).Error
+}
+
+// GetConfig returns the single active-version row, or (nil, nil) if unset.
+func (r *PromptRepository) GetConfig() (*models.PromptConfig, error) {
+	var cfg models.PromptConfig
+	err := r.DB.Order("id").First(&cfg).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// UpsertConfig writes the single active-version row (id=1), inserting or updating.
+func (r *PromptRepository) UpsertConfig(cfg *models.PromptConfig) error {
+	cfg.ID = 1
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"active_version_id", "updated_at"}),
+	}).Create(cfg).Error
+}
+
+// ListPins returns every per-operation pin, by operation.
+func (r *PromptRepository) ListPins() ([]models.PromptPin, error) {
+	var pins []models.PromptPin
+	if err := r.DB.Order("operation").Find(&pins).Error; err != nil {
+		return nil, err
+	}
+	return pins, nil
+}
+
+// UpsertPin writes the pin for pin.Operation, inserting or replacing it.
+func (r *PromptRepository) UpsertPin(pin *models.PromptPin) error {
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "operation"}},
+		DoUpdates: clause.AssignmentColumns([]string{"version_id", "updated_at"}),
+	}).Create(pin).Error
+}
+
+// DeletePin removes the pin for an operation, if any.
+func (r *PromptRepository) DeletePin(operation string) error {
+	return r.DB.Where("operation = ?", operation).Delete(&models.PromptPin{}).Error
+}
@@ -141,6 +141,18 @@ func (r *RecipeRepository) UpdateRecipeStatus(recipeID uint, status string) erro
 		Update("Status", status).Error
 }
 
+// UpdateRecipeImageVariants replaces the resized renditions of a recipe's
+// image; nil clears them.
+func (r *RecipeRepository) UpdateRecipeImageVariants(recipeID uint, variants *models.ImageVariants) error {
+	err := r.DB.Model(&models.Recipe{}).
+		Where("id = ?", recipeID).
+		Update("ImageVariants", variants).Error
+	if err != nil {
+		logger.Get().Error("failed to update recipe image variants", zap.Uint("recipe_id", recipeID), zap.Error(err))
+	}
+	return err
+}
+
 // UpdateRecipeImageURL updates the image URL of a recipe.
 func (r *RecipeRepository) UpdateRecipeImageURL(recipeID uint, imageURL string) error {
 	err := r.DB.Model(&models.Recipe{}).
@@ -179,6 +179,18 @@ func (r *RecipeRepository) SetActiveNode(treeID uint, nodeID uint) error {
 	})
 }
 
+// SetNodeEphemeral marks a node as ephemeral or as a permanent part of the tree.
+func (r *RecipeRepository) SetNodeEphemeral(nodeID uint, ephemeral bool) error {
+	return r.DB.Model(&models.RecipeNode{}).
+		Where("id = ?", nodeID).
+		Update("is_ephemeral", ephemeral).Error
+}
+
+// DeleteNode soft-deletes a single recipe node.
+func (r *RecipeRepository) DeleteNode(nodeID uint) error {
+	return r.DB.Delete(&models.RecipeNode{}, nodeID).Error
+}
+
 // UpdateRecipeFromNode updates the recipe's core fields from a node's response and sets it as active.
 func (r *RecipeRepository) UpdateRecipeFromNode(recipeID uint, node *models.RecipeNode) error {
 	if node.Response == nil {
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SearchBackendRepository persists the web search backend registry
+// (search_backend_options) and its shared per-day usage counters.
+type SearchBackendRepository struct {
+	DB *gorm.DB
+}
+
+// NewSearchBackendRepository creates a new SearchBackendRepository.
+func NewSearchBackendRepository(db *gorm.DB) *SearchBackendRepository {
+	return &SearchBackendRepository{DB: db}
+}
+
+// ListBackends returns every registered backend in ID order.
+func (r *SearchBackendRepository) ListBackends() ([]models.SearchBackendOption, error) {
+	var opts []models.SearchBackendOption
+	if err := r.DB.Order("id").Find(&opts).Error; err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// GetBackend returns a single backend by ID, or (nil, nil) if it does not exist.
+func (r *SearchBackendRepository) GetBackend(id uint) (*models.SearchBackendOption, error) {
+	var opt models.SearchBackendOption
+	err := r.DB.First(&opt, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &opt, nil
+}
+
+// CreateBackend inserts a new backend.
+func (r *SearchBackendRepository) CreateBackend(opt *models.SearchBackendOption) error {
+	return r.DB.Create(opt).Error
+}
+
+// UpdateBackend persists the editable fields of an existing backend. The usage
+// counter columns are left alone so an admin edit can't clobber a concurrent
+// increment from another instance.
+func (r *SearchBackendRepository) UpdateBackend(opt *models.SearchBackendOption) error {
+	return r.DB.Model(opt).
+		Select("kind", "base_url", "label", "enabled", "weight", "daily_quota", "breaker_threshold", "breaker_cooldown_sec", "updated_at").
+		Updates(opt).Error
+}
+
+// DeleteBackend removes a backend by ID.
+func (r *SearchBackendRepository) DeleteBackend(id uint) error {
+	return r.DB.Delete(&models.SearchBackendOption{}, id).Error
+}
+
+// IncrementUsage atomically counts one call against a backend's quota for the
+// given UTC day, restarting the counter when the day rolls over, and returns
+// the new count.
+func (r *SearchBackendRepository) IncrementUsage(id uint, day string) (int, error) {
+	var opt models.SearchBackendOption
+	err := r.DB.Model(&opt).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "used_today"}}}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"used_today": gorm.Expr("CASE WHEN usage_day = ? THEN used_today + 1 ELSE 1 END", day),
+			"usage_day":  day,
+		}).Error
+	if err != nil {
+		return 0, err
+	}
+	return opt.UsedToday, nil
+}
@@ -19,10 +19,11 @@ func NewSearchCacheRepository(db *gorm.DB) *SearchCacheRepository {
 	return &SearchCacheRepository{DB: db}
 }
 
-// GetByNormalizedQuery performs an exact-match lookup on the normalized query.
-func (r *SearchCacheRepository) GetByNormalizedQuery(query string) (*models.SearchCache, error) {
+// GetByNormalizedQuery performs an exact-match lookup on the normalized query
+// within a market.
+func (r *SearchCacheRepository) GetByNormalizedQuery(query, market string) (*models.SearchCache, error) {
 	var entry models.SearchCache
-	err := r.DB.Where("normalized_query = ?", query).First(&entry).Error
+	err := r.DB.Where("normalized_query = ? AND market = ?", query, market).First(&entry).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +33,7 @@ func (r *SearchCacheRepository) GetByNormalizedQuery(query string) (*models.Sear
 // Upsert creates or updates a cache entry, handling race conditions via ON CONFLICT.
 func (r *SearchCacheRepository) Upsert(entry *models.SearchCache) error {
 	return r.DB.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "normalized_query"}},
+		Columns:   []clause.Column{{Name: "normalized_query"}, {Name: "market"}},
 		DoUpdates: clause.AssignmentColumns([]string{"results", "result_count", "fetched_at", "last_accessed_at", "embedding"}),
 	}).Create(entry).Error
 }
@@ -47,14 +48,15 @@ func (r *SearchCacheRepository) IncrementHitCount(id uint) error {
 		}).Error
 }
 
-// FindSimilar finds cached entries with embeddings similar to the given vector.
-func (r *SearchCacheRepository) FindSimilar(embedding []float32, threshold float64, limit int) ([]models.SearchCache, error) {
+// FindSimilar finds cached entries in a market with embeddings similar to the
+// given vector.
+func (r *SearchCacheRepository) FindSimilar(embedding []float32, threshold float64, limit int, market string) ([]models.SearchCache, error) {
 	maxDistance := 1.0 - threshold
 	literal := PgvectorLiteral(embedding)
 
 	var entries []models.SearchCache
 	err := r.DB.
-		Where("embedding IS NOT NULL AND (embedding <=> ?) < ?", literal, maxDistance).
+		Where("market = ? AND embedding IS NOT NULL AND (embedding <=> ?) < ?", market, literal, maxDistance).
 		Order(fmt.Sprintf("embedding <=> '%s'", literal)).
 		Limit(limit).
 		Find(&entries).Error
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 
 	"github.com/windoze95/saltybytes-api/internal/models"
@@ -21,6 +23,20 @@ func (r *FinderRunRepository) Create(run *models.FinderRun) error {
 	return r.DB.Create(run).Error
 }
 
+// ListRankCaptures returns runs created at or after since that carry a ranker
+// capture, newest first, up to limit (0 = no limit).
+func (r *FinderRunRepository) ListRankCaptures(since time.Time, limit int) ([]models.FinderRun, error) {
+	var runs []models.FinderRun
+	q := r.DB.Where("rank_capture IS NOT NULL AND created_at >= ?", since).Order("created_at DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
 // ExtractionEventRepository persists terminal recipe-extraction outcomes
 // (append-only).
 type ExtractionEventRepository struct {
@@ -142,6 +142,15 @@ func (r *UserRepository) UpdatePersonalization(userID uint, update *models.Perso
 	if update.CookingContext != nil {
 		existingPersonalization.CookingContext = *update.CookingContext
 	}
+	if update.Locale != nil {
+		existingPersonalization.Locale = *update.Locale
+	}
+	if update.Region != nil {
+		existingPersonalization.Region = *update.Region
+	}
+	if update.AllergenSet != nil {
+		existingPersonalization.AllergenSet = *update.AllergenSet
+	}
 	if update.UID != nil {
 		existingPersonalization.UID = *update.UID
 	}
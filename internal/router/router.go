@@ -33,30 +33,56 @@ func lightKeysFromConfig(cfg *config.Config) ai.LightKeys {
 	}
 }
 
-// buildMainTextProvider selects the flagship reasoning provider. Defaults to the
-// Anthropic Sonnet provider; MAIN_PROVIDER=gemini/openai/deepseek swaps in a
-// cheaper frontier model (an OpenAI-compatible provider that implements the full
-// TextProvider). gemini defaults to gemini-2.5-pro. Falls back to Sonnet if the
-// alternative can't be built (e.g. a missing key), so the app always boots.
-func buildMainTextProvider(cfg *config.Config, sonnet ai.TextProvider, mw ai.AIMiddleware) ai.TextProvider {
+// aiCassette loads the record/replay cassette when AI_RECORD is set or either
+// tier is "replay". recorder wraps live providers to capture their calls;
+// replay serves the recorded ones. Both are nil when the cassette is unused or
+// can't be read.
+func aiCassette(cfg *config.Config) (*ai.Recorder, *ai.ReplayProvider) {
+	env := cfg.EnvVars
+	if !env.AIRecord && env.LightProvider != "replay" && env.MainProvider != "replay" {
+		return nil, nil
+	}
+	cassette, err := ai.LoadCassette(env.AICassettePath,
+		env.AnthropicAPIKey, env.OpenAIAPIKey, env.GeminiAPIKey, env.DeepSeekAPIKey)
+	if err != nil {
+		logger.Get().Error("failed to load AI cassette", zap.String("path", env.AICassettePath), zap.Error(err))
+		return nil, nil
+	}
+	logger.Get().Info("AI cassette loaded", zap.String("path", env.AICassettePath),
+		zap.Int("interactions", cassette.Len()), zap.Bool("recording", env.AIRecord))
+	var recorder *ai.Recorder
+	if env.AIRecord {
+		recorder = ai.NewRecorder(cassette)
+	}
+	return recorder, ai.NewReplayProvider(cassette, env.AIReplayStrict)
+}
+
+// mainTierSeed is the main tier's failover chain written on first boot.
+// Defaults to the Anthropic Sonnet provider alone; MAIN_PROVIDER=gemini/openai/
+// deepseek puts a cheaper frontier model (an OpenAI-compatible provider that
+// implements the full TextProvider) first, with Sonnet behind it. gemini
+// defaults to gemini-2.5-pro. MAIN_PROVIDER=replay serves the cassette alone,
+// so nothing falls through to the network. After the first boot the chain is
+// edited through the admin API.
+func mainTierSeed(cfg *config.Config) []models.AIFallbackEntry {
+	sonnet := models.AIFallbackEntry{Provider: "anthropic", Label: "Sonnet", Enabled: true}
 	switch cfg.EnvVars.MainProvider {
 	case "", "anthropic":
-		return sonnet
+		return []models.AIFallbackEntry{sonnet}
+	case "replay":
+		return []models.AIFallbackEntry{{Provider: "replay", Label: "Replay", Enabled: true}}
 	default:
 		model := cfg.EnvVars.MainModel
 		if model == "" && cfg.EnvVars.MainProvider == "gemini" {
 			model = "gemini-2.5-pro"
 		}
-		spec := ai.LightProviderSpec{Provider: cfg.EnvVars.MainProvider, Model: model, BaseURL: cfg.EnvVars.MainBaseURL}
-		p, err := ai.BuildLightProvider(spec, lightKeysFromConfig(cfg), cfg.Prompts, mw)
-		if err != nil {
-			logger.Get().Warn("main provider unbuildable, using anthropic sonnet",
-				zap.String("provider", cfg.EnvVars.MainProvider), zap.Error(err))
-			return sonnet
+		primary := models.AIFallbackEntry{
+			Provider: cfg.EnvVars.MainProvider,
+			Model:    model,
+			BaseURL:  cfg.EnvVars.MainBaseURL,
+			Enabled:  true,
 		}
-		logger.Get().Info("main tier provider active",
-			zap.String("provider", cfg.EnvVars.MainProvider), zap.String("model", model))
-		return p
+		return []models.AIFallbackEntry{primary, sonnet}
 	}
 }
 
@@ -74,16 +100,16 @@ func SetupRouter(cfg *config.Config, database *gorm.DB) *gin.Engine {
 		logger.Get().Error("failed to set trusted proxies", zap.Error(err))
 	}
 
-	config := cors.DefaultConfig()
-	config.AllowCredentials = true
-	config.AllowOrigins = []string{
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowCredentials = true
+	corsConfig.AllowOrigins = []string{
 		"https://api.saltybytes.ai",
 		"https://www.api.saltybytes.ai",
 		"https://saltybytes.ai",
 		"https://www.saltybytes.ai",
 	}
-	config.AddAllowHeaders("Authorization", "X-SaltyBytes-Identifier")
-	r.Use(cors.New(config))
+	corsConfig.AddAllowHeaders("Authorization", "X-SaltyBytes-Identifier")
+	r.Use(cors.New(corsConfig))
 
 	// Add request ID middleware for request correlation
 	r.Use(logger.RequestIDMiddleware())
@@ -104,9 +130,18 @@ func SetupRouter(cfg *config.Config, database *gorm.DB) *gin.Engine {
 	// subscription routes for usage gating)
 	subService := service.NewSubscriptionService(cfg, userRepo)
 
+	// Prompt registry: prompt templates live in the DB as versioned records
+	// (seeded from the prompts file on first boot). Every provider reads
+	// cfg.Prompts through the store per call, so activating, rolling back or
+	// pinning a version takes effect without a restart; the poll propagates
+	// dashboard edits across instances.
+	promptRegistry := service.NewPromptRegistry(repository.NewPromptRepository(database),
+		config.NewPromptStore(cfg.Prompts), cfg.Prompts)
+	promptRegistry.Load()
+	promptRegistry.StartRefresh(context.Background(), 30*time.Second)
+
 	// AI provider setup
 	textProvider := ai.NewAnthropicProvider(cfg.EnvVars.AnthropicAPIKey, cfg.EnvVars.AnthropicModel, cfg.Prompts)
-	imageProvider := ai.NewDALLEProvider(cfg.EnvVars.OpenAIAPIKey)
 
 	// AI observability + cost metering. The cost middleware records every AI
 	// call's tokens + metered cost to ai_usage_logs (off the request path) —
@@ -126,33 +161,68 @@ func SetupRouter(cfg *config.Config, database *gorm.DB) *gin.Engine {
 					CostUSD:          rec.CostUSD,
 					DurationMS:       rec.DurationMS,
 					Success:          rec.Success,
+					UserID:           rec.UserID,
+					RequestID:        rec.RequestID,
+					Endpoint:         rec.Endpoint,
+					FailoverAttempt:  rec.FailoverAttempt,
+					FailoverFrom:     rec.FailoverFrom,
+					Experiment:       rec.Experiment,
+					ExperimentArm:    rec.ExperimentArm,
+					RepairIssues:     rec.RepairIssues,
+					RepairTurns:      rec.RepairTurns,
+					RepairCleaned:    rec.RepairCleaned,
 				}); err != nil {
 					logger.Get().Warn("failed to record AI usage", zap.Error(err))
 				}
 			}()
 		},
 	}
-	aiMW := ai.NewMiddlewareChain(&ai.LoggingMiddleware{}, costMW)
+	// Provider rate limits: calls queue by priority for a free slot instead
+	// of hitting the provider's 429s, and are shed (failing over like any
+	// provider error) when the queue is full or the wait runs too long.
+	rateLimits, err := ai.ParseRateLimits(cfg.EnvVars.AIRateLimits)
+	if err != nil {
+		logger.Get().Warn("invalid AI_RATE_LIMITS, AI calls are unthrottled", zap.Error(err))
+		rateLimits = nil
+	}
+	rateLimiter := ai.NewRateLimiter(rateLimits)
+	aiMW := ai.NewMiddlewareChain(&ai.LoggingMiddleware{}, costMW, rateLimiter)
+	// Per-user monthly AI cost ceilings, enforced alongside the count limits.
+	subService.Spend = aiUsageRepo
 	textProvider.WithMiddleware(aiMW)
 
-	// Main (flagship reasoning) tier: Sonnet by default; a cheaper frontier model
-	// (e.g. gemini-2.5-pro) when MAIN_PROVIDER is set, driving recipe
-	// generation/regen/fork, allergens and dietary. Streaming generation falls
-	// back to non-streaming for non-Anthropic providers (no StreamingTextProvider).
-	mainTextProvider := buildMainTextProvider(cfg, textProvider, aiMW)
-
-	// Recipe-related routes setup
-	recipeRepo := repository.NewRecipeRepository(database)
-	vectorRepo := repository.NewVectorRepository(database)
-	embedProvider := ai.NewEmbeddingProvider(cfg.EnvVars.OpenAIAPIKey)
-	recipeService := service.NewRecipeService(cfg, recipeRepo, mainTextProvider, imageProvider)
-	recipeService.EmbedProvider = embedProvider
-	recipeService.VectorRepo = vectorRepo
-	recipeHandler := handlers.NewRecipeHandler(recipeService)
-	recipeHandler.SubService = subService
+	// Record/replay (local development and offline tests): AI_RECORD captures
+	// every AI call into the cassette; LIGHT_PROVIDER=replay and
+	// MAIN_PROVIDER=replay serve the tiers from it. A replayed main tier also
+	// replays vision, video, image, speech and embeddings, so the stack runs
+	// with no network at all.
+	recorder, replay := aiCassette(cfg)
+	lightKeys := lightKeysFromConfig(cfg)
+	lightKeys.Replay = replay
+	replayAll := replay != nil && cfg.EnvVars.MainProvider == "replay"
+
+	// Image generation: the env-selected provider (DALL-E 3 by default), with
+	// a GenerateImage route from the model registry taking precedence once
+	// the model manager is up.
+	imageSpec := ai.LightProviderSpec{
+		Provider: cfg.EnvVars.ImageProvider,
+		Model:    cfg.EnvVars.ImageModel,
+		BaseURL:  cfg.EnvVars.ImageBaseURL,
+	}
+	if replayAll {
+		imageSpec = ai.LightProviderSpec{Provider: "replay"}
+	}
+	imageProvider, err := ai.BuildImageProvider(imageSpec, lightKeys)
+	if err != nil {
+		logger.Get().Warn("image provider unbuildable, falling back to DALL-E 3",
+			zap.String("provider", imageSpec.Provider), zap.Error(err))
+		imageProvider = ai.NewDALLEProvider(cfg.EnvVars.OpenAIAPIKey)
+	}
 
 	// Light-tier model manager: owns the swappable cheap provider behind a
-	// single SwitchableTextProvider. It seeds the registry + active selection
+	// single SwitchableTextProvider, plus the per-operation routing table that
+	// pins single operations (CookingQA, AnalyzeAllergens, …) to their own
+	// registered model on either tier. It seeds the registry + active selection
 	// from the env default, applies whatever the DB marks active, and polls so a
 	// dashboard live-switch on one instance propagates to the others.
 	aiModelOptionRepo := repository.NewAIModelOptionRepository(database)
@@ -161,10 +231,66 @@ func SetupRouter(cfg *config.Config, database *gorm.DB) *gin.Engine {
 		Model:    cfg.EnvVars.LightModel,
 		BaseURL:  cfg.EnvVars.LightBaseURL,
 	}
-	modelManager := service.NewAIModelManager(aiModelOptionRepo, lightKeysFromConfig(cfg), cfg.Prompts, aiMW, envLightSpec)
+	modelManager := service.NewAIModelManager(aiModelOptionRepo, lightKeys, cfg.Prompts, aiMW, envLightSpec)
+	modelManager.UseRateLimiter(rateLimiter)
 	modelManager.Load(context.Background())
 	modelManager.StartRefresh(context.Background(), 30*time.Second)
-	previewProvider := modelManager.Provider()
+	imageProvider = modelManager.RouteImage(imageProvider)
+	if recorder != nil {
+		imageProvider = recorder.WrapImage(imageProvider)
+	}
+
+	// A/B experiments: split one operation's traffic between models, sticky
+	// per user, with every call tagged by arm in ai_usage_logs. Wraps both
+	// tiers, outside the per-operation routes.
+	experiments := service.NewAIExperimentManager(repository.NewAIExperimentRepository(database),
+		lightKeys, cfg.Prompts, aiMW)
+	experiments.Load()
+	experiments.StartRefresh(context.Background(), 30*time.Second)
+	previewProvider := experiments.Wrap(modelManager.Provider())
+	if recorder != nil {
+		previewProvider = recorder.WrapText(previewProvider)
+	}
+
+	// Main (flagship reasoning) tier: an ordered failover chain (Sonnet by
+	// default; a cheaper frontier model first when MAIN_PROVIDER is set) driving
+	// recipe generation/regen/fork, allergens and dietary. Retryable provider
+	// errors move a call down the chain; the chain is edited live through the
+	// admin API. Streaming generation falls back to non-streaming through the
+	// chain (it is not a StreamingTextProvider).
+	mainTier := service.NewMainTierManager(repository.NewAIFallbackRepository(database), textProvider,
+		lightKeys, cfg.Prompts, aiMW, mainTierSeed(cfg))
+	mainTier.Load()
+	mainTier.StartRefresh(context.Background(), 30*time.Second)
+	mainTextProvider := experiments.Wrap(modelManager.Route(mainTier.Provider()))
+	if recorder != nil {
+		mainTextProvider = recorder.WrapText(mainTextProvider)
+	}
+
+	// Recipe-related routes setup
+	recipeRepo := repository.NewRecipeRepository(database)
+	vectorRepo := repository.NewVectorRepository(database)
+	openAIEmbedder := ai.NewEmbeddingProvider(cfg.EnvVars.OpenAIAPIKey)
+	openAIEmbedder.WithMiddleware(aiMW)
+	var embedProvider ai.EmbeddingProvider = openAIEmbedder
+	if replayAll {
+		embedProvider = replay
+	}
+	if recorder != nil {
+		embedProvider = recorder.WrapEmbedding(embedProvider)
+	}
+	recipeService := service.NewRecipeService(cfg, recipeRepo, mainTextProvider, imageProvider)
+	recipeService.EmbedProvider = embedProvider
+	recipeService.VectorRepo = vectorRepo
+	cookLogRepo := repository.NewCookLogRepository(database)
+	recipeService.CookLogs = cookLogRepo
+	recipeHandler := handlers.NewRecipeHandler(recipeService)
+	recipeHandler.SubService = subService
+	// The tree service also saves cooking-mode edits, merged by the main tier.
+	treeService := service.NewRecipeTreeService(cfg, recipeRepo)
+	treeService.EmbedProvider = embedProvider
+	treeService.VectorRepo = vectorRepo
+	treeService.TextProvider = mainTextProvider
 
 	// Vision provider: Sonnet by default; native Gemini when VISION_NATIVE_GEMINI
 	// is set — routes image/PDF import (photo + files) and the video frame
@@ -176,6 +302,12 @@ func SetupRouter(cfg *config.Config, database *gorm.DB) *gin.Engine {
 		visionProvider = gv
 		logger.Get().Info("native gemini vision enabled", zap.String("model", cfg.EnvVars.GeminiVisionModel))
 	}
+	if replayAll {
+		visionProvider = replay
+	}
+	if recorder != nil {
+		visionProvider = recorder.WrapVision(visionProvider)
+	}
 
 	// Import-related routes setup
 	canonicalRepo := repository.NewCanonicalRecipeRepository(database)
@@ -209,12 +341,22 @@ func SetupRouter(cfg *config.Config, database *gorm.DB) *gin.Engine {
 		importService.VideoProvider = gvp
 		logger.Get().Info("native gemini video extraction enabled", zap.String("model", cfg.EnvVars.GeminiVideoModel))
 	}
+	if replayAll && cfg.EnvVars.VideoNativeGemini {
+		importService.VideoProvider = replay
+	}
+	if recorder != nil && importService.VideoProvider != nil {
+		importService.VideoProvider = recorder.WrapVideo(importService.VideoProvider)
+	}
 
 	// Admin API: light-tier model registry + live switch, used by the operator
 	// dashboard. Guarded by the shared ID header AND a dedicated admin token; the
 	// whole group is disabled (503) when ADMIN_TOKEN is unset, so it is never
 	// exposed by accident.
 	adminAIHandler := handlers.NewAdminAIHandler(modelManager)
+	adminAIHandler.MainTier = mainTier
+	adminAIExperimentHandler := handlers.NewAdminAIExperimentHandler(experiments)
+	adminPromptHandler := handlers.NewAdminPromptHandler(promptRegistry)
+	adminAICostHandler := handlers.NewAdminAICostHandler(service.NewAICostService(aiUsageRepo), subService)
 	apiAdmin := r.Group("/v1/admin")
 	apiAdmin.Use(middleware.CheckIDHeader(cfg.EnvVars.IDHeader))
 	apiAdmin.Use(middleware.RequireAdminToken(cfg.EnvVars.AdminToken))
@@ -225,6 +367,24 @@ func SetupRouter(cfg *config.Config, database *gorm.DB) *gin.Engine {
 		apiAdmin.DELETE("/ai/models/:id", adminAIHandler.DeleteModel)
 		apiAdmin.GET("/ai/active", adminAIHandler.GetActive)
 		apiAdmin.PUT("/ai/active", adminAIHandler.SetActive)
+		apiAdmin.GET("/ai/routes", adminAIHandler.ListRoutes)
+		apiAdmin.PUT("/ai/routes/:operation", adminAIHandler.SetRoute)
+		apiAdmin.DELETE("/ai/routes/:operation", adminAIHandler.ClearRoute)
+		apiAdmin.GET("/ai/fallback", adminAIHandler.GetFallbackChain)
+		apiAdmin.PUT("/ai/fallback", adminAIHandler.SetFallbackChain)
+		apiAdmin.GET("/ai/experiments", adminAIExperimentHandler.ListExperiments)
+		apiAdmin.POST("/ai/experiments", adminAIExperimentHandler.StartExperiment)
+		apiAdmin.POST("/ai/experiments/:id/stop", adminAIExperimentHandler.StopExperiment)
+		apiAdmin.GET("/ai/experiments/:id/report", adminAIExperimentHandler.GetReport)
+		apiAdmin.GET("/prompts", adminPromptHandler.GetPrompts)
+		apiAdmin.POST("/prompts/versions", adminPromptHandler.CreateVersion)
+		apiAdmin.GET("/prompts/versions/:id", adminPromptHandler.GetVersion)
+		apiAdmin.GET("/prompts/versions/:id/diff", adminPromptHandler.DiffVersion)
+		apiAdmin.PUT("/prompts/active", adminPromptHandler.SetActive)
+		apiAdmin.PUT("/prompts/pins/:operation", adminPromptHandler.SetPin)
+		apiAdmin.DELETE("/prompts/pins/:operation", adminPromptHandler.ClearPin)
+		apiAdmin.GET("/ai/costs", adminAICostHandler.GetCosts)
+		apiAdmin.GET("/ai/costs/users/:id", adminAICostHandler.GetUserSpend)
 	}
 
 	// Group for API routes that don't require token verification
@@ -249,6 +409,7 @@ func SetupRouter(cfg *config.Config, database *gorm.DB) *gin.Engine {
 	{
 		apiProtected.Use(middleware.CheckIDHeader(cfg.EnvVars.IDHeader))
 		apiProtected.Use(middleware.VerifyTokenMiddleware(cfg))
+		apiProtected.Use(middleware.AttributeAICalls())
 
 		// User-related routes
 
@@ -289,14 +450,13 @@ func SetupRouter(cfg *config.Config, database *gorm.DB) *gin.Engine {
 		apiProtected.POST("/recipes/preview/url", middleware.AttachUserToContext(userService), importHandler.PreviewFromURL)
 
 		// Recipe tree/branching routes
-		treeService := service.NewRecipeTreeService(cfg, recipeRepo)
-		treeService.EmbedProvider = embedProvider
-		treeService.VectorRepo = vectorRepo
 		treeHandler := handlers.NewRecipeTreeHandler(treeService)
 
 		apiProtected.GET("/recipes/:recipe_id/tree", middleware.AttachUserToContext(userService), treeHandler.GetTree)
 		apiProtected.POST("/recipes/:recipe_id/branch", middleware.AttachUserToContext(userService), treeHandler.CreateBranch)
 		apiProtected.PUT("/recipes/:recipe_id/tree/active/:node_id", middleware.AttachUserToContext(userService), treeHandler.SetActiveNode)
+		apiProtected.PUT("/recipes/:recipe_id/tree/nodes/:node_id/promote", middleware.AttachUserToContext(userService), treeHandler.PromoteNode)
+		apiProtected.DELETE("/recipes/:recipe_id/tree/nodes/:node_id", middleware.AttachUserToContext(userService), treeHandler.DiscardNode)
 	}
 
 	// Family-related routes setup
@@ -320,6 +480,16 @@ func SetupRouter(cfg *config.Config, database *gorm.DB) *gin.Engine {
 	apiProtected.POST("/recipes/:recipe_id/allergens/analyze", middleware.AttachUserToContext(userService), allergenHandler.AnalyzeRecipe)
 	apiProtected.GET("/recipes/:recipe_id/allergens", middleware.AttachUserToContext(userService), allergenHandler.GetAnalysis)
 	apiProtected.POST("/recipes/:recipe_id/allergens/check-family", middleware.AttachUserToContext(userService), allergenHandler.CheckFamily)
+	apiProtected.GET("/allergens/sets", allergenHandler.ListAllergenSets)
+
+	// Substitution routes: proposals are grounded in dietary profiles and the
+	// cached allergen analysis; applying one forks the recipe.
+	substitutionService := service.NewSubstitutionService(mainTextProvider, recipeRepo, familyRepo, recipeService)
+	substitutionService.Allergens = allergenRepo
+	recipeHandler.Substitutions = substitutionService
+
+	apiProtected.POST("/recipes/:recipe_id/substitutions", middleware.AttachUserToContext(userService), recipeHandler.SuggestSubstitutions)
+	apiProtected.POST("/recipes/:recipe_id/substitutions/apply", middleware.AttachUserToContext(userService), recipeHandler.ApplySubstitutions)
 
 	// User update routes
 	apiProtected.PUT("/users/me", middleware.AttachUserToContext(userService), userHandler.UpdateUser)
@@ -330,10 +500,31 @@ func SetupRouter(cfg *config.Config, database *gorm.DB) *gin.Engine {
 	apiProtected.DELETE("/recipes/:recipe_id", middleware.AttachUserToContext(userService), recipeHandler.DeleteRecipe)
 
 	// Search routes
-	searchProvider := ai.NewWebSearchProvider(cfg.EnvVars.GoogleSearchKey, cfg.EnvVars.GoogleSearchCX, cfg.EnvVars.BraveSearchKey)
+	// Web search runs through a registry of weighted backends (Brave, Bing,
+	// SearXNG, Google, plus our own canonical-recipe index as a supplement)
+	// with per-backend breakers and daily quotas. Seeded from the configured
+	// keys on first boot; edited live through /v1/admin/search/backends.
+	searchKeys := ai.SearchKeys{
+		GoogleAPIKey: cfg.EnvVars.GoogleSearchKey,
+		GoogleCX:     cfg.EnvVars.GoogleSearchCX,
+		BraveAPIKey:  cfg.EnvVars.BraveSearchKey,
+		BingAPIKey:   cfg.EnvVars.BingSearchKey,
+	}
+	searchBackends := service.NewSearchBackendManager(repository.NewSearchBackendRepository(database), searchKeys, service.NewCanonicalSearchBackend(canonicalRepo), cfg.EnvVars.SearXNGBaseURL)
+	searchBackends.Load()
+	searchBackends.StartRefresh(context.Background(), 30*time.Second)
+	var searchProvider ai.SearchProvider = searchBackends
+
+	adminSearchHandler := handlers.NewAdminSearchHandler(searchBackends)
+	apiAdmin.GET("/search/backends", adminSearchHandler.ListBackends)
+	apiAdmin.POST("/search/backends", adminSearchHandler.CreateBackend)
+	apiAdmin.PUT("/search/backends/:id", adminSearchHandler.UpdateBackend)
+	apiAdmin.DELETE("/search/backends/:id", adminSearchHandler.DeleteBackend)
+
 	searchCacheRepo := repository.NewSearchCacheRepository(database)
 	searchService := service.NewSearchService(cfg, searchProvider, subService, searchCacheRepo)
 	searchService.EmbedProvider = embedProvider
+	searchService.LocalRepo = canonicalRepo
 	searchService.StartBackgroundTasks()
 
 	// Backfill missing recipe/canonical embeddings in the background
@@ -369,12 +560,14 @@ func SetupRouter(cfg *config.Config, database *gorm.DB) *gin.Engine {
 	finderService.MultiResolver = multiResolver
 	finderService.Sessions = finderSessionService
 	finderService.Runs = repository.NewFinderRunRepository(database)
+	finderService.RankCaptureRate = cfg.EnvVars.FinderRankCaptureRate
 	importService.Events = repository.NewExtractionEventRepository(database)
 	finderHandler := &handlers.FinderHandler{Service: finderService, SubService: subService}
 	apiProtected.POST("/recipes/find", middleware.AttachUserToContext(userService), finderHandler.FindRecipes)
 	apiProtected.GET("/recipes/finder/sessions", middleware.AttachUserToContext(userService), finderSessionHandler.ListSessions)
 	apiProtected.GET("/recipes/finder/sessions/:session_id", middleware.AttachUserToContext(userService), finderSessionHandler.GetSession)
 	apiProtected.DELETE("/recipes/finder/sessions/:session_id", middleware.AttachUserToContext(userService), finderSessionHandler.DeleteSession)
+	apiProtected.POST("/recipes/finder/sessions/:session_id/turns", middleware.AttachUserToContext(userService), finderHandler.ContinueSession)
 
 	// Vector similarity routes
 	similarityHandler := handlers.NewSimilarityHandler(vectorRepo, embedProvider, recipeService)
@@ -424,19 +617,96 @@ func SetupRouter(cfg *config.Config, database *gorm.DB) *gin.Engine {
 		Import:        importService,
 		MultiResolver: multiResolver,
 		Subs:          subService,
+		Finder:        finderService,
 	}
 	r.Any("/mcp", gin.WrapH(mcpserver.NewHandler(cfg, mcpDeps)))
 
 	// WebSocket routes (authenticated via query param token)
+	// Cooking rooms span instances through the backplane, so devices in one
+	// kitchen see each other's edits whichever task they connected to.
 	hub := ws.NewHub()
+	if cfg.EnvVars.WSBackplane == "postgres" {
+		bp, err := ws.NewPostgresBackplane(context.Background(), database, cfg.EnvVars.DatabaseUrl, ws.DefaultBackplaneChannel)
+		if err != nil {
+			logger.Get().Warn("cooking backplane unavailable, rooms are local to this instance", zap.Error(err))
+		} else {
+			hub.UseBackplane(bp)
+		}
+	}
 	go hub.Run()
-	speechProvider := ai.NewWhisperProvider(cfg.EnvVars.OpenAIAPIKey)
+	var speechProvider ai.SpeechProvider = ai.NewWhisperProvider(cfg.EnvVars.OpenAIAPIKey)
+	if replayAll {
+		speechProvider = replay
+	}
+	if recorder != nil {
+		speechProvider = recorder.WrapSpeech(speechProvider)
+	}
 	importService.SpeechProvider = speechProvider
 	// Voice cooking assistant (intent classification + cooking Q&A) runs on the
 	// cheap/fast light tier, not the flagship model.
 	voiceService := service.NewVoiceService(cfg, previewProvider, speechProvider)
+	// Read-aloud is optional: without a buildable TTS provider, cooking mode
+	// stays text-only and speech requests get an error message.
+	ttsSpec := ai.LightProviderSpec{Provider: cfg.EnvVars.TTSProvider, Model: cfg.EnvVars.TTSModel}
+	if replayAll {
+		ttsSpec = ai.LightProviderSpec{Provider: "replay"}
+	}
+	if synthesizer, err := ai.BuildSpeechSynthesisProvider(ttsSpec, lightKeys); err != nil {
+		logger.Get().Warn("tts provider unbuildable, read-aloud disabled",
+			zap.String("provider", ttsSpec.Provider), zap.Error(err))
+	} else {
+		if recorder != nil {
+			synthesizer = recorder.WrapSpeechSynthesis(synthesizer)
+		}
+		voiceService.Synthesizer = synthesizer
+		voiceService.SynthesizerModel = ai.SpeechSynthesisModel(ttsSpec)
+		voiceService.Voice = cfg.EnvVars.TTSVoice
+	}
 	cookingHandler := ws.NewCookingHandler(hub, cfg.EnvVars.JwtSecretKey, voiceService, recipeRepo)
+	// Cooking sessions survive reconnects: the connected message carries the
+	// saved step, edits, timers and Q&A, and GET /v1/cooking/sessions lists
+	// sessions to resume.
+	cookingSessionService := service.NewCookingSessionService(repository.NewCookingSessionRepository(database))
+	cookingSessionService.Families = familyRepo
+	cookingSessionService.StartCleanup(time.Hour)
+	cookingHandler.Sessions = cookingSessionService
+	cookingHandler.Users = userRepo
+	// Cooking Q&A is grounded on the active recipe version, the session's
+	// edits and Q&A so far, and the household's dietary needs, and streams
+	// answers as they are generated.
+	cookingQA := service.NewCookingQAService(previewProvider, recipeRepo)
+	cookingQA.Users = userRepo
+	cookingQA.Families = familyRepo
+	cookingQA.Sessions = cookingSessionService
+	cookingHandler.QA = cookingQA
+	// The cook log records each time a recipe is actually cooked; ending a
+	// cooking session offers a prefilled entry.
+	cookLogService := service.NewCookLogService(cfg, cookLogRepo, recipeRepo)
+	cookLogService.Sessions = cookingSessionService
+	cookingHandler.CookLogs = cookLogService
+	cookingSessionHandler := handlers.NewCookingSessionHandler(cookingSessionService)
+	cookingSessionHandler.Tree = treeService
+	cookingSessionHandler.Recipes = recipeRepo
+	cookingSessionHandler.CookLogs = cookLogService
+	cookingSessionHandler.SubService = subService
+	apiProtected.GET("/cooking/sessions", middleware.AttachUserToContext(userService), cookingSessionHandler.ListSessions)
+	apiProtected.DELETE("/cooking/sessions/:recipe_id", middleware.AttachUserToContext(userService), cookingSessionHandler.EndSession)
+	apiProtected.POST("/cooking/sessions/:recipe_id/save", middleware.AttachUserToContext(userService), cookingSessionHandler.SaveSession)
+	apiProtected.POST("/cooking/sessions/:recipe_id/invite", middleware.AttachUserToContext(userService), cookingSessionHandler.Invite)
+	apiProtected.GET("/cooking/join/:code", middleware.AttachUserToContext(userService), cookingSessionHandler.ResolveJoinCode)
+	cookLogHandler := handlers.NewCookLogHandler(cookLogService)
+	apiProtected.POST("/cook-logs", middleware.AttachUserToContext(userService), cookLogHandler.CreateCookLog)
+	apiProtected.GET("/cook-logs", middleware.AttachUserToContext(userService), cookLogHandler.ListCookLogs)
+	apiProtected.GET("/cook-logs/:cook_log_id", middleware.AttachUserToContext(userService), cookLogHandler.GetCookLog)
+	apiProtected.PATCH("/cook-logs/:cook_log_id", middleware.AttachUserToContext(userService), cookLogHandler.UpdateCookLog)
+	apiProtected.DELETE("/cook-logs/:cook_log_id", middleware.AttachUserToContext(userService), cookLogHandler.DeleteCookLog)
+	apiProtected.POST("/cook-logs/:cook_log_id/photos", middleware.AttachUserToContext(userService), cookLogHandler.AddCookLogPhoto)
+	apiProtected.GET("/recipes/:recipe_id/cook-stats", middleware.AttachUserToContext(userService), cookLogHandler.GetCookStats)
 	r.GET("/v1/ws/cook/:recipe_id", cookingHandler.HandleCookingSession)
+	// Multi-dish cook plans time each recipe's steps on the light tier (or
+	// from the step text) and prompt every step in the user's plan room.
+	cookPlanHandler := ws.NewCookPlanHandler(hub, cfg.EnvVars.JwtSecretKey, service.NewCookPlanService(previewProvider, recipeRepo))
+	r.GET("/v1/ws/cook-plan", cookPlanHandler.HandleCookPlan)
 
 	return r
 }
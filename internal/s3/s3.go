@@ -3,6 +3,7 @@ package s3
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/google/uuid"
 	"github.com/windoze95/saltybytes-api/internal/config"
 )
@@ -87,6 +89,44 @@ func DeleteRecipeImageFromS3(ctx context.Context, cfg *config.Config, s3Key stri
 	return nil
 }
 
+// ObjectInfo describes an existing S3 object.
+type ObjectInfo struct {
+	URL         string
+	ContentType string
+}
+
+// StatObject looks up an object without downloading it. It returns (nil, nil)
+// when the key does not exist.
+func StatObject(ctx context.Context, cfg *config.Config, s3Key string) (*ObjectInfo, error) {
+	client, err := newS3Client(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(cfg.EnvVars.S3Bucket),
+		Key:    aws.String(s3Key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to stat S3 object: %v", err)
+	}
+
+	return &ObjectInfo{
+		URL:         objectURL(cfg, s3Key),
+		ContentType: aws.ToString(out.ContentType),
+	}, nil
+}
+
+// objectURL is the virtual-hosted URL of a key, the form the uploader's
+// Location takes for the app's buckets.
+func objectURL(cfg *config.Config, s3Key string) string {
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", cfg.EnvVars.S3Bucket, cfg.EnvVars.AWSRegion, s3Key)
+}
+
 // GenerateS3Key generates a timestamp-versioned S3 key for a generated recipe
 // image. Versioning the key gives regenerated images a fresh URL so URL-keyed
 // caches (Flutter cached_network_image, CDNs) pick up the new image. Generated
@@ -100,6 +140,22 @@ func generateS3KeyAt(recipeID uint, unixTS int64) string {
 	return fmt.Sprintf("recipes/%d/images/recipe_image_%d_%d.png", recipeID, recipeID, unixTS)
 }
 
+// GenerateVariantKey generates the S3 key of one resized rendition of a
+// recipe image (see the imaging package). Variants of one upload share unixTS
+// so they sort together; ext is "webp" or "jpg".
+func GenerateVariantKey(recipeID uint, unixTS int64, variant, ext string) string {
+	return fmt.Sprintf("recipes/%d/images/recipe_image_%d_%d_%s.%s", recipeID, recipeID, unixTS, variant, ext)
+}
+
+// GenerateSpeechKey generates the S3 key of a recipe's cached read-aloud
+// audio. The key is content-addressed by textHash (a hex digest of the spoken
+// text) under the synthesizer (provider and model) and voice, so an edited
+// step or a new synthesizer gets fresh audio while repeated reads of an
+// unchanged step hit the same object.
+func GenerateSpeechKey(recipeID uint, synthesizer, voice, textHash string) string {
+	return fmt.Sprintf("recipes/%d/audio/%s/%s/%s", recipeID, synthesizer, voice, textHash)
+}
+
 // GenerateUploadKey generates a collision-free S3 key for a user-uploaded
 // image. The key is server-generated (never derived from the client filename)
 // so uploads cannot overwrite each other or smuggle path segments. ext must
@@ -108,6 +164,12 @@ func GenerateUploadKey(userID uint, ext string) string {
 	return fmt.Sprintf("uploads/%d/images/%s%s", userID, uuid.NewString(), ext)
 }
 
+// GenerateCookLogPhotoKey generates a collision-free S3 key for a photo
+// attached to a cook log. ext must include the leading dot (e.g. ".jpg").
+func GenerateCookLogPhotoKey(userID, cookLogID uint, ext string) string {
+	return fmt.Sprintf("cook-logs/%d/%d/%s%s", userID, cookLogID, uuid.NewString(), ext)
+}
+
 // S3KeyFromURL derives the S3 object key from an object URL previously
 // returned by an upload. Returns "" when the URL is empty or cannot be parsed.
 func S3KeyFromURL(imageURL string) string {
@@ -180,3 +242,14 @@ func RecipeImageKeyFromURL(imageURL string, recipeID uint) string {
 	}
 	return key
 }
+
+// CookLogPhotoKeyFromURL derives the deletable S3 key of a cook log photo
+// from its URL, returning "" unless the key lies under the log's own
+// "cook-logs/<userID>/<cookLogID>/" prefix.
+func CookLogPhotoKeyFromURL(photoURL string, userID, cookLogID uint) string {
+	key := S3KeyFromURL(photoURL)
+	if key == "" || !strings.HasPrefix(key, fmt.Sprintf("cook-logs/%d/%d/", userID, cookLogID)) {
+		return ""
+	}
+	return key
+}
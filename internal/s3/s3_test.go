@@ -3,6 +3,8 @@ package s3
 import (
 	"regexp"
 	"testing"
+
+	"github.com/windoze95/saltybytes-api/internal/config"
 )
 
 func TestGenerateS3Key_VersionedPNG(t *testing.T) {
@@ -47,6 +49,17 @@ func TestGenerateUploadKey_Unique(t *testing.T) {
 	}
 }
 
+func TestGenerateSpeechKey_RoundTripsThroughObjectURL(t *testing.T) {
+	key := GenerateSpeechKey(5, "openai/tts-1", "alloy", "ab12")
+	if key != "recipes/5/audio/openai/tts-1/alloy/ab12" {
+		t.Errorf("GenerateSpeechKey = %q", key)
+	}
+	cfg := &config.Config{EnvVars: config.EnvVars{S3Bucket: "salty", AWSRegion: "us-east-1"}}
+	if got := RecipeImageKeyFromURL(objectURL(cfg, key), 5); got != key {
+		t.Errorf("key from object URL = %q, want %q", got, key)
+	}
+}
+
 func TestS3KeyFromURL(t *testing.T) {
 	tests := []struct {
 		name string
@@ -162,3 +175,22 @@ func TestRecipeImageKeyFromURL(t *testing.T) {
 		})
 	}
 }
+
+func TestCookLogPhotoKeyFromURL(t *testing.T) {
+	key := GenerateCookLogPhotoKey(3, 12, ".jpg")
+	url := "https://my-bucket.s3.us-east-2.amazonaws.com/" + key
+	if got := CookLogPhotoKeyFromURL(url, 3, 12); got != key {
+		t.Errorf("own photo: got %q, want %q", got, key)
+	}
+	for _, tt := range []struct {
+		name          string
+		userID, logID uint
+	}{
+		{"another user's log", 4, 12},
+		{"another log", 3, 1},
+	} {
+		if got := CookLogPhotoKeyFromURL(url, tt.userID, tt.logID); got != "" {
+			t.Errorf("%s: got %q, want rejected", tt.name, got)
+		}
+	}
+}
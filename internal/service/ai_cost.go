@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/repository"
+)
+
+// ErrInvalidCostQuery marks a cost breakdown request the caller got wrong
+// (unknown grouping, inverted range).
+var ErrInvalidCostQuery = errors.New("invalid cost query")
+
+// maxCostRange bounds how far back a single breakdown may reach.
+const maxCostRange = 366 * 24 * time.Hour
+
+// AICostRepo is the ai_usage_logs aggregation surface behind the admin cost
+// reports. Backed by repository.AIUsageRepository.
+type AICostRepo interface {
+	CostBreakdown(q repository.AIUsageQuery) ([]repository.AIUsageCostRow, error)
+}
+
+// AICostService answers "who and what is driving AI cost" for the operator
+// dashboard.
+type AICostService struct {
+	Repo AICostRepo
+}
+
+// NewAICostService creates a new AICostService.
+func NewAICostService(repo AICostRepo) *AICostService {
+	return &AICostService{Repo: repo}
+}
+
+// AICostReport is a cost breakdown over a time range.
+type AICostReport struct {
+	GroupBy  string                      `json:"group_by"`
+	From     time.Time                   `json:"from"`
+	To       time.Time                   `json:"to"`
+	UserID   uint                        `json:"user_id,omitempty"`
+	TotalUSD float64                     `json:"total_usd"`
+	Calls    int64                       `json:"calls"`
+	Rows     []repository.AIUsageCostRow `json:"rows"`
+}
+
+// Breakdown validates q and sums cost over it. A zero To means now; a zero
+// From means 30 days before To. TotalUSD and Calls cover the returned rows.
+func (s *AICostService) Breakdown(q repository.AIUsageQuery) (*AICostReport, error) {
+	if _, ok := repository.AIUsageGroupings[q.GroupBy]; !ok {
+		return nil, fmt.Errorf("%w: group_by must be one of user, operation, model, endpoint", ErrInvalidCostQuery)
+	}
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.AddDate(0, 0, -30)
+	}
+	if !q.From.Before(q.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidCostQuery)
+	}
+	if q.To.Sub(q.From) > maxCostRange {
+		return nil, fmt.Errorf("%w: range may span at most a year", ErrInvalidCostQuery)
+	}
+
+	rows, err := s.Repo.CostBreakdown(q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to break down AI cost: %w", err)
+	}
+	report := &AICostReport{GroupBy: q.GroupBy, From: q.From, To: q.To, UserID: q.UserID, Rows: rows}
+	if report.Rows == nil {
+		report.Rows = []repository.AIUsageCostRow{}
+	}
+	for _, r := range rows {
+		report.TotalUSD += r.CostUSD
+		report.Calls += r.Calls
+	}
+	return report, nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/repository"
+)
+
+type fakeCostRepo struct {
+	got  repository.AIUsageQuery
+	rows []repository.AIUsageCostRow
+}
+
+func (f *fakeCostRepo) CostBreakdown(q repository.AIUsageQuery) ([]repository.AIUsageCostRow, error) {
+	f.got = q
+	return f.rows, nil
+}
+
+func TestAICostBreakdown_DefaultsAndTotals(t *testing.T) {
+	repo := &fakeCostRepo{rows: []repository.AIUsageCostRow{
+		{Key: "7", Calls: 3, CostUSD: 0.5},
+		{Key: "9", Calls: 1, CostUSD: 0.25},
+	}}
+	report, err := NewAICostService(repo).Breakdown(repository.AIUsageQuery{GroupBy: "user"})
+	if err != nil {
+		t.Fatalf("Breakdown: %v", err)
+	}
+	if report.TotalUSD != 0.75 || report.Calls != 4 {
+		t.Errorf("totals = $%v / %d calls, want $0.75 / 4", report.TotalUSD, report.Calls)
+	}
+	if span := repo.got.To.Sub(repo.got.From); span != 30*24*time.Hour {
+		t.Errorf("default range = %v, want 30 days", span)
+	}
+}
+
+func TestAICostBreakdown_RejectsBadQueries(t *testing.T) {
+	svc := NewAICostService(&fakeCostRepo{})
+	now := time.Now()
+	for name, q := range map[string]repository.AIUsageQuery{
+		"unknown grouping": {GroupBy: "tier"},
+		"inverted range":   {GroupBy: "model", From: now, To: now.Add(-time.Hour)},
+		"too long":         {GroupBy: "model", From: now.AddDate(-2, 0, 0), To: now},
+	} {
+		if _, err := svc.Breakdown(q); !errors.Is(err, ErrInvalidCostQuery) {
+			t.Errorf("%s: err = %v, want ErrInvalidCostQuery", name, err)
+		}
+	}
+}
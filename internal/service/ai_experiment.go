@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/config"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrInvalidExperiment marks an experiment the caller got wrong (bad split,
+	// unknown operation, unbuildable or failing arm, clashing experiment).
+	ErrInvalidExperiment = errors.New("invalid experiment")
+	// ErrExperimentNotFound is returned for an unknown experiment ID.
+	ErrExperimentNotFound = errors.New("experiment not found")
+)
+
+// experimentKeyPattern keeps keys short and safe to use as a usage-log tag.
+var experimentKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// AIExperimentRepo is the persistence surface the experiment manager needs.
+// Backed by repository.AIExperimentRepository in production; faked in tests.
+type AIExperimentRepo interface {
+	ListExperiments() ([]models.AIExperiment, error)
+	ListRunning() ([]models.AIExperiment, error)
+	GetExperiment(id uint) (*models.AIExperiment, error)
+	CreateExperiment(exp *models.AIExperiment) error
+	UpdateExperiment(exp *models.AIExperiment) error
+	ArmUsage(key string) ([]repository.AIExperimentArmUsage, error)
+	ArmExtractions(key string) ([]repository.AIExperimentArmOutcome, error)
+	ArmFinderRuns(key string) ([]repository.AIExperimentArmOutcome, error)
+	ArmRecipeRevisions(key string) ([]repository.AIExperimentArmOutcome, error)
+}
+
+// AIExperimentManager runs A/B experiments between models. It owns an
+// ai.ExperimentRouter that tiers are wrapped with, and drives which
+// experiments it runs:
+//
+//   - Start: validate an experiment (every arm must build and pass a live
+//     probe — fail-closed, like a light-tier switch), persist it, run it.
+//   - Stop: end an experiment; its operation returns to its tier.
+//   - StartRefresh: poll the DB so experiments started or stopped on one
+//     instance propagate to the others.
+//   - Report: per-arm cost, latency and quality.
+//
+// API keys live in LightKeys (env/SSM), never the DB.
+type AIExperimentManager struct {
+	repo    AIExperimentRepo
+	keys    ai.LightKeys
+	prompts *config.Prompts
+	mw      ai.AIMiddleware
+	router  *ai.ExperimentRouter
+
+	// build and validate construct and probe an arm's provider; overridable
+	// in tests to avoid network.
+	build    func(spec ai.LightProviderSpec) (ai.TextProvider, error)
+	validate func(ctx context.Context, spec ai.LightProviderSpec) error
+
+	mu      sync.Mutex
+	applied map[uint]time.Time // running experiment ID → UpdatedAt last applied
+}
+
+// NewAIExperimentManager creates a manager running no experiments until Load.
+func NewAIExperimentManager(repo AIExperimentRepo, keys ai.LightKeys, prompts *config.Prompts, mw ai.AIMiddleware) *AIExperimentManager {
+	m := &AIExperimentManager{
+		repo:    repo,
+		keys:    keys,
+		prompts: prompts,
+		mw:      mw,
+		router:  ai.NewExperimentRouter(),
+	}
+	m.build = func(spec ai.LightProviderSpec) (ai.TextProvider, error) {
+		return ai.BuildLightProvider(spec, m.keys, m.prompts, m.mw)
+	}
+	m.validate = func(ctx context.Context, spec ai.LightProviderSpec) error {
+		return ai.ValidateModel(ctx, spec, m.keys, m.prompts)
+	}
+	return m
+}
+
+// Wrap puts a tier's provider behind the running experiments.
+func (m *AIExperimentManager) Wrap(tier ai.TextProvider) ai.TextProvider {
+	return m.router.Wrap(tier)
+}
+
+// Load applies the running experiments. Best-effort: a DB error is logged and
+// leaves every operation on its tier.
+func (m *AIExperimentManager) Load() {
+	m.Refresh()
+}
+
+// StartRefresh polls the running experiments every interval and applies
+// changes made elsewhere.
+func (m *AIExperimentManager) StartRefresh(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				m.Refresh()
+			}
+		}
+	}()
+}
+
+// Refresh reconciles the running experiments with the DB. Arms are only
+// rebuilt when an experiment was started, stopped or edited; an experiment
+// with an arm that can't be built is skipped so its operation stays on its
+// tier.
+func (m *AIExperimentManager) Refresh() {
+	running, err := m.repo.ListRunning()
+	if err != nil {
+		logger.Get().Warn("ai experiments: list running failed, keeping current experiments", zap.Error(err))
+		return
+	}
+
+	seen := make(map[uint]time.Time, len(running))
+	for _, e := range running {
+		seen[e.ID] = e.UpdatedAt
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if sameAppliedExperiments(seen, m.applied) {
+		return
+	}
+
+	exps := make([]*ai.Experiment, 0, len(running))
+	for _, e := range running {
+		exp, err := m.buildExperiment(e)
+		if err != nil {
+			logger.Get().Warn("ai experiments: arm unbuildable, operation stays on its tier",
+				zap.String("experiment", e.Key), zap.Error(err))
+			continue
+		}
+		exps = append(exps, exp)
+	}
+	m.router.Set(exps)
+	m.applied = seen
+}
+
+func sameAppliedExperiments(a, b map[uint]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for id, at := range a {
+		if other, ok := b[id]; !ok || !other.Equal(at) {
+			return false
+		}
+	}
+	return true
+}
+
+// buildExperiment builds the providers behind an experiment's arms.
+func (m *AIExperimentManager) buildExperiment(e models.AIExperiment) (*ai.Experiment, error) {
+	exp := &ai.Experiment{Key: e.Key, Operation: e.Operation}
+	for _, arm := range e.Arms {
+		p, err := m.build(experimentArmSpec(arm))
+		if err != nil {
+			return nil, fmt.Errorf("arm %q: %w", arm.Name, err)
+		}
+		exp.Arms = append(exp.Arms, ai.ExperimentArm{Name: arm.Name, Percent: arm.Percent, Provider: p})
+	}
+	return exp, nil
+}
+
+func experimentArmSpec(arm models.AIExperimentArm) ai.LightProviderSpec {
+	return ai.LightProviderSpec{Provider: arm.Provider, Model: arm.Model, BaseURL: arm.BaseURL}
+}
+
+// ListExperiments returns every experiment, running or not.
+func (m *AIExperimentManager) ListExperiments() ([]models.AIExperiment, error) {
+	return m.repo.ListExperiments()
+}
+
+// Start validates and starts an experiment. Every arm is built and probed
+// first; nothing is saved unless all of them pass.
+func (m *AIExperimentManager) Start(ctx context.Context, exp *models.AIExperiment) error {
+	if err := m.validateExperiment(exp); err != nil {
+		return err
+	}
+	for _, arm := range exp.Arms {
+		spec := experimentArmSpec(arm)
+		if _, err := m.build(spec); err != nil {
+			return fmt.Errorf("%w: arm %q: %v", ErrInvalidExperiment, arm.Name, err)
+		}
+		if err := m.validate(ctx, spec); err != nil {
+			return fmt.Errorf("%w: arm %q failed validation: %v", ErrInvalidExperiment, arm.Name, err)
+		}
+	}
+
+	exp.Status = models.AIExperimentRunning
+	exp.StartedAt = time.Now()
+	exp.StoppedAt = nil
+	if err := m.repo.CreateExperiment(exp); err != nil {
+		return fmt.Errorf("failed to save experiment: %w", err)
+	}
+	m.Refresh()
+	logger.Get().Info("ai experiments: started",
+		zap.String("experiment", exp.Key), zap.String("operation", exp.Operation), zap.Int("arms", len(exp.Arms)))
+	return nil
+}
+
+// validateExperiment checks an experiment's shape: a usable key, a known
+// operation with no other experiment running on it, and two or more uniquely
+// named arms whose percentages sum to 100.
+func (m *AIExperimentManager) validateExperiment(exp *models.AIExperiment) error {
+	if !experimentKeyPattern.MatchString(exp.Key) {
+		return fmt.Errorf("%w: key must be 1-64 lowercase letters, digits, '-' or '_'", ErrInvalidExperiment)
+	}
+	if !ai.IsTextOperation(exp.Operation) {
+		return fmt.Errorf("%w: unknown operation %q", ErrInvalidExperiment, exp.Operation)
+	}
+	if len(exp.Arms) < 2 {
+		return fmt.Errorf("%w: at least two arms are required", ErrInvalidExperiment)
+	}
+	names := make(map[string]bool, len(exp.Arms))
+	total := 0
+	for _, arm := range exp.Arms {
+		if arm.Name == "" || names[arm.Name] {
+			return fmt.Errorf("%w: arm names must be non-empty and unique", ErrInvalidExperiment)
+		}
+		names[arm.Name] = true
+		if arm.Percent <= 0 {
+			return fmt.Errorf("%w: arm %q needs a positive percent", ErrInvalidExperiment, arm.Name)
+		}
+		total += arm.Percent
+	}
+	if total != 100 {
+		return fmt.Errorf("%w: arm percents sum to %d, want 100", ErrInvalidExperiment, total)
+	}
+
+	running, err := m.repo.ListRunning()
+	if err != nil {
+		return err
+	}
+	for _, other := range running {
+		if other.Operation == exp.Operation {
+			return fmt.Errorf("%w: experiment %q is already running on %s", ErrInvalidExperiment, other.Key, exp.Operation)
+		}
+	}
+	return nil
+}
+
+// Stop ends an experiment; its results stay reportable.
+func (m *AIExperimentManager) Stop(id uint) (*models.AIExperiment, error) {
+	exp, err := m.repo.GetExperiment(id)
+	if err != nil {
+		return nil, err
+	}
+	if exp == nil {
+		return nil, ErrExperimentNotFound
+	}
+	if exp.Status != models.AIExperimentStopped {
+		now := time.Now()
+		exp.Status = models.AIExperimentStopped
+		exp.StoppedAt = &now
+		if err := m.repo.UpdateExperiment(exp); err != nil {
+			return nil, fmt.Errorf("failed to stop experiment: %w", err)
+		}
+		m.Refresh()
+	}
+	return exp, nil
+}
+
+// AIExperimentOutcome is one quality signal for an arm: how many outcome rows
+// its requests produced, how many were good, and the ratio.
+type AIExperimentOutcome struct {
+	Total int64   `json:"total"`
+	Good  int64   `json:"good"`
+	Rate  float64 `json:"rate"`
+}
+
+// AIExperimentArmReport is one arm's cost, latency and quality. For
+// Extractions and FinderRanks a higher rate is better; RecipesRevised counts
+// recipes the user later edited or regenerated, so a lower rate is better.
+type AIExperimentArmReport struct {
+	models.AIExperimentArm
+	Calls           int64               `json:"calls"`
+	Users           int64               `json:"users"`
+	CostUSD         float64             `json:"cost_usd"`
+	CostPerCallUSD  float64             `json:"cost_per_call_usd"`
+	AvgDurationMS   float64             `json:"avg_duration_ms"`
+	CallSuccessRate float64             `json:"call_success_rate"`
+	Extractions     AIExperimentOutcome `json:"extractions"`
+	FinderRanks     AIExperimentOutcome `json:"finder_ranks"`
+	RecipesRevised  AIExperimentOutcome `json:"recipes_revised"`
+}
+
+// AIExperimentReport is an experiment with its per-arm results.
+type AIExperimentReport struct {
+	Experiment models.AIExperiment     `json:"experiment"`
+	Arms       []AIExperimentArmReport `json:"arms"`
+}
+
+// Report gathers an experiment's per-arm results. Arms that no longer exist
+// in the experiment definition but have tagged calls are reported too, after
+// the defined ones.
+func (m *AIExperimentManager) Report(id uint) (*AIExperimentReport, error) {
+	exp, err := m.repo.GetExperiment(id)
+	if err != nil {
+		return nil, err
+	}
+	if exp == nil {
+		return nil, ErrExperimentNotFound
+	}
+
+	usage, err := m.repo.ArmUsage(exp.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load experiment usage: %w", err)
+	}
+	extractions, err := m.repo.ArmExtractions(exp.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load experiment extractions: %w", err)
+	}
+	finder, err := m.repo.ArmFinderRuns(exp.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load experiment finder runs: %w", err)
+	}
+	revisions, err := m.repo.ArmRecipeRevisions(exp.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load experiment recipe revisions: %w", err)
+	}
+
+	arms := make(map[string]*AIExperimentArmReport, len(exp.Arms))
+	order := make([]string, 0, len(exp.Arms))
+	armFor := func(name string) *AIExperimentArmReport {
+		if r, ok := arms[name]; ok {
+			return r
+		}
+		r := &AIExperimentArmReport{AIExperimentArm: models.AIExperimentArm{Name: name}}
+		arms[name] = r
+		order = append(order, name)
+		return r
+	}
+	for _, arm := range exp.Arms {
+		armFor(arm.Name).AIExperimentArm = arm
+	}
+	defined := len(order)
+
+	for _, u := range usage {
+		r := armFor(u.Arm)
+		r.Calls = u.Calls
+		r.Users = u.Users
+		r.CostUSD = u.CostUSD
+		r.AvgDurationMS = u.AvgDurationMS
+		r.CallSuccessRate = u.SuccessRate
+		if u.Calls > 0 {
+			r.CostPerCallUSD = u.CostUSD / float64(u.Calls)
+		}
+	}
+	for _, o := range extractions {
+		armFor(o.Arm).Extractions = experimentOutcome(o)
+	}
+	for _, o := range finder {
+		armFor(o.Arm).FinderRanks = experimentOutcome(o)
+	}
+	for _, o := range revisions {
+		armFor(o.Arm).RecipesRevised = experimentOutcome(o)
+	}
+
+	sort.Strings(order[defined:])
+	report := &AIExperimentReport{Experiment: *exp, Arms: make([]AIExperimentArmReport, 0, len(order))}
+	for _, name := range order {
+		report.Arms = append(report.Arms, *arms[name])
+	}
+	return report, nil
+}
+
+func experimentOutcome(o repository.AIExperimentArmOutcome) AIExperimentOutcome {
+	out := AIExperimentOutcome{Total: o.Total, Good: o.Good}
+	if o.Total > 0 {
+		out.Rate = float64(o.Good) / float64(o.Total)
+	}
+	return out
+}
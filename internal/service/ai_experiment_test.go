@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+)
+
+// fakeExperimentRepo keeps experiments in memory and serves fixed report rows.
+type fakeExperimentRepo struct {
+	exps        []models.AIExperiment
+	usage       []repository.AIExperimentArmUsage
+	extractions []repository.AIExperimentArmOutcome
+}
+
+func (r *fakeExperimentRepo) ListExperiments() ([]models.AIExperiment, error) {
+	return append([]models.AIExperiment(nil), r.exps...), nil
+}
+
+func (r *fakeExperimentRepo) ListRunning() ([]models.AIExperiment, error) {
+	var out []models.AIExperiment
+	for _, e := range r.exps {
+		if e.Status == models.AIExperimentRunning {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeExperimentRepo) GetExperiment(id uint) (*models.AIExperiment, error) {
+	for _, e := range r.exps {
+		if e.ID == id {
+			cp := e
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeExperimentRepo) CreateExperiment(exp *models.AIExperiment) error {
+	exp.ID = uint(len(r.exps) + 1)
+	r.exps = append(r.exps, *exp)
+	return nil
+}
+
+func (r *fakeExperimentRepo) UpdateExperiment(exp *models.AIExperiment) error {
+	for i := range r.exps {
+		if r.exps[i].ID == exp.ID {
+			r.exps[i] = *exp
+			return nil
+		}
+	}
+	return errors.New("not found")
+}
+
+func (r *fakeExperimentRepo) ArmUsage(string) ([]repository.AIExperimentArmUsage, error) {
+	return r.usage, nil
+}
+
+func (r *fakeExperimentRepo) ArmExtractions(string) ([]repository.AIExperimentArmOutcome, error) {
+	return r.extractions, nil
+}
+
+func (r *fakeExperimentRepo) ArmFinderRuns(string) ([]repository.AIExperimentArmOutcome, error) {
+	return nil, nil
+}
+
+func (r *fakeExperimentRepo) ArmRecipeRevisions(string) ([]repository.AIExperimentArmOutcome, error) {
+	return nil, nil
+}
+
+func newTestExperiments(repo *fakeExperimentRepo) *AIExperimentManager {
+	m := NewAIExperimentManager(repo, ai.LightKeys{}, nil, nil)
+	m.build = func(spec ai.LightProviderSpec) (ai.TextProvider, error) {
+		return namedTextProvider(spec.Provider + "/" + spec.Model), nil
+	}
+	m.validate = func(context.Context, ai.LightProviderSpec) error { return nil }
+	return m
+}
+
+func qaExperiment(key string) *models.AIExperiment {
+	return &models.AIExperiment{
+		Key:       key,
+		Operation: "CookingQA",
+		Arms: models.AIExperimentArms{
+			{Name: "control", Provider: "anthropic", Model: "claude-haiku-4-5", Percent: 50},
+			{Name: "flash", Provider: "gemini", Model: "gemini-2.0-flash", Percent: 50},
+		},
+	}
+}
+
+func TestAIExperimentManager_StartValidates(t *testing.T) {
+	repo := &fakeExperimentRepo{}
+	m := newTestExperiments(repo)
+	ctx := context.Background()
+
+	bad := map[string]func(*models.AIExperiment){
+		"bad key":       func(e *models.AIExperiment) { e.Key = "Has Spaces" },
+		"unknown op":    func(e *models.AIExperiment) { e.Operation = "MakeCoffee" },
+		"one arm":       func(e *models.AIExperiment) { e.Arms = e.Arms[:1] },
+		"split not 100": func(e *models.AIExperiment) { e.Arms[1].Percent = 40 },
+		"dup names":     func(e *models.AIExperiment) { e.Arms[1].Name = "control" },
+	}
+	for name, mutate := range bad {
+		exp := qaExperiment("qa-test")
+		mutate(exp)
+		if err := m.Start(ctx, exp); !errors.Is(err, ErrInvalidExperiment) {
+			t.Errorf("%s: err = %v, want ErrInvalidExperiment", name, err)
+		}
+	}
+
+	m.validate = func(context.Context, ai.LightProviderSpec) error { return errors.New("probe failed") }
+	if err := m.Start(ctx, qaExperiment("qa-test")); !errors.Is(err, ErrInvalidExperiment) {
+		t.Errorf("failing probe: err = %v, want ErrInvalidExperiment", err)
+	}
+	if len(repo.exps) != 0 {
+		t.Fatalf("rejected experiments must not be saved, have %d", len(repo.exps))
+	}
+}
+
+func TestAIExperimentManager_StartAndStop(t *testing.T) {
+	repo := &fakeExperimentRepo{}
+	m := newTestExperiments(repo)
+	tier := m.Wrap(namedTextProvider("tier"))
+	ctx := ai.WithAttribution(context.Background(), ai.CallAttribution{UserID: 42})
+
+	exp := qaExperiment("qa-test")
+	if err := m.Start(context.Background(), exp); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if got, _ := tier.CookingQA(ctx, "q", ""); got == "tier" {
+		t.Error("an attributed CookingQA call should be served by an arm")
+	}
+	if err := m.Start(context.Background(), qaExperiment("qa-other")); !errors.Is(err, ErrInvalidExperiment) {
+		t.Errorf("second experiment on the same operation: err = %v, want ErrInvalidExperiment", err)
+	}
+
+	if _, err := m.Stop(exp.ID); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if got, _ := tier.CookingQA(ctx, "q", ""); got != "tier" {
+		t.Errorf("after stop, CookingQA served by %q, want the tier", got)
+	}
+	if _, err := m.Stop(99); !errors.Is(err, ErrExperimentNotFound) {
+		t.Errorf("unknown id: err = %v, want ErrExperimentNotFound", err)
+	}
+}
+
+func TestAIExperimentManager_Report(t *testing.T) {
+	repo := &fakeExperimentRepo{
+		usage: []repository.AIExperimentArmUsage{
+			{Arm: "control", Calls: 10, Users: 4, CostUSD: 0.5, AvgDurationMS: 900, SuccessRate: 1},
+			{Arm: "flash", Calls: 8, Users: 3, CostUSD: 0.08, AvgDurationMS: 400, SuccessRate: 0.75},
+		},
+		extractions: []repository.AIExperimentArmOutcome{{Arm: "flash", Total: 4, Good: 3}},
+	}
+	m := newTestExperiments(repo)
+	exp := qaExperiment("qa-test")
+	if err := m.Start(context.Background(), exp); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	report, err := m.Report(exp.ID)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if len(report.Arms) != 2 || report.Arms[0].Name != "control" || report.Arms[1].Name != "flash" {
+		t.Fatalf("arms = %+v, want control then flash", report.Arms)
+	}
+	flash := report.Arms[1]
+	if flash.Model != "gemini-2.0-flash" || flash.CostPerCallUSD != 0.01 {
+		t.Errorf("flash = %+v, want its definition and $0.01 per call", flash)
+	}
+	if flash.Extractions.Rate != 0.75 {
+		t.Errorf("flash extraction rate = %v, want 0.75", flash.Extractions.Rate)
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -23,8 +24,15 @@ type AIModelOptionRepo interface {
 	DeleteOption(id uint) error
 	GetConfig() (*models.AIConfig, error)
 	UpsertConfig(cfg *models.AIConfig) error
+	ListRoutes() ([]models.AIOperationRoute, error)
+	UpsertRoute(route *models.AIOperationRoute) error
+	DeleteRoute(operation string) error
 }
 
+// ErrInvalidModelRoute marks a per-operation route edit that can't be applied
+// (unknown operation, unknown/disabled option, failed probe).
+var ErrInvalidModelRoute = errors.New("invalid model route")
+
 // AIModelManager owns the live light-tier model selection. It exposes a single
 // SwitchableTextProvider (handed to the import/normalize services) and drives
 // what that provider points at:
@@ -35,6 +43,13 @@ type AIModelOptionRepo interface {
 //     the others (the API runs multiple ECS tasks).
 //   - Activate: validate a candidate with a live probe and, only on success,
 //     persist it as active and switch the running provider (fail-closed).
+//   - SetRoute/ClearRoute: pin a single operation (CookingQA, AnalyzeAllergens,
+//     …) to its own registered model, with the same probe-first rule. Routes
+//     apply to the light tier and to any tier wrapped with Route. Image models
+//     sit in the same registry and serve the GenerateImage route, which applies
+//     to the image provider wrapped with RouteImage.
+//   - Per-model RPM/TPM limits on registry rows are pushed to the rate
+//     limiter set with UseRateLimiter on Load, Refresh and every edit.
 //
 // API keys live in LightKeys (from env/SSM), never in the DB.
 type AIModelManager struct {
@@ -44,12 +59,18 @@ type AIModelManager struct {
 	mw       ai.AIMiddleware
 	sw       *ai.SwitchableTextProvider
 	fallback ai.LightProviderSpec
+	router   *ai.OperationRouter
+	light    ai.TextProvider
+	limiter  *ai.RateLimiter
 
-	// validate runs the live probe; overridable in tests to avoid network.
-	validate func(ctx context.Context, spec ai.LightProviderSpec) error
+	// validate and validateImage run the live probes for text and image
+	// models; overridable in tests to avoid network.
+	validate      func(ctx context.Context, spec ai.LightProviderSpec) error
+	validateImage func(ctx context.Context, spec ai.LightProviderSpec) error
 
 	mu     sync.RWMutex
 	active ai.LightProviderSpec
+	routes map[string]ai.LightProviderSpec // applied per-operation routes
 }
 
 // NewAIModelManager builds the manager and its initial provider from the env
@@ -67,6 +88,9 @@ func NewAIModelManager(repo AIModelOptionRepo, keys ai.LightKeys, prompts *confi
 	m.validate = func(ctx context.Context, spec ai.LightProviderSpec) error {
 		return ai.ValidateModel(ctx, spec, m.keys, m.prompts)
 	}
+	m.validateImage = func(ctx context.Context, spec ai.LightProviderSpec) error {
+		return ai.ValidateImageModel(ctx, spec, m.keys)
+	}
 
 	provider, err := ai.BuildLightProvider(fallback, keys, prompts, mw)
 	if err != nil {
@@ -78,11 +102,32 @@ func NewAIModelManager(repo AIModelOptionRepo, keys ai.LightKeys, prompts *confi
 	}
 	m.sw = ai.NewSwitchableTextProvider(provider)
 	m.active = fallback
+	m.router = ai.NewOperationRouter()
+	m.light = m.router.Wrap(m.sw)
 	return m
 }
 
 // Provider returns the switchable TextProvider to hand to dependent services.
-func (m *AIModelManager) Provider() ai.TextProvider { return m.sw }
+// Operations with a route go to their own model.
+func (m *AIModelManager) Provider() ai.TextProvider { return m.light }
+
+// Route wraps another tier's provider so operations with a route go to their
+// own model and everything else stays on tier.
+func (m *AIModelManager) Route(tier ai.TextProvider) ai.TextProvider {
+	return m.router.Wrap(tier)
+}
+
+// RouteImage wraps the default image provider so image generation goes to the
+// GenerateImage route when one is set.
+func (m *AIModelManager) RouteImage(def ai.ImageProvider) ai.ImageProvider {
+	return m.router.WrapImage(def)
+}
+
+// UseRateLimiter has the registry's per-model limits applied to l, which
+// should also sit in the manager's middleware chain.
+func (m *AIModelManager) UseRateLimiter(l *ai.RateLimiter) {
+	m.limiter = l
+}
 
 // GetActive returns the spec currently driving the light tier.
 func (m *AIModelManager) GetActive() ai.LightProviderSpec {
@@ -95,11 +140,14 @@ func (m *AIModelManager) GetActive() ai.LightProviderSpec {
 // then applies whatever the DB marks active. Best-effort: any DB error is
 // logged and leaves the env-default provider running.
 func (m *AIModelManager) Load(ctx context.Context) {
+	m.refreshRoutes()
+
 	if opts, err := m.repo.ListOptions(); err != nil {
 		logger.Get().Warn("ai model manager: list options failed during load", zap.Error(err))
 	} else if len(opts) == 0 {
 		m.seedDefaults()
 	}
+	m.refreshRateLimits()
 
 	cfg, err := m.repo.GetConfig()
 	if err != nil {
@@ -125,9 +173,9 @@ func (m *AIModelManager) Load(ctx context.Context) {
 	}
 }
 
-// StartRefresh polls the active config every interval and applies a change made
-// elsewhere (another instance's live switch). It does NOT re-probe — the spec
-// was already validated when written — it only rebuilds the provider.
+// StartRefresh polls the active config and routes every interval and applies a
+// change made elsewhere (another instance's live switch). It does NOT re-probe —
+// the spec was already validated when written — it only rebuilds the provider.
 func (m *AIModelManager) StartRefresh(ctx context.Context, interval time.Duration) {
 	if interval <= 0 {
 		interval = 30 * time.Second
@@ -146,8 +194,11 @@ func (m *AIModelManager) StartRefresh(ctx context.Context, interval time.Duratio
 	}()
 }
 
-// Refresh reconciles the running provider with the DB's active config.
+// Refresh reconciles the running provider and routes with the DB.
 func (m *AIModelManager) Refresh() {
+	m.refreshRoutes()
+	m.refreshRateLimits()
+
 	cfg, err := m.repo.GetConfig()
 	if err != nil || cfg == nil || cfg.ActiveProvider == "" {
 		return
@@ -193,17 +244,20 @@ func (m *AIModelManager) ListModels() ([]models.AIModelOption, error) {
 // operator sees it in the list, marked invalid with the error) — only a real
 // DB write failure returns an error here.
 func (m *AIModelManager) AddModel(ctx context.Context, opt *models.AIModelOption) error {
-	spec := ai.LightProviderSpec{Provider: opt.Provider, Model: opt.ModelID, BaseURL: opt.BaseURL}
 	now := time.Now()
 	opt.LastValidatedAt = &now
-	if err := m.validate(ctx, spec); err != nil {
+	if err := m.probe(ctx, opt); err != nil {
 		opt.Validated = false
 		opt.ValidationError = err.Error()
 	} else {
 		opt.Validated = true
 		opt.ValidationError = ""
 	}
-	return m.repo.CreateOption(opt)
+	if err := m.repo.CreateOption(opt); err != nil {
+		return err
+	}
+	m.refreshRateLimits()
+	return nil
 }
 
 // UpdateModel applies editable fields to an option. If the model identity
@@ -217,21 +271,28 @@ func (m *AIModelManager) UpdateModel(ctx context.Context, id uint, in *models.AI
 		return nil, fmt.Errorf("model option %d not found", id)
 	}
 
-	identityChanged := existing.Provider != in.Provider || existing.ModelID != in.ModelID || existing.BaseURL != in.BaseURL
+	// An update that doesn't name a kind keeps the option's kind.
+	if in.Kind == "" {
+		in.Kind = existing.Kind
+	}
+	identityChanged := existing.Provider != in.Provider || existing.ModelID != in.ModelID ||
+		existing.BaseURL != in.BaseURL || existing.IsImage() != in.IsImage()
 
+	existing.Kind = in.Kind
 	existing.Provider = in.Provider
 	existing.ModelID = in.ModelID
 	existing.BaseURL = in.BaseURL
 	existing.Label = in.Label
 	existing.InputPricePerMTok = in.InputPricePerMTok
 	existing.OutputPricePerMTok = in.OutputPricePerMTok
+	existing.RPMLimit = in.RPMLimit
+	existing.TPMLimit = in.TPMLimit
 	existing.Enabled = in.Enabled
 
 	if identityChanged {
-		spec := ai.LightProviderSpec{Provider: existing.Provider, Model: existing.ModelID, BaseURL: existing.BaseURL}
 		now := time.Now()
 		existing.LastValidatedAt = &now
-		if verr := m.validate(ctx, spec); verr != nil {
+		if verr := m.probe(ctx, existing); verr != nil {
 			existing.Validated = false
 			existing.ValidationError = verr.Error()
 		} else {
@@ -243,6 +304,7 @@ func (m *AIModelManager) UpdateModel(ctx context.Context, id uint, in *models.AI
 	if err := m.repo.UpdateOption(existing); err != nil {
 		return nil, err
 	}
+	m.refreshRateLimits()
 	return existing, nil
 }
 
@@ -250,7 +312,11 @@ func (m *AIModelManager) UpdateModel(ctx context.Context, id uint, in *models.AI
 // config stores the resolved spec independently, so the running provider is
 // unaffected.
 func (m *AIModelManager) DeleteModel(id uint) error {
-	return m.repo.DeleteOption(id)
+	if err := m.repo.DeleteOption(id); err != nil {
+		return err
+	}
+	m.refreshRateLimits()
+	return nil
 }
 
 // Activate switches the live light tier to the given option — probe FIRST, and
@@ -265,6 +331,9 @@ func (m *AIModelManager) Activate(ctx context.Context, id uint) (*models.AIModel
 	if opt == nil {
 		return nil, fmt.Errorf("model option %d not found", id)
 	}
+	if opt.IsImage() {
+		return nil, fmt.Errorf("model option %d is an image model; route %s to it instead", id, ai.ImageOperation)
+	}
 
 	spec := ai.LightProviderSpec{Provider: opt.Provider, Model: opt.ModelID, BaseURL: opt.BaseURL}
 	now := time.Now()
@@ -302,22 +371,25 @@ func (m *AIModelManager) Activate(ctx context.Context, id uint) (*models.AIModel
 // The entry matching the env default is marked validated (it is what's running);
 // the rest are left unvalidated until probed via the dashboard.
 func (m *AIModelManager) seedDefaults() {
-	type seed struct{ provider, model, label string }
+	type seed struct{ provider, model, label, kind string }
 	anthropicModel := m.keys.AnthropicLightModel
 	if anthropicModel == "" {
 		anthropicModel = ai.DefaultAnthropicLightModel
 	}
 	seeds := []seed{
-		{"anthropic", anthropicModel, "Claude Haiku 4.5"},
-		{"openai", "gpt-4o-mini", "GPT-4o mini"},
-		{"gemini", "gemini-2.0-flash", "Gemini 2.0 Flash"},
-		{"deepseek", "deepseek-chat", "DeepSeek Chat"},
+		{"anthropic", anthropicModel, "Claude Haiku 4.5", models.AIModelKindText},
+		{"openai", "gpt-4o-mini", "GPT-4o mini", models.AIModelKindText},
+		{"gemini", "gemini-2.0-flash", "Gemini 2.0 Flash", models.AIModelKindText},
+		{"deepseek", "deepseek-chat", "DeepSeek Chat", models.AIModelKindText},
+		{"openai", "dall-e-3", "DALL-E 3", models.AIModelKindImage},
+		{"gemini", "imagen-3.0-generate-002", "Imagen 3", models.AIModelKindImage},
 	}
 	for _, s := range seeds {
 		opt := &models.AIModelOption{
 			Provider: s.provider,
 			ModelID:  s.model,
 			Label:    s.label,
+			Kind:     s.kind,
 			Enabled:  true,
 		}
 		if mp, ok := ai.DefaultPricing.Lookup(s.model); ok {
@@ -325,7 +397,7 @@ func (m *AIModelManager) seedDefaults() {
 			opt.OutputPricePerMTok = mp.OutputPerM
 		}
 		// Reflect the running default as already-validated.
-		if s.provider == m.fallback.Provider && (m.fallback.Model == "" || m.fallback.Model == s.model) {
+		if s.kind == models.AIModelKindText && s.provider == m.fallback.Provider && (m.fallback.Model == "" || m.fallback.Model == s.model) {
 			opt.Validated = true
 		}
 		if err := m.repo.CreateOption(opt); err != nil {
@@ -334,3 +406,175 @@ func (m *AIModelManager) seedDefaults() {
 		}
 	}
 }
+
+// ListRoutes returns the per-operation routes.
+func (m *AIModelManager) ListRoutes() ([]models.AIOperationRoute, error) {
+	return m.repo.ListRoutes()
+}
+
+// SetRoute pins operation to a registered option — probe FIRST, and only on a
+// green probe persist the route and switch the running operation. A failed
+// probe records the failure on the option and leaves the current routing
+// untouched (fail-closed, like Activate).
+func (m *AIModelManager) SetRoute(ctx context.Context, operation string, optionID uint) (*models.AIOperationRoute, error) {
+	if !isRoutableOperation(operation) {
+		return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidModelRoute, operation)
+	}
+	opt, err := m.repo.GetOption(optionID)
+	if err != nil {
+		return nil, err
+	}
+	if opt == nil {
+		return nil, fmt.Errorf("%w: model option %d not found", ErrInvalidModelRoute, optionID)
+	}
+	if !opt.Enabled {
+		return nil, fmt.Errorf("%w: model option %d is disabled", ErrInvalidModelRoute, optionID)
+	}
+	if opt.IsImage() != (operation == ai.ImageOperation) {
+		return nil, fmt.Errorf("%w: model option %d can't serve %s", ErrInvalidModelRoute, optionID, operation)
+	}
+
+	spec := ai.LightProviderSpec{Provider: opt.Provider, Model: opt.ModelID, BaseURL: opt.BaseURL}
+	now := time.Now()
+	opt.LastValidatedAt = &now
+	if verr := m.probe(ctx, opt); verr != nil {
+		opt.Validated = false
+		opt.ValidationError = verr.Error()
+		_ = m.repo.UpdateOption(opt)
+		return nil, fmt.Errorf("%w: validation failed, routing unchanged: %v", ErrInvalidModelRoute, verr)
+	}
+	opt.Validated = true
+	opt.ValidationError = ""
+	_ = m.repo.UpdateOption(opt)
+
+	if err := m.buildRoute(operation, spec); err != nil {
+		return nil, fmt.Errorf("model validated but provider build failed: %w", err)
+	}
+	route := &models.AIOperationRoute{
+		Operation: operation,
+		OptionID:  opt.ID,
+		Provider:  spec.Provider,
+		Model:     spec.Model,
+		BaseURL:   spec.BaseURL,
+	}
+	if err := m.repo.UpsertRoute(route); err != nil {
+		return nil, fmt.Errorf("failed to save route: %w", err)
+	}
+	m.refreshRoutes()
+	logger.Get().Info("ai model manager: operation routed",
+		zap.String("operation", operation), zap.String("provider", spec.Provider), zap.String("model", spec.Model))
+	return route, nil
+}
+
+// ClearRoute sends operation back to its tier's provider.
+func (m *AIModelManager) ClearRoute(operation string) error {
+	if !isRoutableOperation(operation) {
+		return fmt.Errorf("%w: unknown operation %q", ErrInvalidModelRoute, operation)
+	}
+	if err := m.repo.DeleteRoute(operation); err != nil {
+		return err
+	}
+	m.refreshRoutes()
+	return nil
+}
+
+// refreshRoutes reconciles the running routing table with the DB. Providers
+// are only rebuilt when the set of routes changed; a route whose provider
+// can't be built (e.g. its key was removed) is skipped so the operation falls
+// back to its tier.
+func (m *AIModelManager) refreshRoutes() {
+	stored, err := m.repo.ListRoutes()
+	if err != nil {
+		logger.Get().Warn("ai model manager: list routes failed, keeping current routing", zap.Error(err))
+		return
+	}
+	specs := make(map[string]ai.LightProviderSpec, len(stored))
+	for _, r := range stored {
+		specs[r.Operation] = ai.LightProviderSpec{Provider: r.Provider, Model: r.Model, BaseURL: r.BaseURL}
+	}
+
+	m.mu.RLock()
+	unchanged := sameRouteSpecs(specs, m.routes)
+	m.mu.RUnlock()
+	if unchanged {
+		return
+	}
+
+	table := make(map[string]ai.TextProvider, len(specs))
+	var image ai.ImageProvider
+	for op, spec := range specs {
+		var err error
+		if op == ai.ImageOperation {
+			image, err = ai.BuildImageProvider(spec, m.keys)
+		} else {
+			table[op], err = ai.BuildLightProvider(spec, m.keys, m.prompts, m.mw)
+		}
+		if err != nil {
+			delete(table, op)
+			logger.Get().Warn("ai model manager: route unbuildable, operation stays on its tier",
+				zap.String("operation", op), zap.String("provider", spec.Provider), zap.Error(err))
+		}
+	}
+	m.router.Set(table)
+	m.router.SetImage(image)
+	m.mu.Lock()
+	m.routes = specs
+	m.mu.Unlock()
+}
+
+// refreshRateLimits pushes the registry's per-model limits to the rate
+// limiter, replacing the previous set so cleared or deleted limits fall back
+// to AI_RATE_LIMITS.
+func (m *AIModelManager) refreshRateLimits() {
+	if m.limiter == nil {
+		return
+	}
+	opts, err := m.repo.ListOptions()
+	if err != nil {
+		logger.Get().Warn("ai model manager: list options failed, keeping current rate limits", zap.Error(err))
+		return
+	}
+	limits := make(map[string]ai.RateLimit, len(opts))
+	for _, opt := range opts {
+		if opt.RPMLimit > 0 || opt.TPMLimit > 0 {
+			limits[opt.Provider+"/"+opt.ModelID] = ai.RateLimit{RPM: opt.RPMLimit, TPM: opt.TPMLimit}
+		}
+	}
+	m.limiter.SetRegistryLimits(limits)
+}
+
+// probe runs the live validation probe that matches the option's kind.
+func (m *AIModelManager) probe(ctx context.Context, opt *models.AIModelOption) error {
+	spec := ai.LightProviderSpec{Provider: opt.Provider, Model: opt.ModelID, BaseURL: opt.BaseURL}
+	if opt.IsImage() {
+		return m.validateImage(ctx, spec)
+	}
+	return m.validate(ctx, spec)
+}
+
+// buildRoute checks that the provider for a route can be built.
+func (m *AIModelManager) buildRoute(operation string, spec ai.LightProviderSpec) error {
+	if operation == ai.ImageOperation {
+		_, err := ai.BuildImageProvider(spec, m.keys)
+		return err
+	}
+	_, err := ai.BuildLightProvider(spec, m.keys, m.prompts, m.mw)
+	return err
+}
+
+// isRoutableOperation reports whether op can be given its own model.
+func isRoutableOperation(op string) bool {
+	return ai.IsTextOperation(op) || op == ai.ImageOperation
+}
+
+func sameRouteSpecs(a, b map[string]ai.LightProviderSpec) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for op, spec := range a {
+		if other, ok := b[op]; !ok || other != spec {
+			return false
+		}
+	}
+	return true
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/windoze95/saltybytes-api/internal/ai"
 	"github.com/windoze95/saltybytes-api/internal/config"
@@ -15,6 +16,7 @@ type fakeOptionRepo struct {
 	opts   []models.AIModelOption
 	nextID uint
 	cfg    *models.AIConfig
+	routes []models.AIOperationRoute
 }
 
 func newFakeOptionRepo() *fakeOptionRepo { return &fakeOptionRepo{nextID: 1} }
@@ -71,6 +73,28 @@ func (r *fakeOptionRepo) UpsertConfig(cfg *models.AIConfig) error {
 	return nil
 }
 
+func (r *fakeOptionRepo) ListRoutes() ([]models.AIOperationRoute, error) {
+	out := make([]models.AIOperationRoute, len(r.routes))
+	copy(out, r.routes)
+	return out, nil
+}
+
+func (r *fakeOptionRepo) UpsertRoute(route *models.AIOperationRoute) error {
+	_ = r.DeleteRoute(route.Operation)
+	r.routes = append(r.routes, *route)
+	return nil
+}
+
+func (r *fakeOptionRepo) DeleteRoute(operation string) error {
+	for i := range r.routes {
+		if r.routes[i].Operation == operation {
+			r.routes = append(r.routes[:i], r.routes[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
 // newTestManager builds a manager with all keys present (so any provider builds)
 // and an anthropic fallback. The validator is overridden per-test.
 func newTestManager(repo AIModelOptionRepo) *AIModelManager {
@@ -87,8 +111,8 @@ func TestAIModelManager_LoadSeedsDefaultsAndActiveConfig(t *testing.T) {
 	m.Load(context.Background())
 
 	opts, _ := repo.ListOptions()
-	if len(opts) != 4 {
-		t.Fatalf("expected 4 seeded options, got %d", len(opts))
+	if len(opts) != 6 {
+		t.Fatalf("expected 6 seeded options (4 text, 2 image), got %d", len(opts))
 	}
 	if repo.cfg == nil || repo.cfg.ActiveProvider != "anthropic" {
 		t.Fatalf("expected active config seeded to anthropic, got %+v", repo.cfg)
@@ -164,3 +188,146 @@ func TestAIModelManager_AddModelSavesProbeOutcome(t *testing.T) {
 		t.Errorf("expected unvalidated option with error, got %+v", stored)
 	}
 }
+
+func TestAIModelManager_UpdateModelKeepsKindWhenOmitted(t *testing.T) {
+	repo := newFakeOptionRepo()
+	m := newTestManager(repo)
+	m.validateImage = func(context.Context, ai.LightProviderSpec) error { return nil }
+	image := &models.AIModelOption{Provider: "gemini", ModelID: "imagen-3.0-generate-002", Kind: models.AIModelKindImage, Enabled: true}
+	_ = repo.CreateOption(image)
+
+	updated, err := m.UpdateModel(context.Background(), image.ID, &models.AIModelOption{Provider: "gemini", ModelID: "imagen-3.0-generate-002", Label: "Imagen", Enabled: true})
+	if err != nil {
+		t.Fatalf("UpdateModel: %v", err)
+	}
+	if updated.Kind != models.AIModelKindImage || updated.Label != "Imagen" {
+		t.Errorf("updated = %+v, want the label changed and the image kind kept", updated)
+	}
+}
+
+func TestAIModelManager_SetRouteRoutesOneOperation(t *testing.T) {
+	repo := newFakeOptionRepo()
+	m := newTestManager(repo)
+	m.validate = func(context.Context, ai.LightProviderSpec) error { return nil }
+
+	opt := &models.AIModelOption{Provider: "gemini", ModelID: "gemini-2.0-flash", Enabled: true}
+	_ = repo.CreateOption(opt)
+
+	route, err := m.SetRoute(context.Background(), "CookingQA", opt.ID)
+	if err != nil {
+		t.Fatalf("SetRoute: %v", err)
+	}
+	if route.Provider != "gemini" || route.Model != "gemini-2.0-flash" {
+		t.Errorf("route = %+v, want gemini/gemini-2.0-flash", route)
+	}
+	if m.routes["CookingQA"].Provider != "gemini" {
+		t.Errorf("applied routes = %v, want CookingQA on gemini", m.routes)
+	}
+
+	// Another instance picks the route up on refresh, and drops it once cleared.
+	other := newTestManager(repo)
+	other.Refresh()
+	if len(other.routes) != 1 {
+		t.Fatalf("refreshed routes = %v, want the CookingQA route", other.routes)
+	}
+	if err := m.ClearRoute("CookingQA"); err != nil {
+		t.Fatalf("ClearRoute: %v", err)
+	}
+	other.Refresh()
+	if len(other.routes) != 0 {
+		t.Errorf("routes after clear = %v, want none", other.routes)
+	}
+}
+
+func TestAIModelManager_SetRouteFailsClosed(t *testing.T) {
+	repo := newFakeOptionRepo()
+	m := newTestManager(repo)
+	m.validate = func(context.Context, ai.LightProviderSpec) error { return errors.New("probe failed") }
+
+	opt := &models.AIModelOption{Provider: "openai", ModelID: "gpt-4o-mini", Enabled: true}
+	_ = repo.CreateOption(opt)
+
+	if _, err := m.SetRoute(context.Background(), "AnalyzeAllergens", opt.ID); !errors.Is(err, ErrInvalidModelRoute) {
+		t.Fatalf("err = %v, want ErrInvalidModelRoute", err)
+	}
+	if len(repo.routes) != 0 {
+		t.Error("a failed probe must not persist a route")
+	}
+	if _, err := m.SetRoute(context.Background(), "MakeCoffee", opt.ID); !errors.Is(err, ErrInvalidModelRoute) {
+		t.Fatalf("unknown operation err = %v, want ErrInvalidModelRoute", err)
+	}
+}
+
+func TestAIModelManager_ImageRouteUsesImageModels(t *testing.T) {
+	repo := newFakeOptionRepo()
+	m := newTestManager(repo)
+	var probed string
+	m.validate = func(context.Context, ai.LightProviderSpec) error { probed = "text"; return nil }
+	m.validateImage = func(context.Context, ai.LightProviderSpec) error { probed = "image"; return nil }
+
+	text := &models.AIModelOption{Provider: "openai", ModelID: "gpt-4o-mini", Kind: models.AIModelKindText, Enabled: true}
+	image := &models.AIModelOption{Provider: "gemini", ModelID: "imagen-3.0-generate-002", Kind: models.AIModelKindImage, Enabled: true}
+	_ = repo.CreateOption(text)
+	_ = repo.CreateOption(image)
+
+	if _, err := m.SetRoute(context.Background(), ai.ImageOperation, text.ID); !errors.Is(err, ErrInvalidModelRoute) {
+		t.Errorf("routing images to a text model: err = %v, want ErrInvalidModelRoute", err)
+	}
+	if _, err := m.SetRoute(context.Background(), "CookingQA", image.ID); !errors.Is(err, ErrInvalidModelRoute) {
+		t.Errorf("routing CookingQA to an image model: err = %v, want ErrInvalidModelRoute", err)
+	}
+	if _, err := m.Activate(context.Background(), image.ID); err == nil {
+		t.Error("an image model must not become the light tier")
+	}
+
+	if _, err := m.SetRoute(context.Background(), ai.ImageOperation, image.ID); err != nil {
+		t.Fatalf("SetRoute(GenerateImage): %v", err)
+	}
+	if probed != "image" {
+		t.Errorf("probe = %s, want the image probe", probed)
+	}
+	if m.routes[ai.ImageOperation].Provider != "gemini" {
+		t.Errorf("applied routes = %v, want GenerateImage on gemini", m.routes)
+	}
+	if err := m.ClearRoute(ai.ImageOperation); err != nil {
+		t.Fatalf("ClearRoute: %v", err)
+	}
+	if len(m.routes) != 0 {
+		t.Errorf("routes after clear = %v, want none", m.routes)
+	}
+}
+
+func TestAIModelManager_RegistryRateLimitsReachLimiter(t *testing.T) {
+	repo := newFakeOptionRepo()
+	m := newTestManager(repo)
+	m.validate = func(context.Context, ai.LightProviderSpec) error { return nil }
+	limiter := ai.NewRateLimiter(map[string]ai.RateLimit{"openai": {RPM: 1}})
+	m.UseRateLimiter(limiter)
+
+	admitTen := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		for i := 0; i < 10; i++ {
+			if _, err := limiter.Admit(ctx, ai.AIOperation{Name: "CookingQA", Provider: "openai", Model: "gpt-4o-mini"}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	opt := &models.AIModelOption{Provider: "openai", ModelID: "gpt-4o-mini", RPMLimit: 600, Enabled: true}
+	if err := m.AddModel(context.Background(), opt); err != nil {
+		t.Fatalf("AddModel: %v", err)
+	}
+	if err := admitTen(); err != nil {
+		t.Fatalf("registry limit of 600 RPM not applied: %v", err)
+	}
+
+	// Deleting the row falls back to the configured 1 RPM.
+	if err := m.DeleteModel(opt.ID); err != nil {
+		t.Fatalf("DeleteModel: %v", err)
+	}
+	if err := admitTen(); err == nil {
+		t.Error("expected the configured 1 RPM to apply after the registry row was deleted")
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/allergens"
 	"github.com/windoze95/saltybytes-api/internal/config"
 	"github.com/windoze95/saltybytes-api/internal/logger"
 	"github.com/windoze95/saltybytes-api/internal/models"
@@ -21,6 +23,11 @@ const AllergenDisclaimer = "AI-generated analysis — does not replace medical a
 // Bump this when the AI prompt changes to invalidate cached results.
 const promptVersion = "v1"
 
+// ErrAllergenAIUnavailable is returned by AnalyzeRecipe when a recipe needs
+// the AI provider (the lexicon doesn't cover every ingredient) and none is
+// configured.
+var ErrAllergenAIUnavailable = errors.New("AI provider is not configured")
+
 // AllergenService is the business logic layer for allergen analysis operations.
 type AllergenService struct {
 	Cfg          *config.Config
@@ -43,10 +50,41 @@ func NewAllergenService(cfg *config.Config, allergenRepo repository.AllergenRepo
 	}
 }
 
-// AllergenAnalysisResponse wraps the analysis model with a disclaimer.
+// AllergenAnalysisResponse wraps the analysis model with a disclaimer and,
+// once WithRegulatorySet is called, the allergens a regulatory set declares.
 type AllergenAnalysisResponse struct {
 	models.AllergenAnalysis
-	Disclaimer string `json:"disclaimer"`
+	Disclaimer    string                    `json:"disclaimer"`
+	RegulatorySet string                    `json:"regulatory_set,omitempty"`
+	Regulated     models.AllergenResultList `json:"regulated,omitempty"`
+	// UsedAI reports whether producing this analysis called the AI provider
+	// (false for cached and rule-only analyses).
+	UsedAI bool `json:"-"`
+}
+
+// WithRegulatorySet fills RegulatorySet and Regulated: the analysis's
+// allergens that the given set requires labels to declare.
+func (r *AllergenAnalysisResponse) WithRegulatorySet(set string) *AllergenAnalysisResponse {
+	r.RegulatorySet = set
+	r.Regulated = models.AllergenResultList{}
+	for _, res := range r.Allergens {
+		if allergens.InSet(set, res.Allergen) {
+			r.Regulated = append(r.Regulated, res)
+		}
+	}
+	return r
+}
+
+// UserAllergenSet returns the regulatory allergen set the user picked, or the
+// one for their region when they have not picked one.
+func UserAllergenSet(user *models.User) string {
+	if user == nil || user.Personalization == nil {
+		return allergens.DefaultSet
+	}
+	if user.Personalization.AllergenSet != "" {
+		return user.Personalization.AllergenSet
+	}
+	return allergens.SetForRegion(UserLocale(user).CountryCode())
 }
 
 // FamilyCheckResponse contains the per-member allergen check results.
@@ -65,11 +103,12 @@ type MemberAllergenResult struct {
 }
 
 // AnalyzeRecipe performs allergen analysis on a recipe's ingredients.
+// Ingredients the allergen lexicon knows are classified by rule; only the
+// rest are sent to the AI provider, which is skipped when none remain. A nil
+// AIProvider still analyzes a recipe the lexicon covers entirely, and fails
+// with ErrAllergenAIUnavailable otherwise. UsedAI on the response reports
+// whether the provider was called, so callers meter only those analyses.
 func (s *AllergenService) AnalyzeRecipe(ctx context.Context, recipeID uint, isPremium bool) (*AllergenAnalysisResponse, error) {
-	if s.AIProvider == nil {
-		return nil, errors.New("AI provider is not configured")
-	}
-
 	// 1. Get recipe and its ingredients
 	recipe, err := s.RecipeRepo.GetRecipeByID(recipeID)
 	if err != nil {
@@ -79,6 +118,7 @@ func (s *AllergenService) AnalyzeRecipe(ctx context.Context, recipeID uint, isPr
 	// 2. Check for cached analysis with the same prompt version and premium tier
 	existing, err := s.AllergenRepo.GetAnalysisByRecipeID(recipeID)
 	if err == nil && existing.PromptVersion == promptVersion && existing.IsPremium == isPremium {
+		rederiveAllergens(existing)
 		existing.Disclaimer = AllergenDisclaimer
 		return &AllergenAnalysisResponse{
 			AllergenAnalysis: *existing,
@@ -86,73 +126,80 @@ func (s *AllergenService) AnalyzeRecipe(ctx context.Context, recipeID uint, isPr
 		}, nil
 	}
 
-	// 3. Build ingredient list for AI analysis
-	ingredients := make([]ai.IngredientInput, len(recipe.Ingredients))
+	// 3. Classify well-known ingredients with the allergen lexicon; only
+	// the rest need the AI provider. Each analysis keeps its ingredient's slot
+	// so the result follows the recipe's order.
+	slots := make([]*models.IngredientAnalysis, len(recipe.Ingredients))
+	var unclassified []ai.IngredientInput
+	var unclassifiedAt []int
 	for i, ing := range recipe.Ingredients {
-		ingredients[i] = ai.IngredientInput{
+		if c, ok := allergens.Classify(ing.Name); ok {
+			analysis := ruleAnalysis(ing.Name, c)
+			slots[i] = &analysis
+			continue
+		}
+		unclassified = append(unclassified, ai.IngredientInput{
 			Name:   ing.Name,
 			Unit:   ing.Unit,
 			Amount: ing.Amount,
-		}
+		})
+		unclassifiedAt = append(unclassifiedAt, i)
 	}
 
-	// 4. Call AI provider for allergen analysis
-	result, err := s.AIProvider.AnalyzeAllergens(ctx, ai.AllergenRequest{
-		Ingredients: ingredients,
-		IsPremium:   isPremium,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("AI allergen analysis failed: %w", err)
+	// 4. Call AI provider for the unclassified ingredients and slot its
+	// analyses back in among the lexicon's
+	confidence, requiresReview, usedAI := 1.0, false, false
+	var unplaced models.IngredientAnalysisList
+	if len(unclassified) > 0 {
+		if s.AIProvider == nil {
+			return nil, ErrAllergenAIUnavailable
+		}
+		result, err := s.AIProvider.AnalyzeAllergens(ctx, ai.AllergenRequest{
+			Ingredients: unclassified,
+			IsPremium:   isPremium,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("AI allergen analysis failed: %w", err)
+		}
+		usedAI = true
+		model := make(models.IngredientAnalysisList, 0, len(result.IngredientAnalyses))
+		for _, ia := range result.IngredientAnalyses {
+			model = append(model, models.IngredientAnalysis{
+				IngredientName:    ia.IngredientName,
+				CommonAllergens:   ia.CommonAllergens,
+				PossibleAllergens: ia.PossibleAllergens,
+				SubIngredients:    ia.SubIngredients,
+				SeedOilRisk:       ia.SeedOilRisk,
+				Confidence:        ia.Confidence,
+				Source:            models.AnalysisSourceModel,
+			})
+		}
+		unplaced = placeModelAnalyses(slots, unclassifiedAt, recipe.Ingredients, model)
+		confidence = result.Confidence
+		requiresReview = result.Confidence < 0.8 || result.RequiresReview
 	}
-
-	// 5. Map AI results to model ingredient analyses
-	ingredientAnalyses := make(models.IngredientAnalysisList, len(result.IngredientAnalyses))
-	for i, ia := range result.IngredientAnalyses {
-		ingredientAnalyses[i] = models.IngredientAnalysis{
-			IngredientName:    ia.IngredientName,
-			CommonAllergens:   ia.CommonAllergens,
-			PossibleAllergens: ia.PossibleAllergens,
-			SubIngredients:    ia.SubIngredients,
-			SeedOilRisk:       ia.SeedOilRisk,
-			Confidence:        ia.Confidence,
+	ingredientAnalyses := make(models.IngredientAnalysisList, 0, len(slots)+len(unplaced))
+	for _, analysis := range slots {
+		if analysis != nil {
+			ingredientAnalyses = append(ingredientAnalyses, *analysis)
 		}
 	}
+	ingredientAnalyses = append(ingredientAnalyses, unplaced...)
 
-	// 6. Set aggregate flags — conservative approach: uncertain = flagged
+	// 5. Derive per-allergen results and aggregate flags — conservative
+	// approach: uncertain = flagged
 	analysis := &models.AllergenAnalysis{
 		RecipeID:           recipeID,
 		IngredientAnalyses: ingredientAnalyses,
-		Confidence:         result.Confidence,
-		RequiresReview:     result.Confidence < 0.8 || result.RequiresReview,
+		Confidence:         confidence,
+		RequiresReview:     requiresReview,
 		IsPremium:          isPremium,
 		PromptVersion:      promptVersion,
 	}
 
-	for _, ia := range ingredientAnalyses {
-		allAllergens := append(ia.CommonAllergens, ia.PossibleAllergens...)
-		for _, allergen := range allAllergens {
-			lower := strings.ToLower(allergen)
-			switch {
-			case containsAny(lower, "nut", "peanut", "almond", "cashew", "walnut", "pecan", "pistachio", "hazelnut", "macadamia"):
-				analysis.ContainsNuts = true
-			case containsAny(lower, "dairy", "milk", "cheese", "butter", "cream", "lactose", "whey", "casein"):
-				analysis.ContainsDairy = true
-			case containsAny(lower, "gluten", "wheat", "barley", "rye"):
-				analysis.ContainsGluten = true
-			case containsAny(lower, "soy", "soya", "soybean"):
-				analysis.ContainsSoy = true
-			case containsAny(lower, "shellfish", "shrimp", "crab", "lobster", "clam", "mussel", "oyster"):
-				analysis.ContainsShellfish = true
-			case containsAny(lower, "egg"):
-				analysis.ContainsEggs = true
-			}
-		}
-		if ia.SeedOilRisk {
-			analysis.ContainsSeedOils = true
-		}
-	}
+	deriveAllergens(analysis)
 
-	// 7. Save to DB (update if existing, create if not)
+	// 6. Save to DB (update if existing, create if not)
 	if existing != nil {
 		analysis.ID = existing.ID
 		analysis.CreatedAt = existing.CreatedAt
@@ -166,13 +213,50 @@ func (s *AllergenService) AnalyzeRecipe(ctx context.Context, recipeID uint, isPr
 		}
 	}
 
-	// 8. Return with disclaimer
+	// 7. Return with disclaimer
 	return &AllergenAnalysisResponse{
 		AllergenAnalysis: *analysis,
 		Disclaimer:       AllergenDisclaimer,
+		UsedAI:           usedAI,
 	}, nil
 }
 
+// placeModelAnalyses puts the provider's analyses into the empty slots of the
+// ingredients they describe (positions in at): by name first, then in order
+// for any the provider renamed. Analyses left over once every slot is filled
+// are returned to go after the rest.
+func placeModelAnalyses(slots []*models.IngredientAnalysis, at []int, ingredients models.Ingredients, model models.IngredientAnalysisList) models.IngredientAnalysisList {
+	var unmatched models.IngredientAnalysisList
+	for i := range model {
+		placed := false
+		for _, slot := range at {
+			if slots[slot] == nil && strings.EqualFold(strings.TrimSpace(ingredients[slot].Name), strings.TrimSpace(model[i].IngredientName)) {
+				slots[slot] = &model[i]
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			unmatched = append(unmatched, model[i])
+		}
+	}
+	var rest models.IngredientAnalysisList
+	for i := range unmatched {
+		placed := false
+		for _, slot := range at {
+			if slots[slot] == nil {
+				slots[slot] = &unmatched[i]
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			rest = append(rest, unmatched[i])
+		}
+	}
+	return rest
+}
+
 // CheckFamily cross-references allergen analysis with family member dietary profiles.
 func (s *AllergenService) CheckFamily(ctx context.Context, recipeID uint, ownerID uint) (*FamilyCheckResponse, error) {
 	// 1. Get allergen analysis for recipe
@@ -210,18 +294,17 @@ func (s *AllergenService) CheckFamily(ctx context.Context, recipeID uint, ownerI
 
 		// Check allergies
 		for _, allergy := range profile.Allergies {
-			allergyLower := strings.ToLower(allergy.Name)
 			for _, ia := range analysis.IngredientAnalyses {
 				// Check common allergens — definite match = unsafe
 				for _, common := range ia.CommonAllergens {
-					if strings.Contains(strings.ToLower(common), allergyLower) || strings.Contains(allergyLower, strings.ToLower(common)) {
+					if allergenTermsMatch(common, allergy.Name) {
 						result.Status = "unsafe"
 						result.Warnings = append(result.Warnings, fmt.Sprintf("%s contains %s (allergy: %s)", ia.IngredientName, common, allergy.Name))
 					}
 				}
 				// Check possible allergens — possible match = caution
 				for _, possible := range ia.PossibleAllergens {
-					if strings.Contains(strings.ToLower(possible), allergyLower) || strings.Contains(allergyLower, strings.ToLower(possible)) {
+					if allergenTermsMatch(possible, allergy.Name) {
 						if result.Status != "unsafe" {
 							result.Status = "caution"
 						}
@@ -230,9 +313,8 @@ func (s *AllergenService) CheckFamily(ctx context.Context, recipeID uint, ownerI
 				}
 				// Check sub-forms of the allergy
 				for _, subForm := range allergy.SubForms {
-					subFormLower := strings.ToLower(subForm)
 					for _, common := range ia.CommonAllergens {
-						if strings.Contains(strings.ToLower(common), subFormLower) || strings.Contains(subFormLower, strings.ToLower(common)) {
+						if allergenTermsMatch(common, subForm) {
 							result.Status = "unsafe"
 							result.Warnings = append(result.Warnings, fmt.Sprintf("%s contains %s (sub-form of %s)", ia.IngredientName, common, allergy.Name))
 						}
@@ -243,10 +325,9 @@ func (s *AllergenService) CheckFamily(ctx context.Context, recipeID uint, ownerI
 
 		// Check intolerances — treated as caution unless already unsafe
 		for _, intolerance := range profile.Intolerances {
-			intoleranceLower := strings.ToLower(intolerance)
 			for _, ia := range analysis.IngredientAnalyses {
 				for _, common := range ia.CommonAllergens {
-					if strings.Contains(strings.ToLower(common), intoleranceLower) || strings.Contains(intoleranceLower, strings.ToLower(common)) {
+					if allergenTermsMatch(common, intolerance) {
 						if result.Status != "unsafe" {
 							result.Status = "caution"
 						}
@@ -284,6 +365,7 @@ func (s *AllergenService) GetAnalysis(ctx context.Context, recipeID uint) (*Alle
 	if err != nil {
 		return nil, fmt.Errorf("failed to get allergen analysis: %w", err)
 	}
+	rederiveAllergens(analysis)
 
 	return &AllergenAnalysisResponse{
 		AllergenAnalysis: *analysis,
@@ -291,12 +373,104 @@ func (s *AllergenService) GetAnalysis(ctx context.Context, recipeID uint) (*Alle
 	}, nil
 }
 
-// containsAny checks if s contains any of the substrings.
-func containsAny(s string, substrs ...string) bool {
-	for _, sub := range substrs {
-		if strings.Contains(s, sub) {
-			return true
+// ruleAnalysis is the ingredient analysis for a lexicon classification.
+func ruleAnalysis(name string, c allergens.Classification) models.IngredientAnalysis {
+	return models.IngredientAnalysis{
+		IngredientName:    name,
+		CommonAllergens:   c.Contains,
+		PossibleAllergens: c.MayContain,
+		SubIngredients:    []string{},
+		SeedOilRisk:       c.SeedOil,
+		Confidence:        1,
+		Source:            models.AnalysisSourceRule,
+		Rule:              c.Entry,
+	}
+}
+
+// legacyFlags maps each aggregate Contains* flag to the taxonomy allergens
+// that set it.
+var legacyFlags = []struct {
+	ids  []string
+	flag func(*models.AllergenAnalysis) *bool
+}{
+	{[]string{allergens.TreeNut, allergens.Peanut}, func(a *models.AllergenAnalysis) *bool { return &a.ContainsNuts }},
+	{[]string{allergens.Milk}, func(a *models.AllergenAnalysis) *bool { return &a.ContainsDairy }},
+	{[]string{allergens.Gluten}, func(a *models.AllergenAnalysis) *bool { return &a.ContainsGluten }},
+	{[]string{allergens.Soy}, func(a *models.AllergenAnalysis) *bool { return &a.ContainsSoy }},
+	{[]string{allergens.Shellfish}, func(a *models.AllergenAnalysis) *bool { return &a.ContainsShellfish }},
+	{[]string{allergens.Egg}, func(a *models.AllergenAnalysis) *bool { return &a.ContainsEggs }},
+}
+
+// deriveAllergens resolves the analysis's ingredient analyses against the
+// allergen taxonomy and fills Allergens, TaxonomyVersion and the aggregate
+// Contains* flags. An ingredient's name, common allergens and sub-ingredients
+// make it "contains"; its possible allergens make it "may_contain". Results
+// include ancestors (almond also reports tree nuts) in taxonomy order.
+func deriveAllergens(analysis *models.AllergenAnalysis) {
+	found := map[string]*models.AllergenResult{}
+	add := func(term, ingredient, status string) {
+		for _, id := range allergens.Resolve(term) {
+			res, ok := found[id]
+			if !ok {
+				a, _ := allergens.Lookup(id)
+				res = &models.AllergenResult{Allergen: id, Name: a.Name, Status: status}
+				found[id] = res
+			}
+			if status == models.AllergenContains {
+				res.Status = models.AllergenContains
+			}
+			if !slices.Contains(res.Ingredients, ingredient) {
+				res.Ingredients = append(res.Ingredients, ingredient)
+			}
+		}
+	}
+
+	seedOils := false
+	for _, ia := range analysis.IngredientAnalyses {
+		add(ia.IngredientName, ia.IngredientName, models.AllergenContains)
+		for _, term := range append(slices.Clone(ia.CommonAllergens), ia.SubIngredients...) {
+			add(term, ia.IngredientName, models.AllergenContains)
+		}
+		for _, term := range ia.PossibleAllergens {
+			add(term, ia.IngredientName, models.AllergenMayContain)
+		}
+		seedOils = seedOils || ia.SeedOilRisk
+	}
+
+	analysis.Allergens = models.AllergenResultList{}
+	for _, a := range allergens.All() {
+		if res, ok := found[a.ID]; ok {
+			analysis.Allergens = append(analysis.Allergens, *res)
+		}
+	}
+	for _, lf := range legacyFlags {
+		flag := lf.flag(analysis)
+		*flag = false
+		for _, id := range lf.ids {
+			if found[id] != nil {
+				*flag = true
+			}
 		}
 	}
-	return false
+	analysis.ContainsSeedOils = seedOils
+	analysis.TaxonomyVersion = allergens.Version
+}
+
+// rederiveAllergens re-runs deriveAllergens on a stored analysis derived
+// with an older taxonomy. Analyses without ingredient analyses keep their
+// stored flags, since there is nothing to derive them from.
+func rederiveAllergens(analysis *models.AllergenAnalysis) {
+	if analysis.TaxonomyVersion == allergens.Version || len(analysis.IngredientAnalyses) == 0 {
+		return
+	}
+	deriveAllergens(analysis)
+}
+
+// allergenTermsMatch reports whether an analysis allergen and a profile
+// allergy name the same thing: the taxonomy relates them ("dairy" and
+// "whey", "tree nuts" and "almonds"), or they are the same words ("Kiwis"
+// and "kiwi"). Terms merely containing one another don't match, so a "pea"
+// allergy isn't flagged by peanuts, and an empty term matches nothing.
+func allergenTermsMatch(analysisTerm, profileTerm string) bool {
+	return allergens.Related(analysisTerm, profileTerm) || allergens.SameTerm(analysisTerm, profileTerm)
 }
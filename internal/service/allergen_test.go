@@ -4,19 +4,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/allergens"
 	"github.com/windoze95/saltybytes-api/internal/config"
 	"github.com/windoze95/saltybytes-api/internal/models"
 	"github.com/windoze95/saltybytes-api/internal/testutil"
 )
 
 // newAllergenTestService wires an AllergenService with a recipe already in the
-// repo and the given allergen repo / AI provider mocks.
+// repo and the given allergen repo / AI provider mocks. The recipe's flour,
+// milk, egg and butter are in the allergen lexicon; its shortening is not, so
+// analysis still reaches the provider.
 func newAllergenTestService(allergenRepo *testutil.MockAllergenRepo, provider ai.TextProvider) (*AllergenService, *testutil.MockRecipeRepo) {
 	recipeRepo := testutil.NewMockRecipeRepo()
 	recipe := testutil.TestRecipe()
+	recipe.Ingredients = append(recipe.Ingredients, models.Ingredient{Name: "Vegetable shortening", Unit: "tbsp", Amount: 1})
 	recipeRepo.Recipes[recipe.ID] = recipe
 
 	svc := NewAllergenService(&config.Config{}, allergenRepo, &testutil.MockFamilyRepo{}, recipeRepo, provider, nil)
@@ -24,10 +29,63 @@ func newAllergenTestService(allergenRepo *testutil.MockAllergenRepo, provider ai
 }
 
 func TestAnalyzeRecipe_NilProvider(t *testing.T) {
-	svc, _ := newAllergenTestService(&testutil.MockAllergenRepo{}, nil)
+	svc, recipeRepo := newAllergenTestService(&testutil.MockAllergenRepo{}, nil)
 
-	if _, err := svc.AnalyzeRecipe(context.Background(), 1, false); err == nil {
-		t.Fatal("expected error when AI provider is not configured")
+	if _, err := svc.AnalyzeRecipe(context.Background(), 1, false); !errors.Is(err, ErrAllergenAIUnavailable) {
+		t.Fatalf("err = %v, want ErrAllergenAIUnavailable for an ingredient only the AI can classify", err)
+	}
+
+	// The lexicon covers every ingredient: no provider needed.
+	recipeRepo.Recipes[1].Ingredients = testutil.TestRecipe().Ingredients
+	resp, err := svc.AnalyzeRecipe(context.Background(), 1, false)
+	if err != nil {
+		t.Fatalf("rule-only analysis without a provider: %v", err)
+	}
+	if resp.UsedAI || len(resp.IngredientAnalyses) != 4 {
+		t.Errorf("UsedAI = %v, %d analyses; want a 4-ingredient rule-only analysis", resp.UsedAI, len(resp.IngredientAnalyses))
+	}
+}
+
+func TestAnalyzeRecipe_KeepsIngredientOrder(t *testing.T) {
+	provider := &testutil.MockTextProvider{
+		AnalyzeAllergensFunc: func(ctx context.Context, req ai.AllergenRequest) (*ai.AllergenResult, error) {
+			// Answered out of order, one under a different name.
+			return &ai.AllergenResult{
+				IngredientAnalyses: []ai.IngredientAnalysisResult{
+					{IngredientName: "shortening", SeedOilRisk: true, Confidence: 0.9},
+					{IngredientName: "House seasoning", CommonAllergens: []string{"fish"}, Confidence: 0.9},
+				},
+				Confidence: 0.9,
+			}, nil
+		},
+	}
+	svc, recipeRepo := newAllergenTestService(&testutil.MockAllergenRepo{}, provider)
+	recipeRepo.Recipes[1].Ingredients = models.Ingredients{
+		{Name: "House seasoning"},
+		{Name: "Milk"},
+		{Name: "Vegetable shortening"},
+		{Name: "Egg"},
+	}
+
+	resp, err := svc.AnalyzeRecipe(context.Background(), 1, false)
+	if err != nil {
+		t.Fatalf("AnalyzeRecipe error: %v", err)
+	}
+	if !resp.UsedAI {
+		t.Error("UsedAI = false, want true")
+	}
+	var got []string
+	for _, ia := range resp.IngredientAnalyses {
+		got = append(got, ia.IngredientName+"/"+ia.Source)
+	}
+	want := []string{
+		"House seasoning/" + models.AnalysisSourceModel,
+		"Milk/" + models.AnalysisSourceRule,
+		"shortening/" + models.AnalysisSourceModel,
+		"Egg/" + models.AnalysisSourceRule,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("analyses = %v, want recipe order %v", got, want)
 	}
 }
 
@@ -123,16 +181,13 @@ func TestAnalyzeRecipe_CreatesAnalysis_StampsPromptVersion(t *testing.T) {
 	var created *models.AllergenAnalysis
 	provider := &testutil.MockTextProvider{
 		AnalyzeAllergensFunc: func(ctx context.Context, req ai.AllergenRequest) (*ai.AllergenResult, error) {
-			// The test recipe has 4 ingredients; all must be forwarded.
-			if len(req.Ingredients) != 4 {
-				t.Errorf("len(Ingredients) = %d, want 4", len(req.Ingredients))
+			// Only the ingredient the lexicon can't classify is forwarded.
+			if len(req.Ingredients) != 1 || req.Ingredients[0].Name != "Vegetable shortening" {
+				t.Errorf("Ingredients = %+v, want only the shortening", req.Ingredients)
 			}
 			return &ai.AllergenResult{
 				IngredientAnalyses: []ai.IngredientAnalysisResult{
-					{IngredientName: "All-purpose flour", CommonAllergens: []string{"gluten", "wheat"}, Confidence: 0.97},
-					{IngredientName: "Milk", CommonAllergens: []string{"dairy"}, Confidence: 0.99},
-					{IngredientName: "Egg", CommonAllergens: []string{"egg"}, Confidence: 0.99},
-					{IngredientName: "Vegetable oil", PossibleAllergens: []string{"soy"}, SeedOilRisk: true, Confidence: 0.7},
+					{IngredientName: "Vegetable shortening", PossibleAllergens: []string{"soy"}, SeedOilRisk: true, Confidence: 0.7},
 				},
 				Confidence:     0.92,
 				RequiresReview: false,
@@ -183,8 +238,57 @@ func TestAnalyzeRecipe_CreatesAnalysis_StampsPromptVersion(t *testing.T) {
 	if resp.Disclaimer != AllergenDisclaimer {
 		t.Errorf("Disclaimer = %q, want standard disclaimer", resp.Disclaimer)
 	}
-	if len(resp.IngredientAnalyses) != 4 {
-		t.Errorf("len(IngredientAnalyses) = %d, want 4", len(resp.IngredientAnalyses))
+	if len(resp.IngredientAnalyses) != 5 {
+		t.Fatalf("len(IngredientAnalyses) = %d, want 5", len(resp.IngredientAnalyses))
+	}
+	// Lexicon and provider analyses together, in recipe order.
+	for i, want := range []struct{ name, source, rule string }{
+		{"All-purpose flour", models.AnalysisSourceRule, "all-purpose flour"},
+		{"Milk", models.AnalysisSourceRule, "milk"},
+		{"Egg", models.AnalysisSourceRule, "egg"},
+		{"Butter", models.AnalysisSourceRule, "butter"},
+		{"Vegetable shortening", models.AnalysisSourceModel, ""},
+	} {
+		ia := resp.IngredientAnalyses[i]
+		if ia.IngredientName != want.name || ia.Source != want.source || ia.Rule != want.rule {
+			t.Errorf("IngredientAnalyses[%d] = %+v, want %s from %s", i, ia, want.name, want.source)
+		}
+	}
+}
+
+func TestAnalyzeRecipe_LexiconOnly_SkipsAI(t *testing.T) {
+	var created *models.AllergenAnalysis
+	provider := &testutil.MockTextProvider{
+		AnalyzeAllergensFunc: func(ctx context.Context, req ai.AllergenRequest) (*ai.AllergenResult, error) {
+			t.Errorf("AI provider called with %+v; every ingredient is in the lexicon", req.Ingredients)
+			return &ai.AllergenResult{}, nil
+		},
+	}
+	allergenRepo := &testutil.MockAllergenRepo{
+		CreateAnalysisFunc: func(analysis *models.AllergenAnalysis) error {
+			created = analysis
+			return nil
+		},
+	}
+	svc, recipeRepo := newAllergenTestService(allergenRepo, provider)
+	recipeRepo.Recipes[1].Ingredients = testutil.TestRecipe().Ingredients
+
+	if _, err := svc.AnalyzeRecipe(context.Background(), 1, false); err != nil {
+		t.Fatalf("AnalyzeRecipe error: %v", err)
+	}
+	if created == nil {
+		t.Fatal("CreateAnalysis was not called")
+	}
+	if created.Confidence != 1 || created.RequiresReview {
+		t.Errorf("confidence = %v, requires review = %v; want a certain rule-only analysis", created.Confidence, created.RequiresReview)
+	}
+	if !created.ContainsGluten || !created.ContainsDairy || !created.ContainsEggs || created.ContainsSoy {
+		t.Errorf("aggregate flags wrong: %+v", created)
+	}
+	for _, ia := range created.IngredientAnalyses {
+		if ia.Source != models.AnalysisSourceRule || ia.Confidence != 1 {
+			t.Errorf("%s: source %q confidence %v, want a rule", ia.IngredientName, ia.Source, ia.Confidence)
+		}
 	}
 }
 
@@ -242,6 +346,118 @@ func TestAnalyzeRecipe_PersistFails(t *testing.T) {
 	}
 }
 
+func TestAnalyzeRecipe_DerivesTaxonomyAllergens(t *testing.T) {
+	var created *models.AllergenAnalysis
+	provider := &testutil.MockTextProvider{
+		AnalyzeAllergensFunc: func(ctx context.Context, req ai.AllergenRequest) (*ai.AllergenResult, error) {
+			return &ai.AllergenResult{
+				IngredientAnalyses: []ai.IngredientAnalysisResult{
+					{IngredientName: "Hummus", CommonAllergens: []string{"sesame"}, SubIngredients: []string{"chickpeas", "tahini"}},
+					{IngredientName: "Protein powder", SubIngredients: []string{"whey concentrate"}},
+					{IngredientName: "Pesto", PossibleAllergens: []string{"cashews"}},
+					{IngredientName: "Nutmeg"},
+				},
+				Confidence: 0.9,
+			}, nil
+		},
+	}
+	allergenRepo := &testutil.MockAllergenRepo{
+		CreateAnalysisFunc: func(analysis *models.AllergenAnalysis) error {
+			created = analysis
+			return nil
+		},
+	}
+	svc, _ := newAllergenTestService(allergenRepo, provider)
+
+	resp, err := svc.AnalyzeRecipe(context.Background(), 1, false)
+	if err != nil {
+		t.Fatalf("AnalyzeRecipe error: %v", err)
+	}
+	if created.TaxonomyVersion != allergens.Version {
+		t.Errorf("TaxonomyVersion = %q, want %q", created.TaxonomyVersion, allergens.Version)
+	}
+
+	got := map[string]models.AllergenResult{}
+	var order []string
+	for _, res := range created.Allergens {
+		got[res.Allergen] = res
+		order = append(order, res.Allergen)
+	}
+	// Pesto names pine nuts outright; the possible cashews stay may_contain.
+	// Milk, egg and wheat come from the recipe's lexicon-classified staples.
+	want := []string{
+		allergens.Milk, allergens.Egg, allergens.TreeNut, allergens.Cashew, allergens.PineNut,
+		allergens.Gluten, allergens.Wheat, allergens.Sesame,
+	}
+	if !slices.Equal(order, want) {
+		t.Fatalf("allergens = %v, want %v in taxonomy order", order, want)
+	}
+	if got[allergens.Milk].Status != models.AllergenContains || !slices.Equal(got[allergens.Milk].Ingredients, []string{"Milk", "Butter", "Protein powder"}) {
+		t.Errorf("milk = %+v, want contained in milk, butter and (via whey) protein powder", got[allergens.Milk])
+	}
+	if got[allergens.Cashew].Status != models.AllergenMayContain {
+		t.Errorf("cashew status = %q, want may_contain", got[allergens.Cashew].Status)
+	}
+	if got[allergens.TreeNut].Status != models.AllergenContains {
+		t.Errorf("tree nut status = %q, want contains (pine nuts)", got[allergens.TreeNut].Status)
+	}
+	if !created.ContainsNuts || !created.ContainsDairy || !created.ContainsGluten || created.ContainsShellfish {
+		t.Errorf("legacy flags: nuts=%v dairy=%v gluten=%v shellfish=%v", created.ContainsNuts, created.ContainsDairy, created.ContainsGluten, created.ContainsShellfish)
+	}
+
+	resp.WithRegulatorySet(allergens.SetANZ)
+	if len(resp.Regulated) != len(want) || resp.RegulatorySet != allergens.SetANZ {
+		t.Errorf("regulated = %+v, want every allergen for anz", resp.Regulated)
+	}
+}
+
+func TestGetAnalysis_RederivesOlderTaxonomy(t *testing.T) {
+	allergenRepo := &testutil.MockAllergenRepo{
+		GetAnalysisByRecipeIDFunc: func(recipeID uint) (*models.AllergenAnalysis, error) {
+			// Stored by the pre-taxonomy flags, which had no sesame.
+			return &models.AllergenAnalysis{ID: 9, RecipeID: recipeID, IngredientAnalyses: models.IngredientAnalysisList{
+				{IngredientName: "Tahini", CommonAllergens: []string{"sesame"}},
+				{IngredientName: "Yogurt", CommonAllergens: []string{"dairy"}},
+				{IngredientName: "Lupin flour", CommonAllergens: []string{"lupin"}},
+			}}, nil
+		},
+	}
+	svc, _ := newAllergenTestService(allergenRepo, &testutil.MockTextProvider{})
+
+	resp, err := svc.GetAnalysis(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetAnalysis error: %v", err)
+	}
+	if resp.TaxonomyVersion != allergens.Version || len(resp.Allergens) != 3 || !resp.ContainsDairy {
+		t.Fatalf("analysis not re-derived: %+v", resp.AllergenAnalysis)
+	}
+
+	var regulated []string
+	for _, res := range resp.WithRegulatorySet(allergens.SetUSTop9).Regulated {
+		regulated = append(regulated, res.Allergen)
+	}
+	if !slices.Equal(regulated, []string{allergens.Milk, allergens.Sesame}) {
+		t.Errorf("US Top 9 regulated = %v, want milk and sesame (lupin is EU-only)", regulated)
+	}
+}
+
+func TestUserAllergenSet(t *testing.T) {
+	tests := []struct {
+		p    *models.Personalization
+		want string
+	}{
+		{nil, allergens.DefaultSet},
+		{&models.Personalization{Region: "DE"}, allergens.SetEU14},
+		{&models.Personalization{Locale: "en-AU"}, allergens.SetANZ},
+		{&models.Personalization{Region: "GB", AllergenSet: allergens.SetCanada}, allergens.SetCanada},
+	}
+	for _, tt := range tests {
+		if got := UserAllergenSet(&models.User{Personalization: tt.p}); got != tt.want {
+			t.Errorf("UserAllergenSet(%+v) = %q, want %q", tt.p, got, tt.want)
+		}
+	}
+}
+
 // --- CheckFamily ---
 
 // checkFamilyFixture wires an AllergenService whose stored analysis flags
@@ -382,6 +598,44 @@ func TestCheckFamily_UnsafeNotDowngradedByCaution(t *testing.T) {
 	}
 }
 
+func TestCheckFamily_TaxonomyMatch(t *testing.T) {
+	// "cow's milk" and "dairy" share no substring; the taxonomy relates them.
+	members := []models.FamilyMember{
+		{ID: 1, FamilyID: 7, Name: "Gina", DietaryProfile: &models.DietaryProfile{
+			Allergies: models.AllergyList{{Name: "cow's milk"}},
+		}},
+	}
+	svc := checkFamilyFixture(members, nil)
+
+	resp, err := svc.CheckFamily(context.Background(), 1, 10)
+	if err != nil {
+		t.Fatalf("CheckFamily error: %v", err)
+	}
+	if resp.MemberResults[0].Status != "unsafe" {
+		t.Errorf("status = %q, want unsafe via taxonomy match (warnings: %v)", resp.MemberResults[0].Status, resp.MemberResults[0].Warnings)
+	}
+}
+
+func TestAllergenTermsMatch(t *testing.T) {
+	tests := []struct {
+		analysis, profile string
+		want              bool
+	}{
+		{"peanut oil", "peanuts", true},
+		{"whey", "dairy", true},
+		{"Kiwis", "kiwi", true},
+		{"peanuts", "pea", false},
+		{"buckwheat", "wheat", false},
+		{"milk", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got := allergenTermsMatch(tt.analysis, tt.profile); got != tt.want {
+			t.Errorf("allergenTermsMatch(%q, %q) = %v, want %v", tt.analysis, tt.profile, got, tt.want)
+		}
+	}
+}
+
 func TestCheckFamily_NoAnalysis(t *testing.T) {
 	svc := NewAllergenService(&config.Config{}, &testutil.MockAllergenRepo{}, &testutil.MockFamilyRepo{}, testutil.NewMockRecipeRepo(), &testutil.MockTextProvider{}, nil)
 
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/config"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+	"github.com/windoze95/saltybytes-api/internal/s3"
+	"go.uber.org/zap"
+)
+
+// Cook log limits.
+const (
+	MaxCookLogPhotos   = 6
+	maxCookLogServings = 100
+	maxCookLogNotes    = 4000
+)
+
+// Cook log errors. The validation errors are safe to show the user.
+var (
+	ErrCookLogNotOwned     = errors.New("cook log not owned by user")
+	ErrCookLogRecipeHidden = errors.New("recipe not visible to user")
+	ErrInvalidCookRating   = errors.New("rating must be between 1 and 5")
+	ErrInvalidCookServings = fmt.Errorf("servings must be between 1 and %d", maxCookLogServings)
+	ErrCookLogNotesTooLong = fmt.Errorf("notes must be at most %d characters", maxCookLogNotes)
+	ErrCookedInFuture      = errors.New("cooked_at is in the future")
+	ErrCookLogNodeMismatch = errors.New("node is not part of this recipe's tree")
+	ErrTooManyCookPhotos   = fmt.Errorf("a cook log can have at most %d photos", MaxCookLogPhotos)
+	ErrUnknownCookPhoto    = errors.New("photo_urls can only remove existing photos")
+)
+
+// CookLogService records the times a recipe was actually cooked ("I made
+// this"), with ratings, notes and photos, and summarizes them per recipe.
+// Anyone who can open a recipe in cooking mode can log cooking it; a log is
+// only visible to, and editable by, the person who cooked.
+type CookLogService struct {
+	Cfg     *config.Config
+	Repo    repository.CookLogRepo
+	Recipes repository.RecipeRepo
+	// Sessions lets people the owner cooks with (see AuthorizeJoin) log the
+	// owner's recipes; nil limits logging to the user's own recipes.
+	Sessions *CookingSessionService
+	now      func() time.Time
+}
+
+// NewCookLogService creates a new CookLogService.
+func NewCookLogService(cfg *config.Config, repo repository.CookLogRepo, recipes repository.RecipeRepo) *CookLogService {
+	return &CookLogService{Cfg: cfg, Repo: repo, Recipes: recipes, now: time.Now}
+}
+
+// CookLogInput is a new cook log. A nil NodeID logs the recipe's active
+// version; a zero CookedAt means now. Servings and Rating are optional.
+// JoinCode is the owner's room code, for a guest outside their family.
+type CookLogInput struct {
+	RecipeID uint
+	NodeID   *uint
+	CookedAt time.Time
+	Servings int
+	Rating   int
+	Notes    string
+	JoinCode string
+}
+
+// CookLogUpdate changes a cook log; nil fields are left as they are.
+// PhotoURLs can only drop photos (added through AddPhoto); dropped photos are
+// deleted from storage.
+type CookLogUpdate struct {
+	CookedAt  *time.Time
+	Servings  *int
+	Rating    *int
+	Notes     *string
+	PhotoURLs []string
+}
+
+// CookLogDraft is a cook log prefilled from a cooking session, offered when
+// the session ends for the cook to rate and confirm.
+type CookLogDraft struct {
+	RecipeID    uint      `json:"recipe_id"`
+	NodeID      *uint     `json:"node_id,omitempty"`
+	RecipeTitle string    `json:"recipe_title"`
+	CookedAt    time.Time `json:"cooked_at"`
+	Notes       string    `json:"notes,omitempty"`
+}
+
+// Log records that userID cooked a recipe.
+func (s *CookLogService) Log(ctx context.Context, userID uint, in CookLogInput) (*models.CookLog, error) {
+	if in.CookedAt.IsZero() {
+		in.CookedAt = s.now()
+	}
+	if err := s.validate(in.CookedAt, in.Servings, in.Rating, in.Notes); err != nil {
+		return nil, err
+	}
+	recipe, err := s.Recipes.GetRecipeByID(in.RecipeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeRecipe(ctx, userID, recipe, in.JoinCode); err != nil {
+		return nil, err
+	}
+	nodeID, title, err := s.cookedVersion(recipe, in.NodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	log := &models.CookLog{
+		RecipeID:    recipe.ID,
+		NodeID:      nodeID,
+		RecipeTitle: title,
+		UserID:      userID,
+		CookedAt:    in.CookedAt,
+		Servings:    in.Servings,
+		Rating:      in.Rating,
+		Notes:       strings.TrimSpace(in.Notes),
+		PhotoURLs:   []string{},
+	}
+	if err := s.Repo.Create(ctx, log); err != nil {
+		return nil, fmt.Errorf("failed to create cook log: %w", err)
+	}
+	return log, nil
+}
+
+// authorizeRecipe checks that userID can see recipe, as for opening it in
+// cooking mode: they own it, or may cook it with the owner.
+func (s *CookLogService) authorizeRecipe(ctx context.Context, userID uint, recipe *models.Recipe, code string) error {
+	if recipe.CreatedByID == userID {
+		return nil
+	}
+	if s.Sessions == nil {
+		return ErrCookLogRecipeHidden
+	}
+	err := s.Sessions.AuthorizeJoin(ctx, userID, recipe, code)
+	if errors.Is(err, ErrNotInvited) {
+		return ErrCookLogRecipeHidden
+	}
+	return err
+}
+
+// validate checks a log's user-supplied fields. Servings and Rating of 0
+// mean not given.
+func (s *CookLogService) validate(cookedAt time.Time, servings, rating int, notes string) error {
+	switch {
+	case rating < 0 || rating > 5:
+		return ErrInvalidCookRating
+	case servings < 0 || servings > maxCookLogServings:
+		return ErrInvalidCookServings
+	case len([]rune(notes)) > maxCookLogNotes:
+		return ErrCookLogNotesTooLong
+	case cookedAt.After(s.now().Add(time.Minute)):
+		return ErrCookedInFuture
+	}
+	return nil
+}
+
+// cookedVersion resolves which node of the recipe's tree was cooked, and
+// the title it had: nodeID when given (it must belong to the recipe), else
+// the active node. Recipes without a tree have no node.
+func (s *CookLogService) cookedVersion(recipe *models.Recipe, nodeID *uint) (*uint, string, error) {
+	title := effectiveRecipeDef(recipe).Title
+	tree, err := s.Recipes.GetTreeByRecipeID(recipe.ID)
+	if err != nil {
+		if nodeID != nil {
+			return nil, "", ErrCookLogNodeMismatch
+		}
+		return nil, title, nil
+	}
+
+	var node *models.RecipeNode
+	if nodeID != nil {
+		node, err = s.Recipes.GetNodeByID(*nodeID)
+		if err != nil || node.TreeID != tree.ID {
+			return nil, "", ErrCookLogNodeMismatch
+		}
+	} else if node, err = s.Recipes.GetActiveNode(tree.ID); err != nil {
+		return nil, title, nil
+	}
+	if node.Response != nil && node.Response.Title != "" {
+		title = node.Response.Title
+	}
+	return &node.ID, title, nil
+}
+
+// Get returns one of the user's cook logs.
+func (s *CookLogService) Get(ctx context.Context, userID, id uint) (*models.CookLog, error) {
+	log, err := s.Repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if log.UserID != userID {
+		return nil, ErrCookLogNotOwned
+	}
+	return log, nil
+}
+
+// List returns a page of the user's cook logs (most recently cooked first),
+// optionally for one recipe, and the total count.
+func (s *CookLogService) List(ctx context.Context, userID, recipeID uint, limit, offset int) ([]models.CookLog, int64, error) {
+	return s.Repo.ListByUser(ctx, userID, recipeID, limit, offset)
+}
+
+// Update changes one of the user's cook logs.
+func (s *CookLogService) Update(ctx context.Context, userID, id uint, upd CookLogUpdate) (*models.CookLog, error) {
+	log, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if upd.CookedAt != nil {
+		log.CookedAt = *upd.CookedAt
+	}
+	if upd.Servings != nil {
+		log.Servings = *upd.Servings
+	}
+	if upd.Rating != nil {
+		log.Rating = *upd.Rating
+	}
+	if upd.Notes != nil {
+		log.Notes = strings.TrimSpace(*upd.Notes)
+	}
+	if err := s.validate(log.CookedAt, log.Servings, log.Rating, log.Notes); err != nil {
+		return nil, err
+	}
+
+	var dropped []string
+	if upd.PhotoURLs != nil {
+		for _, url := range upd.PhotoURLs {
+			if !slices.Contains(log.PhotoURLs, url) {
+				return nil, ErrUnknownCookPhoto
+			}
+		}
+		kept := []string{}
+		for _, url := range log.PhotoURLs {
+			if slices.Contains(upd.PhotoURLs, url) {
+				kept = append(kept, url)
+			} else {
+				dropped = append(dropped, url)
+			}
+		}
+		log.PhotoURLs = kept
+	}
+
+	if err := s.Repo.Update(ctx, log); err != nil {
+		return nil, fmt.Errorf("failed to update cook log: %w", err)
+	}
+	s.deletePhotos(ctx, log, dropped)
+	return log, nil
+}
+
+// AddPhoto uploads a photo and attaches it to one of the user's cook logs.
+// ext includes the leading dot.
+func (s *CookLogService) AddPhoto(ctx context.Context, userID, id uint, img []byte, ext, contentType string) (*models.CookLog, error) {
+	log, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(log.PhotoURLs) >= MaxCookLogPhotos {
+		return nil, ErrTooManyCookPhotos
+	}
+
+	url, err := uploadImageToS3(ctx, s.Cfg, img, s3.GenerateCookLogPhotoKey(userID, log.ID, ext), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload cook log photo: %w", err)
+	}
+	log.PhotoURLs = append(log.PhotoURLs, url)
+	if err := s.Repo.Update(ctx, log); err != nil {
+		s.deletePhotos(ctx, log, []string{url})
+		return nil, fmt.Errorf("failed to update cook log: %w", err)
+	}
+	return log, nil
+}
+
+// Delete removes one of the user's cook logs and its photos.
+func (s *CookLogService) Delete(ctx context.Context, userID, id uint) error {
+	log, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.Repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete cook log: %w", err)
+	}
+	s.deletePhotos(ctx, log, log.PhotoURLs)
+	return nil
+}
+
+// deletePhotos removes a log's photos from storage. Cleanup is best-effort:
+// the log no longer references them, so a failure is only logged.
+func (s *CookLogService) deletePhotos(ctx context.Context, log *models.CookLog, urls []string) {
+	for _, url := range urls {
+		key := s3.CookLogPhotoKeyFromURL(url, log.UserID, log.ID)
+		if key == "" {
+			continue
+		}
+		if err := deleteImageFromS3(ctx, s.Cfg, key); err != nil {
+			logger.Get().Warn("failed to delete cook log photo from S3",
+				zap.Uint("cook_log_id", log.ID),
+				zap.String("s3_key", key),
+				zap.Error(err))
+		}
+	}
+}
+
+// Stats summarizes every cook log of a recipe.
+func (s *CookLogService) Stats(ctx context.Context, recipeID uint) (models.CookStats, error) {
+	stats, err := s.Repo.Stats(ctx, []uint{recipeID})
+	if err != nil {
+		return models.CookStats{}, fmt.Errorf("failed to get cook stats: %w", err)
+	}
+	if st, ok := stats[recipeID]; ok {
+		return st, nil
+	}
+	return models.CookStats{RecipeID: recipeID}, nil
+}
+
+// Draft prefills a cook log from a cooking session: the recipe's active
+// version, cooked now, with the edits made while cooking as notes.
+func (s *CookLogService) Draft(session *models.CookingSession) CookLogDraft {
+	draft := CookLogDraft{
+		RecipeID:    session.RecipeID,
+		RecipeTitle: session.RecipeTitle,
+		CookedAt:    s.now(),
+	}
+	if recipe, err := s.Recipes.GetRecipeByID(session.RecipeID); err == nil {
+		if nodeID, title, err := s.cookedVersion(recipe, nil); err == nil {
+			draft.NodeID, draft.RecipeTitle = nodeID, title
+		}
+	}
+	if len(session.Edits) > 0 {
+		lines := []string{"Changes while cooking:"}
+		for _, edit := range session.Edits {
+			lines = append(lines, "- "+edit.Modification)
+		}
+		draft.Notes = strings.Join(lines, "\n")
+	}
+	return draft
+}
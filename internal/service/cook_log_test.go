@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/config"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// newCookLogService returns a cook log service over the seeded tree fixture
+// (recipe 7, owned by user 1, whose active node is the root) with the clock
+// fixed. User 2 is in user 1's family, so can log their recipes too.
+func newCookLogService(t *testing.T) (*CookLogService, *testutil.MockRecipeRepo, *models.RecipeNode, *models.RecipeNode, time.Time) {
+	t.Helper()
+	recipes := testutil.NewMockRecipeRepo()
+	root, child := seedTreeServiceFixture(t, recipes)
+	logs := testutil.NewMockCookLogRepo()
+	logs.Recipes = recipes
+	svc := NewCookLogService(&config.Config{}, logs, recipes)
+	svc.Sessions = NewCookingSessionService(testutil.NewMockCookingSessionRepo())
+	member := uint(2)
+	svc.Sessions.Families = &testutil.MockFamilyRepo{
+		GetFamilyByOwnerIDFunc: func(ownerID uint) (*models.Family, error) {
+			if ownerID != 1 {
+				return nil, errors.New("not found")
+			}
+			return &models.Family{Members: []models.FamilyMember{{Name: "Partner", UserID: &member}}}, nil
+		},
+	}
+	now := time.Date(2026, 3, 1, 19, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, recipes, root, child, now
+}
+
+func TestCookLog_LogRecordsCookedVersion(t *testing.T) {
+	svc, _, root, child, now := newCookLogService(t)
+	ctx := context.Background()
+
+	log, err := svc.Log(ctx, 2, CookLogInput{RecipeID: 7, Servings: 4, Rating: 5, Notes: "  kids loved it "})
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if log.NodeID == nil || *log.NodeID != root.ID || !log.CookedAt.Equal(now) ||
+		log.UserID != 2 || log.Notes != "kids loved it" || log.RecipeTitle != root.Response.Title {
+		t.Errorf("log = %+v, want the active node cooked now", log)
+	}
+
+	log, err = svc.Log(ctx, 2, CookLogInput{RecipeID: 7, NodeID: &child.ID, CookedAt: now.Add(-24 * time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *log.NodeID != child.ID || log.RecipeTitle != "Fluffier Pancakes" {
+		t.Errorf("log = %+v, want the chosen node and its title", log)
+	}
+}
+
+func TestCookLog_OnlyVisibleRecipes(t *testing.T) {
+	svc, _, _, _, _ := newCookLogService(t)
+	ctx := context.Background()
+
+	if _, err := svc.Log(ctx, 3, CookLogInput{RecipeID: 7}); !errors.Is(err, ErrCookLogRecipeHidden) {
+		t.Errorf("stranger's Log error = %v, want ErrCookLogRecipeHidden", err)
+	}
+
+	// A guest the owner invited into their kitchen can log while the code is
+	// valid.
+	recipe, _ := svc.Recipes.GetRecipeByID(7)
+	session, err := svc.Sessions.CreateJoinCode(ctx, 1, recipe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Log(ctx, 3, CookLogInput{RecipeID: 7, JoinCode: session.JoinCode}); err != nil {
+		t.Errorf("invited guest's Log error = %v", err)
+	}
+
+	svc.Sessions = nil
+	if _, err := svc.Log(ctx, 2, CookLogInput{RecipeID: 7}); !errors.Is(err, ErrCookLogRecipeHidden) {
+		t.Errorf("without sessions, a family member's Log error = %v, want ErrCookLogRecipeHidden", err)
+	}
+	if _, err := svc.Log(ctx, 1, CookLogInput{RecipeID: 7}); err != nil {
+		t.Errorf("owner's Log error = %v", err)
+	}
+}
+
+func TestCookLog_Validation(t *testing.T) {
+	svc, recipes, _, _, now := newCookLogService(t)
+	ctx := context.Background()
+	recipes.Recipes[8] = &models.Recipe{Model: gorm.Model{ID: 8}, CreatedByID: 1}
+	stray := uint(999)
+
+	tests := []struct {
+		name string
+		in   CookLogInput
+		want error
+	}{
+		{"rating too high", CookLogInput{RecipeID: 7, Rating: 6}, ErrInvalidCookRating},
+		{"negative servings", CookLogInput{RecipeID: 7, Servings: -1}, ErrInvalidCookServings},
+		{"notes too long", CookLogInput{RecipeID: 7, Notes: strings.Repeat("a", maxCookLogNotes+1)}, ErrCookLogNotesTooLong},
+		{"cooked tomorrow", CookLogInput{RecipeID: 7, CookedAt: now.Add(24 * time.Hour)}, ErrCookedInFuture},
+		{"node from elsewhere", CookLogInput{RecipeID: 7, NodeID: &stray}, ErrCookLogNodeMismatch},
+		{"node on a recipe without a tree", CookLogInput{RecipeID: 8, NodeID: &stray}, ErrCookLogNodeMismatch},
+	}
+	for _, tt := range tests {
+		if _, err := svc.Log(ctx, 1, tt.in); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	var notFound repository.NotFoundError
+	if _, err := svc.Log(ctx, 1, CookLogInput{RecipeID: 99}); !errors.As(err, &notFound) {
+		t.Errorf("missing recipe error = %v", err)
+	}
+}
+
+func TestCookLog_PhotosAndOwnership(t *testing.T) {
+	svc, _, _, _, _ := newCookLogService(t)
+	ctx := context.Background()
+	orig := uploadImageToS3
+	uploadImageToS3 = func(_ context.Context, _ *config.Config, _ []byte, s3Key, _ string) (string, error) {
+		return "https://bucket.s3.us-east-2.amazonaws.com/" + s3Key, nil
+	}
+	t.Cleanup(func() { uploadImageToS3 = orig })
+	deleted := stubS3Delete(t, nil)
+
+	log, err := svc.Log(ctx, 1, CookLogInput{RecipeID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if log, err = svc.AddPhoto(ctx, 1, log.ID, []byte("img"), ".jpg", "image/jpeg"); err != nil {
+			t.Fatalf("AddPhoto() error = %v", err)
+		}
+	}
+	if len(log.PhotoURLs) != 2 || !strings.Contains(log.PhotoURLs[0], "/cook-logs/1/") {
+		t.Fatalf("photos = %v", log.PhotoURLs)
+	}
+	first, second := log.PhotoURLs[0], log.PhotoURLs[1]
+
+	if _, err := svc.Get(ctx, 2, log.ID); !errors.Is(err, ErrCookLogNotOwned) {
+		t.Errorf("another user's Get error = %v", err)
+	}
+	if _, err := svc.AddPhoto(ctx, 2, log.ID, []byte("img"), ".jpg", "image/jpeg"); !errors.Is(err, ErrCookLogNotOwned) {
+		t.Errorf("another user's AddPhoto error = %v", err)
+	}
+
+	// Photos can be dropped but not smuggled in.
+	if _, err := svc.Update(ctx, 1, log.ID, CookLogUpdate{PhotoURLs: []string{"https://example.com/x.jpg"}}); !errors.Is(err, ErrUnknownCookPhoto) {
+		t.Errorf("foreign photo error = %v", err)
+	}
+	rating := 4
+	log, err = svc.Update(ctx, 1, log.ID, CookLogUpdate{Rating: &rating, PhotoURLs: []string{second}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log.Rating != 4 || len(log.PhotoURLs) != 1 || log.PhotoURLs[0] != second {
+		t.Errorf("updated log = %+v", log)
+	}
+	if len(*deleted) != 1 || !strings.HasSuffix(first, (*deleted)[0]) {
+		t.Errorf("deleted %v, want the dropped photo", *deleted)
+	}
+
+	if err := svc.Delete(ctx, 1, log.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(*deleted) != 2 || !strings.HasSuffix(second, (*deleted)[1]) {
+		t.Errorf("deleted %v, want the remaining photo too", *deleted)
+	}
+	if _, err := svc.Get(ctx, 1, log.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("deleted log Get error = %v", err)
+	}
+}
+
+func TestCookLog_StatsAndDraft(t *testing.T) {
+	svc, _, root, _, now := newCookLogService(t)
+	ctx := context.Background()
+
+	if stats, _ := svc.Stats(ctx, 7); stats.TimesCooked != 0 || stats.RecipeID != 7 {
+		t.Errorf("stats before cooking = %+v", stats)
+	}
+	_, _ = svc.Log(ctx, 1, CookLogInput{RecipeID: 7, Rating: 5, CookedAt: now.Add(-48 * time.Hour)})
+	_, _ = svc.Log(ctx, 2, CookLogInput{RecipeID: 7, Rating: 2, CookedAt: now.Add(-time.Hour)})
+	_, _ = svc.Log(ctx, 1, CookLogInput{RecipeID: 7, CookedAt: now.Add(-24 * time.Hour)})
+
+	stats, err := svc.Stats(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TimesCooked != 3 || stats.RatingCount != 2 || stats.AverageRating != 3.5 ||
+		stats.LastCookedAt == nil || !stats.LastCookedAt.Equal(now.Add(-time.Hour)) {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	draft := svc.Draft(&models.CookingSession{
+		RecipeID:    7,
+		RecipeTitle: "old title",
+		Edits:       models.CookingEditList{{StepIndex: 1, Modification: "used oat milk"}},
+	})
+	if draft.NodeID == nil || *draft.NodeID != root.ID || draft.RecipeTitle != root.Response.Title ||
+		!draft.CookedAt.Equal(now) || draft.Notes != "Changes while cooking:\n- used oat milk" {
+		t.Errorf("draft = %+v", draft)
+	}
+}
+
+func TestListUserRecipes_SortsByCookHistory(t *testing.T) {
+	recipes := testutil.NewMockRecipeRepo()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for id := uint(1); id <= 3; id++ {
+		recipes.Recipes[id] = &models.Recipe{Model: gorm.Model{ID: id, CreatedAt: base.Add(time.Duration(id) * time.Hour)}, CreatedByID: 1}
+	}
+	logs := testutil.NewMockCookLogRepo()
+	logs.Recipes = recipes
+	ctx := context.Background()
+	for _, l := range []models.CookLog{
+		{RecipeID: 1, UserID: 1, Rating: 3, CookedAt: base.Add(72 * time.Hour)},
+		{RecipeID: 1, UserID: 1, CookedAt: base.Add(96 * time.Hour)},
+		{RecipeID: 2, UserID: 1, Rating: 5, CookedAt: base.Add(48 * time.Hour)},
+	} {
+		_ = logs.Create(ctx, &l)
+	}
+	svc := NewRecipeService(&config.Config{}, recipes, nil, nil)
+	svc.CookLogs = logs
+
+	for sort, want := range map[string]string{
+		"":            "3,2,1",
+		"last_cooked": "1,2,3",
+		"most_cooked": "1,2,3",
+		"top_rated":   "2,1,3",
+	} {
+		items, total, err := svc.ListUserRecipes(ctx, 1, sort, 1, 10)
+		if err != nil {
+			t.Fatalf("sort %q: %v", sort, err)
+		}
+		var ids []string
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		if got := strings.Join(ids, ","); total != 3 || (sort != "" && got != want) {
+			t.Errorf("sort %q = %s (total %d), want %s", sort, got, total, want)
+		}
+		for _, item := range items {
+			if (item.ID == "3") != (item.CookStats == nil) {
+				t.Errorf("sort %q: recipe %s cook stats = %+v", sort, item.ID, item.CookStats)
+			}
+		}
+	}
+
+	if _, _, err := svc.ListUserRecipes(ctx, 1, "alphabetical", 1, 10); !errors.Is(err, ErrInvalidRecipeSort) {
+		t.Errorf("unknown sort error = %v", err)
+	}
+}
+
+func TestSearchUserRecipes_CarriesCookStats(t *testing.T) {
+	recipes := testutil.NewMockRecipeRepo()
+	recipes.Recipes[1] = &models.Recipe{Model: gorm.Model{ID: 1}, CreatedByID: 1}
+	recipes.Recipes[2] = &models.Recipe{Model: gorm.Model{ID: 2}, CreatedByID: 1}
+	logs := testutil.NewMockCookLogRepo()
+	logs.Recipes = recipes
+	ctx := context.Background()
+	_ = logs.Create(ctx, &models.CookLog{RecipeID: 2, UserID: 1, Rating: 4, CookedAt: time.Now()})
+
+	svc := NewRecipeService(&config.Config{}, recipes, nil, nil)
+	svc.CookLogs = logs
+	svc.VectorRepo = &testutil.MockVectorRepo{
+		SearchUserRecipesByTitleFunc: func(uint, string, bool, int) ([]models.Recipe, error) {
+			return []models.Recipe{*recipes.Recipes[1], *recipes.Recipes[2]}, nil
+		},
+	}
+
+	items, _, err := svc.SearchUserRecipes(ctx, 1, "pancakes", 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].CookStats != nil || items[1].CookStats == nil || items[1].CookStats.TimesCooked != 1 {
+		t.Errorf("items = %+v, want cook stats on the cooked recipe only", items)
+	}
+}
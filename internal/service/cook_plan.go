@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Errors returned when building a cook plan.
+var (
+	ErrNoPlanRecipes      = errors.New("a cook plan needs at least one recipe")
+	ErrTooManyPlanRecipes = fmt.Errorf("a cook plan can have at most %d recipes", maxPlanRecipes)
+	ErrPlanRecipeNotOwned = errors.New("recipe not owned by user")
+	ErrPlanRecipeNoSteps  = errors.New("recipe has no steps to plan")
+)
+
+const (
+	// maxPlanRecipes bounds how many dishes one plan juggles.
+	maxPlanRecipes = 6
+	// defaultStepMinutes is assumed for steps whose text gives no duration.
+	defaultStepMinutes = 5
+	// defaultPreheatMinutes is assumed for an oven preheat with no duration.
+	defaultPreheatMinutes = 10
+	// Kitchen capacity assumed when a request doesn't give its own.
+	defaultPlanOvens   = 1
+	defaultPlanBurners = 4
+)
+
+// CookPlanService schedules several recipes so they finish together. Step
+// durations, equipment and dependencies come from an AI pass when the text
+// provider supports one (ai.CookStepAnalyzer), and otherwise from the step
+// text: durations it mentions, oven and stovetop words, and each step waiting
+// on the one before.
+type CookPlanService struct {
+	TextProvider ai.TextProvider // optional; nil plans from the step text
+	Recipes      repository.RecipeRepo
+	now          func() time.Time
+}
+
+// NewCookPlanService creates a new CookPlanService.
+func NewCookPlanService(textProvider ai.TextProvider, recipes repository.RecipeRepo) *CookPlanService {
+	return &CookPlanService{TextProvider: textProvider, Recipes: recipes, now: time.Now}
+}
+
+// CookPlanRequest asks for a plan for the given recipes. A zero ServeAt, or
+// one too soon to fit every step, serves as soon as possible. Ovens and
+// Burners describe the kitchen; zero uses one oven and four burners.
+type CookPlanRequest struct {
+	RecipeIDs []uint    `json:"recipe_ids"`
+	ServeAt   time.Time `json:"serve_at"`
+	Ovens     int       `json:"ovens,omitempty"`
+	Burners   int       `json:"burners,omitempty"`
+}
+
+// CookPlan is a merged timeline of several recipes' steps, ordered by start
+// time, with every dish finishing at ServeAt.
+type CookPlan struct {
+	StartAt   time.Time      `json:"start_at"`
+	ServeAt   time.Time      `json:"serve_at"`
+	Steps     []PlannedStep  `json:"steps"`
+	Conflicts []PlanConflict `json:"conflicts,omitempty"`
+}
+
+// PlannedStep is one recipe step placed on a plan's timeline. Step is
+// 1-based.
+type PlannedStep struct {
+	RecipeID    uint      `json:"recipe_id"`
+	RecipeTitle string    `json:"recipe_title"`
+	Step        int       `json:"step"`
+	Text        string    `json:"text"`
+	Minutes     int       `json:"minutes"`
+	Equipment   string    `json:"equipment,omitempty"` // ai.EquipmentOven, ai.EquipmentBurner
+	StartAt     time.Time `json:"start_at"`
+	EndAt       time.Time `json:"end_at"`
+}
+
+// PlanConflict flags a stretch of the plan that needs more ovens or burners
+// than the kitchen has.
+type PlanConflict struct {
+	Equipment string        `json:"equipment"`
+	StartAt   time.Time     `json:"start_at"`
+	EndAt     time.Time     `json:"end_at"`
+	InUse     int           `json:"in_use"`
+	Available int           `json:"available"`
+	Steps     []PlanStepRef `json:"steps"`
+}
+
+// PlanStepRef names a step in a plan.
+type PlanStepRef struct {
+	RecipeID uint `json:"recipe_id"`
+	Step     int  `json:"step"`
+}
+
+// dishSchedule is one recipe's steps positioned relative to serving time.
+type dishSchedule struct {
+	recipeID uint
+	title    string
+	steps    []string
+	timings  []ai.CookStepTiming
+	// startBefore and endBefore are how long before serving each step starts
+	// and ends.
+	startBefore []time.Duration
+	endBefore   []time.Duration
+}
+
+// BuildPlan plans the user's recipes to finish together.
+func (s *CookPlanService) BuildPlan(ctx context.Context, userID uint, req CookPlanRequest) (*CookPlan, error) {
+	ids := uniqueRecipeIDs(req.RecipeIDs)
+	if len(ids) == 0 {
+		return nil, ErrNoPlanRecipes
+	}
+	if len(ids) > maxPlanRecipes {
+		return nil, ErrTooManyPlanRecipes
+	}
+
+	var dishes []*dishSchedule
+	var lead time.Duration
+	for _, id := range ids {
+		recipe, err := s.Recipes.GetRecipeByID(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get recipe %d: %w", id, err)
+		}
+		if recipe.CreatedByID != userID {
+			return nil, ErrPlanRecipeNotOwned
+		}
+		def := activeRecipeDef(s.Recipes, recipe)
+		if len(def.Instructions) == 0 {
+			return nil, fmt.Errorf("%w: %q", ErrPlanRecipeNoSteps, def.Title)
+		}
+		dish := &dishSchedule{recipeID: id, title: def.Title, steps: def.Instructions}
+		dish.timings = s.stepTimings(ctx, dish)
+		dish.schedule()
+		for _, before := range dish.startBefore {
+			lead = max(lead, before)
+		}
+		dishes = append(dishes, dish)
+	}
+
+	serveAt := req.ServeAt
+	if earliest := s.now().Add(lead); serveAt.Before(earliest) {
+		serveAt = earliest
+	}
+	serveAt = serveAt.Truncate(time.Second)
+
+	plan := &CookPlan{StartAt: serveAt.Add(-lead), ServeAt: serveAt}
+	for _, dish := range dishes {
+		for i, text := range dish.steps {
+			plan.Steps = append(plan.Steps, PlannedStep{
+				RecipeID:    dish.recipeID,
+				RecipeTitle: dish.title,
+				Step:        i + 1,
+				Text:        text,
+				Minutes:     dish.timings[i].Minutes,
+				Equipment:   dish.timings[i].Equipment,
+				StartAt:     serveAt.Add(-dish.startBefore[i]),
+				EndAt:       serveAt.Add(-dish.endBefore[i]),
+			})
+		}
+	}
+	sort.SliceStable(plan.Steps, func(i, j int) bool {
+		return plan.Steps[i].StartAt.Before(plan.Steps[j].StartAt)
+	})
+
+	ovens, burners := req.Ovens, req.Burners
+	if ovens <= 0 {
+		ovens = defaultPlanOvens
+	}
+	if burners <= 0 {
+		burners = defaultPlanBurners
+	}
+	plan.Conflicts = append(equipmentConflicts(plan.Steps, ai.EquipmentOven, ovens),
+		equipmentConflicts(plan.Steps, ai.EquipmentBurner, burners)...)
+	return plan, nil
+}
+
+// stepTimings times a dish's steps with the AI pass, falling back to the
+// step text when the provider can't or the answer is unusable. Steps the AI
+// gives no duration take theirs from the text.
+func (s *CookPlanService) stepTimings(ctx context.Context, dish *dishSchedule) []ai.CookStepTiming {
+	fromText := make([]ai.CookStepTiming, len(dish.steps))
+	for i, step := range dish.steps {
+		fromText[i] = stepTimingFromText(step)
+		if i > 0 {
+			fromText[i].DependsOn = []int{i}
+		}
+	}
+	if s.TextProvider == nil {
+		return fromText
+	}
+
+	timings, err := ai.AnalyzeCookSteps(ctx, s.TextProvider, ai.CookStepsRequest{Title: dish.title, Steps: dish.steps})
+	if err != nil || len(timings) != len(dish.steps) {
+		if err != nil && !errors.Is(err, ai.ErrCookStepsUnsupported) {
+			logger.Get().Warn("failed to analyze cook steps; timing from step text",
+				zap.Uint("recipe_id", dish.recipeID), zap.Error(err))
+		}
+		return fromText
+	}
+	for i := range timings {
+		if timings[i].Minutes <= 0 {
+			timings[i].Minutes = fromText[i].Minutes
+		}
+		// Only earlier steps can be waited on, which also rules out cycles.
+		var deps []int
+		for _, d := range timings[i].DependsOn {
+			if d >= 1 && d <= i {
+				deps = append(deps, d)
+			}
+		}
+		timings[i].DependsOn = deps
+	}
+	return timings
+}
+
+// schedule places each step as late as its dependents allow: a step no
+// other step waits on ends at serving time, and every other step ends when
+// the earliest step waiting on it starts.
+func (d *dishSchedule) schedule() {
+	n := len(d.steps)
+	d.startBefore = make([]time.Duration, n)
+	d.endBefore = make([]time.Duration, n)
+	// Dependencies point at earlier steps, so walking backwards settles every
+	// step waiting on a step before the step itself.
+	for i := n - 1; i >= 0; i-- {
+		d.startBefore[i] = d.endBefore[i] + time.Duration(d.timings[i].Minutes)*time.Minute
+		for _, dep := range d.timings[i].DependsOn {
+			d.endBefore[dep-1] = max(d.endBefore[dep-1], d.startBefore[i])
+		}
+	}
+}
+
+// equipmentConflicts finds the stretches where more steps use equipment than
+// capacity allows, merging back-to-back stretches into one conflict.
+func equipmentConflicts(steps []PlannedStep, equipment string, capacity int) []PlanConflict {
+	var using []PlannedStep
+	var bounds []time.Time
+	for _, st := range steps {
+		if st.Equipment == equipment && st.EndAt.After(st.StartAt) {
+			using = append(using, st)
+			bounds = append(bounds, st.StartAt, st.EndAt)
+		}
+	}
+	if len(using) <= capacity {
+		return nil
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i].Before(bounds[j]) })
+
+	var conflicts []PlanConflict
+	for k := 0; k+1 < len(bounds); k++ {
+		from, to := bounds[k], bounds[k+1]
+		if !to.After(from) {
+			continue
+		}
+		var active []PlanStepRef
+		for _, st := range using {
+			if st.StartAt.Before(to) && st.EndAt.After(from) {
+				active = append(active, PlanStepRef{RecipeID: st.RecipeID, Step: st.Step})
+			}
+		}
+		if len(active) <= capacity {
+			continue
+		}
+		if last := len(conflicts) - 1; last >= 0 && conflicts[last].EndAt.Equal(from) {
+			c := &conflicts[last]
+			c.EndAt = to
+			c.InUse = max(c.InUse, len(active))
+			for _, ref := range active {
+				if !containsStepRef(c.Steps, ref) {
+					c.Steps = append(c.Steps, ref)
+				}
+			}
+			continue
+		}
+		conflicts = append(conflicts, PlanConflict{
+			Equipment: equipment,
+			StartAt:   from,
+			EndAt:     to,
+			InUse:     len(active),
+			Available: capacity,
+			Steps:     active,
+		})
+	}
+	return conflicts
+}
+
+func containsStepRef(refs []PlanStepRef, ref PlanStepRef) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueRecipeIDs drops zero and repeated IDs, keeping the first occurrence.
+func uniqueRecipeIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	var out []uint
+	for _, id := range ids {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+var (
+	stepDurationRe = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)(?:\s*(?:-|–|to)\s*(\d+(?:\.\d+)?))?\s*(hours?|hrs?|minutes?|mins?|seconds?|secs?)\b`)
+	ovenStepRe     = regexp.MustCompile(`(?i)\b(oven|bake|baking|roast|roasting|broil|broiling|preheat)\b`)
+	burnerStepRe   = regexp.MustCompile(`(?i)\b(stove|stovetop|burner|skillet|pan|saucepan|pot|wok|griddle|boil|boiling|simmer|saut[eé]e?|fry|frying|sear)\b`)
+	preheatStepRe  = regexp.MustCompile(`(?i)\bpreheat`)
+)
+
+// stepTimingFromText reads a step's duration and equipment from its text.
+// Every duration mentioned is added up ("sear 3 minutes, then roast 20
+// minutes"), taking the top of any range; a step without one is assumed to
+// take a few minutes.
+func stepTimingFromText(text string) ai.CookStepTiming {
+	var minutes float64
+	for _, m := range stepDurationRe.FindAllStringSubmatch(text, -1) {
+		amount := m[1]
+		if m[2] != "" {
+			amount = m[2]
+		}
+		v, err := strconv.ParseFloat(amount, 64)
+		if err != nil {
+			continue
+		}
+		switch unit := strings.ToLower(m[3]); {
+		case strings.HasPrefix(unit, "h"):
+			minutes += v * 60
+		case strings.HasPrefix(unit, "s"):
+			minutes += v / 60
+		default:
+			minutes += v
+		}
+	}
+
+	timing := ai.CookStepTiming{Minutes: int(math.Ceil(minutes))}
+	switch {
+	case ovenStepRe.MatchString(text):
+		timing.Equipment = ai.EquipmentOven
+	case burnerStepRe.MatchString(text):
+		timing.Equipment = ai.EquipmentBurner
+	}
+	if timing.Minutes == 0 {
+		timing.Minutes = defaultStepMinutes
+		if preheatStepRe.MatchString(text) {
+			timing.Minutes = defaultPreheatMinutes
+		}
+	}
+	return timing
+}
+
+// activeRecipeDef is the recipe as of its active tree node, falling back to
+// the recipe's own definition for recipes without a tree.
+func activeRecipeDef(repo repository.RecipeRepo, recipe *models.Recipe) models.RecipeDef {
+	if tree, err := repo.GetTreeByRecipeID(recipe.ID); err == nil {
+		if node, err := repo.GetActiveNode(tree.ID); err == nil && node.Response != nil {
+			return *node.Response
+		}
+	}
+	return effectiveRecipeDef(recipe)
+}
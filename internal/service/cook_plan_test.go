@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// newCookPlanService returns a plan service over a roast (1), mash (2) and
+// rolls (3) owned by user 1, with the clock fixed at 17:00.
+func newCookPlanService() (*CookPlanService, time.Time) {
+	repo := testutil.NewMockRecipeRepo()
+	for id, def := range map[uint]models.RecipeDef{
+		1: {Title: "Roast Chicken", Instructions: pq.StringArray{"Preheat oven to 400F", "Roast the chicken for 45 minutes", "Rest 10 minutes before carving"}},
+		2: {Title: "Mashed Potatoes", Instructions: pq.StringArray{"Boil potatoes for 15-20 minutes", "Mash with butter"}},
+		3: {Title: "Dinner Rolls", Instructions: pq.StringArray{"Bake the rolls for 15 minutes"}},
+	} {
+		repo.Recipes[id] = &models.Recipe{Model: gorm.Model{ID: id}, RecipeDef: def, CreatedByID: 1}
+	}
+	svc := NewCookPlanService(nil, repo)
+	now := time.Date(2026, 3, 1, 17, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, now
+}
+
+// plannedStep finds a step in the plan.
+func plannedStep(t *testing.T, plan *CookPlan, recipeID uint, step int) PlannedStep {
+	t.Helper()
+	for _, st := range plan.Steps {
+		if st.RecipeID == recipeID && st.Step == step {
+			return st
+		}
+	}
+	t.Fatalf("plan has no step %d of recipe %d", step, recipeID)
+	return PlannedStep{}
+}
+
+func TestBuildPlan_FinishesTogether(t *testing.T) {
+	svc, now := newCookPlanService()
+	serve := now.Add(2 * time.Hour)
+
+	plan, err := svc.BuildPlan(context.Background(), 1, CookPlanRequest{RecipeIDs: []uint{1, 2, 2}, ServeAt: serve})
+	if err != nil {
+		t.Fatalf("BuildPlan() error = %v", err)
+	}
+	if len(plan.Steps) != 5 {
+		t.Fatalf("plan has %d steps, want 5 (duplicate recipe dropped)", len(plan.Steps))
+	}
+
+	// Roast: preheat 10 + roast 45 + rest 10, so it starts 65 minutes out.
+	if got := plannedStep(t, plan, 1, 1); !got.StartAt.Equal(serve.Add(-65*time.Minute)) || got.Equipment != ai.EquipmentOven {
+		t.Errorf("preheat = %+v, want an oven step 65 minutes before serving", got)
+	}
+	if !plan.StartAt.Equal(serve.Add(-65 * time.Minute)) {
+		t.Errorf("StartAt = %v, want the preheat", plan.StartAt)
+	}
+	// Mash: boil takes the top of its range and every dish ends at serving.
+	if got := plannedStep(t, plan, 2, 1); got.Minutes != 20 || !got.StartAt.Equal(serve.Add(-25*time.Minute)) {
+		t.Errorf("boil = %+v, want 20 minutes starting 25 minutes out", got)
+	}
+	for _, last := range []PlannedStep{plannedStep(t, plan, 1, 3), plannedStep(t, plan, 2, 2)} {
+		if !last.EndAt.Equal(serve) {
+			t.Errorf("recipe %d ends at %v, want %v", last.RecipeID, last.EndAt, serve)
+		}
+	}
+	for i := 1; i < len(plan.Steps); i++ {
+		if plan.Steps[i].StartAt.Before(plan.Steps[i-1].StartAt) {
+			t.Fatal("steps are not ordered by start time")
+		}
+	}
+	if len(plan.Conflicts) != 0 {
+		t.Errorf("conflicts = %+v, want none", plan.Conflicts)
+	}
+}
+
+func TestBuildPlan_FlagsOvenConflict(t *testing.T) {
+	svc, now := newCookPlanService()
+	serve := now.Add(2 * time.Hour)
+
+	plan, err := svc.BuildPlan(context.Background(), 1, CookPlanRequest{RecipeIDs: []uint{1, 3}, ServeAt: serve})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The rolls bake for the last 15 minutes; the chicken is still roasting
+	// for the first 5 of them.
+	if len(plan.Conflicts) != 1 {
+		t.Fatalf("conflicts = %+v, want one", plan.Conflicts)
+	}
+	c := plan.Conflicts[0]
+	if c.Equipment != ai.EquipmentOven || c.InUse != 2 || c.Available != 1 ||
+		!c.StartAt.Equal(serve.Add(-15*time.Minute)) || !c.EndAt.Equal(serve.Add(-10*time.Minute)) {
+		t.Errorf("conflict = %+v", c)
+	}
+
+	plan, _ = svc.BuildPlan(context.Background(), 1, CookPlanRequest{RecipeIDs: []uint{1, 3}, ServeAt: serve, Ovens: 2})
+	if len(plan.Conflicts) != 0 {
+		t.Errorf("with two ovens conflicts = %+v, want none", plan.Conflicts)
+	}
+}
+
+func TestBuildPlan_ServesAsSoonAsPossible(t *testing.T) {
+	svc, now := newCookPlanService()
+
+	for _, serve := range []time.Time{{}, now.Add(10 * time.Minute)} {
+		plan, err := svc.BuildPlan(context.Background(), 1, CookPlanRequest{RecipeIDs: []uint{1}, ServeAt: serve})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !plan.StartAt.Equal(now) || !plan.ServeAt.Equal(now.Add(65*time.Minute)) {
+			t.Errorf("serve %v: plan runs %v to %v, want to start now", serve, plan.StartAt, plan.ServeAt)
+		}
+	}
+}
+
+// stepAnalyzer times every recipe as two parallel prep steps feeding a third.
+type stepAnalyzer struct {
+	*testutil.MockTextProvider
+}
+
+func (stepAnalyzer) AnalyzeCookSteps(ctx context.Context, req ai.CookStepsRequest) ([]ai.CookStepTiming, error) {
+	return []ai.CookStepTiming{
+		{Minutes: 10},
+		{Minutes: 5, DependsOn: []int{2}}, // self-dependency is dropped
+		{Minutes: 20, Equipment: ai.EquipmentBurner, DependsOn: []int{1, 2}},
+	}, nil
+}
+
+func TestBuildPlan_UsesAIStepTiming(t *testing.T) {
+	svc, now := newCookPlanService()
+	svc.TextProvider = stepAnalyzer{&testutil.MockTextProvider{}}
+	serve := now.Add(time.Hour)
+
+	plan, err := svc.BuildPlan(context.Background(), 1, CookPlanRequest{RecipeIDs: []uint{1}, ServeAt: serve})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second := plannedStep(t, plan, 1, 1), plannedStep(t, plan, 1, 2)
+	if !first.EndAt.Equal(serve.Add(-20*time.Minute)) || !second.EndAt.Equal(serve.Add(-20*time.Minute)) {
+		t.Errorf("prep steps end at %v and %v, want both just before the last step", first.EndAt, second.EndAt)
+	}
+	if !plan.StartAt.Equal(serve.Add(-30 * time.Minute)) {
+		t.Errorf("StartAt = %v, want the longer prep step 30 minutes out", plan.StartAt)
+	}
+
+	// A timing that doesn't match the steps falls back to the step text.
+	plan, err = svc.BuildPlan(context.Background(), 1, CookPlanRequest{RecipeIDs: []uint{2}, ServeAt: serve})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := plannedStep(t, plan, 2, 1); got.Minutes != 20 {
+		t.Errorf("boil = %+v, want timing from the step text", got)
+	}
+}
+
+func TestBuildPlan_Errors(t *testing.T) {
+	svc, _ := newCookPlanService()
+	ctx := context.Background()
+
+	if _, err := svc.BuildPlan(ctx, 1, CookPlanRequest{}); !errors.Is(err, ErrNoPlanRecipes) {
+		t.Errorf("no recipes error = %v", err)
+	}
+	if _, err := svc.BuildPlan(ctx, 2, CookPlanRequest{RecipeIDs: []uint{1}}); !errors.Is(err, ErrPlanRecipeNotOwned) {
+		t.Errorf("another user's recipe error = %v", err)
+	}
+	if _, err := svc.BuildPlan(ctx, 1, CookPlanRequest{RecipeIDs: []uint{1, 2, 3, 4, 5, 6, 7}}); !errors.Is(err, ErrTooManyPlanRecipes) {
+		t.Errorf("too many recipes error = %v", err)
+	}
+	if _, err := svc.BuildPlan(ctx, 1, CookPlanRequest{RecipeIDs: []uint{99}}); err == nil {
+		t.Error("a missing recipe should fail")
+	}
+}
+
+func TestStepTimingFromText(t *testing.T) {
+	tests := []struct {
+		text      string
+		minutes   int
+		equipment string
+	}{
+		{"Simmer for 1 hour, then add the cream", 60, ai.EquipmentBurner},
+		{"Sear 3 minutes per side, then roast 20 minutes", 23, ai.EquipmentOven},
+		{"Whisk for 30 seconds", 1, ""},
+		{"Chop the onions", defaultStepMinutes, ""},
+		{"Preheat the oven to 220C", defaultPreheatMinutes, ai.EquipmentOven},
+		{"Fold in the pancake batter", defaultStepMinutes, ""},
+	}
+	for _, tt := range tests {
+		got := stepTimingFromText(tt.text)
+		if got.Minutes != tt.minutes || got.Equipment != tt.equipment {
+			t.Errorf("stepTimingFromText(%q) = %+v, want %d minutes on %q", tt.text, got, tt.minutes, tt.equipment)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// maxCookingChatTurns is how many earlier questions and answers from the
+// session are replayed to the model, so follow-ups resolve against them.
+const maxCookingChatTurns = 6
+
+// CookingQAService answers cooking-mode questions from context the server
+// builds itself: the recipe as of its active tree node, the step the user is
+// on, the session's ephemeral edits, the household's dietary profiles and the
+// user's kitchen notes, plus the session's recent Q&A as conversation
+// history.
+type CookingQAService struct {
+	TextProvider ai.TextProvider
+	Recipes      repository.RecipeRepo
+	// Optional context sources: when nil, or when a lookup fails, that part
+	// of the context is left out.
+	Users    repository.UserRepo
+	Families repository.FamilyRepo
+	Sessions *CookingSessionService
+}
+
+// NewCookingQAService creates a new CookingQAService.
+func NewCookingQAService(textProvider ai.TextProvider, recipes repository.RecipeRepo) *CookingQAService {
+	return &CookingQAService{TextProvider: textProvider, Recipes: recipes}
+}
+
+// CookingQuestion is a question asked in cooking mode. UserID owns the
+// cooking session: the recipe's owner when their kitchen is shared.
+type CookingQuestion struct {
+	UserID   uint
+	RecipeID uint
+	Question string
+	// Step is the 1-based step the user is on; 0 when unknown. The session's
+	// step, shared by every device in the room, takes precedence.
+	Step int
+	// Participants are the users cooking together in the room.
+	Participants []uint
+}
+
+// CookingAnswer is the answer to a cooking question. AllergyAlerts lists the
+// participants' allergies when the question asks for a substitution.
+type CookingAnswer struct {
+	Text          string
+	AllergyAlerts []ParticipantAllergy
+}
+
+// ParticipantAllergy is one participant's allergies, from the dietary
+// profile of their family member entry.
+type ParticipantAllergy struct {
+	UserID    uint     `json:"user_id"`
+	Name      string   `json:"name"`
+	Allergies []string `json:"allergies"`
+}
+
+// substitutionQuestionRe matches questions asking to swap an ingredient.
+// "out of" only counts when someone is out of something, so "take it out of
+// the oven" isn't a substitution question.
+var substitutionQuestionRe = regexp.MustCompile(`(?i)\b(substitut\w*|instead of|in place of|swap\w*|alternatives? (to|for)|sub (in|out|for))\b|\breplac\w* .+ with\b|\b(don['’]?t|do not|didn['’]?t|did not) have\b|\b(i['’]?m|i am|we['’]?re|we are)( all)? out of\b|\b(ran|run|running) out of\b|\buse .+ instead\b`)
+
+// IsSubstitutionQuestion reports whether a cooking question asks for an
+// ingredient substitution.
+func IsSubstitutionQuestion(question string) bool {
+	return substitutionQuestionRe.MatchString(question)
+}
+
+// Answer answers q, passing the answer to onDelta as it streams in, and
+// returns the full answer. Recording the exchange in the session history is
+// left to the caller.
+func (s *CookingQAService) Answer(ctx context.Context, q CookingQuestion, onDelta func(string)) (*CookingAnswer, error) {
+	req, alerts, err := s.chatRequest(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	text, err := ai.StreamCookingChat(ctx, s.TextProvider, req, onDelta)
+	if err != nil {
+		return nil, err
+	}
+	return &CookingAnswer{Text: text, AllergyAlerts: alerts}, nil
+}
+
+// chatRequest gathers the question's context and history, and for a
+// substitution question the participants' allergies to alert on.
+func (s *CookingQAService) chatRequest(ctx context.Context, q CookingQuestion) (ai.CookingChatRequest, []ParticipantAllergy, error) {
+	recipe, err := s.Recipes.GetRecipeByID(q.RecipeID)
+	if err != nil {
+		return ai.CookingChatRequest{}, nil, fmt.Errorf("failed to get recipe: %w", err)
+	}
+	log := logger.Get().With(zap.Uint("recipe_id", q.RecipeID), zap.Uint("user_id", q.UserID))
+
+	grounding := cookingGrounding{
+		Def:          activeRecipeDef(s.Recipes, recipe),
+		Step:         q.Step,
+		Substitution: IsSubstitutionQuestion(q.Question),
+	}
+	var history []ai.Message
+	if s.Sessions != nil {
+		session, err := s.Sessions.Get(ctx, q.UserID, q.RecipeID)
+		if err != nil {
+			log.Warn("failed to load cooking session for Q&A", zap.Error(err))
+		} else if session != nil {
+			grounding.Edits = session.Edits
+			if session.HasStep {
+				grounding.Step = session.CurrentStep
+			}
+			turns := session.History
+			if len(turns) > maxCookingChatTurns {
+				turns = turns[len(turns)-maxCookingChatTurns:]
+			}
+			for _, turn := range turns {
+				history = append(history,
+					ai.Message{Role: "user", Content: turn.Question},
+					ai.Message{Role: "assistant", Content: turn.Answer},
+				)
+			}
+		}
+	}
+	if s.Families != nil {
+		// Most users have no family; a lookup error just means no profiles.
+		family, err := s.Families.GetFamilyByOwnerID(q.UserID)
+		if err == nil && family != nil {
+			grounding.Diet, _ = familyDietSummary(family)
+		}
+		grounding.Participants = s.participantAllergies(q.UserID, family, q.Participants)
+	}
+	if s.Users != nil {
+		if user, err := s.Users.GetUserByID(q.UserID); err != nil {
+			log.Warn("failed to load user for cooking Q&A", zap.Error(err))
+		} else if user.Personalization != nil {
+			grounding.Kitchen = user.Personalization.CookingContextPrompt()
+		}
+	}
+
+	var alerts []ParticipantAllergy
+	if grounding.Substitution {
+		alerts = grounding.Participants
+	}
+	return ai.CookingChatRequest{
+		Question:      q.Question,
+		RecipeContext: grounding.String(),
+		History:       history,
+	}, alerts, nil
+}
+
+// participantAllergies finds the allergies of the participants other than
+// the owner, whose household is already covered by the family summary. A
+// participant is looked up in the owner's family, or else in their own
+// family as the member linked to their account.
+func (s *CookingQAService) participantAllergies(ownerID uint, ownerFamily *models.Family, participants []uint) []ParticipantAllergy {
+	var out []ParticipantAllergy
+	for _, userID := range participants {
+		if userID == ownerID {
+			continue
+		}
+		member := familyMemberForUser(ownerFamily, userID)
+		if member == nil {
+			if own, err := s.Families.GetFamilyByOwnerID(userID); err == nil {
+				member = familyMemberForUser(own, userID)
+			}
+		}
+		if member == nil || member.DietaryProfile == nil {
+			continue
+		}
+		var allergies []string
+		for _, a := range member.DietaryProfile.Allergies {
+			if name := strings.TrimSpace(a.Name); name != "" {
+				allergies = append(allergies, name)
+			}
+		}
+		if len(allergies) > 0 {
+			out = append(out, ParticipantAllergy{UserID: userID, Name: member.Name, Allergies: allergies})
+		}
+	}
+	return out
+}
+
+// familyMemberForUser is the family member linked to userID's account.
+func familyMemberForUser(family *models.Family, userID uint) *models.FamilyMember {
+	if family == nil {
+		return nil
+	}
+	for i := range family.Members {
+		if id := family.Members[i].UserID; id != nil && *id == userID {
+			return &family.Members[i]
+		}
+	}
+	return nil
+}
+
+// cookingGrounding is what a cooking answer is grounded in.
+type cookingGrounding struct {
+	Def          models.RecipeDef
+	Step         int
+	Edits        []models.CookingEdit
+	Diet         string
+	Participants []ParticipantAllergy
+	Substitution bool
+	Kitchen      string
+}
+
+// String renders the grounding as the model-facing recipe context.
+func (g cookingGrounding) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Recipe: %s\n", g.Def.Title)
+	if len(g.Def.Ingredients) > 0 {
+		b.WriteString("\nIngredients:\n")
+		for _, ing := range g.Def.Ingredients {
+			b.WriteString("- " + ingredientLine(ing) + "\n")
+		}
+	}
+	if len(g.Def.Instructions) > 0 {
+		b.WriteString("\nSteps:\n")
+		for i, step := range g.Def.Instructions {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+		}
+	}
+	if len(g.Edits) > 0 {
+		b.WriteString("\nChanges the user has made while cooking (these override the recipe above):\n")
+		for _, edit := range g.Edits {
+			if edit.StepIndex >= 0 && edit.StepIndex < len(g.Def.Instructions) {
+				fmt.Fprintf(&b, "- Step %d: %s\n", edit.StepIndex+1, edit.Modification)
+			} else {
+				b.WriteString("- " + edit.Modification + "\n")
+			}
+		}
+	}
+	if g.Step > 0 && g.Step <= len(g.Def.Instructions) {
+		fmt.Fprintf(&b, "\nThe user is currently on step %d: %s\n", g.Step, g.Def.Instructions[g.Step-1])
+	} else if g.Step > 0 {
+		fmt.Fprintf(&b, "\nThe user is currently on step %d.\n", g.Step)
+	}
+	if g.Diet != "" {
+		b.WriteString("\nHousehold dietary needs: " + g.Diet + "\n")
+	}
+	if len(g.Participants) > 0 {
+		var people []string
+		for _, p := range g.Participants {
+			people = append(people, fmt.Sprintf("%s (allergic to %s)", p.Name, strings.Join(p.Allergies, ", ")))
+		}
+		b.WriteString("\nCooking together right now: " + strings.Join(people, "; ") + "\n")
+		if g.Substitution {
+			b.WriteString("Any substitution you suggest must be safe for these allergies; say so when an option is not.\n")
+		}
+	}
+	if g.Kitchen != "" {
+		b.WriteString("\n" + g.Kitchen + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// ingredientLine renders an ingredient as written in the source, or from its
+// parsed amount, unit and name.
+func ingredientLine(ing models.Ingredient) string {
+	if ing.OriginalText != "" {
+		return ing.OriginalText
+	}
+	var parts []string
+	if ing.Amount > 0 {
+		parts = append(parts, strconv.FormatFloat(ing.Amount, 'f', -1, 64))
+	}
+	if ing.Unit != "" {
+		parts = append(parts, ing.Unit)
+	}
+	return strings.Join(append(parts, ing.Name), " ")
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+)
+
+// newCookingQAService returns a QA service over the seeded tree fixture
+// (recipe 7, owned by user 1) with every optional context source wired up,
+// recording the recipe context and question each CookingQA call is sent.
+func newCookingQAService(t *testing.T) (*CookingQAService, *CookingSessionService, *string) {
+	t.Helper()
+	repo := testutil.NewMockRecipeRepo()
+	root, child := seedTreeServiceFixture(t, repo)
+	if err := repo.SetActiveNode(root.TreeID, child.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var sent string
+	svc := NewCookingQAService(&testutil.MockTextProvider{
+		CookingQAFunc: func(ctx context.Context, question, recipeContext string) (string, error) {
+			sent = recipeContext
+			return "Flip when bubbles form.", nil
+		},
+	}, repo)
+
+	users := testutil.NewMockUserRepo()
+	user := testutil.TestUser()
+	user.Personalization.CookingContext = "gas stove, cast iron"
+	users.Users[user.ID] = user
+	svc.Users = users
+	svc.Families = &testutil.MockFamilyRepo{
+		GetFamilyByOwnerIDFunc: func(ownerID uint) (*models.Family, error) {
+			return &models.Family{Members: []models.FamilyMember{{
+				Name:           "Sam",
+				DietaryProfile: &models.DietaryProfile{Allergies: models.AllergyList{{Name: "milk"}}},
+			}}}, nil
+		},
+	}
+	svc.Sessions = NewCookingSessionService(testutil.NewMockCookingSessionRepo())
+	return svc, svc.Sessions, &sent
+}
+
+func TestCookingQA_GroundsOnServerState(t *testing.T) {
+	svc, sessions, sent := newCookingQAService(t)
+	ctx := context.Background()
+	recipe := &models.Recipe{RecipeDef: testutil.TestRecipeDef()}
+	recipe.ID = 7
+	if _, err := sessions.Open(ctx, 1, recipe); err != nil {
+		t.Fatal(err)
+	}
+	_ = sessions.AddEdit(ctx, 1, 7, 1, "used oat milk")
+
+	var deltas []string
+	answer, err := svc.Answer(ctx, CookingQuestion{UserID: 1, RecipeID: 7, Question: "when do I flip?", Step: 3}, func(d string) {
+		deltas = append(deltas, d)
+	})
+	if err != nil {
+		t.Fatalf("Answer() error = %v", err)
+	}
+	if answer.Text != "Flip when bubbles form." || strings.Join(deltas, "") != answer.Text {
+		t.Errorf("answer %q, deltas %q", answer.Text, deltas)
+	}
+	if len(answer.AllergyAlerts) != 0 {
+		t.Errorf("AllergyAlerts = %+v, want none when cooking alone", answer.AllergyAlerts)
+	}
+
+	for _, want := range []string{
+		"Recipe: Fluffier Pancakes",      // the active node, not the recipe row
+		"- 1 1/4 cups milk",              // ingredients as written
+		"3. Combine and cook on griddle", // numbered steps
+		"- Step 2: used oat milk",        // session edits
+		"currently on step 3: Combine and cook on griddle",
+		"Sam: allergic to milk",
+		"gas stove, cast iron",
+	} {
+		if !strings.Contains(*sent, want) {
+			t.Errorf("recipe context missing %q:\n%s", want, *sent)
+		}
+	}
+}
+
+func TestCookingQA_ReplaysRecentHistory(t *testing.T) {
+	svc, sessions, sent := newCookingQAService(t)
+	ctx := context.Background()
+	recipe := &models.Recipe{RecipeDef: testutil.TestRecipeDef()}
+	recipe.ID = 7
+	if _, err := sessions.Open(ctx, 1, recipe); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < maxCookingChatTurns+2; i++ {
+		_ = sessions.RecordQA(ctx, 1, 7, fmt.Sprintf("question %d", i), fmt.Sprintf("answer %d", i))
+	}
+
+	req, _, err := svc.chatRequest(ctx, CookingQuestion{UserID: 1, RecipeID: 7, Question: "and for that?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(req.History) != 2*maxCookingChatTurns {
+		t.Fatalf("history has %d messages, want the last %d turns", len(req.History), maxCookingChatTurns)
+	}
+	if first := req.History[0]; first.Role != "user" || first.Content != "question 2" {
+		t.Errorf("oldest replayed message = %+v, want question 2", first)
+	}
+	if last := req.History[len(req.History)-1]; last.Role != "assistant" || last.Content != "answer 7" {
+		t.Errorf("newest replayed message = %+v, want answer 7", last)
+	}
+
+	// The mock provider doesn't stream, so the history reaches it folded into
+	// the recipe context.
+	if _, err := svc.Answer(ctx, CookingQuestion{UserID: 1, RecipeID: 7, Question: "and for that?"}, func(string) {}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(*sent, "User: question 7\nAssistant: answer 7") {
+		t.Errorf("recipe context missing the conversation:\n%s", *sent)
+	}
+}
+
+func TestCookingQA_OptionalSourcesMissing(t *testing.T) {
+	svc, _, sent := newCookingQAService(t)
+	svc.Users, svc.Families, svc.Sessions = nil, nil, nil
+
+	if _, err := svc.Answer(context.Background(), CookingQuestion{UserID: 1, RecipeID: 7, Question: "hot enough?"}, func(string) {}); err != nil {
+		t.Fatalf("Answer() error = %v", err)
+	}
+	if strings.Contains(*sent, "Household") || strings.Contains(*sent, "currently on step") {
+		t.Errorf("recipe context has parts without a source:\n%s", *sent)
+	}
+
+	if _, err := svc.Answer(context.Background(), CookingQuestion{UserID: 1, RecipeID: 99, Question: "?"}, func(string) {}); err == nil {
+		t.Error("Answer() for a missing recipe should fail")
+	}
+}
+
+func TestCookingQA_ParticipantAllergies(t *testing.T) {
+	svc, sessions, sent := newCookingQAService(t)
+	ctx := context.Background()
+	guest, neighbour := uint(2), uint(3)
+	svc.Families = &testutil.MockFamilyRepo{
+		GetFamilyByOwnerIDFunc: func(ownerID uint) (*models.Family, error) {
+			switch ownerID {
+			case 1:
+				return &models.Family{Members: []models.FamilyMember{{
+					Name:           "Robin",
+					UserID:         &guest,
+					DietaryProfile: &models.DietaryProfile{Allergies: models.AllergyList{{Name: "peanuts"}}},
+				}}}, nil
+			case 3:
+				return &models.Family{Members: []models.FamilyMember{{
+					Name:           "Alex",
+					UserID:         &neighbour,
+					DietaryProfile: &models.DietaryProfile{Allergies: models.AllergyList{{Name: "shellfish"}, {Name: " "}}},
+				}}}, nil
+			}
+			return nil, fmt.Errorf("no family")
+		},
+	}
+	recipe := &models.Recipe{RecipeDef: testutil.TestRecipeDef()}
+	recipe.ID = 7
+	if _, err := sessions.Open(ctx, 1, recipe); err != nil {
+		t.Fatal(err)
+	}
+	// A guest moved the room on; the session's step wins over the asker's.
+	_ = sessions.SetStep(ctx, 1, 7, 2)
+
+	q := CookingQuestion{UserID: 1, RecipeID: 7, Question: "Can I substitute the butter?", Step: 1, Participants: []uint{1, 2, 3, 4}}
+	answer, err := svc.Answer(ctx, q, func(string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(answer.AllergyAlerts) != 2 ||
+		answer.AllergyAlerts[0].Name != "Robin" || answer.AllergyAlerts[0].Allergies[0] != "peanuts" ||
+		answer.AllergyAlerts[1].Name != "Alex" || len(answer.AllergyAlerts[1].Allergies) != 1 {
+		t.Errorf("AllergyAlerts = %+v", answer.AllergyAlerts)
+	}
+	for _, want := range []string{
+		"currently on step 2",
+		"Cooking together right now: Robin (allergic to peanuts); Alex (allergic to shellfish)",
+		"must be safe for these allergies",
+	} {
+		if !strings.Contains(*sent, want) {
+			t.Errorf("recipe context missing %q:\n%s", want, *sent)
+		}
+	}
+
+	// Other questions still know who is cooking, but raise no alerts.
+	q.Question = "how hot should the pan be?"
+	answer, err = svc.Answer(ctx, q, func(string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(answer.AllergyAlerts) != 0 || strings.Contains(*sent, "must be safe") {
+		t.Errorf("non-substitution question alerts = %+v, context:\n%s", answer.AllergyAlerts, *sent)
+	}
+}
+
+func TestIsSubstitutionQuestion(t *testing.T) {
+	for q, want := range map[string]bool{
+		"What can I use instead of eggs?":       true,
+		"I don't have buttermilk":               true,
+		"Can I swap the flour for almond?":      true,
+		"Any alternative to honey?":             true,
+		"We ran out of sugar":                   true,
+		"I'm out of butter":                     true,
+		"I’m all out of cream":                  true,
+		"Can I replace the milk with oat?":      true,
+		"How long do I bake this?":              false,
+		"Is the oven hot enough for bread?":     false,
+		"When do I take it out of the oven?":    false,
+		"How do I get the cake out of the pan?": false,
+		"Should I replace the lid?":             false,
+		"Is there an alternative method?":       false,
+	} {
+		if got := IsSubstitutionQuestion(q); got != want {
+			t.Errorf("IsSubstitutionQuestion(%q) = %v, want %v", q, got, want)
+		}
+	}
+}
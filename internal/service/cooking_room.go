@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/models"
+)
+
+// JoinCodeTTL is how long a cooking room's join code can be used.
+const JoinCodeTTL = 2 * time.Hour
+
+// joinCodeLength is how many base32 characters (A-Z, 2-7) a join code has.
+const joinCodeLength = 8
+
+// Errors returned when joining someone else's cooking room.
+var (
+	ErrNotInvited      = errors.New("not invited to this cooking session")
+	ErrInvalidJoinCode = errors.New("join code is invalid or has expired")
+)
+
+// CreateJoinCode gives the owner's cooking session for recipe a fresh join
+// code, valid for JoinCodeTTL, and returns the session.
+func (s *CookingSessionService) CreateJoinCode(ctx context.Context, ownerID uint, recipe *models.Recipe) (*models.CookingSession, error) {
+	code := rand.Text()[:joinCodeLength]
+	expires := s.now().Add(JoinCodeTTL)
+	return s.modify(ctx, ownerID, recipe.ID, func(session *models.CookingSession) error {
+		session.RecipeTitle = effectiveRecipeDef(recipe).Title
+		session.JoinCode, session.JoinCodeExpiresAt = code, &expires
+		return nil
+	})
+}
+
+// ResolveJoinCode returns the session a join code opens.
+func (s *CookingSessionService) ResolveJoinCode(ctx context.Context, code string) (*models.CookingSession, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil, ErrInvalidJoinCode
+	}
+	session, err := s.Repo.GetByJoinCode(ctx, code, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrInvalidJoinCode
+	}
+	return session, nil
+}
+
+// AuthorizeJoin checks that a user may join the cooking room for recipe: its
+// owner, a member of the owner's family with their own account, or anyone
+// holding the room's join code.
+func (s *CookingSessionService) AuthorizeJoin(ctx context.Context, userID uint, recipe *models.Recipe, code string) error {
+	if recipe.CreatedByID == userID {
+		return nil
+	}
+	if s.Families != nil {
+		// An owner without a family is the common case; a lookup error just
+		// means no family access.
+		if family, err := s.Families.GetFamilyByOwnerID(recipe.CreatedByID); err == nil && family != nil {
+			for _, member := range family.Members {
+				if member.UserID != nil && *member.UserID == userID {
+					return nil
+				}
+			}
+		}
+	}
+	if code == "" {
+		return ErrNotInvited
+	}
+	session, err := s.ResolveJoinCode(ctx, code)
+	if errors.Is(err, ErrInvalidJoinCode) {
+		return ErrNotInvited
+	}
+	if err != nil {
+		return err
+	}
+	if session.UserID != recipe.CreatedByID || session.RecipeID != recipe.ID {
+		return ErrNotInvited
+	}
+	return nil
+}
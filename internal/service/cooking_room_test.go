@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+)
+
+func TestCookingRoom_JoinCode(t *testing.T) {
+	svc, _, now := newClockedSessionService()
+	ctx := context.Background()
+	recipe := testutil.TestRecipe()
+	owner := recipe.CreatedByID
+
+	session, err := svc.CreateJoinCode(ctx, owner, recipe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(session.JoinCode) != joinCodeLength || session.JoinCodeExpiresAt == nil {
+		t.Fatalf("session = %+v, want a join code with an expiry", session)
+	}
+
+	resolved, err := svc.ResolveJoinCode(ctx, " "+strings.ToLower(session.JoinCode)+" ")
+	if err != nil || resolved.UserID != owner || resolved.RecipeID != recipe.ID {
+		t.Fatalf("ResolveJoinCode() = %+v, %v", resolved, err)
+	}
+	if err := svc.AuthorizeJoin(ctx, 99, recipe, session.JoinCode); err != nil {
+		t.Errorf("guest with the code: %v", err)
+	}
+	if err := svc.AuthorizeJoin(ctx, owner, recipe, ""); err != nil {
+		t.Errorf("owner: %v", err)
+	}
+	if err := svc.AuthorizeJoin(ctx, 99, recipe, ""); !errors.Is(err, ErrNotInvited) {
+		t.Errorf("guest without a code: %v", err)
+	}
+	other := *recipe
+	other.ID = recipe.ID + 1
+	if err := svc.AuthorizeJoin(ctx, 99, &other, session.JoinCode); !errors.Is(err, ErrNotInvited) {
+		t.Errorf("code for another recipe: %v", err)
+	}
+
+	*now = now.Add(JoinCodeTTL + 1)
+	if _, err := svc.ResolveJoinCode(ctx, session.JoinCode); !errors.Is(err, ErrInvalidJoinCode) {
+		t.Errorf("expired code: %v", err)
+	}
+	if err := svc.AuthorizeJoin(ctx, 99, recipe, session.JoinCode); !errors.Is(err, ErrNotInvited) {
+		t.Errorf("guest with an expired code: %v", err)
+	}
+}
+
+func TestCookingRoom_FamilyMembersJoin(t *testing.T) {
+	svc, _, _ := newClockedSessionService()
+	recipe := testutil.TestRecipe()
+	member := uint(42)
+	svc.Families = &testutil.MockFamilyRepo{
+		GetFamilyByOwnerIDFunc: func(ownerID uint) (*models.Family, error) {
+			if ownerID != recipe.CreatedByID {
+				return nil, errors.New("not found")
+			}
+			return &models.Family{Members: []models.FamilyMember{{Name: "Kid"}, {Name: "Partner", UserID: &member}}}, nil
+		},
+	}
+
+	if err := svc.AuthorizeJoin(context.Background(), member, recipe, ""); err != nil {
+		t.Errorf("family member: %v", err)
+	}
+	if err := svc.AuthorizeJoin(context.Background(), 43, recipe, ""); !errors.Is(err, ErrNotInvited) {
+		t.Errorf("stranger: %v", err)
+	}
+}
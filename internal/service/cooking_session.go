@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// DefaultCookingSessionTTL is how long a cooking session survives without
+// activity.
+const DefaultCookingSessionTTL = 12 * time.Hour
+
+// Session size caps: older edits and Q&A are dropped first.
+const (
+	maxCookingEdits   = 100
+	maxCookingHistory = 20
+	maxTimerDuration  = 24 * time.Hour
+)
+
+// Cooking session errors, reported to the cooking client.
+var (
+	ErrInvalidTimer  = errors.New("timer duration must be between 1 second and 24 hours")
+	ErrTimerNotFound = errors.New("timer not found")
+)
+
+// CookingSessionService persists cooking-mode state so a client that
+// reconnects can pick up where it left off. Every change marks the session
+// active; sessions idle for longer than TTL start over on the next connect,
+// drop out of the "resume cooking" list and are eventually deleted.
+type CookingSessionService struct {
+	Repo repository.CookingSessionRepo
+	TTL  time.Duration
+	// Families lets members of the recipe owner's family join their cooking
+	// room (see AuthorizeJoin); nil allows only the owner and join codes.
+	Families repository.FamilyRepo
+
+	now func() time.Time
+}
+
+// NewCookingSessionService creates a new CookingSessionService.
+func NewCookingSessionService(repo repository.CookingSessionRepo) *CookingSessionService {
+	return &CookingSessionService{Repo: repo, TTL: DefaultCookingSessionTTL, now: time.Now}
+}
+
+// Open returns the user's session for recipe, creating it if needed, for a
+// newly connected client. An expired session is reset first, and timers
+// that have finished are dropped.
+func (s *CookingSessionService) Open(ctx context.Context, userID uint, recipe *models.Recipe) (*models.CookingSession, error) {
+	now := s.now()
+	return s.modify(ctx, userID, recipe.ID, func(session *models.CookingSession) error {
+		if s.expired(session, now) {
+			session.CurrentStep, session.HasStep = 0, false
+			session.Edits, session.Timers, session.History = nil, nil, nil
+		}
+		session.RecipeTitle = effectiveRecipeDef(recipe).Title
+		session.Timers = runningTimers(session.Timers, now)
+		return nil
+	})
+}
+
+// SetStep records the step the user is viewing.
+func (s *CookingSessionService) SetStep(ctx context.Context, userID, recipeID uint, step int) error {
+	_, err := s.modify(ctx, userID, recipeID, func(session *models.CookingSession) error {
+		session.CurrentStep, session.HasStep = step, true
+		return nil
+	})
+	return err
+}
+
+// AddEdit records an ephemeral edit.
+func (s *CookingSessionService) AddEdit(ctx context.Context, userID, recipeID uint, stepIndex int, modification string) error {
+	_, err := s.modify(ctx, userID, recipeID, func(session *models.CookingSession) error {
+		session.Edits = appendCapped(session.Edits, models.CookingEdit{
+			StepIndex:    stepIndex,
+			Modification: modification,
+			CreatedAt:    s.now(),
+		}, maxCookingEdits)
+		return nil
+	})
+	return err
+}
+
+// ResetEdits clears the ephemeral edits.
+func (s *CookingSessionService) ResetEdits(ctx context.Context, userID, recipeID uint) error {
+	_, err := s.modify(ctx, userID, recipeID, func(session *models.CookingSession) error {
+		session.Edits = nil
+		return nil
+	})
+	return err
+}
+
+// StartTimer starts a timer of the given duration, optionally tied to a
+// step, and returns it.
+func (s *CookingSessionService) StartTimer(ctx context.Context, userID, recipeID uint, label string, step int, duration time.Duration) (*models.CookingTimer, error) {
+	if duration < time.Second || duration > maxTimerDuration {
+		return nil, ErrInvalidTimer
+	}
+	now := s.now()
+	timer := models.CookingTimer{
+		ID:              uuid.NewString(),
+		Label:           label,
+		Step:            step,
+		DurationSeconds: int(duration / time.Second),
+		StartedAt:       now,
+		EndsAt:          now.Add(duration),
+	}
+	_, err := s.modify(ctx, userID, recipeID, func(session *models.CookingSession) error {
+		session.Timers = append(runningTimers(session.Timers, now), timer)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &timer, nil
+}
+
+// CancelTimer stops a running timer.
+func (s *CookingSessionService) CancelTimer(ctx context.Context, userID, recipeID uint, timerID string) error {
+	_, err := s.modify(ctx, userID, recipeID, func(session *models.CookingSession) error {
+		n := len(session.Timers)
+		session.Timers = slices.DeleteFunc(session.Timers, func(t models.CookingTimer) bool { return t.ID == timerID })
+		if len(session.Timers) == n {
+			return ErrTimerNotFound
+		}
+		return nil
+	})
+	return err
+}
+
+// RecordQA appends a cooking question and its answer to the history.
+func (s *CookingSessionService) RecordQA(ctx context.Context, userID, recipeID uint, question, answer string) error {
+	_, err := s.modify(ctx, userID, recipeID, func(session *models.CookingSession) error {
+		session.History = appendCapped(session.History, models.CookingQA{
+			Question:  question,
+			Answer:    answer,
+			CreatedAt: s.now(),
+		}, maxCookingHistory)
+		return nil
+	})
+	return err
+}
+
+// Get returns the user's unexpired session for a recipe, or nil if there is
+// none.
+func (s *CookingSessionService) Get(ctx context.Context, userID, recipeID uint) (*models.CookingSession, error) {
+	session, err := s.Repo.Get(ctx, userID, recipeID)
+	if err != nil || session == nil || s.expired(session, s.now()) {
+		return nil, err
+	}
+	return session, nil
+}
+
+// ListActive returns the user's unexpired sessions, most recent first, for
+// "resume cooking".
+func (s *CookingSessionService) ListActive(ctx context.Context, userID uint) ([]models.CookingSession, error) {
+	now := s.now()
+	sessions, err := s.Repo.ListActiveByUser(ctx, userID, now.Add(-s.TTL))
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Timers = runningTimers(sessions[i].Timers, now)
+	}
+	return sessions, nil
+}
+
+// End deletes the user's session for a recipe, e.g. when they finish
+// cooking.
+func (s *CookingSessionService) End(ctx context.Context, userID, recipeID uint) error {
+	return s.Repo.Delete(ctx, userID, recipeID)
+}
+
+// StartCleanup periodically deletes sessions that have expired.
+func (s *CookingSessionService) StartCleanup(interval time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			s.cleanupExpired()
+		}
+	}()
+}
+
+// cleanupExpired removes sessions idle for longer than TTL.
+func (s *CookingSessionService) cleanupExpired() {
+	deleted, err := s.Repo.DeleteInactive(context.Background(), s.now().Add(-s.TTL))
+	if err != nil {
+		logger.Get().Warn("failed to clean up expired cooking sessions", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		logger.Get().Info("cleaned up expired cooking sessions", zap.Int64("deleted", deleted))
+	}
+}
+
+// modify applies fn to the session and marks it active.
+func (s *CookingSessionService) modify(ctx context.Context, userID, recipeID uint, fn func(*models.CookingSession) error) (*models.CookingSession, error) {
+	return s.Repo.Modify(ctx, userID, recipeID, func(session *models.CookingSession) error {
+		if err := fn(session); err != nil {
+			return err
+		}
+		session.LastActiveAt = s.now()
+		return nil
+	})
+}
+
+// expired reports whether session has been idle for longer than TTL.
+func (s *CookingSessionService) expired(session *models.CookingSession, now time.Time) bool {
+	return session.LastActiveAt.Before(now.Add(-s.TTL))
+}
+
+// runningTimers drops timers that have finished.
+func runningTimers(timers models.CookingTimerList, now time.Time) models.CookingTimerList {
+	return slices.DeleteFunc(timers, func(t models.CookingTimer) bool { return !t.EndsAt.After(now) })
+}
+
+// appendCapped appends v, keeping at most the last limit elements.
+func appendCapped[S ~[]E, E any](s S, v E, limit int) S {
+	s = append(s, v)
+	if len(s) > limit {
+		s = slices.Delete(s, 0, len(s)-limit)
+	}
+	return s
+}
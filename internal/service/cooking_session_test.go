@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+)
+
+// newClockedSessionService returns a service whose clock the test moves.
+func newClockedSessionService() (*CookingSessionService, *testutil.MockCookingSessionRepo, *time.Time) {
+	repo := testutil.NewMockCookingSessionRepo()
+	svc := NewCookingSessionService(repo)
+	now := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, repo, &now
+}
+
+func TestCookingSession_StateSurvivesReopen(t *testing.T) {
+	svc, _, now := newClockedSessionService()
+	ctx := context.Background()
+	recipe := testutil.TestRecipe()
+
+	if _, err := svc.Open(ctx, 1, recipe); err != nil {
+		t.Fatal(err)
+	}
+	_ = svc.SetStep(ctx, 1, recipe.ID, 2)
+	_ = svc.AddEdit(ctx, 1, recipe.ID, 1, "use oat milk")
+	timer, err := svc.StartTimer(ctx, 1, recipe.ID, "rest batter", 2, 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	short, _ := svc.StartTimer(ctx, 1, recipe.ID, "", 0, time.Minute)
+	_ = svc.RecordQA(ctx, 1, recipe.ID, "is it done?", "Not yet.")
+
+	*now = now.Add(5 * time.Minute)
+	session, err := svc.Open(ctx, 1, recipe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !session.HasStep || session.CurrentStep != 2 {
+		t.Errorf("step = %d (set %v), want 2", session.CurrentStep, session.HasStep)
+	}
+	if len(session.Edits) != 1 || session.Edits[0].Modification != "use oat milk" {
+		t.Errorf("edits = %+v", session.Edits)
+	}
+	if len(session.Timers) != 1 || session.Timers[0].ID != timer.ID {
+		t.Errorf("timers = %+v, want only the running %s (finished %s dropped)", session.Timers, timer.ID, short.ID)
+	}
+	if len(session.History) != 1 || session.History[0].Answer != "Not yet." {
+		t.Errorf("history = %+v", session.History)
+	}
+	if session.RecipeTitle != recipe.Title {
+		t.Errorf("recipe title = %q, want %q", session.RecipeTitle, recipe.Title)
+	}
+}
+
+func TestCookingSession_ExpiresAfterInactivity(t *testing.T) {
+	svc, _, now := newClockedSessionService()
+	ctx := context.Background()
+	recipe := testutil.TestRecipe()
+
+	_, _ = svc.Open(ctx, 1, recipe)
+	_ = svc.SetStep(ctx, 1, recipe.ID, 3)
+	_ = svc.RecordQA(ctx, 1, recipe.ID, "q", "a")
+
+	*now = now.Add(DefaultCookingSessionTTL + time.Minute)
+	if sessions, _ := svc.ListActive(ctx, 1); len(sessions) != 0 {
+		t.Errorf("expired session still listed: %+v", sessions)
+	}
+
+	session, err := svc.Open(ctx, 1, recipe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.HasStep || len(session.History) != 0 {
+		t.Errorf("expired session was resumed: %+v", session)
+	}
+	if sessions, _ := svc.ListActive(ctx, 1); len(sessions) != 1 {
+		t.Errorf("reopened session should be listed again, got %d", len(sessions))
+	}
+}
+
+func TestCookingSession_CleanupDeletesExpired(t *testing.T) {
+	svc, repo, now := newClockedSessionService()
+	ctx := context.Background()
+	recipe := testutil.TestRecipe()
+	_, _ = svc.Open(ctx, 1, recipe)
+
+	*now = now.Add(DefaultCookingSessionTTL + time.Minute)
+	svc.cleanupExpired()
+	if session, _ := repo.Get(ctx, 1, recipe.ID); session != nil {
+		t.Error("expired session was not deleted")
+	}
+}
+
+func TestCookingSession_ListActiveMostRecentFirst(t *testing.T) {
+	svc, _, now := newClockedSessionService()
+	ctx := context.Background()
+	first, second := testutil.TestRecipe(), testutil.TestRecipe()
+	second.ID = 2
+
+	_, _ = svc.Open(ctx, 1, first)
+	*now = now.Add(time.Minute)
+	_, _ = svc.Open(ctx, 1, second)
+	_, _ = svc.Open(ctx, 9, first) // another user's session
+
+	sessions, err := svc.ListActive(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].RecipeID != 2 || sessions[1].RecipeID != 1 {
+		t.Errorf("sessions = %+v, want recipe 2 then 1", sessions)
+	}
+}
+
+func TestCookingSession_TimersAndCaps(t *testing.T) {
+	svc, _, _ := newClockedSessionService()
+	ctx := context.Background()
+
+	for _, d := range []time.Duration{0, 25 * time.Hour} {
+		if _, err := svc.StartTimer(ctx, 1, 1, "", 0, d); !errors.Is(err, ErrInvalidTimer) {
+			t.Errorf("StartTimer(%v) err = %v, want ErrInvalidTimer", d, err)
+		}
+	}
+	timer, _ := svc.StartTimer(ctx, 1, 1, "", 0, time.Minute)
+	if err := svc.CancelTimer(ctx, 1, 1, timer.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.CancelTimer(ctx, 1, 1, timer.ID); !errors.Is(err, ErrTimerNotFound) {
+		t.Errorf("second cancel err = %v, want ErrTimerNotFound", err)
+	}
+
+	for i := 0; i < maxCookingHistory+5; i++ {
+		_ = svc.RecordQA(ctx, 1, 1, fmt.Sprintf("q%d", i), "a")
+	}
+	session, _ := svc.Repo.Get(ctx, 1, 1)
+	if len(session.History) != maxCookingHistory || session.History[0].Question != "q5" {
+		t.Errorf("history has %d entries starting %q, want the last %d", len(session.History), session.History[0].Question, maxCookingHistory)
+	}
+}
@@ -76,7 +76,7 @@ func backfillRecipeEmbeddings(repo EmbeddingBackfillRepo, embedProvider ai.Embed
 				continue
 			}
 
-			embedCtx, cancel := context.WithTimeout(context.Background(), embeddingCallTimeout)
+			embedCtx, cancel := context.WithTimeout(ai.WithPriority(context.Background(), ai.PriorityBackfill), embeddingCallTimeout)
 			embedding, err := embedProvider.GenerateEmbedding(embedCtx, text)
 			cancel()
 			if err != nil {
@@ -132,7 +132,7 @@ func backfillCanonicalEmbeddings(repo EmbeddingBackfillRepo, embedProvider ai.Em
 				continue
 			}
 
-			embedCtx, cancel := context.WithTimeout(context.Background(), embeddingCallTimeout)
+			embedCtx, cancel := context.WithTimeout(ai.WithPriority(context.Background(), ai.PriorityBackfill), embeddingCallTimeout)
 			embedding, err := embedProvider.GenerateEmbedding(embedCtx, text)
 			cancel()
 			if err != nil {
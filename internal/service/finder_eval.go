@@ -0,0 +1,368 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+)
+
+// FinderEvalCase is one captured ranker call replayable offline: the exact
+// FinderRankRequest production sent and the result it got back (the reference
+// a candidate model is scored against).
+type FinderEvalCase struct {
+	RunID     uint                 `json:"run_id,omitempty"`
+	Request   ai.FinderRankRequest `json:"request"`
+	Reference *ai.FinderRankResult `json:"reference"`
+
+	// Reference call metadata, for the cost/latency comparison.
+	Model      string        `json:"model,omitempty"`
+	Usage      ai.TokenUsage `json:"usage"`
+	DurationMS int64         `json:"duration_ms,omitempty"`
+}
+
+// FinderRanker is the slice of ai.TextProvider the eval harness replays
+// against, so a recorded or mock ranker can stand in for a real model.
+type FinderRanker interface {
+	ExpandAndRankRecipes(ctx context.Context, req ai.FinderRankRequest) (*ai.FinderRankResult, error)
+}
+
+// LoadFinderEvalCases turns captured finder runs into eval cases. Captures
+// whose production call failed carry no reference result and are skipped —
+// there is nothing to agree with.
+func LoadFinderEvalCases(repo repository.FinderRankCorpusRepo, since time.Time, limit int) ([]FinderEvalCase, error) {
+	runs, err := repo.ListRankCaptures(since, limit)
+	if err != nil {
+		return nil, err
+	}
+	cases := make([]FinderEvalCase, 0, len(runs))
+	for _, run := range runs {
+		c, ok, err := finderEvalCaseFromRun(run)
+		if err != nil {
+			return nil, fmt.Errorf("run %d: %w", run.ID, err)
+		}
+		if ok {
+			cases = append(cases, c)
+		}
+	}
+	return cases, nil
+}
+
+func finderEvalCaseFromRun(run models.FinderRun) (FinderEvalCase, bool, error) {
+	capture := run.RankCapture
+	if capture == nil || len(capture.Result) == 0 {
+		return FinderEvalCase{}, false, nil
+	}
+	c := FinderEvalCase{
+		RunID: run.ID,
+		Model: capture.Model,
+		Usage: ai.TokenUsage{
+			InputTokens:      capture.InputTokens,
+			OutputTokens:     capture.OutputTokens,
+			CacheInputTokens: capture.CacheInputTokens,
+		},
+		DurationMS: capture.DurationMS,
+	}
+	if err := json.Unmarshal(capture.Request, &c.Request); err != nil {
+		return FinderEvalCase{}, false, fmt.Errorf("decode request: %w", err)
+	}
+	if err := json.Unmarshal(capture.Result, &c.Reference); err != nil {
+		return FinderEvalCase{}, false, fmt.Errorf("decode result: %w", err)
+	}
+	return c, true, nil
+}
+
+// ReadFinderEvalCases reads a JSONL corpus (one FinderEvalCase per line), as
+// written by WriteFinderEvalCases. Blank lines are ignored.
+func ReadFinderEvalCases(r io.Reader) ([]FinderEvalCase, error) {
+	var cases []FinderEvalCase
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		var c FinderEvalCase
+		if err := json.Unmarshal([]byte(text), &c); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		cases = append(cases, c)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+// WriteFinderEvalCases writes cases as JSONL, so a corpus can be frozen and
+// replayed without database access.
+func WriteFinderEvalCases(w io.Writer, cases []FinderEvalCase) error {
+	enc := json.NewEncoder(w)
+	for _, c := range cases {
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FinderEvalReport summarises one replay of a corpus against a ranker.
+// Agreement metrics compare what the user would have been shown (the
+// buildShortlist picks: no collections, no "avoid"s) under the reference and
+// replayed rankings.
+type FinderEvalReport struct {
+	Cases  int `json:"cases"`
+	Failed int `json:"failed"` // replay calls that errored (scored as the unranked fallback)
+
+	Top1Agreement float64 `json:"top1_agreement"` // same first pick
+	Top3Overlap   float64 `json:"top3_overlap"`   // share of the reference top 3 also in the replay top 3
+	KeepAgreement float64 `json:"keep_agreement"` // per candidate: both show it, or both drop it
+
+	// Avoid-flag recall over allergen cases (a household member "allergic to"
+	// something): of the candidates the reference marked "avoid", the share the
+	// replay also kept out of the shortlist.
+	AllergenCases int     `json:"allergen_cases"`
+	AvoidFlags    int     `json:"avoid_flags"`
+	AvoidCaught   int     `json:"avoid_caught"`
+	AvoidRecall   float64 `json:"avoid_recall"`
+
+	LatencyP50 time.Duration `json:"latency_p50"`
+	LatencyP95 time.Duration `json:"latency_p95"`
+
+	// Cost is priced from the metered token usage of the replay calls;
+	// ReferenceCost from the captured production usage.
+	CostUSD          float64 `json:"cost_usd"`
+	ReferenceCostUSD float64 `json:"reference_cost_usd"`
+}
+
+// RunFinderEval replays every case against ranker and scores it against the
+// reference. Cases run sequentially so latencies aren't skewed by our own
+// concurrency.
+func RunFinderEval(ctx context.Context, ranker FinderRanker, cases []FinderEvalCase, pricing ai.PricingTable) FinderEvalReport {
+	var report FinderEvalReport
+	var top1, top3, keep float64
+	var top3Cases int
+	latencies := make([]time.Duration, 0, len(cases))
+
+	for _, c := range cases {
+		if ctx.Err() != nil {
+			break
+		}
+		report.Cases++
+		report.ReferenceCostUSD += pricing.Cost(c.Model, c.Usage)
+
+		callCtx, calls := ai.WithCallCapture(ctx)
+		start := time.Now()
+		got, err := ranker.ExpandAndRankRecipes(callCtx, c.Request)
+		latencies = append(latencies, time.Since(start))
+		for _, r := range calls.Results() {
+			report.CostUSD += pricing.Cost(r.Operation.Model, r.Usage)
+		}
+		if err != nil || got == nil {
+			report.Failed++
+			got = fallbackRanking(len(c.Request.Candidates))
+		}
+
+		n := len(c.Request.Candidates)
+		refPicks := shortlistIndices(c.Reference, n)
+		gotPicks := shortlistIndices(got, n)
+
+		if firstOrNeg(refPicks) == firstOrNeg(gotPicks) {
+			top1++
+		}
+		if len(refPicks) > 0 {
+			top3Cases++
+			top3 += overlapAt(refPicks, gotPicks, 3)
+		}
+		keep += keepAgreement(refPicks, gotPicks, n)
+
+		if isAllergenCase(c.Request) {
+			report.AllergenCases++
+			shown := indexSet(gotPicks)
+			for _, idx := range avoidIndices(c.Reference, n) {
+				report.AvoidFlags++
+				if !shown[idx] {
+					report.AvoidCaught++
+				}
+			}
+		}
+	}
+
+	if report.Cases > 0 {
+		report.Top1Agreement = top1 / float64(report.Cases)
+		report.KeepAgreement = keep / float64(report.Cases)
+	}
+	if top3Cases > 0 {
+		report.Top3Overlap = top3 / float64(top3Cases)
+	}
+	if report.AvoidFlags > 0 {
+		report.AvoidRecall = float64(report.AvoidCaught) / float64(report.AvoidFlags)
+	}
+	report.LatencyP50 = durationPercentile(latencies, 0.50)
+	report.LatencyP95 = durationPercentile(latencies, 0.95)
+	return report
+}
+
+// shortlistIndices mirrors buildShortlist's filtering, returning the candidate
+// indices a ranking would show, in order.
+func shortlistIndices(rank *ai.FinderRankResult, n int) []int {
+	if rank == nil {
+		return nil
+	}
+	picks := make([]int, 0, len(rank.Ranked))
+	used := make(map[int]bool, len(rank.Ranked))
+	for _, r := range rank.Ranked {
+		if r.Index < 0 || r.Index >= n || used[r.Index] {
+			continue
+		}
+		used[r.Index] = true
+		if r.Expand || hasAvoid(r.Safety) {
+			continue
+		}
+		picks = append(picks, r.Index)
+	}
+	return picks
+}
+
+// avoidIndices returns the candidates a ranking marked "avoid" for any member.
+func avoidIndices(rank *ai.FinderRankResult, n int) []int {
+	if rank == nil {
+		return nil
+	}
+	var out []int
+	seen := make(map[int]bool)
+	for _, r := range rank.Ranked {
+		if r.Index < 0 || r.Index >= n || seen[r.Index] || !hasAvoid(r.Safety) {
+			continue
+		}
+		seen[r.Index] = true
+		out = append(out, r.Index)
+	}
+	return out
+}
+
+// isAllergenCase reports whether the request carried a household allergy.
+func isAllergenCase(req ai.FinderRankRequest) bool {
+	return strings.Contains(strings.ToLower(req.DietSummary), "allergic to")
+}
+
+func firstOrNeg(picks []int) int {
+	if len(picks) == 0 {
+		return -1
+	}
+	return picks[0]
+}
+
+func indexSet(picks []int) map[int]bool {
+	set := make(map[int]bool, len(picks))
+	for _, i := range picks {
+		set[i] = true
+	}
+	return set
+}
+
+// overlapAt is the share of ref's top k that also appear in got's top k.
+func overlapAt(ref, got []int, k int) float64 {
+	if len(ref) > k {
+		ref = ref[:k]
+	}
+	if len(got) > k {
+		got = got[:k]
+	}
+	if len(ref) == 0 {
+		return 0
+	}
+	gotSet := indexSet(got)
+	hits := 0
+	for _, i := range ref {
+		if gotSet[i] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(ref))
+}
+
+// keepAgreement is the share of the n candidates that both rankings either
+// show or drop. An empty candidate list agrees trivially.
+func keepAgreement(ref, got []int, n int) float64 {
+	if n == 0 {
+		return 1
+	}
+	refSet, gotSet := indexSet(ref), indexSet(got)
+	agree := 0
+	for i := 0; i < n; i++ {
+		if refSet[i] == gotSet[i] {
+			agree++
+		}
+	}
+	return float64(agree) / float64(n)
+}
+
+// durationPercentile returns the nearest-rank p-th percentile of ds.
+func durationPercentile(ds []time.Duration, p float64) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), ds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// CapturedFinderRanker replays the reference results of a corpus — scoring it
+// against itself is a harness sanity check (every agreement metric is 1).
+type CapturedFinderRanker struct {
+	byRequest map[string]*ai.FinderRankResult
+}
+
+// NewCapturedFinderRanker indexes the corpus' reference results by request.
+func NewCapturedFinderRanker(cases []FinderEvalCase) *CapturedFinderRanker {
+	r := &CapturedFinderRanker{byRequest: make(map[string]*ai.FinderRankResult, len(cases))}
+	for _, c := range cases {
+		if key, err := json.Marshal(c.Request); err == nil {
+			r.byRequest[string(key)] = c.Reference
+		}
+	}
+	return r
+}
+
+// ExpandAndRankRecipes returns the captured result for an identical request.
+func (r *CapturedFinderRanker) ExpandAndRankRecipes(_ context.Context, req ai.FinderRankRequest) (*ai.FinderRankResult, error) {
+	key, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	res, ok := r.byRequest[string(key)]
+	if !ok {
+		return nil, errors.New("no captured result for request")
+	}
+	return res, nil
+}
+
+// SearchOrderFinderRanker is the no-model baseline: the same unranked,
+// search-order shortlist the finder falls back to when ranking fails.
+type SearchOrderFinderRanker struct{}
+
+// ExpandAndRankRecipes returns every candidate in search order.
+func (SearchOrderFinderRanker) ExpandAndRankRecipes(_ context.Context, req ai.FinderRankRequest) (*ai.FinderRankResult, error) {
+	return fallbackRanking(len(req.Candidates)), nil
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+)
+
+// evalCase builds a three-candidate eval case with the given reference ranking.
+func evalCase(diet string, ranked ...ai.FinderRanking) FinderEvalCase {
+	return FinderEvalCase{
+		Request: ai.FinderRankRequest{
+			DietSummary: diet,
+			Candidates: []ai.FinderCandidate{
+				{Index: 0, Title: "Peanut Noodles"},
+				{Index: 1, Title: "Chicken Soup"},
+				{Index: 2, Title: "Bean Tacos"},
+			},
+		},
+		Reference: &ai.FinderRankResult{Ranked: ranked},
+		Model:     "gemini-2.5-flash",
+		Usage:     ai.TokenUsage{InputTokens: 1000, OutputTokens: 200},
+	}
+}
+
+func avoid(member string) []ai.MemberSafety {
+	return []ai.MemberSafety{{MemberName: member, Status: "avoid"}}
+}
+
+func TestFindRecipes_CapturesRankCallWhenSampled(t *testing.T) {
+	results := digSearchResults(3)
+	searchProvider := &testutil.MockSearchProvider{
+		SearchRecipesFunc: func(ctx context.Context, query string, count, offset int) ([]ai.SearchResult, error) {
+			return results, nil
+		},
+	}
+	svc := newFinderService(searchProvider, rankAllFlagging(results, nil), &testutil.MockFamilyRepo{})
+	runs := testutil.NewMockFinderRunRepo()
+	svc.Runs = runs
+	svc.RankCaptureRate = 0.5
+
+	svc.captureRoll = func() float64 { return 0.9 }
+	runFinder(svc, testutil.TestUser(), FinderRequest{Facets: FinderFacets{Protein: "chicken"}})
+	svc.captureRoll = func() float64 { return 0.1 }
+	runFinder(svc, testutil.TestUser(), FinderRequest{Facets: FinderFacets{Protein: "chicken"}})
+
+	all := runs.Runs()
+	if len(all) != 2 {
+		t.Fatalf("recorded %d runs, want 2", len(all))
+	}
+	if all[0].RankCapture != nil {
+		t.Error("unsampled run carries a rank capture")
+	}
+	if all[1].RankCapture == nil || len(all[1].RankCapture.Request) == 0 || len(all[1].RankCapture.Result) == 0 {
+		t.Fatalf("sampled run capture = %+v, want request and result", all[1].RankCapture)
+	}
+
+	cases, err := LoadFinderEvalCases(runs, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("LoadFinderEvalCases: %v", err)
+	}
+	if len(cases) != 1 {
+		t.Fatalf("loaded %d cases, want 1", len(cases))
+	}
+	if got := len(cases[0].Request.Candidates); got != 3 {
+		t.Errorf("captured request has %d candidates, want 3", got)
+	}
+
+	// Replaying the captured results against themselves agrees perfectly.
+	report := RunFinderEval(context.Background(), NewCapturedFinderRanker(cases), cases, ai.DefaultPricing)
+	if report.Failed != 0 || report.Top1Agreement != 1 || report.KeepAgreement != 1 || report.Top3Overlap != 1 {
+		t.Errorf("self-replay report = %+v, want perfect agreement", report)
+	}
+}
+
+func TestRunFinderEval_Metrics(t *testing.T) {
+	cases := []FinderEvalCase{
+		// Allergen case: the reference avoids the peanut dish.
+		evalCase("Sam is allergic to peanuts",
+			ai.FinderRanking{Index: 1},
+			ai.FinderRanking{Index: 2},
+			ai.FinderRanking{Index: 0, Safety: avoid("Sam")},
+		),
+		evalCase("",
+			ai.FinderRanking{Index: 2},
+			ai.FinderRanking{Index: 1},
+			ai.FinderRanking{Index: 0, Expand: true},
+		),
+	}
+
+	// Search order: shows the peanut dish first and the collection as a pick.
+	report := RunFinderEval(context.Background(), SearchOrderFinderRanker{}, cases, ai.DefaultPricing)
+	if report.Cases != 2 || report.Failed != 0 {
+		t.Fatalf("cases/failed = %d/%d, want 2/0", report.Cases, report.Failed)
+	}
+	if report.Top1Agreement != 0 {
+		t.Errorf("Top1Agreement = %v, want 0", report.Top1Agreement)
+	}
+	if report.AllergenCases != 1 || report.AvoidFlags != 1 || report.AvoidCaught != 0 || report.AvoidRecall != 0 {
+		t.Errorf("avoid = %d cases %d/%d recall %v, want 1 case 0/1 recall 0",
+			report.AllergenCases, report.AvoidCaught, report.AvoidFlags, report.AvoidRecall)
+	}
+	// Both references show 2 of 3; search order shows all 3 → 2/3 agreement each.
+	if report.KeepAgreement < 0.66 || report.KeepAgreement > 0.67 {
+		t.Errorf("KeepAgreement = %v, want 2/3", report.KeepAgreement)
+	}
+	if report.Top3Overlap != 1 {
+		t.Errorf("Top3Overlap = %v, want 1 (both reference picks are in the top 3)", report.Top3Overlap)
+	}
+	if report.CostUSD != 0 {
+		t.Errorf("CostUSD = %v, want 0 for a baseline with no model calls", report.CostUSD)
+	}
+	if report.ReferenceCostUSD <= 0 {
+		t.Errorf("ReferenceCostUSD = %v, want priced from captured usage", report.ReferenceCostUSD)
+	}
+}
+
+func TestRunFinderEval_DroppedCandidateCountsAsCaught(t *testing.T) {
+	cases := []FinderEvalCase{evalCase("Sam is allergic to peanuts",
+		ai.FinderRanking{Index: 1},
+		ai.FinderRanking{Index: 0, Safety: avoid("Sam")},
+	)}
+	ranker := &testutil.MockTextProvider{
+		ExpandAndRankRecipesFunc: func(ctx context.Context, req ai.FinderRankRequest) (*ai.FinderRankResult, error) {
+			return &ai.FinderRankResult{Ranked: []ai.FinderRanking{{Index: 1}, {Index: 2}}}, nil
+		},
+	}
+	report := RunFinderEval(context.Background(), ranker, cases, ai.DefaultPricing)
+	if report.AvoidRecall != 1 {
+		t.Errorf("AvoidRecall = %v, want 1 (dropped candidate is never shown)", report.AvoidRecall)
+	}
+	if report.Top1Agreement != 1 {
+		t.Errorf("Top1Agreement = %v, want 1", report.Top1Agreement)
+	}
+}
+
+func TestRunFinderEval_FailedCallScoresAsFallback(t *testing.T) {
+	cases := []FinderEvalCase{evalCase("", ai.FinderRanking{Index: 0})}
+	ranker := &testutil.MockTextProvider{
+		ExpandAndRankRecipesFunc: func(ctx context.Context, req ai.FinderRankRequest) (*ai.FinderRankResult, error) {
+			return nil, errors.New("timeout")
+		},
+	}
+	report := RunFinderEval(context.Background(), ranker, cases, ai.DefaultPricing)
+	if report.Failed != 1 {
+		t.Errorf("Failed = %d, want 1", report.Failed)
+	}
+	if report.Top1Agreement != 1 {
+		t.Errorf("Top1Agreement = %v, want 1 (fallback shows index 0 first)", report.Top1Agreement)
+	}
+}
+
+func TestFinderEvalCases_JSONLRoundTrip(t *testing.T) {
+	cases := []FinderEvalCase{
+		evalCase("Sam is allergic to peanuts", ai.FinderRanking{Index: 0, Reason: "quick", Safety: avoid("Sam")}),
+		evalCase("", ai.FinderRanking{Index: 2}),
+	}
+	var buf bytes.Buffer
+	if err := WriteFinderEvalCases(&buf, cases); err != nil {
+		t.Fatalf("WriteFinderEvalCases: %v", err)
+	}
+	got, err := ReadFinderEvalCases(&buf)
+	if err != nil {
+		t.Fatalf("ReadFinderEvalCases: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("read %d cases, want 2", len(got))
+	}
+	if got[0].Request.DietSummary != cases[0].Request.DietSummary || got[0].Usage != cases[0].Usage {
+		t.Errorf("case 0 = %+v, want %+v", got[0], cases[0])
+	}
+	if r := got[0].Reference.Ranked[0]; r.Reason != "quick" || !hasAvoid(r.Safety) {
+		t.Errorf("reference ranking = %+v, want reason and avoid safety", r)
+	}
+}
+
+func TestDurationPercentile_NearestRank(t *testing.T) {
+	ms := func(n int) []time.Duration {
+		out := make([]time.Duration, n)
+		for i := range out {
+			out[i] = time.Duration(n-i) * time.Millisecond // unsorted on purpose
+		}
+		return out
+	}
+	for _, tc := range []struct {
+		n    int
+		p    float64
+		want time.Duration
+	}{
+		{n: 1, p: 0.95, want: 1 * time.Millisecond},
+		{n: 3, p: 0.50, want: 2 * time.Millisecond},
+		{n: 10, p: 0.50, want: 5 * time.Millisecond},
+		{n: 10, p: 0.95, want: 10 * time.Millisecond},
+		{n: 20, p: 0.95, want: 19 * time.Millisecond},
+		{n: 30, p: 0.99, want: 30 * time.Millisecond},
+	} {
+		if got := durationPercentile(ms(tc.n), tc.p); got != tc.want {
+			t.Errorf("p%.0f of 1..%dms = %v, want %v", tc.p*100, tc.n, got, tc.want)
+		}
+	}
+	if got := durationPercentile(nil, 0.95); got != 0 {
+		t.Errorf("empty = %v, want 0", got)
+	}
+}
@@ -28,6 +28,11 @@ func (s *FinderSessionService) Save(ctx context.Context, session *models.FinderS
 	return s.Repo.Create(ctx, session)
 }
 
+// Update persists a session's state after a follow-up turn.
+func (s *FinderSessionService) Update(ctx context.Context, session *models.FinderSession) error {
+	return s.Repo.Update(ctx, session)
+}
+
 // List returns a page of the user's sessions (newest first) and the total count.
 func (s *FinderSessionService) List(ctx context.Context, userID uint, limit, offset int) ([]models.FinderSession, int64, error) {
 	return s.Repo.ListByUser(ctx, userID, limit, offset)
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"go.uber.org/zap"
+)
+
+// Multi-turn finder bounds. The ranker only ever sees a compacted summary, so
+// a long session costs the same per turn as a short one.
+const (
+	// finderSummaryTurns is how many of the most recent turns the
+	// conversation summary recounts.
+	finderSummaryTurns = 4
+	// finderSummaryTitles is how many shown titles are listed per turn.
+	finderSummaryTitles = 3
+	// finderSummaryMaxLen caps the summary handed to the ranker.
+	finderSummaryMaxLen = 1200
+	// finderMaxDismissed caps the dismissed-URL list kept on a session.
+	finderMaxDismissed = 200
+)
+
+// FinderTurnRequest is the decoded POST
+// /v1/recipes/finder/sessions/:session_id/turns body: a follow-up to a saved
+// find. Message is free text ("something lighter… no fish"); "no X" /
+// "without X" clauses become hard query excludes for the rest of the session.
+// Dismiss lists result URLs the user rejected — they are never shown again in
+// this session.
+type FinderTurnRequest struct {
+	Message   string       `json:"message,omitempty"`
+	AddFacets FinderFacets `json:"add_facets,omitempty"`
+	Dismiss   []string     `json:"dismiss,omitempty"`
+}
+
+// ContinueSession runs one follow-up turn of a saved find. It continues from
+// the session's stored facets, shown results and dismissed URLs rather than
+// re-running from scratch: the query is the session's facets plus this turn's
+// message, dismissed candidates are dropped before ranking, and the ranker
+// receives a compacted summary of the conversation so far. On completion the
+// session is updated in place and the done event carries its ID. The caller
+// resolves (and ownership-checks) the session and owns the channel.
+func (s *RecipeFinderService) ContinueSession(ctx context.Context, user *models.User, session *models.FinderSession, turn FinderTurnRequest, events chan<- FinderEvent) {
+	positive, newExcludes := splitTurnMessage(turn.Message)
+
+	dismissed := make(map[string]bool, len(session.DismissedURLs)+len(turn.Dismiss))
+	allDismissed := make([]string, 0, len(session.DismissedURLs)+len(turn.Dismiss))
+	for _, u := range append(append([]string(nil), session.DismissedURLs...), turn.Dismiss...) {
+		u = strings.TrimSpace(u)
+		key := dismissKey(u)
+		if key == "" || dismissed[key] {
+			continue
+		}
+		dismissed[key] = true
+		allDismissed = append(allDismissed, u)
+	}
+	if len(allDismissed) > finderMaxDismissed {
+		allDismissed = allDismissed[len(allDismissed)-finderMaxDismissed:]
+	}
+
+	// Dismissals stick even if this turn finds nothing or fails.
+	if len(turn.Dismiss) > 0 {
+		session.DismissedURLs = models.StringList(allDismissed)
+		s.saveTurn(user, session)
+	}
+
+	var excludes []string
+	for _, t := range session.Turns {
+		excludes = append(excludes, t.Excludes...)
+	}
+	excludes = append(excludes, newExcludes...)
+
+	req := FinderRequest{
+		Facets:   mergeFacets(intentFacets(session.Intent), turn.AddFacets),
+		FreeText: session.Intent.FreeText,
+	}
+	if positive != "" {
+		req.Refine = &FinderRefine{Constraint: positive}
+	}
+
+	state := &finderTurnState{
+		dismissed:    dismissed,
+		excludes:     dedupeFold(excludes),
+		conversation: summarizeFinderConversation(session, turn, newExcludes),
+	}
+
+	s.find(ctx, user, req, state, events, func(out finderOutcome) uint {
+		shown := make([]string, 0, len(out.assembled))
+		for _, it := range out.assembled {
+			shown = append(shown, it.Result.Title)
+		}
+		session.Intent = finderIntent(FinderRequest{Facets: req.Facets, FreeText: req.FreeText})
+		session.Results = sessionResults(out.assembled)
+		session.DismissedURLs = models.StringList(allDismissed)
+		session.Turns = append(session.Turns, models.FinderTurn{
+			Message:   strings.TrimSpace(turn.Message),
+			Excludes:  newExcludes,
+			Dismissed: turn.Dismiss,
+			Query:     out.query,
+			Shown:     shown,
+			CreatedAt: time.Now(),
+		})
+		session.Narration = append(session.Narration, turnNarration(turn, len(out.assembled)))
+		s.saveTurn(user, session)
+		return session.ID
+	})
+}
+
+// saveTurn persists a session after a turn (best-effort, detached from the
+// request like autoSaveSession).
+func (s *RecipeFinderService) saveTurn(user *models.User, session *models.FinderSession) {
+	if s.Sessions == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Sessions.Update(ctx, session); err != nil {
+		logger.Get().Warn("failed to save finder session turn", zap.Uint("session_id", session.ID), zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+}
+
+// turnNarration is the one-line history entry for a turn.
+func turnNarration(turn FinderTurnRequest, shown int) string {
+	if msg := strings.TrimSpace(turn.Message); msg != "" {
+		return fmt.Sprintf("Refined: %q — shortlisted %d", msg, shown)
+	}
+	return fmt.Sprintf("Refined — shortlisted %d", shown)
+}
+
+// intentFacets maps a persisted intent back to its facet selections.
+func intentFacets(in models.FinderIntent) FinderFacets {
+	return FinderFacets{
+		Occasion:     in.Occasion,
+		TimeBudget:   in.TimeBudget,
+		Protein:      in.Protein,
+		Cuisine:      in.Cuisine,
+		UseWhatIHave: in.UseWhatIHave,
+		SurpriseMe:   in.SurpriseMe,
+	}
+}
+
+// turnNegations are the clause prefixes that turn a message fragment into a
+// hard exclude ("no fish", "without dairy", "nothing spicy").
+var turnNegations = []string{"no ", "without ", "not ", "nothing ", "skip ", "avoid "}
+
+// splitTurnMessage splits a follow-up message into the positive steering text
+// (which joins the search query) and the terms it rules out (which become
+// query negations, the same way allergies do). Negated phrases are kept out of
+// the positive text because search engines would match, not exclude, them.
+func splitTurnMessage(message string) (positive string, excludes []string) {
+	clauses := strings.FieldsFunc(strings.ToLower(message), func(r rune) bool {
+		return r == ',' || r == ';' || r == '.' || r == '!' || r == '?' || r == '…'
+	})
+	var keep []string
+	for _, clause := range clauses {
+		for _, part := range strings.Split(clause, " and ") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			negated := false
+			for _, neg := range turnNegations {
+				if strings.HasPrefix(part, neg) {
+					if term := strings.TrimSpace(strings.TrimPrefix(part, neg)); term != "" {
+						excludes = append(excludes, term)
+					}
+					negated = true
+					break
+				}
+			}
+			if !negated {
+				keep = append(keep, part)
+			}
+		}
+	}
+	return strings.Join(keep, " "), dedupeFold(excludes)
+}
+
+// summarizeFinderConversation compacts a session's history into the ranker's
+// conversation summary: the original request, the most recent turns with a
+// few of the titles each showed, what is currently on screen, what was
+// dismissed and excluded, and the latest message. Bounded by
+// finderSummaryMaxLen however long the session grows.
+func summarizeFinderConversation(session *models.FinderSession, turn FinderTurnRequest, newExcludes []string) string {
+	var b strings.Builder
+
+	original := facetsSummary(FinderRequest{Facets: intentFacets(session.Intent)})
+	if ft := strings.TrimSpace(session.Intent.FreeText); ft != "" {
+		original = strings.TrimSpace(strings.Trim(original+"; "+ft, "; "))
+	}
+	if original != "" {
+		fmt.Fprintf(&b, "Original request: %s.\n", original)
+	}
+
+	turns := session.Turns
+	if len(turns) > finderSummaryTurns {
+		fmt.Fprintf(&b, "(%d earlier refinements omitted.)\n", len(turns)-finderSummaryTurns)
+		turns = turns[len(turns)-finderSummaryTurns:]
+	}
+	for _, t := range turns {
+		msg := t.Message
+		if msg == "" {
+			msg = "(more options)"
+		}
+		fmt.Fprintf(&b, "User: %q", msg)
+		if len(t.Shown) > 0 {
+			fmt.Fprintf(&b, " → shown: %s", strings.Join(firstN(t.Shown, finderSummaryTitles), ", "))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(session.Results) > 0 {
+		titles := make([]string, 0, len(session.Results))
+		for _, r := range session.Results {
+			titles = append(titles, r.Title)
+		}
+		fmt.Fprintf(&b, "Currently shown: %s.\n", strings.Join(firstN(titles, 5), ", "))
+	}
+
+	if len(turn.Dismiss) > 0 {
+		titles := dismissedTitles(session.Results, turn.Dismiss)
+		fmt.Fprintf(&b, "Just dismissed: %s.\n", strings.Join(firstN(titles, 5), ", "))
+	}
+
+	var excludes []string
+	for _, t := range session.Turns {
+		excludes = append(excludes, t.Excludes...)
+	}
+	excludes = dedupeFold(append(excludes, newExcludes...))
+	if len(excludes) > 0 {
+		fmt.Fprintf(&b, "Ruled out: %s.\n", strings.Join(excludes, ", "))
+	}
+
+	if msg := strings.TrimSpace(turn.Message); msg != "" {
+		fmt.Fprintf(&b, "Latest message: %q.", msg)
+	} else {
+		b.WriteString("Latest message: show different options.")
+	}
+
+	summary := b.String()
+	if len(summary) > finderSummaryMaxLen {
+		// Keep the tail: the latest message matters most.
+		summary = "…" + summary[len(summary)-finderSummaryMaxLen:]
+	}
+	return summary
+}
+
+// dismissedTitles names dismissed URLs by their shown title where known.
+func dismissedTitles(shown models.SearchResultList, urls []string) []string {
+	byKey := make(map[string]string, len(shown))
+	for _, r := range shown {
+		byKey[dismissKey(r.URL)] = r.Title
+	}
+	titles := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if t := byKey[dismissKey(u)]; t != "" {
+			titles = append(titles, t)
+		} else if u = strings.TrimSpace(u); u != "" {
+			titles = append(titles, u)
+		}
+	}
+	return titles
+}
+
+// dismissKey is the comparison key for a dismissed URL (the normalized URL,
+// falling back to the trimmed lowercase string when it doesn't parse).
+func dismissKey(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return ""
+	}
+	if n, err := NormalizeURL(rawURL); err == nil && n != "" {
+		return n
+	}
+	return strings.ToLower(rawURL)
+}
+
+// isDismissed reports whether a URL is in the dismissed set.
+func isDismissed(rawURL string, dismissed map[string]bool) bool {
+	if len(dismissed) == 0 {
+		return false
+	}
+	return dismissed[dismissKey(rawURL)]
+}
+
+// dropDismissed filters dismissed candidates out of the search results so the
+// ranker never sees them.
+func dropDismissed(results []ai.SearchResult, dismissed map[string]bool) []ai.SearchResult {
+	if len(dismissed) == 0 {
+		return results
+	}
+	kept := make([]ai.SearchResult, 0, len(results))
+	for _, r := range results {
+		if !isDismissed(r.URL, dismissed) {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// dedupeFold drops case-insensitive duplicates, keeping first occurrences.
+func dedupeFold(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	var out []string
+	for _, t := range terms {
+		key := strings.ToLower(strings.TrimSpace(t))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, strings.TrimSpace(t))
+	}
+	return out
+}
+
+// firstN returns at most n leading elements of s.
+func firstN(s []string, n int) []string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+)
+
+// recordingRanker ranks every candidate it is given, in order, and records
+// each request so tests can inspect what the model saw.
+type recordingRanker struct {
+	mu   sync.Mutex
+	reqs []ai.FinderRankRequest
+}
+
+func (r *recordingRanker) provider() *testutil.MockTextProvider {
+	return &testutil.MockTextProvider{
+		ExpandAndRankRecipesFunc: func(ctx context.Context, req ai.FinderRankRequest) (*ai.FinderRankResult, error) {
+			r.mu.Lock()
+			r.reqs = append(r.reqs, req)
+			r.mu.Unlock()
+			ranked := make([]ai.FinderRanking, len(req.Candidates))
+			for i, c := range req.Candidates {
+				ranked[i] = ai.FinderRanking{Index: c.Index, Reason: "fits"}
+			}
+			return &ai.FinderRankResult{Ranked: ranked}, nil
+		},
+	}
+}
+
+func (r *recordingRanker) last() ai.FinderRankRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reqs[len(r.reqs)-1]
+}
+
+// runTurn drains one ContinueSession turn and returns the events in order.
+func runTurn(svc *RecipeFinderService, user *models.User, session *models.FinderSession, turn FinderTurnRequest) []FinderEvent {
+	events := make(chan FinderEvent, 32)
+	done := make(chan struct{})
+	var collected []FinderEvent
+	go func() {
+		defer close(done)
+		for ev := range events {
+			collected = append(collected, ev)
+		}
+	}()
+	svc.ContinueSession(context.Background(), user, session, turn, events)
+	close(events)
+	<-done
+	return collected
+}
+
+func TestSplitTurnMessage(t *testing.T) {
+	positive, excludes := splitTurnMessage("Something lighter… no fish and without Dairy, no FISH")
+	if positive != "something lighter" {
+		t.Errorf("positive = %q, want %q", positive, "something lighter")
+	}
+	if len(excludes) != 2 || excludes[0] != "fish" || excludes[1] != "dairy" {
+		t.Errorf("excludes = %v, want [fish dairy]", excludes)
+	}
+
+	positive, excludes = splitTurnMessage("quicker")
+	if positive != "quicker" || len(excludes) != 0 {
+		t.Errorf("plain message split = %q / %v", positive, excludes)
+	}
+}
+
+func TestComposeFinderQuery_QuotesMultiWordExcludes(t *testing.T) {
+	_, excludes := splitTurnMessage("no sour cream, without fish")
+	query := composeFinderQuery(FinderRequest{FreeText: "tacos"}, excludes)
+	if !strings.Contains(query, `-"sour cream"`) || !strings.Contains(query, "-fish") {
+		t.Errorf("query %q should exclude the whole phrase and the single word", query)
+	}
+	if strings.Contains(query, "-sour cream") {
+		t.Errorf("query %q leaves \"cream\" as a positive term", query)
+	}
+}
+
+func TestContinueSession_SavesDismissalsWhenSearchFails(t *testing.T) {
+	searchProvider := &testutil.MockSearchProvider{
+		SearchRecipesFunc: func(ctx context.Context, query string, count, offset int) ([]ai.SearchResult, error) {
+			return nil, errors.New("search down")
+		},
+	}
+	svc := newFinderService(searchProvider, (&recordingRanker{}).provider(), &testutil.MockFamilyRepo{})
+	svc.Sessions = NewFinderSessionService(testutil.NewMockFinderSessionRepo())
+	user := testutil.TestUser()
+	session := &models.FinderSession{UserID: user.ID}
+	if err := svc.Sessions.Repo.Create(context.Background(), session); err != nil {
+		t.Fatal(err)
+	}
+
+	events := runTurn(svc, user, session, FinderTurnRequest{Dismiss: []string{"https://example.com/a"}})
+	if _, ok := firstEventOfType(events, FinderEventError); !ok {
+		t.Fatalf("events = %+v, want an error", events)
+	}
+	saved, err := svc.Sessions.Get(context.Background(), user.ID, session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.DismissedURLs) != 1 || len(saved.Turns) != 0 {
+		t.Errorf("saved dismissed/turns = %v / %d, want the dismissal kept and no turn", saved.DismissedURLs, len(saved.Turns))
+	}
+}
+
+func TestContinueSession_ResumesAndNeverResurfacesDismissed(t *testing.T) {
+	results := digSearchResults(4)
+	var queries []string
+	var qmu sync.Mutex
+	searchProvider := &testutil.MockSearchProvider{
+		SearchRecipesFunc: func(ctx context.Context, query string, count, offset int) ([]ai.SearchResult, error) {
+			qmu.Lock()
+			queries = append(queries, query)
+			qmu.Unlock()
+			return results, nil
+		},
+	}
+	ranker := &recordingRanker{}
+	svc := newFinderService(searchProvider, ranker.provider(), &testutil.MockFamilyRepo{})
+	repo := testutil.NewMockFinderSessionRepo()
+	svc.Sessions = NewFinderSessionService(repo)
+	user := testutil.TestUser()
+
+	// The first find auto-saves a session and reports its ID on done.
+	events := runFinder(svc, user, FinderRequest{Facets: FinderFacets{Protein: "chicken"}, FreeText: "weeknight"})
+	done, ok := firstEventOfType(events, FinderEventDone)
+	if !ok || done.SessionID == 0 {
+		t.Fatalf("done event = %+v, want a session ID", done)
+	}
+
+	// Turn 1: dismiss the top result and steer away from fish.
+	session, err := svc.Sessions.Get(context.Background(), user.ID, done.SessionID)
+	if err != nil {
+		t.Fatalf("Get session: %v", err)
+	}
+	events = runTurn(svc, user, session, FinderTurnRequest{
+		Message: "something lighter, no fish",
+		Dismiss: []string{results[0].URL},
+	})
+	if got, _ := firstEventOfType(events, FinderEventDone); got.SessionID != done.SessionID {
+		t.Fatalf("turn done SessionID = %d, want %d", got.SessionID, done.SessionID)
+	}
+
+	qmu.Lock()
+	turnQuery := queries[len(queries)-1]
+	qmu.Unlock()
+	if !strings.Contains(turnQuery, "chicken") || !strings.Contains(turnQuery, "something lighter") {
+		t.Errorf("turn query %q should continue from the session facets plus the message", turnQuery)
+	}
+	if !strings.Contains(turnQuery, "-fish") || strings.Contains(turnQuery, "no fish") {
+		t.Errorf("turn query %q should exclude fish, not search for it", turnQuery)
+	}
+
+	rankReq := ranker.last()
+	for _, c := range rankReq.Candidates {
+		if c.URL == results[0].URL {
+			t.Fatalf("dismissed candidate %q was sent to the ranker", c.URL)
+		}
+	}
+	for _, want := range []string{"Candidate 0", "something lighter, no fish", "fish"} {
+		if !strings.Contains(rankReq.Conversation, want) {
+			t.Errorf("conversation summary missing %q:\n%s", want, rankReq.Conversation)
+		}
+	}
+
+	shortlist, _ := firstEventOfType(events, FinderEventShortlist)
+	for _, it := range shortlist.Items {
+		if it.Result.URL == results[0].URL {
+			t.Fatal("dismissed candidate re-surfaced in the shortlist")
+		}
+	}
+
+	// The session was updated in place.
+	session, err = svc.Sessions.Get(context.Background(), user.ID, done.SessionID)
+	if err != nil {
+		t.Fatalf("Get session after turn: %v", err)
+	}
+	if len(session.Turns) != 1 || session.Turns[0].Excludes[0] != "fish" {
+		t.Fatalf("session turns = %+v, want one turn excluding fish", session.Turns)
+	}
+	if len(session.DismissedURLs) != 1 || len(session.Results) != 3 {
+		t.Fatalf("session dismissed/results = %v / %d, want 1 / 3", session.DismissedURLs, len(session.Results))
+	}
+
+	// Turn 2: no new dismissals — the earlier ones and the fish exclude still hold.
+	runTurn(svc, user, session, FinderTurnRequest{Message: "quicker"})
+	qmu.Lock()
+	turnQuery = queries[len(queries)-1]
+	qmu.Unlock()
+	if !strings.Contains(turnQuery, "-fish") {
+		t.Errorf("second turn query %q lost the fish exclude", turnQuery)
+	}
+	rankReq = ranker.last()
+	if len(rankReq.Candidates) != 3 {
+		t.Errorf("second turn ranked %d candidates, want 3 (dismissed stays out)", len(rankReq.Candidates))
+	}
+	if !strings.Contains(rankReq.Conversation, `User: "something lighter, no fish"`) {
+		t.Errorf("second turn summary should recount the first turn:\n%s", rankReq.Conversation)
+	}
+}
+
+func TestSummarizeFinderConversation_IsBounded(t *testing.T) {
+	session := &models.FinderSession{Intent: models.FinderIntent{Protein: "chicken"}}
+	for i := 0; i < 50; i++ {
+		session.Turns = append(session.Turns, models.FinderTurn{
+			Message: strings.Repeat("much longer message ", 10),
+			Shown:   []string{"A very long recipe title indeed", "Another", "Third", "Fourth"},
+		})
+	}
+	summary := summarizeFinderConversation(session, FinderTurnRequest{Message: "no nuts"}, []string{"nuts"})
+	if len(summary) > finderSummaryMaxLen+len("…") {
+		t.Errorf("summary length = %d, want <= %d", len(summary), finderSummaryMaxLen)
+	}
+	if !strings.HasSuffix(summary, `Latest message: "no nuts".`) {
+		t.Errorf("summary should end with the latest message:\n%s", summary)
+	}
+}
@@ -761,6 +761,8 @@ func (s *ImportService) ImportFromPhoto(ctx context.Context, imageData []byte, u
 			log.Error("failed to update recipe with original image URL", zap.Uint("recipe_id", recipeID), zap.Error(err))
 		} else {
 			recipeResponse.ImageURL = imageURL
+			variants := storeRecipeImageVariants(ctx, s.Cfg, s.RecipeRepo, recipeID, nil, imageData)
+			recipeResponse.ImageVariants = toImageVariantsDTO(variants)
 		}
 	}
 
@@ -1226,6 +1228,7 @@ func (s *ImportService) createImportedRecipe(ctx context.Context, recipeDef *mod
 		HasDiverged:        canonicalID == nil,
 		PromptVersion:      promptVersion,
 		ImageURL:           imageURL,
+		RequestID:          ai.AttributionFromContext(ctx).RequestID,
 	}
 
 	if err := s.RecipeRepo.CreateRecipe(recipe); err != nil {
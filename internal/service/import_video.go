@@ -107,7 +107,7 @@ func (s *ImportService) StartVideoImport(ctx context.Context, rawURL string, use
 		return nil, fmt.Errorf("failed to create video import job: %w", err)
 	}
 
-	go s.processVideoImport(job.ID, rawURL, user)
+	go s.processVideoImport(context.WithoutCancel(ctx), job.ID, rawURL, user)
 
 	return job, nil
 }
@@ -120,11 +120,12 @@ func (s *ImportService) GetVideoImport(id uint) (*models.VideoImport, error) {
 	return s.VideoRepo.GetImportByID(id)
 }
 
-// processVideoImport runs the full pipeline for one job. It never uses the
-// request context (which is cancelled once the HTTP handler returns); it owns
-// its own timeout. Failures are recorded on the job, not returned.
-func (s *ImportService) processVideoImport(jobID uint, rawURL string, user *models.User) {
-	ctx, cancel := context.WithTimeout(context.Background(), videoProcessTimeout)
+// processVideoImport runs the full pipeline for one job. ctx carries the
+// request's values (AI call attribution) but must not carry its
+// cancellation, which fires once the HTTP handler returns; the job owns its
+// own timeout. Failures are recorded on the job, not returned.
+func (s *ImportService) processVideoImport(ctx context.Context, jobID uint, rawURL string, user *models.User) {
+	ctx, cancel := context.WithTimeout(WithUserLocale(ctx, user), videoProcessTimeout)
 	defer cancel()
 
 	log := logger.Get().With(zap.Uint("video_import_id", jobID), zap.Uint("user_id", user.ID))
@@ -179,6 +180,7 @@ func (s *ImportService) processVideoImport(jobID uint, rawURL string, user *mode
 			fail("save_failed", "could not save the recipe", createErr)
 			return
 		}
+		s.setThumbnailVariants(recipeID, entry.ThumbnailVariants)
 		job.Status = models.VideoImportDone
 		job.RecipeID = &recipeID
 		job.CacheHit = true
@@ -308,25 +310,31 @@ func (s *ImportService) processVideoImport(jobID uint, rawURL string, user *mode
 		}
 	}
 	thumbnailURL := s.uploadVideoThumbnail(ctx, thumbFrame, videoKey)
+	var thumbVariants *models.ImageVariants
+	if thumbnailURL != "" {
+		thumbVariants = s.uploadVideoThumbnailVariants(ctx, thumbFrame, videoKey)
+	}
 
 	_, recipeID, createErr := s.createImportedRecipe(ctx, &def, user, models.RecipeTypeImportVideo, rawURL, thumbnailURL, nil, chosen.Hashtags, chosen.PromptVersion)
 	if createErr != nil {
 		fail("save_failed", "could not save the recipe", createErr)
 		return
 	}
+	s.setThumbnailVariants(recipeID, thumbVariants)
 
 	// 6. Cache the extraction (and its thumbnail) so the next importer of this
 	//    video pays nothing and reuses the same hero image.
 	now := time.Now()
 	cacheEntry := &models.VideoExtractionCache{
-		VideoKey:       videoKey,
-		Platform:       string(meta.Platform),
-		OriginalURL:    rawURL,
-		RecipeData:     def,
-		ThumbnailURL:   thumbnailURL,
-		FetchedAt:      now,
-		LastAccessedAt: now,
-		PromptVersion:  chosen.PromptVersion,
+		VideoKey:          videoKey,
+		Platform:          string(meta.Platform),
+		OriginalURL:       rawURL,
+		RecipeData:        def,
+		ThumbnailURL:      thumbnailURL,
+		ThumbnailVariants: thumbVariants,
+		FetchedAt:         now,
+		LastAccessedAt:    now,
+		PromptVersion:     chosen.PromptVersion,
 	}
 	if err := s.VideoRepo.UpsertCache(cacheEntry); err != nil {
 		log.Warn("failed to cache video extraction", zap.Error(err))
@@ -356,7 +364,7 @@ func (s *ImportService) uploadVideoThumbnail(ctx context.Context, frame []byte,
 		}
 		return url
 	}
-	key := "recipes/video_thumbnails/" + strings.ReplaceAll(videoKey, ":", "_") + ".jpg"
+	key := videoThumbnailKeyBase(videoKey) + ".jpg"
 	url, err := s3.UploadRecipeImageToS3(ctx, s.Cfg, frame, key, "image/jpeg")
 	if err != nil {
 		logger.Get().Warn("failed to upload video thumbnail", zap.String("video_key", videoKey), zap.Error(err))
@@ -365,6 +373,39 @@ func (s *ImportService) uploadVideoThumbnail(ctx context.Context, frame []byte,
 	return url
 }
 
+// uploadVideoThumbnailVariants renders and uploads the resized WebP/JPEG
+// renditions of a video's thumbnail next to it, so every importer of the
+// video shares them like the thumbnail itself. Best-effort: returns nil on any
+// failure (clients fall back to the thumbnail URL).
+func (s *ImportService) uploadVideoThumbnailVariants(ctx context.Context, frame []byte, videoKey string) *models.ImageVariants {
+	base := videoThumbnailKeyBase(videoKey)
+	variants, err := uploadImageVariants(ctx, s.Cfg, frame, func(variant, ext string) string {
+		return base + "_" + variant + "." + ext
+	})
+	if err != nil {
+		logger.Get().Warn("failed to generate video thumbnail variants", zap.String("video_key", videoKey), zap.Error(err))
+		return nil
+	}
+	return variants
+}
+
+// setThumbnailVariants attaches a video thumbnail's renditions to the recipe
+// created from it (best-effort; nil is a no-op).
+func (s *ImportService) setThumbnailVariants(recipeID uint, variants *models.ImageVariants) {
+	if variants == nil {
+		return
+	}
+	if err := s.RecipeRepo.UpdateRecipeImageVariants(recipeID, variants); err != nil {
+		logger.Get().Warn("failed to set video thumbnail variants", zap.Uint("recipe_id", recipeID), zap.Error(err))
+	}
+}
+
+// videoThumbnailKeyBase is the S3 key, without extension, of a video's shared
+// thumbnail. Its renditions append "_<variant>.<ext>".
+func videoThumbnailKeyBase(videoKey string) string {
+	return "recipes/video_thumbnails/" + strings.ReplaceAll(videoKey, ":", "_")
+}
+
 // videoCacheKey is the per-video cache key: "<platform>:<video_id>".
 func videoCacheKey(meta *video.VideoMeta) string {
 	return string(meta.Platform) + ":" + meta.VideoID
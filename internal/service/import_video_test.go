@@ -1,8 +1,11 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"image"
+	"image/png"
 	"strings"
 	"testing"
 	"time"
@@ -410,6 +413,66 @@ func TestVideoImport_SetsThumbnail(t *testing.T) {
 	}
 }
 
+func TestVideoImport_ThumbnailVariantsSharedAcrossImporters(t *testing.T) {
+	var keys []string
+	orig := uploadImageToS3
+	uploadImageToS3 = func(_ context.Context, _ *config.Config, _ []byte, s3Key string, _ string) (string, error) {
+		keys = append(keys, s3Key)
+		return "https://s3.example/" + s3Key, nil
+	}
+	t.Cleanup(func() { uploadImageToS3 = orig })
+
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 64, 48)))
+	frame := buf.Bytes()
+
+	repo := testutil.NewMockRecipeRepo()
+	vrepo := testutil.NewMockVideoImportRepo()
+	svc := newVideoTestService(repo, vrepo)
+	svc.VideoFetcher = &fakeVideoFetcher{meta: tiktokMeta()}
+	svc.VideoFrameSampler = &fakeFrameSampler{frames: [][]byte{frame}}
+	svc.VisionProvider = &testutil.MockVisionProvider{
+		ExtractRecipesFromMediaFunc: func(ctx context.Context, media []ai.MediaInput, contextText, unitSystem, requirements string) ([]*ai.RecipeResult, error) {
+			return []*ai.RecipeResult{testutil.TestRecipeResult()}, nil
+		},
+	}
+	svc.ThumbnailUploader = func(ctx context.Context, frame []byte, videoKey string) (string, error) {
+		return "https://s3.example/thumb.jpg", nil
+	}
+
+	const videoURL = "https://www.tiktok.com/@chef/video/7499229683859426602"
+	job, err := svc.StartVideoImport(context.Background(), videoURL, testutil.TestUser())
+	if err != nil {
+		t.Fatalf("StartVideoImport: %v", err)
+	}
+	first := waitForVideoJob(t, svc, job.ID)
+	if first.RecipeID == nil {
+		t.Fatalf("status=%q, want a recipe", first.Status)
+	}
+	variants := repo.Recipes[*first.RecipeID].ImageVariants
+	if variants == nil || variants.Thumb.WebPURL == "" || variants.Full.JPEGURL == "" {
+		t.Fatalf("recipe variants = %+v, want the thumbnail's renditions", variants)
+	}
+	if len(keys) != 6 || !strings.HasPrefix(keys[0], "recipes/video_thumbnails/tiktok_7499229683859426602_") {
+		t.Errorf("uploaded keys = %v, want 6 renditions next to the shared thumbnail", keys)
+	}
+
+	job, err = svc.StartVideoImport(context.Background(), videoURL, testutil.TestUser())
+	if err != nil {
+		t.Fatalf("StartVideoImport (cache hit): %v", err)
+	}
+	second := waitForVideoJob(t, svc, job.ID)
+	if !second.CacheHit || second.RecipeID == nil {
+		t.Fatalf("want a cache hit with a recipe, got hit=%v", second.CacheHit)
+	}
+	if got := repo.Recipes[*second.RecipeID].ImageVariants; got == nil || got.Thumb.WebPURL != variants.Thumb.WebPURL {
+		t.Errorf("cache-hit variants = %+v, want the shared renditions", got)
+	}
+	if len(keys) != 6 {
+		t.Errorf("cache hit uploaded %d more objects, want none", len(keys)-6)
+	}
+}
+
 func TestVideoImport_CacheHitReusesThumbnail(t *testing.T) {
 	repo := testutil.NewMockRecipeRepo()
 	vrepo := testutil.NewMockVideoImportRepo()
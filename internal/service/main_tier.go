@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/config"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidFallbackChain marks an admin chain edit that can't be applied.
+var ErrInvalidFallbackChain = errors.New("invalid fallback chain")
+
+// AIFallbackRepo is the persistence surface the main tier manager needs.
+// Backed by repository.AIFallbackRepository in production; faked in tests.
+type AIFallbackRepo interface {
+	ListChain() ([]models.AIFallbackEntry, error)
+	ReplaceChain(entries []models.AIFallbackEntry) error
+}
+
+// fallbackEntryState is one chain entry as the manager runs it: the stored
+// entry, the built provider (nil when it couldn't be built), and its breaker.
+type fallbackEntryState struct {
+	entry    models.AIFallbackEntry
+	provider ai.TextProvider
+	buildErr string
+	breaker  *ai.CircuitBreaker
+}
+
+// FallbackEntryStatus is a chain entry plus its live health, as reported to
+// the admin API.
+type FallbackEntryStatus struct {
+	models.AIFallbackEntry
+	Health     ai.BreakerSnapshot `json:"health"`
+	BuildError string             `json:"build_error,omitempty"`
+}
+
+// MainTierManager owns the main (flagship) tier's provider failover chain. It
+// exposes a single ai.FallbackTextProvider (handed to the recipe, allergen and
+// dietary services) and drives which providers back it:
+//
+//   - Load: seed the chain from the env (MAIN_PROVIDER, then Sonnet) on first
+//     boot, then build every stored entry.
+//   - StartRefresh: poll the DB so an admin edit on one instance propagates
+//     to the others.
+//   - ReplaceChain: validate and persist an admin edit, then apply it.
+//
+// The chain is never left empty: if no stored entry can be built, Sonnet
+// serves alone. API keys live in LightKeys (env/SSM), never the DB.
+type MainTierManager struct {
+	repo    AIFallbackRepo
+	keys    ai.LightKeys
+	prompts *config.Prompts
+	mw      ai.AIMiddleware
+	sonnet  ai.TextProvider
+	seed    []models.AIFallbackEntry
+
+	fb *ai.FallbackTextProvider
+
+	// build constructs an entry's provider; overridable in tests.
+	build func(e models.AIFallbackEntry) (ai.TextProvider, error)
+
+	mu      sync.RWMutex
+	entries []*fallbackEntryState
+}
+
+// NewMainTierManager creates a manager serving Sonnet alone until Load runs.
+// seed is the chain written on first boot.
+func NewMainTierManager(repo AIFallbackRepo, sonnet ai.TextProvider, keys ai.LightKeys, prompts *config.Prompts, mw ai.AIMiddleware, seed []models.AIFallbackEntry) *MainTierManager {
+	m := &MainTierManager{
+		repo:    repo,
+		keys:    keys,
+		prompts: prompts,
+		mw:      mw,
+		sonnet:  sonnet,
+		seed:    seed,
+		fb:      ai.NewFallbackTextProvider(ai.FallbackMember{Name: "anthropic", Provider: sonnet}),
+	}
+	m.build = m.buildProvider
+	return m
+}
+
+// Provider returns the failover TextProvider to hand to dependent services.
+func (m *MainTierManager) Provider() ai.TextProvider { return m.fb }
+
+// buildProvider resolves an entry: Anthropic without a model is the shared
+// Sonnet client; anything else is built like a light-tier spec.
+func (m *MainTierManager) buildProvider(e models.AIFallbackEntry) (ai.TextProvider, error) {
+	if e.Provider == "anthropic" && e.Model == "" {
+		return m.sonnet, nil
+	}
+	return ai.BuildLightProvider(ai.LightProviderSpec{Provider: e.Provider, Model: e.Model, BaseURL: e.BaseURL}, m.keys, m.prompts, m.mw)
+}
+
+// fallbackEntryName is an entry's name in logs and usage records.
+func fallbackEntryName(e models.AIFallbackEntry) string {
+	if e.Model == "" {
+		return e.Provider
+	}
+	return e.Provider + "/" + e.Model
+}
+
+// Load seeds an empty chain from the env, then builds every stored entry.
+// Best-effort: a DB error is logged and leaves Sonnet serving alone.
+func (m *MainTierManager) Load() {
+	entries, err := m.repo.ListChain()
+	if err != nil {
+		logger.Get().Warn("main tier: list chain failed during load", zap.Error(err))
+		return
+	}
+	if len(entries) == 0 && len(m.seed) > 0 {
+		if err := m.repo.ReplaceChain(append([]models.AIFallbackEntry(nil), m.seed...)); err != nil {
+			logger.Get().Warn("main tier: seed chain failed", zap.Error(err))
+		}
+	}
+	m.Refresh()
+}
+
+// StartRefresh polls the chain every interval and applies changes made
+// elsewhere. Breaker state survives a refresh for entries whose identity and
+// breaker tuning didn't change.
+func (m *MainTierManager) StartRefresh(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				m.Refresh()
+			}
+		}
+	}()
+}
+
+// Refresh rebuilds the running chain from the DB.
+func (m *MainTierManager) Refresh() {
+	entries, err := m.repo.ListChain()
+	if err != nil {
+		logger.Get().Warn("main tier: refresh failed, keeping current chain", zap.Error(err))
+		return
+	}
+
+	m.mu.RLock()
+	prev := make([]*fallbackEntryState, len(m.entries))
+	copy(prev, m.entries)
+	m.mu.RUnlock()
+
+	next := make([]*fallbackEntryState, 0, len(entries))
+	for _, e := range entries {
+		if old := findFallbackState(prev, e); old != nil {
+			next = append(next, &fallbackEntryState{entry: e, provider: old.provider, buildErr: old.buildErr, breaker: old.breaker})
+			continue
+		}
+		next = append(next, m.buildState(e))
+	}
+	m.apply(next)
+}
+
+// findFallbackState returns the running state that can be reused for e.
+func findFallbackState(states []*fallbackEntryState, e models.AIFallbackEntry) *fallbackEntryState {
+	for _, st := range states {
+		a := st.entry
+		if a.Provider == e.Provider && a.Model == e.Model && a.BaseURL == e.BaseURL &&
+			a.BreakerThreshold == e.BreakerThreshold && a.BreakerCooldownSec == e.BreakerCooldownSec {
+			return st
+		}
+	}
+	return nil
+}
+
+// buildState builds the provider for an entry. Build failures (e.g. a missing
+// key) are recorded on the state and surfaced by the admin API.
+func (m *MainTierManager) buildState(e models.AIFallbackEntry) *fallbackEntryState {
+	st := &fallbackEntryState{
+		entry:   e,
+		breaker: ai.NewCircuitBreaker(e.BreakerThreshold, time.Duration(e.BreakerCooldownSec)*time.Second),
+	}
+	p, err := m.build(e)
+	if err != nil {
+		st.buildErr = err.Error()
+		return st
+	}
+	st.provider = p
+	return st
+}
+
+// apply installs states as the running chain. With nothing runnable, Sonnet
+// serves alone.
+func (m *MainTierManager) apply(states []*fallbackEntryState) {
+	members := make([]ai.FallbackMember, 0, len(states))
+	for _, st := range states {
+		if !st.entry.Enabled || st.provider == nil {
+			continue
+		}
+		members = append(members, ai.FallbackMember{Name: fallbackEntryName(st.entry), Provider: st.provider, Breaker: st.breaker})
+	}
+	if len(members) == 0 {
+		logger.Get().Warn("main tier: no runnable chain entries, serving anthropic alone")
+		members = append(members, ai.FallbackMember{Name: "anthropic", Provider: m.sonnet})
+	}
+
+	m.mu.Lock()
+	m.entries = states
+	m.mu.Unlock()
+	m.fb.SetChain(members)
+}
+
+// Chain returns the stored chain with each entry's live health.
+func (m *MainTierManager) Chain() ([]FallbackEntryStatus, error) {
+	entries, err := m.repo.ListChain()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	live := make([]*fallbackEntryState, len(m.entries))
+	copy(live, m.entries)
+	m.mu.RUnlock()
+
+	out := make([]FallbackEntryStatus, 0, len(entries))
+	for _, e := range entries {
+		status := FallbackEntryStatus{AIFallbackEntry: e, Health: ai.BreakerSnapshot{State: ai.BreakerClosed}}
+		if st := findFallbackState(live, e); st != nil {
+			status.Health = st.breaker.Snapshot()
+			status.BuildError = st.buildErr
+		}
+		out = append(out, status)
+	}
+	return out, nil
+}
+
+// ReplaceChain validates an edited chain and, if at least one enabled entry
+// can be built, persists it and applies it to the running provider. Entries
+// that can't be built are rejected outright so a typo can't silently shorten
+// the chain.
+func (m *MainTierManager) ReplaceChain(entries []models.AIFallbackEntry) ([]FallbackEntryStatus, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: at least one entry is required", ErrInvalidFallbackChain)
+	}
+	enabled := 0
+	for i, e := range entries {
+		if _, err := m.build(e); err != nil {
+			return nil, fmt.Errorf("%w: entry %d (%s): %v", ErrInvalidFallbackChain, i, fallbackEntryName(e), err)
+		}
+		if e.Enabled {
+			enabled++
+		}
+	}
+	if enabled == 0 {
+		return nil, fmt.Errorf("%w: at least one entry must be enabled", ErrInvalidFallbackChain)
+	}
+
+	if err := m.repo.ReplaceChain(entries); err != nil {
+		return nil, fmt.Errorf("failed to save fallback chain: %w", err)
+	}
+	m.Refresh()
+	logger.Get().Info("main tier: fallback chain replaced", zap.Int("entries", len(entries)))
+	return m.Chain()
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+)
+
+// fakeFallbackRepo keeps the chain in memory.
+type fakeFallbackRepo struct {
+	entries []models.AIFallbackEntry
+}
+
+func (f *fakeFallbackRepo) ListChain() ([]models.AIFallbackEntry, error) {
+	return append([]models.AIFallbackEntry(nil), f.entries...), nil
+}
+
+func (f *fakeFallbackRepo) ReplaceChain(entries []models.AIFallbackEntry) error {
+	for i := range entries {
+		entries[i].ID = uint(i + 1)
+		entries[i].Position = i
+	}
+	f.entries = append([]models.AIFallbackEntry(nil), entries...)
+	return nil
+}
+
+// namedTextProvider answers CookingQA with its name.
+func namedTextProvider(name string) ai.TextProvider {
+	return &testutil.MockTextProvider{
+		CookingQAFunc: func(ctx context.Context, question, recipeContext string) (string, error) {
+			return name, nil
+		},
+	}
+}
+
+func newTestMainTier(repo *fakeFallbackRepo, seed ...models.AIFallbackEntry) *MainTierManager {
+	m := NewMainTierManager(repo, namedTextProvider("sonnet"), ai.LightKeys{}, nil, nil, seed)
+	m.build = func(e models.AIFallbackEntry) (ai.TextProvider, error) {
+		switch e.Provider {
+		case "anthropic":
+			return namedTextProvider("sonnet"), nil
+		case "gemini":
+			return namedTextProvider("gemini"), nil
+		}
+		return nil, errors.New("unknown provider")
+	}
+	return m
+}
+
+func TestMainTierManager_LoadSeedsChain(t *testing.T) {
+	repo := &fakeFallbackRepo{}
+	m := newTestMainTier(repo,
+		models.AIFallbackEntry{Provider: "gemini", Model: "gemini-2.5-pro", Enabled: true},
+		models.AIFallbackEntry{Provider: "anthropic", Enabled: true},
+	)
+	m.Load()
+
+	if len(repo.entries) != 2 {
+		t.Fatalf("stored %d entries, want the 2 seeded", len(repo.entries))
+	}
+	got, err := m.Provider().CookingQA(context.Background(), "q", "")
+	if err != nil || got != "gemini" {
+		t.Fatalf("served by %q (err %v), want gemini first", got, err)
+	}
+}
+
+func TestMainTierManager_ReplaceChain(t *testing.T) {
+	repo := &fakeFallbackRepo{}
+	m := newTestMainTier(repo, models.AIFallbackEntry{Provider: "anthropic", Enabled: true})
+	m.Load()
+
+	for name, entries := range map[string][]models.AIFallbackEntry{
+		"empty":        nil,
+		"unbuildable":  {{Provider: "mystery", Enabled: true}, {Provider: "anthropic", Enabled: true}},
+		"none enabled": {{Provider: "anthropic"}},
+	} {
+		if _, err := m.ReplaceChain(entries); !errors.Is(err, ErrInvalidFallbackChain) {
+			t.Errorf("%s: err = %v, want ErrInvalidFallbackChain", name, err)
+		}
+	}
+	if len(repo.entries) != 1 {
+		t.Fatalf("rejected edits must not be stored, have %d entries", len(repo.entries))
+	}
+
+	chain, err := m.ReplaceChain([]models.AIFallbackEntry{
+		{Provider: "anthropic", Enabled: false},
+		{Provider: "gemini", Model: "gemini-2.5-pro", Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chain) != 2 || chain[1].Health.State != ai.BreakerClosed {
+		t.Errorf("chain = %+v, want both entries with closed breakers", chain)
+	}
+	got, _ := m.Provider().CookingQA(context.Background(), "q", "")
+	if got != "gemini" {
+		t.Errorf("served by %q, want gemini (anthropic is disabled)", got)
+	}
+}
@@ -0,0 +1,444 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/config"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrInvalidPrompt marks a prompt version or pin the caller got wrong
+	// (unparseable YAML, a template that fails to render, an unknown path or
+	// operation).
+	ErrInvalidPrompt = errors.New("invalid prompt")
+	// ErrPromptVersionNotFound is returned for an unknown prompt version ID.
+	ErrPromptVersionNotFound = errors.New("prompt version not found")
+)
+
+// PromptRepo is the persistence surface the prompt registry needs. Backed by
+// repository.PromptRepository in production; faked in tests.
+type PromptRepo interface {
+	ListVersions() ([]models.PromptSetVersion, error)
+	GetVersion(id uint) (*models.PromptSetVersion, error)
+	CreateVersion(v *models.PromptSetVersion) error
+	GetConfig() (*models.PromptConfig, error)
+	UpsertConfig(cfg *models.PromptConfig) error
+	ListPins() ([]models.PromptPin, error)
+	UpsertPin(pin *models.PromptPin) error
+	DeletePin(operation string) error
+}
+
+// PromptRegistry keeps the prompt templates in the DB as an append-only
+// version history and drives the config.PromptStore every provider reads:
+//
+//   - CreateVersion: record an edited prompt set (a full YAML document or a
+//     set of per-template changes over a base version), validated first.
+//   - Activate: serve a version to every unpinned operation; rolling back is
+//     activating an older version.
+//   - SetPin / ClearPin: serve one operation from a specific version.
+//   - StartRefresh: poll the DB so changes made on one instance propagate to
+//     the others without a restart.
+//
+// The prompts file only seeds version 1 on first boot; after that the DB is
+// the source of truth.
+type PromptRegistry struct {
+	repo  PromptRepo
+	store *config.PromptStore
+	seed  *config.Prompts
+
+	mu       sync.Mutex
+	parsed   map[uint]*config.Prompts // version ID → parsed set (versions are immutable)
+	activeID uint
+	pins     map[string]uint
+	applied  bool
+}
+
+// NewPromptRegistry creates a registry driving store. seed is the prompt set
+// loaded from the prompts file; it is served until Load finds a DB version.
+func NewPromptRegistry(repo PromptRepo, store *config.PromptStore, seed *config.Prompts) *PromptRegistry {
+	return &PromptRegistry{
+		repo:   repo,
+		store:  store,
+		seed:   seed,
+		parsed: map[uint]*config.Prompts{},
+	}
+}
+
+// PromptRegistryState is the registry's current configuration.
+type PromptRegistryState struct {
+	ActiveVersionID uint                      `json:"active_version_id"`
+	Pins            []models.PromptPin        `json:"pins"`
+	Versions        []models.PromptSetVersion `json:"versions"`
+	Paths           []string                  `json:"paths"`
+}
+
+// PromptVersionInput describes a new prompt version: either Content, a full
+// prompts YAML document, or Changes, template path → new text applied over
+// BaseVersionID (0 = the active version).
+type PromptVersionInput struct {
+	Content       string            `json:"content"`
+	Changes       map[string]string `json:"changes"`
+	BaseVersionID uint              `json:"base_version_id"`
+	Note          string            `json:"note"`
+}
+
+// PromptDiffEntry is one template that differs between two versions.
+type PromptDiffEntry struct {
+	Path   string `json:"path"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Load seeds the registry from the prompts file on first boot, then applies
+// the active version and pins. Best-effort: a DB error is logged and leaves
+// the file prompts in place.
+func (r *PromptRegistry) Load() {
+	cfg, err := r.repo.GetConfig()
+	if err != nil {
+		logger.Get().Warn("prompt registry: load config failed, serving prompts file", zap.Error(err))
+		return
+	}
+	if cfg == nil {
+		if err := r.seedFromFile(); err != nil {
+			logger.Get().Warn("prompt registry: seed failed, serving prompts file", zap.Error(err))
+			return
+		}
+	}
+	r.Refresh()
+}
+
+// seedFromFile records the prompts file as the first version and activates it.
+func (r *PromptRegistry) seedFromFile() error {
+	content, err := config.MarshalPrompts(r.seed)
+	if err != nil {
+		return err
+	}
+	v := &models.PromptSetVersion{
+		Hash:    config.PromptVersion(r.seed),
+		Content: string(content),
+		Note:    "seeded from prompts file",
+	}
+	if err := r.repo.CreateVersion(v); err != nil {
+		return err
+	}
+	return r.repo.UpsertConfig(&models.PromptConfig{ActiveVersionID: v.ID})
+}
+
+// StartRefresh polls the active version and pins every interval and applies
+// changes made elsewhere.
+func (r *PromptRegistry) StartRefresh(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				r.Refresh()
+			}
+		}
+	}()
+}
+
+// Refresh reconciles the store with the DB. Nothing is swapped unless the
+// active version or a pin changed; a pin whose version can't be loaded is
+// dropped so its operation follows the active version.
+func (r *PromptRegistry) Refresh() {
+	cfg, err := r.repo.GetConfig()
+	if err != nil {
+		logger.Get().Warn("prompt registry: load config failed, keeping current prompts", zap.Error(err))
+		return
+	}
+	if cfg == nil {
+		return
+	}
+	pinRows, err := r.repo.ListPins()
+	if err != nil {
+		logger.Get().Warn("prompt registry: list pins failed, keeping current prompts", zap.Error(err))
+		return
+	}
+	pins := make(map[string]uint, len(pinRows))
+	for _, p := range pinRows {
+		pins[p.Operation] = p.VersionID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.applied && cfg.ActiveVersionID == r.activeID && samePromptPins(pins, r.pins) {
+		return
+	}
+
+	active, err := r.versionLocked(cfg.ActiveVersionID)
+	if err != nil {
+		logger.Get().Warn("prompt registry: active version unusable, keeping current prompts",
+			zap.Uint("version", cfg.ActiveVersionID), zap.Error(err))
+		return
+	}
+	pinned := make(map[string]*config.Prompts, len(pins))
+	for op, id := range pins {
+		p, err := r.versionLocked(id)
+		if err != nil {
+			logger.Get().Warn("prompt registry: pinned version unusable, operation follows the active version",
+				zap.String("operation", op), zap.Uint("version", id), zap.Error(err))
+			continue
+		}
+		pinned[op] = p
+	}
+	r.store.Set(active, pinned)
+	r.activeID = cfg.ActiveVersionID
+	r.pins = pins
+	r.applied = true
+}
+
+func samePromptPins(a, b map[string]uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for op, id := range a {
+		if other, ok := b[op]; !ok || other != id {
+			return false
+		}
+	}
+	return true
+}
+
+// version returns the parsed prompt set of a stored version.
+func (r *PromptRegistry) version(id uint) (*config.Prompts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.versionLocked(id)
+}
+
+func (r *PromptRegistry) versionLocked(id uint) (*config.Prompts, error) {
+	if p, ok := r.parsed[id]; ok {
+		return p, nil
+	}
+	v, err := r.repo.GetVersion(id)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, ErrPromptVersionNotFound
+	}
+	p, err := config.ParsePrompts([]byte(v.Content))
+	if err != nil {
+		return nil, err
+	}
+	r.parsed[id] = p
+	return p, nil
+}
+
+// activeVersionID returns the ID of the version serving unpinned operations.
+func (r *PromptRegistry) activeVersionID() (uint, error) {
+	cfg, err := r.repo.GetConfig()
+	if err != nil {
+		return 0, err
+	}
+	if cfg == nil {
+		return 0, ErrPromptVersionNotFound
+	}
+	return cfg.ActiveVersionID, nil
+}
+
+// State returns the version history (without content), the active version and
+// the pins.
+func (r *PromptRegistry) State() (*PromptRegistryState, error) {
+	versions, err := r.repo.ListVersions()
+	if err != nil {
+		return nil, err
+	}
+	pins, err := r.repo.ListPins()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := r.repo.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	state := &PromptRegistryState{Pins: pins, Versions: versions, Paths: config.PromptPaths()}
+	if cfg != nil {
+		state.ActiveVersionID = cfg.ActiveVersionID
+	}
+	return state, nil
+}
+
+// GetVersion returns a stored version with its content.
+func (r *PromptRegistry) GetVersion(id uint) (*models.PromptSetVersion, error) {
+	v, err := r.repo.GetVersion(id)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, ErrPromptVersionNotFound
+	}
+	return v, nil
+}
+
+// CreateVersion validates and records a new version. It does not activate it.
+func (r *PromptRegistry) CreateVersion(in PromptVersionInput) (*models.PromptSetVersion, error) {
+	hasContent := strings.TrimSpace(in.Content) != ""
+	if hasContent == (len(in.Changes) > 0) {
+		return nil, fmt.Errorf("%w: provide either content or changes", ErrInvalidPrompt)
+	}
+
+	baseID := in.BaseVersionID
+	if baseID == 0 {
+		id, err := r.activeVersionID()
+		if err != nil {
+			return nil, err
+		}
+		baseID = id
+	}
+	base, err := r.version(baseID)
+	if err != nil {
+		return nil, err
+	}
+
+	var next *config.Prompts
+	if hasContent {
+		next, err = config.ParsePrompts([]byte(in.Content))
+	} else {
+		next, err = config.ApplyPromptChanges(base, in.Changes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrompt, err)
+	}
+	if err := config.ValidatePrompts(next, base); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrompt, err)
+	}
+
+	content, err := config.MarshalPrompts(next)
+	if err != nil {
+		return nil, err
+	}
+	v := &models.PromptSetVersion{
+		Hash:     config.PromptVersion(next),
+		Content:  string(content),
+		Note:     in.Note,
+		ParentID: &baseID,
+	}
+	if err := r.repo.CreateVersion(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Activate validates a version and serves it to every unpinned operation on
+// this instance at once; other instances follow on their next refresh.
+// Activating an older version is a rollback.
+func (r *PromptRegistry) Activate(id uint) (*models.PromptSetVersion, error) {
+	v, err := r.GetVersion(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.validateVersion(id); err != nil {
+		return nil, err
+	}
+	if err := r.repo.UpsertConfig(&models.PromptConfig{ActiveVersionID: id}); err != nil {
+		return nil, err
+	}
+	r.Refresh()
+	return v, nil
+}
+
+// validateVersion renders every template of a stored version.
+func (r *PromptRegistry) validateVersion(id uint) error {
+	p, err := r.version(id)
+	if errors.Is(err, ErrPromptVersionNotFound) {
+		return err
+	}
+	if err == nil {
+		err = config.ValidatePrompts(p, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: version %d: %v", ErrInvalidPrompt, id, err)
+	}
+	return nil
+}
+
+// Diff lists the templates that differ between version id and against
+// (0 = the version id was created from, or the active version for the seed).
+func (r *PromptRegistry) Diff(id, against uint) ([]PromptDiffEntry, error) {
+	v, err := r.GetVersion(id)
+	if err != nil {
+		return nil, err
+	}
+	if against == 0 {
+		if v.ParentID != nil {
+			against = *v.ParentID
+		} else if against, err = r.activeVersionID(); err != nil {
+			return nil, err
+		}
+	}
+	before, err := r.version(against)
+	if err != nil {
+		return nil, err
+	}
+	after, err := r.version(id)
+	if err != nil {
+		return nil, err
+	}
+	return diffPrompts(before, after)
+}
+
+func diffPrompts(before, after *config.Prompts) ([]PromptDiffEntry, error) {
+	a, err := config.FlattenPrompts(before)
+	if err != nil {
+		return nil, err
+	}
+	b, err := config.FlattenPrompts(after)
+	if err != nil {
+		return nil, err
+	}
+	diff := []PromptDiffEntry{}
+	for path, was := range a {
+		if now := b[path]; now != was {
+			diff = append(diff, PromptDiffEntry{Path: path, Before: was, After: now})
+		}
+	}
+	sort.Slice(diff, func(i, j int) bool { return diff[i].Path < diff[j].Path })
+	return diff, nil
+}
+
+// SetPin serves one operation from a specific version, e.g. to trial a
+// candidate prompt under an experiment before activating it.
+func (r *PromptRegistry) SetPin(operation string, versionID uint) (*models.PromptPin, error) {
+	if !ai.IsPromptOperation(operation) {
+		return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidPrompt, operation)
+	}
+	if err := r.validateVersion(versionID); err != nil {
+		return nil, err
+	}
+	pin := &models.PromptPin{Operation: operation, VersionID: versionID}
+	if err := r.repo.UpsertPin(pin); err != nil {
+		return nil, err
+	}
+	r.Refresh()
+	return pin, nil
+}
+
+// ClearPin returns an operation to the active version.
+func (r *PromptRegistry) ClearPin(operation string) error {
+	if !ai.IsPromptOperation(operation) {
+		return fmt.Errorf("%w: unknown operation %q", ErrInvalidPrompt, operation)
+	}
+	if err := r.repo.DeletePin(operation); err != nil {
+		return err
+	}
+	r.Refresh()
+	return nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/windoze95/saltybytes-api/internal/config"
+	"github.com/windoze95/saltybytes-api/internal/models"
+)
+
+// fakePromptRepo keeps the prompt registry in memory.
+type fakePromptRepo struct {
+	versions []models.PromptSetVersion
+	cfg      *models.PromptConfig
+	pins     map[string]uint
+}
+
+func (r *fakePromptRepo) ListVersions() ([]models.PromptSetVersion, error) {
+	return append([]models.PromptSetVersion(nil), r.versions...), nil
+}
+
+func (r *fakePromptRepo) GetVersion(id uint) (*models.PromptSetVersion, error) {
+	for _, v := range r.versions {
+		if v.ID == id {
+			cp := v
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakePromptRepo) CreateVersion(v *models.PromptSetVersion) error {
+	v.ID = uint(len(r.versions) + 1)
+	r.versions = append(r.versions, *v)
+	return nil
+}
+
+func (r *fakePromptRepo) GetConfig() (*models.PromptConfig, error) {
+	if r.cfg == nil {
+		return nil, nil
+	}
+	cp := *r.cfg
+	return &cp, nil
+}
+
+func (r *fakePromptRepo) UpsertConfig(cfg *models.PromptConfig) error {
+	cp := *cfg
+	r.cfg = &cp
+	return nil
+}
+
+func (r *fakePromptRepo) ListPins() ([]models.PromptPin, error) {
+	var out []models.PromptPin
+	for op, id := range r.pins {
+		out = append(out, models.PromptPin{Operation: op, VersionID: id})
+	}
+	return out, nil
+}
+
+func (r *fakePromptRepo) UpsertPin(pin *models.PromptPin) error {
+	if r.pins == nil {
+		r.pins = map[string]uint{}
+	}
+	r.pins[pin.Operation] = pin.VersionID
+	return nil
+}
+
+func (r *fakePromptRepo) DeletePin(operation string) error {
+	delete(r.pins, operation)
+	return nil
+}
+
+// newTestPromptRegistry returns a loaded registry over an in-memory repo,
+// plus the file prompts its store is attached to.
+func newTestPromptRegistry(t *testing.T) (*PromptRegistry, *fakePromptRepo, *config.Prompts) {
+	t.Helper()
+	seed := &config.Prompts{CookingQA: config.SinglePrompt{System: "Answer: {{.RecipeContext}}"}}
+	repo := &fakePromptRepo{}
+	reg := NewPromptRegistry(repo, config.NewPromptStore(seed), seed)
+	reg.Load()
+	return reg, repo, seed
+}
+
+func TestPromptRegistry_LoadSeedsFromFile(t *testing.T) {
+	reg, repo, seed := newTestPromptRegistry(t)
+
+	if len(repo.versions) != 1 || repo.cfg == nil || repo.cfg.ActiveVersionID != 1 {
+		t.Fatalf("seed not recorded: versions=%d cfg=%+v", len(repo.versions), repo.cfg)
+	}
+	if repo.versions[0].Hash != config.PromptVersion(seed) {
+		t.Errorf("seed hash = %q, want %q", repo.versions[0].Hash, config.PromptVersion(seed))
+	}
+	if got := seed.For("CookingQA").CookingQA.System; got != "Answer: {{.RecipeContext}}" {
+		t.Errorf("served %q after seeding", got)
+	}
+
+	// A second Load must not seed again.
+	reg.Load()
+	if len(repo.versions) != 1 {
+		t.Errorf("reseeded: %d versions", len(repo.versions))
+	}
+}
+
+func TestPromptRegistry_CreateActivateRollback(t *testing.T) {
+	reg, repo, seed := newTestPromptRegistry(t)
+
+	v2, err := reg.CreateVersion(PromptVersionInput{
+		Changes: map[string]string{"cooking_qa.system": "Be brief. {{.RecipeContext}}"},
+		Note:    "shorter answers",
+	})
+	if err != nil {
+		t.Fatalf("CreateVersion() error = %v", err)
+	}
+	if v2.ParentID == nil || *v2.ParentID != 1 {
+		t.Errorf("ParentID = %v, want 1", v2.ParentID)
+	}
+	if got := seed.For("CookingQA").CookingQA.System; got != "Answer: {{.RecipeContext}}" {
+		t.Errorf("creating a version must not activate it; served %q", got)
+	}
+
+	diff, err := reg.Diff(v2.ID, 0)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if len(diff) != 1 || diff[0].Path != "cooking_qa.system" || diff[0].After != "Be brief. {{.RecipeContext}}" {
+		t.Errorf("diff = %+v", diff)
+	}
+
+	if _, err := reg.Activate(v2.ID); err != nil {
+		t.Fatalf("Activate() error = %v", err)
+	}
+	if got := seed.For("CookingQA").CookingQA.System; got != "Be brief. {{.RecipeContext}}" {
+		t.Errorf("after activate served %q", got)
+	}
+
+	if _, err := reg.Activate(1); err != nil {
+		t.Fatalf("rollback error = %v", err)
+	}
+	if got := seed.For("CookingQA").CookingQA.System; got != "Answer: {{.RecipeContext}}" {
+		t.Errorf("after rollback served %q", got)
+	}
+	if repo.cfg.ActiveVersionID != 1 {
+		t.Errorf("active = %d, want 1", repo.cfg.ActiveVersionID)
+	}
+}
+
+func TestPromptRegistry_RejectsInvalidVersions(t *testing.T) {
+	reg, repo, _ := newTestPromptRegistry(t)
+
+	cases := []PromptVersionInput{
+		{},
+		{Content: "cooking_qa: {system: x}", Changes: map[string]string{"cooking_qa.system": "x"}},
+		{Changes: map[string]string{"cooking_qa.system": "{{.RecipeContext"}},
+		{Changes: map[string]string{"cooking_qa.system": ""}},
+		{Changes: map[string]string{"cooking_qa.nope": "x"}},
+		{Content: "cooking_qa: [not, a, map]"},
+	}
+	for i, in := range cases {
+		if _, err := reg.CreateVersion(in); !errors.Is(err, ErrInvalidPrompt) {
+			t.Errorf("case %d: err = %v, want ErrInvalidPrompt", i, err)
+		}
+	}
+	if len(repo.versions) != 1 {
+		t.Errorf("invalid versions were recorded: %d", len(repo.versions))
+	}
+
+	if _, err := reg.Activate(99); !errors.Is(err, ErrPromptVersionNotFound) {
+		t.Errorf("Activate(99) err = %v, want ErrPromptVersionNotFound", err)
+	}
+}
+
+func TestPromptRegistry_PinServesOneOperation(t *testing.T) {
+	reg, _, seed := newTestPromptRegistry(t)
+
+	v2, err := reg.CreateVersion(PromptVersionInput{Changes: map[string]string{"cooking_qa.system": "Candidate {{.RecipeContext}}"}})
+	if err != nil {
+		t.Fatalf("CreateVersion() error = %v", err)
+	}
+	if _, err := reg.SetPin("CookingQA", v2.ID); err != nil {
+		t.Fatalf("SetPin() error = %v", err)
+	}
+	if got := seed.For("CookingQA").CookingQA.System; got != "Candidate {{.RecipeContext}}" {
+		t.Errorf("pinned op served %q", got)
+	}
+	if got := seed.For("GenerateRecipe").CookingQA.System; got != "Answer: {{.RecipeContext}}" {
+		t.Errorf("unpinned op served %q", got)
+	}
+
+	if _, err := reg.SetPin("NotAnOperation", v2.ID); !errors.Is(err, ErrInvalidPrompt) {
+		t.Errorf("unknown op err = %v", err)
+	}
+	if _, err := reg.SetPin("CookingQA", 99); !errors.Is(err, ErrPromptVersionNotFound) {
+		t.Errorf("unknown version err = %v", err)
+	}
+
+	if err := reg.ClearPin("CookingQA"); err != nil {
+		t.Fatalf("ClearPin() error = %v", err)
+	}
+	if got := seed.For("CookingQA").CookingQA.System; got != "Answer: {{.RecipeContext}}" {
+		t.Errorf("after clearing pin served %q", got)
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/windoze95/saltybytes-api/internal/ai"
 	"github.com/windoze95/saltybytes-api/internal/config"
+	"github.com/windoze95/saltybytes-api/internal/imaging"
 	"github.com/windoze95/saltybytes-api/internal/logger"
 	"github.com/windoze95/saltybytes-api/internal/models"
 	"github.com/windoze95/saltybytes-api/internal/repository"
@@ -26,6 +28,9 @@ type RecipeService struct {
 	// create/update and semantic search over a user's recipes.
 	EmbedProvider ai.EmbeddingProvider
 	VectorRepo    repository.VectorRepo
+	// Optional: set to add cook stats to recipe lists and sort them by cook
+	// history.
+	CookLogs repository.CookLogRepo
 }
 
 // RecipeResponse is the response object for recipe-related operations.
@@ -35,6 +40,7 @@ type RecipeResponse struct {
 	Title           string             `json:"title"`
 	OwnerID         string             `json:"ownerId"`
 	ImageURL        string             `json:"imageUrl"`
+	ImageVariants   *ImageVariantsDTO  `json:"imageVariants,omitempty"`
 	Ingredients     models.Ingredients `json:"ingredients"`
 	Instructions    []string           `json:"instructions"`
 	Tags            []string           `json:"tags"`
@@ -50,16 +56,55 @@ type RecipeResponse struct {
 
 // RecipeListItem is a lightweight response object for recipe listing.
 type RecipeListItem struct {
-	ID              string   `json:"id"`
-	Title           string   `json:"title"`
-	OwnerID         string   `json:"ownerId"`
-	ImageURL        string   `json:"imageUrl"`
-	CookTimeMinutes int      `json:"cookTimeMinutes"`
-	Tags            []string `json:"tags"`
-	SourceURL       string   `json:"sourceUrl,omitempty"`
-	Status          string   `json:"status"`
-	CreatedAt       string   `json:"createdAt"`
-	UpdatedAt       string   `json:"updatedAt"`
+	ID              string            `json:"id"`
+	Title           string            `json:"title"`
+	OwnerID         string            `json:"ownerId"`
+	ImageURL        string            `json:"imageUrl"`
+	ImageVariants   *ImageVariantsDTO `json:"imageVariants,omitempty"`
+	CookTimeMinutes int               `json:"cookTimeMinutes"`
+	Tags            []string          `json:"tags"`
+	SourceURL       string            `json:"sourceUrl,omitempty"`
+	Status          string            `json:"status"`
+	CreatedAt       string            `json:"createdAt"`
+	UpdatedAt       string            `json:"updatedAt"`
+	CookStats       *CookStatsDTO     `json:"cookStats,omitempty"`
+}
+
+// CookStatsDTO summarizes how often and how well a recipe has been cooked.
+// Absent from lists for recipes never cooked.
+type CookStatsDTO struct {
+	TimesCooked   int64   `json:"timesCooked"`
+	AverageRating float64 `json:"averageRating,omitempty"`
+	LastCookedAt  string  `json:"lastCookedAt,omitempty"`
+}
+
+// ImageVariantsDTO is the resized renditions of a recipe's image: list views
+// load thumb, cards load card, the detail view loads full, each painting the
+// blurhash until it arrives. Absent until variants have been generated, in
+// which case clients fall back to imageUrl.
+type ImageVariantsDTO struct {
+	Blurhash string          `json:"blurhash"`
+	Thumb    ImageVariantDTO `json:"thumb"`
+	Card     ImageVariantDTO `json:"card"`
+	Full     ImageVariantDTO `json:"full"`
+}
+
+// ImageVariantDTO is one rendition, in both formats.
+type ImageVariantDTO struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	WebP   string `json:"webp"`
+	JPEG   string `json:"jpeg"`
+}
+
+func toImageVariantsDTO(v *models.ImageVariants) *ImageVariantsDTO {
+	if v == nil {
+		return nil
+	}
+	conv := func(iv models.ImageVariant) ImageVariantDTO {
+		return ImageVariantDTO{Width: iv.Width, Height: iv.Height, WebP: iv.WebPURL, JPEG: iv.JPEGURL}
+	}
+	return &ImageVariantsDTO{Blurhash: v.Blurhash, Thumb: conv(v.Thumb), Card: conv(v.Card), Full: conv(v.Full)}
 }
 
 // NewRecipeService is the constructor function for initializing a new RecipeService
@@ -117,6 +162,70 @@ func (s *RecipeService) GetUserRecipes(userID uint, page, pageSize int) ([]Recip
 	return s.ToRecipeListItems(recipes), total, nil
 }
 
+// RecipeSortRecent lists a user's recipes newest first; the other sorts are
+// the repository.CookSort values.
+const RecipeSortRecent = "recent"
+
+// ErrInvalidRecipeSort is returned for an unknown recipe list sort.
+var ErrInvalidRecipeSort = errors.New("sort must be one of recent, last_cooked, most_cooked, top_rated")
+
+// ListUserRecipes returns a paginated list of a user's recipes in the given
+// order (RecipeSortRecent when empty), with cook stats when cook logs are
+// available.
+func (s *RecipeService) ListUserRecipes(ctx context.Context, userID uint, sort string, page, pageSize int) ([]RecipeListItem, int64, error) {
+	var (
+		recipes []models.Recipe
+		total   int64
+		err     error
+	)
+	switch cookSort := repository.CookSort(sort); cookSort {
+	case "", RecipeSortRecent:
+		recipes, total, err = s.Repo.GetUserRecipes(userID, page, pageSize)
+	case repository.CookSortLastCooked, repository.CookSortMostCooked, repository.CookSortTopRated:
+		if s.CookLogs == nil {
+			return nil, 0, ErrInvalidRecipeSort
+		}
+		recipes, total, err = s.CookLogs.ListUserRecipesByCooking(ctx, userID, cookSort, page, pageSize)
+	default:
+		return nil, 0, ErrInvalidRecipeSort
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get user recipes: %w", err)
+	}
+
+	items := s.ToRecipeListItems(recipes)
+	s.addCookStats(ctx, recipes, items)
+	return items, total, nil
+}
+
+// addCookStats fills in the cook stats of list items built from recipes.
+// Stats are decoration: a failed lookup leaves them out.
+func (s *RecipeService) addCookStats(ctx context.Context, recipes []models.Recipe, items []RecipeListItem) {
+	if s.CookLogs == nil || len(recipes) == 0 {
+		return
+	}
+	ids := make([]uint, len(recipes))
+	for i := range recipes {
+		ids[i] = recipes[i].ID
+	}
+	stats, err := s.CookLogs.Stats(ctx, ids)
+	if err != nil {
+		logger.Get().Warn("failed to load cook stats for recipe list", zap.Error(err))
+		return
+	}
+	for i := range recipes {
+		st, ok := stats[recipes[i].ID]
+		if !ok {
+			continue
+		}
+		dto := &CookStatsDTO{TimesCooked: st.TimesCooked, AverageRating: st.AverageRating}
+		if st.LastCookedAt != nil {
+			dto.LastCookedAt = st.LastCookedAt.UTC().Format("2006-01-02T15:04:05Z")
+		}
+		items[i].CookStats = dto
+	}
+}
+
 // ToRecipeListItem converts a Recipe to the lightweight RecipeListItem DTO.
 func (s *RecipeService) ToRecipeListItem(r *models.Recipe) RecipeListItem {
 	effectiveDef := effectiveRecipeDef(r)
@@ -131,6 +240,7 @@ func (s *RecipeService) ToRecipeListItem(r *models.Recipe) RecipeListItem {
 		Title:           effectiveDef.Title,
 		OwnerID:         fmt.Sprintf("%d", r.CreatedByID),
 		ImageURL:        r.ImageURL,
+		ImageVariants:   toImageVariantsDTO(r.ImageVariants),
 		CookTimeMinutes: effectiveDef.CookTime,
 		Tags:            tags,
 		SourceURL:       effectiveDef.SourceURL,
@@ -220,7 +330,9 @@ func (s *RecipeService) SearchUserRecipes(ctx context.Context, userID uint, quer
 		end = len(merged)
 	}
 
-	return s.ToRecipeListItems(merged[start:end]), total, nil
+	items := s.ToRecipeListItems(merged[start:end])
+	s.addCookStats(ctx, merged[start:end], items)
+	return items, total, nil
 }
 
 // GetRecipeByID fetches a recipe by its ID.
@@ -252,8 +364,10 @@ func (s *RecipeService) DeleteRecipe(ctx context.Context, recipeID uint) error {
 	// cleaned up afterwards. Image keys are timestamp-versioned, so the key
 	// must be derived from the stored URL rather than regenerated.
 	var imageURL string
+	var variants *models.ImageVariants
 	if recipe, err := s.Repo.GetRecipeByID(recipeID); err == nil {
 		imageURL = recipe.ImageURL
+		variants = recipe.ImageVariants
 	}
 
 	// Delete the recipe (and its tree + nodes) from the database.
@@ -273,6 +387,7 @@ func (s *RecipeService) DeleteRecipe(ctx context.Context, recipeID uint) error {
 				zap.Error(err))
 		}
 	}
+	deleteRecipeImageVariants(ctx, s.Cfg, recipeID, variants)
 
 	return nil
 }
@@ -345,6 +460,103 @@ func uploadRecipeImage(ctx context.Context, recipeID uint, oldImageURL string, i
 	return imageURL, nil
 }
 
+// uploadRecipeImageVariants renders the resized WebP/JPEG variants of a
+// recipe image and uploads them under the recipe's own prefix.
+func uploadRecipeImageVariants(ctx context.Context, recipeID uint, imageBytes []byte, cfg *config.Config) (*models.ImageVariants, error) {
+	ts := time.Now().Unix()
+	return uploadImageVariants(ctx, cfg, imageBytes, func(variant, ext string) string {
+		return s3.GenerateVariantKey(recipeID, ts, variant, ext)
+	})
+}
+
+// uploadImageVariants renders the resized WebP/JPEG variants of an image and
+// uploads each under key(variant, ext). It is all or nothing: when an upload
+// fails, the objects already uploaded are deleted so none are left orphaned.
+func uploadImageVariants(ctx context.Context, cfg *config.Config, imageBytes []byte, key func(variant, ext string) string) (*models.ImageVariants, error) {
+	res, err := imaging.Process(imageBytes)
+	if err != nil {
+		return nil, err
+	}
+	var uploaded []string
+	upload := func(data []byte, variant, ext, contentType string) (string, error) {
+		k := key(variant, ext)
+		url, err := uploadImageToS3(ctx, cfg, data, k, contentType)
+		if err != nil {
+			deleteImageObjects(ctx, cfg, uploaded)
+			return "", fmt.Errorf("upload %s %s: %w", variant, ext, err)
+		}
+		uploaded = append(uploaded, k)
+		return url, nil
+	}
+	out := &models.ImageVariants{Blurhash: res.Blurhash}
+	for _, v := range res.Variants {
+		variant := models.ImageVariant{Width: v.Width, Height: v.Height}
+		if variant.WebPURL, err = upload(v.WebP, v.Name, "webp", "image/webp"); err != nil {
+			return nil, err
+		}
+		if variant.JPEGURL, err = upload(v.JPEG, v.Name, "jpg", "image/jpeg"); err != nil {
+			return nil, err
+		}
+		switch v.Name {
+		case imaging.Thumb:
+			out.Thumb = variant
+		case imaging.Card:
+			out.Card = variant
+		case imaging.Full:
+			out.Full = variant
+		}
+	}
+	return out, nil
+}
+
+// deleteImageObjects deletes S3 objects by key, best-effort.
+func deleteImageObjects(ctx context.Context, cfg *config.Config, keys []string) {
+	for _, key := range keys {
+		if err := deleteImageFromS3(ctx, cfg, key); err != nil {
+			logger.Get().Warn("failed to delete image object from S3", zap.String("s3_key", key), zap.Error(err))
+		}
+	}
+}
+
+// deleteRecipeImageVariants deletes the objects behind replaced variants,
+// best-effort and scoped to the recipe's own prefix like the image itself.
+func deleteRecipeImageVariants(ctx context.Context, cfg *config.Config, recipeID uint, variants *models.ImageVariants) {
+	if variants == nil {
+		return
+	}
+	for _, u := range variants.URLs() {
+		key := s3.RecipeImageKeyFromURL(u, recipeID)
+		if key == "" {
+			continue
+		}
+		if err := deleteImageFromS3(ctx, cfg, key); err != nil {
+			logger.Get().Warn("failed to delete recipe image variant from S3",
+				zap.Uint("recipe_id", recipeID),
+				zap.String("s3_key", key),
+				zap.Error(err))
+		}
+	}
+}
+
+// storeRecipeImageVariants replaces a recipe's image variants with ones
+// rendered from its new image and returns them. Best-effort: when rendering
+// or uploading fails the variants are cleared (nil), so clients fall back to
+// ImageURL rather than showing the previous image's renditions.
+func storeRecipeImageVariants(ctx context.Context, cfg *config.Config, repo repository.RecipeRepo, recipeID uint, old *models.ImageVariants, imageBytes []byte) *models.ImageVariants {
+	variants, err := uploadRecipeImageVariants(ctx, recipeID, imageBytes, cfg)
+	if err != nil {
+		logger.Get().Warn("failed to generate recipe image variants", zap.Uint("recipe_id", recipeID), zap.Error(err))
+		variants = nil
+	}
+	if err := repo.UpdateRecipeImageVariants(recipeID, variants); err != nil {
+		// Nothing references the new renditions; don't leave them behind.
+		deleteRecipeImageVariants(ctx, cfg, recipeID, variants)
+		return nil
+	}
+	deleteRecipeImageVariants(ctx, cfg, recipeID, old)
+	return variants
+}
+
 // AssociateTagsWithRecipe checks if each hashtag exists as a Tag in the database.
 // If it does, it uses the existing Tag's ID and Name.
 func (s *RecipeService) AssociateTagsWithRecipe(recipe *models.Recipe, tags []string) error {
@@ -409,6 +621,7 @@ func (s *RecipeService) ToRecipeResponse(r *models.Recipe) *RecipeResponse {
 		Title:           effectiveDef.Title,
 		OwnerID:         fmt.Sprintf("%d", r.CreatedByID),
 		ImageURL:        r.ImageURL,
+		ImageVariants:   toImageVariantsDTO(r.ImageVariants),
 		Ingredients:     effectiveDef.Ingredients,
 		Instructions:    effectiveDef.Instructions,
 		Tags:            tags,
@@ -13,8 +13,11 @@ import (
 	"go.uber.org/zap"
 )
 
-// InitGenerateRecipeWithFork initializes a new recipe with fork.
-func (s *RecipeService) InitGenerateRecipeWithFork(user *models.User, forkedRecipeID uint, userPrompt string, genImage bool) (*RecipeResponse, error) {
+// InitGenerateRecipeWithFork initializes a new recipe with fork. The fork
+// generates in the background under ctx's values (AI call attribution with
+// its user and request ID, locale) but not its cancellation, so its usage and
+// experiment rows join back to the request.
+func (s *RecipeService) InitGenerateRecipeWithFork(ctx context.Context, user *models.User, forkedRecipeID uint, userPrompt string, genImage bool) (*RecipeResponse, error) {
 	if user.Personalization.ID == 0 {
 		logger.Get().Warn("user personalization is nil", zap.Uint("user_id", user.ID))
 		return nil, errors.New("user's Personalization is nil")
@@ -41,17 +44,17 @@ func (s *RecipeService) InitGenerateRecipeWithFork(user *models.User, forkedReci
 
 	recipeResponse := s.ToRecipeResponse(recipe)
 
-	go s.FinishGenerateRecipeWithFork(recipe, forkedRecipe, user, userPrompt, genImage)
+	go s.FinishGenerateRecipeWithFork(context.WithoutCancel(ctx), recipe, forkedRecipe, user, userPrompt, genImage)
 
 	return recipeResponse, nil
 }
 
 // FinishGenerateRecipeWithFork finishes generating a recipe with fork.
 // sourceRecipe is the original recipe being forked (used for AI conversation context).
-func (s *RecipeService) FinishGenerateRecipeWithFork(recipe *models.Recipe, sourceRecipe *models.Recipe, user *models.User, userPrompt string, genImage bool) {
+func (s *RecipeService) FinishGenerateRecipeWithFork(ctx context.Context, recipe *models.Recipe, sourceRecipe *models.Recipe, user *models.User, userPrompt string, genImage bool) {
 	defer util.RecoverPanic("finish generate recipe with fork")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
 	// Both channels are buffered so the worker goroutines' single send never
@@ -127,6 +130,7 @@ func (s *RecipeService) FinishGenerateRecipeWithFork(recipe *models.Recipe, sour
 					imageErrChan <- dbErr
 					return
 				}
+				storeRecipeImageVariants(ctx, s.Cfg, s.Repo, recipe.ID, recipe.ImageVariants, imageBytes)
 			}
 
 			imageErrChan <- nil
@@ -15,8 +15,10 @@ import (
 	"go.uber.org/zap"
 )
 
-// InitRegenerateRecipe initializes recipe regeneration from conversation history.
-func (s *RecipeService) InitRegenerateRecipe(user *models.User, recipeID uint, userPrompt string, genImage bool) error {
+// InitRegenerateRecipe initializes recipe regeneration from conversation
+// history. The regeneration runs in the background under ctx's values (AI
+// call attribution, locale) but not its cancellation.
+func (s *RecipeService) InitRegenerateRecipe(ctx context.Context, user *models.User, recipeID uint, userPrompt string, genImage bool) error {
 	if user.Personalization.ID == 0 {
 		logger.Get().Warn("user personalization is nil", zap.Uint("user_id", user.ID))
 		return errors.New("user's Personalization is nil")
@@ -31,16 +33,16 @@ func (s *RecipeService) InitRegenerateRecipe(user *models.User, recipeID uint, u
 		return errors.New("unauthorized: you do not own this recipe")
 	}
 
-	go s.FinishRegenerateRecipe(recipe, user, userPrompt, genImage)
+	go s.FinishRegenerateRecipe(context.WithoutCancel(ctx), recipe, user, userPrompt, genImage)
 
 	return nil
 }
 
 // FinishRegenerateRecipe finishes regenerating a recipe.
-func (s *RecipeService) FinishRegenerateRecipe(recipe *models.Recipe, user *models.User, userPrompt string, genImage bool) {
+func (s *RecipeService) FinishRegenerateRecipe(ctx context.Context, recipe *models.Recipe, user *models.User, userPrompt string, genImage bool) {
 	defer util.RecoverPanic("finish regenerate recipe")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
 	// Materialize from canonical before mutation (copy-on-write)
@@ -118,6 +120,7 @@ func (s *RecipeService) FinishRegenerateRecipe(recipe *models.Recipe, user *mode
 					imageErrChan <- dbErr
 					return
 				}
+				storeRecipeImageVariants(ctx, s.Cfg, s.Repo, recipe.ID, recipe.ImageVariants, imageBytes)
 			}
 
 			imageErrChan <- nil
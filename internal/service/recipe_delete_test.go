@@ -1,8 +1,11 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"image"
+	"image/png"
 	"regexp"
 	"testing"
 
@@ -219,3 +222,115 @@ func TestUploadRecipeImage_NoOldImageSkipsDelete(t *testing.T) {
 		t.Errorf("no old image: delete should not be called, got keys %v", *deletedKeys)
 	}
 }
+
+func TestStoreRecipeImageVariants_UploadsEveryRenditionAndReplacesOld(t *testing.T) {
+	var keys []string
+	orig := uploadImageToS3
+	uploadImageToS3 = func(_ context.Context, _ *config.Config, _ []byte, s3Key string, _ string) (string, error) {
+		keys = append(keys, s3Key)
+		return "https://bucket.s3.us-east-2.amazonaws.com/" + s3Key, nil
+	}
+	t.Cleanup(func() { uploadImageToS3 = orig })
+	deletedKeys := stubS3Delete(t, nil)
+
+	repo := testutil.NewMockRecipeRepo()
+	recipe := testutil.TestRecipe()
+	repo.Recipes[recipe.ID] = recipe
+	old := &models.ImageVariants{Thumb: models.ImageVariant{
+		WebPURL: "https://bucket.s3.us-east-2.amazonaws.com/recipes/1/images/recipe_image_1_1600000000_thumb.webp",
+		JPEGURL: "https://example.com/not-ours.jpg",
+	}}
+
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 64, 48)))
+	got := storeRecipeImageVariants(context.Background(), &config.Config{}, repo, recipe.ID, old, buf.Bytes())
+	if got == nil {
+		t.Fatal("want variants")
+	}
+	if len(keys) != 6 {
+		t.Errorf("uploaded %d objects, want 3 variants x 2 formats", len(keys))
+	}
+	if got.Blurhash == "" || got.Thumb.Width != 64 || got.Full.WebPURL == "" || got.Card.JPEGURL == "" {
+		t.Errorf("variants = %+v", got)
+	}
+	if repo.Recipes[recipe.ID].ImageVariants != got {
+		t.Error("variants were not stored on the recipe")
+	}
+	if len(*deletedKeys) != 1 || (*deletedKeys)[0] != "recipes/1/images/recipe_image_1_1600000000_thumb.webp" {
+		t.Errorf("deleted %v, want only the old variant under the recipe's prefix", *deletedKeys)
+	}
+	if dto := toImageVariantsDTO(got); dto.Thumb.WebP != got.Thumb.WebPURL {
+		t.Errorf("DTO thumb = %+v", dto.Thumb)
+	}
+}
+
+func TestStoreRecipeImageVariants_UndecodableClearsVariants(t *testing.T) {
+	var gotKey, gotContentType string
+	stubS3Upload(t, &gotKey, &gotContentType, "https://bucket/x", nil)
+	stubS3Delete(t, nil)
+
+	repo := testutil.NewMockRecipeRepo()
+	recipe := testutil.TestRecipe()
+	recipe.ImageVariants = &models.ImageVariants{Blurhash: "stale"}
+	repo.Recipes[recipe.ID] = recipe
+
+	if got := storeRecipeImageVariants(context.Background(), &config.Config{}, repo, recipe.ID, recipe.ImageVariants, []byte("png-bytes")); got != nil {
+		t.Errorf("variants = %+v, want nil", got)
+	}
+	if repo.Recipes[recipe.ID].ImageVariants != nil {
+		t.Error("stale variants must be cleared")
+	}
+	if gotKey != "" {
+		t.Error("nothing should be uploaded for an undecodable image")
+	}
+}
+
+func TestStoreRecipeImageVariants_FailedUploadDeletesEarlierObjects(t *testing.T) {
+	var keys []string
+	orig := uploadImageToS3
+	uploadImageToS3 = func(_ context.Context, _ *config.Config, _ []byte, s3Key string, _ string) (string, error) {
+		if len(keys) == 3 {
+			return "", errors.New("s3 unavailable")
+		}
+		keys = append(keys, s3Key)
+		return "https://bucket.s3.us-east-2.amazonaws.com/" + s3Key, nil
+	}
+	t.Cleanup(func() { uploadImageToS3 = orig })
+	deletedKeys := stubS3Delete(t, nil)
+
+	repo := testutil.NewMockRecipeRepo()
+	recipe := testutil.TestRecipe()
+	repo.Recipes[recipe.ID] = recipe
+
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 64, 48)))
+	if got := storeRecipeImageVariants(context.Background(), &config.Config{}, repo, recipe.ID, nil, buf.Bytes()); got != nil {
+		t.Errorf("variants = %+v, want nil after a failed upload", got)
+	}
+	if len(*deletedKeys) != 3 || (*deletedKeys)[0] != keys[0] || (*deletedKeys)[2] != keys[2] {
+		t.Errorf("deleted %v, want the 3 objects uploaded before the failure %v", *deletedKeys, keys)
+	}
+}
+
+func TestStoreRecipeImageVariants_FailedSaveDeletesNewObjects(t *testing.T) {
+	var keys []string
+	orig := uploadImageToS3
+	uploadImageToS3 = func(_ context.Context, _ *config.Config, _ []byte, s3Key string, _ string) (string, error) {
+		keys = append(keys, s3Key)
+		return "https://bucket.s3.us-east-2.amazonaws.com/" + s3Key, nil
+	}
+	t.Cleanup(func() { uploadImageToS3 = orig })
+	deletedKeys := stubS3Delete(t, nil)
+
+	repo := testutil.NewMockRecipeRepo()
+	repo.UpdateRecipeImageVariantsErr = errors.New("db down")
+	recipe := testutil.TestRecipe()
+	repo.Recipes[recipe.ID] = recipe
+
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 64, 48)))
+	storeRecipeImageVariants(context.Background(), &config.Config{}, repo, recipe.ID, nil, buf.Bytes())
+	if len(*deletedKeys) != len(keys) || len(keys) != 6 {
+		t.Errorf("deleted %v, want all %d new objects", *deletedKeys, len(keys))
+	}
+}
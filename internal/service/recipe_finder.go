@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math/rand/v2"
 	"net/url"
 	"sort"
 	"strings"
@@ -107,6 +109,9 @@ type FinderEvent struct {
 	// CollectionTitle is the title of the collection/listicle being expanded,
 	// carried on the digging and expanded events.
 	CollectionTitle string `json:"collection_title,omitempty"`
+	// SessionID is the saved finder session the run belongs to, carried on the
+	// done event so the client can continue it with follow-up turns.
+	SessionID uint `json:"session_id,omitempty"`
 }
 
 // FinderFacets are the tappable facet-chip selections that steer a find. They
@@ -158,6 +163,12 @@ type RecipeFinderService struct {
 	// Runs (nil-safe) persists per-run workflow telemetry (step funnel,
 	// latencies, failure modes) for the dashboard. When nil, nothing is saved.
 	Runs repository.FinderRunRepo
+	// RankCaptureRate is the fraction of runs (0–1) whose exact ranker
+	// request/result is captured on the run row for offline evaluation.
+	RankCaptureRate float64
+
+	// captureRoll is overridable in tests.
+	captureRoll func() float64
 }
 
 // NewRecipeFinderService wires the finder over the existing search, family and
@@ -176,11 +187,43 @@ func NewRecipeFinderService(cfg *config.Config, search *SearchService, familyRep
 // goes and returning when the run reaches a terminal event (done/empty/error) or
 // the context is cancelled. The caller owns the channel and closes it.
 func (s *RecipeFinderService) FindRecipes(ctx context.Context, user *models.User, req FinderRequest, events chan<- FinderEvent) {
+	s.find(ctx, user, req, nil, events, func(out finderOutcome) uint {
+		// Auto-save the completed first-page run for history (ungated, best-effort).
+		return s.autoSaveSession(user, req, out.query, out.found, out.folded, out.assembled)
+	})
+}
+
+// finderTurnState carries a session turn's history into the trajectory. A nil
+// *finderTurnState is a plain one-shot find.
+type finderTurnState struct {
+	// dismissed holds the normalized URLs the user rejected earlier in the
+	// session; they are dropped before ranking and never shown again.
+	dismissed map[string]bool
+	// excludes are the accumulated "no X" terms, appended to the query as
+	// negations alongside the allergen excludes.
+	excludes []string
+	// conversation is the compacted summary handed to the ranker.
+	conversation string
+}
+
+// finderOutcome is what a completed (done) run surfaced, handed to the
+// caller's save step.
+type finderOutcome struct {
+	query     string
+	found     int
+	folded    int
+	assembled []FinderResultItem
+}
+
+// find is the trajectory shared by one-shot finds and session turns. save runs
+// once the run completes (before the done event, so the event can carry the
+// session ID it returns; 0 = not saved).
+func (s *RecipeFinderService) find(ctx context.Context, user *models.User, req FinderRequest, turn *finderTurnState, events chan<- FinderEvent, save func(finderOutcome) uint) {
 	// Workflow telemetry: one row per run, whatever way it ends. Terminal
 	// stays "cancelled" unless a real terminal event overwrites it, so client
 	// disconnects are visible too.
 	runStart := time.Now()
-	run := &models.FinderRun{Terminal: "cancelled"}
+	run := &models.FinderRun{Terminal: "cancelled", RequestID: ai.AttributionFromContext(ctx).RequestID}
 	if user != nil {
 		run.UserID = user.ID
 	}
@@ -193,9 +236,13 @@ func (s *RecipeFinderService) FindRecipes(ctx context.Context, user *models.User
 	// allergies become hard query-excludes; restrictions/preferences steer the
 	// model via the diet summary.
 	dietSummary, allergenExcludes := s.dietContext(user)
+	excludes := allergenExcludes
+	if turn != nil {
+		excludes = append(append([]string(nil), allergenExcludes...), turn.excludes...)
+	}
 
 	// 1. Compose the search query deterministically — no model call.
-	query := composeFinderQuery(req, allergenExcludes)
+	query := composeFinderQuery(req, excludes)
 	run.Query = query
 	if !s.emit(ctx, events, FinderEvent{Type: FinderEventSearching, Query: query}) {
 		return
@@ -219,6 +266,9 @@ func (s *RecipeFinderService) FindRecipes(ctx context.Context, user *models.User
 		return
 	}
 	results := searchRes.Results
+	if turn != nil {
+		results = dropDismissed(results, turn.dismissed)
+	}
 	run.ResultsFound = len(results)
 	run.FromCache = searchRes.FromCache
 	if !s.emit(ctx, events, FinderEvent{Type: FinderEventFound, Count: len(results), FromCache: searchRes.FromCache}) {
@@ -238,10 +288,15 @@ func (s *RecipeFinderService) FindRecipes(ctx context.Context, user *models.User
 		return
 	}
 	rankStart := time.Now()
-	rank, rankErr := s.rankCandidates(ctx, user, req, dietSummary, results)
+	conversation := ""
+	if turn != nil {
+		conversation = turn.conversation
+	}
+	rank, rankErr, capture := s.rankCandidates(ctx, user, req, dietSummary, conversation, results)
 	run.RankMS = time.Since(rankStart).Milliseconds()
 	run.RankOK = rankErr == ""
 	run.RankError = rankErr
+	run.RankCapture = capture
 
 	// 4.5. Ground-truth override: force-flag candidates the canonical cache
 	// already KNOWS are multi-recipe collection pages. The model's expand flag
@@ -282,7 +337,11 @@ func (s *RecipeFinderService) FindRecipes(ctx context.Context, user *models.User
 		room = finderDigMaxCards
 	}
 	digStart := time.Now()
-	folded, dug := s.digCollections(ctx, events, results, rank, room, &shortlistEmitted, searchRes.HasMore)
+	var dismissed map[string]bool
+	if turn != nil {
+		dismissed = turn.dismissed
+	}
+	folded, dug := s.digCollections(ctx, events, results, rank, room, &shortlistEmitted, searchRes.HasMore, dismissed)
 	run.DigMS = time.Since(digStart).Milliseconds()
 	run.CollectionsDug = dug
 	run.CardsMined = len(folded)
@@ -307,19 +366,22 @@ func (s *RecipeFinderService) FindRecipes(ctx context.Context, user *models.User
 		}
 	}
 
-	// 9. Offer bounded refinement, then finish.
+	// 9. Offer bounded refinement.
 	if !s.emit(ctx, events, FinderEvent{Type: FinderEventRefineReady, Chips: finderRefineChips, Broaden: broadenList(rank, req)}) {
 		return
 	}
 	run.Terminal = "done"
-	s.emit(ctx, events, FinderEvent{Type: FinderEventDone})
 
-	// 10. Auto-save the completed first-page run for history (ungated, best-effort).
+	// 10. Save the run (history auto-save, or the session turn), then finish.
 	// assembled = the curated direct picks plus everything mined from collections.
 	assembled := make([]FinderResultItem, 0, len(shown)+len(folded))
 	assembled = append(assembled, shown...)
 	assembled = append(assembled, folded...)
-	s.autoSaveSession(user, req, query, len(results), len(folded), assembled)
+	var sessionID uint
+	if save != nil {
+		sessionID = save(finderOutcome{query: query, found: len(results), folded: len(folded), assembled: assembled})
+	}
+	s.emit(ctx, events, FinderEvent{Type: FinderEventDone, SessionID: sessionID})
 }
 
 // emit sends one event, returning false if the context is cancelled (e.g. the
@@ -368,8 +430,9 @@ func countExpandFlags(rank *ai.FinderRankResult) int {
 // real extraction. Folded recipes append via expanded events; when no direct pick
 // was shown (shortlistEmitted false), the first mined batch seeds the shortlist
 // instead so build-89 gets a proper base list. Returns every folded recipe plus
-// how many collections were actually dug (for run telemetry).
-func (s *RecipeFinderService) digCollections(ctx context.Context, events chan<- FinderEvent, results []ai.SearchResult, rank *ai.FinderRankResult, room int, shortlistEmitted *bool, hasMore bool) ([]FinderResultItem, int) {
+// how many collections were actually dug (for run telemetry). Recipes the user
+// dismissed earlier in a session are never folded back in.
+func (s *RecipeFinderService) digCollections(ctx context.Context, events chan<- FinderEvent, results []ai.SearchResult, rank *ai.FinderRankResult, room int, shortlistEmitted *bool, hasMore bool, dismissed map[string]bool) ([]FinderResultItem, int) {
 	if s.MultiResolver == nil || rank == nil || room <= 0 {
 		return nil, 0
 	}
@@ -433,7 +496,7 @@ func (s *RecipeFinderService) digCollections(ctx context.Context, events chan<-
 				continue
 			}
 			cached := strings.TrimSpace(card.CachedURL)
-			if cached == "" {
+			if cached == "" || isDismissed(cached, dismissed) {
 				continue
 			}
 			batch = append(batch, FinderResultItem{
@@ -490,26 +553,17 @@ func (s *RecipeFinderService) waitForCollection(ctx context.Context, entry *Mult
 	}
 }
 
-// autoSaveSession persists a completed first-page finder run for history. It is
-// ungated and best-effort: only first-page runs with at least one result are
-// saved, on a context detached from the request (so a client disconnect after
-// "done" doesn't cancel the write), and any error is logged, never surfaced.
-func (s *RecipeFinderService) autoSaveSession(user *models.User, req FinderRequest, query string, foundCount, foldedCount int, assembled []FinderResultItem) {
+// autoSaveSession persists a completed first-page finder run for history and
+// returns the new session's ID (0 when nothing was saved). It is ungated and
+// best-effort: only first-page runs with at least one result are saved, on a
+// context detached from the request (so a client disconnect doesn't cancel the
+// write), and any error is logged, never surfaced.
+func (s *RecipeFinderService) autoSaveSession(user *models.User, req FinderRequest, query string, foundCount, foldedCount int, assembled []FinderResultItem) uint {
 	if s.Sessions == nil || user == nil || req.Offset != 0 || len(assembled) == 0 {
-		return
+		return 0
 	}
 
-	results := make(models.SearchResultList, 0, len(assembled))
-	for _, it := range assembled {
-		results = append(results, models.SearchResultItem{
-			Title:       it.Result.Title,
-			URL:         it.Result.URL,
-			Source:      it.Result.Source,
-			Rating:      it.Result.Rating,
-			ImageURL:    it.Result.ImageURL,
-			Description: it.Result.Description,
-		})
-	}
+	results := sessionResults(assembled)
 
 	narration := models.StringList{
 		fmt.Sprintf("Searched for %q", query),
@@ -532,7 +586,25 @@ func (s *RecipeFinderService) autoSaveSession(user *models.User, req FinderReque
 	defer cancel()
 	if err := s.Sessions.Save(ctx, session); err != nil {
 		logger.Get().Warn("failed to auto-save finder session", zap.Uint("user_id", user.ID), zap.Error(err))
+		return 0
+	}
+	return session.ID
+}
+
+// sessionResults maps shown finder items to their persisted shape.
+func sessionResults(items []FinderResultItem) models.SearchResultList {
+	results := make(models.SearchResultList, 0, len(items))
+	for _, it := range items {
+		results = append(results, models.SearchResultItem{
+			Title:       it.Result.Title,
+			URL:         it.Result.URL,
+			Source:      it.Result.Source,
+			Rating:      it.Result.Rating,
+			ImageURL:    it.Result.ImageURL,
+			Description: it.Result.Description,
+		})
 	}
+	return results
 }
 
 // finderIntent maps a finder request to its persisted intent shape.
@@ -585,8 +657,9 @@ func hostOf(rawURL string) string {
 // unranked-but-real shortlist, never a fabricated one.
 // rankCandidates runs the single ranking call. The second return is the rank
 // failure text ("" on success) — a failure degrades to fallbackRanking, and
-// the caller records it on the run telemetry.
-func (s *RecipeFinderService) rankCandidates(ctx context.Context, user *models.User, req FinderRequest, dietSummary string, results []ai.SearchResult) (*ai.FinderRankResult, string) {
+// the caller records it on the run telemetry. The third is the ranker capture
+// for offline evaluation, nil unless this run was sampled.
+func (s *RecipeFinderService) rankCandidates(ctx context.Context, user *models.User, req FinderRequest, dietSummary, conversation string, results []ai.SearchResult) (*ai.FinderRankResult, string, *models.FinderRankCapture) {
 	candidates := make([]ai.FinderCandidate, len(results))
 	for i, r := range results {
 		candidates[i] = ai.FinderCandidate{
@@ -599,10 +672,11 @@ func (s *RecipeFinderService) rankCandidates(ctx context.Context, user *models.U
 	}
 
 	rankReq := ai.FinderRankRequest{
-		Facets:      facetsSummary(req),
-		FreeText:    strings.TrimSpace(req.FreeText),
-		DietSummary: dietSummary,
-		Candidates:  candidates,
+		Facets:       facetsSummary(req),
+		FreeText:     strings.TrimSpace(req.FreeText),
+		DietSummary:  dietSummary,
+		Conversation: conversation,
+		Candidates:   candidates,
 	}
 	if user != nil && user.Personalization != nil {
 		rankReq.UnitSystem = user.Personalization.UnitSystemText()
@@ -610,12 +684,63 @@ func (s *RecipeFinderService) rankCandidates(ctx context.Context, user *models.U
 		rankReq.Requirements = user.Personalization.Requirements
 	}
 
+	var calls *ai.CallCapture
+	sampled := s.sampleRankCapture()
+	if sampled {
+		ctx, calls = ai.WithCallCapture(ctx)
+	}
+
 	res, err := s.RankProvider.ExpandAndRankRecipes(ctx, rankReq)
+
+	var capture *models.FinderRankCapture
+	if sampled {
+		capture = buildRankCapture(rankReq, res, err, calls)
+	}
 	if err != nil {
 		logger.Get().Warn("recipe finder ranking failed; showing unranked real results", zap.Error(err))
-		return fallbackRanking(len(results)), truncateErr(err)
+		return fallbackRanking(len(results)), truncateErr(err), capture
 	}
-	return res, ""
+	return res, "", capture
+}
+
+// sampleRankCapture decides whether this run's ranker call is captured.
+func (s *RecipeFinderService) sampleRankCapture() bool {
+	if s.RankCaptureRate <= 0 {
+		return false
+	}
+	if s.RankCaptureRate >= 1 {
+		return true
+	}
+	roll := rand.Float64
+	if s.captureRoll != nil {
+		roll = s.captureRoll
+	}
+	return roll() < s.RankCaptureRate
+}
+
+// buildRankCapture packages one ranker call for the run row. Best-effort: a
+// request that can't be marshalled yields no capture rather than a failed run.
+func buildRankCapture(req ai.FinderRankRequest, res *ai.FinderRankResult, callErr error, calls *ai.CallCapture) *models.FinderRankCapture {
+	reqJSON, err := json.Marshal(req)
+	if err != nil {
+		logger.Get().Warn("failed to capture finder rank request", zap.Error(err))
+		return nil
+	}
+	capture := &models.FinderRankCapture{Request: reqJSON}
+	if callErr == nil && res != nil {
+		if resJSON, err := json.Marshal(res); err == nil {
+			capture.Result = resJSON
+		}
+	}
+	if last, ok := calls.Last(); ok {
+		capture.Provider = last.Operation.Provider
+		capture.Model = last.Operation.Model
+		capture.InputTokens = last.Usage.InputTokens
+		capture.OutputTokens = last.Usage.OutputTokens
+		capture.CacheInputTokens = last.Usage.CacheInputTokens
+		capture.DurationMS = last.Duration.Milliseconds()
+	}
+	return capture
 }
 
 // applyKnownCollections force-flags ranked candidates whose URL the canonical
@@ -654,7 +779,12 @@ func (s *RecipeFinderService) dietContext(user *models.User) (summary string, al
 	if err != nil || family == nil {
 		return "", nil
 	}
+	return familyDietSummary(family)
+}
 
+// familyDietSummary compacts a family's dietary profiles into "Name: needs"
+// entries and the distinct allergens named in them.
+func familyDietSummary(family *models.Family) (summary string, allergens []string) {
 	var parts []string
 	seen := make(map[string]bool)
 	for _, member := range family.Members {
@@ -671,7 +801,7 @@ func (s *RecipeFinderService) dietContext(user *models.User) (summary string, al
 			needs = append(needs, "allergic to "+name)
 			if key := strings.ToLower(name); !seen[key] {
 				seen[key] = true
-				allergenExcludes = append(allergenExcludes, name)
+				allergens = append(allergens, name)
 			}
 		}
 		for _, in := range dp.Intolerances {
@@ -697,7 +827,7 @@ func (s *RecipeFinderService) dietContext(user *models.User) (summary string, al
 			parts = append(parts, fmt.Sprintf("%s: %s", name, strings.Join(needs, ", ")))
 		}
 	}
-	return strings.Join(parts, "; "), allergenExcludes
+	return strings.Join(parts, "; "), allergens
 }
 
 // composeFinderQuery builds the search query string deterministically from the
@@ -730,13 +860,27 @@ func composeFinderQuery(req FinderRequest, allergenExcludes []string) string {
 	query := core + " recipe"
 
 	for _, ex := range allergenExcludes {
-		if ex = strings.TrimSpace(ex); ex != "" {
-			query += " -" + ex
+		if neg := queryNegation(ex); neg != "" {
+			query += " " + neg
 		}
 	}
 	return query
 }
 
+// queryNegation renders an exclude as a search negation. A multi-word term is
+// quoted so the whole phrase is excluded ("-\"tree nuts\"") rather than just
+// its first word with the rest left as a positive term.
+func queryNegation(term string) string {
+	term = strings.Join(strings.Fields(strings.ReplaceAll(term, `"`, "")), " ")
+	if term == "" {
+		return ""
+	}
+	if strings.Contains(term, " ") {
+		return `-"` + term + `"`
+	}
+	return "-" + term
+}
+
 // facetsSummary renders the effective facets as a compact, human-readable line
 // for the model.
 func facetsSummary(req FinderRequest) string {
@@ -74,7 +74,7 @@ func TestInitGenerateRecipeWithFork_NilPersonalization(t *testing.T) {
 	user.Personalization = &models.Personalization{}
 
 	svc := newGenRecipeService(repo, &testutil.MockTextProvider{}, &testutil.MockImageProvider{}, nil, nil)
-	if _, err := svc.InitGenerateRecipeWithFork(user, 1, "make it vegan", false); err == nil {
+	if _, err := svc.InitGenerateRecipeWithFork(context.Background(), user, 1, "make it vegan", false); err == nil {
 		t.Fatal("InitGenerateRecipeWithFork() error = nil, want error for missing personalization")
 	}
 }
@@ -83,7 +83,7 @@ func TestInitGenerateRecipeWithFork_SourceNotFound(t *testing.T) {
 	repo := testutil.NewMockRecipeRepo()
 
 	svc := newGenRecipeService(repo, &testutil.MockTextProvider{}, &testutil.MockImageProvider{}, nil, nil)
-	if _, err := svc.InitGenerateRecipeWithFork(forkUser(), 999, "make it vegan", false); err == nil {
+	if _, err := svc.InitGenerateRecipeWithFork(context.Background(), forkUser(), 999, "make it vegan", false); err == nil {
 		t.Fatal("InitGenerateRecipeWithFork() error = nil, want error for missing source recipe")
 	}
 }
@@ -105,7 +105,7 @@ func TestInitGenerateRecipeWithFork_CreatesGeneratingPlaceholder(t *testing.T) {
 	}
 
 	svc := newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, nil, nil)
-	resp, err := svc.InitGenerateRecipeWithFork(user, source.ID, "make it vegan", false)
+	resp, err := svc.InitGenerateRecipeWithFork(context.Background(), user, source.ID, "make it vegan", false)
 	if err != nil {
 		t.Fatalf("InitGenerateRecipeWithFork() error = %v", err)
 	}
@@ -131,6 +131,37 @@ func TestInitGenerateRecipeWithFork_CreatesGeneratingPlaceholder(t *testing.T) {
 	}
 }
 
+func TestInitGenerateRecipeWithFork_AttributesAICallsToUser(t *testing.T) {
+	repo := testutil.NewMockRecipeRepo()
+	source := seedForkSource(t, repo)
+	user := forkUser()
+
+	attributions := make(chan ai.CallAttribution, 1)
+	provider := &testutil.MockTextProvider{
+		ForkRecipeFunc: func(ctx context.Context, req ai.ForkRequest) (*ai.RecipeResult, error) {
+			attributions <- ai.AttributionFromContext(ctx)
+			return nil, errors.New("stopped by test")
+		},
+	}
+	svc := newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, nil, nil)
+
+	attribution := ai.CallAttribution{UserID: user.ID, RequestID: "req-fork", Endpoint: "/v1/recipes/:recipe_id/fork"}
+	reqCtx, cancel := context.WithCancel(ai.WithAttribution(context.Background(), attribution))
+	if _, err := svc.InitGenerateRecipeWithFork(reqCtx, user, source.ID, "make it vegan", false); err != nil {
+		t.Fatalf("InitGenerateRecipeWithFork() error = %v", err)
+	}
+	cancel()
+
+	select {
+	case got := <-attributions:
+		if got != attribution {
+			t.Errorf("attribution = %+v, want the forking request's %+v", got, attribution)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("FinishGenerateRecipeWithFork goroutine never called the text provider")
+	}
+}
+
 // --- FinishGenerateRecipeWithFork ---
 
 func TestFinishGenerateRecipeWithFork_HappyPath(t *testing.T) {
@@ -160,7 +191,7 @@ func TestFinishGenerateRecipeWithFork_HappyPath(t *testing.T) {
 	}
 
 	svc := newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, embed, vector)
-	svc.FinishGenerateRecipeWithFork(recipe, source, user, "make it vegan", false)
+	svc.FinishGenerateRecipeWithFork(context.Background(), recipe, source, user, "make it vegan", false)
 
 	// Conversation context comes from the source recipe's tree.
 	wantHistory := []ai.Message{
@@ -264,7 +295,7 @@ func TestFinishGenerateRecipeWithFork_CanonicalSourceReadOnly(t *testing.T) {
 	}
 
 	svc := newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, nil, nil)
-	svc.FinishGenerateRecipeWithFork(recipe, source, user, "make it vegan", false)
+	svc.FinishGenerateRecipeWithFork(context.Background(), recipe, source, user, "make it vegan", false)
 
 	// The fork context resolved the canonical's def without materializing it.
 	canonicalJSON, _ := json.Marshal(effectiveRecipeDef(source))
@@ -292,7 +323,7 @@ func TestFinishGenerateRecipeWithFork_AIFailureDeletesForkOnly(t *testing.T) {
 	}
 
 	svc := newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, nil, nil)
-	svc.FinishGenerateRecipeWithFork(recipe, source, user, "make it vegan", false)
+	svc.FinishGenerateRecipeWithFork(context.Background(), recipe, source, user, "make it vegan", false)
 
 	if repo.RecipeSnapshot(recipe.ID) != nil {
 		t.Error("forked placeholder should be deleted after AI failure")
@@ -327,7 +358,7 @@ func TestFinishGenerateRecipeWithFork_SourceWithoutTreeNoHistory(t *testing.T) {
 	}
 
 	svc := newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, nil, nil)
-	svc.FinishGenerateRecipeWithFork(recipe, source, user, "make it vegan", false)
+	svc.FinishGenerateRecipeWithFork(context.Background(), recipe, source, user, "make it vegan", false)
 
 	if gotHistory != nil {
 		t.Errorf("ExistingHistory = %+v, want nil when the source has no tree", gotHistory)
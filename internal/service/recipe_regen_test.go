@@ -56,7 +56,7 @@ func TestInitRegenerateRecipe_NilPersonalization(t *testing.T) {
 	user.Personalization = &models.Personalization{}
 
 	svc := newGenRecipeService(repo, &testutil.MockTextProvider{}, &testutil.MockImageProvider{}, nil, nil)
-	if err := svc.InitRegenerateRecipe(user, 1, "fluffier", false); err == nil {
+	if err := svc.InitRegenerateRecipe(context.Background(), user, 1, "fluffier", false); err == nil {
 		t.Fatal("InitRegenerateRecipe() error = nil, want error for missing personalization")
 	}
 }
@@ -65,7 +65,7 @@ func TestInitRegenerateRecipe_RecipeNotFound(t *testing.T) {
 	repo := testutil.NewMockRecipeRepo()
 
 	svc := newGenRecipeService(repo, &testutil.MockTextProvider{}, &testutil.MockImageProvider{}, nil, nil)
-	if err := svc.InitRegenerateRecipe(testutil.TestUser(), 999, "fluffier", false); err == nil {
+	if err := svc.InitRegenerateRecipe(context.Background(), testutil.TestUser(), 999, "fluffier", false); err == nil {
 		t.Fatal("InitRegenerateRecipe() error = nil, want error for missing recipe")
 	}
 }
@@ -76,7 +76,7 @@ func TestInitRegenerateRecipe_NotOwner(t *testing.T) {
 	seedOwnedRecipeWithTree(t, repo, 99)
 
 	svc := newGenRecipeService(repo, &testutil.MockTextProvider{}, &testutil.MockImageProvider{}, nil, nil)
-	err := svc.InitRegenerateRecipe(user, 1, "fluffier", false)
+	err := svc.InitRegenerateRecipe(context.Background(), user, 1, "fluffier", false)
 	if err == nil {
 		t.Fatal("InitRegenerateRecipe() error = nil, want unauthorized error")
 	}
@@ -102,7 +102,7 @@ func TestInitRegenerateRecipe_HappyPathStartsRegen(t *testing.T) {
 	}
 
 	svc := newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, nil, nil)
-	if err := svc.InitRegenerateRecipe(user, 1, "fluffier", false); err != nil {
+	if err := svc.InitRegenerateRecipe(context.Background(), user, 1, "fluffier", false); err != nil {
 		t.Fatalf("InitRegenerateRecipe() error = %v", err)
 	}
 
@@ -113,6 +113,83 @@ func TestInitRegenerateRecipe_HappyPathStartsRegen(t *testing.T) {
 	}
 }
 
+func TestInitRegenerateRecipe_AttributesAICallsToUser(t *testing.T) {
+	repo := testutil.NewMockRecipeRepo()
+	user := testutil.TestUser()
+	seedOwnedRecipeWithTree(t, repo, user.ID)
+
+	type call struct {
+		attribution ai.CallAttribution
+		err         error
+	}
+	calls := make(chan call, 1)
+	provider := &testutil.MockTextProvider{
+		RegenerateRecipeFunc: func(ctx context.Context, req ai.RegenerateRequest) (*ai.RecipeResult, error) {
+			calls <- call{ai.AttributionFromContext(ctx), ctx.Err()}
+			return nil, errors.New("stopped by test")
+		},
+	}
+	svc := newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, nil, nil)
+
+	// The request context is cancelled as soon as the handler returns; the
+	// background regeneration must still run, charged to the user.
+	attribution := ai.CallAttribution{UserID: user.ID, RequestID: "req-1", Endpoint: "/v1/recipes/:recipe_id/regenerate"}
+	reqCtx, cancel := context.WithCancel(ai.WithAttribution(context.Background(), attribution))
+	if err := svc.InitRegenerateRecipe(reqCtx, user, 1, "fluffier", false); err != nil {
+		t.Fatalf("InitRegenerateRecipe() error = %v", err)
+	}
+	cancel()
+
+	select {
+	case got := <-calls:
+		if got.attribution != attribution {
+			t.Errorf("attribution = %+v, want %+v", got.attribution, attribution)
+		}
+		if got.err != nil {
+			t.Errorf("regeneration context error = %v; request cancellation must not reach it", got.err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("FinishRegenerateRecipe goroutine never called the text provider")
+	}
+}
+
+func TestInitRegenerateRecipe_ServedByExperimentArm(t *testing.T) {
+	repo := testutil.NewMockRecipeRepo()
+	user := testutil.TestUser()
+	seedOwnedRecipeWithTree(t, repo, user.ID)
+
+	served := make(chan string, 1)
+	serve := func(name string) *testutil.MockTextProvider {
+		return &testutil.MockTextProvider{
+			RegenerateRecipeFunc: func(ctx context.Context, req ai.RegenerateRequest) (*ai.RecipeResult, error) {
+				served <- name
+				return nil, errors.New("stopped by test")
+			},
+		}
+	}
+	router := ai.NewExperimentRouter()
+	router.Set([]*ai.Experiment{{
+		Key:       "regen-test",
+		Operation: "RegenerateRecipe",
+		Arms:      []ai.ExperimentArm{{Name: "only", Percent: 100, Provider: serve("arm")}},
+	}})
+	svc := newGenRecipeService(repo, router.Wrap(serve("tier")), &testutil.MockImageProvider{}, nil, nil)
+
+	ctx := ai.WithAttribution(context.Background(), ai.CallAttribution{UserID: user.ID})
+	if err := svc.InitRegenerateRecipe(ctx, user, 1, "fluffier", false); err != nil {
+		t.Fatalf("InitRegenerateRecipe() error = %v", err)
+	}
+
+	select {
+	case got := <-served:
+		if got != "arm" {
+			t.Errorf("regeneration served by %q, want the experiment arm", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("FinishRegenerateRecipe goroutine never called the text provider")
+	}
+}
+
 // --- FinishRegenerateRecipe ---
 
 func TestFinishRegenerateRecipe_HappyPathAppendsNode(t *testing.T) {
@@ -141,7 +218,7 @@ func TestFinishRegenerateRecipe_HappyPathAppendsNode(t *testing.T) {
 	}
 
 	svc := newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, embed, vector)
-	svc.FinishRegenerateRecipe(recipe, user, "make them fluffier", false)
+	svc.FinishRegenerateRecipe(context.Background(), recipe, user, "make them fluffier", false)
 
 	// Conversation context: the chain root rendered with the pre-regen def.
 	wantHistory := []ai.Message{
@@ -222,7 +299,7 @@ func TestFinishRegenerateRecipe_CanonicalCopyOnWrite(t *testing.T) {
 	}
 
 	svc := newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, nil, nil)
-	svc.FinishRegenerateRecipe(recipe, user, "make it mine", false)
+	svc.FinishRegenerateRecipe(context.Background(), recipe, user, "make it mine", false)
 
 	stored := repo.RecipeSnapshot(recipe.ID)
 	if stored == nil {
@@ -249,7 +326,7 @@ func TestFinishRegenerateRecipe_AIFailurePreservesRecipe(t *testing.T) {
 	}
 
 	svc := newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, nil, nil)
-	svc.FinishRegenerateRecipe(recipe, user, "fluffier", false)
+	svc.FinishRegenerateRecipe(context.Background(), recipe, user, "fluffier", false)
 
 	stored := repo.RecipeSnapshot(recipe.ID)
 	if stored == nil {
@@ -277,7 +354,7 @@ func TestFinishRegenerateRecipe_ValidationFailurePreservesRecipe(t *testing.T) {
 	}
 
 	svc := newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, nil, nil)
-	svc.FinishRegenerateRecipe(recipe, user, "fluffier", false)
+	svc.FinishRegenerateRecipe(context.Background(), recipe, user, "fluffier", false)
 
 	stored := repo.RecipeSnapshot(recipe.ID)
 	if stored == nil || stored.Title != "Classic Pancakes" {
@@ -299,7 +376,7 @@ func TestFinishRegenerateRecipe_UnitSystemFallbackToPersonalization(t *testing.T
 	}
 
 	svc := newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, nil, nil)
-	svc.FinishRegenerateRecipe(recipe, user, "fluffier", false)
+	svc.FinishRegenerateRecipe(context.Background(), recipe, user, "fluffier", false)
 
 	stored := repo.RecipeSnapshot(recipe.ID)
 	if stored == nil || stored.UnitSystem != "us_customary" {
@@ -331,7 +408,7 @@ func TestFinishRegenerateRecipe_NoTreeStillUpdatesDef(t *testing.T) {
 	}
 
 	svc := newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, nil, nil)
-	svc.FinishRegenerateRecipe(recipe, user, "fluffier", false)
+	svc.FinishRegenerateRecipe(context.Background(), recipe, user, "fluffier", false)
 
 	if gotHistory != nil {
 		t.Errorf("ExistingHistory = %+v, want nil when no tree exists", gotHistory)
@@ -359,7 +436,7 @@ func TestFinishRegenerateRecipe_PersistFailureSkipsNodeAppend(t *testing.T) {
 	}
 
 	svc := newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, nil, nil)
-	svc.FinishRegenerateRecipe(recipe, user, "fluffier", false)
+	svc.FinishRegenerateRecipe(context.Background(), recipe, user, "fluffier", false)
 
 	if repo.RecipeSnapshot(recipe.ID) == nil {
 		t.Fatal("regen persist failure must not delete the recipe")
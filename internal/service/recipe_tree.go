@@ -20,6 +20,9 @@ type RecipeTreeService struct {
 	// the active node rewrites the recipe definition.
 	EmbedProvider ai.EmbeddingProvider
 	VectorRepo    repository.VectorRepo
+	// Optional: the main tier, used to merge cooking-mode edits into the
+	// active recipe definition (see SaveCookingEdits).
+	TextProvider ai.TextProvider
 }
 
 // NewRecipeTreeService creates a new RecipeTreeService.
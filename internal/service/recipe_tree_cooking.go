@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"go.uber.org/zap"
+)
+
+// Errors returned when saving cooking-mode edits to a recipe tree.
+var (
+	ErrNoCookingEdits         = errors.New("no cooking edits to save")
+	ErrCookingMergeDisabled   = errors.New("merging cooking edits is not available")
+	ErrNodeNotInTree          = errors.New("node does not belong to this recipe's tree")
+	ErrNodeNotEphemeral       = errors.New("node is not ephemeral")
+	ErrEphemeralNodeInUse     = errors.New("node is active or has child nodes")
+	errMergedRecipeIncomplete = errors.New("merged recipe is missing required fields")
+)
+
+// SaveCookingEdits merges the ephemeral edits made while cooking into the
+// recipe's active definition with a main-tier regeneration, and stores the
+// result as a child of the active node. A permanent node becomes the active
+// version of the recipe; an ephemeral one is kept aside until it is promoted
+// (PromoteNode) or discarded (DiscardNode). branchName defaults to the active
+// node's branch.
+func (s *RecipeTreeService) SaveCookingEdits(ctx context.Context, user *models.User, recipe *models.Recipe, edits []models.CookingEdit, branchName string, ephemeral bool) (*models.RecipeNode, error) {
+	if len(edits) == 0 {
+		return nil, ErrNoCookingEdits
+	}
+	if s.TextProvider == nil {
+		return nil, ErrCookingMergeDisabled
+	}
+
+	tree, err := s.Repo.GetTreeByRecipeID(recipe.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recipe tree: %w", err)
+	}
+	activeNode, err := s.Repo.GetActiveNode(tree.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active node: %w", err)
+	}
+
+	current := effectiveRecipeDef(recipe)
+	var history []ai.Message
+	if ancestors, ancestorErr := s.Repo.GetNodeAncestors(activeNode.ID); ancestorErr == nil {
+		history = compactNodeChain(ancestors, &current, maxUncompactedNodes)
+	} else {
+		logger.Get().Warn("failed to load node ancestors for cooking edits", zap.Uint("recipe_id", recipe.ID), zap.Error(ancestorErr))
+	}
+
+	prompt := cookingEditsPrompt(edits, current.Instructions)
+	req := ai.RegenerateRequest{
+		RecipeRequest:   ai.RecipeRequest{UserPrompt: prompt},
+		ExistingHistory: history,
+	}
+	if p := user.Personalization; p != nil {
+		req.UnitSystem = p.UnitSystemText()
+		req.Requirements = p.Requirements
+		req.CookingContext = p.CookingContextPrompt()
+	}
+
+	result, err := s.TextProvider.RegenerateRecipe(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge cooking edits: %w", err)
+	}
+	if result.UnitSystem == "" {
+		result.UnitSystem = current.UnitSystem
+	}
+	merged := recipeResultToRecipeDef(result)
+	if merged.SourceURL == "" {
+		merged.SourceURL = current.SourceURL
+	}
+	if merged.Title == "" || len(merged.Ingredients) == 0 || len(merged.Instructions) == 0 {
+		return nil, errMergedRecipeIncomplete
+	}
+
+	if branchName == "" {
+		branchName = activeNode.BranchName
+	}
+	node := &models.RecipeNode{
+		TreeID:      tree.ID,
+		ParentID:    &activeNode.ID,
+		Prompt:      prompt,
+		Response:    &merged,
+		Summary:     result.Summary,
+		Type:        models.RecipeTypeCookingEdits,
+		BranchName:  branchName,
+		IsEphemeral: ephemeral,
+		CreatedByID: user.ID,
+	}
+	if err := s.Repo.AddNodeToTree(node, false); err != nil {
+		return nil, fmt.Errorf("failed to add cooking edits node: %w", err)
+	}
+
+	if !ephemeral {
+		if err := s.activate(recipe, node.ID); err != nil {
+			return nil, err
+		}
+		node.IsActive = true
+	}
+	return node, nil
+}
+
+// PromoteNode turns an ephemeral node into a permanent one and makes it the
+// active version of the recipe.
+func (s *RecipeTreeService) PromoteNode(recipe *models.Recipe, nodeID uint) (*models.RecipeNode, error) {
+	node, err := s.treeNode(recipe.ID, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if !node.IsEphemeral {
+		return nil, ErrNodeNotEphemeral
+	}
+
+	if err := s.Repo.SetNodeEphemeral(nodeID, false); err != nil {
+		return nil, fmt.Errorf("failed to promote node: %w", err)
+	}
+	if err := s.activate(recipe, nodeID); err != nil {
+		return nil, err
+	}
+	node.IsEphemeral, node.IsActive = false, true
+	return node, nil
+}
+
+// DiscardNode deletes an ephemeral node. Nodes that are active or have been
+// built on are kept.
+func (s *RecipeTreeService) DiscardNode(recipeID uint, nodeID uint) error {
+	node, err := s.treeNode(recipeID, nodeID)
+	if err != nil {
+		return err
+	}
+	if !node.IsEphemeral {
+		return ErrNodeNotEphemeral
+	}
+	children, err := s.Repo.GetNodeChildren(nodeID)
+	if err != nil {
+		return fmt.Errorf("failed to get node children: %w", err)
+	}
+	if node.IsActive || len(children) > 0 {
+		return ErrEphemeralNodeInUse
+	}
+
+	if err := s.Repo.DeleteNode(nodeID); err != nil {
+		return fmt.Errorf("failed to discard node: %w", err)
+	}
+	return nil
+}
+
+// treeNode loads a node, checking that it belongs to the recipe's tree.
+func (s *RecipeTreeService) treeNode(recipeID uint, nodeID uint) (*models.RecipeNode, error) {
+	tree, err := s.Repo.GetTreeByRecipeID(recipeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recipe tree: %w", err)
+	}
+	node, err := s.Repo.GetNodeByID(nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+	if node.TreeID != tree.ID {
+		return nil, ErrNodeNotInTree
+	}
+	return node, nil
+}
+
+// activate makes a node the recipe's active version. A recipe still backed by
+// a shared canonical is materialized first, so the node's definition is what
+// the recipe serves from then on.
+func (s *RecipeTreeService) activate(recipe *models.Recipe, nodeID uint) error {
+	if err := MaterializeCanonical(recipe, s.Repo); err != nil {
+		return fmt.Errorf("failed to materialize canonical recipe: %w", err)
+	}
+	return s.SetActiveNode(recipe.ID, nodeID)
+}
+
+// cookingEditsPrompt describes the edits made while cooking as a
+// regeneration request, quoting the step each edit was made on.
+func cookingEditsPrompt(edits []models.CookingEdit, instructions []string) string {
+	var b strings.Builder
+	b.WriteString("While cooking this recipe I made the following changes. Update the recipe so it reflects them, adjusting ingredients and steps as needed, and keep everything else the same:\n")
+	for _, edit := range edits {
+		if edit.StepIndex >= 0 && edit.StepIndex < len(instructions) {
+			fmt.Fprintf(&b, "- Step %d (%q): %s\n", edit.StepIndex+1, instructions[edit.StepIndex], edit.Modification)
+		} else {
+			fmt.Fprintf(&b, "- %s\n", edit.Modification)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
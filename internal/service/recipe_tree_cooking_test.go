@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/config"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+)
+
+// newCookingTreeService returns a tree service over the seeded fixture whose
+// main tier answers with a two-egg version of the recipe, recording the
+// request it was sent.
+func newCookingTreeService(t *testing.T) (*RecipeTreeService, *testutil.MockRecipeRepo, *models.RecipeNode, *ai.RegenerateRequest) {
+	t.Helper()
+	repo := testutil.NewMockRecipeRepo()
+	root, _ := seedTreeServiceFixture(t, repo)
+
+	var sent ai.RegenerateRequest
+	svc := NewRecipeTreeService(&config.Config{}, repo)
+	svc.TextProvider = &testutil.MockTextProvider{
+		RegenerateRecipeFunc: func(ctx context.Context, req ai.RegenerateRequest) (*ai.RecipeResult, error) {
+			sent = req
+			result := testutil.TestRecipeResult()
+			result.Title = "Two-Egg Pancakes"
+			result.Summary = "Used two eggs."
+			return result, nil
+		},
+	}
+	return svc, repo, root, &sent
+}
+
+var testCookingEdits = []models.CookingEdit{
+	{StepIndex: 1, Modification: "used 2 eggs instead of 1"},
+	{StepIndex: 99, Modification: "served with berries"},
+}
+
+func TestSaveCookingEdits_PermanentBecomesActive(t *testing.T) {
+	svc, repo, root, sent := newCookingTreeService(t)
+
+	node, err := svc.SaveCookingEdits(context.Background(), testutil.TestUser(), repo.RecipeSnapshot(7), testCookingEdits, "", false)
+	if err != nil {
+		t.Fatalf("SaveCookingEdits() error = %v", err)
+	}
+
+	if !strings.Contains(sent.UserPrompt, `Step 2 ("Whisk wet ingredients"): used 2 eggs instead of 1`) ||
+		!strings.Contains(sent.UserPrompt, "- served with berries") {
+		t.Errorf("merge prompt = %q, want every edit with its step", sent.UserPrompt)
+	}
+	if len(sent.ExistingHistory) == 0 {
+		t.Error("merge should carry the active node's history")
+	}
+	if sent.Requirements != "No peanuts" {
+		t.Errorf("Requirements = %q, want the user's personalization", sent.Requirements)
+	}
+
+	if node.ParentID == nil || *node.ParentID != root.ID || node.Type != models.RecipeTypeCookingEdits {
+		t.Errorf("node = %+v, want a cooking-edits child of the active root", node)
+	}
+	if node.BranchName != root.BranchName || node.Summary != "Used two eggs." {
+		t.Errorf("branch %q summary %q, want the active branch and the merge summary", node.BranchName, node.Summary)
+	}
+	if !node.IsActive || root.IsActive {
+		t.Error("the saved node should replace the root as the active node")
+	}
+	if got := repo.RecipeSnapshot(7).Title; got != "Two-Egg Pancakes" {
+		t.Errorf("recipe Title = %q, want the merged definition", got)
+	}
+}
+
+func TestSaveCookingEdits_EphemeralThenPromote(t *testing.T) {
+	svc, repo, root, _ := newCookingTreeService(t)
+	recipe := repo.RecipeSnapshot(7)
+
+	node, err := svc.SaveCookingEdits(context.Background(), testutil.TestUser(), recipe, testCookingEdits, "weeknight", true)
+	if err != nil {
+		t.Fatalf("SaveCookingEdits() error = %v", err)
+	}
+	if !node.IsEphemeral || node.IsActive || node.BranchName != "weeknight" {
+		t.Errorf("node = %+v, want an inactive ephemeral node on branch weeknight", node)
+	}
+	if !root.IsActive || repo.RecipeSnapshot(7).Title != "Classic Pancakes" {
+		t.Error("an ephemeral save must leave the recipe unchanged")
+	}
+
+	promoted, err := svc.PromoteNode(recipe, node.ID)
+	if err != nil {
+		t.Fatalf("PromoteNode() error = %v", err)
+	}
+	if promoted.IsEphemeral || !promoted.IsActive || root.IsActive {
+		t.Errorf("promoted node = %+v, want permanent and active", promoted)
+	}
+	if got := repo.RecipeSnapshot(7).Title; got != "Two-Egg Pancakes" {
+		t.Errorf("recipe Title = %q, want the promoted definition", got)
+	}
+	if _, err := svc.PromoteNode(recipe, node.ID); !errors.Is(err, ErrNodeNotEphemeral) {
+		t.Errorf("second PromoteNode() error = %v, want ErrNodeNotEphemeral", err)
+	}
+}
+
+func TestDiscardNode(t *testing.T) {
+	svc, repo, root, _ := newCookingTreeService(t)
+	recipe := repo.RecipeSnapshot(7)
+	node, _ := svc.SaveCookingEdits(context.Background(), testutil.TestUser(), recipe, testCookingEdits, "", true)
+
+	if err := svc.DiscardNode(7, root.ID); !errors.Is(err, ErrNodeNotEphemeral) {
+		t.Errorf("DiscardNode(root) error = %v, want ErrNodeNotEphemeral", err)
+	}
+	if _, err := svc.CreateBranch(7, node.ID, "built on", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DiscardNode(7, node.ID); !errors.Is(err, ErrEphemeralNodeInUse) {
+		t.Errorf("DiscardNode(with child) error = %v, want ErrEphemeralNodeInUse", err)
+	}
+
+	leaf, _ := svc.SaveCookingEdits(context.Background(), testutil.TestUser(), recipe, testCookingEdits, "", true)
+	if err := svc.DiscardNode(7, leaf.ID); err != nil {
+		t.Fatalf("DiscardNode() error = %v", err)
+	}
+	if _, err := repo.GetNodeByID(leaf.ID); err == nil {
+		t.Error("discarded node is still stored")
+	}
+	if err := svc.DiscardNode(8, node.ID); err == nil {
+		t.Error("DiscardNode() on another recipe's tree should fail")
+	}
+}
+
+func TestSaveCookingEdits_Errors(t *testing.T) {
+	svc, repo, _, _ := newCookingTreeService(t)
+	recipe := repo.RecipeSnapshot(7)
+	ctx := context.Background()
+
+	if _, err := svc.SaveCookingEdits(ctx, testutil.TestUser(), recipe, nil, "", false); !errors.Is(err, ErrNoCookingEdits) {
+		t.Errorf("no edits error = %v, want ErrNoCookingEdits", err)
+	}
+
+	svc.TextProvider = &testutil.MockTextProvider{
+		RegenerateRecipeFunc: func(ctx context.Context, req ai.RegenerateRequest) (*ai.RecipeResult, error) {
+			return &ai.RecipeResult{Title: "Half a recipe"}, nil
+		},
+	}
+	if _, err := svc.SaveCookingEdits(ctx, testutil.TestUser(), recipe, testCookingEdits, "", false); err == nil {
+		t.Error("an incomplete merge should be rejected")
+	}
+
+	svc.TextProvider = nil
+	if _, err := svc.SaveCookingEdits(ctx, testutil.TestUser(), recipe, testCookingEdits, "", false); !errors.Is(err, ErrCookingMergeDisabled) {
+		t.Errorf("no provider error = %v, want ErrCookingMergeDisabled", err)
+	}
+	if got := len(repo.Nodes); got != 2 {
+		t.Errorf("failed saves left %d nodes, want the 2 seeded", got)
+	}
+}
@@ -21,7 +21,9 @@ const cacheTTL = 24 * time.Hour
 // caller requests more than the provider can deliver.
 const maxProviderPageSize = 20
 
-// SearchServiceResult wraps search results with a cache flag.
+// SearchServiceResult wraps search results with a cache flag. FromCache is
+// true whenever no paid web search was made (a cache hit, or a page served
+// from the local canonical index).
 type SearchServiceResult struct {
 	Results   []ai.SearchResult
 	FromCache bool
@@ -35,6 +37,9 @@ type SearchService struct {
 	SubService     *SubscriptionService
 	CacheRepo      repository.SearchCacheRepo
 	EmbedProvider  ai.EmbeddingProvider
+	// LocalRepo, when set, is searched for a first page after the caches
+	// miss and before the web. Nil disables the local phase.
+	LocalRepo LocalRecipeSearchRepo
 }
 
 // NewSearchService creates a new SearchService.
@@ -49,9 +54,17 @@ func NewSearchService(cfg *config.Config, searchProvider ai.SearchProvider, subS
 
 // SearchRecipes searches for recipes, checking cache first.
 // Caching is only used for the first page (offset == 0); subsequent pages
-// go directly to the search provider.
+// go directly to the search provider. The cache is partitioned by the
+// searcher's market (ai.LocaleFromContext), which the backends also receive.
+//
+// After both caches miss, the local canonical index is searched: when it has
+// enough high-quality hits the page is served from it alone; otherwise its
+// hits lead the web results. If the web search fails (or no provider is
+// configured) any local hits are served instead of the error. Every result
+// carries its Origin.
 func (s *SearchService) SearchRecipes(ctx context.Context, query string, count int, offset int) (*SearchServiceResult, error) {
 	normalized := normalizeQuery(query)
+	market := ai.LocaleFromContext(ctx).CacheKey()
 
 	// The provider may cap page size below what the caller asked for
 	// (e.g. Brave max 20). Use the effective cap for HasMore so we
@@ -64,12 +77,15 @@ func (s *SearchService) SearchRecipes(ctx context.Context, query string, count i
 
 	// Paginated requests bypass cache entirely.
 	if offset > 0 {
+		if s.SearchProvider == nil {
+			return &SearchServiceResult{FromCache: true}, nil
+		}
 		results, err := s.SearchProvider.SearchRecipes(ctx, query, count, offset)
 		if err != nil {
 			return nil, err
 		}
 		return &SearchServiceResult{
-			Results:   results,
+			Results:   markWebOrigin(results),
 			FromCache: false,
 			HasMore:   len(results) >= effectiveCount,
 		}, nil
@@ -77,7 +93,7 @@ func (s *SearchService) SearchRecipes(ctx context.Context, query string, count i
 
 	// Phase 1: exact-match cache lookup
 	if s.CacheRepo != nil {
-		entry, err := s.CacheRepo.GetByNormalizedQuery(normalized)
+		entry, err := s.CacheRepo.GetByNormalizedQuery(normalized, market)
 		if err == nil && time.Since(entry.FetchedAt) < cacheTTL {
 			go func() {
 				if err := s.CacheRepo.IncrementHitCount(entry.ID); err != nil {
@@ -93,52 +109,74 @@ func (s *SearchService) SearchRecipes(ctx context.Context, query string, count i
 		}
 	}
 
+	// The query embedding is shared by the semantic cache and the local index.
+	var embedding []float32
+	if s.CacheRepo != nil || s.LocalRepo != nil {
+		embedding = s.queryEmbedding(ctx, normalized)
+	}
+
 	// Phase 2: semantic/vector cache lookup
-	if s.CacheRepo != nil && s.EmbedProvider != nil {
-		embedding, err := s.EmbedProvider.GenerateEmbedding(ctx, normalized)
-		if err == nil {
-			similar, err := s.CacheRepo.FindSimilar(embedding, 0.92, 1)
-			if err == nil && len(similar) > 0 && time.Since(similar[0].FetchedAt) < cacheTTL {
-				go func() {
-					if err := s.CacheRepo.IncrementHitCount(similar[0].ID); err != nil {
-						logger.Get().Warn("failed to increment cache hit count", zap.Error(err))
-					}
-				}()
-				results := cacheItemsToSearchResults(similar[0].Results)
-				return &SearchServiceResult{
-					Results:   results,
-					FromCache: true,
-					HasMore:   len(results) >= effectiveCount,
-				}, nil
-			}
-		} else {
-			logger.Get().Warn("failed to generate embedding for search query", zap.Error(err))
+	if s.CacheRepo != nil && embedding != nil {
+		similar, err := s.CacheRepo.FindSimilar(embedding, 0.92, 1, market)
+		if err == nil && len(similar) > 0 && time.Since(similar[0].FetchedAt) < cacheTTL {
+			go func() {
+				if err := s.CacheRepo.IncrementHitCount(similar[0].ID); err != nil {
+					logger.Get().Warn("failed to increment cache hit count", zap.Error(err))
+				}
+			}()
+			results := cacheItemsToSearchResults(similar[0].Results)
+			return &SearchServiceResult{
+				Results:   results,
+				FromCache: true,
+				HasMore:   len(results) >= effectiveCount,
+			}, nil
 		}
 	}
 
+	// Phase 3: local canonical index. Enough strong hits skip the web; weaker
+	// ones are only served when the web is unavailable.
+	local, strong := s.searchLocal(query, embedding, effectiveCount)
+	if strong > 0 && strong >= min(effectiveCount, localEnoughHits) {
+		return &SearchServiceResult{
+			Results:   local,
+			FromCache: true,
+			HasMore:   false,
+		}, nil
+	}
+
 	// Cache miss — call search provider
+	if s.SearchProvider == nil {
+		return &SearchServiceResult{Results: local, FromCache: true}, nil
+	}
 	results, err := s.SearchProvider.SearchRecipes(ctx, query, count, 0)
 	if err != nil {
+		if len(local) > 0 {
+			logger.Get().Warn("web search failed; serving local results", zap.String("query", query), zap.Error(err))
+			return &SearchServiceResult{Results: local, FromCache: true}, nil
+		}
 		return nil, err
 	}
+	results = markWebOrigin(results)
 
-	// Save to cache asynchronously
+	// Save to cache asynchronously. Only the web results are cached: local
+	// hits are re-read from the index on every miss, so they stay current.
 	if s.CacheRepo != nil {
-		go s.saveToCache(normalized, results)
+		go s.saveToCache(normalized, market, results)
 	}
 
 	return &SearchServiceResult{
-		Results:   results,
+		Results:   blendResults(local[:strong], results, count),
 		FromCache: false,
 		HasMore:   len(results) >= effectiveCount,
 	}, nil
 }
 
 // saveToCache upserts search results into the cache.
-func (s *SearchService) saveToCache(normalizedQuery string, results []ai.SearchResult) {
+func (s *SearchService) saveToCache(normalizedQuery, market string, results []ai.SearchResult) {
 	now := time.Now()
 	entry := &models.SearchCache{
 		NormalizedQuery: normalizedQuery,
+		Market:          market,
 		Results:         searchResultsToCacheItems(results),
 		ResultCount:     len(results),
 		LastAccessedAt:  now,
@@ -180,7 +218,8 @@ func (s *SearchService) StartBackgroundTasks() {
 	}()
 }
 
-// refreshHotQueries re-fetches popular queries approaching staleness.
+// refreshHotQueries re-fetches popular queries approaching staleness, each in
+// the market it was cached under.
 func (s *SearchService) refreshHotQueries() {
 	entries, err := s.CacheRepo.GetHotQueries(10, cacheTTL, 2*time.Hour)
 	if err != nil {
@@ -189,7 +228,8 @@ func (s *SearchService) refreshHotQueries() {
 	}
 
 	for _, entry := range entries {
-		results, err := s.SearchProvider.SearchRecipes(context.Background(), entry.NormalizedQuery, entry.ResultCount, 0)
+		ctx := ai.WithLocale(context.Background(), ai.ParseLocaleKey(entry.Market))
+		results, err := s.SearchProvider.SearchRecipes(ctx, entry.NormalizedQuery, entry.ResultCount, 0)
 		if err != nil {
 			logger.Get().Warn("failed to refresh hot query", zap.String("query", entry.NormalizedQuery), zap.Error(err))
 			continue
@@ -249,6 +289,7 @@ func searchResultsToCacheItems(results []ai.SearchResult) models.SearchResultLis
 			Rating:      r.Rating,
 			ImageURL:    r.ImageURL,
 			Description: r.Description,
+			Origin:      r.Origin,
 		}
 	}
 	return items
@@ -265,6 +306,7 @@ func cacheItemsToSearchResults(items models.SearchResultList) []ai.SearchResult
 			Rating:      item.Rating,
 			ImageURL:    item.ImageURL,
 			Description: item.Description,
+			Origin:      item.Origin,
 		}
 	}
 	return results
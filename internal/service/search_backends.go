@@ -0,0 +1,648 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"go.uber.org/zap"
+)
+
+// searchMinRecipeHits is how many recipe-like hits a first page needs before
+// the registry stops consulting further backends to top it up.
+const searchMinRecipeHits = 5
+
+// canonicalSearchMaxTerms bounds how many query terms the canonical index
+// ANDs together (each is one ILIKE).
+const canonicalSearchMaxTerms = 6
+
+// SearchBackendRepo is the persistence surface the search backend manager
+// needs. Backed by repository.SearchBackendRepository in production; faked in
+// tests.
+type SearchBackendRepo interface {
+	ListBackends() ([]models.SearchBackendOption, error)
+	GetBackend(id uint) (*models.SearchBackendOption, error)
+	CreateBackend(opt *models.SearchBackendOption) error
+	UpdateBackend(opt *models.SearchBackendOption) error
+	DeleteBackend(id uint) error
+	IncrementUsage(id uint, day string) (int, error)
+}
+
+// CanonicalSearchRepo is the canonical-recipe lookup behind the self-hosted
+// search index.
+type CanonicalSearchRepo interface {
+	SearchByTitle(terms []string, limit, offset int) ([]models.CanonicalRecipe, error)
+}
+
+// CanonicalSearchBackend is a search backend over our own CanonicalRecipe
+// table: every recipe anyone has ever extracted, matched on title. Its hits are
+// real, already-extracted recipes, so a tap on one is an instant cache hit.
+type CanonicalSearchBackend struct {
+	Repo CanonicalSearchRepo
+}
+
+var _ ai.SearchBackend = (*CanonicalSearchBackend)(nil)
+
+// NewCanonicalSearchBackend creates a CanonicalSearchBackend.
+func NewCanonicalSearchBackend(repo CanonicalSearchRepo) *CanonicalSearchBackend {
+	return &CanonicalSearchBackend{Repo: repo}
+}
+
+// Kind implements ai.SearchBackend.
+func (b *CanonicalSearchBackend) Kind() string { return ai.SearchBackendCanonical }
+
+// SearchRecipes implements ai.SearchProvider. "-term" negations (the finder's
+// allergen excludes) filter matching titles out rather than being searched for.
+func (b *CanonicalSearchBackend) SearchRecipes(ctx context.Context, query string, count int, offset int) ([]ai.SearchResult, error) {
+	if count <= 0 {
+		count = 10
+	}
+	terms, excludes := canonicalSearchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	entries, err := b.Repo.SearchByTitle(terms, count+len(excludes)*2, offset)
+	if err != nil {
+		return nil, fmt.Errorf("canonical search failed: %w", err)
+	}
+
+	results := make([]ai.SearchResult, 0, len(entries))
+	for _, e := range entries {
+		if len(results) >= count {
+			break
+		}
+		if titleHasAny(e.RecipeData.Title, excludes) {
+			continue
+		}
+		results = append(results, canonicalToSearchResult(e))
+	}
+	return results, nil
+}
+
+// canonicalSearchTerms splits a query into the terms to match and the
+// "-term" negations to exclude, dropping the generic "recipe(s)" suffix every
+// composed query carries.
+func canonicalSearchTerms(query string) (terms, excludes []string) {
+	for _, f := range strings.Fields(normalizeQuery(query)) {
+		if strings.HasPrefix(f, "-") {
+			if ex := strings.TrimPrefix(f, "-"); ex != "" {
+				excludes = append(excludes, ex)
+			}
+			continue
+		}
+		if f == "recipe" || f == "recipes" {
+			continue
+		}
+		if len(terms) < canonicalSearchMaxTerms {
+			terms = append(terms, f)
+		}
+	}
+	return terms, excludes
+}
+
+// titleHasAny reports whether title contains any of the (lowercase) terms.
+func titleHasAny(title string, terms []string) bool {
+	lower := strings.ToLower(title)
+	for _, t := range terms {
+		if strings.Contains(lower, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalToSearchResult maps a canonical entry to a search result, using a
+// short ingredient summary as the description so the finder's ranker has
+// something to judge it by.
+func canonicalToSearchResult(e models.CanonicalRecipe) ai.SearchResult {
+	var names []string
+	for _, ing := range e.RecipeData.Ingredients {
+		if n := strings.TrimSpace(ing.Name); n != "" {
+			names = append(names, n)
+		}
+		if len(names) >= 6 {
+			break
+		}
+	}
+	desc := ""
+	if len(names) > 0 {
+		desc = "Ingredients: " + strings.Join(names, ", ")
+		if len(e.RecipeData.Ingredients) > len(names) {
+			desc += "…"
+		}
+	}
+	return ai.SearchResult{
+		Title:       e.RecipeData.Title,
+		URL:         e.OriginalURL,
+		Source:      hostOf(e.OriginalURL),
+		Description: desc,
+		Origin:      ai.SearchOriginLocal,
+	}
+}
+
+// searchBackendState is one registry entry as the manager runs it: the stored
+// option, the built backend (nil when it couldn't be built), and its breaker.
+type searchBackendState struct {
+	opt      models.SearchBackendOption
+	backend  ai.SearchProvider
+	buildErr string
+	breaker  *ai.CircuitBreaker
+}
+
+// SearchBackendStatus is a registry entry plus its live health, as reported to
+// the admin API.
+type SearchBackendStatus struct {
+	models.SearchBackendOption
+	Health     ai.BreakerSnapshot `json:"health"`
+	BuildError string             `json:"build_error,omitempty"`
+}
+
+// SearchBackendManager owns the web search backend registry and is itself an
+// ai.SearchProvider, so it drops in wherever a single backend was used:
+//
+//   - Load: seed the registry from the configured keys on first boot, then
+//     build every stored backend.
+//   - StartRefresh: poll the DB so an admin edit on one instance propagates
+//     to the others (the API runs multiple ECS tasks).
+//   - SearchRecipes: pick a healthy primary by weight, fail over on error,
+//     and — for a first page with too few recipe-like hits — top it up from
+//     the next backends, merged and deduped by NormalizeURL.
+//
+// Each backend has a circuit breaker and an optional daily quota shared across
+// instances through the DB. API keys live in SearchKeys (env/SSM), never in
+// the DB.
+type SearchBackendManager struct {
+	repo       SearchBackendRepo
+	keys       ai.SearchKeys
+	local      ai.SearchBackend
+	searxngURL string
+
+	// randIntN and now are overridable in tests.
+	randIntN func(n int) int
+	now      func() time.Time
+
+	mu       sync.RWMutex
+	backends []*searchBackendState
+}
+
+// NewSearchBackendManager creates a manager. local (nil-safe) serves the
+// canonical kind; searxngURL seeds a SearXNG backend on first boot when set.
+func NewSearchBackendManager(repo SearchBackendRepo, keys ai.SearchKeys, local ai.SearchBackend, searxngURL string) *SearchBackendManager {
+	return &SearchBackendManager{
+		repo:       repo,
+		keys:       keys,
+		local:      local,
+		searxngURL: searxngURL,
+		randIntN:   rand.IntN,
+		now:        time.Now,
+	}
+}
+
+var _ ai.SearchProvider = (*SearchBackendManager)(nil)
+
+// Load seeds an empty registry from the configured keys, then builds every
+// stored backend. Best-effort: a DB error is logged and leaves the registry
+// empty (searches then fail with "no search providers available").
+func (m *SearchBackendManager) Load() {
+	opts, err := m.repo.ListBackends()
+	if err != nil {
+		logger.Get().Warn("search backends: list failed during load", zap.Error(err))
+		return
+	}
+	if len(opts) == 0 {
+		m.seedDefaults()
+	}
+	m.Refresh()
+}
+
+// StartRefresh polls the registry every interval and applies changes made
+// elsewhere. Breaker state survives a refresh for backends whose identity and
+// breaker tuning didn't change.
+func (m *SearchBackendManager) StartRefresh(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				m.Refresh()
+			}
+		}
+	}()
+}
+
+// Refresh rebuilds the running registry from the DB.
+func (m *SearchBackendManager) Refresh() {
+	opts, err := m.repo.ListBackends()
+	if err != nil {
+		logger.Get().Warn("search backends: refresh failed, keeping current registry", zap.Error(err))
+		return
+	}
+
+	m.mu.RLock()
+	prev := make(map[uint]*searchBackendState, len(m.backends))
+	for _, st := range m.backends {
+		prev[st.opt.ID] = st
+	}
+	m.mu.RUnlock()
+
+	next := make([]*searchBackendState, 0, len(opts))
+	for _, opt := range opts {
+		if old, ok := prev[opt.ID]; ok && sameBackendIdentity(old.opt, opt) {
+			next = append(next, &searchBackendState{opt: opt, backend: old.backend, buildErr: old.buildErr, breaker: old.breaker})
+			continue
+		}
+		next = append(next, m.buildState(opt))
+	}
+
+	m.mu.Lock()
+	m.backends = next
+	m.mu.Unlock()
+}
+
+// sameBackendIdentity reports whether two versions of an option can share a
+// built backend and breaker.
+func sameBackendIdentity(a, b models.SearchBackendOption) bool {
+	return a.Kind == b.Kind && a.BaseURL == b.BaseURL &&
+		a.BreakerThreshold == b.BreakerThreshold && a.BreakerCooldownSec == b.BreakerCooldownSec
+}
+
+// buildState builds the backend for an option. Build failures (e.g. a missing
+// key) are recorded on the state and surfaced by the admin API.
+func (m *SearchBackendManager) buildState(opt models.SearchBackendOption) *searchBackendState {
+	st := &searchBackendState{
+		opt:     opt,
+		breaker: ai.NewCircuitBreaker(opt.BreakerThreshold, time.Duration(opt.BreakerCooldownSec)*time.Second),
+	}
+	if opt.Kind == ai.SearchBackendCanonical {
+		if m.local == nil {
+			st.buildErr = "canonical index is not configured"
+		} else {
+			st.backend = m.local
+		}
+		return st
+	}
+	backend, err := ai.BuildSearchBackend(ai.SearchBackendSpec{Kind: opt.Kind, BaseURL: opt.BaseURL}, m.keys)
+	if err != nil {
+		st.buildErr = err.Error()
+		return st
+	}
+	st.backend = backend
+	return st
+}
+
+// SearchRecipes implements ai.SearchProvider over the registry. Backends are
+// tried in weighted-random order (supplement-only backends last), skipping any
+// whose breaker is open or whose daily quota is spent. Later pages (offset >
+// 0) take the first backend that answers; a first page keeps consulting
+// backends until it holds enough recipe-like hits, merging and deduping by
+// NormalizeURL.
+func (m *SearchBackendManager) SearchRecipes(ctx context.Context, query string, count int, offset int) ([]ai.SearchResult, error) {
+	if count <= 0 {
+		count = 10
+	}
+	minHits := searchMinRecipeHits
+	if count < minHits {
+		minHits = count
+	}
+
+	var (
+		merged  []ai.SearchResult
+		seen    = make(map[string]bool)
+		served  int
+		lastErr error
+	)
+	for _, st := range m.order() {
+		if ctx.Err() != nil {
+			break
+		}
+		if served > 0 && (offset > 0 || countRecipeLike(merged) >= minHits) {
+			break
+		}
+		if !m.quotaAvailable(st) || !st.breaker.Allow() {
+			continue
+		}
+		m.countUsage(st)
+
+		results, err := st.backend.SearchRecipes(ctx, query, count, offset)
+		if err != nil {
+			if errors.Is(err, ai.ErrSearchQuotaExhausted) {
+				st.breaker.Trip(err)
+			} else {
+				st.breaker.RecordFailure(err)
+			}
+			logger.Get().Warn("search backend failed",
+				zap.String("kind", st.opt.Kind), zap.Uint("backend_id", st.opt.ID), zap.Error(err))
+			lastErr = err
+			continue
+		}
+		st.breaker.RecordSuccess()
+		served++
+		for _, r := range results {
+			key := dedupeKey(r.URL)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, r)
+		}
+	}
+
+	if served == 0 {
+		if lastErr != nil {
+			return nil, fmt.Errorf("no search providers available: %w", lastErr)
+		}
+		return nil, fmt.Errorf("no search providers available")
+	}
+	if served > 1 {
+		merged = recipeLikeFirst(merged)
+	}
+	if len(merged) > count {
+		merged = merged[:count]
+	}
+	return merged, nil
+}
+
+// order returns the runnable backends in try order: weighted backends in a
+// weight-proportional random order, then supplement-only (weight 0) backends
+// in registry order.
+func (m *SearchBackendManager) order() []*searchBackendState {
+	m.mu.RLock()
+	var weighted, supplements []*searchBackendState
+	for _, st := range m.backends {
+		if !st.opt.Enabled || st.backend == nil {
+			continue
+		}
+		if st.opt.Weight > 0 {
+			weighted = append(weighted, st)
+		} else {
+			supplements = append(supplements, st)
+		}
+	}
+	m.mu.RUnlock()
+
+	ordered := make([]*searchBackendState, 0, len(weighted)+len(supplements))
+	for len(weighted) > 0 {
+		total := 0
+		for _, st := range weighted {
+			total += st.opt.Weight
+		}
+		pick := m.randIntN(total)
+		for i, st := range weighted {
+			pick -= st.opt.Weight
+			if pick < 0 {
+				ordered = append(ordered, st)
+				weighted = append(weighted[:i], weighted[i+1:]...)
+				break
+			}
+		}
+	}
+	return append(ordered, supplements...)
+}
+
+// usageDay is the UTC day key quota counters are bucketed by.
+func (m *SearchBackendManager) usageDay() string {
+	return m.now().UTC().Format("2006-01-02")
+}
+
+// quotaAvailable reports whether a backend has quota left today, judged from
+// the last-known shared counter.
+func (m *SearchBackendManager) quotaAvailable(st *searchBackendState) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if st.opt.DailyQuota <= 0 || st.opt.UsageDay != m.usageDay() {
+		return true
+	}
+	return st.opt.UsedToday < st.opt.DailyQuota
+}
+
+// countUsage counts one call against a backend's shared daily counter and
+// records the new value locally. Only quota-capped backends are counted, so
+// uncapped ones never cost a DB write per search. Best-effort: a failed
+// increment is logged and the call proceeds.
+func (m *SearchBackendManager) countUsage(st *searchBackendState) {
+	if st.opt.DailyQuota <= 0 {
+		return
+	}
+	day := m.usageDay()
+	used, err := m.repo.IncrementUsage(st.opt.ID, day)
+	if err != nil {
+		logger.Get().Warn("search backends: failed to count usage", zap.Uint("backend_id", st.opt.ID), zap.Error(err))
+		return
+	}
+	m.mu.Lock()
+	st.opt.UsedToday = used
+	st.opt.UsageDay = day
+	m.mu.Unlock()
+}
+
+// isRecipeLike is the cheap heuristic for whether a hit is likely a recipe page
+// (vs. a forum thread, video or shop): our own canonical entries always are;
+// web hits qualify when their URL or title says "recipe".
+func isRecipeLike(r ai.SearchResult) bool {
+	if strings.Contains(strings.ToLower(r.URL), "recipe") {
+		return true
+	}
+	return strings.Contains(strings.ToLower(r.Title), "recipe")
+}
+
+// countRecipeLike counts the recipe-like hits in results.
+func countRecipeLike(results []ai.SearchResult) int {
+	n := 0
+	for _, r := range results {
+		if isRecipeLike(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// recipeLikeFirst stably moves recipe-like hits ahead of the rest, so topping
+// up a thin primary can't be truncated away by the primary's own non-recipe
+// hits.
+func recipeLikeFirst(results []ai.SearchResult) []ai.SearchResult {
+	out := make([]ai.SearchResult, 0, len(results))
+	var rest []ai.SearchResult
+	for _, r := range results {
+		if isRecipeLike(r) {
+			out = append(out, r)
+		} else {
+			rest = append(rest, r)
+		}
+	}
+	return append(out, rest...)
+}
+
+// dedupeKey is the URL identity used to merge backends' results.
+func dedupeKey(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return ""
+	}
+	if n, err := NormalizeURL(rawURL); err == nil {
+		return n
+	}
+	return rawURL
+}
+
+// ListBackends returns the registry with each backend's live health.
+func (m *SearchBackendManager) ListBackends() ([]SearchBackendStatus, error) {
+	opts, err := m.repo.ListBackends()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	live := make(map[uint]*searchBackendState, len(m.backends))
+	for _, st := range m.backends {
+		live[st.opt.ID] = st
+	}
+	m.mu.RUnlock()
+
+	out := make([]SearchBackendStatus, 0, len(opts))
+	for _, opt := range opts {
+		status := SearchBackendStatus{SearchBackendOption: opt, Health: ai.BreakerSnapshot{State: ai.BreakerClosed}}
+		if st, ok := live[opt.ID]; ok {
+			status.Health = st.breaker.Snapshot()
+			status.BuildError = st.buildErr
+		}
+		out = append(out, status)
+	}
+	return out, nil
+}
+
+// validateBackendOption rejects unknown kinds and a SearXNG entry without an
+// instance URL.
+func validateBackendOption(opt *models.SearchBackendOption) error {
+	switch opt.Kind {
+	case ai.SearchBackendBrave, ai.SearchBackendGoogle, ai.SearchBackendBing, ai.SearchBackendCanonical:
+	case ai.SearchBackendSearXNG:
+		if strings.TrimSpace(opt.BaseURL) == "" {
+			return fmt.Errorf("searxng backend requires base_url")
+		}
+	default:
+		return fmt.Errorf("unknown search backend kind %q", opt.Kind)
+	}
+	if opt.Weight < 0 {
+		return fmt.Errorf("weight must be >= 0")
+	}
+	if opt.DailyQuota < 0 {
+		return fmt.Errorf("daily_quota must be >= 0")
+	}
+	return nil
+}
+
+// SearchBackendUpdate carries a partial edit to a search backend. Nil fields
+// are left unchanged.
+type SearchBackendUpdate struct {
+	Kind               *string
+	BaseURL            *string
+	Label              *string
+	Enabled            *bool
+	Weight             *int
+	DailyQuota         *int
+	BreakerThreshold   *int
+	BreakerCooldownSec *int
+}
+
+// setIfPresent sets *dst to *v when v is non-nil.
+func setIfPresent[T any](dst *T, v *T) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+// ErrInvalidSearchBackend wraps a validation failure on an admin edit.
+var ErrInvalidSearchBackend = errors.New("invalid search backend")
+
+// AddBackend validates and persists a new backend, then applies it.
+func (m *SearchBackendManager) AddBackend(opt *models.SearchBackendOption) error {
+	if err := validateBackendOption(opt); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSearchBackend, err)
+	}
+	if err := m.repo.CreateBackend(opt); err != nil {
+		return err
+	}
+	m.Refresh()
+	return nil
+}
+
+// UpdateBackend applies the fields present in the update to a backend, then
+// applies the change. A changed identity or breaker tuning resets the backend's breaker.
+func (m *SearchBackendManager) UpdateBackend(id uint, in SearchBackendUpdate) (*models.SearchBackendOption, error) {
+	existing, err := m.repo.GetBackend(id)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("search backend %d not found", id)
+	}
+
+	setIfPresent(&existing.Kind, in.Kind)
+	setIfPresent(&existing.BaseURL, in.BaseURL)
+	setIfPresent(&existing.Label, in.Label)
+	setIfPresent(&existing.Enabled, in.Enabled)
+	setIfPresent(&existing.Weight, in.Weight)
+	setIfPresent(&existing.DailyQuota, in.DailyQuota)
+	setIfPresent(&existing.BreakerThreshold, in.BreakerThreshold)
+	setIfPresent(&existing.BreakerCooldownSec, in.BreakerCooldownSec)
+	if err := validateBackendOption(existing); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSearchBackend, err)
+	}
+
+	if err := m.repo.UpdateBackend(existing); err != nil {
+		return nil, err
+	}
+	m.Refresh()
+	return existing, nil
+}
+
+// DeleteBackend removes a backend and drops it from the running registry.
+func (m *SearchBackendManager) DeleteBackend(id uint) error {
+	if err := m.repo.DeleteBackend(id); err != nil {
+		return err
+	}
+	m.Refresh()
+	return nil
+}
+
+// seedDefaults populates an empty registry from whatever keys are configured:
+// Brave as the weighted primary, Bing and SearXNG as lighter-weight peers, and
+// the canonical index as a supplement-only backend. Google is seeded disabled
+// (CSE can no longer search the whole web) so it can be switched on later.
+func (m *SearchBackendManager) seedDefaults() {
+	var seeds []models.SearchBackendOption
+	if m.keys.BraveAPIKey != "" {
+		seeds = append(seeds, models.SearchBackendOption{Kind: ai.SearchBackendBrave, Label: "Brave Search", Enabled: true, Weight: 10})
+	}
+	if m.keys.BingAPIKey != "" {
+		seeds = append(seeds, models.SearchBackendOption{Kind: ai.SearchBackendBing, Label: "Bing Web Search", Enabled: true, Weight: 5})
+	}
+	if m.searxngURL != "" {
+		seeds = append(seeds, models.SearchBackendOption{Kind: ai.SearchBackendSearXNG, BaseURL: m.searxngURL, Label: "SearXNG", Enabled: true, Weight: 1})
+	}
+	if m.keys.GoogleAPIKey != "" && m.keys.GoogleCX != "" {
+		seeds = append(seeds, models.SearchBackendOption{Kind: ai.SearchBackendGoogle, Label: "Google CSE", Enabled: false, Weight: 1})
+	}
+	if m.local != nil {
+		seeds = append(seeds, models.SearchBackendOption{Kind: ai.SearchBackendCanonical, Label: "Canonical recipe index", Enabled: true, Weight: 0})
+	}
+	for i := range seeds {
+		if err := m.repo.CreateBackend(&seeds[i]); err != nil {
+			logger.Get().Warn("search backends: seed failed", zap.String("kind", seeds[i].Kind), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/models"
+)
+
+// fakeSearchBackendRepo is an in-memory SearchBackendRepo.
+type fakeSearchBackendRepo struct {
+	opts   []models.SearchBackendOption
+	nextID uint
+}
+
+func newFakeSearchBackendRepo() *fakeSearchBackendRepo { return &fakeSearchBackendRepo{nextID: 1} }
+
+func (r *fakeSearchBackendRepo) ListBackends() ([]models.SearchBackendOption, error) {
+	out := make([]models.SearchBackendOption, len(r.opts))
+	copy(out, r.opts)
+	return out, nil
+}
+
+func (r *fakeSearchBackendRepo) GetBackend(id uint) (*models.SearchBackendOption, error) {
+	for i := range r.opts {
+		if r.opts[i].ID == id {
+			cp := r.opts[i]
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeSearchBackendRepo) CreateBackend(opt *models.SearchBackendOption) error {
+	opt.ID = r.nextID
+	r.nextID++
+	r.opts = append(r.opts, *opt)
+	return nil
+}
+
+func (r *fakeSearchBackendRepo) UpdateBackend(opt *models.SearchBackendOption) error {
+	for i := range r.opts {
+		if r.opts[i].ID == opt.ID {
+			r.opts[i] = *opt
+			return nil
+		}
+	}
+	return errors.New("not found")
+}
+
+func (r *fakeSearchBackendRepo) DeleteBackend(id uint) error {
+	for i := range r.opts {
+		if r.opts[i].ID == id {
+			r.opts = append(r.opts[:i], r.opts[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *fakeSearchBackendRepo) IncrementUsage(id uint, day string) (int, error) {
+	for i := range r.opts {
+		if r.opts[i].ID == id {
+			if r.opts[i].UsageDay != day {
+				r.opts[i].UsedToday = 0
+				r.opts[i].UsageDay = day
+			}
+			r.opts[i].UsedToday++
+			return r.opts[i].UsedToday, nil
+		}
+	}
+	return 0, errors.New("not found")
+}
+
+// stubSearchBackend returns canned results (or an error) and counts calls.
+type stubSearchBackend struct {
+	kind    string
+	results []ai.SearchResult
+	err     error
+	calls   int
+}
+
+func (s *stubSearchBackend) Kind() string { return s.kind }
+
+func (s *stubSearchBackend) SearchRecipes(context.Context, string, int, int) ([]ai.SearchResult, error) {
+	s.calls++
+	return s.results, s.err
+}
+
+// stubSearchResults makes n recipe-like results on host.
+func stubSearchResults(host string, n int) []ai.SearchResult {
+	out := make([]ai.SearchResult, n)
+	for i := range out {
+		out[i] = ai.SearchResult{Title: fmt.Sprintf("Recipe %d", i), URL: fmt.Sprintf("https://%s/recipes/%d", host, i)}
+	}
+	return out
+}
+
+// newTestSearchManager builds a manager over pre-built backends, bypassing
+// BuildSearchBackend so no network clients are involved. randIntN always
+// picks the first weighted candidate, making the try order deterministic.
+func newTestSearchManager(repo *fakeSearchBackendRepo, backends map[uint]ai.SearchProvider) *SearchBackendManager {
+	m := NewSearchBackendManager(repo, ai.SearchKeys{}, nil, "")
+	m.randIntN = func(int) int { return 0 }
+	for _, opt := range repo.opts {
+		m.backends = append(m.backends, &searchBackendState{
+			opt:     opt,
+			backend: backends[opt.ID],
+			breaker: ai.NewCircuitBreaker(opt.BreakerThreshold, time.Duration(opt.BreakerCooldownSec)*time.Second),
+		})
+	}
+	return m
+}
+
+func TestSearchBackendManager_FailsOverOnError(t *testing.T) {
+	repo := newFakeSearchBackendRepo()
+	repo.CreateBackend(&models.SearchBackendOption{Kind: ai.SearchBackendBrave, Enabled: true, Weight: 10, BreakerThreshold: 1})
+	repo.CreateBackend(&models.SearchBackendOption{Kind: ai.SearchBackendBing, Enabled: true, Weight: 5})
+	primary := &stubSearchBackend{kind: ai.SearchBackendBrave, err: errors.New("timeout")}
+	secondary := &stubSearchBackend{kind: ai.SearchBackendBing, results: stubSearchResults("b.com", 10)}
+	m := newTestSearchManager(repo, map[uint]ai.SearchProvider{1: primary, 2: secondary})
+
+	results, err := m.SearchRecipes(context.Background(), "soup", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchRecipes: %v", err)
+	}
+	if len(results) != 10 || secondary.calls != 1 {
+		t.Fatalf("got %d results, secondary calls %d", len(results), secondary.calls)
+	}
+
+	// Primary's breaker (threshold 1) is now open, so it is skipped.
+	if _, err := m.SearchRecipes(context.Background(), "soup", 10, 0); err != nil {
+		t.Fatalf("second SearchRecipes: %v", err)
+	}
+	if primary.calls != 1 {
+		t.Errorf("primary calls = %d, want 1 (breaker open)", primary.calls)
+	}
+
+	statuses, _ := m.ListBackends()
+	if statuses[0].Health.State != ai.BreakerOpen || statuses[0].Health.LastError != "timeout" {
+		t.Errorf("primary health = %+v", statuses[0].Health)
+	}
+}
+
+func TestSearchBackendManager_QuotaErrorTripsBreaker(t *testing.T) {
+	repo := newFakeSearchBackendRepo()
+	repo.CreateBackend(&models.SearchBackendOption{Kind: ai.SearchBackendBrave, Enabled: true, Weight: 10, BreakerThreshold: 5})
+	repo.CreateBackend(&models.SearchBackendOption{Kind: ai.SearchBackendBing, Enabled: true, Weight: 5})
+	primary := &stubSearchBackend{kind: ai.SearchBackendBrave, err: fmt.Errorf("brave: %w", ai.ErrSearchQuotaExhausted)}
+	secondary := &stubSearchBackend{kind: ai.SearchBackendBing, results: stubSearchResults("b.com", 10)}
+	m := newTestSearchManager(repo, map[uint]ai.SearchProvider{1: primary, 2: secondary})
+
+	m.SearchRecipes(context.Background(), "soup", 10, 0)
+	if m.backends[0].breaker.State() != ai.BreakerOpen {
+		t.Errorf("quota exhaustion should trip the breaker immediately")
+	}
+}
+
+func TestSearchBackendManager_SupplementsThinFirstPage(t *testing.T) {
+	repo := newFakeSearchBackendRepo()
+	repo.CreateBackend(&models.SearchBackendOption{Kind: ai.SearchBackendBrave, Enabled: true, Weight: 10})
+	repo.CreateBackend(&models.SearchBackendOption{Kind: ai.SearchBackendCanonical, Enabled: true, Weight: 0})
+	primary := &stubSearchBackend{kind: ai.SearchBackendBrave, results: []ai.SearchResult{
+		{Title: "Soup forum thread", URL: "https://forum.com/t/1"},
+		{Title: "Best soup", URL: "https://a.com/recipes/soup?utm_source=x"},
+	}}
+	supplement := &stubSearchBackend{kind: ai.SearchBackendCanonical, results: []ai.SearchResult{
+		{Title: "Best soup", URL: "https://A.com/recipes/soup/"}, // dup of primary's hit
+		{Title: "Tomato soup", URL: "https://c.com/recipes/tomato"},
+	}}
+	m := newTestSearchManager(repo, map[uint]ai.SearchProvider{1: primary, 2: supplement})
+
+	results, err := m.SearchRecipes(context.Background(), "soup", 3, 0)
+	if err != nil {
+		t.Fatalf("SearchRecipes: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3: %+v", len(results), results)
+	}
+	if results[0].URL != "https://a.com/recipes/soup?utm_source=x" || results[1].URL != "https://c.com/recipes/tomato" {
+		t.Errorf("recipe-like hits should lead, deduped: %+v", results)
+	}
+	if results[2].URL != "https://forum.com/t/1" {
+		t.Errorf("non-recipe hit should trail: %+v", results)
+	}
+
+	// Later pages take the first backend that answers.
+	supplement.calls = 0
+	m.SearchRecipes(context.Background(), "soup", 3, 10)
+	if supplement.calls != 0 {
+		t.Errorf("offset page should not consult supplements")
+	}
+}
+
+func TestSearchBackendManager_DailyQuota(t *testing.T) {
+	repo := newFakeSearchBackendRepo()
+	repo.CreateBackend(&models.SearchBackendOption{Kind: ai.SearchBackendBrave, Enabled: true, Weight: 10, DailyQuota: 1})
+	primary := &stubSearchBackend{kind: ai.SearchBackendBrave, results: stubSearchResults("a.com", 5)}
+	m := newTestSearchManager(repo, map[uint]ai.SearchProvider{1: primary})
+
+	if _, err := m.SearchRecipes(context.Background(), "soup", 5, 0); err != nil {
+		t.Fatalf("first search: %v", err)
+	}
+	if _, err := m.SearchRecipes(context.Background(), "soup", 5, 0); err == nil {
+		t.Fatal("second search should fail: quota spent and no other backend")
+	}
+	if primary.calls != 1 {
+		t.Errorf("primary calls = %d, want 1", primary.calls)
+	}
+
+	// A new UTC day restores the quota.
+	m.now = func() time.Time { return time.Now().Add(24 * time.Hour) }
+	if _, err := m.SearchRecipes(context.Background(), "soup", 5, 0); err != nil {
+		t.Fatalf("next-day search: %v", err)
+	}
+}
+
+func TestSearchBackendManager_SeedAndValidate(t *testing.T) {
+	repo := newFakeSearchBackendRepo()
+	m := NewSearchBackendManager(repo, ai.SearchKeys{BraveAPIKey: "b"}, &CanonicalSearchBackend{}, "http://searxng:8080")
+	m.Load()
+
+	if len(repo.opts) != 3 {
+		t.Fatalf("seeded %d backends, want brave+searxng+canonical: %+v", len(repo.opts), repo.opts)
+	}
+	if repo.opts[0].Kind != ai.SearchBackendBrave || repo.opts[2].Kind != ai.SearchBackendCanonical || repo.opts[2].Weight != 0 {
+		t.Errorf("seeds = %+v", repo.opts)
+	}
+
+	err := m.AddBackend(&models.SearchBackendOption{Kind: ai.SearchBackendSearXNG})
+	if !errors.Is(err, ErrInvalidSearchBackend) {
+		t.Errorf("searxng without base_url: err = %v", err)
+	}
+	err = m.AddBackend(&models.SearchBackendOption{Kind: "altavista"})
+	if !errors.Is(err, ErrInvalidSearchBackend) {
+		t.Errorf("unknown kind: err = %v", err)
+	}
+
+	// Bing without a key saves but reports why it can't run.
+	if err := m.AddBackend(&models.SearchBackendOption{Kind: ai.SearchBackendBing, Enabled: true, Weight: 1}); err != nil {
+		t.Fatalf("AddBackend bing: %v", err)
+	}
+	statuses, _ := m.ListBackends()
+	if last := statuses[len(statuses)-1]; last.BuildError == "" {
+		t.Errorf("bing without key should report a build error: %+v", last)
+	}
+}
+
+// fakeCanonicalSearchRepo records the terms it was asked for.
+type fakeCanonicalSearchRepo struct {
+	terms   []string
+	entries []models.CanonicalRecipe
+}
+
+func (r *fakeCanonicalSearchRepo) SearchByTitle(terms []string, limit, offset int) ([]models.CanonicalRecipe, error) {
+	r.terms = terms
+	return r.entries, nil
+}
+
+func TestCanonicalSearchBackend_TermsAndExcludes(t *testing.T) {
+	repo := &fakeCanonicalSearchRepo{entries: []models.CanonicalRecipe{
+		{OriginalURL: "https://a.com/peanut-noodles", RecipeData: models.RecipeDef{Title: "Peanut Noodles"}},
+		{OriginalURL: "https://b.com/sesame-noodles", RecipeData: models.RecipeDef{
+			Title:       "Sesame Noodles",
+			Ingredients: models.Ingredients{{Name: "noodles"}, {Name: "sesame oil"}},
+		}},
+	}}
+	results, err := NewCanonicalSearchBackend(repo).SearchRecipes(context.Background(), "Noodles recipe -peanut", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchRecipes: %v", err)
+	}
+	if len(repo.terms) != 1 || repo.terms[0] != "noodles" {
+		t.Errorf("terms = %v, want [noodles]", repo.terms)
+	}
+	if len(results) != 1 || results[0].Title != "Sesame Noodles" {
+		t.Fatalf("results = %+v", results)
+	}
+	if results[0].Source != "b.com" || results[0].Description != "Ingredients: noodles, sesame oil" {
+		t.Errorf("result = %+v", results[0])
+	}
+}
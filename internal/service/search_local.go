@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Local canonical search tuning. The embedding arm compares the query to each
+// entry's title+ingredients embedding, so similarities run lower than the
+// query-to-query semantic cache.
+const (
+	// localMaxDistance is the widest cosine distance the embedding arm returns.
+	localMaxDistance = 0.35
+	// localStrongSimilarity is the similarity at which an embedding-only hit
+	// counts as high quality.
+	localStrongSimilarity = 0.82
+	// localEnoughHits is how many high-quality local hits let a first page
+	// skip the web entirely (capped by the page size).
+	localEnoughHits = 5
+	// localMinIngredients and localMinInstructions are the floor for an entry
+	// to count as a complete recipe.
+	localMinIngredients  = 3
+	localMinInstructions = 2
+)
+
+// LocalRecipeSearchRepo is the canonical-recipe index SearchService consults
+// before the web: nearest neighbours by embedding and a full-text match over
+// the stored recipe data. Backed by repository.CanonicalRecipeRepository.
+type LocalRecipeSearchRepo interface {
+	SearchByEmbedding(embedding []float32, maxDistance float64, limit int) ([]repository.CanonicalRecipeHit, error)
+	SearchFullText(query string, limit int) ([]repository.CanonicalRecipeHit, error)
+}
+
+// localHit is one canonical entry matched by either arm of the local search.
+type localHit struct {
+	result     ai.SearchResult
+	similarity float64 // embedding arm; 0 when it didn't match
+	textual    bool    // matched the full-text arm
+	complete   bool    // title, enough ingredients and steps
+}
+
+// strong reports whether a hit is good enough to serve without the web: a
+// complete recipe that matched both arms, or matched the query's words, or
+// sits very close to it in embedding space.
+func (h localHit) strong() bool {
+	return h.complete && (h.textual || h.similarity >= localStrongSimilarity)
+}
+
+// searchLocal runs both arms of the canonical index search for a first page
+// and returns the merged hits, best first, and how many of them are strong
+// (strong hits sort ahead of the rest, so they are the leading ones).
+// "-term" negations filter matching titles and ingredients out. Failures are
+// logged and treated as no hits — the web is always the fallback.
+func (s *SearchService) searchLocal(query string, embedding []float32, limit int) ([]ai.SearchResult, int) {
+	if s.LocalRepo == nil {
+		return nil, 0
+	}
+	terms, excludes := canonicalSearchTerms(query)
+	if len(terms) == 0 {
+		return nil, 0
+	}
+	fetch := limit + len(excludes)*2
+
+	byURL := make(map[string]*localHit)
+	var order []*localHit
+	add := func(hit repository.CanonicalRecipeHit) *localHit {
+		e := hit.Recipe
+		if e.OriginalURL == "" || localExcluded(e.RecipeData, excludes) {
+			return nil
+		}
+		key := dismissKey(e.OriginalURL)
+		if h, ok := byURL[key]; ok {
+			return h
+		}
+		h := &localHit{
+			result: canonicalToSearchResult(e),
+			complete: strings.TrimSpace(e.RecipeData.Title) != "" &&
+				len(e.RecipeData.Ingredients) >= localMinIngredients &&
+				len(e.RecipeData.Instructions) >= localMinInstructions,
+		}
+		byURL[key] = h
+		order = append(order, h)
+		return h
+	}
+
+	if embedding != nil {
+		hits, err := s.LocalRepo.SearchByEmbedding(embedding, localMaxDistance, fetch)
+		if err != nil {
+			logger.Get().Warn("local embedding search failed", zap.Error(err))
+		}
+		for _, hit := range hits {
+			if h := add(hit); h != nil && hit.Score > h.similarity {
+				h.similarity = hit.Score
+			}
+		}
+	}
+
+	textQuery := strings.Join(terms, " ")
+	for _, ex := range excludes {
+		textQuery += " -" + ex
+	}
+	hits, err := s.LocalRepo.SearchFullText(textQuery, fetch)
+	if err != nil {
+		logger.Get().Warn("local full-text search failed", zap.Error(err))
+	}
+	for _, hit := range hits {
+		if h := add(hit); h != nil {
+			h.textual = true
+		}
+	}
+
+	// Strong hits first; among equals, matched-both before either arm alone,
+	// then by similarity. Stable so full-text rank order survives ties.
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if a.strong() != b.strong() {
+			return a.strong()
+		}
+		if both := a.textual && a.similarity > 0; both != (b.textual && b.similarity > 0) {
+			return both
+		}
+		return a.similarity > b.similarity
+	})
+
+	results := make([]ai.SearchResult, 0, min(len(order), limit))
+	strong := 0
+	for _, h := range order {
+		if len(results) >= limit {
+			break
+		}
+		if h.strong() {
+			strong++
+		}
+		results = append(results, h.result)
+	}
+	return results, strong
+}
+
+// localExcluded reports whether a recipe's title or any ingredient name
+// contains one of the (lowercase) excluded terms.
+func localExcluded(def models.RecipeDef, excludes []string) bool {
+	if len(excludes) == 0 {
+		return false
+	}
+	if titleHasAny(def.Title, excludes) {
+		return true
+	}
+	for _, ing := range def.Ingredients {
+		if titleHasAny(ing.Name, excludes) {
+			return true
+		}
+	}
+	return false
+}
+
+// blendResults puts local hits ahead of web results, dropping web results
+// that point at a page the local index already returned, up to count.
+func blendResults(local, web []ai.SearchResult, count int) []ai.SearchResult {
+	out := make([]ai.SearchResult, 0, count)
+	seen := make(map[string]bool, len(local))
+	for _, r := range local {
+		if len(out) >= count {
+			return out
+		}
+		seen[dismissKey(r.URL)] = true
+		out = append(out, r)
+	}
+	for _, r := range web {
+		if len(out) >= count {
+			break
+		}
+		if seen[dismissKey(r.URL)] {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// markWebOrigin tags results without an origin as web results. Backends that
+// know better (the canonical backend) set their own.
+func markWebOrigin(results []ai.SearchResult) []ai.SearchResult {
+	for i := range results {
+		if results[i].Origin == "" {
+			results[i].Origin = ai.SearchOriginWeb
+		}
+	}
+	return results
+}
+
+// queryEmbedding embeds a normalized query, or returns nil when no embedding
+// provider is configured or the call fails.
+func (s *SearchService) queryEmbedding(ctx context.Context, normalized string) []float32 {
+	if s.EmbedProvider == nil {
+		return nil
+	}
+	embedding, err := s.EmbedProvider.GenerateEmbedding(ctx, normalized)
+	if err != nil {
+		logger.Get().Warn("failed to generate embedding for search query", zap.Error(err))
+		return nil
+	}
+	return embedding
+}
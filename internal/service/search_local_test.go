@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/config"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// fakeLocalRepo serves fixed hits from both arms and records the full-text
+// query it was given.
+type fakeLocalRepo struct {
+	semantic  []repository.CanonicalRecipeHit
+	text      []repository.CanonicalRecipeHit
+	textQuery string
+}
+
+func (f *fakeLocalRepo) SearchByEmbedding(embedding []float32, maxDistance float64, limit int) ([]repository.CanonicalRecipeHit, error) {
+	return f.semantic, nil
+}
+
+func (f *fakeLocalRepo) SearchFullText(query string, limit int) ([]repository.CanonicalRecipeHit, error) {
+	f.textQuery = query
+	return f.text, nil
+}
+
+// localEntry builds a canonical hit; complete entries have enough ingredients
+// and steps to count as strong.
+func localEntry(id uint, title string, complete bool, score float64, ingredients ...string) repository.CanonicalRecipeHit {
+	def := models.RecipeDef{Title: title}
+	for _, name := range ingredients {
+		def.Ingredients = append(def.Ingredients, models.Ingredient{Name: name})
+	}
+	if complete {
+		for len(def.Ingredients) < localMinIngredients {
+			def.Ingredients = append(def.Ingredients, models.Ingredient{Name: "salt"})
+		}
+		def.Instructions = []string{"Prep.", "Cook."}
+	}
+	return repository.CanonicalRecipeHit{
+		Recipe: models.CanonicalRecipe{
+			Model:       gorm.Model{ID: id},
+			OriginalURL: fmt.Sprintf("https://local.example/%d", id),
+			RecipeData:  def,
+		},
+		Score: score,
+	}
+}
+
+func staticEmbedder() *testutil.MockEmbeddingProvider {
+	return &testutil.MockEmbeddingProvider{
+		GenerateEmbeddingFunc: func(ctx context.Context, text string) ([]float32, error) {
+			return []float32{0.1, 0.2, 0.3}, nil
+		},
+	}
+}
+
+func TestSearchRecipes_LocalHitsSkipWeb(t *testing.T) {
+	searchCalled := false
+	searchProvider := &testutil.MockSearchProvider{
+		SearchRecipesFunc: func(ctx context.Context, query string, count int, offset int) ([]ai.SearchResult, error) {
+			searchCalled = true
+			return testResults(), nil
+		},
+	}
+	local := &fakeLocalRepo{}
+	for i := uint(1); i <= 5; i++ {
+		local.text = append(local.text, localEntry(i, fmt.Sprintf("Chicken Curry %d", i), true, 0.5))
+	}
+
+	svc := NewSearchService(&config.Config{}, searchProvider, nil, nil)
+	svc.LocalRepo = local
+	result, err := svc.SearchRecipes(context.Background(), "chicken curry recipe", 10, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if searchCalled {
+		t.Error("search provider should not be called when the local index has enough strong hits")
+	}
+	if !result.FromCache || result.HasMore {
+		t.Errorf("FromCache/HasMore = %v/%v, want true/false", result.FromCache, result.HasMore)
+	}
+	if len(result.Results) != 5 || result.Results[0].Origin != ai.SearchOriginLocal {
+		t.Fatalf("results = %+v, want 5 local results", result.Results)
+	}
+	if local.textQuery != "chicken curry" {
+		t.Errorf("full-text query = %q, want the terms without \"recipe\"", local.textQuery)
+	}
+}
+
+func TestSearchRecipes_LocalBlendsWithWeb(t *testing.T) {
+	searchProvider := &testutil.MockSearchProvider{
+		SearchRecipesFunc: func(ctx context.Context, query string, count int, offset int) ([]ai.SearchResult, error) {
+			return []ai.SearchResult{
+				{Title: "Duplicate", URL: "https://local.example/1"},
+				{Title: "Web Curry", URL: "https://web.example/curry"},
+			}, nil
+		},
+	}
+	local := &fakeLocalRepo{
+		// One strong hit found by both arms, one incomplete embedding-only hit.
+		semantic: []repository.CanonicalRecipeHit{
+			localEntry(1, "Chicken Curry", true, 0.7),
+			localEntry(2, "Curry Sketch", false, 0.9),
+		},
+		text: []repository.CanonicalRecipeHit{localEntry(1, "Chicken Curry", true, 0.4)},
+	}
+
+	svc := NewSearchService(&config.Config{}, searchProvider, nil, nil)
+	svc.EmbedProvider = staticEmbedder()
+	svc.LocalRepo = local
+	result, err := svc.SearchRecipes(context.Background(), "chicken curry", 10, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.FromCache {
+		t.Error("expected FromCache=false when the web was searched")
+	}
+	got := result.Results
+	if len(got) != 2 {
+		t.Fatalf("results = %+v, want the strong local hit then the new web result", got)
+	}
+	if got[0].Title != "Chicken Curry" || got[0].Origin != ai.SearchOriginLocal {
+		t.Errorf("first result = %+v, want the local hit", got[0])
+	}
+	if got[1].Title != "Web Curry" || got[1].Origin != ai.SearchOriginWeb {
+		t.Errorf("second result = %+v, want the web result marked web", got[1])
+	}
+}
+
+func TestSearchRecipes_LocalServedWhenWebUnavailable(t *testing.T) {
+	local := &fakeLocalRepo{semantic: []repository.CanonicalRecipeHit{localEntry(1, "Lentil Soup", false, 0.7)}}
+
+	failing := &testutil.MockSearchProvider{
+		SearchRecipesFunc: func(ctx context.Context, query string, count int, offset int) ([]ai.SearchResult, error) {
+			return nil, errors.New("no search keys configured")
+		},
+	}
+	for name, provider := range map[string]ai.SearchProvider{"failing": failing, "nil": nil} {
+		svc := NewSearchService(&config.Config{}, provider, nil, nil)
+		svc.EmbedProvider = staticEmbedder()
+		svc.LocalRepo = local
+		result, err := svc.SearchRecipes(context.Background(), "lentil soup", 10, 0)
+		if err != nil {
+			t.Fatalf("%s provider: unexpected error: %v", name, err)
+		}
+		if len(result.Results) != 1 || result.Results[0].Origin != ai.SearchOriginLocal {
+			t.Errorf("%s provider: results = %+v, want the local hit", name, result.Results)
+		}
+	}
+}
+
+func TestSearchRecipes_LocalHonoursExcludes(t *testing.T) {
+	local := &fakeLocalRepo{}
+	for i := uint(1); i <= 5; i++ {
+		local.text = append(local.text, localEntry(i, fmt.Sprintf("Pad Thai %d", i), true, 0.5))
+	}
+	local.text = append(local.text, localEntry(6, "Pad Thai Classic", true, 0.9, "rice noodles", "crushed peanuts"))
+
+	svc := NewSearchService(&config.Config{}, nil, nil, nil)
+	svc.LocalRepo = local
+	result, err := svc.SearchRecipes(context.Background(), "pad thai -peanuts", 10, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if local.textQuery != "pad thai -peanuts" {
+		t.Errorf("full-text query = %q, want the exclude passed through", local.textQuery)
+	}
+	for _, r := range result.Results {
+		if r.Title == "Pad Thai Classic" {
+			t.Fatal("recipe with an excluded ingredient was served")
+		}
+	}
+	if len(result.Results) != 5 {
+		t.Errorf("got %d results, want 5", len(result.Results))
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -50,7 +51,7 @@ func TestSearchRecipes_CacheHit(t *testing.T) {
 		},
 	}
 	cacheRepo := &testutil.MockSearchCacheRepo{
-		GetByNormalizedQueryFunc: func(query string) (*models.SearchCache, error) {
+		GetByNormalizedQueryFunc: func(query, market string) (*models.SearchCache, error) {
 			return freshCacheEntry(), nil
 		},
 	}
@@ -80,7 +81,7 @@ func TestSearchRecipes_CacheStale(t *testing.T) {
 		},
 	}
 	cacheRepo := &testutil.MockSearchCacheRepo{
-		GetByNormalizedQueryFunc: func(query string) (*models.SearchCache, error) {
+		GetByNormalizedQueryFunc: func(query, market string) (*models.SearchCache, error) {
 			return staleCacheEntry(), nil
 		},
 	}
@@ -107,7 +108,7 @@ func TestSearchRecipes_CacheMiss(t *testing.T) {
 		},
 	}
 	cacheRepo := &testutil.MockSearchCacheRepo{
-		GetByNormalizedQueryFunc: func(query string) (*models.SearchCache, error) {
+		GetByNormalizedQueryFunc: func(query, market string) (*models.SearchCache, error) {
 			return nil, fmt.Errorf("not found")
 		},
 	}
@@ -158,10 +159,10 @@ func TestSearchRecipes_SemanticHit(t *testing.T) {
 		},
 	}
 	cacheRepo := &testutil.MockSearchCacheRepo{
-		GetByNormalizedQueryFunc: func(query string) (*models.SearchCache, error) {
+		GetByNormalizedQueryFunc: func(query, market string) (*models.SearchCache, error) {
 			return nil, fmt.Errorf("not found") // no exact match
 		},
-		FindSimilarFunc: func(embedding []float32, threshold float64, limit int) ([]models.SearchCache, error) {
+		FindSimilarFunc: func(embedding []float32, threshold float64, limit int, market string) ([]models.SearchCache, error) {
 			return []models.SearchCache{*freshCacheEntry()}, nil // semantic match
 		},
 	}
@@ -195,10 +196,10 @@ func TestSearchRecipes_SemanticMiss(t *testing.T) {
 		},
 	}
 	cacheRepo := &testutil.MockSearchCacheRepo{
-		GetByNormalizedQueryFunc: func(query string) (*models.SearchCache, error) {
+		GetByNormalizedQueryFunc: func(query, market string) (*models.SearchCache, error) {
 			return nil, fmt.Errorf("not found")
 		},
-		FindSimilarFunc: func(embedding []float32, threshold float64, limit int) ([]models.SearchCache, error) {
+		FindSimilarFunc: func(embedding []float32, threshold float64, limit int, market string) ([]models.SearchCache, error) {
 			return nil, nil // no similar entries
 		},
 	}
@@ -232,7 +233,7 @@ func TestSearchRecipes_EmbeddingFailure(t *testing.T) {
 		},
 	}
 	cacheRepo := &testutil.MockSearchCacheRepo{
-		GetByNormalizedQueryFunc: func(query string) (*models.SearchCache, error) {
+		GetByNormalizedQueryFunc: func(query, market string) (*models.SearchCache, error) {
 			return nil, fmt.Errorf("not found")
 		},
 	}
@@ -266,7 +267,7 @@ func TestSearchRecipes_NilEmbedProvider(t *testing.T) {
 		},
 	}
 	cacheRepo := &testutil.MockSearchCacheRepo{
-		GetByNormalizedQueryFunc: func(query string) (*models.SearchCache, error) {
+		GetByNormalizedQueryFunc: func(query, market string) (*models.SearchCache, error) {
 			return nil, fmt.Errorf("not found")
 		},
 	}
@@ -299,7 +300,7 @@ func TestSearchRecipes_OffsetBypassesCache(t *testing.T) {
 		},
 	}
 	cacheRepo := &testutil.MockSearchCacheRepo{
-		GetByNormalizedQueryFunc: func(query string) (*models.SearchCache, error) {
+		GetByNormalizedQueryFunc: func(query, market string) (*models.SearchCache, error) {
 			t.Error("cache should not be consulted for offset > 0")
 			return nil, fmt.Errorf("not found")
 		},
@@ -407,6 +408,50 @@ func TestSearchResultConversion(t *testing.T) {
 	}
 }
 
+// --- Locale partitioning ---
+
+func TestSearchRecipes_CacheKeyedByMarket(t *testing.T) {
+	var gotMarket string
+	var providerLocale ai.Locale
+	searchProvider := &testutil.MockSearchProvider{
+		SearchRecipesFunc: func(ctx context.Context, query string, count int, offset int) ([]ai.SearchResult, error) {
+			providerLocale = ai.LocaleFromContext(ctx)
+			return testResults(), nil
+		},
+	}
+	saved := make(chan *models.SearchCache, 1)
+	cacheRepo := &testutil.MockSearchCacheRepo{
+		GetByNormalizedQueryFunc: func(query, market string) (*models.SearchCache, error) {
+			gotMarket = market
+			return nil, errors.New("not found")
+		},
+		UpsertFunc: func(entry *models.SearchCache) error {
+			saved <- entry
+			return nil
+		},
+	}
+
+	svc := NewSearchService(&config.Config{}, searchProvider, nil, cacheRepo)
+	ctx := ai.WithLocale(context.Background(), ai.Locale{Language: "en-GB"})
+	if _, err := svc.SearchRecipes(ctx, "Courgette Bake", 10, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotMarket != "en-gb" {
+		t.Errorf("cache lookup market = %q, want en-gb", gotMarket)
+	}
+	if providerLocale.CountryCode() != "GB" {
+		t.Errorf("provider locale = %+v, want GB", providerLocale)
+	}
+	select {
+	case entry := <-saved:
+		if entry.Market != "en-gb" {
+			t.Errorf("saved market = %q, want en-gb", entry.Market)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("cache entry was not saved")
+	}
+}
+
 // --- Phase 3: Background task tests ---
 
 func TestRefreshHotQueries(t *testing.T) {
@@ -11,10 +11,19 @@ import (
 	"go.uber.org/zap"
 )
 
+// AISpendRepo reports the metered AI cost attributed to a user. Backed by
+// repository.AIUsageRepository in production; faked in tests.
+type AISpendRepo interface {
+	SumUserCostSince(userID uint, t time.Time) (float64, error)
+}
+
 // SubscriptionService handles subscription management and usage limits.
 type SubscriptionService struct {
 	Cfg  *config.Config
 	Repo repository.UserRepo
+	// Spend, when set, enforces the per-tier monthly AI cost ceiling in
+	// CheckLimit alongside the count-based limits. Nil disables it.
+	Spend AISpendRepo
 }
 
 // NewSubscriptionService creates a new SubscriptionService.
@@ -109,23 +118,76 @@ func (s *SubscriptionService) DecrementUsage(userID uint, usageType string) erro
 	return s.Repo.DecrementSubscriptionUsage(userID, column)
 }
 
-// CheckLimit returns true if the user is within their usage limits for the given type.
+// CheckLimit returns true if the user is within their usage limits for the
+// given type: the type's monthly count and, when Spend is set, the tier's
+// monthly AI cost ceiling.
 func (s *SubscriptionService) CheckLimit(userID uint, usageType string) (bool, error) {
 	sub, err := s.GetSubscription(userID)
 	if err != nil {
 		return false, err
 	}
 
+	var allowed bool
 	switch usageType {
 	case "allergen":
-		return sub.CanUseAllergenAnalysis(), nil
+		allowed = sub.CanUseAllergenAnalysis()
 	case "search":
-		return sub.CanUseWebSearch(), nil
+		allowed = sub.CanUseWebSearch()
 	case "ai_generation":
-		return sub.CanUseAIGeneration(), nil
+		allowed = sub.CanUseAIGeneration()
 	case "video_import":
-		return sub.CanUseVideoImport(), nil
+		allowed = sub.CanUseVideoImport()
 	default:
 		return false, fmt.Errorf("unknown usage type: %s", usageType)
 	}
+	if !allowed || s.Spend == nil || s.monthlyCapUSD(sub.Tier) <= 0 {
+		return allowed, nil
+	}
+
+	spend, err := s.MonthlySpend(sub)
+	if err != nil {
+		return false, err
+	}
+	return !spend.OverCap(), nil
+}
+
+// MonthlySpend is a user's metered AI cost in the current subscription month
+// against their tier's ceiling.
+type MonthlySpend struct {
+	SpentUSD float64   `json:"spent_usd"`
+	CapUSD   float64   `json:"cap_usd"` // 0 = no ceiling
+	Since    time.Time `json:"since"`
+}
+
+// OverCap reports whether the ceiling is set and has been reached.
+func (m MonthlySpend) OverCap() bool {
+	return m.CapUSD > 0 && m.SpentUSD >= m.CapUSD
+}
+
+// MonthlySpend sums the user's AI cost since the start of the subscription's
+// current month (one month before MonthlyResetAt). Without a Spend repo it
+// reports no spend and no ceiling.
+func (s *SubscriptionService) MonthlySpend(sub *models.Subscription) (MonthlySpend, error) {
+	spend := MonthlySpend{Since: sub.MonthlyResetAt.AddDate(0, -1, 0)}
+	if s.Spend == nil {
+		return spend, nil
+	}
+	spend.CapUSD = s.monthlyCapUSD(sub.Tier)
+	spent, err := s.Spend.SumUserCostSince(sub.UserID, spend.Since)
+	if err != nil {
+		return spend, fmt.Errorf("failed to sum AI spend: %w", err)
+	}
+	spend.SpentUSD = spent
+	return spend, nil
+}
+
+// monthlyCapUSD is the configured AI cost ceiling for a tier (0 = none).
+func (s *SubscriptionService) monthlyCapUSD(tier models.SubscriptionTier) float64 {
+	if s.Cfg == nil {
+		return 0
+	}
+	if tier == models.TierPremium {
+		return s.Cfg.EnvVars.AIMonthlyCapPremiumUSD
+	}
+	return s.Cfg.EnvVars.AIMonthlyCapFreeUSD
 }
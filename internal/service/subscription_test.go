@@ -280,3 +280,60 @@ func TestUpgradeSubscription_NotAvailable(t *testing.T) {
 		t.Error("UpgradeSubscription should error until paid plans are wired up")
 	}
 }
+
+// fakeSpendRepo reports a fixed spend and records the window start it was asked for.
+type fakeSpendRepo struct {
+	spent float64
+	since time.Time
+}
+
+func (f *fakeSpendRepo) SumUserCostSince(userID uint, t time.Time) (float64, error) {
+	f.since = t
+	return f.spent, nil
+}
+
+func TestCheckLimit_MonthlySpendCap(t *testing.T) {
+	repo := testutil.NewMockUserRepo()
+	user := testutil.TestUser()
+	resetAt := time.Now().Add(10 * 24 * time.Hour)
+	user.Subscription = &models.Subscription{
+		Model:          gorm.Model{ID: 1},
+		UserID:         user.ID,
+		Tier:           models.TierFree,
+		MonthlyResetAt: resetAt,
+	}
+	repo.Users[user.ID] = user
+
+	cfg := &config.Config{}
+	cfg.EnvVars.AIMonthlyCapFreeUSD = 1
+	cfg.EnvVars.AIMonthlyCapPremiumUSD = 20
+	spend := &fakeSpendRepo{spent: 0.4}
+	svc := NewSubscriptionService(cfg, repo)
+	svc.Spend = spend
+
+	if allowed, err := svc.CheckLimit(user.ID, "ai_generation"); err != nil || !allowed {
+		t.Fatalf("under cap: CheckLimit = %v, %v; want true", allowed, err)
+	}
+	if want := resetAt.AddDate(0, -1, 0); !spend.since.Equal(want) {
+		t.Errorf("spend window starts %v, want one month before the reset (%v)", spend.since, want)
+	}
+
+	// Over the free ceiling: refused even though the count limits have room.
+	spend.spent = 1.2
+	if allowed, err := svc.CheckLimit(user.ID, "search"); err != nil || allowed {
+		t.Fatalf("over cap: CheckLimit = %v, %v; want false", allowed, err)
+	}
+
+	// The same spend is within the premium ceiling.
+	user.Subscription.Tier = models.TierPremium
+	if allowed, err := svc.CheckLimit(user.ID, "search"); err != nil || !allowed {
+		t.Fatalf("premium: CheckLimit = %v, %v; want true", allowed, err)
+	}
+
+	// A zero ceiling disables the check.
+	cfg.EnvVars.AIMonthlyCapPremiumUSD = 0
+	spend.spent = 1000
+	if allowed, err := svc.CheckLimit(user.ID, "search"); err != nil || !allowed {
+		t.Fatalf("no ceiling: CheckLimit = %v, %v; want true", allowed, err)
+	}
+}
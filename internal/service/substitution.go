@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/repository"
+	"github.com/windoze95/saltybytes-api/internal/units"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxSubstitutionConstraints bounds the free-form things to avoid.
+	maxSubstitutionConstraints = 20
+	// maxPantryItems bounds the pantry list sent to the model.
+	maxPantryItems = 100
+	// maxAppliedSubstitutions bounds the swaps folded into one fork.
+	maxAppliedSubstitutions = 30
+)
+
+// Substitution errors. All but ErrSubstitutionsUnavailable are safe to show
+// the user.
+var (
+	ErrNoSubstitutionConstraints     = errors.New("give at least one family member or thing to avoid")
+	ErrTooManySubstitutionInputs     = fmt.Errorf("at most %d things to avoid and %d pantry items", maxSubstitutionConstraints, maxPantryItems)
+	ErrSubstitutionMemberNotFound    = errors.New("family member not found")
+	ErrRecipeHasNoIngredients        = errors.New("recipe has no ingredients to substitute")
+	ErrNoSubstitutions               = errors.New("choose at least one substitution to apply")
+	ErrTooManySubstitutions          = fmt.Errorf("at most %d substitutions can be applied at once", maxAppliedSubstitutions)
+	ErrUnknownSubstitutionIngredient = errors.New("substitution names an ingredient that is not in the recipe")
+	ErrSubstitutionsUnavailable      = errors.New("substitutions are not available")
+)
+
+// SubstitutionService proposes ingredient swaps that make a recipe fit a
+// family member's allergies, intolerances and restrictions, or ad-hoc
+// constraints like "no eggs", preferring what is in the pantry. Quantities
+// keep the original's proportions, re-expressed in cooking-friendly units.
+// Applying a proposal forks the recipe with the swaps as the prompt.
+type SubstitutionService struct {
+	TextProvider ai.TextProvider
+	Recipes      repository.RecipeRepo
+	Families     repository.FamilyRepo
+	// Allergens, when set, contributes the recipe's cached allergen analysis
+	// so swaps also cover allergens hidden in an ingredient.
+	Allergens repository.AllergenRepo
+	Forks     *RecipeService
+}
+
+// NewSubstitutionService creates a new SubstitutionService.
+func NewSubstitutionService(textProvider ai.TextProvider, recipes repository.RecipeRepo, families repository.FamilyRepo, forks *RecipeService) *SubstitutionService {
+	return &SubstitutionService{TextProvider: textProvider, Recipes: recipes, Families: families, Forks: forks}
+}
+
+// SubstitutionConstraints is what a proposal must design around. MemberIDs
+// are members of the user's family whose dietary profiles apply.
+type SubstitutionConstraints struct {
+	MemberIDs []uint   `json:"member_ids"`
+	Avoid     []string `json:"avoid"`
+	Pantry    []string `json:"pantry"`
+}
+
+// Substitution is one ingredient swap. Original is the ingredient's line in
+// the recipe; Amount and Unit are the replacement's quantity (zero when the
+// original has none, e.g. "to taste").
+type Substitution struct {
+	Ingredient  string  `json:"ingredient"`
+	Original    string  `json:"original,omitempty"`
+	Replacement string  `json:"replacement"`
+	Amount      float64 `json:"amount,omitempty"`
+	Unit        string  `json:"unit,omitempty"`
+	Addresses   string  `json:"addresses,omitempty"`
+	Effect      string  `json:"effect,omitempty"`
+	FromPantry  bool    `json:"from_pantry,omitempty"`
+}
+
+// SubstitutionProposal is the proposed swaps for a recipe, the constraints
+// they were made for, and their expected effect on the dish.
+type SubstitutionProposal struct {
+	RecipeID      uint           `json:"recipe_id"`
+	RecipeTitle   string         `json:"recipe_title"`
+	Constraints   []string       `json:"constraints"`
+	Substitutions []Substitution `json:"substitutions"`
+	Effect        string         `json:"effect,omitempty"`
+	Disclaimer    string         `json:"disclaimer"`
+}
+
+// Propose suggests swaps in the recipe's active version for userID's
+// constraints. An empty Substitutions list means nothing needs to change.
+func (s *SubstitutionService) Propose(ctx context.Context, userID, recipeID uint, c SubstitutionConstraints) (*SubstitutionProposal, error) {
+	if len(c.Avoid) > maxSubstitutionConstraints || len(c.Pantry) > maxPantryItems {
+		return nil, ErrTooManySubstitutionInputs
+	}
+	constraints, err := s.constraints(userID, c)
+	if err != nil {
+		return nil, err
+	}
+	if len(constraints) == 0 {
+		return nil, ErrNoSubstitutionConstraints
+	}
+
+	recipe, err := s.Recipes.GetRecipeByID(recipeID)
+	if err != nil {
+		return nil, err
+	}
+	def := activeRecipeDef(s.Recipes, recipe)
+	if len(def.Ingredients) == 0 {
+		return nil, ErrRecipeHasNoIngredients
+	}
+
+	req := ai.SubstitutionRequest{
+		Title:         def.Title,
+		Instructions:  def.Instructions,
+		Avoid:         constraints,
+		AllergenNotes: s.allergenNotes(recipeID),
+		Pantry:        trimmedNonEmpty(c.Pantry),
+	}
+	for _, ing := range def.Ingredients {
+		req.Ingredients = append(req.Ingredients, ingredientLine(ing))
+	}
+	result, err := ai.SuggestSubstitutions(ctx, s.TextProvider, req)
+	if errors.Is(err, ai.ErrSubstitutionsUnsupported) {
+		return nil, ErrSubstitutionsUnavailable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest substitutions: %w", err)
+	}
+
+	proposal := &SubstitutionProposal{
+		RecipeID:      recipe.ID,
+		RecipeTitle:   def.Title,
+		Constraints:   constraints,
+		Substitutions: []Substitution{},
+		Effect:        result.Effect,
+		Disclaimer:    AllergenDisclaimer,
+	}
+	for _, sug := range result.Suggestions {
+		if sug.Ingredient < 1 || sug.Ingredient > len(def.Ingredients) {
+			continue
+		}
+		orig := def.Ingredients[sug.Ingredient-1]
+		amount, unit := substituteQuantity(orig, sug.Replacement, sug.Ratio, sug.Unit)
+		proposal.Substitutions = append(proposal.Substitutions, Substitution{
+			Ingredient:  orig.Name,
+			Original:    ingredientLine(orig),
+			Replacement: sug.Replacement,
+			Amount:      amount,
+			Unit:        unit,
+			Addresses:   sug.Addresses,
+			Effect:      sug.Effect,
+			FromPantry:  sug.FromPantry,
+		})
+	}
+	return proposal, nil
+}
+
+// constraints gathers the chosen members' dietary needs and the ad-hoc
+// things to avoid, as the model-facing list.
+func (s *SubstitutionService) constraints(userID uint, c SubstitutionConstraints) ([]string, error) {
+	var out []string
+	if len(c.MemberIDs) > 0 {
+		if s.Families == nil {
+			return nil, ErrSubstitutionMemberNotFound
+		}
+		family, err := s.Families.GetFamilyByOwnerID(userID)
+		if err != nil || family == nil {
+			return nil, ErrSubstitutionMemberNotFound
+		}
+		for _, id := range c.MemberIDs {
+			member := familyMemberByID(family, id)
+			if member == nil {
+				return nil, ErrSubstitutionMemberNotFound
+			}
+			out = append(out, memberDietaryConstraints(member)...)
+		}
+	}
+	return append(out, trimmedNonEmpty(c.Avoid)...), nil
+}
+
+// familyMemberByID finds a member of the family.
+func familyMemberByID(family *models.Family, id uint) *models.FamilyMember {
+	for i := range family.Members {
+		if family.Members[i].ID == id {
+			return &family.Members[i]
+		}
+	}
+	return nil
+}
+
+// memberDietaryConstraints renders a member's allergies, intolerances and
+// restrictions, e.g. "Sam: peanut allergy (severe; includes peanut oil)".
+// Preferences are left out: they are not reasons to substitute.
+func memberDietaryConstraints(member *models.FamilyMember) []string {
+	dp := member.DietaryProfile
+	if dp == nil {
+		return nil
+	}
+	name := strings.TrimSpace(member.Name)
+	if name == "" {
+		name = "member"
+	}
+	var out []string
+	for _, a := range dp.Allergies {
+		allergen := strings.TrimSpace(a.Name)
+		if allergen == "" {
+			continue
+		}
+		var detail []string
+		if a.Severity != "" {
+			detail = append(detail, a.Severity)
+		}
+		if forms := trimmedNonEmpty(a.SubForms); len(forms) > 0 {
+			detail = append(detail, "includes "+strings.Join(forms, ", "))
+		}
+		line := fmt.Sprintf("%s: %s allergy", name, allergen)
+		if len(detail) > 0 {
+			line += " (" + strings.Join(detail, "; ") + ")"
+		}
+		out = append(out, line)
+	}
+	for _, in := range trimmedNonEmpty(dp.Intolerances) {
+		out = append(out, fmt.Sprintf("%s: %s intolerance", name, in))
+	}
+	for _, r := range trimmedNonEmpty(dp.Restrictions) {
+		out = append(out, fmt.Sprintf("%s: %s", name, r))
+	}
+	return out
+}
+
+// allergenNotes lists the allergens the recipe's cached allergen analysis
+// found per ingredient. A missing analysis just means no notes.
+func (s *SubstitutionService) allergenNotes(recipeID uint) []string {
+	if s.Allergens == nil {
+		return nil
+	}
+	analysis, err := s.Allergens.GetAnalysisByRecipeID(recipeID)
+	if err != nil {
+		return nil
+	}
+	var notes []string
+	for _, ia := range analysis.IngredientAnalyses {
+		allergens := append(append([]string{}, ia.CommonAllergens...), ia.PossibleAllergens...)
+		if len(allergens) > 0 {
+			notes = append(notes, ia.IngredientName+": "+strings.Join(allergens, ", "))
+		}
+	}
+	return notes
+}
+
+// substituteQuantity scales the original's amount by ratio, in unit when
+// given (else the original's), and re-expresses measurable quantities in a
+// cooking-friendly unit of the same measurement system: 6 tbsp becomes 3/8
+// cup. Count and unrecognized units are only rounded.
+func substituteQuantity(orig models.Ingredient, replacement string, ratio float64, unit string) (float64, string) {
+	if unit == "" {
+		unit = orig.Unit
+	}
+	if c, ok := units.Canonical(unit); ok {
+		unit = c
+	}
+	if orig.Amount <= 0 || ratio <= 0 {
+		return 0, unit
+	}
+	amount := orig.Amount * ratio
+	kind := units.MeasureKind(unit, replacement, "")
+	if system := units.SystemOf(unit); system != "" && (kind == units.KindMass || kind == units.KindVolume) {
+		if a, u := units.ExpressInSystem(units.BaseAmount(amount, unit, kind), kind, system); a > 0 {
+			return a, u
+		}
+	}
+	return math.Round(amount*100) / 100, unit
+}
+
+// Apply forks the recipe with the chosen swaps as the prompt, through the
+// same path as a user-requested fork, and returns the new recipe while it
+// generates.
+func (s *SubstitutionService) Apply(ctx context.Context, user *models.User, recipeID uint, subs []Substitution, genImage bool) (*RecipeResponse, error) {
+	prompt, err := s.ForkPrompt(recipeID, subs)
+	if err != nil {
+		return nil, err
+	}
+	logger.Get().Info("applying substitutions as a fork",
+		zap.Uint("recipe_id", recipeID),
+		zap.Uint("user_id", user.ID),
+		zap.Int("substitutions", len(subs)))
+	return s.Forks.InitGenerateRecipeWithFork(ctx, user, recipeID, prompt, genImage)
+}
+
+// ForkPrompt renders swaps as a fork prompt. Every swap must name an
+// ingredient of the recipe's active version.
+func (s *SubstitutionService) ForkPrompt(recipeID uint, subs []Substitution) (string, error) {
+	if len(subs) == 0 {
+		return "", ErrNoSubstitutions
+	}
+	if len(subs) > maxAppliedSubstitutions {
+		return "", ErrTooManySubstitutions
+	}
+	recipe, err := s.Recipes.GetRecipeByID(recipeID)
+	if err != nil {
+		return "", err
+	}
+	def := activeRecipeDef(s.Recipes, recipe)
+
+	lines := []string{"Make these ingredient substitutions:"}
+	for _, sub := range subs {
+		orig, ok := findIngredient(def.Ingredients, sub.Ingredient)
+		replacement := strings.TrimSpace(sub.Replacement)
+		if !ok || replacement == "" {
+			return "", ErrUnknownSubstitutionIngredient
+		}
+		if sub.Amount > 0 {
+			replacement = strings.TrimSpace(strings.Join([]string{strconv.FormatFloat(sub.Amount, 'f', -1, 64), sub.Unit, replacement}, " "))
+		}
+		line := fmt.Sprintf("- Replace %s with %s", ingredientLine(orig), replacement)
+		if sub.Addresses != "" {
+			line += " (" + strings.TrimSpace(sub.Addresses) + ")"
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "Adjust the method and timing where the swaps need it, and keep everything else the same.")
+	return strings.Join(lines, "\n"), nil
+}
+
+// findIngredient finds an ingredient by name, ignoring case and surrounding
+// space.
+func findIngredient(ingredients models.Ingredients, name string) (models.Ingredient, bool) {
+	name = strings.TrimSpace(name)
+	for _, ing := range ingredients {
+		if strings.EqualFold(strings.TrimSpace(ing.Name), name) {
+			return ing, true
+		}
+	}
+	return models.Ingredient{}, false
+}
+
+// trimmedNonEmpty trims each string and drops the empty ones.
+func trimmedNonEmpty(in []string) []string {
+	var out []string
+	for _, s := range in {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+)
+
+// substituter answers SuggestSubstitutions with fixed suggestions and
+// records the request.
+type substituter struct {
+	*testutil.MockTextProvider
+	got    *ai.SubstitutionRequest
+	result *ai.SubstitutionResult
+}
+
+func (s substituter) SuggestSubstitutions(ctx context.Context, req ai.SubstitutionRequest) (*ai.SubstitutionResult, error) {
+	*s.got = req
+	return s.result, nil
+}
+
+// newSubstitutionService returns a substitution service over the fork
+// fixture (pancakes, recipe 1) for user 2, whose family has Sam (member 5),
+// allergic to milk.
+func newSubstitutionService(t *testing.T, provider ai.TextProvider) (*SubstitutionService, *testutil.MockRecipeRepo) {
+	t.Helper()
+	repo := testutil.NewMockRecipeRepo()
+	seedForkSource(t, repo)
+	families := &testutil.MockFamilyRepo{
+		GetFamilyByOwnerIDFunc: func(ownerID uint) (*models.Family, error) {
+			if ownerID != 2 {
+				return nil, errors.New("no family")
+			}
+			return &models.Family{OwnerID: 2, Members: []models.FamilyMember{{
+				ID:   5,
+				Name: "Sam",
+				DietaryProfile: &models.DietaryProfile{
+					Allergies:   models.AllergyList{{Name: "milk", Severity: "severe", SubForms: []string{"whey"}}},
+					Preferences: models.StringList{"spicy food"},
+				},
+			}}}, nil
+		},
+	}
+	svc := NewSubstitutionService(provider, repo, families, newGenRecipeService(repo, provider, &testutil.MockImageProvider{}, nil, nil))
+	svc.Allergens = &testutil.MockAllergenRepo{
+		GetAnalysisByRecipeIDFunc: func(recipeID uint) (*models.AllergenAnalysis, error) {
+			return &models.AllergenAnalysis{RecipeID: recipeID, IngredientAnalyses: models.IngredientAnalysisList{
+				{IngredientName: "Butter", CommonAllergens: []string{"milk"}},
+				{IngredientName: "All-purpose flour", CommonAllergens: []string{"wheat"}, PossibleAllergens: []string{"soy"}},
+			}}, nil
+		},
+	}
+	return svc, repo
+}
+
+func TestSubstitution_ProposeScalesQuantities(t *testing.T) {
+	var got ai.SubstitutionRequest
+	provider := substituter{MockTextProvider: &testutil.MockTextProvider{}, got: &got, result: &ai.SubstitutionResult{
+		Suggestions: []ai.SubstitutionSuggestion{
+			{Ingredient: 2, Replacement: "oat milk", Ratio: 1, Addresses: "milk allergy"},
+			{Ingredient: 3, Replacement: "ground flaxseed mixed with water", Ratio: 6, Unit: "tsp", Addresses: "no eggs", Effect: "Denser crumb."},
+			{Ingredient: 4, Replacement: "coconut oil", Ratio: 0.75, Addresses: "milk allergy", FromPantry: true},
+		},
+		Effect: "Dairy- and egg-free pancakes.",
+	}}
+	svc, _ := newSubstitutionService(t, provider)
+
+	proposal, err := svc.Propose(context.Background(), 2, 1, SubstitutionConstraints{
+		MemberIDs: []uint{5},
+		Avoid:     []string{" no eggs ", ""},
+		Pantry:    []string{"coconut oil"},
+	})
+	if err != nil {
+		t.Fatalf("Propose() error = %v", err)
+	}
+
+	if want := []string{"Sam: milk allergy (severe; includes whey)", "no eggs"}; !slices.Equal(got.Avoid, want) {
+		t.Errorf("avoid = %q, want %q", got.Avoid, want)
+	}
+	if len(got.Ingredients) != 4 || got.Ingredients[3] != "3 tbsp melted butter" {
+		t.Errorf("ingredients = %q", got.Ingredients)
+	}
+	if !slices.Contains(got.AllergenNotes, "Butter: milk") || !slices.Contains(got.AllergenNotes, "All-purpose flour: wheat, soy") {
+		t.Errorf("allergen notes = %q", got.AllergenNotes)
+	}
+	if !slices.Equal(got.Pantry, []string{"coconut oil"}) {
+		t.Errorf("pantry = %q", got.Pantry)
+	}
+
+	if len(proposal.Substitutions) != 3 || proposal.Effect != "Dairy- and egg-free pancakes." || proposal.Disclaimer == "" {
+		t.Fatalf("proposal = %+v", proposal)
+	}
+	for i, want := range []struct {
+		ingredient string
+		amount     float64
+		unit       string
+	}{
+		{"Milk", 1.25, "cup"},
+		{"Egg", 2, "tbsp"},       // 6 tsp re-expressed
+		{"Butter", 2.25, "tbsp"}, // 3/4 of 3 tbsp
+	} {
+		sub := proposal.Substitutions[i]
+		if sub.Ingredient != want.ingredient || sub.Amount != want.amount || sub.Unit != want.unit {
+			t.Errorf("substitution %d = %+v, want %s as %v %s", i, sub, want.ingredient, want.amount, want.unit)
+		}
+	}
+	if !proposal.Substitutions[2].FromPantry || proposal.Substitutions[1].Effect != "Denser crumb." {
+		t.Errorf("substitutions = %+v", proposal.Substitutions)
+	}
+}
+
+func TestSubstitution_ProposeErrors(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newSubstitutionService(t, &testutil.MockTextProvider{})
+
+	tests := []struct {
+		name   string
+		userID uint
+		c      SubstitutionConstraints
+		want   error
+	}{
+		{"no constraints", 2, SubstitutionConstraints{Avoid: []string{"  "}}, ErrNoSubstitutionConstraints},
+		{"member outside the family", 2, SubstitutionConstraints{MemberIDs: []uint{9}}, ErrSubstitutionMemberNotFound},
+		{"user without a family", 3, SubstitutionConstraints{MemberIDs: []uint{5}}, ErrSubstitutionMemberNotFound},
+		{"too many pantry items", 2, SubstitutionConstraints{Avoid: []string{"nuts"}, Pantry: make([]string, maxPantryItems+1)}, ErrTooManySubstitutionInputs},
+		{"provider without substitutions", 2, SubstitutionConstraints{Avoid: []string{"no eggs"}}, ErrSubstitutionsUnavailable},
+	}
+	for _, tt := range tests {
+		if _, err := svc.Propose(ctx, tt.userID, 1, tt.c); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSubstitution_ApplyForksWithSwaps(t *testing.T) {
+	prompts := make(chan string, 1)
+	provider := &testutil.MockTextProvider{
+		ForkRecipeFunc: func(ctx context.Context, req ai.ForkRequest) (*ai.RecipeResult, error) {
+			prompts <- req.UserPrompt
+			return nil, errors.New("stopped by test")
+		},
+	}
+	svc, repo := newSubstitutionService(t, provider)
+
+	if _, err := svc.Apply(context.Background(), forkUser(), 1, nil, false); !errors.Is(err, ErrNoSubstitutions) {
+		t.Errorf("empty apply error = %v", err)
+	}
+	if _, err := svc.Apply(context.Background(), forkUser(), 1, []Substitution{{Ingredient: "Cream", Replacement: "oat cream"}}, false); !errors.Is(err, ErrUnknownSubstitutionIngredient) {
+		t.Errorf("unknown ingredient error = %v", err)
+	}
+
+	resp, err := svc.Apply(context.Background(), forkUser(), 1, []Substitution{
+		{Ingredient: "butter", Replacement: "coconut oil", Amount: 2.25, Unit: "tbsp", Addresses: "milk allergy"},
+		{Ingredient: "Egg", Replacement: "flax egg"},
+	}, false)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if resp.Status != "generating" {
+		t.Errorf("response status = %q, want generating", resp.Status)
+	}
+	if stored := repo.RecipeSnapshot(2); stored == nil || stored.ForkedFrom == nil || stored.ForkedFrom.ID != 1 {
+		t.Fatalf("fork placeholder = %+v", stored)
+	}
+
+	select {
+	case prompt := <-prompts:
+		for _, want := range []string{
+			"Replace 3 tbsp melted butter with 2.25 tbsp coconut oil (milk allergy)",
+			"Replace 1 egg with flax egg",
+		} {
+			if !strings.Contains(prompt, want) {
+				t.Errorf("fork prompt %q lacks %q", prompt, want)
+			}
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("fork never reached the text provider")
+	}
+}
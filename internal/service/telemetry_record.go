@@ -45,7 +45,8 @@ func extractionOrigin(ctx context.Context) string {
 // recordExtraction persists one terminal extraction outcome. Best-effort and
 // synchronous (a single indexed insert): a telemetry failure only warns, never
 // fails the extraction itself. Callers fill URL/Method/Success/Error*; Origin
-// falls back to the ctx flow tag and Domain is derived from the URL.
+// falls back to the ctx flow tag, RequestID to the ctx attribution, and Domain
+// is derived from the URL.
 func (s *ImportService) recordExtraction(ctx context.Context, ev models.ExtractionEvent) {
 	if s == nil || s.Events == nil {
 		return
@@ -56,6 +57,9 @@ func (s *ImportService) recordExtraction(ctx context.Context, ev models.Extracti
 	if ev.Domain == "" {
 		ev.Domain = domainFromURL(ev.URL)
 	}
+	if ev.RequestID == "" {
+		ev.RequestID = ai.AttributionFromContext(ctx).RequestID
+	}
 	if ev.CreatedAt.IsZero() {
 		ev.CreatedAt = time.Now()
 	}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"regexp"
@@ -10,6 +11,8 @@ import (
 
 	goaway "github.com/TwiN/go-away"
 	"github.com/asaskevich/govalidator"
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/allergens"
 	"github.com/windoze95/saltybytes-api/internal/config"
 	"github.com/windoze95/saltybytes-api/internal/models"
 	"github.com/windoze95/saltybytes-api/internal/repository"
@@ -44,6 +47,9 @@ type PersonalizationResponse struct {
 	UnitSystem     string `json:"unit_system"`
 	Requirements   string `json:"requirements"`
 	CookingContext string `json:"cooking_context"`
+	Locale         string `json:"locale"`
+	Region         string `json:"region"`
+	AllergenSet    string `json:"allergen_set"`
 	UID            string `json:"uid"`
 }
 
@@ -156,6 +162,9 @@ func ToUserResponse(user *models.User) *UserResponse {
 			UnitSystem:     user.Personalization.UnitSystem,
 			Requirements:   user.Personalization.Requirements,
 			CookingContext: user.Personalization.CookingContext,
+			Locale:         user.Personalization.Locale,
+			Region:         user.Personalization.Region,
+			AllergenSet:    user.Personalization.AllergenSet,
 			UID:            user.Personalization.UID.String(),
 		}
 	}
@@ -173,9 +182,45 @@ func (s *UserService) UpdatePersonalization(user *models.User, update *models.Pe
 	if update.UnitSystem != nil && *update.UnitSystem != "us_customary" && *update.UnitSystem != "metric" {
 		return fmt.Errorf("unit_system must be 'us_customary' or 'metric'")
 	}
+	if update.Locale != nil {
+		locale, err := models.NormalizeLocaleTag(*update.Locale)
+		if err != nil {
+			return err
+		}
+		update.Locale = &locale
+	}
+	if update.Region != nil {
+		region, err := models.NormalizeRegion(*update.Region)
+		if err != nil {
+			return err
+		}
+		update.Region = &region
+	}
+	if update.AllergenSet != nil {
+		set, err := allergens.NormalizeSet(*update.AllergenSet)
+		if err != nil {
+			return err
+		}
+		update.AllergenSet = &set
+	}
 	return s.Repo.UpdatePersonalization(user.ID, update)
 }
 
+// UserLocale returns the user's search/prompt locale. An unset Region falls
+// back to the region subtag of Locale (see ai.Locale.CountryCode).
+func UserLocale(user *models.User) ai.Locale {
+	if user == nil || user.Personalization == nil {
+		return ai.Locale{}
+	}
+	return ai.Locale{Language: user.Personalization.Locale, Region: user.Personalization.Region}
+}
+
+// WithUserLocale attaches the user's locale to ctx for search backends and
+// extraction prompts.
+func WithUserLocale(ctx context.Context, user *models.User) context.Context {
+	return ai.WithLocale(ctx, UserLocale(user))
+}
+
 // UpdateUser updates a user's profile fields (first name, email).
 func (s *UserService) UpdateUser(user *models.User, firstName, email string) error {
 	if email != "" && email != user.Email {
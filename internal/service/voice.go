@@ -1,11 +1,33 @@
 package service
 
 import (
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/windoze95/saltybytes-api/internal/ai"
 	"github.com/windoze95/saltybytes-api/internal/config"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/s3"
+	"go.uber.org/zap"
+)
+
+// Read-aloud errors, reported to the cooking client.
+var (
+	ErrReadAloudUnavailable = errors.New("read-aloud is not configured")
+	ErrNoSuchStep           = errors.New("recipe has no such step")
+)
+
+// statSpeechObject and uploadSpeechToS3 are indirections over the s3 package
+// so tests can stub the step-audio cache.
+var (
+	statSpeechObject = s3.StatObject
+	uploadSpeechToS3 = s3.UploadRecipeImageToS3
 )
 
 // VoiceService handles voice command processing for cooking mode.
@@ -13,6 +35,12 @@ type VoiceService struct {
 	Cfg            *config.Config
 	TextProvider   ai.TextProvider
 	SpeechProvider ai.SpeechProvider
+	// Synthesizer reads answers and steps aloud in Voice; nil disables
+	// read-aloud. SynthesizerModel names its provider and model (see
+	// ai.SpeechSynthesisModel) for the step-audio cache.
+	Synthesizer      ai.SpeechSynthesisProvider
+	SynthesizerModel string
+	Voice            string
 }
 
 // NewVoiceService creates a new VoiceService.
@@ -45,3 +73,83 @@ func (s *VoiceService) ProcessVoiceCommand(ctx context.Context, audioData []byte
 func (s *VoiceService) AnswerCookingQuestion(ctx context.Context, question string, recipeContext string) (string, error) {
 	return s.TextProvider.CookingQA(ctx, question, recipeContext)
 }
+
+// SpeechClip is read-aloud audio for the client: either inline Audio or, for
+// cached step audio, the URL of the stored object.
+type SpeechClip struct {
+	Text   string
+	Format string
+	Audio  []byte
+	URL    string
+}
+
+// CanReadAloud reports whether a synthesizer is configured.
+func (s *VoiceService) CanReadAloud() bool {
+	return s.Synthesizer != nil
+}
+
+// ReadAloud synthesizes text (typically a cooking answer) as inline audio.
+// Answers are one-off, so they are not cached.
+func (s *VoiceService) ReadAloud(ctx context.Context, text string) (*SpeechClip, error) {
+	if s.Synthesizer == nil {
+		return nil, ErrReadAloudUnavailable
+	}
+	audio, err := s.Synthesizer.SynthesizeSpeech(ctx, text, s.Voice)
+	if err != nil {
+		return nil, fmt.Errorf("synthesize speech: %w", err)
+	}
+	return &SpeechClip{Text: text, Format: audio.Format, Audio: audio.Data}, nil
+}
+
+// ReadStep returns audio of the recipe's 1-based step. Step audio is cached
+// in S3 keyed by the synthesizer, the voice and the hash of the spoken text,
+// so repeated reads
+// of an unchanged step skip synthesis and hand back the stored object's URL.
+// The cache is best-effort: with no bucket configured, or when S3 is
+// unavailable, the step is synthesized and returned inline.
+func (s *VoiceService) ReadStep(ctx context.Context, recipe *models.Recipe, step int) (*SpeechClip, error) {
+	if s.Synthesizer == nil {
+		return nil, ErrReadAloudUnavailable
+	}
+	instructions := effectiveRecipeDef(recipe).Instructions
+	if step < 1 || step > len(instructions) || strings.TrimSpace(instructions[step-1]) == "" {
+		return nil, ErrNoSuchStep
+	}
+	text := fmt.Sprintf("Step %d. %s", step, strings.TrimSpace(instructions[step-1]))
+	if s.Cfg.EnvVars.S3Bucket == "" {
+		return s.ReadAloud(ctx, text)
+	}
+
+	log := logger.Get()
+	sum := sha256.Sum256([]byte(text))
+	key := s3.GenerateSpeechKey(recipe.ID, cmp.Or(s.SynthesizerModel, "default"), cmp.Or(s.Voice, "default"), hex.EncodeToString(sum[:]))
+
+	if obj, err := statSpeechObject(ctx, s.Cfg, key); err != nil {
+		log.Warn("failed to check step audio cache", zap.Uint("recipe_id", recipe.ID), zap.Error(err))
+	} else if obj != nil {
+		return &SpeechClip{Text: text, Format: speechFormat(obj.ContentType), URL: obj.URL}, nil
+	}
+
+	audio, err := s.Synthesizer.SynthesizeSpeech(ctx, text, s.Voice)
+	if err != nil {
+		return nil, fmt.Errorf("synthesize step %d: %w", step, err)
+	}
+	url, err := uploadSpeechToS3(ctx, s.Cfg, audio.Data, key, audio.ContentType())
+	if err != nil {
+		log.Warn("failed to cache step audio", zap.Uint("recipe_id", recipe.ID), zap.Error(err))
+		return &SpeechClip{Text: text, Format: audio.Format, Audio: audio.Data}, nil
+	}
+	return &SpeechClip{Text: text, Format: audio.Format, URL: url}, nil
+}
+
+// speechFormat maps a cached object's Content-Type back to its audio format.
+func speechFormat(contentType string) string {
+	switch contentType {
+	case "audio/mpeg":
+		return "mp3"
+	case "audio/wav":
+		return "wav"
+	default:
+		return ""
+	}
+}
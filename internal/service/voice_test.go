@@ -9,6 +9,7 @@ import (
 
 	"github.com/windoze95/saltybytes-api/internal/ai"
 	"github.com/windoze95/saltybytes-api/internal/config"
+	"github.com/windoze95/saltybytes-api/internal/s3"
 	"github.com/windoze95/saltybytes-api/internal/testutil"
 )
 
@@ -200,3 +201,134 @@ func TestAnswerCookingQuestion_ErrorPropagates(t *testing.T) {
 		t.Errorf("answer = %q, want empty on error", answer)
 	}
 }
+
+// stubSpeechCache replaces the S3 step-audio cache with an in-memory map and
+// counts uploads.
+func stubSpeechCache(t *testing.T) (objects map[string]*s3.ObjectInfo, uploads *int) {
+	t.Helper()
+	objects = map[string]*s3.ObjectInfo{}
+	uploads = new(int)
+	origStat, origUpload := statSpeechObject, uploadSpeechToS3
+	statSpeechObject = func(_ context.Context, _ *config.Config, key string) (*s3.ObjectInfo, error) {
+		return objects[key], nil
+	}
+	uploadSpeechToS3 = func(_ context.Context, _ *config.Config, _ []byte, key string, contentType string) (string, error) {
+		*uploads++
+		url := "https://bucket.s3.us-east-2.amazonaws.com/" + key
+		objects[key] = &s3.ObjectInfo{URL: url, ContentType: contentType}
+		return url, nil
+	}
+	t.Cleanup(func() { statSpeechObject, uploadSpeechToS3 = origStat, origUpload })
+	return objects, uploads
+}
+
+// cachedVoiceService is a VoiceService with a bucket configured, so ReadStep
+// uses the (stubbed) step-audio cache.
+func cachedVoiceService() *VoiceService {
+	return NewVoiceService(&config.Config{EnvVars: config.EnvVars{S3Bucket: "bucket"}}, nil, nil)
+}
+
+func mp3Synthesizer() *testutil.MockSpeechSynthesisProvider {
+	return &testutil.MockSpeechSynthesisProvider{
+		SynthesizeSpeechFunc: func(ctx context.Context, text, voice string) (*ai.SpeechAudio, error) {
+			return &ai.SpeechAudio{Data: []byte("ID3" + text), Format: "mp3"}, nil
+		},
+	}
+}
+
+func TestReadStep_CachesAudioByTextSynthesizerAndVoice(t *testing.T) {
+	_, uploads := stubSpeechCache(t)
+	synth := mp3Synthesizer()
+	svc := cachedVoiceService()
+	svc.Synthesizer, svc.SynthesizerModel, svc.Voice = synth, "openai/tts-1", "alloy"
+	recipe := testutil.TestRecipe()
+
+	first, err := svc.ReadStep(context.Background(), recipe, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Text != "Step 2. Whisk wet ingredients" || first.Format != "mp3" {
+		t.Errorf("clip = %q (%s)", first.Text, first.Format)
+	}
+	if !strings.Contains(first.URL, "recipes/1/audio/openai/tts-1/alloy/") || len(first.Audio) != 0 {
+		t.Errorf("want a cached URL under the recipe, got url %q and %d inline bytes", first.URL, len(first.Audio))
+	}
+
+	again, err := svc.ReadStep(context.Background(), recipe, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if synth.Calls != 1 || *uploads != 1 || again.URL != first.URL || again.Format != "mp3" {
+		t.Errorf("repeat read: %d syntheses, %d uploads, url %q; want the cached object", synth.Calls, *uploads, again.URL)
+	}
+
+	svc.Voice = "nova"
+	if other, _ := svc.ReadStep(context.Background(), recipe, 2); other.URL == first.URL || synth.Calls != 2 {
+		t.Error("a different voice should synthesize its own audio")
+	}
+
+	svc.Voice, svc.SynthesizerModel = "alloy", "openai/tts-1-hd"
+	if other, _ := svc.ReadStep(context.Background(), recipe, 2); other.URL == first.URL || synth.Calls != 3 {
+		t.Error("a different model should synthesize its own audio")
+	}
+}
+
+func TestReadStep_UploadFailureReturnsInlineAudio(t *testing.T) {
+	stubSpeechCache(t)
+	uploadSpeechToS3 = func(_ context.Context, _ *config.Config, _ []byte, _ string, _ string) (string, error) {
+		return "", errors.New("s3 unavailable")
+	}
+	svc := cachedVoiceService()
+	svc.Synthesizer = mp3Synthesizer()
+
+	clip, err := svc.ReadStep(context.Background(), testutil.TestRecipe(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clip.URL != "" || !bytes.HasPrefix(clip.Audio, []byte("ID3")) {
+		t.Errorf("want inline audio when the cache upload fails, got %+v", clip)
+	}
+}
+
+func TestReadStep_Errors(t *testing.T) {
+	stubSpeechCache(t)
+	svc := cachedVoiceService()
+	if _, err := svc.ReadStep(context.Background(), testutil.TestRecipe(), 1); !errors.Is(err, ErrReadAloudUnavailable) {
+		t.Errorf("no synthesizer: err = %v", err)
+	}
+	svc.Synthesizer = mp3Synthesizer()
+	for _, step := range []int{0, 4} {
+		if _, err := svc.ReadStep(context.Background(), testutil.TestRecipe(), step); !errors.Is(err, ErrNoSuchStep) {
+			t.Errorf("step %d: err = %v, want ErrNoSuchStep", step, err)
+		}
+	}
+}
+
+func TestReadStep_NoBucketSkipsCache(t *testing.T) {
+	_, uploads := stubSpeechCache(t)
+	svc := NewVoiceService(&config.Config{}, nil, nil)
+	svc.Synthesizer = mp3Synthesizer()
+
+	clip, err := svc.ReadStep(context.Background(), testutil.TestRecipe(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *uploads != 0 || clip.URL != "" || len(clip.Audio) == 0 {
+		t.Errorf("want inline audio and no cache traffic without a bucket, got %d uploads, %+v", *uploads, clip)
+	}
+}
+
+func TestReadAloud_ReturnsInlineAudio(t *testing.T) {
+	svc := NewVoiceService(&config.Config{}, nil, nil)
+	if svc.CanReadAloud() {
+		t.Error("read-aloud should be off without a synthesizer")
+	}
+	svc.Synthesizer = mp3Synthesizer()
+	clip, err := svc.ReadAloud(context.Background(), "Bake at 375F.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clip.URL != "" || string(clip.Audio) != "ID3Bake at 375F." {
+		t.Errorf("clip = %+v, want inline audio", clip)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/windoze95/saltybytes-api/internal/ai"
 	"github.com/windoze95/saltybytes-api/internal/logger"
 	"go.uber.org/zap"
 )
@@ -135,7 +136,8 @@ func (w *WarmService) warmOne(rawURL, normalizedURL string) {
 	w.sem <- struct{}{}
 	defer func() { <-w.sem }()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	// Warming yields to interactive calls when a provider is rate limited.
+	ctx, cancel := context.WithTimeout(ai.WithPriority(context.Background(), ai.PriorityWarming), 60*time.Second)
 	defer cancel()
 
 	if err := w.Import.WarmURL(ctx, w.Resolver, rawURL); err != nil {
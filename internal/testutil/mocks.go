@@ -3,6 +3,7 @@ package testutil
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -149,6 +150,23 @@ func (m *MockSpeechProvider) TranscribeAudio(ctx context.Context, audioData []by
 	return "", fmt.Errorf("TranscribeAudio not configured")
 }
 
+// --- MockSpeechSynthesisProvider ---
+
+// MockSpeechSynthesisProvider is a mock implementation of
+// ai.SpeechSynthesisProvider that records how often it was called.
+type MockSpeechSynthesisProvider struct {
+	SynthesizeSpeechFunc func(ctx context.Context, text, voice string) (*ai.SpeechAudio, error)
+	Calls                int
+}
+
+func (m *MockSpeechSynthesisProvider) SynthesizeSpeech(ctx context.Context, text, voice string) (*ai.SpeechAudio, error) {
+	m.Calls++
+	if m.SynthesizeSpeechFunc != nil {
+		return m.SynthesizeSpeechFunc(ctx, text, voice)
+	}
+	return nil, fmt.Errorf("SynthesizeSpeech not configured")
+}
+
 // --- MockRecipeRepo ---
 
 // MockRecipeRepo is an in-memory mock implementation of repository.RecipeRepo.
@@ -169,6 +187,7 @@ type MockRecipeRepo struct {
 	DeleteRecipeErr                   error
 	UpdateRecipeTitleErr              error
 	UpdateRecipeImageURLErr           error
+	UpdateRecipeImageVariantsErr      error
 	UpdateRecipeDefErr                error
 	CreateRecipeTreeErr               error
 	AddNodeToTreeErr                  error
@@ -178,6 +197,8 @@ type MockRecipeRepo struct {
 	GetNodeByIDErr                    error
 	GetNodeAncestorsErr               error
 	SetActiveNodeErr                  error
+	SetNodeEphemeralErr               error
+	DeleteNodeErr                     error
 	UpdateRecipeFromNodeErr           error
 	MaterializeRecipeFromCanonicalErr error
 
@@ -355,6 +376,19 @@ func (m *MockRecipeRepo) UpdateRecipeImageURL(recipeID uint, imageURL string) er
 	return nil
 }
 
+func (m *MockRecipeRepo) UpdateRecipeImageVariants(recipeID uint, variants *models.ImageVariants) error {
+	if m.UpdateRecipeImageVariantsErr != nil {
+		return m.UpdateRecipeImageVariantsErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r, ok := m.Recipes[recipeID]; ok {
+		r.ImageVariants = variants
+	}
+	return nil
+}
+
 func (m *MockRecipeRepo) UpdateRecipeDef(recipe *models.Recipe) error {
 	if m.UpdateRecipeDefErr != nil {
 		return m.UpdateRecipeDefErr
@@ -563,6 +597,30 @@ func (m *MockRecipeRepo) SetActiveNode(treeID uint, nodeID uint) error {
 	return nil
 }
 
+func (m *MockRecipeRepo) SetNodeEphemeral(nodeID uint, ephemeral bool) error {
+	if m.SetNodeEphemeralErr != nil {
+		return m.SetNodeEphemeralErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if n, ok := m.Nodes[nodeID]; ok {
+		n.IsEphemeral = ephemeral
+	}
+	return nil
+}
+
+func (m *MockRecipeRepo) DeleteNode(nodeID uint) error {
+	if m.DeleteNodeErr != nil {
+		return m.DeleteNodeErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.Nodes, nodeID)
+	return nil
+}
+
 func (m *MockRecipeRepo) UpdateRecipeFromNode(recipeID uint, node *models.RecipeNode) error {
 	if m.UpdateRecipeFromNodeErr != nil {
 		return m.UpdateRecipeFromNodeErr
@@ -704,6 +762,15 @@ func (m *MockUserRepo) UpdatePersonalization(userID uint, update *models.Persona
 		if update.CookingContext != nil {
 			u.Personalization.CookingContext = *update.CookingContext
 		}
+		if update.Locale != nil {
+			u.Personalization.Locale = *update.Locale
+		}
+		if update.Region != nil {
+			u.Personalization.Region = *update.Region
+		}
+		if update.AllergenSet != nil {
+			u.Personalization.AllergenSet = *update.AllergenSet
+		}
 		if update.UID != nil {
 			u.Personalization.UID = *update.UID
 		}
@@ -871,17 +938,17 @@ func (m *MockEmbeddingProvider) GenerateEmbedding(ctx context.Context, text stri
 
 // MockSearchCacheRepo mocks repository.SearchCacheRepository for testing.
 type MockSearchCacheRepo struct {
-	GetByNormalizedQueryFunc func(query string) (*models.SearchCache, error)
+	GetByNormalizedQueryFunc func(query, market string) (*models.SearchCache, error)
 	UpsertFunc               func(entry *models.SearchCache) error
 	IncrementHitCountFunc    func(id uint) error
-	FindSimilarFunc          func(embedding []float32, threshold float64, limit int) ([]models.SearchCache, error)
+	FindSimilarFunc          func(embedding []float32, threshold float64, limit int, market string) ([]models.SearchCache, error)
 	GetHotQueriesFunc        func(minHits int, maxAge, refreshWindow time.Duration) ([]models.SearchCache, error)
 	DeleteStaleFunc          func(maxAge time.Duration) (int64, error)
 }
 
-func (m *MockSearchCacheRepo) GetByNormalizedQuery(query string) (*models.SearchCache, error) {
+func (m *MockSearchCacheRepo) GetByNormalizedQuery(query, market string) (*models.SearchCache, error) {
 	if m.GetByNormalizedQueryFunc != nil {
-		return m.GetByNormalizedQueryFunc(query)
+		return m.GetByNormalizedQueryFunc(query, market)
 	}
 	return nil, fmt.Errorf("not found")
 }
@@ -900,9 +967,9 @@ func (m *MockSearchCacheRepo) IncrementHitCount(id uint) error {
 	return nil
 }
 
-func (m *MockSearchCacheRepo) FindSimilar(embedding []float32, threshold float64, limit int) ([]models.SearchCache, error) {
+func (m *MockSearchCacheRepo) FindSimilar(embedding []float32, threshold float64, limit int, market string) ([]models.SearchCache, error) {
 	if m.FindSimilarFunc != nil {
-		return m.FindSimilarFunc(embedding, threshold, limit)
+		return m.FindSimilarFunc(embedding, threshold, limit, market)
 	}
 	return nil, nil
 }
@@ -1003,6 +1070,7 @@ type MockFinderSessionRepo struct {
 	nextID   uint
 
 	CreateErr error
+	UpdateErr error
 }
 
 // NewMockFinderSessionRepo creates an empty in-memory finder-session repo.
@@ -1025,6 +1093,20 @@ func (m *MockFinderSessionRepo) Create(ctx context.Context, session *models.Find
 	return nil
 }
 
+func (m *MockFinderSessionRepo) Update(ctx context.Context, session *models.FinderSession) error {
+	if m.UpdateErr != nil {
+		return m.UpdateErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[session.ID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	cp := *session
+	m.sessions[session.ID] = &cp
+	return nil
+}
+
 func (m *MockFinderSessionRepo) ListByUser(ctx context.Context, userID uint, limit, offset int) ([]models.FinderSession, int64, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -1075,6 +1157,278 @@ func (m *MockFinderSessionRepo) Created() []models.FinderSession {
 	return out
 }
 
+// --- MockCookingSessionRepo ---
+
+// MockCookingSessionRepo is an in-memory mock of repository.CookingSessionRepo.
+type MockCookingSessionRepo struct {
+	mu       sync.Mutex
+	sessions map[[2]uint]*models.CookingSession
+	nextID   uint
+
+	ModifyErr error
+}
+
+// NewMockCookingSessionRepo creates an empty in-memory cooking-session repo.
+func NewMockCookingSessionRepo() *MockCookingSessionRepo {
+	return &MockCookingSessionRepo{sessions: make(map[[2]uint]*models.CookingSession)}
+}
+
+// cloneCookingSession copies a session including its lists, so callers never
+// share state with the store.
+func cloneCookingSession(s *models.CookingSession) *models.CookingSession {
+	cp := *s
+	cp.Edits = slices.Clone(s.Edits)
+	cp.Timers = slices.Clone(s.Timers)
+	cp.History = slices.Clone(s.History)
+	return &cp
+}
+
+func (m *MockCookingSessionRepo) Get(ctx context.Context, userID, recipeID uint) (*models.CookingSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[[2]uint{userID, recipeID}]
+	if !ok {
+		return nil, nil
+	}
+	return cloneCookingSession(s), nil
+}
+
+func (m *MockCookingSessionRepo) GetByJoinCode(ctx context.Context, code string, now time.Time) (*models.CookingSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.sessions {
+		if s.JoinCode == code && s.JoinCodeExpiresAt != nil && s.JoinCodeExpiresAt.After(now) {
+			return cloneCookingSession(s), nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockCookingSessionRepo) Modify(ctx context.Context, userID, recipeID uint, fn func(*models.CookingSession) error) (*models.CookingSession, error) {
+	if m.ModifyErr != nil {
+		return nil, m.ModifyErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := [2]uint{userID, recipeID}
+	session, ok := m.sessions[key]
+	if !ok {
+		m.nextID++
+		session = &models.CookingSession{ID: m.nextID, UserID: userID, RecipeID: recipeID, CreatedAt: time.Now(), LastActiveAt: time.Now()}
+	}
+	session = cloneCookingSession(session)
+	if err := fn(session); err != nil {
+		return nil, err
+	}
+	session.UpdatedAt = time.Now()
+	m.sessions[key] = session
+	return cloneCookingSession(session), nil
+}
+
+func (m *MockCookingSessionRepo) ListActiveByUser(ctx context.Context, userID uint, since time.Time) ([]models.CookingSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []models.CookingSession
+	for _, s := range m.sessions {
+		if s.UserID == userID && !s.LastActiveAt.Before(since) {
+			out = append(out, *cloneCookingSession(s))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LastActiveAt.After(out[j].LastActiveAt) })
+	return out, nil
+}
+
+func (m *MockCookingSessionRepo) Delete(ctx context.Context, userID, recipeID uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, [2]uint{userID, recipeID})
+	return nil
+}
+
+func (m *MockCookingSessionRepo) DeleteInactive(ctx context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for key, s := range m.sessions {
+		if s.LastActiveAt.Before(before) {
+			delete(m.sessions, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// --- MockCookLogRepo ---
+
+// MockCookLogRepo is an in-memory mock of repository.CookLogRepo.
+// ListUserRecipesByCooking sorts the recipes of Recipes, which must be set to
+// use it.
+type MockCookLogRepo struct {
+	mu     sync.Mutex
+	logs   map[uint]*models.CookLog
+	nextID uint
+
+	Recipes *MockRecipeRepo
+
+	CreateErr error
+}
+
+// NewMockCookLogRepo creates an empty in-memory cook-log repo.
+func NewMockCookLogRepo() *MockCookLogRepo {
+	return &MockCookLogRepo{logs: make(map[uint]*models.CookLog)}
+}
+
+func cloneCookLog(l *models.CookLog) *models.CookLog {
+	cp := *l
+	cp.PhotoURLs = slices.Clone(l.PhotoURLs)
+	return &cp
+}
+
+func (m *MockCookLogRepo) Create(ctx context.Context, log *models.CookLog) error {
+	if m.CreateErr != nil {
+		return m.CreateErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	log.ID = m.nextID
+	log.CreatedAt = time.Now()
+	log.UpdatedAt = log.CreatedAt
+	m.logs[log.ID] = cloneCookLog(log)
+	return nil
+}
+
+func (m *MockCookLogRepo) Update(ctx context.Context, log *models.CookLog) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.logs[log.ID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	log.UpdatedAt = time.Now()
+	m.logs[log.ID] = cloneCookLog(log)
+	return nil
+}
+
+func (m *MockCookLogRepo) GetByID(ctx context.Context, id uint) (*models.CookLog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.logs[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return cloneCookLog(l), nil
+}
+
+func (m *MockCookLogRepo) Delete(ctx context.Context, id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.logs, id)
+	return nil
+}
+
+func (m *MockCookLogRepo) ListByUser(ctx context.Context, userID, recipeID uint, limit, offset int) ([]models.CookLog, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var all []models.CookLog
+	for _, l := range m.logs {
+		if l.UserID == userID && (recipeID == 0 || l.RecipeID == recipeID) {
+			all = append(all, *cloneCookLog(l))
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].CookedAt.Equal(all[j].CookedAt) {
+			return all[i].CookedAt.After(all[j].CookedAt)
+		}
+		return all[i].ID > all[j].ID
+	})
+	total := int64(len(all))
+	if offset >= len(all) {
+		return []models.CookLog{}, total, nil
+	}
+	end := min(offset+limit, len(all))
+	return all[offset:end], total, nil
+}
+
+func (m *MockCookLogRepo) Stats(ctx context.Context, recipeIDs []uint) (map[uint]models.CookStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.statsLocked(recipeIDs), nil
+}
+
+// statsLocked aggregates the logs of recipeIDs. m.mu must be held.
+func (m *MockCookLogRepo) statsLocked(recipeIDs []uint) map[uint]models.CookStats {
+	out := make(map[uint]models.CookStats)
+	ratingSum := make(map[uint]int)
+	for _, l := range m.logs {
+		if !slices.Contains(recipeIDs, l.RecipeID) {
+			continue
+		}
+		stats := out[l.RecipeID]
+		stats.RecipeID = l.RecipeID
+		stats.TimesCooked++
+		if l.Rating > 0 {
+			stats.RatingCount++
+			ratingSum[l.RecipeID] += l.Rating
+		}
+		if stats.LastCookedAt == nil || l.CookedAt.After(*stats.LastCookedAt) {
+			cookedAt := l.CookedAt
+			stats.LastCookedAt = &cookedAt
+		}
+		out[l.RecipeID] = stats
+	}
+	for id, stats := range out {
+		if stats.RatingCount > 0 {
+			stats.AverageRating = float64(ratingSum[id]) / float64(stats.RatingCount)
+			out[id] = stats
+		}
+	}
+	return out
+}
+
+func (m *MockCookLogRepo) ListUserRecipesByCooking(ctx context.Context, userID uint, by repository.CookSort, page, pageSize int) ([]models.Recipe, int64, error) {
+	recipes, _, err := m.Recipes.GetUserRecipes(userID, 1, len(m.Recipes.Recipes)+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := make([]uint, len(recipes))
+	for i, r := range recipes {
+		ids[i] = r.ID
+	}
+	m.mu.Lock()
+	stats := m.statsLocked(ids)
+	m.mu.Unlock()
+
+	lastCooked := func(id uint) time.Time {
+		if t := stats[id].LastCookedAt; t != nil {
+			return *t
+		}
+		return time.Time{}
+	}
+	sort.SliceStable(recipes, func(i, j int) bool {
+		a, b := recipes[i], recipes[j]
+		sa, sb := stats[a.ID], stats[b.ID]
+		switch {
+		case by == repository.CookSortMostCooked && sa.TimesCooked != sb.TimesCooked:
+			return sa.TimesCooked > sb.TimesCooked
+		case by == repository.CookSortTopRated && sa.AverageRating != sb.AverageRating:
+			return sa.AverageRating > sb.AverageRating
+		case by == repository.CookSortTopRated && sa.TimesCooked != sb.TimesCooked:
+			return sa.TimesCooked > sb.TimesCooked
+		case by != repository.CookSortTopRated && !lastCooked(a.ID).Equal(lastCooked(b.ID)):
+			return lastCooked(a.ID).After(lastCooked(b.ID))
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+
+	total := int64(len(recipes))
+	start := (page - 1) * pageSize
+	if start >= len(recipes) {
+		return []models.Recipe{}, total, nil
+	}
+	end := min(start+pageSize, len(recipes))
+	return recipes[start:end], total, nil
+}
+
 // --- MockCanonicalRecipeRepo ---
 
 // MockCanonicalRecipeRepo mocks repository.CanonicalRecipeRepo for testing.
@@ -1223,6 +1577,7 @@ var _ ai.TextProvider = (*MockTextProvider)(nil)
 var _ ai.VisionProvider = (*MockVisionProvider)(nil)
 var _ ai.ImageProvider = (*MockImageProvider)(nil)
 var _ ai.SpeechProvider = (*MockSpeechProvider)(nil)
+var _ ai.SpeechSynthesisProvider = (*MockSpeechSynthesisProvider)(nil)
 var _ ai.SearchProvider = (*MockSearchProvider)(nil)
 var _ ai.EmbeddingProvider = (*MockEmbeddingProvider)(nil)
 var _ repository.RecipeRepo = (*MockRecipeRepo)(nil)
@@ -1231,6 +1586,7 @@ var _ repository.SearchCacheRepo = (*MockSearchCacheRepo)(nil)
 var _ repository.CanonicalRecipeRepo = (*MockCanonicalRecipeRepo)(nil)
 var _ repository.FamilyRepo = (*MockFamilyRepo)(nil)
 var _ repository.FinderSessionRepo = (*MockFinderSessionRepo)(nil)
+var _ repository.CookingSessionRepo = (*MockCookingSessionRepo)(nil)
 var _ repository.VideoImportRepo = (*MockVideoImportRepo)(nil)
 
 // --- MockFinderRunRepo ---
@@ -1270,6 +1626,25 @@ func (m *MockFinderRunRepo) Runs() []*models.FinderRun {
 	return out
 }
 
+// ListRankCaptures returns recorded runs carrying a ranker capture, created at
+// or after since, newest first, up to limit (0 = no limit).
+func (m *MockFinderRunRepo) ListRankCaptures(since time.Time, limit int) ([]models.FinderRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []models.FinderRun
+	for i := len(m.runs) - 1; i >= 0; i-- {
+		run := m.runs[i]
+		if run.RankCapture == nil || run.CreatedAt.Before(since) {
+			continue
+		}
+		out = append(out, *run)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
 // --- MockExtractionEventRepo ---
 
 // MockExtractionEventRepo is an in-memory mock of repository.ExtractionEventRepo.
@@ -77,7 +77,8 @@ var aliases = map[string]string{
 	"pt": "pt", "pts": "pt", "pint": "pt", "pints": "pt",
 	"qt": "qt", "qts": "qt", "quart": "qt", "quarts": "qt",
 	"gal": "gal", "gals": "gal", "gallon": "gal", "gallons": "gal",
-	"floz": "fl oz",
+	"fl oz": "fl oz", "floz": "fl oz", "fl. oz": "fl oz", "fl. oz.": "fl oz",
+	"fluid ounce": "fl oz", "fluid ounces": "fl oz",
 	// weight — US customary
 	"oz": "oz", "ozs": "oz", "ounce": "oz", "ounces": "oz",
 	"lb": "lb", "lbs": "lb", "pound": "lb", "pounds": "lb",
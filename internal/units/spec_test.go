@@ -12,6 +12,7 @@ func TestCanonical(t *testing.T) {
 		"grams": "g", "Grams": "g", "ml": "mL", "ML": "mL",
 		"tablespoons": "tbsp", "CUPS": "cup", "pounds": "lb",
 		"cloves": "pieces", "sticks": "pieces",
+		"fl oz": "fl oz", "FL. OZ.": "fl oz", "fluid ounces": "fl oz",
 	}
 	for in, want := range cases {
 		if got, ok := Canonical(in); !ok || got != want {
@@ -0,0 +1,118 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// Backplane event kinds.
+const (
+	// EventMessage carries a RoomMessage to every instance's clients.
+	EventMessage = "message"
+	// EventPresence is an instance's full membership of one room: user ID
+	// to connected devices. An empty Members means it has left the room.
+	EventPresence = "presence"
+	// EventHeartbeat keeps an instance's presence alive; an instance that
+	// goes quiet for presenceTTL is forgotten.
+	EventHeartbeat = "heartbeat"
+	// EventSync asks every other instance to republish its presence, sent
+	// when an instance starts or its backplane reconnects.
+	EventSync = "sync"
+	// EventResync is raised by a Backplane, never published: events may have
+	// been missed (e.g. across a reconnect), so presence must be rebuilt.
+	EventResync = "resync"
+)
+
+// ErrBackplaneClosed is returned by Publish after Close.
+var ErrBackplaneClosed = errors.New("backplane closed")
+
+// BackplaneEvent is what hubs exchange over a Backplane. Instance identifies
+// the publishing hub; Sender is the sending client's ID on that hub (0 for
+// system messages) so the sender can be skipped on delivery.
+type BackplaneEvent struct {
+	Kind     string          `json:"k"`
+	Instance string          `json:"i"`
+	RoomID   string          `json:"r,omitempty"`
+	Sender   uint64          `json:"s,omitempty"`
+	Message  json.RawMessage `json:"m,omitempty"`
+	Members  map[uint]int    `json:"p,omitempty"`
+}
+
+// Backplane connects the hubs of every API instance. Events published by any
+// hub, including the subscriber itself, arrive on Events in the same order on
+// every instance, which is what gives rooms a single cluster-wide message
+// order.
+type Backplane interface {
+	Publish(ctx context.Context, ev *BackplaneEvent) error
+	Events() <-chan *BackplaneEvent
+	Close() error
+}
+
+// MemoryBus is an in-process Backplane hub for tests and single-binary
+// setups: every Connect is one instance, and a Publish reaches all of them in
+// publish order.
+type MemoryBus struct {
+	mu    sync.Mutex
+	conns map[*memoryBackplane]bool
+}
+
+// NewMemoryBus creates an empty MemoryBus.
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{conns: make(map[*memoryBackplane]bool)}
+}
+
+// Connect attaches a new instance to the bus.
+func (b *MemoryBus) Connect() Backplane {
+	c := &memoryBackplane{bus: b, events: make(chan *BackplaneEvent, 1024)}
+	b.mu.Lock()
+	b.conns[c] = true
+	b.mu.Unlock()
+	return c
+}
+
+type memoryBackplane struct {
+	bus    *MemoryBus
+	events chan *BackplaneEvent
+	closed bool // guarded by bus.mu
+}
+
+// Publish delivers ev to every connected instance. Events round-trip through
+// JSON so tests exercise the same encoding as the Postgres backplane.
+func (c *memoryBackplane) Publish(ctx context.Context, ev *BackplaneEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	c.bus.mu.Lock()
+	defer c.bus.mu.Unlock()
+	if c.closed {
+		return ErrBackplaneClosed
+	}
+	for conn := range c.bus.conns {
+		var copied BackplaneEvent
+		if err := json.Unmarshal(data, &copied); err != nil {
+			return err
+		}
+		select {
+		case conn.events <- &copied:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (c *memoryBackplane) Events() <-chan *BackplaneEvent { return c.events }
+
+// Close detaches the instance; the other instances stop hearing from it.
+func (c *memoryBackplane) Close() error {
+	c.bus.mu.Lock()
+	defer c.bus.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		delete(c.bus.conns, c)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DefaultBackplaneChannel is the Postgres NOTIFY channel hubs share.
+const DefaultBackplaneChannel = "cooking_hub"
+
+// maxNotifyPayload is Postgres's NOTIFY payload limit (8000 bytes less the
+// terminator).
+const maxNotifyPayload = 7999
+
+// PostgresBackplane is a Backplane over Postgres LISTEN/NOTIFY. Events are
+// published with pg_notify on the shared pool and received on a dedicated
+// listening connection. Postgres delivers notifications in commit order to
+// every listener, so all instances see one event order. Events larger than
+// the NOTIFY payload limit can't be published.
+type PostgresBackplane struct {
+	db      *gorm.DB
+	dsn     string
+	channel string
+	events  chan *BackplaneEvent
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	closed bool
+}
+
+// NewPostgresBackplane connects the listener to dsn and starts receiving
+// events. The connection is re-established with backoff if it drops; a
+// resync event follows each reconnect, as notifications sent in between are
+// lost.
+func NewPostgresBackplane(ctx context.Context, db *gorm.DB, dsn, channel string) (*PostgresBackplane, error) {
+	if channel == "" {
+		channel = DefaultBackplaneChannel
+	}
+	b := &PostgresBackplane{
+		db:      db,
+		dsn:     dsn,
+		channel: channel,
+		events:  make(chan *BackplaneEvent, 1024),
+	}
+	conn, err := b.listen(ctx)
+	if err != nil {
+		return nil, err
+	}
+	runCtx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	go b.receive(runCtx, conn)
+	return b, nil
+}
+
+// listen opens the listening connection.
+func (b *PostgresBackplane) listen(ctx context.Context) (*pgx.Conn, error) {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return nil, fmt.Errorf("connect backplane listener: %w", err)
+	}
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{b.channel}.Sanitize()); err != nil {
+		conn.Close(ctx)
+		return nil, fmt.Errorf("listen on %s: %w", b.channel, err)
+	}
+	return conn, nil
+}
+
+// receive decodes notifications into events until ctx is cancelled,
+// reconnecting whenever the listening connection fails.
+func (b *PostgresBackplane) receive(ctx context.Context, conn *pgx.Conn) {
+	log := logger.Get()
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err == nil {
+			var ev BackplaneEvent
+			if err := json.Unmarshal([]byte(n.Payload), &ev); err != nil {
+				log.Warn("backplane: dropping undecodable event", zap.Error(err))
+				continue
+			}
+			b.events <- &ev
+			continue
+		}
+		conn.Close(context.Background())
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Warn("backplane: listener connection lost, reconnecting", zap.Error(err))
+		for backoff := time.Second; ; backoff = min(2*backoff, 30*time.Second) {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if conn, err = b.listen(ctx); err == nil {
+				break
+			}
+			log.Warn("backplane: reconnect failed", zap.Error(err))
+		}
+		b.events <- &BackplaneEvent{Kind: EventResync}
+	}
+}
+
+// Publish sends ev to every listening instance.
+func (b *PostgresBackplane) Publish(ctx context.Context, ev *BackplaneEvent) error {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return ErrBackplaneClosed
+	}
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		return fmt.Errorf("backplane event is %d bytes, over the %d-byte NOTIFY limit", len(payload), maxNotifyPayload)
+	}
+	return b.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", b.channel, string(payload)).Error
+}
+
+func (b *PostgresBackplane) Events() <-chan *BackplaneEvent { return b.events }
+
+// Close stops listening; later Publish calls fail.
+func (b *PostgresBackplane) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.closed = true
+		b.cancel()
+	}
+	return nil
+}
@@ -0,0 +1,349 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/service"
+	"github.com/windoze95/saltybytes-api/internal/util"
+	"go.uber.org/zap"
+)
+
+// WebSocket message types for the cook plan protocol. Ping, pong, error and
+// connected are shared with cooking mode.
+const (
+	MsgTypePlanStart     = "plan_start"      // Client asks for a plan (request), then the room is sent the plan
+	MsgTypePlanStepStart = "plan_step_start" // A planned step is due to start now
+	MsgTypePlanServe     = "plan_serve"      // Every dish is done; time to serve
+	MsgTypePlanCancel    = "plan_cancel"     // Plan cancelled (client request, then room broadcast)
+)
+
+// planBuildTimeout bounds building a plan, including its AI step timing.
+const planBuildTimeout = 60 * time.Second
+
+// PlanStartPayload asks for a plan for the given recipes. ServeAt is RFC
+// 3339; when omitted (or too soon) the plan serves as soon as possible.
+// Starting a plan replaces any plan already running for the user.
+type PlanStartPayload struct {
+	RecipeIDs []uint    `json:"recipe_ids"`
+	ServeAt   time.Time `json:"serve_at"`
+	Ovens     int       `json:"ovens,omitempty"`
+	Burners   int       `json:"burners,omitempty"`
+}
+
+// PlanStartedPayload is the plan sent to the room when it starts. PlanID
+// tells the instances apart which plan replaced which.
+type PlanStartedPayload struct {
+	*service.CookPlan
+	PlanID string `json:"plan_id"`
+}
+
+// PlanStepStartPayload prompts the cook to start a step now. Message is a
+// ready-to-show (or speak) prompt.
+type PlanStepStartPayload struct {
+	service.PlannedStep
+	Message string `json:"message"`
+}
+
+// PlanServePayload says every dish in the plan is done.
+type PlanServePayload struct {
+	ServeAt time.Time `json:"serve_at"`
+	Message string    `json:"message"`
+}
+
+// PlanConnectedPayload confirms a cook plan connection. Plan is the plan
+// running for the user, when one was started on this instance.
+type PlanConnectedPayload struct {
+	UserID uint              `json:"user_id"`
+	Plan   *service.CookPlan `json:"plan,omitempty"`
+}
+
+// CookPlanHandler manages WebSocket connections for multi-dish cook plans.
+// Each user has one plan room, shared by all their devices: the plan is sent
+// to the room as a plan_start message when it is built, then plan_step_start
+// messages as each step comes due and plan_serve at serving time.
+//
+// A plan runs on the instance that built it, but the room spans instances,
+// so plans are stopped through the room itself: every instance watches its
+// plan rooms, and a plan_cancel, or another plan's plan_start, stops the
+// plan running there.
+type CookPlanHandler struct {
+	Hub       *Hub
+	JwtSecret string
+	Plans     *service.CookPlanService
+
+	mu      sync.Mutex
+	running map[string]*runningPlan // roomID -> plan
+}
+
+// runningPlan is a plan whose prompts are being sent to its room. It is
+// announced once its own plan_start has come back through the room; only
+// then can room messages stop it, so a cancel or plan sent before it started
+// does not.
+type runningPlan struct {
+	id        string
+	plan      *service.CookPlan
+	cancel    context.CancelFunc
+	announced bool
+}
+
+// NewCookPlanHandler returns a new CookPlanHandler.
+func NewCookPlanHandler(hub *Hub, jwtSecret string, plans *service.CookPlanService) *CookPlanHandler {
+	ph := &CookPlanHandler{
+		Hub:       hub,
+		JwtSecret: jwtSecret,
+		Plans:     plans,
+		running:   make(map[string]*runningPlan),
+	}
+	hub.Observe(ph.observe)
+	return ph
+}
+
+// planRoomPrefix starts every plan room ID. Cooking rooms are recipe IDs,
+// so the prefix keeps the two apart.
+const planRoomPrefix = "plan:"
+
+// planRoomID is the room for a user's cook plan.
+func planRoomID(userID uint) string {
+	return fmt.Sprintf("%s%d", planRoomPrefix, userID)
+}
+
+// HandleCookPlan upgrades an HTTP request to a WebSocket connection for the
+// user's cook plan room, authenticating like cooking mode.
+func (ph *CookPlanHandler) HandleCookPlan(c *gin.Context) {
+	log := logger.Get()
+
+	userID, ok := authenticateQueryToken(c, ph.JwtSecret)
+	if !ok {
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Error("websocket upgrade failed", zap.Uint("user_id", userID), zap.Error(err))
+		return
+	}
+
+	client := NewClient(ph.Hub, conn, planRoomID(userID), userID)
+	ph.Hub.Register <- client
+
+	connectedPayload, _ := json.Marshal(PlanConnectedPayload{
+		UserID: userID,
+		Plan:   ph.currentPlan(client.RoomID),
+	})
+	connectedMsg, _ := json.Marshal(WSMessage{
+		Type:    MsgTypeConnected,
+		Payload: connectedPayload,
+	})
+	client.TrySend(connectedMsg)
+
+	go client.WritePump()
+	go client.ReadPump(func(cl *Client, data []byte) {
+		ph.handleMessage(cl, data)
+	})
+}
+
+// handleMessage routes a cook plan message.
+func (ph *CookPlanHandler) handleMessage(client *Client, data []byte) {
+	var msg WSMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		sendClientError(client, "invalid message format")
+		return
+	}
+
+	switch msg.Type {
+	case MsgTypePlanStart:
+		// Building runs an AI pass per recipe; keep it off the read pump.
+		if !client.tryAcquireHandlerSlot() {
+			sendClientError(client, "too many requests in flight; please wait")
+			return
+		}
+		go func() {
+			defer client.releaseHandlerSlot()
+			defer util.RecoverPanic("cook plan ws async handler")
+			ph.handlePlanStart(client, msg.Payload)
+		}()
+	case MsgTypePlanCancel:
+		// The plan may be running on another instance; the broadcast stops
+		// it wherever it is (see observe).
+		sendToRoom(ph.Hub, client.RoomID, MsgTypePlanCancel, struct{}{})
+	case MsgTypePing:
+		pongMsg, _ := json.Marshal(WSMessage{
+			Type:    MsgTypePong,
+			Payload: json.RawMessage("{}"),
+		})
+		client.TrySend(pongMsg)
+	default:
+		sendClientError(client, "unknown message type: "+msg.Type)
+	}
+}
+
+// handlePlanStart builds the requested plan and starts prompting the room.
+func (ph *CookPlanHandler) handlePlanStart(client *Client, payload json.RawMessage) {
+	var req PlanStartPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		sendClientError(client, "invalid plan payload")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), planBuildTimeout)
+	defer cancel()
+	plan, err := ph.Plans.BuildPlan(ctx, client.UserID, service.CookPlanRequest{
+		RecipeIDs: req.RecipeIDs,
+		ServeAt:   req.ServeAt,
+		Ovens:     req.Ovens,
+		Burners:   req.Burners,
+	})
+	switch {
+	case errors.Is(err, service.ErrNoPlanRecipes),
+		errors.Is(err, service.ErrTooManyPlanRecipes),
+		errors.Is(err, service.ErrPlanRecipeNoSteps):
+		sendClientError(client, err.Error())
+		return
+	case errors.Is(err, service.ErrPlanRecipeNotOwned):
+		sendClientError(client, "you do not own one of these recipes")
+		return
+	case err != nil:
+		logger.Get().Error("failed to build cook plan",
+			zap.Uint("user_id", client.UserID),
+			zap.Error(err),
+		)
+		sendClientError(client, "failed to build cook plan")
+		return
+	}
+
+	ph.run(client.RoomID, plan)
+}
+
+// run sends the plan to its room and then prompts each step as it comes
+// due, replacing any plan already running there. A plan running on another
+// instance stops when this one's plan_start reaches it.
+func (ph *CookPlanHandler) run(roomID string, plan *service.CookPlan) {
+	ctx, cancel := context.WithCancel(context.Background())
+	current := &runningPlan{id: newRandomID(), plan: plan, cancel: cancel}
+	ph.mu.Lock()
+	if prev := ph.running[roomID]; prev != nil {
+		prev.cancel()
+	}
+	ph.running[roomID] = current
+	ph.mu.Unlock()
+
+	sendToRoom(ph.Hub, roomID, MsgTypePlanStart, PlanStartedPayload{CookPlan: plan, PlanID: current.id})
+	go func() {
+		defer util.RecoverPanic("cook plan scheduler")
+		defer func() {
+			ph.mu.Lock()
+			if ph.running[roomID] == current {
+				delete(ph.running, roomID)
+			}
+			ph.mu.Unlock()
+		}()
+
+		for _, step := range plan.Steps {
+			if !waitUntil(ctx, step.StartAt) {
+				return
+			}
+			sendToRoom(ph.Hub, roomID, MsgTypePlanStepStart, PlanStepStartPayload{
+				PlannedStep: step,
+				Message:     fmt.Sprintf("Start %s now: %s", step.RecipeTitle, step.Text),
+			})
+		}
+		if !waitUntil(ctx, plan.ServeAt) {
+			return
+		}
+		sendToRoom(ph.Hub, roomID, MsgTypePlanServe, PlanServePayload{
+			ServeAt: plan.ServeAt,
+			Message: "Everything is ready. Time to serve!",
+		})
+	}()
+}
+
+// observe watches the messages of every plan room, from any instance, to
+// stop this instance's plan when it is cancelled or replaced.
+func (ph *CookPlanHandler) observe(roomID string, message []byte) {
+	if !strings.HasPrefix(roomID, planRoomPrefix) {
+		return
+	}
+	var msg WSMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return
+	}
+	if msg.Type != MsgTypePlanStart && msg.Type != MsgTypePlanCancel {
+		return
+	}
+
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+	current := ph.running[roomID]
+	if current == nil {
+		return
+	}
+	if msg.Type == MsgTypePlanStart {
+		var started PlanStartedPayload
+		if err := json.Unmarshal(msg.Payload, &started); err != nil {
+			return
+		}
+		if started.PlanID == current.id {
+			current.announced = true
+			return
+		}
+	}
+	if current.announced {
+		current.cancel()
+		delete(ph.running, roomID)
+	}
+}
+
+// currentPlan is the plan running for the room on this instance, if any.
+func (ph *CookPlanHandler) currentPlan(roomID string) *service.CookPlan {
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+	if current := ph.running[roomID]; current != nil {
+		return current.plan
+	}
+	return nil
+}
+
+// waitUntil blocks until t, returning false if ctx ends first.
+func waitUntil(ctx context.Context, t time.Time) bool {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// sendToRoom broadcasts a message to every client in a room.
+func sendToRoom(hub *Hub, roomID, msgType string, payload any) {
+	body, _ := json.Marshal(payload)
+	msg, _ := json.Marshal(WSMessage{
+		Type:    msgType,
+		Payload: body,
+	})
+	hub.Broadcast <- &RoomMessage{
+		RoomID:  roomID,
+		Message: msg,
+	}
+}
+
+// sendClientError sends an error message to a single client.
+func sendClientError(client *Client, message string) {
+	errPayload, _ := json.Marshal(ErrorPayload{
+		Message: message,
+	})
+	errMsg, _ := json.Marshal(WSMessage{
+		Type:    MsgTypeError,
+		Payload: errPayload,
+	})
+	client.TrySend(errMsg)
+}
@@ -0,0 +1,130 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/service"
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// setupCookPlanHandler returns a plan handler over two of user 1's recipes
+// (1 and 2) and a client of theirs joined to the plan room.
+func setupCookPlanHandler(t *testing.T) (*CookPlanHandler, *Client) {
+	t.Helper()
+	repo := testutil.NewMockRecipeRepo()
+	repo.Recipes[1] = &models.Recipe{Model: gorm.Model{ID: 1}, CreatedByID: 1,
+		RecipeDef: models.RecipeDef{Title: "Salad", Instructions: pq.StringArray{"Toss the greens"}}}
+	repo.Recipes[2] = &models.Recipe{Model: gorm.Model{ID: 2}, CreatedByID: 1,
+		RecipeDef: models.RecipeDef{Title: "Soup", Instructions: pq.StringArray{"Simmer for 30 minutes", "Blend"}}}
+
+	hub := NewHub()
+	go hub.Run()
+	ph := NewCookPlanHandler(hub, "test-secret", service.NewCookPlanService(nil, repo))
+	client := newTestClient(hub, planRoomID(1), 1)
+	hub.Register <- client
+	return ph, client
+}
+
+func TestCookPlan_PromptsDueStepsAndCancels(t *testing.T) {
+	ph, client := setupCookPlanHandler(t)
+
+	ph.handlePlanStart(client, json.RawMessage(`{"recipe_ids":[1,2]}`))
+
+	msg := readMessage(t, client)
+	if msg.Type != MsgTypePlanStart {
+		t.Fatalf("expected type %q, got %q", MsgTypePlanStart, msg.Type)
+	}
+	var plan service.CookPlan
+	if err := json.Unmarshal(msg.Payload, &plan); err != nil {
+		t.Fatalf("failed to unmarshal plan: %v", err)
+	}
+	if len(plan.Steps) != 3 {
+		t.Fatalf("plan has %d steps, want 3", len(plan.Steps))
+	}
+
+	// Served as soon as possible, the soup's simmer is due immediately.
+	msg = readMessage(t, client)
+	if msg.Type != MsgTypePlanStepStart {
+		t.Fatalf("expected type %q, got %q", MsgTypePlanStepStart, msg.Type)
+	}
+	var prompt PlanStepStartPayload
+	_ = json.Unmarshal(msg.Payload, &prompt)
+	if prompt.RecipeID != 2 || prompt.Step != 1 || prompt.Message != "Start Soup now: Simmer for 30 minutes" {
+		t.Errorf("prompt = %+v", prompt)
+	}
+	assertNoMoreMessages(t, client)
+	if ph.currentPlan(client.RoomID) == nil {
+		t.Fatal("plan should be running")
+	}
+
+	ph.handleMessage(client, []byte(`{"type":"plan_cancel","payload":{}}`))
+	if msg := readMessage(t, client); msg.Type != MsgTypePlanCancel {
+		t.Fatalf("expected type %q, got %q", MsgTypePlanCancel, msg.Type)
+	}
+	if ph.currentPlan(client.RoomID) != nil {
+		t.Error("cancelled plan is still running")
+	}
+}
+
+func TestCookPlan_StoppedFromAnotherInstance(t *testing.T) {
+	repo := testutil.NewMockRecipeRepo()
+	repo.Recipes[1] = &models.Recipe{Model: gorm.Model{ID: 1}, CreatedByID: 1,
+		RecipeDef: models.RecipeDef{Title: "Salad", Instructions: pq.StringArray{"Toss the greens"}}}
+	plans := service.NewCookPlanService(nil, repo)
+
+	bus := NewMemoryBus()
+	hubA, _ := newClusterHub(bus)
+	hubB, _ := newClusterHub(bus)
+	phA := NewCookPlanHandler(hubA, "test-secret", plans)
+	phB := NewCookPlanHandler(hubB, "test-secret", plans)
+	phone := newTestClient(hubA, planRoomID(1), 1)
+	tablet := newTestClient(hubB, planRoomID(1), 1)
+	hubA.Register <- phone
+	hubB.Register <- tablet
+
+	announced := func() bool {
+		phA.mu.Lock()
+		defer phA.mu.Unlock()
+		current := phA.running[phone.RoomID]
+		return current != nil && current.announced
+	}
+
+	// Cancelled from a device on the other instance.
+	phA.handlePlanStart(phone, json.RawMessage(`{"recipe_ids":[1]}`))
+	eventually(t, "plan announced", announced)
+	phB.handleMessage(tablet, []byte(`{"type":"plan_cancel","payload":{}}`))
+	eventually(t, "plan cancelled", func() bool { return phA.currentPlan(phone.RoomID) == nil })
+
+	// Replaced by a plan started on the other instance.
+	phA.handlePlanStart(phone, json.RawMessage(`{"recipe_ids":[1]}`))
+	eventually(t, "first plan announced", announced)
+	phB.handlePlanStart(tablet, json.RawMessage(`{"recipe_ids":[1]}`))
+	eventually(t, "first plan replaced", func() bool { return phA.currentPlan(phone.RoomID) == nil })
+	if phB.currentPlan(tablet.RoomID) == nil {
+		t.Error("replacement plan should still be running")
+	}
+}
+
+func TestCookPlan_Errors(t *testing.T) {
+	ph, client := setupCookPlanHandler(t)
+
+	ph.handlePlanStart(client, json.RawMessage(`{"recipe_ids":[]}`))
+	if got := readErrorMessage(t, client); got != service.ErrNoPlanRecipes.Error() {
+		t.Errorf("unexpected error message: %q", got)
+	}
+
+	other := newTestClient(ph.Hub, planRoomID(2), 2)
+	ph.handlePlanStart(other, json.RawMessage(`{"recipe_ids":[1]}`))
+	if got := readErrorMessage(t, other); got != "you do not own one of these recipes" {
+		t.Errorf("unexpected error message: %q", got)
+	}
+
+	ph.handleMessage(client, []byte(`{"type":"step_change","payload":{}}`))
+	if got := readErrorMessage(t, client); got != "unknown message type: step_change" {
+		t.Errorf("unexpected error message: %q", got)
+	}
+}
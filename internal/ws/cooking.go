@@ -3,6 +3,7 @@ package ws
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/windoze95/saltybytes-api/internal/ai"
 	"github.com/windoze95/saltybytes-api/internal/logger"
@@ -23,19 +25,28 @@ import (
 
 // WebSocket message types for the cooking protocol.
 const (
-	MsgTypeChatMessage     = "chat_message"     // User sends a cooking Q&A question
-	MsgTypeChatResponse    = "chat_response"    // AI responds to cooking Q&A
-	MsgTypeEphemeralEdit   = "ephemeral_edit"   // Temporary recipe modification
-	MsgTypeEphemeralReset  = "ephemeral_reset"  // Reset ephemeral edits
-	MsgTypeVoiceTranscript = "voice_transcript" // Audio transcription result
-	MsgTypeVoiceIntent     = "voice_intent"     // Classified voice intent
-	MsgTypeScrollCommand   = "scroll_command"   // Voice-driven scroll
-	MsgTypeNavigateCommand = "navigate_command" // Voice-driven navigation
-	MsgTypeError           = "error"            // Error message
-	MsgTypeConnected       = "connected"        // Connection confirmed
-	MsgTypeStepChange      = "step_change"      // User moved to a different recipe step
-	MsgTypePing            = "ping"             // Client keepalive probe
-	MsgTypePong            = "pong"             // Server keepalive reply
+	MsgTypeChatMessage     = "chat_message"       // User sends a cooking Q&A question
+	MsgTypeChatResponse    = "chat_response"      // AI responds to cooking Q&A
+	MsgTypeChatDelta       = "chat_delta"         // Part of a cooking Q&A answer as it streams in
+	MsgTypeEphemeralEdit   = "ephemeral_edit"     // Temporary recipe modification
+	MsgTypeEphemeralReset  = "ephemeral_reset"    // Reset ephemeral edits
+	MsgTypeVoiceTranscript = "voice_transcript"   // Audio transcription result
+	MsgTypeVoiceIntent     = "voice_intent"       // Classified voice intent
+	MsgTypeScrollCommand   = "scroll_command"     // Voice-driven scroll
+	MsgTypeNavigateCommand = "navigate_command"   // Voice-driven navigation
+	MsgTypeError           = "error"              // Error message
+	MsgTypeConnected       = "connected"          // Connection confirmed
+	MsgTypeStepChange      = "step_change"        // User moved to a different recipe step
+	MsgTypePing            = "ping"               // Client keepalive probe
+	MsgTypePong            = "pong"               // Server keepalive reply
+	MsgTypeReadStep        = "read_step"          // User asks for a recipe step read aloud
+	MsgTypeSpeechAudio     = "speech_audio"       // Synthesized read-aloud audio
+	MsgTypePresence        = "presence"           // Who is in the room, across all instances
+	MsgTypeTimerStart      = "timer_start"        // Kitchen timer started (client request, then room broadcast)
+	MsgTypeTimerCancel     = "timer_cancel"       // Kitchen timer cancelled (client request, then room broadcast)
+	MsgTypeParticipantJoin = "participant_joined" // Someone joined the cooking room
+	MsgTypeParticipantLeft = "participant_left"   // Someone left the cooking room
+	MsgTypeSessionEnd      = "session_end"        // Host finished cooking (client request, then room broadcast with a cook log draft)
 )
 
 // WSMessage is the envelope for all messages sent over the cooking WebSocket.
@@ -45,14 +56,30 @@ type WSMessage struct {
 }
 
 // ChatMessagePayload is sent by the client to ask a cooking question.
+// Speak asks for the answer to be read aloud as well (see
+// SpeechAudioPayload). RecipeContext is only used when the server does not
+// ground answers itself (CookingHandler.QA is nil).
 type ChatMessagePayload struct {
 	Message       string `json:"message"`
 	RecipeContext string `json:"recipe_context,omitempty"`
+	Speak         bool   `json:"speak,omitempty"`
 }
 
-// ChatResponsePayload is sent by the server with an AI answer.
+// ChatResponsePayload is sent by the server with an AI answer. When the
+// answer was streamed, ID matches the chat_delta messages that preceded it
+// and Message is the full answer. AllergyAlerts lists the allergies of the
+// people cooking together when the question asked for a substitution.
 type ChatResponsePayload struct {
-	Message string `json:"message"`
+	ID            string                       `json:"id,omitempty"`
+	Message       string                       `json:"message"`
+	AllergyAlerts []service.ParticipantAllergy `json:"allergy_alerts,omitempty"`
+}
+
+// ChatDeltaPayload carries the next chunk of a streaming answer. Chunks
+// sharing an ID concatenate, in order, to the answer's chat_response.
+type ChatDeltaPayload struct {
+	ID    string `json:"id"`
+	Delta string `json:"delta"`
 }
 
 // EphemeralEditPayload represents a temporary recipe modification.
@@ -65,23 +92,69 @@ type EphemeralEditPayload struct {
 // Transcript is set (on-device STT), Whisper is skipped and the text goes
 // straight to intent classification. Otherwise AudioData is transcribed
 // first; Format optionally names its container format (e.g. "webm", "m4a").
+// Speak asks for answers to spoken questions to be read aloud as well.
 type VoiceTranscriptPayload struct {
 	Transcript string `json:"transcript"`
 	AudioData  []byte `json:"audio_data,omitempty"` // base64-encoded
 	Format     string `json:"format,omitempty"`     // audio format for AudioData; defaults to webm
+	Speak      bool   `json:"speak,omitempty"`
 }
 
 // StepChangePayload reports which recipe step the user is currently viewing.
+// StepNumber is 1-based, like read_step, voice intents and speech audio.
+// Step is the original field, kept as the client sends it so existing
+// clients keep working; when both are set StepNumber wins. The server relays
+// it to the rest of the room with UserID set to whoever moved.
 type StepChangePayload struct {
+	Step       int  `json:"step"`
+	StepNumber int  `json:"step_number,omitempty"`
+	UserID     uint `json:"user_id,omitempty"`
+}
+
+// ReadStepPayload asks for a recipe step to be read aloud. Step is 1-based,
+// as in step_change's step_number; 0 reads the step last reported via
+// step_change.
+type ReadStepPayload struct {
 	Step int `json:"step"`
 }
 
 // VoiceIntentPayload carries the classified intent of a voice command.
 type VoiceIntentPayload struct {
-	Type   string `json:"type"`             // scroll_up, scroll_down, navigate, question, ignore
+	Type   string `json:"type"`             // scroll_up, scroll_down, navigate, question, read_step, ignore
 	Amount string `json:"amount,omitempty"` // small, large
 	Target string `json:"target,omitempty"` // ingredients, instructions
 	Text   string `json:"text,omitempty"`   // for question type
+	Step   int    `json:"step,omitempty"`   // for read_step type
+}
+
+// SpeechAudioPayload carries read-aloud audio: an answer (Step 0) or a recipe
+// step. Answers arrive inline in AudioData; cached step audio arrives as
+// AudioURL for the client to fetch.
+type SpeechAudioPayload struct {
+	Text      string `json:"text"`
+	Step      int    `json:"step,omitempty"`
+	Format    string `json:"format"`               // mp3, wav
+	AudioData []byte `json:"audio_data,omitempty"` // base64-encoded
+	AudioURL  string `json:"audio_url,omitempty"`
+}
+
+// TimerStartPayload asks for a kitchen timer; the room is sent the started
+// models.CookingTimer, with its ID and end time, as a timer_start message.
+type TimerStartPayload struct {
+	Label           string `json:"label,omitempty"`
+	Step            int    `json:"step,omitempty"`
+	DurationSeconds int    `json:"duration_seconds"`
+}
+
+// TimerCancelPayload names the timer to cancel, in both directions.
+type TimerCancelPayload struct {
+	ID string `json:"id"`
+}
+
+// PresencePayload answers a presence request with the room's members on
+// every instance.
+type PresencePayload struct {
+	Members []RoomMember `json:"members"`
 }
 
 // ScrollCommandPayload drives voice-driven scrolling.
@@ -95,15 +168,34 @@ type NavigateCommandPayload struct {
 	Target string `json:"target"` // ingredients, instructions, step_N
 }
 
+// ParticipantPayload announces someone joining or leaving the cooking room.
+// Host is true for the recipe's owner.
+type ParticipantPayload struct {
+	UserID uint   `json:"user_id"`
+	Name   string `json:"name,omitempty"`
+	Host   bool   `json:"host,omitempty"`
+}
+
+// SessionEndPayload tells the room the host ended the cooking session.
+// CookLogDraft, when present, prefills an "I made this" entry that each
+// participant can rate and confirm via POST /v1/cook-logs.
+type SessionEndPayload struct {
+	CookLogDraft *service.CookLogDraft `json:"cook_log_draft,omitempty"`
+}
+
 // ErrorPayload carries an error message to the client.
 type ErrorPayload struct {
 	Message string `json:"message"`
 }
 
-// ConnectedPayload confirms a successful connection.
+// ConnectedPayload confirms a successful connection. Session is the full
+// state of the user's cooking session for the recipe (current step, edits,
+// running timers and Q&A history), so a reconnecting client can restore it;
+// it is absent when sessions are unavailable.
 type ConnectedPayload struct {
-	RecipeID string `json:"recipe_id"`
-	UserID   uint   `json:"user_id"`
+	RecipeID string                 `json:"recipe_id"`
+	UserID   uint                   `json:"user_id"`
+	Session  *models.CookingSession `json:"session,omitempty"`
 }
 
 // RecipeLookup is used by the cooking handler to verify recipe ownership.
@@ -111,12 +203,30 @@ type RecipeLookup interface {
 	GetRecipeByID(recipeID uint) (*models.Recipe, error)
 }
 
+// UserLookup is used by the cooking handler to name room participants.
+type UserLookup interface {
+	GetUserByID(userID uint) (*models.User, error)
+}
+
 // CookingHandler manages WebSocket connections for cooking mode.
 type CookingHandler struct {
 	Hub          *Hub
 	JwtSecret    string
 	VoiceService *service.VoiceService
 	Recipes      RecipeLookup
+	// Sessions persists cooking state across reconnects; nil keeps it only
+	// for the life of the connection (and disables timers).
+	Sessions *service.CookingSessionService
+	// QA answers questions from server-side recipe, session and household
+	// state, streaming them as chat_delta messages; nil answers each
+	// question on its own from the client's RecipeContext.
+	QA *service.CookingQAService
+	// Users names participants in join and leave events; nil sends IDs only.
+	Users UserLookup
+	// CookLogs drafts a cook log when the session ends; nil ends it without.
+	CookLogs *service.CookLogService
+
+	writes sessionWriter
 }
 
 // NewCookingHandler returns a new CookingHandler.
@@ -157,48 +267,58 @@ var upgrader = websocket.Upgrader{
 	WriteBufferSize: 1024,
 }
 
-// HandleCookingSession upgrades an HTTP request to a WebSocket connection
-// for cooking mode. Authentication is done via a "token" query parameter
-// because WebSocket connections cannot easily use Authorization headers.
-func (ch *CookingHandler) HandleCookingSession(c *gin.Context) {
-	log := logger.Get()
-
-	recipeID := c.Param("recipe_id")
-	if recipeID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "recipe_id is required"})
-		return
-	}
-
-	// Authenticate via query param token
+// authenticateQueryToken checks the access token in the "token" query
+// parameter (WebSocket connections cannot easily use Authorization headers)
+// and returns its user ID. On failure it writes the error response and
+// returns false.
+func authenticateQueryToken(c *gin.Context, jwtSecret string) (uint, bool) {
 	tokenString := c.Query("token")
 	if tokenString == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"message": "token query parameter is required"})
-		return
+		return 0, false
 	}
 
 	claims := jwt.MapClaims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(ch.JwtSecret), nil
+		return []byte(jwtSecret), nil
 	}, jwt.WithValidMethods([]string{"HS256"}))
 	if err != nil || !token.Valid {
 		c.JSON(http.StatusUnauthorized, gin.H{"message": "invalid or expired token"})
-		return
+		return 0, false
 	}
 
 	// Ensure this is an access token
 	tokenType, ok := claims["type"].(string)
 	if !ok || tokenType != "access" {
 		c.JSON(http.StatusUnauthorized, gin.H{"message": "invalid token type"})
-		return
+		return 0, false
 	}
 
 	// Extract user ID
 	idFloat, ok := claims["user_id"].(float64)
 	if !ok {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid user_id in token"})
+		return 0, false
+	}
+	return uint(idFloat), true
+}
+
+// HandleCookingSession upgrades an HTTP request to a WebSocket connection
+// for cooking mode. Authentication is done via a "token" query parameter
+// because WebSocket connections cannot easily use Authorization headers.
+func (ch *CookingHandler) HandleCookingSession(c *gin.Context) {
+	log := logger.Get()
+
+	recipeID := c.Param("recipe_id")
+	if recipeID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "recipe_id is required"})
+		return
+	}
+
+	userID, ok := authenticateQueryToken(c, ch.JwtSecret)
+	if !ok {
 		return
 	}
-	userID := uint(idFloat)
 
 	// Verify the user owns this recipe before granting WebSocket access
 	recipeIDUint, parseErr := strconv.ParseUint(recipeID, 10, 64)
@@ -211,8 +331,7 @@ func (ch *CookingHandler) HandleCookingSession(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"message": "recipe not found"})
 		return
 	}
-	if recipe.CreatedByID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"message": "you do not own this recipe"})
+	if !ch.authorizeJoin(c, userID, recipe) {
 		return
 	}
 
@@ -229,12 +348,16 @@ func (ch *CookingHandler) HandleCookingSession(c *gin.Context) {
 
 	// Create client and register with hub
 	client := NewClient(ch.Hub, conn, recipeID, userID)
+	client.HostID = recipe.CreatedByID
+	session := ch.openSession(client, recipe)
 	ch.Hub.Register <- client
+	ch.announceParticipant(client, MsgTypeParticipantJoin)
 
 	// Send connected confirmation
 	connectedPayload, _ := json.Marshal(ConnectedPayload{
 		RecipeID: recipeID,
 		UserID:   userID,
+		Session:  session,
 	})
 	connectedMsg, _ := json.Marshal(WSMessage{
 		Type:    MsgTypeConnected,
@@ -249,9 +372,68 @@ func (ch *CookingHandler) HandleCookingSession(c *gin.Context) {
 
 	// Start read and write pumps
 	go client.WritePump()
-	go client.ReadPump(func(cl *Client, data []byte) {
-		ch.handleMessage(cl, data)
+	go func() {
+		client.ReadPump(func(cl *Client, data []byte) {
+			ch.handleMessage(cl, data)
+		})
+		ch.announceParticipant(client, MsgTypeParticipantLeft)
+	}()
+}
+
+// authorizeJoin checks that the user may cook recipe: its owner, or with
+// sessions enabled anyone the owner has let into their kitchen (family
+// members, or holders of the room's join code). On failure it writes the
+// error response and returns false.
+func (ch *CookingHandler) authorizeJoin(c *gin.Context, userID uint, recipe *models.Recipe) bool {
+	if ch.Sessions == nil {
+		if recipe.CreatedByID != userID {
+			c.JSON(http.StatusForbidden, gin.H{"message": "you do not own this recipe"})
+			return false
+		}
+		return true
+	}
+	err := ch.Sessions.AuthorizeJoin(c.Request.Context(), userID, recipe, c.Query("code"))
+	if errors.Is(err, service.ErrNotInvited) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "you are not invited to cook this recipe"})
+		return false
+	}
+	if err != nil {
+		logger.Get().Error("failed to authorize cooking room join",
+			zap.Uint("recipe_id", recipe.ID),
+			zap.Uint("user_id", userID),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to join cooking session"})
+		return false
+	}
+	return true
+}
+
+// announceParticipant tells the rest of the room that the client joined or
+// left.
+func (ch *CookingHandler) announceParticipant(client *Client, msgType string) {
+	payload := ParticipantPayload{
+		UserID: client.UserID,
+		Host:   client.UserID == client.SessionOwner(),
+	}
+	if ch.Users != nil {
+		if user, err := ch.Users.GetUserByID(client.UserID); err == nil {
+			payload.Name = user.FirstName
+			if payload.Name == "" {
+				payload.Name = user.Username
+			}
+		}
+	}
+	body, _ := json.Marshal(payload)
+	msg, _ := json.Marshal(WSMessage{
+		Type:    msgType,
+		Payload: body,
 	})
+	ch.Hub.Broadcast <- &RoomMessage{
+		RoomID:  client.RoomID,
+		Message: msg,
+		Sender:  client,
+	}
 }
 
 // handleMessage parses an incoming WebSocket message and routes it to the
@@ -278,6 +460,12 @@ func (ch *CookingHandler) handleMessage(client *Client, data []byte) {
 		ch.dispatchAsync(client, msg.Payload, ch.handleChatMessage)
 
 	case MsgTypeEphemeralEdit:
+		var edit EphemeralEditPayload
+		if err := json.Unmarshal(msg.Payload, &edit); err == nil && edit.Modification != "" {
+			ch.saveSession(client, "ephemeral_edit", func(ctx context.Context, recipeID uint) error {
+				return ch.Sessions.AddEdit(ctx, client.SessionOwner(), recipeID, edit.StepIndex, edit.Modification)
+			})
+		}
 		// Broadcast ephemeral edit to all clients in the room
 		ch.Hub.Broadcast <- &RoomMessage{
 			RoomID:  client.RoomID,
@@ -286,6 +474,9 @@ func (ch *CookingHandler) handleMessage(client *Client, data []byte) {
 		}
 
 	case MsgTypeEphemeralReset:
+		ch.saveSession(client, "ephemeral_reset", func(ctx context.Context, recipeID uint) error {
+			return ch.Sessions.ResetEdits(ctx, client.SessionOwner(), recipeID)
+		})
 		// Broadcast reset to all clients in the room
 		ch.Hub.Broadcast <- &RoomMessage{
 			RoomID:  client.RoomID,
@@ -300,6 +491,29 @@ func (ch *CookingHandler) handleMessage(client *Client, data []byte) {
 	case MsgTypeStepChange:
 		ch.handleStepChange(client, msg.Payload)
 
+	case MsgTypeReadStep:
+		// Slow on a cache miss (TTS round-trip): run async.
+		ch.dispatchAsync(client, msg.Payload, ch.handleReadStep)
+
+	case MsgTypeTimerStart:
+		ch.handleTimerStart(client, msg.Payload)
+
+	case MsgTypeTimerCancel:
+		ch.handleTimerCancel(client, msg.Payload)
+
+	case MsgTypeSessionEnd:
+		ch.handleSessionEnd(client)
+
+	case MsgTypePresence:
+		presencePayload, _ := json.Marshal(PresencePayload{
+			Members: ch.Hub.Presence(client.RoomID),
+		})
+		presenceMsg, _ := json.Marshal(WSMessage{
+			Type:    MsgTypePresence,
+			Payload: presencePayload,
+		})
+		client.TrySend(presenceMsg)
+
 	case MsgTypePing:
 		pongMsg, _ := json.Marshal(WSMessage{
 			Type:    MsgTypePong,
@@ -331,7 +545,9 @@ func (ch *CookingHandler) dispatchAsync(client *Client, payload json.RawMessage,
 }
 
 // handleStepChange records the recipe step the user is currently viewing so
-// subsequent cooking QA answers can use it as context.
+// subsequent cooking QA answers can use it as context. The room cooks at one
+// pace: the step is shared by this instance's clients in the room, saved to
+// the session for the others, and relayed to every other device.
 func (ch *CookingHandler) handleStepChange(client *Client, payload json.RawMessage) {
 	var stepChange StepChangePayload
 	if err := json.Unmarshal(payload, &stepChange); err != nil {
@@ -339,12 +555,31 @@ func (ch *CookingHandler) handleStepChange(client *Client, payload json.RawMessa
 		return
 	}
 
-	if stepChange.Step < 0 {
+	if stepChange.Step < 0 || stepChange.StepNumber < 0 {
 		ch.sendError(client, "step must be non-negative")
 		return
 	}
+	step := stepChange.Step
+	if stepChange.StepNumber > 0 {
+		step = stepChange.StepNumber
+	}
 
-	client.SetCurrentStep(stepChange.Step)
+	ch.Hub.setRoomStep(client.RoomID, step)
+	client.SetCurrentStep(step)
+	ch.saveSession(client, "step_change", func(ctx context.Context, recipeID uint) error {
+		return ch.Sessions.SetStep(ctx, client.SessionOwner(), recipeID, step)
+	})
+
+	relayPayload, _ := json.Marshal(StepChangePayload{Step: step, StepNumber: stepChange.StepNumber, UserID: client.UserID})
+	relayMsg, _ := json.Marshal(WSMessage{
+		Type:    MsgTypeStepChange,
+		Payload: relayPayload,
+	})
+	ch.Hub.Broadcast <- &RoomMessage{
+		RoomID:  client.RoomID,
+		Message: relayMsg,
+		Sender:  client,
+	}
 }
 
 // recipeContextWithStep appends the client's current step (if known) to the
@@ -361,6 +596,61 @@ func recipeContextWithStep(client *Client, recipeContext string) string {
 	return recipeContext + "\n\n" + stepNote
 }
 
+// answerQuestion answers a cooking question and sends the answer to the
+// client. With QA set the answer is grounded on server state and streamed as
+// chat_delta messages ahead of the final chat_response; otherwise it is
+// answered from the client-supplied recipe context in one chat_response.
+func (ch *CookingHandler) answerQuestion(ctx context.Context, client *Client, question, recipeContext string) (string, error) {
+	if ch.QA == nil {
+		answer, err := ch.VoiceService.AnswerCookingQuestion(ctx, question, recipeContextWithStep(client, recipeContext))
+		if err != nil {
+			return "", err
+		}
+		ch.sendChatResponse(client, ChatResponsePayload{Message: answer})
+		return answer, nil
+	}
+
+	recipeID, err := strconv.ParseUint(client.RoomID, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid recipe_id %q: %w", client.RoomID, err)
+	}
+	step, _ := client.CurrentStep()
+	id := uuid.NewString()
+	var participants []uint
+	for _, member := range ch.Hub.Presence(client.RoomID) {
+		participants = append(participants, member.UserID)
+	}
+	answer, err := ch.QA.Answer(ctx, service.CookingQuestion{
+		UserID:       client.SessionOwner(),
+		RecipeID:     uint(recipeID),
+		Question:     question,
+		Step:         step,
+		Participants: participants,
+	}, func(delta string) {
+		deltaPayload, _ := json.Marshal(ChatDeltaPayload{ID: id, Delta: delta})
+		deltaMsg, _ := json.Marshal(WSMessage{
+			Type:    MsgTypeChatDelta,
+			Payload: deltaPayload,
+		})
+		client.TrySend(deltaMsg)
+	})
+	if err != nil {
+		return "", err
+	}
+	ch.sendChatResponse(client, ChatResponsePayload{ID: id, Message: answer.Text, AllergyAlerts: answer.AllergyAlerts})
+	return answer.Text, nil
+}
+
+// sendChatResponse sends an answer to a single client.
+func (ch *CookingHandler) sendChatResponse(client *Client, response ChatResponsePayload) {
+	responsePayload, _ := json.Marshal(response)
+	responseMsg, _ := json.Marshal(WSMessage{
+		Type:    MsgTypeChatResponse,
+		Payload: responsePayload,
+	})
+	client.TrySend(responseMsg)
+}
+
 // handleChatMessage processes a cooking Q&A question.
 func (ch *CookingHandler) handleChatMessage(client *Client, payload json.RawMessage) {
 	log := logger.Get()
@@ -384,7 +674,7 @@ func (ch *CookingHandler) handleChatMessage(client *Client, payload json.RawMess
 		zap.Uint("user_id", client.UserID),
 	)
 
-	answer, err := ch.VoiceService.AnswerCookingQuestion(ctx, chatMsg.Message, recipeContextWithStep(client, chatMsg.RecipeContext))
+	answer, err := ch.answerQuestion(ctx, client, chatMsg.Message, chatMsg.RecipeContext)
 	if err != nil {
 		log.Error("failed to get cooking answer",
 			zap.String("room_id", client.RoomID),
@@ -394,15 +684,11 @@ func (ch *CookingHandler) handleChatMessage(client *Client, payload json.RawMess
 		ch.sendError(client, "failed to get cooking answer")
 		return
 	}
+	ch.recordQA(client, chatMsg.Message, answer)
 
-	responsePayload, _ := json.Marshal(ChatResponsePayload{
-		Message: answer,
-	})
-	responseMsg, _ := json.Marshal(WSMessage{
-		Type:    MsgTypeChatResponse,
-		Payload: responsePayload,
-	})
-	client.TrySend(responseMsg)
+	if chatMsg.Speak {
+		ch.speakAnswer(ctx, client, answer)
+	}
 }
 
 // handleVoiceTranscript processes a voice transcription.
@@ -455,6 +741,7 @@ func (ch *CookingHandler) handleVoiceTranscript(client *Client, payload json.Raw
 		Amount: intent.Amount,
 		Target: intent.Target,
 		Text:   intent.Text,
+		Step:   intent.Step,
 	})
 	intentMsg, _ := json.Marshal(WSMessage{
 		Type:    MsgTypeVoiceIntent,
@@ -490,7 +777,7 @@ func (ch *CookingHandler) handleVoiceTranscript(client *Client, payload json.Raw
 		client.TrySend(navMsg)
 
 	case "question":
-		answer, err := ch.VoiceService.AnswerCookingQuestion(ctx, intent.Text, recipeContextWithStep(client, ""))
+		answer, err := ch.answerQuestion(ctx, client, intent.Text, "")
 		if err != nil {
 			log.Error("failed to answer voice question",
 				zap.String("room_id", client.RoomID),
@@ -500,14 +787,14 @@ func (ch *CookingHandler) handleVoiceTranscript(client *Client, payload json.Raw
 			ch.sendError(client, "failed to get cooking answer")
 			return
 		}
-		chatPayload, _ := json.Marshal(ChatResponsePayload{
-			Message: answer,
-		})
-		chatMsg, _ := json.Marshal(WSMessage{
-			Type:    MsgTypeChatResponse,
-			Payload: chatPayload,
-		})
-		client.TrySend(chatMsg)
+		ch.recordQA(client, intent.Text, answer)
+
+		if transcript.Speak {
+			ch.speakAnswer(ctx, client, answer)
+		}
+
+	case "read_step":
+		ch.readStep(ctx, client, intent.Step)
 
 	case "ignore":
 		// Do nothing
@@ -520,14 +807,106 @@ func (ch *CookingHandler) handleVoiceTranscript(client *Client, payload json.Raw
 	}
 }
 
-// sendError sends an error message to a single client.
-func (ch *CookingHandler) sendError(client *Client, message string) {
-	errPayload, _ := json.Marshal(ErrorPayload{
-		Message: message,
+// handleReadStep reads a recipe step aloud on request.
+func (ch *CookingHandler) handleReadStep(client *Client, payload json.RawMessage) {
+	var req ReadStepPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		ch.sendError(client, "invalid read step payload")
+		return
+	}
+	if req.Step < 0 {
+		ch.sendError(client, "step must be non-negative")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	ch.readStep(ctx, client, req.Step)
+}
+
+// readStep sends the audio of a 1-based recipe step, or of the client's
+// current step when step is 0.
+func (ch *CookingHandler) readStep(ctx context.Context, client *Client, step int) {
+	log := logger.Get()
+
+	if step == 0 {
+		current, ok := client.CurrentStep()
+		if !ok {
+			ch.sendError(client, "no current step; say which step to read")
+			return
+		}
+		step = current
+	}
+
+	recipeID, err := strconv.ParseUint(client.RoomID, 10, 64)
+	if err != nil {
+		ch.sendError(client, "invalid recipe_id")
+		return
+	}
+	recipe, err := ch.Recipes.GetRecipeByID(uint(recipeID))
+	if err != nil {
+		ch.sendError(client, "recipe not found")
+		return
+	}
+
+	clip, err := ch.VoiceService.ReadStep(ctx, recipe, step)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNoSuchStep):
+			ch.sendError(client, fmt.Sprintf("recipe has no step %d", step))
+		case errors.Is(err, service.ErrReadAloudUnavailable):
+			ch.sendError(client, "read-aloud is not available")
+		default:
+			log.Error("failed to read step aloud",
+				zap.String("room_id", client.RoomID),
+				zap.Uint("user_id", client.UserID),
+				zap.Int("step", step),
+				zap.Error(err),
+			)
+			ch.sendError(client, "failed to read step aloud")
+		}
+		return
+	}
+	ch.sendSpeech(client, clip, step)
+}
+
+// speakAnswer follows a chat_response with the answer read aloud. The text
+// answer has already been delivered, so failures only report an error.
+func (ch *CookingHandler) speakAnswer(ctx context.Context, client *Client, answer string) {
+	if !ch.VoiceService.CanReadAloud() {
+		ch.sendError(client, "read-aloud is not available")
+		return
+	}
+	clip, err := ch.VoiceService.ReadAloud(ctx, answer)
+	if err != nil {
+		logger.Get().Error("failed to read answer aloud",
+			zap.String("room_id", client.RoomID),
+			zap.Uint("user_id", client.UserID),
+			zap.Error(err),
+		)
+		ch.sendError(client, "failed to read answer aloud")
+		return
+	}
+	ch.sendSpeech(client, clip, 0)
+}
+
+// sendSpeech sends read-aloud audio to a single client.
+func (ch *CookingHandler) sendSpeech(client *Client, clip *service.SpeechClip, step int) {
+	speechPayload, _ := json.Marshal(SpeechAudioPayload{
+		Text:      clip.Text,
+		Step:      step,
+		Format:    clip.Format,
+		AudioData: clip.Audio,
+		AudioURL:  clip.URL,
 	})
-	errMsg, _ := json.Marshal(WSMessage{
-		Type:    MsgTypeError,
-		Payload: errPayload,
+	speechMsg, _ := json.Marshal(WSMessage{
+		Type:    MsgTypeSpeechAudio,
+		Payload: speechPayload,
 	})
-	client.TrySend(errMsg)
+	client.TrySend(speechMsg)
+}
+
+// sendError sends an error message to a single client.
+func (ch *CookingHandler) sendError(client *Client, message string) {
+	sendClientError(client, message)
 }
@@ -0,0 +1,255 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// sessionSaveTimeout bounds a single cooking-session write.
+const sessionSaveTimeout = 5 * time.Second
+
+// sessionWriter applies cooking-session writes off the websocket read loop so
+// a slow database never stalls message handling. Writes to one session run
+// one at a time in the order they were queued; different sessions write
+// concurrently. The zero value is ready to use.
+type sessionWriter struct {
+	mu sync.Mutex
+	// pending holds each session's queued writes; a key is present while a
+	// goroutine is draining it.
+	pending map[string][]func()
+}
+
+// enqueue queues write for the session under key, starting a drainer when
+// none is running.
+func (w *sessionWriter) enqueue(key string, write func()) {
+	w.mu.Lock()
+	if w.pending == nil {
+		w.pending = make(map[string][]func())
+	}
+	queued, draining := w.pending[key]
+	w.pending[key] = append(queued, write)
+	w.mu.Unlock()
+	if !draining {
+		go w.drain(key)
+	}
+}
+
+// drain runs key's queued writes until none are left.
+func (w *sessionWriter) drain(key string) {
+	for {
+		w.mu.Lock()
+		queued := w.pending[key]
+		if len(queued) == 0 {
+			delete(w.pending, key)
+			w.mu.Unlock()
+			return
+		}
+		w.pending[key] = queued[1:]
+		w.mu.Unlock()
+		queued[0]()
+	}
+}
+
+// flush blocks until every write queued for key so far has been applied.
+func (w *sessionWriter) flush(key string) {
+	done := make(chan struct{})
+	w.enqueue(key, func() { close(done) })
+	<-done
+}
+
+// sessionKey identifies a persisted cooking session in the write queue.
+func sessionKey(ownerID, recipeID uint) string {
+	return fmt.Sprintf("%d/%d", ownerID, recipeID)
+}
+
+// flushSession waits for the client's queued session writes, so a read that
+// follows sees them.
+func (ch *CookingHandler) flushSession(client *Client) {
+	if recipeID, ok := ch.sessionRecipeID(client); ok {
+		ch.writes.flush(sessionKey(client.SessionOwner(), recipeID))
+	}
+}
+
+// openSession loads (or starts) the cooking session for a newly connected
+// client (the host's, for a guest in their kitchen) and restores its current
+// step, after any writes still queued from an earlier connection. A failure
+// is logged and the client starts without a snapshot.
+func (ch *CookingHandler) openSession(client *Client, recipe *models.Recipe) *models.CookingSession {
+	if ch.Sessions == nil {
+		return nil
+	}
+	ch.writes.flush(sessionKey(client.SessionOwner(), recipe.ID))
+	ctx, cancel := context.WithTimeout(context.Background(), sessionSaveTimeout)
+	defer cancel()
+	session, err := ch.Sessions.Open(ctx, client.SessionOwner(), recipe)
+	if err != nil {
+		logger.Get().Warn("failed to open cooking session",
+			zap.Uint("recipe_id", recipe.ID),
+			zap.Uint("user_id", client.UserID),
+			zap.Error(err),
+		)
+		return nil
+	}
+	if session.HasStep {
+		client.SetCurrentStep(session.CurrentStep)
+	}
+	return session
+}
+
+// saveSession queues a change to the client's persisted cooking session and
+// returns without waiting for it. Failures are logged, never sent: the live
+// session carries on regardless.
+func (ch *CookingHandler) saveSession(client *Client, change string, save func(ctx context.Context, recipeID uint) error) {
+	recipeID, ok := ch.sessionRecipeID(client)
+	if !ok {
+		return
+	}
+	ch.writes.enqueue(sessionKey(client.SessionOwner(), recipeID), func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sessionSaveTimeout)
+		defer cancel()
+		if err := save(ctx, recipeID); err != nil {
+			logger.Get().Warn("failed to save cooking session",
+				zap.String("change", change),
+				zap.String("room_id", client.RoomID),
+				zap.Uint("user_id", client.UserID),
+				zap.Error(err),
+			)
+		}
+	})
+}
+
+// sessionRecipeID is the recipe the client's session belongs to; false when
+// sessions are off.
+func (ch *CookingHandler) sessionRecipeID(client *Client) (uint, bool) {
+	if ch.Sessions == nil {
+		return 0, false
+	}
+	recipeID, err := strconv.ParseUint(client.RoomID, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(recipeID), true
+}
+
+// recordQA adds an answered question to the session history.
+func (ch *CookingHandler) recordQA(client *Client, question, answer string) {
+	ch.saveSession(client, "qa", func(ctx context.Context, recipeID uint) error {
+		return ch.Sessions.RecordQA(ctx, client.SessionOwner(), recipeID, question, answer)
+	})
+}
+
+// handleTimerStart starts a kitchen timer and tells every device in the
+// room about it.
+func (ch *CookingHandler) handleTimerStart(client *Client, payload json.RawMessage) {
+	var req TimerStartPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		ch.sendError(client, "invalid timer payload")
+		return
+	}
+	recipeID, ok := ch.sessionRecipeID(client)
+	if !ok {
+		ch.sendError(client, "timers are not available")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sessionSaveTimeout)
+	defer cancel()
+	timer, err := ch.Sessions.StartTimer(ctx, client.SessionOwner(), recipeID, req.Label, req.Step, time.Duration(req.DurationSeconds)*time.Second)
+	if errors.Is(err, service.ErrInvalidTimer) {
+		ch.sendError(client, err.Error())
+		return
+	}
+	if err != nil {
+		ch.sendError(client, "failed to start timer")
+		return
+	}
+	ch.broadcastToRoom(client, MsgTypeTimerStart, timer)
+}
+
+// handleTimerCancel stops a kitchen timer and tells every device in the
+// room.
+func (ch *CookingHandler) handleTimerCancel(client *Client, payload json.RawMessage) {
+	var req TimerCancelPayload
+	if err := json.Unmarshal(payload, &req); err != nil || req.ID == "" {
+		ch.sendError(client, "invalid timer payload")
+		return
+	}
+	recipeID, ok := ch.sessionRecipeID(client)
+	if !ok {
+		ch.sendError(client, "timers are not available")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sessionSaveTimeout)
+	defer cancel()
+	err := ch.Sessions.CancelTimer(ctx, client.SessionOwner(), recipeID, req.ID)
+	if errors.Is(err, service.ErrTimerNotFound) {
+		ch.sendError(client, "timer not found")
+		return
+	}
+	if err != nil {
+		ch.sendError(client, "failed to cancel timer")
+		return
+	}
+	ch.broadcastToRoom(client, MsgTypeTimerCancel, TimerCancelPayload{ID: req.ID})
+}
+
+// broadcastToRoom sends a server message to every client in the room,
+// including the sender.
+func (ch *CookingHandler) broadcastToRoom(client *Client, msgType string, payload any) {
+	sendToRoom(ch.Hub, client.RoomID, msgType, payload)
+}
+
+// handleSessionEnd ends the host's cooking session and tells the room,
+// offering everyone a cook log draft for what they just made.
+func (ch *CookingHandler) handleSessionEnd(client *Client) {
+	if client.UserID != client.SessionOwner() {
+		ch.sendError(client, "only the host can end the cooking session")
+		return
+	}
+	recipeID, ok := ch.sessionRecipeID(client)
+	if !ok {
+		ch.sendError(client, "sessions are not available")
+		return
+	}
+
+	// Queued writes land first, so the draft is complete and none of them
+	// recreates the session after it ends.
+	ch.flushSession(client)
+	ctx, cancel := context.WithTimeout(context.Background(), sessionSaveTimeout)
+	defer cancel()
+	var payload SessionEndPayload
+	if ch.CookLogs != nil {
+		session, err := ch.Sessions.Get(ctx, client.UserID, recipeID)
+		if err != nil {
+			logger.Get().Warn("failed to load cooking session for cook log draft",
+				zap.String("room_id", client.RoomID),
+				zap.Uint("user_id", client.UserID),
+				zap.Error(err),
+			)
+		} else if session != nil {
+			draft := ch.CookLogs.Draft(session)
+			payload.CookLogDraft = &draft
+		}
+	}
+	if err := ch.Sessions.End(ctx, client.UserID, recipeID); err != nil {
+		logger.Get().Error("failed to end cooking session",
+			zap.String("room_id", client.RoomID),
+			zap.Uint("user_id", client.UserID),
+			zap.Error(err),
+		)
+		ch.sendError(client, "failed to end cooking session")
+		return
+	}
+	ch.broadcastToRoom(client, MsgTypeSessionEnd, payload)
+}
@@ -0,0 +1,380 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/models"
+	"github.com/windoze95/saltybytes-api/internal/service"
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+)
+
+// setupSessionHandler is setupTestCookingHandler with persistent sessions;
+// clients should join room "1" (the test recipe).
+func setupSessionHandler() (*CookingHandler, *testutil.MockTextProvider, *service.CookingSessionService) {
+	ch, mockText, _ := setupTestCookingHandler()
+	sessions := service.NewCookingSessionService(testutil.NewMockCookingSessionRepo())
+	ch.Sessions = sessions
+	return ch, mockText, sessions
+}
+
+func TestCookingSession_ReconnectRestoresState(t *testing.T) {
+	ch, mockText, _ := setupSessionHandler()
+	mockText.CookingQAFunc = func(ctx context.Context, question, recipeContext string) (string, error) {
+		return "About five minutes.", nil
+	}
+	recipe := testutil.TestRecipe()
+
+	first := newTestClient(ch.Hub, "1", 1)
+	ch.openSession(first, recipe)
+	ch.handleMessage(first, []byte(`{"type":"step_change","payload":{"step":3}}`))
+	ch.handleMessage(first, []byte(`{"type":"ephemeral_edit","payload":{"step_index":2,"modification":"double the garlic"}}`))
+	ch.handleChatMessage(first, json.RawMessage(`{"message":"how long to rest?"}`))
+
+	// The socket drops; a new connection picks the session back up.
+	second := newTestClient(ch.Hub, "1", 1)
+	session := ch.openSession(second, recipe)
+	if session == nil {
+		t.Fatal("no session snapshot on reconnect")
+	}
+	if step, ok := second.CurrentStep(); !ok || step != 3 {
+		t.Errorf("client step = %d (%v), want 3 restored", step, ok)
+	}
+	if len(session.Edits) != 1 || session.Edits[0].Modification != "double the garlic" {
+		t.Errorf("edits = %+v", session.Edits)
+	}
+	if len(session.History) != 1 || session.History[0].Answer != "About five minutes." {
+		t.Errorf("history = %+v", session.History)
+	}
+
+	ch.handleMessage(second, []byte(`{"type":"ephemeral_reset","payload":{}}`))
+	if session = ch.openSession(second, recipe); len(session.Edits) != 0 {
+		t.Errorf("edits after reset = %+v", session.Edits)
+	}
+}
+
+// blockingSessionRepo holds every session write until release is closed.
+type blockingSessionRepo struct {
+	*testutil.MockCookingSessionRepo
+	release chan struct{}
+}
+
+func (r *blockingSessionRepo) Modify(ctx context.Context, userID, recipeID uint, fn func(*models.CookingSession) error) (*models.CookingSession, error) {
+	<-r.release
+	return r.MockCookingSessionRepo.Modify(ctx, userID, recipeID, fn)
+}
+
+func TestCookingSession_SlowWritesDoNotBlockMessages(t *testing.T) {
+	ch, _, _ := setupSessionHandler()
+	repo := &blockingSessionRepo{MockCookingSessionRepo: testutil.NewMockCookingSessionRepo(), release: make(chan struct{})}
+	sessions := service.NewCookingSessionService(repo)
+	ch.Sessions = sessions
+	client := newTestClient(ch.Hub, "1", 1)
+
+	handled := make(chan struct{})
+	go func() {
+		defer close(handled)
+		ch.handleMessage(client, []byte(`{"type":"step_change","payload":{"step":3}}`))
+		ch.handleMessage(client, []byte(`{"type":"step_change","payload":{"step":5}}`))
+	}()
+	select {
+	case <-handled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("handling messages waited on the session write")
+	}
+
+	close(repo.release)
+	ch.flushSession(client)
+	if session, _ := sessions.Get(context.Background(), 1, 1); session == nil || session.CurrentStep != 5 {
+		t.Errorf("session = %+v, want the writes applied in order ending on step 5", session)
+	}
+}
+
+func TestCookingSession_TimersBroadcastToRoom(t *testing.T) {
+	ch, _, sessions := setupSessionHandler()
+	phone := newTestClient(ch.Hub, "1", 1)
+	tablet := newTestClient(ch.Hub, "1", 1)
+	ch.Hub.Register <- phone
+	ch.Hub.Register <- tablet
+	ch.Hub.Register <- newTestClient(ch.Hub, "2", 2)
+
+	ch.handleMessage(phone, []byte(`{"type":"timer_start","payload":{"label":"rest dough","duration_seconds":600}}`))
+	var timer models.CookingTimer
+	for _, c := range []*Client{phone, tablet} {
+		msg := readMessage(t, c)
+		if msg.Type != MsgTypeTimerStart {
+			t.Fatalf("expected %q, got %q", MsgTypeTimerStart, msg.Type)
+		}
+		if err := json.Unmarshal(msg.Payload, &timer); err != nil || timer.ID == "" || timer.DurationSeconds != 600 {
+			t.Fatalf("unexpected timer %+v (%v)", timer, err)
+		}
+	}
+	if list, _ := sessions.ListActive(context.Background(), 1); len(list) != 1 || len(list[0].Timers) != 1 {
+		t.Fatalf("timer not persisted: %+v", list)
+	}
+
+	payload, _ := json.Marshal(TimerCancelPayload{ID: timer.ID})
+	data, _ := json.Marshal(WSMessage{Type: MsgTypeTimerCancel, Payload: payload})
+	ch.handleMessage(tablet, data)
+	if msg := readMessage(t, phone); msg.Type != MsgTypeTimerCancel {
+		t.Errorf("expected %q, got %q", MsgTypeTimerCancel, msg.Type)
+	}
+	readMessage(t, tablet)
+
+	ch.handleMessage(tablet, data)
+	if got := readErrorMessage(t, tablet); got != "timer not found" {
+		t.Errorf("unexpected error message: %q", got)
+	}
+}
+
+func TestCookingSession_TimerErrors(t *testing.T) {
+	ch, _, _ := setupSessionHandler()
+	client := newTestClient(ch.Hub, "1", 1)
+
+	ch.handleTimerStart(client, json.RawMessage(`{"duration_seconds":0}`))
+	if got := readErrorMessage(t, client); got != service.ErrInvalidTimer.Error() {
+		t.Errorf("unexpected error message: %q", got)
+	}
+
+	ch.Sessions = nil
+	ch.handleTimerStart(client, json.RawMessage(`{"duration_seconds":60}`))
+	if got := readErrorMessage(t, client); got != "timers are not available" {
+		t.Errorf("unexpected error message: %q", got)
+	}
+}
+
+func TestCookingSession_VoiceQuestionRecorded(t *testing.T) {
+	ch, mockText, sessions := setupSessionHandler()
+	mockText.ClassifyVoiceIntentFunc = func(ctx context.Context, transcript string) (*ai.VoiceIntent, error) {
+		return &ai.VoiceIntent{Type: "question", Text: "can I use butter?"}, nil
+	}
+	mockText.CookingQAFunc = func(ctx context.Context, question, recipeContext string) (string, error) {
+		return "Yes.", nil
+	}
+	client := newTestClient(ch.Hub, "1", 1)
+
+	ch.handleVoiceTranscript(client, json.RawMessage(`{"transcript":"can I use butter"}`))
+	readMessage(t, client) // intent
+	readMessage(t, client) // answer
+
+	ch.flushSession(client)
+	list, _ := sessions.ListActive(context.Background(), 1)
+	if len(list) != 1 || len(list[0].History) != 1 || list[0].History[0].Question != "can I use butter?" {
+		t.Errorf("voice question not recorded: %+v", list)
+	}
+}
+
+// streamingText answers cooking chat in fixed chunks, recording the request.
+type streamingText struct {
+	*testutil.MockTextProvider
+	chunks []string
+	got    chan ai.CookingChatRequest
+}
+
+func (s *streamingText) StreamCookingChat(ctx context.Context, req ai.CookingChatRequest, onDelta func(string)) (string, error) {
+	s.got <- req
+	for _, c := range s.chunks {
+		onDelta(c)
+	}
+	return strings.Join(s.chunks, ""), nil
+}
+
+func TestCookingSession_GroundedQAStreamsWithHistory(t *testing.T) {
+	ch, _, sessions := setupSessionHandler()
+	recipes := testutil.NewMockRecipeRepo()
+	recipes.Recipes[1] = testutil.TestRecipe()
+	text := &streamingText{MockTextProvider: &testutil.MockTextProvider{}, chunks: []string{"About ", "two ", "minutes."}, got: make(chan ai.CookingChatRequest, 2)}
+	ch.QA = service.NewCookingQAService(text, recipes)
+	ch.QA.Sessions = sessions
+
+	client := newTestClient(ch.Hub, "1", 1)
+	ch.openSession(client, recipes.Recipes[1])
+	ch.handleMessage(client, []byte(`{"type":"step_change","payload":{"step":3}}`))
+
+	ask := func(question string) (ai.CookingChatRequest, string) {
+		ch.handleChatMessage(client, json.RawMessage(`{"message":"`+question+`","recipe_context":"ignored"}`))
+		var id, streamed string
+		for range text.chunks {
+			msg := readMessage(t, client)
+			if msg.Type != MsgTypeChatDelta {
+				t.Fatalf("expected type %q, got %q", MsgTypeChatDelta, msg.Type)
+			}
+			var delta ChatDeltaPayload
+			_ = json.Unmarshal(msg.Payload, &delta)
+			if id != "" && delta.ID != id {
+				t.Errorf("delta ID changed mid-answer: %q then %q", id, delta.ID)
+			}
+			id, streamed = delta.ID, streamed+delta.Delta
+		}
+		msg := readMessage(t, client)
+		var resp ChatResponsePayload
+		_ = json.Unmarshal(msg.Payload, &resp)
+		if msg.Type != MsgTypeChatResponse || resp.ID != id || resp.Message != streamed {
+			t.Errorf("final message %s %+v, want chat_response %q with ID %q", msg.Type, resp, streamed, id)
+		}
+		assertNoMoreMessages(t, client)
+		return <-text.got, resp.Message
+	}
+
+	first, answer := ask("how long to sear?")
+	if answer != "About two minutes." {
+		t.Errorf("answer = %q", answer)
+	}
+	if strings.Contains(first.RecipeContext, "ignored") ||
+		!strings.Contains(first.RecipeContext, "Classic Pancakes") ||
+		!strings.Contains(first.RecipeContext, "currently on step 3") {
+		t.Errorf("recipe context not built from server state: %q", first.RecipeContext)
+	}
+	if len(first.History) != 0 {
+		t.Errorf("first question history = %+v", first.History)
+	}
+
+	second, _ := ask("and the other side?")
+	if len(second.History) != 2 || second.History[0].Content != "how long to sear?" || second.History[1].Content != "About two minutes." {
+		t.Errorf("follow-up history = %+v, want the first exchange", second.History)
+	}
+}
+
+func TestCookingSession_SharedRoomSyncsGuests(t *testing.T) {
+	ch, _, sessions := setupSessionHandler()
+	users := testutil.NewMockUserRepo()
+	users.Users[2] = &models.User{Username: "robin"}
+	ch.Users = users
+	recipe := testutil.TestRecipe()
+
+	host := newTestClient(ch.Hub, "1", 1)
+	host.HostID = recipe.CreatedByID
+	ch.openSession(host, recipe)
+	ch.Hub.Register <- host
+	guest := newTestClient(ch.Hub, "1", 2)
+	guest.HostID = recipe.CreatedByID
+	ch.Hub.Register <- guest
+	ch.announceParticipant(guest, MsgTypeParticipantJoin)
+
+	msg := readMessage(t, host)
+	var joined ParticipantPayload
+	_ = json.Unmarshal(msg.Payload, &joined)
+	if msg.Type != MsgTypeParticipantJoin || joined.UserID != 2 || joined.Name != "robin" || joined.Host {
+		t.Fatalf("join event = %s %+v", msg.Type, joined)
+	}
+	assertNoMoreMessages(t, guest)
+
+	// The guest moves the room on: the host's device follows and the step is
+	// saved to the host's session.
+	ch.handleMessage(guest, []byte(`{"type":"step_change","payload":{"step":4}}`))
+	msg = readMessage(t, host)
+	var moved StepChangePayload
+	_ = json.Unmarshal(msg.Payload, &moved)
+	if msg.Type != MsgTypeStepChange || moved.Step != 4 || moved.UserID != 2 {
+		t.Fatalf("step event = %s %+v", msg.Type, moved)
+	}
+	if step, ok := host.CurrentStep(); !ok || step != 4 {
+		t.Errorf("host step = %d (%v), want 4", step, ok)
+	}
+	ch.flushSession(guest)
+	if session, _ := sessions.Get(context.Background(), 1, recipe.ID); session == nil || session.CurrentStep != 4 {
+		t.Errorf("host session = %+v, want step 4", session)
+	}
+
+	ch.handleMessage(guest, []byte(`{"type":"timer_start","payload":{"duration_seconds":60}}`))
+	for _, c := range []*Client{host, guest} {
+		if msg := readMessage(t, c); msg.Type != MsgTypeTimerStart {
+			t.Fatalf("expected %q, got %q", MsgTypeTimerStart, msg.Type)
+		}
+	}
+	if session, _ := sessions.Get(context.Background(), 1, recipe.ID); len(session.Timers) != 1 {
+		t.Errorf("guest timer not on the host's session: %+v", session.Timers)
+	}
+	if list, _ := sessions.ListActive(context.Background(), 2); len(list) != 0 {
+		t.Errorf("guest has sessions of their own: %+v", list)
+	}
+}
+
+func TestCookingSession_AuthorizeJoin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ch, _, sessions := setupSessionHandler()
+	recipe := testutil.TestRecipe()
+	session, err := sessions.CreateJoinCode(context.Background(), recipe.CreatedByID, recipe)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		userID uint
+		code   string
+		ok     bool
+	}{
+		{"owner", recipe.CreatedByID, "", true},
+		{"guest with code", 2, session.JoinCode, true},
+		{"guest without code", 2, "", false},
+		{"guest with wrong code", 2, "NOTACODE", false},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v1/ws/cook/1?code="+tt.code, nil)
+		if got := ch.authorizeJoin(c, tt.userID, recipe); got != tt.ok {
+			t.Errorf("%s: authorizeJoin() = %v, want %v", tt.name, got, tt.ok)
+		}
+		if !tt.ok && w.Code != http.StatusForbidden {
+			t.Errorf("%s: status = %d, want 403", tt.name, w.Code)
+		}
+	}
+
+	// Without sessions there are no invites: only the owner may cook.
+	ch.Sessions = nil
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/ws/cook/1?code="+session.JoinCode, nil)
+	if ch.authorizeJoin(c, 2, recipe) || w.Code != http.StatusForbidden {
+		t.Errorf("guest without sessions was let in (status %d)", w.Code)
+	}
+}
+
+func TestCookingSession_EndOffersCookLogDraft(t *testing.T) {
+	ch, _, sessions := setupSessionHandler()
+	recipes := testutil.NewMockRecipeRepo()
+	recipe := testutil.TestRecipe()
+	recipes.Recipes[recipe.ID] = recipe
+	ch.CookLogs = service.NewCookLogService(nil, testutil.NewMockCookLogRepo(), recipes)
+
+	host := newTestClient(ch.Hub, "1", 1)
+	host.HostID = recipe.CreatedByID
+	ch.openSession(host, recipe)
+	ch.Hub.Register <- host
+	guest := newTestClient(ch.Hub, "1", 2)
+	guest.HostID = recipe.CreatedByID
+	ch.Hub.Register <- guest
+	ch.handleMessage(host, []byte(`{"type":"ephemeral_edit","payload":{"step_index":1,"modification":"half the sugar"}}`))
+	for _, c := range []*Client{host, guest} {
+		readMessage(t, c)
+	}
+
+	ch.handleMessage(guest, []byte(`{"type":"session_end","payload":{}}`))
+	if got := readErrorMessage(t, guest); got != "only the host can end the cooking session" {
+		t.Errorf("unexpected error message: %q", got)
+	}
+
+	ch.handleMessage(host, []byte(`{"type":"session_end","payload":{}}`))
+	for _, c := range []*Client{host, guest} {
+		msg := readMessage(t, c)
+		var end SessionEndPayload
+		_ = json.Unmarshal(msg.Payload, &end)
+		if msg.Type != MsgTypeSessionEnd || end.CookLogDraft == nil ||
+			end.CookLogDraft.RecipeTitle != "Classic Pancakes" || !strings.Contains(end.CookLogDraft.Notes, "half the sugar") {
+			t.Fatalf("end event = %s %+v", msg.Type, end.CookLogDraft)
+		}
+	}
+	if session, _ := sessions.Get(context.Background(), 1, recipe.ID); session != nil {
+		t.Errorf("session still active after end: %+v", session)
+	}
+}
@@ -0,0 +1,168 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/windoze95/saltybytes-api/internal/ai"
+	"github.com/windoze95/saltybytes-api/internal/testutil"
+)
+
+// setupReadAloudHandler is setupTestCookingHandler with a synthesizer and the
+// test recipe (ID 1) in the repo; clients should join room "1".
+func setupReadAloudHandler() (*CookingHandler, *testutil.MockTextProvider, *testutil.MockSpeechSynthesisProvider) {
+	ch, mockText, _ := setupTestCookingHandler()
+	ch.Recipes.(*testutil.MockRecipeRepo).Recipes[1] = testutil.TestRecipe()
+	synth := &testutil.MockSpeechSynthesisProvider{
+		SynthesizeSpeechFunc: func(ctx context.Context, text, voice string) (*ai.SpeechAudio, error) {
+			return &ai.SpeechAudio{Data: []byte("RIFF" + text), Format: "wav"}, nil
+		},
+	}
+	ch.VoiceService.Synthesizer = synth
+	return ch, mockText, synth
+}
+
+func readSpeech(t *testing.T, client *Client) SpeechAudioPayload {
+	t.Helper()
+	msg := readMessage(t, client)
+	if msg.Type != MsgTypeSpeechAudio {
+		t.Fatalf("expected type %q, got %q: %s", MsgTypeSpeechAudio, msg.Type, msg.Payload)
+	}
+	var speech SpeechAudioPayload
+	if err := json.Unmarshal(msg.Payload, &speech); err != nil {
+		t.Fatalf("failed to unmarshal SpeechAudioPayload: %v", err)
+	}
+	return speech
+}
+
+func readErrorMessage(t *testing.T, client *Client) string {
+	t.Helper()
+	msg := readMessage(t, client)
+	if msg.Type != MsgTypeError {
+		t.Fatalf("expected error type, got %q", msg.Type)
+	}
+	var errPayload ErrorPayload
+	if err := json.Unmarshal(msg.Payload, &errPayload); err != nil {
+		t.Fatalf("failed to unmarshal ErrorPayload: %v", err)
+	}
+	return errPayload.Message
+}
+
+func TestHandleMessage_ReadStep(t *testing.T) {
+	ch, _, _ := setupReadAloudHandler()
+	client := newTestClient(ch.Hub, "1", 42)
+
+	payload, _ := json.Marshal(ReadStepPayload{Step: 2})
+	data, _ := json.Marshal(WSMessage{Type: MsgTypeReadStep, Payload: payload})
+	ch.handleMessage(client, data)
+
+	speech := readSpeech(t, client)
+	if speech.Step != 2 || speech.Text != "Step 2. Whisk wet ingredients" {
+		t.Errorf("speech = step %d %q, want step 2", speech.Step, speech.Text)
+	}
+	// No bucket in the test config, so the audio arrives inline.
+	if speech.Format != "wav" || string(speech.AudioData) != "RIFF"+speech.Text || speech.AudioURL != "" {
+		t.Errorf("unexpected audio: format %q, url %q, %d bytes", speech.Format, speech.AudioURL, len(speech.AudioData))
+	}
+	assertNoMoreMessages(t, client)
+}
+
+func TestHandleReadStep_ZeroReadsCurrentStep(t *testing.T) {
+	ch, _, _ := setupReadAloudHandler()
+	client := newTestClient(ch.Hub, "1", 42)
+
+	ch.handleReadStep(client, json.RawMessage(`{"step":0}`))
+	if got := readErrorMessage(t, client); got != "no current step; say which step to read" {
+		t.Errorf("unexpected error message: %q", got)
+	}
+
+	client.SetCurrentStep(3)
+	ch.handleReadStep(client, json.RawMessage(`{"step":0}`))
+	if speech := readSpeech(t, client); speech.Step != 3 {
+		t.Errorf("read step %d, want the current step 3", speech.Step)
+	}
+}
+
+func TestReadStep_UsesStepChangeNumbering(t *testing.T) {
+	ch, _, _ := setupReadAloudHandler()
+	client := newTestClient(ch.Hub, "1", 42)
+
+	// step_change's step_number and read_step number steps the same way, so
+	// reading the current step reads the one the user is looking at.
+	ch.handleMessage(client, []byte(`{"type":"step_change","payload":{"step":1,"step_number":2}}`))
+	ch.handleReadStep(client, json.RawMessage(`{"step":0}`))
+	current := readSpeech(t, client)
+
+	ch.handleReadStep(client, json.RawMessage(`{"step":2}`))
+	explicit := readSpeech(t, client)
+
+	if current.Step != 2 || current.Text != explicit.Text {
+		t.Errorf("current step read as %d %q, want step 2 %q", current.Step, current.Text, explicit.Text)
+	}
+}
+
+func TestHandleReadStep_Errors(t *testing.T) {
+	ch, _, _ := setupReadAloudHandler()
+	client := newTestClient(ch.Hub, "1", 42)
+
+	ch.handleReadStep(client, json.RawMessage(`{"step":9}`))
+	if got := readErrorMessage(t, client); got != "recipe has no step 9" {
+		t.Errorf("unexpected error message: %q", got)
+	}
+
+	ch.VoiceService.Synthesizer = nil
+	ch.handleReadStep(client, json.RawMessage(`{"step":1}`))
+	if got := readErrorMessage(t, client); got != "read-aloud is not available" {
+		t.Errorf("unexpected error message: %q", got)
+	}
+}
+
+func TestHandleVoiceTranscript_ReadStepIntent(t *testing.T) {
+	ch, mockText, _ := setupReadAloudHandler()
+	client := newTestClient(ch.Hub, "1", 42)
+	mockText.ClassifyVoiceIntentFunc = func(ctx context.Context, transcript string) (*ai.VoiceIntent, error) {
+		return &ai.VoiceIntent{Type: "read_step", Step: 1}, nil
+	}
+
+	payload, _ := json.Marshal(VoiceTranscriptPayload{Transcript: "read step one"})
+	ch.handleVoiceTranscript(client, payload)
+
+	msg := readMessage(t, client)
+	var intent VoiceIntentPayload
+	if err := json.Unmarshal(msg.Payload, &intent); err != nil || intent.Type != "read_step" || intent.Step != 1 {
+		t.Fatalf("intent = %+v (%v), want read_step 1", intent, err)
+	}
+	if speech := readSpeech(t, client); speech.Text != "Step 1. Mix dry ingredients" {
+		t.Errorf("unexpected step text: %q", speech.Text)
+	}
+	assertNoMoreMessages(t, client)
+}
+
+func TestHandleChatMessage_SpeakFollowsAnswerWithAudio(t *testing.T) {
+	ch, mockText, synth := setupReadAloudHandler()
+	client := newTestClient(ch.Hub, "1", 42)
+	mockText.CookingQAFunc = func(ctx context.Context, question, recipeContext string) (string, error) {
+		return "Yes, chef!", nil
+	}
+
+	payload, _ := json.Marshal(ChatMessagePayload{Message: "is it done?", Speak: true})
+	ch.handleChatMessage(client, payload)
+
+	if msg := readMessage(t, client); msg.Type != MsgTypeChatResponse {
+		t.Fatalf("expected the text answer first, got %q", msg.Type)
+	}
+	speech := readSpeech(t, client)
+	if speech.Step != 0 || speech.Text != "Yes, chef!" || len(speech.AudioData) == 0 {
+		t.Errorf("unexpected answer audio: %+v", speech)
+	}
+
+	// Without speak, only the text answer is sent.
+	payload, _ = json.Marshal(ChatMessagePayload{Message: "is it done?"})
+	ch.handleChatMessage(client, payload)
+	readMessage(t, client)
+	assertNoMoreMessages(t, client)
+	if synth.Calls != 1 {
+		t.Errorf("synthesizer called %d times, want 1", synth.Calls)
+	}
+}
@@ -533,6 +533,27 @@ func TestHandleMessage_PingWithoutPayload(t *testing.T) {
 	}
 }
 
+// --- presence tests ---
+
+func TestHandleMessage_PresenceListsRoomMembers(t *testing.T) {
+	ch, _, _ := setupTestCookingHandler()
+	client := newTestClient(ch.Hub, "recipe-1", 42)
+	ch.Hub.Register <- client
+	ch.Hub.Register <- newTestClient(ch.Hub, "recipe-1", 42)
+	ch.Hub.Register <- newTestClient(ch.Hub, "recipe-2", 7)
+
+	ch.handleMessage(client, []byte(`{"type":"presence"}`))
+
+	msg := readMessage(t, client)
+	var presence PresencePayload
+	if err := json.Unmarshal(msg.Payload, &presence); err != nil || msg.Type != MsgTypePresence {
+		t.Fatalf("unexpected reply %q: %v", msg.Type, err)
+	}
+	if len(presence.Members) != 1 || presence.Members[0] != (RoomMember{UserID: 42, Devices: 2}) {
+		t.Errorf("members = %+v, want user 42 on two devices", presence.Members)
+	}
+}
+
 // --- step_change tests ---
 
 func TestHandleMessage_StepChange_UpdatesClientState(t *testing.T) {
@@ -595,6 +616,40 @@ func TestHandleStepChange_NegativeStep(t *testing.T) {
 	}
 }
 
+func TestHandleStepChange_StepZeroStillAccepted(t *testing.T) {
+	ch, _, _ := setupTestCookingHandler()
+	client := newTestClient(ch.Hub, "recipe-1", 42)
+
+	ch.handleStepChange(client, json.RawMessage(`{"step":0}`))
+
+	if step, ok := client.CurrentStep(); !ok || step != 0 {
+		t.Errorf("current step = %d, %v; want 0, true", step, ok)
+	}
+	assertNoMoreMessages(t, client)
+}
+
+func TestHandleStepChange_StepNumberWins(t *testing.T) {
+	ch, _, _ := setupTestCookingHandler()
+	client := newTestClient(ch.Hub, "recipe-1", 42)
+	other := newTestClient(ch.Hub, "recipe-1", 7)
+	ch.Hub.Register <- client
+	ch.Hub.Register <- other
+
+	ch.handleStepChange(client, json.RawMessage(`{"step":2,"step_number":3}`))
+
+	if step, _ := client.CurrentStep(); step != 3 {
+		t.Errorf("current step = %d, want 3", step)
+	}
+	msg := readMessage(t, other)
+	var relayed StepChangePayload
+	if err := json.Unmarshal(msg.Payload, &relayed); err != nil {
+		t.Fatalf("failed to unmarshal relayed step change: %v", err)
+	}
+	if relayed.StepNumber != 3 || relayed.UserID != 42 {
+		t.Errorf("relayed %+v, want step_number 3 from user 42", relayed)
+	}
+}
+
 func TestStepChange_ThreadsIntoQAContext(t *testing.T) {
 	ch, mockText, _ := setupTestCookingHandler()
 	client := newTestClient(ch.Hub, "recipe-1", 42)
@@ -2,6 +2,7 @@ package ws
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -28,13 +29,20 @@ const (
 	maxConcurrentHandlers = 2
 )
 
+// nextClientID numbers clients so a backplane event can name its sender.
+var nextClientID atomic.Uint64
+
 // Client represents a single WebSocket connection.
 type Client struct {
+	id     uint64
 	Hub    *Hub
 	Conn   *websocket.Conn
 	Send   chan []byte
 	RoomID string
 	UserID uint
+	// HostID owns the cooking session the client works on: the recipe's
+	// owner when a guest joins their kitchen. Zero means the client's own.
+	HostID uint
 
 	// done is closed exactly once (via closeOnce) when the client is
 	// unregistered or evicted by the hub. The Send channel itself is NEVER
@@ -56,6 +64,7 @@ type Client struct {
 // NewClient creates a Client with its channels initialized.
 func NewClient(hub *Hub, conn *websocket.Conn, roomID string, userID uint) *Client {
 	return &Client{
+		id:         nextClientID.Add(1),
 		Hub:        hub,
 		Conn:       conn,
 		Send:       make(chan []byte, 256),
@@ -125,13 +134,39 @@ func (c *Client) CurrentStep() (int, bool) {
 	return c.currentStep, c.hasStep
 }
 
-// Hub maintains active rooms and broadcasts messages.
+// SessionOwner is the user whose cooking session the client works on.
+func (c *Client) SessionOwner() uint {
+	if c.HostID != 0 {
+		return c.HostID
+	}
+	return c.UserID
+}
+
+// Hub maintains active rooms and broadcasts messages. On its own a hub only
+// reaches clients connected to this process; with a Backplane (see
+// UseBackplane) room messages and presence are shared by every instance.
 type Hub struct {
 	Rooms      map[string]map[*Client]bool // roomID -> set of clients
 	Register   chan *Client
 	Unregister chan *Client
 	Broadcast  chan *RoomMessage
 	mu         sync.RWMutex
+
+	// observers see every room message as it is delivered; guarded by mu.
+	observers []func(roomID string, message []byte)
+
+	// instance identifies this hub on the backplane.
+	instance  string
+	backplane Backplane
+	outbound  chan *BackplaneEvent
+
+	// heartbeat is how often this hub announces itself; remote presence not
+	// refreshed within presenceTTL is dropped.
+	heartbeat   time.Duration
+	presenceTTL time.Duration
+
+	presenceMu sync.RWMutex
+	remote     map[string]*instancePresence // instance -> its rooms
 }
 
 // RoomMessage carries a message destined for a specific room.
@@ -144,18 +179,49 @@ type RoomMessage struct {
 // NewHub creates and returns a new Hub instance.
 func NewHub() *Hub {
 	return &Hub{
-		Rooms:      make(map[string]map[*Client]bool),
-		Register:   make(chan *Client),
-		Unregister: make(chan *Client),
-		Broadcast:  make(chan *RoomMessage),
+		Rooms:       make(map[string]map[*Client]bool),
+		Register:    make(chan *Client),
+		Unregister:  make(chan *Client),
+		Broadcast:   make(chan *RoomMessage),
+		instance:    newRandomID(),
+		heartbeat:   10 * time.Second,
+		presenceTTL: 30 * time.Second,
+		remote:      make(map[string]*instancePresence),
 	}
 }
 
+// UseBackplane connects the hub to the other instances through bp. It must
+// be called before Run.
+func (h *Hub) UseBackplane(bp Backplane) {
+	h.backplane = bp
+	h.outbound = make(chan *BackplaneEvent, 1024)
+}
+
+// Observe registers fn to be called with every room message this hub
+// delivers, before its clients get it. With a backplane that is every
+// instance's messages, in the room's cluster-wide order, whether or not the
+// room has clients here. fn must not block.
+func (h *Hub) Observe(fn func(roomID string, message []byte)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.observers = append(h.observers, fn)
+}
+
 // Run handles register, unregister, and broadcast events. It should be
 // launched as a goroutine.
 func (h *Hub) Run() {
 	log := logger.Get()
 
+	var heartbeat <-chan time.Time
+	if h.backplane != nil {
+		go h.publishLoop()
+		go h.consumeLoop()
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+		h.enqueue(&BackplaneEvent{Kind: EventSync})
+	}
+
 	for {
 		select {
 		case client := <-h.Register:
@@ -165,6 +231,7 @@ func (h *Hub) Run() {
 			}
 			h.Rooms[client.RoomID][client] = true
 			h.mu.Unlock()
+			h.announcePresence(client.RoomID)
 
 			log.Info("client registered",
 				zap.String("room_id", client.RoomID),
@@ -173,15 +240,11 @@ func (h *Hub) Run() {
 
 		case client := <-h.Unregister:
 			h.mu.Lock()
-			if clients, ok := h.Rooms[client.RoomID]; ok {
-				if _, exists := clients[client]; exists {
-					delete(clients, client)
-					if len(clients) == 0 {
-						delete(h.Rooms, client.RoomID)
-					}
-				}
-			}
+			left := h.removeLocked(client)
 			h.mu.Unlock()
+			if left {
+				h.announcePresence(client.RoomID)
+			}
 
 			// Never close client.Send: handler goroutines may still be
 			// sending. Signal death via the done channel instead.
@@ -193,50 +256,104 @@ func (h *Hub) Run() {
 			)
 
 		case msg := <-h.Broadcast:
-			// Collect clients that can't keep up during a read-locked
-			// iteration, then evict them afterwards under a write lock.
-			// Mutating the map while ranging over it under a dropped read
-			// lock is racy.
-			var doomed []*Client
-			h.mu.RLock()
-			for client := range h.Rooms[msg.RoomID] {
-				// Skip sender if present
-				if msg.Sender != nil && client == msg.Sender {
-					continue
-				}
-				select {
-				case client.Send <- msg.Message:
-				default:
-					// Client's send buffer is full; evict it below.
-					doomed = append(doomed, client)
-				}
+			if h.backplane == nil {
+				h.deliver(msg.RoomID, msg.Message, msg.Sender)
+				continue
 			}
-			h.mu.RUnlock()
-
-			if len(doomed) > 0 {
-				h.mu.Lock()
-				for _, client := range doomed {
-					if clients, ok := h.Rooms[msg.RoomID]; ok {
-						if _, exists := clients[client]; exists {
-							delete(clients, client)
-							if len(clients) == 0 {
-								delete(h.Rooms, msg.RoomID)
-							}
-						}
-					}
-				}
-				h.mu.Unlock()
-
-				for _, client := range doomed {
-					client.markClosed()
-					log.Warn("evicted slow websocket client",
-						zap.String("room_id", client.RoomID),
-						zap.Uint("user_id", client.UserID),
-					)
-				}
+			// Local clients get the message when it comes back from the
+			// backplane, in the same order as every other instance.
+			ev := &BackplaneEvent{Kind: EventMessage, RoomID: msg.RoomID, Message: msg.Message}
+			if msg.Sender != nil {
+				ev.Sender = msg.Sender.id
 			}
+			if !h.enqueue(ev) {
+				h.deliver(msg.RoomID, msg.Message, msg.Sender)
+			}
+
+		case <-heartbeat:
+			h.enqueue(&BackplaneEvent{Kind: EventHeartbeat})
+			h.expirePresence()
+		}
+	}
+}
+
+// removeLocked drops client from its room, deleting the room when it empties.
+// It reports whether the client was a member. h.mu must be held.
+func (h *Hub) removeLocked(client *Client) bool {
+	clients, ok := h.Rooms[client.RoomID]
+	if !ok {
+		return false
+	}
+	if _, exists := clients[client]; !exists {
+		return false
+	}
+	delete(clients, client)
+	if len(clients) == 0 {
+		delete(h.Rooms, client.RoomID)
+	}
+	return true
+}
+
+// deliver sends message to this hub's clients in roomID, skipping sender.
+// A client that can't keep up is evicted.
+func (h *Hub) deliver(roomID string, message []byte, sender *Client) {
+	h.deliverTo(roomID, message, func(c *Client) bool { return sender != nil && c == sender })
+}
+
+// deliverTo is deliver with the sender identified by skip.
+func (h *Hub) deliverTo(roomID string, message []byte, skip func(*Client) bool) {
+	// Collect clients that can't keep up during a read-locked iteration,
+	// then evict them afterwards under a write lock. Mutating the map while
+	// ranging over it under a dropped read lock is racy.
+	h.mu.RLock()
+	observers := h.observers
+	h.mu.RUnlock()
+	for _, fn := range observers {
+		fn(roomID, message)
+	}
+
+	var doomed []*Client
+	h.mu.RLock()
+	for client := range h.Rooms[roomID] {
+		if skip(client) {
+			continue
+		}
+		select {
+		case client.Send <- message:
+		default:
+			// Client's send buffer is full; evict it below.
+			doomed = append(doomed, client)
 		}
 	}
+	h.mu.RUnlock()
+
+	if len(doomed) == 0 {
+		return
+	}
+	h.mu.Lock()
+	for _, client := range doomed {
+		h.removeLocked(client)
+	}
+	h.mu.Unlock()
+	h.announcePresence(roomID)
+
+	for _, client := range doomed {
+		client.markClosed()
+		logger.Get().Warn("evicted slow websocket client",
+			zap.String("room_id", client.RoomID),
+			zap.Uint("user_id", client.UserID),
+		)
+	}
+}
+
+// setRoomStep records step as the current step of every client of this hub
+// in roomID, so devices cooking together share where they are.
+func (h *Hub) setRoomStep(roomID string, step int) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.Rooms[roomID] {
+		client.SetCurrentStep(step)
+	}
 }
 
 // ReadPump reads messages from the WebSocket connection. It is intended to be
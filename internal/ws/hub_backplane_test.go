@@ -0,0 +1,177 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newClusterHub starts a hub connected to bus.
+func newClusterHub(bus *MemoryBus) (*Hub, Backplane) {
+	hub := NewHub()
+	bp := bus.Connect()
+	hub.UseBackplane(bp)
+	go hub.Run()
+	return hub, bp
+}
+
+// eventually polls cond until it holds or fails the test after two seconds.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func receive(t *testing.T, c *Client) string {
+	t.Helper()
+	select {
+	case msg := <-c.Send:
+		return string(msg)
+	case <-time.After(2 * time.Second):
+		t.Fatalf("client %d received nothing", c.UserID)
+		return ""
+	}
+}
+
+func assertNothing(t *testing.T, c *Client) {
+	t.Helper()
+	select {
+	case msg := <-c.Send:
+		t.Fatalf("client %d received unexpected %s", c.UserID, msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHubBackplane_BroadcastReachesOtherInstances(t *testing.T) {
+	bus := NewMemoryBus()
+	hubA, _ := newClusterHub(bus)
+	hubB, _ := newClusterHub(bus)
+
+	phone := NewClient(hubA, nil, "room-1", 1)
+	tablet := NewClient(hubB, nil, "room-1", 1)
+	elsewhere := NewClient(hubB, nil, "room-2", 2)
+	hubA.Register <- phone
+	hubB.Register <- tablet
+	hubB.Register <- elsewhere
+
+	hubA.Broadcast <- &RoomMessage{RoomID: "room-1", Message: []byte(`{"type":"edit"}`), Sender: phone}
+	if got := receive(t, tablet); got != `{"type":"edit"}` {
+		t.Errorf("tablet got %s", got)
+	}
+	assertNothing(t, phone)
+	assertNothing(t, elsewhere)
+
+	// A system message reaches the sender's instance too.
+	hubB.Broadcast <- &RoomMessage{RoomID: "room-1", Message: []byte(`{"type":"reset"}`)}
+	receive(t, phone)
+	receive(t, tablet)
+}
+
+func TestHubBackplane_RoomOrderIsTheSameEverywhere(t *testing.T) {
+	bus := NewMemoryBus()
+	hubA, _ := newClusterHub(bus)
+	hubB, _ := newClusterHub(bus)
+	onA := NewClient(hubA, nil, "room-1", 1)
+	onB := NewClient(hubB, nil, "room-1", 2)
+	hubA.Register <- onA
+	hubB.Register <- onB
+
+	var wg sync.WaitGroup
+	for _, hub := range []*Hub{hubA, hubB} {
+		wg.Add(1)
+		go func(hub *Hub) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				hub.Broadcast <- &RoomMessage{RoomID: "room-1", Message: fmt.Appendf(nil, `{"from":%q,"n":%d}`, hub.instance, i)}
+			}
+		}(hub)
+	}
+	wg.Wait()
+
+	var seenA, seenB []string
+	for i := 0; i < 100; i++ {
+		seenA = append(seenA, receive(t, onA))
+		seenB = append(seenB, receive(t, onB))
+	}
+	if !slices.Equal(seenA, seenB) {
+		t.Error("instances delivered the room's messages in different orders")
+	}
+}
+
+func TestHubBackplane_PresenceIsClusterWide(t *testing.T) {
+	bus := NewMemoryBus()
+	hubA, _ := newClusterHub(bus)
+	hubB, _ := newClusterHub(bus)
+
+	hubA.Register <- NewClient(hubA, nil, "room-1", 1)
+	phone := NewClient(hubB, nil, "room-1", 2)
+	tablet := NewClient(hubB, nil, "room-1", 2)
+	hubB.Register <- phone
+	hubB.Register <- tablet
+
+	want := []RoomMember{{UserID: 1, Devices: 1}, {UserID: 2, Devices: 2}}
+	eventually(t, "both instances to see every device", func() bool {
+		return slices.Equal(hubA.Presence("room-1"), want) && slices.Equal(hubB.Presence("room-1"), want)
+	})
+
+	hubB.Unregister <- phone
+	hubB.Unregister <- tablet
+	eventually(t, "the departed user to leave hub A's presence", func() bool {
+		return slices.Equal(hubA.Presence("room-1"), []RoomMember{{UserID: 1, Devices: 1}})
+	})
+
+	// A hub that starts later learns the existing presence through a sync.
+	hubC, _ := newClusterHub(bus)
+	eventually(t, "the late hub to sync presence", func() bool {
+		return slices.Equal(hubC.Presence("room-1"), []RoomMember{{UserID: 1, Devices: 1}})
+	})
+}
+
+func TestHubBackplane_SilentInstanceExpires(t *testing.T) {
+	bus := NewMemoryBus()
+	hubA, bpA := newClusterHub(bus)
+	hubB := NewHub()
+	hubB.presenceTTL = 50 * time.Millisecond
+	hubB.UseBackplane(bus.Connect())
+	go hubB.Run()
+
+	hubA.Register <- NewClient(hubA, nil, "room-1", 1)
+	eventually(t, "hub B to see hub A's client", func() bool { return len(hubB.Presence("room-1")) == 1 })
+
+	// Hub A drops off the backplane without saying goodbye.
+	bpA.Close()
+	eventually(t, "hub A's presence to expire", func() bool { return len(hubB.Presence("room-1")) == 0 })
+}
+
+// failingBackplane refuses every publish.
+type failingBackplane struct{ events chan *BackplaneEvent }
+
+func (f *failingBackplane) Publish(context.Context, *BackplaneEvent) error {
+	return errors.New("database unavailable")
+}
+func (f *failingBackplane) Events() <-chan *BackplaneEvent { return f.events }
+func (f *failingBackplane) Close() error                   { return nil }
+
+func TestHubBackplane_PublishFailureStillDeliversLocally(t *testing.T) {
+	hub := NewHub()
+	hub.UseBackplane(&failingBackplane{events: make(chan *BackplaneEvent)})
+	go hub.Run()
+
+	sender := NewClient(hub, nil, "room-1", 1)
+	other := NewClient(hub, nil, "room-1", 2)
+	hub.Register <- sender
+	hub.Register <- other
+
+	hub.Broadcast <- &RoomMessage{RoomID: "room-1", Message: []byte(`{"type":"edit"}`), Sender: sender}
+	receive(t, other)
+	assertNothing(t, sender)
+}
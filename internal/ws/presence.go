@@ -0,0 +1,197 @@
+package ws
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"time"
+
+	"github.com/windoze95/saltybytes-api/internal/logger"
+	"go.uber.org/zap"
+)
+
+// publishTimeout bounds a single backplane publish.
+const publishTimeout = 5 * time.Second
+
+// RoomMember is one user in a room and how many of their devices are
+// connected, across every instance.
+type RoomMember struct {
+	UserID  uint `json:"user_id"`
+	Devices int  `json:"devices"`
+}
+
+// instancePresence is the last presence a remote hub published.
+type instancePresence struct {
+	rooms map[string]map[uint]int // roomID -> user ID -> devices
+	seen  time.Time
+}
+
+// newRandomID returns a random identifier, for a hub or a cook plan.
+func newRandomID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Presence returns the room's members across the cluster, ordered by user
+// ID. Without a backplane only this instance's clients are counted.
+func (h *Hub) Presence(roomID string) []RoomMember {
+	devices := h.localMembers(roomID)
+	if devices == nil {
+		devices = make(map[uint]int)
+	}
+
+	h.presenceMu.RLock()
+	cutoff := time.Now().Add(-h.presenceTTL)
+	for _, inst := range h.remote {
+		if inst.seen.Before(cutoff) {
+			continue
+		}
+		for userID, n := range inst.rooms[roomID] {
+			devices[userID] += n
+		}
+	}
+	h.presenceMu.RUnlock()
+
+	members := make([]RoomMember, 0, len(devices))
+	for userID, n := range devices {
+		members = append(members, RoomMember{UserID: userID, Devices: n})
+	}
+	slices.SortFunc(members, func(a, b RoomMember) int { return cmp.Compare(a.UserID, b.UserID) })
+	return members
+}
+
+// localMembers counts this hub's devices in roomID per user; nil when the
+// room has no local clients.
+func (h *Hub) localMembers(roomID string) map[uint]int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.Rooms[roomID]) == 0 {
+		return nil
+	}
+	devices := make(map[uint]int)
+	for client := range h.Rooms[roomID] {
+		devices[client.UserID]++
+	}
+	return devices
+}
+
+// enqueue hands ev to the publisher without blocking. It returns false when
+// there is no backplane or the queue is full.
+func (h *Hub) enqueue(ev *BackplaneEvent) bool {
+	if h.backplane == nil {
+		return false
+	}
+	select {
+	case h.outbound <- ev:
+		return true
+	default:
+		logger.Get().Warn("backplane queue full, dropping event",
+			zap.String("kind", ev.Kind), zap.String("room_id", ev.RoomID))
+		return false
+	}
+}
+
+// announcePresence queues this hub's membership of roomID for the other
+// instances.
+func (h *Hub) announcePresence(roomID string) {
+	h.enqueue(&BackplaneEvent{Kind: EventPresence, RoomID: roomID})
+}
+
+// publishLoop publishes queued events in order. Presence is snapshotted at
+// publish time, so the last announcement for a room is never stale. A room
+// message the backplane can't carry is still delivered to this instance.
+func (h *Hub) publishLoop() {
+	for ev := range h.outbound {
+		ev.Instance = h.instance
+		if ev.Kind == EventPresence {
+			ev.Members = h.localMembers(ev.RoomID)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		err := h.backplane.Publish(ctx, ev)
+		cancel()
+		if err == nil {
+			continue
+		}
+		logger.Get().Warn("backplane publish failed",
+			zap.String("kind", ev.Kind), zap.String("room_id", ev.RoomID), zap.Error(err))
+		if ev.Kind == EventMessage {
+			h.deliverTo(ev.RoomID, ev.Message, h.isSender(ev))
+		}
+	}
+}
+
+// consumeLoop applies events from the backplane.
+func (h *Hub) consumeLoop() {
+	for ev := range h.backplane.Events() {
+		switch ev.Kind {
+		case EventMessage:
+			h.deliverTo(ev.RoomID, ev.Message, h.isSender(ev))
+
+		case EventPresence, EventHeartbeat:
+			if ev.Instance == h.instance {
+				continue
+			}
+			h.presenceMu.Lock()
+			inst := h.remote[ev.Instance]
+			if inst == nil {
+				inst = &instancePresence{rooms: make(map[string]map[uint]int)}
+				h.remote[ev.Instance] = inst
+			}
+			inst.seen = time.Now()
+			if ev.Kind == EventPresence {
+				if len(ev.Members) == 0 {
+					delete(inst.rooms, ev.RoomID)
+				} else {
+					inst.rooms[ev.RoomID] = ev.Members
+				}
+			}
+			h.presenceMu.Unlock()
+
+		case EventSync:
+			if ev.Instance != h.instance {
+				h.announceAll()
+			}
+
+		case EventResync:
+			h.enqueue(&BackplaneEvent{Kind: EventSync})
+			h.announceAll()
+		}
+	}
+}
+
+// isSender matches the client that sent ev, if it is connected here.
+func (h *Hub) isSender(ev *BackplaneEvent) func(*Client) bool {
+	return func(c *Client) bool {
+		return ev.Sender != 0 && ev.Instance == h.instance && c.id == ev.Sender
+	}
+}
+
+// announceAll republishes this hub's presence in every room it has clients
+// in, plus a heartbeat so an instance with no rooms is still known.
+func (h *Hub) announceAll() {
+	h.mu.RLock()
+	rooms := make([]string, 0, len(h.Rooms))
+	for roomID := range h.Rooms {
+		rooms = append(rooms, roomID)
+	}
+	h.mu.RUnlock()
+	for _, roomID := range rooms {
+		h.announcePresence(roomID)
+	}
+	h.enqueue(&BackplaneEvent{Kind: EventHeartbeat})
+}
+
+// expirePresence forgets instances that stopped heartbeating.
+func (h *Hub) expirePresence() {
+	cutoff := time.Now().Add(-h.presenceTTL)
+	h.presenceMu.Lock()
+	defer h.presenceMu.Unlock()
+	for id, inst := range h.remote {
+		if inst.seen.Before(cutoff) {
+			delete(h.remote, id)
+		}
+	}
+}